@@ -180,7 +180,7 @@ func Load(
 			}
 
 			ri, err = sqlbase.MakeRowInserter(nil, tableDesc, nil, tableDesc.Columns,
-				true, &sqlbase.DatumAlloc{})
+				true, &evalCtx, &sqlbase.DatumAlloc{})
 			if err != nil {
 				return backupccl.BackupDescriptor{}, errors.Wrap(err, "make row inserter")
 			}
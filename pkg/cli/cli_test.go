@@ -2286,6 +2286,7 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/20
   debug/nodes/1/ranges/21
   debug/nodes/1/ranges/22
+  debug/nodes/1/ranges/23
   debug/reports/problemranges
   debug/schema/defaultdb@details
   debug/schema/postgres@details
@@ -2296,6 +2297,7 @@ writing ` + os.DevNull + `
   debug/schema/system/lease
   debug/schema/system/locations
   debug/schema/system/namespace
+  debug/schema/system/plan_baselines
   debug/schema/system/rangelog
   debug/schema/system/role_members
   debug/schema/system/settings
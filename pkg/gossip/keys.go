@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -89,6 +90,16 @@ const (
 	// statistic was computed. The statistics themselves are not stored in gossip;
 	// the keys are used to notify nodes to invalidate table statistic caches.
 	KeyTableStatAddedPrefix = "table-stat-added"
+
+	// KeySQLNotificationPrefix is the prefix for keys under which the SQL
+	// notifications sent by a committed transaction are gossiped to the
+	// nodes with listening sessions.
+	KeySQLNotificationPrefix = "sql-notification"
+
+	// KeySQLPlanBaselinesChanged is the key that indicates that the SQL plan
+	// baselines changed. The baselines themselves are not stored in gossip; the
+	// key is used to notify nodes to reload them from system.plan_baselines.
+	KeySQLPlanBaselinesChanged = "sql-plan-baselines-changed"
 )
 
 // MakeKey creates a canonical key under which to gossip a piece of
@@ -185,6 +196,12 @@ func TableIDFromTableStatAddedKey(key string) (uint32, error) {
 	return uint32(tableID), nil
 }
 
+// MakeSQLNotificationKey returns the gossip key for a batch of SQL
+// notifications, identified by the given unique ID.
+func MakeSQLNotificationKey(id uuid.UUID) string {
+	return MakeKey(KeySQLNotificationPrefix, id.String())
+}
+
 // removePrefixFromKey removes the key prefix and separator and returns what's
 // left. Returns an error if the key doesn't have this prefix.
 func removePrefixFromKey(key, prefix string) (string, error) {
@@ -176,6 +176,25 @@ type TxnSender interface {
 	// However, this is used by DistSQL for sending the transaction over the wire
 	// when it creates flows.
 	SerializeTxn() *roachpb.Transaction
+
+	// CreateSavepoint establishes a savepoint at the transaction's current
+	// position. Writes performed after this point can later be undone through
+	// RollbackToSavepoint.
+	CreateSavepoint(context.Context) (SavepointToken, error)
+
+	// RollbackToSavepoint reverts all the writes performed since the savepoint
+	// was created, leaving the transaction usable afterwards. This is permitted
+	// even after the transaction has encountered a non-retryable error. The
+	// savepoint must have been created in the transaction's current epoch.
+	RollbackToSavepoint(context.Context, SavepointToken) error
+}
+
+// SavepointToken represents a savepoint in a transaction. It is opaque to
+// everything but the TxnSender that created it.
+type SavepointToken interface {
+	// Epoch returns the epoch of the transaction at the time the savepoint
+	// was created. The savepoint is invalidated when the epoch changes.
+	Epoch() uint32
 }
 
 // TxnSenderFactory is the interface used to create new instances
@@ -307,6 +326,16 @@ func (m *MockTransactionalSender) SerializeTxn() *roachpb.Transaction {
 	return &cp
 }
 
+// CreateSavepoint is part of the TxnSender interface.
+func (m *MockTransactionalSender) CreateSavepoint(context.Context) (SavepointToken, error) {
+	panic("unimplemented")
+}
+
+// RollbackToSavepoint is part of the TxnSender interface.
+func (m *MockTransactionalSender) RollbackToSavepoint(context.Context, SavepointToken) error {
+	panic("unimplemented")
+}
+
 // UpdateStateOnRemoteRetryableErr is part of the TxnSender interface.
 func (m *MockTransactionalSender) UpdateStateOnRemoteRetryableErr(
 	ctx context.Context, pErr *roachpb.Error,
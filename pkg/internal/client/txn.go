@@ -980,6 +980,31 @@ func (txn *Txn) Type() TxnType {
 	return txn.typ
 }
 
+// CreateSavepoint establishes a savepoint at the transaction's current
+// position. See TxnSender.CreateSavepoint.
+func (txn *Txn) CreateSavepoint(ctx context.Context) (SavepointToken, error) {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.CreateSavepoint(ctx)
+}
+
+// RollbackToSavepoint reverts the writes performed since the savepoint was
+// created. See TxnSender.RollbackToSavepoint.
+func (txn *Txn) RollbackToSavepoint(ctx context.Context, s SavepointToken) error {
+	requestTxnID := txn.ID()
+	txn.mu.Lock()
+	sender := txn.mu.sender
+	txn.mu.Unlock()
+	err := sender.RollbackToSavepoint(ctx, s)
+	if retryErr, ok := err.(*roachpb.HandledRetryableTxnError); ok {
+		txn.mu.Lock()
+		txn.resetDeadlineLocked()
+		txn.replaceSenderIfTxnAbortedLocked(ctx, retryErr, requestTxnID)
+		txn.mu.Unlock()
+	}
+	return err
+}
+
 // Serialize returns a clone of the transaction's current proto.
 // This is a nuclear option; generally client code shouldn't deal with protos.
 // However, this is used by DistSQL for sending the transaction over the wire
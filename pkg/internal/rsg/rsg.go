@@ -289,6 +289,10 @@ func (r *RSG) GenerateRandomArg(typ types.T) string {
 			panic(err)
 		}
 		v = fmt.Sprintf(`'%s'`, tree.DJSON{JSON: j})
+	case types.TSVector:
+		v = fmt.Sprintf(`%s::TSVECTOR`, stringArgs[r.Intn(len(stringArgs))])
+	case types.TSQuery:
+		v = fmt.Sprintf(`%s::TSQUERY`, stringArgs[r.Intn(len(stringArgs))])
 	default:
 		// Check types that can't be compared using equality
 		switch types.UnwrapType(typ).(type) {
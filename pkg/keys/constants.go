@@ -324,4 +324,5 @@ const (
 	LocationsTableID       = 21
 	LivenessRangesID       = 22
 	RoleMembersTableID     = 23
+	PlanBaselinesTableID   = 24
 )
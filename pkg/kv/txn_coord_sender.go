@@ -106,6 +106,16 @@ type TxnCoordSender struct {
 
 		txnState txnState
 
+		// errRecoverable is set while the transaction is in txnError if all
+		// the errors that put it there were confined to the requests that
+		// failed, so that the transaction can be resumed by rolling back to a
+		// savepoint created before them. See isSavepointRecoverableError.
+		errRecoverable bool
+		// errSeqNum is the sequence number counter after the first batch that
+		// put the transaction in txnError. Only savepoints created before that
+		// batch can be used to recover from the error.
+		errSeqNum int32
+
 		// active is set whenever the transaction has sent any requests.
 		active bool
 
@@ -637,7 +647,12 @@ func (tc *TxnCoordSender) Send(
 		}
 
 		if !retriable {
-			tc.mu.txnState = txnError
+			if tc.mu.txnState != txnError {
+				tc.mu.txnState = txnError
+				tc.mu.errRecoverable = true
+				tc.mu.errSeqNum = tc.interceptorAlloc.txnSeqNumAllocator.seqNumCounter
+			}
+			tc.mu.errRecoverable = tc.mu.errRecoverable && isSavepointRecoverableError(pErr)
 		}
 
 		return nil, pErr
@@ -997,6 +1012,7 @@ func (tc *TxnCoordSender) ManualRestart(
 	// The txn might have entered the txnError state after the epoch was bumped.
 	// Reset the state for the retry.
 	tc.mu.txnState = txnPending
+	tc.mu.errRecoverable = false
 }
 
 // IsSerializablePushAndRefreshNotPossible is part of the client.TxnSender interface.
@@ -0,0 +1,166 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// savepoint is the client.SavepointToken handed out by the TxnCoordSender.
+// It records the sequence number of the last request sent before the
+// savepoint was created; rolling back to the savepoint ignores the writes of
+// all the requests sent after it.
+type savepoint struct {
+	txnID  uuid.UUID
+	epoch  uint32
+	seqNum int32
+	// intentsMark is the position of the savepoint in the txnIntentCollector's
+	// savepoint log, used to find the intents written after the savepoint.
+	intentsMark int
+}
+
+var _ client.SavepointToken = &savepoint{}
+
+// Epoch is part of the client.SavepointToken interface.
+func (s *savepoint) Epoch() uint32 {
+	return s.epoch
+}
+
+// CreateSavepoint is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) CreateSavepoint(ctx context.Context) (client.SavepointToken, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if err := tc.maybeRejectClientLocked(ctx, nil /* ba */); err != nil {
+		return nil, err.GoError()
+	}
+	return &savepoint{
+		txnID:       tc.mu.txn.ID,
+		epoch:       tc.mu.txn.Epoch,
+		seqNum:      tc.interceptorAlloc.txnSeqNumAllocator.seqNumCounter,
+		intentsMark: tc.interceptorAlloc.txnIntentCollector.savepointMarkLocked(),
+	}, nil
+}
+
+// RollbackToSavepoint is part of the client.TxnSender interface.
+//
+// The writes performed since the savepoint are marked as ignored on the
+// transaction proto, so that they are rolled back whenever the transaction's
+// intents are resolved. To make the rollback visible to the transaction's own
+// subsequent reads, the intents written since the savepoint are also reverted
+// eagerly, using the intent history that each intent keeps of the values it
+// replaced.
+func (tc *TxnCoordSender) RollbackToSavepoint(
+	ctx context.Context, s client.SavepointToken,
+) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	sp := s.(*savepoint)
+	if tc.mu.txnState == txnFinalized {
+		return roachpb.NewTransactionStatusError(
+			"client already committed or rolled back the transaction")
+	}
+	if sp.txnID != tc.mu.txn.ID || sp.epoch != tc.mu.txn.Epoch {
+		return errors.Errorf("savepoint was created in a previous epoch of the transaction")
+	}
+	if tc.mu.txnState == txnError {
+		if !tc.mu.errRecoverable {
+			return errors.New("cannot roll back to savepoint after an error that may have affected " +
+				"the rest of the transaction")
+		}
+		if sp.seqNum >= tc.mu.errSeqNum {
+			return errors.New("cannot roll back to savepoint created after the transaction encountered an error")
+		}
+		// The requests that caused the error all happened after the
+		// savepoint, so they're about to be rolled back.
+		tc.mu.txnState = txnPending
+		tc.mu.errRecoverable = false
+	}
+	if pErr := tc.maybeRejectClientLocked(ctx, nil /* ba */); pErr != nil {
+		return pErr.GoError()
+	}
+
+	lastSeq := tc.interceptorAlloc.txnSeqNumAllocator.seqNumCounter
+	if lastSeq == sp.seqNum {
+		// Nothing was sent since the savepoint was created.
+		return nil
+	}
+	tc.mu.txn.AddIgnoredSeqNumRange(enginepb.IgnoredSeqNumRange{
+		Start: sp.seqNum + 1, End: lastSeq,
+	})
+
+	intents := tc.interceptorAlloc.txnIntentCollector.intentsSinceSavepointLocked(sp.intentsMark)
+	if len(intents) == 0 {
+		return nil
+	}
+
+	// Revert the intents through the interceptor stack. Resolving intents is
+	// not a transactional request, so the txnPipeliner proves all outstanding
+	// writes before the batch is evaluated; none of the writes being rolled
+	// back can be in flight while their intents are reverted.
+	var ba roachpb.BatchRequest
+	newTxn := tc.mu.txn.Clone()
+	ba.Txn = &newTxn
+	for _, span := range intents {
+		if len(span.EndKey) == 0 {
+			ba.Add(&roachpb.ResolveIntentRequest{
+				RequestHeader:  roachpb.RequestHeaderFromSpan(span),
+				IntentTxn:      newTxn.TxnMeta,
+				Status:         roachpb.PENDING,
+				IgnoredSeqNums: newTxn.IgnoredSeqNums,
+			})
+		} else {
+			ba.Add(&roachpb.ResolveIntentRangeRequest{
+				RequestHeader:  roachpb.RequestHeaderFromSpan(span),
+				IntentTxn:      newTxn.TxnMeta,
+				Status:         roachpb.PENDING,
+				IgnoredSeqNums: newTxn.IgnoredSeqNums,
+			})
+		}
+	}
+	br, pErr := tc.interceptorStack[0].SendLocked(ctx, ba)
+	if pErr = tc.updateStateLocked(ctx, 0 /* startNS */, ba, br, pErr); pErr != nil {
+		if _, ok := pErr.GetDetail().(*roachpb.HandledRetryableTxnError); !ok {
+			// The intents may have been partially reverted, so the error
+			// can't be recovered from.
+			tc.mu.txnState = txnError
+			tc.mu.errRecoverable = false
+		}
+		return pErr.GoError()
+	}
+	return nil
+}
+
+// isSavepointRecoverableError returns true if the error leaves no trace on the
+// transaction beyond the failed request, so that the transaction can be
+// resumed by rolling back the request to a savepoint. Other errors, for
+// instance ambiguous results or errors encountered while committing, may have
+// left the transaction in an unknown state.
+func isSavepointRecoverableError(pErr *roachpb.Error) bool {
+	switch pErr.GetDetail().(type) {
+	case *roachpb.ConditionFailedError, *roachpb.WriteIntentError:
+		return true
+	default:
+		return false
+	}
+}
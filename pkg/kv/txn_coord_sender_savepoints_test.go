@@ -0,0 +1,208 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// TestTxnCoordSenderRollbackToSavepointAfterError verifies that rolling back
+// to a savepoint only recovers a transaction from errors that are confined to
+// requests sent after the savepoint.
+func TestTxnCoordSenderRollbackToSavepointAfterError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	manual := hlc.NewManualClock(123)
+	clock := hlc.NewClock(manual.UnixNano, time.Nanosecond)
+
+	// Conditional puts fail with a ConditionFailedError, and writes to the
+	// "fail" key with an error of unknown consequences.
+	var senderFn client.SenderFunc = func(_ context.Context, ba roachpb.BatchRequest) (
+		*roachpb.BatchResponse, *roachpb.Error) {
+		txn := ba.Txn.Clone()
+		var pErr *roachpb.Error
+		if _, ok := ba.GetArg(roachpb.ConditionalPut); ok {
+			pErr = roachpb.NewError(&roachpb.ConditionFailedError{})
+		} else if ba.Requests[0].GetInner().Header().Key.Equal(roachpb.Key("fail")) {
+			pErr = roachpb.NewErrorf("injected error")
+		}
+		if pErr != nil {
+			pErr.SetTxn(&txn)
+			return nil, pErr
+		}
+		br := ba.CreateReply()
+		br.Txn = &txn
+		return br, nil
+	}
+	ambient := log.AmbientContext{Tracer: tracing.NewTracer()}
+	factory := NewTxnCoordSenderFactory(
+		TxnCoordSenderFactoryConfig{
+			AmbientCtx: ambient,
+			Clock:      clock,
+			Stopper:    stopper,
+		},
+		senderFn,
+	)
+	db := client.NewDB(ambient, factory, clock)
+	ctx := context.Background()
+
+	t.Run("condition failed", func(t *testing.T) {
+		txn := client.NewTxn(db, 0 /* gatewayNodeID */, client.RootTxn)
+		if err := txn.Put(ctx, "a", "value"); err != nil {
+			t.Fatal(err)
+		}
+		sp, err := txn.CreateSavepoint(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := txn.CPut(ctx, "b", "value", nil); !testutils.IsError(err, "unexpected value") {
+			t.Fatalf("expected condition failed error, got %v", err)
+		}
+		if err := txn.RollbackToSavepoint(ctx, sp); err != nil {
+			t.Fatal(err)
+		}
+		if err := txn.Put(ctx, "b", "value"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("unknown error", func(t *testing.T) {
+		txn := client.NewTxn(db, 0 /* gatewayNodeID */, client.RootTxn)
+		sp, err := txn.CreateSavepoint(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := txn.Put(ctx, "fail", "value"); !testutils.IsError(err, "injected error") {
+			t.Fatalf("expected injected error, got %v", err)
+		}
+		if err := txn.RollbackToSavepoint(ctx, sp); !testutils.IsError(err,
+			"cannot roll back to savepoint after an error that may have affected the rest of the transaction",
+		) {
+			t.Fatalf("expected rollback to be rejected, got %v", err)
+		}
+		if err := txn.Put(ctx, "b", "value"); !testutils.IsError(err, "txn already encountered an error") {
+			t.Fatalf("expected transaction to remain in error, got %v", err)
+		}
+	})
+
+	t.Run("error before savepoint", func(t *testing.T) {
+		txn := client.NewTxn(db, 0 /* gatewayNodeID */, client.RootTxn)
+		if err := txn.CPut(ctx, "b", "value", nil); !testutils.IsError(err, "unexpected value") {
+			t.Fatalf("expected condition failed error, got %v", err)
+		}
+		// Savepoints can't be created once the transaction is in error, so
+		// construct one at the current position, as if the failed request had
+		// been sent before it.
+		tc := txn.Sender().(*TxnCoordSender)
+		tc.mu.Lock()
+		sp := &savepoint{
+			txnID:  tc.mu.txn.ID,
+			epoch:  tc.mu.txn.Epoch,
+			seqNum: tc.interceptorAlloc.txnSeqNumAllocator.seqNumCounter,
+		}
+		tc.mu.Unlock()
+		if err := txn.RollbackToSavepoint(ctx, sp); !testutils.IsError(err,
+			"cannot roll back to savepoint created after the transaction encountered an error",
+		) {
+			t.Fatalf("expected rollback to be rejected, got %v", err)
+		}
+		if err := txn.Put(ctx, "b", "value"); !testutils.IsError(err, "txn already encountered an error") {
+			t.Fatalf("expected transaction to remain in error, got %v", err)
+		}
+	})
+}
+
+// TestTxnCoordSenderRollbackToSavepointRevertsNewIntents verifies that rolling
+// back to a savepoint only reverts the intents written since the savepoint.
+func TestTxnCoordSenderRollbackToSavepointRevertsNewIntents(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	manual := hlc.NewManualClock(123)
+	clock := hlc.NewClock(manual.UnixNano, time.Nanosecond)
+
+	var reverted []string
+	var senderFn client.SenderFunc = func(_ context.Context, ba roachpb.BatchRequest) (
+		*roachpb.BatchResponse, *roachpb.Error) {
+		for _, ru := range ba.Requests {
+			if req, ok := ru.GetInner().(*roachpb.ResolveIntentRequest); ok {
+				reverted = append(reverted, string(req.Key))
+			}
+		}
+		txn := ba.Txn.Clone()
+		br := ba.CreateReply()
+		br.Txn = &txn
+		return br, nil
+	}
+	ambient := log.AmbientContext{Tracer: tracing.NewTracer()}
+	factory := NewTxnCoordSenderFactory(
+		TxnCoordSenderFactoryConfig{
+			AmbientCtx: ambient,
+			Clock:      clock,
+			Stopper:    stopper,
+		},
+		senderFn,
+	)
+	db := client.NewDB(ambient, factory, clock)
+	ctx := context.Background()
+
+	txn := client.NewTxn(db, 0 /* gatewayNodeID */, client.RootTxn)
+	put := func(key string) {
+		if err := txn.Put(ctx, key, "value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	savepoint := func() client.SavepointToken {
+		sp, err := txn.CreateSavepoint(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sp
+	}
+	rollback := func(sp client.SavepointToken, expected ...string) {
+		reverted = nil
+		if err := txn.RollbackToSavepoint(ctx, sp); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(reverted, expected) {
+			t.Fatalf("expected %v to be reverted, got %v", expected, reverted)
+		}
+	}
+
+	put("a")
+	sp1 := savepoint()
+	put("b")
+	sp2 := savepoint()
+	put("c")
+	put("d")
+	rollback(sp2, "c", "d")
+	put("e")
+	rollback(sp2, "e")
+	rollback(sp1, "b")
+	rollback(sp1)
+}
@@ -58,6 +58,19 @@ type txnIntentCollector struct {
 	// intentsSizeBytes is the size in bytes of the intent spans in the
 	// meta, maintained to efficiently check the threshold.
 	intentsSizeBytes int64
+
+	// savepointIntents logs the intent spans in the order in which they
+	// were added, once the transaction has created a savepoint. Savepoints
+	// remember a position in the log so that rolling back to them only
+	// needs to revert the spans written since. The log starts at position
+	// savepointIntentsBase; earlier spans were dropped when the log grew
+	// too large or the transaction's epoch was bumped.
+	savepointIntents     []roachpb.Span
+	savepointIntentsBase int
+	// savepointIntentsSizeBytes is the size in bytes of savepointIntents.
+	savepointIntentsSizeBytes int64
+	// trackSavepoints is set once the first savepoint has been created.
+	trackSavepoints bool
 }
 
 // SendLocked implements the lockedSender interface.
@@ -130,6 +143,9 @@ func (ic *txnIntentCollector) augmentMetaLocked(meta roachpb.TxnCoordMeta) {
 	copy(newIntents, ic.intents)
 	copy(newIntents[len(ic.intents):], meta.Intents)
 	ic.intents, _ = roachpb.MergeSpans(newIntents)
+	for _, span := range meta.Intents {
+		ic.logSavepointIntent(span)
+	}
 	// Recompute the size of the intents.
 	ic.intentsSizeBytes = 0
 	for _, i := range ic.intents {
@@ -138,8 +154,10 @@ func (ic *txnIntentCollector) augmentMetaLocked(meta roachpb.TxnCoordMeta) {
 }
 
 // epochBumpedLocked implements the txnInterceptor interface.
-func (*txnIntentCollector) epochBumpedLocked() {
-	// No-op. Intents are tracked cumulatively across epochs on retries.
+func (ic *txnIntentCollector) epochBumpedLocked() {
+	// Intents are tracked cumulatively across epochs on retries. Savepoints
+	// don't survive an epoch bump though, so their log can be dropped.
+	ic.dropSavepointIntents()
 }
 
 // closeLocked implements the txnInterceptor interface.
@@ -154,6 +172,7 @@ func (ic *txnIntentCollector) appendAndCondenseIntents(
 	ba.IntentSpanIterate(br, func(span roachpb.Span) {
 		ic.intents = append(ic.intents, span)
 		ic.intentsSizeBytes += int64(len(span.Key) + len(span.EndKey))
+		ic.logSavepointIntent(span)
 	})
 	if condensedIntents, condensedIntentsSize, err := ic.maybeCondenseIntentSpans(
 		ctx, ic.intents, ic.intentsSizeBytes,
@@ -249,3 +268,57 @@ func (ic *txnIntentCollector) maybeCondenseIntentSpans(
 
 	return spans, spansSize, nil
 }
+
+// logSavepointIntent appends the span to the savepoint log, if the
+// transaction has created savepoints. If the log grows beyond the size
+// allowed for the transaction's intents, it is dropped; rolling back to a
+// savepoint created before that falls back to reverting all intents.
+func (ic *txnIntentCollector) logSavepointIntent(span roachpb.Span) {
+	if !ic.trackSavepoints {
+		return
+	}
+	ic.savepointIntents = append(ic.savepointIntents, span)
+	ic.savepointIntentsSizeBytes += int64(len(span.Key) + len(span.EndKey))
+	if ic.savepointIntentsSizeBytes > maxTxnIntentsBytes.Get(&ic.st.SV) {
+		ic.dropSavepointIntents()
+	}
+}
+
+// dropSavepointIntents empties the savepoint log. Spans are logged from the
+// log's current end on; savepoints created before fall back to reverting all
+// the transaction's intents.
+func (ic *txnIntentCollector) dropSavepointIntents() {
+	ic.savepointIntentsBase += len(ic.savepointIntents)
+	ic.savepointIntents = nil
+	ic.savepointIntentsSizeBytes = 0
+}
+
+// savepointMarkLocked returns the current position in the savepoint log, to
+// be passed to intentsSinceSavepointLocked when rolling back to a savepoint
+// created now.
+func (ic *txnIntentCollector) savepointMarkLocked() int {
+	ic.trackSavepoints = true
+	return ic.savepointIntentsBase + len(ic.savepointIntents)
+}
+
+// intentsSinceSavepointLocked returns the merged spans of the intents written
+// since the savepoint log was at the given position, and drops them from the
+// log; the caller is expected to revert them. If the log no longer goes back
+// to the position, all the transaction's intents are returned.
+func (ic *txnIntentCollector) intentsSinceSavepointLocked(mark int) []roachpb.Span {
+	var spans []roachpb.Span
+	if mark < ic.savepointIntentsBase || mark > ic.savepointIntentsBase+len(ic.savepointIntents) {
+		spans = append(spans, ic.intents...)
+		ic.savepointIntents = nil
+		ic.savepointIntentsBase = mark
+	} else {
+		spans = append(spans, ic.savepointIntents[mark-ic.savepointIntentsBase:]...)
+		ic.savepointIntents = ic.savepointIntents[:mark-ic.savepointIntentsBase]
+	}
+	ic.savepointIntentsSizeBytes = 0
+	for _, span := range ic.savepointIntents {
+		ic.savepointIntentsSizeBytes += int64(len(span.Key) + len(span.EndKey))
+	}
+	spans, _ = roachpb.MergeSpans(spans)
+	return spans
+}
@@ -763,6 +763,7 @@ func (t Transaction) Clone() Transaction {
 	// Note that we're not cloning the span keys under the assumption that the
 	// keys themselves are not mutable.
 	t.Intents = append([]Span(nil), t.Intents...)
+	t.IgnoredSeqNums = append([]enginepb.IgnoredSeqNumRange(nil), t.IgnoredSeqNums...)
 	return t
 }
 
@@ -882,6 +883,9 @@ func (t *Transaction) Restart(
 	t.WriteTooOld = false
 	t.RetryOnPush = false
 	t.Sequence = 0
+	// Savepoints don't survive a restart, and neither do the sequence numbers
+	// that were rolled back to them.
+	t.IgnoredSeqNums = nil
 	// Reset Writing. Since we're using a new epoch, we don't care about the abort
 	// cache.
 	t.Writing = false
@@ -944,6 +948,12 @@ func (t *Transaction) Update(o *Transaction) {
 
 	if t.Epoch < o.Epoch {
 		t.Epoch = o.Epoch
+		t.IgnoredSeqNums = o.IgnoredSeqNums
+	} else if t.Epoch == o.Epoch &&
+		lastIgnoredSeqNum(t.IgnoredSeqNums) < lastIgnoredSeqNum(o.IgnoredSeqNums) {
+		// Within an epoch, the ignored ranges only ever extend to higher
+		// sequence numbers.
+		t.IgnoredSeqNums = o.IgnoredSeqNums
 	}
 
 	t.Timestamp.Forward(o.Timestamp)
@@ -976,6 +986,30 @@ func (t *Transaction) Update(o *Transaction) {
 	}
 }
 
+// AddIgnoredSeqNumRange marks the writes with sequence numbers in the given
+// range as rolled back. Any existing ranges that are contained in the new
+// range are subsumed by it, keeping the list sorted and non-overlapping.
+func (t *Transaction) AddIgnoredSeqNumRange(newRange enginepb.IgnoredSeqNumRange) {
+	i := len(t.IgnoredSeqNums)
+	for i > 0 && t.IgnoredSeqNums[i-1].Start >= newRange.Start {
+		i--
+	}
+	// Copy the list instead of modifying it in place, as it may be shared
+	// with requests that are still in flight.
+	ignored := make([]enginepb.IgnoredSeqNumRange, i, i+1)
+	copy(ignored, t.IgnoredSeqNums[:i])
+	t.IgnoredSeqNums = append(ignored, newRange)
+}
+
+// lastIgnoredSeqNum returns the highest sequence number contained in the
+// given ignored ranges, or zero if there are none.
+func lastIgnoredSeqNum(ignored []enginepb.IgnoredSeqNumRange) int32 {
+	if len(ignored) == 0 {
+		return 0
+	}
+	return ignored[len(ignored)-1].End
+}
+
 // UpgradePriority sets transaction priority to the maximum of current
 // priority and the specified minPriority. The exception is if the
 // current priority is set to the minimum, in which case the minimum
@@ -1375,9 +1409,10 @@ func AsIntents(spans []Span, txn *Transaction) []Intent {
 	ret := make([]Intent, len(spans))
 	for i := range spans {
 		ret[i] = Intent{
-			Span:   spans[i],
-			Txn:    txn.TxnMeta,
-			Status: txn.Status,
+			Span:           spans[i],
+			Txn:            txn.TxnMeta,
+			Status:         txn.Status,
+			IgnoredSeqNums: txn.IgnoredSeqNums,
 		}
 	}
 	return ret
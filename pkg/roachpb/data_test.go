@@ -496,6 +496,7 @@ var nonZeroTxn = Transaction{
 	Intents:                  []Span{{Key: []byte("a"), EndKey: []byte("b")}},
 	EpochZeroTimestamp:       makeTS(1, 1),
 	OrigTimestampWasObserved: true,
+	IgnoredSeqNums:           []enginepb.IgnoredSeqNumRange{{Start: 10, End: 12}},
 }
 
 func TestTransactionUpdate(t *testing.T) {
@@ -562,6 +563,59 @@ func TestTransactionClone(t *testing.T) {
 	}
 }
 
+func TestTransactionAddIgnoredSeqNumRange(t *testing.T) {
+	type r = enginepb.IgnoredSeqNumRange
+	testData := []struct {
+		list     []r
+		newRange r
+		exp      []r
+	}{
+		{nil, r{Start: 1, End: 2}, []r{{Start: 1, End: 2}}},
+		{[]r{{Start: 1, End: 2}}, r{Start: 4, End: 5}, []r{{Start: 1, End: 2}, {Start: 4, End: 5}}},
+		{[]r{{Start: 1, End: 2}, {Start: 4, End: 5}}, r{Start: 4, End: 7}, []r{{Start: 1, End: 2}, {Start: 4, End: 7}}},
+		{[]r{{Start: 3, End: 3}, {Start: 4, End: 5}}, r{Start: 2, End: 7}, []r{{Start: 2, End: 7}}},
+	}
+	for _, tc := range testData {
+		txn := Transaction{IgnoredSeqNums: tc.list}
+		orig := append([]r(nil), tc.list...)
+		txn.AddIgnoredSeqNumRange(tc.newRange)
+		if !reflect.DeepEqual(tc.exp, txn.IgnoredSeqNums) {
+			t.Errorf("adding %v to %v: expected %v, got %v", tc.newRange, tc.list, tc.exp, txn.IgnoredSeqNums)
+		}
+		// The original list must not have been modified.
+		if !reflect.DeepEqual(orig, tc.list) {
+			t.Errorf("adding %v modified the original list: %v", tc.newRange, tc.list)
+		}
+	}
+}
+
+func TestTransactionUpdateIgnoredSeqNums(t *testing.T) {
+	txn := MakeTransaction("test", Key("a"), NormalUserPriority, enginepb.SERIALIZABLE,
+		makeTS(10, 0), 0 /* maxOffsetNs */)
+	stale := txn.Clone()
+	txn.AddIgnoredSeqNumRange(enginepb.IgnoredSeqNumRange{Start: 2, End: 3})
+
+	// An update with a stale list doesn't undo a rollback.
+	txn.Update(&stale)
+	if len(txn.IgnoredSeqNums) != 1 {
+		t.Fatalf("expected the ignored seqnums to be kept, got %v", txn.IgnoredSeqNums)
+	}
+
+	// An update with a newer list replaces it.
+	newer := txn.Clone()
+	newer.AddIgnoredSeqNumRange(enginepb.IgnoredSeqNumRange{Start: 5, End: 6})
+	txn.Update(&newer)
+	if !reflect.DeepEqual(newer.IgnoredSeqNums, txn.IgnoredSeqNums) {
+		t.Fatalf("expected %v, got %v", newer.IgnoredSeqNums, txn.IgnoredSeqNums)
+	}
+
+	// Restarting forgets about the savepoints of the previous epoch.
+	txn.Restart(0, 0, txn.Timestamp)
+	if len(txn.IgnoredSeqNums) != 0 {
+		t.Fatalf("expected no ignored seqnums after restart, got %v", txn.IgnoredSeqNums)
+	}
+}
+
 // checkVal verifies if a value is close to an expected value, within a fraction (e.g. if
 // fraction=0.1, it checks if val is within 10% of expected).
 func checkVal(val, expected, errFraction float64) bool {
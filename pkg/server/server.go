@@ -587,6 +587,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 			internalExecutor,
 		),
 
+		NotificationBus: sql.NewNotificationBus(s.gossip, &s.nodeIDContainer),
+		PlanBaselines:   sql.NewPlanBaselines(s.gossip, internalExecutor),
+
 		ExecLogger: log.NewSecondaryLogger(
 			nil /* dirName */, "sql-exec", true /* enableGc */, false, /*forceSyncWrites*/
 		),
@@ -598,6 +601,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		ConnResultsBufferBytes: s.cfg.ConnResultsBufferBytes,
 	}
 
+	execCfg.StatsRefresher = stats.MakeRefresher(s.st, s.db, execCfg.TableStatsCache, s.jobRegistry)
+
 	if sqlSchemaChangerTestingKnobs := s.cfg.TestingKnobs.SQLSchemaChanger; sqlSchemaChangerTestingKnobs != nil {
 		execCfg.SchemaChangerTestingKnobs = sqlSchemaChangerTestingKnobs.(*sql.SchemaChangerTestingKnobs)
 	} else {
@@ -1505,6 +1510,8 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 		s.execCfg.DistSQLPlanner,
 	).Start(s.stopper)
 
+	sql.NewTemporaryObjectCleaner(s.cfg.AmbientCtx, s.execCfg, s.nodeLiveness).Start(s.stopper)
+
 	s.distSQLServer.Start()
 	s.pgServer.Start(ctx, s.stopper)
 
@@ -1579,6 +1586,12 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 		}
 	}
 
+	// Start the automatic statistics refresher, which relies on the job
+	// registry to run statistics jobs.
+	s.execCfg.StatsRefresher.Start(
+		s.AnnotateCtx(context.Background()), s.stopper, stats.DefaultRefreshInterval,
+	)
+
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
 	// We have to do this after actually starting up the server to be able to
@@ -1605,6 +1618,12 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 		}
 	}
 	log.Infof(ctx, "done ensuring all necessary migrations have run")
+
+	// Load the plan baselines, now that the migrations have created the table
+	// that stores them.
+	if err := s.execCfg.PlanBaselines.Start(ctx, s.stopper); err != nil {
+		return err
+	}
 	close(serveSQL)
 
 	log.Info(ctx, "serving sql connections")
@@ -26,6 +26,7 @@ import (
 	"golang.org/x/text/language"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -256,6 +257,9 @@ func (n *alterTableNode) startExec(params runParams) error {
 				if err != nil {
 					return err
 				}
+				if err := checkTemporaryReferences(n.tableDesc, affected); err != nil {
+					return err
+				}
 				descriptorChanged = true
 				for _, updated := range affected {
 					if err := params.p.saveNonmutationAndNotify(params.ctx, updated); err != nil {
@@ -325,6 +329,10 @@ func (n *alterTableNode) startExec(params runParams) error {
 			if n.tableDesc.PrimaryIndex.ContainsColumnID(col.ID) {
 				return fmt.Errorf("column %q is referenced by the primary key", col.Name)
 			}
+			if n.tableDesc.ValidatingNotNull(col.ID) {
+				return fmt.Errorf("NOT NULL constraint on column %q in the middle of being added, try again later",
+					col.Name)
+			}
 			for _, idx := range n.tableDesc.AllNonDropIndexes() {
 				// We automatically drop indexes on that column that only
 				// index that column (and no other columns). If CASCADE is
@@ -376,6 +384,19 @@ func (n *alterTableNode) startExec(params runParams) error {
 						containsThisColumn = true
 					}
 				}
+				// A partial index also depends on the columns referenced by
+				// its predicate.
+				if idx.IsPartial() {
+					predCols, err := n.tableDesc.PartialIndexPredicateColumnIDs(&idx)
+					if err != nil {
+						return err
+					}
+					for _, id := range predCols {
+						if id == col.ID {
+							containsThisColumn = true
+						}
+					}
+				}
 
 				// Perform the DROP.
 				if containsThisColumn {
@@ -651,6 +672,221 @@ func (n *alterTableNode) Next(runParams) (bool, error) { return false, nil }
 func (n *alterTableNode) Values() tree.Datums          { return tree.Datums{} }
 func (n *alterTableNode) Close(context.Context)        {}
 
+// addColumnTypeSwapMutations changes the type of col to newType by rewriting
+// its data. It adds a hidden shadow column of the new type, computed with the
+// USING expression of the command or else by converting the values of col,
+// and copies of the indexes on col rebuilt on top of the shadow column. Once
+// these are backfilled, the schema changer puts them in place of col and its
+// indexes in a single descriptor version and drops the originals.
+func addColumnTypeSwapMutations(
+	tableDesc *sqlbase.TableDescriptor,
+	col *sqlbase.ColumnDescriptor,
+	newType sqlbase.ColumnType,
+	t *tree.AlterTableAlterColumnType,
+	params runParams,
+) error {
+	if _, err := tableDesc.FindActiveColumnByID(col.ID); err != nil {
+		return fmt.Errorf("column %q in the middle of being added, try again later", col.Name)
+	}
+	if tableDesc.ValidatingNotNull(col.ID) {
+		return fmt.Errorf("NOT NULL constraint on column %q in the middle of being added, try again later",
+			col.Name)
+	}
+	if tableDesc.PrimaryIndex.ContainsColumnID(col.ID) {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot alter the type of column %q, which is part of the primary key", col.Name)
+	}
+	if col.IsComputed() {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot alter the type of computed column %q", col.Name)
+	}
+	if len(col.UsesSequenceIds) > 0 {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot alter the type of column %q, whose default uses a sequence", col.Name)
+	}
+	for _, ref := range tableDesc.DependedOnBy {
+		for _, colID := range ref.ColumnIDs {
+			if colID == col.ID {
+				return sqlbase.NewDependentObjectError(fmt.Sprintf(
+					"cannot alter the type of column %q because a view depends on it", col.Name))
+			}
+		}
+	}
+	for _, check := range tableDesc.Checks {
+		if used, err := check.UsesColumn(tableDesc, col.ID); err != nil {
+			return err
+		} else if used {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot alter the type of column %q, which is used by CHECK constraint %q",
+				col.Name, check.Name)
+		}
+	}
+	for _, c := range tableDesc.Columns {
+		if !c.IsComputed() {
+			continue
+		}
+		expr, err := parser.ParseExpr(*c.ComputeExpr)
+		if err != nil {
+			return err
+		}
+		_, colIDs, err := replaceVars(*tableDesc, expr)
+		if err != nil {
+			return err
+		}
+		if _, ok := colIDs[col.ID]; ok {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot alter the type of column %q, which is used by computed column %q",
+				col.Name, c.Name)
+		}
+	}
+	for _, m := range tableDesc.Mutations {
+		if idx := m.GetIndex(); idx != nil && idx.ContainsColumnID(col.ID) {
+			return fmt.Errorf("column %q is referenced by index %q in the middle of a schema change, try again later",
+				col.Name, idx.Name)
+		}
+	}
+
+	// The values of the shadow column.
+	var expr tree.Expr = &tree.ColumnItem{ColumnName: tree.Name(col.Name)}
+	switch {
+	case t.Using != nil:
+		expr = t.Using
+	case t.Collation != "":
+		expr = &tree.CollateExpr{Expr: expr, Locale: t.Collation}
+	case !col.Type.ToDatumType().Equivalent(newType.ToDatumType()):
+		expr = &tree.CastExpr{Expr: expr, Type: t.ToType, SyntaxMode: tree.CastShort}
+	}
+	if err := iterColDescriptorsInExpr(*tableDesc, expr, func(c sqlbase.ColumnDescriptor) error {
+		if c.IsComputed() {
+			return pgerror.NewError(pgerror.CodeInvalidColumnReferenceError,
+				"USING expression cannot reference computed columns")
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	replacedExpr, _, err := replaceVars(*tableDesc, expr)
+	if err != nil {
+		return err
+	}
+	if _, err := sqlbase.SanitizeVarFreeExpr(
+		replacedExpr, newType.ToDatumType(), "USING", &params.p.semaCtx, params.EvalContext(), false, /* allowImpure */
+	); err != nil {
+		return err
+	}
+
+	// The column keeps its DEFAULT expression, which must therefore be valid
+	// for the new type.
+	if col.DefaultExpr != nil {
+		defaultExpr, err := parser.ParseExpr(*col.DefaultExpr)
+		if err != nil {
+			return err
+		}
+		if _, err := sqlbase.SanitizeVarFreeExpr(
+			defaultExpr, newType.ToDatumType(), "DEFAULT", &params.p.semaCtx, params.EvalContext(), true, /* allowImpure */
+		); err != nil {
+			return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"default for column %q cannot be converted to type %s", col.Name, newType.SQLString())
+		}
+	}
+
+	computeExpr := tree.Serialize(expr)
+	newCol := sqlbase.ColumnDescriptor{
+		Name:        makeShadowName(tableDesc, col.Name),
+		ID:          tableDesc.NextColumnID,
+		Type:        newType,
+		Nullable:    col.Nullable,
+		Hidden:      true,
+		ComputeExpr: &computeExpr,
+	}
+	tableDesc.NextColumnID++
+	tableDesc.AddColumnMutation(newCol, sqlbase.DescriptorMutation_ADD)
+
+	// Rebuild the secondary indexes that contain the column.
+	var oldIndexIDs, newIndexIDs []sqlbase.IndexID
+	for _, idx := range tableDesc.Indexes {
+		if idx.IsPartial() {
+			predCols, err := tableDesc.PartialIndexPredicateColumnIDs(&idx)
+			if err != nil {
+				return err
+			}
+			for _, id := range predCols {
+				if id == col.ID {
+					return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+						"cannot alter the type of column %q, which is used by the predicate of index %q",
+						col.Name, idx.Name)
+				}
+			}
+		}
+		if !idx.ContainsColumnID(col.ID) {
+			continue
+		}
+		if idx.ForeignKey.IsSet() || len(idx.ReferencedBy) > 0 {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot alter the type of column %q, which is used by a foreign key constraint", col.Name)
+		}
+		if len(idx.Interleave.Ancestors) > 0 || len(idx.InterleavedBy) > 0 {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot alter the type of column %q, which is used by interleaved index %q",
+				col.Name, idx.Name)
+		}
+		if idx.Partitioning.NumColumns > 0 {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot alter the type of column %q, which is used by partitioned index %q",
+				col.Name, idx.Name)
+		}
+
+		newIdx := idx
+		newIdx.ID = tableDesc.NextIndexID
+		tableDesc.NextIndexID++
+		newIdx.Name = makeShadowName(tableDesc, idx.Name)
+		newIdx.ColumnNames = append([]string(nil), idx.ColumnNames...)
+		newIdx.ColumnIDs = append([]sqlbase.ColumnID(nil), idx.ColumnIDs...)
+		for i := range newIdx.ColumnIDs {
+			if newIdx.ColumnIDs[i] == col.ID {
+				newIdx.ColumnIDs[i] = newCol.ID
+				newIdx.ColumnNames[i] = newCol.Name
+			}
+		}
+		newIdx.StoreColumnNames = append([]string(nil), idx.StoreColumnNames...)
+		for i := range newIdx.StoreColumnNames {
+			if newIdx.StoreColumnNames[i] == col.Name {
+				newIdx.StoreColumnNames[i] = newCol.Name
+			}
+		}
+		// These are recomputed when IDs are allocated.
+		newIdx.ExtraColumnIDs = nil
+		newIdx.StoreColumnIDs = nil
+		newIdx.CompositeColumnIDs = nil
+		if err := tableDesc.AddIndexMutation(newIdx, sqlbase.DescriptorMutation_ADD); err != nil {
+			return err
+		}
+		oldIndexIDs = append(oldIndexIDs, idx.ID)
+		newIndexIDs = append(newIndexIDs, newIdx.ID)
+	}
+
+	tableDesc.AddColumnTypeSwapMutation(col.ID, newCol.ID, oldIndexIDs, newIndexIDs)
+	return nil
+}
+
+// makeShadowName returns a name derived from the given one that is used by
+// none of the columns and indexes of the table.
+func makeShadowName(tableDesc *sqlbase.TableDescriptor, name string) string {
+	for i := 0; ; i++ {
+		shadowName := name + "_crdb_internal_new_type"
+		if i > 0 {
+			shadowName = fmt.Sprintf("%s_%d", shadowName, i)
+		}
+		if _, _, err := tableDesc.FindColumnByName(tree.Name(shadowName)); err == nil {
+			continue
+		}
+		if _, _, err := tableDesc.FindIndexByName(shadowName); err == nil {
+			continue
+		}
+		return shadowName
+	}
+}
+
 // applyColumnMutation applies the mutation specified in `mut` to the given
 // columnDescriptor, and saves the containing table descriptor. If the column's
 // dependencies on sequences change, it updates them as well.
@@ -689,6 +925,12 @@ func applyColumnMutation(
 			return err
 		}
 
+		if t.Using != nil {
+			// The USING expression determines the new values, whatever the
+			// conversion between the two types would be.
+			return addColumnTypeSwapMutations(tableDesc, col, nextType, t, params)
+		}
+
 		// No-op if the types are Equal.  We don't use Equivalent here
 		// because the user may want to change the visible type of the
 		// column without changing the underlying semantic type.
@@ -712,7 +954,7 @@ func applyColumnMutation(
 		case schemachange.ColumnConversionTrivial:
 			col.Type = nextType
 		default:
-			return pgerror.Unimplemented("alter column type", "type conversion not yet implemented")
+			return addColumnTypeSwapMutations(tableDesc, col, nextType, t, params)
 		}
 
 	case *tree.AlterTableSetDefault:
@@ -752,7 +994,22 @@ func applyColumnMutation(
 			}
 		}
 
+	case *tree.AlterTableSetNotNull:
+		if !col.Nullable || tableDesc.ValidatingNotNull(col.ID) {
+			return nil
+		}
+		if _, err := tableDesc.FindActiveColumnByID(col.ID); err != nil {
+			return fmt.Errorf("column %q in the middle of being added, try again later", col.Name)
+		}
+		// The column becomes non-nullable once the schema changer has verified
+		// that it contains no NULLs.
+		tableDesc.AddNotNullMutation(col.ID)
+
 	case *tree.AlterTableDropNotNull:
+		if tableDesc.ValidatingNotNull(col.ID) {
+			return fmt.Errorf("NOT NULL constraint on column %q in the middle of being added, try again later",
+				col.Name)
+		}
 		col.Nullable = true
 
 	case *tree.AlterTableDropStored:
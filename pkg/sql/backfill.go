@@ -114,6 +114,8 @@ func (sc *SchemaChanger) runBackfill(
 	// mutations. Collect the elements that are part of the mutation.
 	var droppedIndexDescs []sqlbase.IndexDescriptor
 	var addedIndexDescs []sqlbase.IndexDescriptor
+	var viewRefreshes []*sqlbase.MaterializedViewRefresh
+	var notNullColIDs []sqlbase.ColumnID
 	// Indexes within the Mutations slice for checkpointing.
 	mutationSentinel := -1
 	var droppedIndexMutationIdx int
@@ -149,6 +151,13 @@ func (sc *SchemaChanger) runBackfill(
 				}
 			case *sqlbase.DescriptorMutation_Index:
 				addedIndexDescs = append(addedIndexDescs, *t.Index)
+			case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+				viewRefreshes = append(viewRefreshes, t.MaterializedViewRefresh)
+			case *sqlbase.DescriptorMutation_ColumnTypeSwap:
+				// Nothing to backfill; the shadow column and indexes are
+				// backfilled by their own mutations.
+			case *sqlbase.DescriptorMutation_NotNullConstraint:
+				notNullColIDs = append(notNullColIDs, t.NotNullConstraint.ColumnID)
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -162,6 +171,17 @@ func (sc *SchemaChanger) runBackfill(
 				if droppedIndexMutationIdx == mutationSentinel {
 					droppedIndexMutationIdx = i
 				}
+			case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+				// The refresh was rolled back; discard whatever it had written.
+				droppedIndexDescs = append(droppedIndexDescs, t.MaterializedViewRefresh.NewPrimaryIndex)
+				if droppedIndexMutationIdx == mutationSentinel {
+					droppedIndexMutationIdx = i
+				}
+			case *sqlbase.DescriptorMutation_ColumnTypeSwap:
+				// The swap was rolled back; the shadow column and indexes are
+				// dropped by their own mutations.
+			case *sqlbase.DescriptorMutation_NotNullConstraint:
+				// The constraint was rolled back; the column stays nullable.
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -173,7 +193,7 @@ func (sc *SchemaChanger) runBackfill(
 	// Drop indexes.
 	if len(droppedIndexDescs) > 0 {
 		if err := sc.truncateIndexes(
-			ctx, lease, version, droppedIndexDescs, droppedIndexMutationIdx,
+			ctx, evalCtx, lease, version, droppedIndexDescs, droppedIndexMutationIdx,
 		); err != nil {
 			return err
 		}
@@ -200,9 +220,78 @@ func (sc *SchemaChanger) runBackfill(
 		}
 	}
 
+	// Recompute materialized views.
+	for _, refresh := range viewRefreshes {
+		if err := sc.refreshMaterializedView(
+			ctx, evalCtx, lease, version, tableDesc, refresh,
+		); err != nil {
+			return err
+		}
+	}
+
+	// Validate the existing rows against the NOT NULL constraints being added.
+	// Every node enforces the constraints on writes by the time the backfill
+	// runs, so no new violations can appear behind the scan.
+	for _, colID := range notNullColIDs {
+		if err := sc.ExtendLease(ctx, lease); err != nil {
+			return err
+		}
+		if err := validateNotNull(ctx, sc.execCfg.InternalExecutor, tableDesc, colID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// refreshMaterializedView recomputes the contents of a materialized view into
+// the new primary index of a refresh mutation. The view query is evaluated in
+// a single transaction, with the privileges of the user that issued the
+// refresh, and its result is written in chunks.
+func (sc *SchemaChanger) refreshMaterializedView(
+	ctx context.Context,
+	evalCtx *extendedEvalContext,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	version sqlbase.DescriptorVersion,
+	tableDesc *sqlbase.TableDescriptor,
+	refresh *sqlbase.MaterializedViewRefresh,
+) error {
+	// The rows are written through a copy of the descriptor whose primary
+	// index is the one being backfilled.
+	newDesc := *tableDesc
+	newDesc.PrimaryIndex = refresh.NewPrimaryIndex
+	newDesc.Mutations = nil
+
+	// The query of the view is run in a single transaction, so that the
+	// view reflects a consistent snapshot, while its result rows are
+	// streamed into the new primary index in chunks, each written in its
+	// own transaction.
+	chunkSize := int(sc.getChunkSize(indexBackfillChunkSize))
+	return sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		// Clear anything written by a previous attempt at this refresh,
+		// including a previous attempt of this transaction.
+		if err := sc.truncateIndexes(
+			ctx, evalCtx, lease, version, []sqlbase.IndexDescriptor{refresh.NewPrimaryIndex}, 0,
+		); err != nil {
+			return err
+		}
+		return streamMaterializedViewRows(
+			ctx, sc.execCfg, txn, &MemoryMetrics{}, "refresh-materialized-view",
+			refresh.User, tableDesc.ViewQuery, chunkSize,
+			func(rows []tree.Datums) error {
+				if err := sc.ExtendLease(ctx, lease); err != nil {
+					return err
+				}
+				return sc.db.Txn(ctx, func(ctx context.Context, writeTxn *client.Txn) error {
+					return insertMaterializedViewRows(
+						ctx, writeTxn, &evalCtx.EvalContext, &newDesc, rows, false, /* traceKV */
+					)
+				})
+			},
+		)
+	})
+}
+
 func (sc *SchemaChanger) getTableVersion(
 	ctx context.Context, txn *client.Txn, tc *TableCollection, version sqlbase.DescriptorVersion,
 ) (*sqlbase.TableDescriptor, error) {
@@ -218,6 +307,7 @@ func (sc *SchemaChanger) getTableVersion(
 
 func (sc *SchemaChanger) truncateIndexes(
 	ctx context.Context,
+	evalCtx *extendedEvalContext,
 	lease *sqlbase.TableDescriptor_SchemaChangeLease,
 	version sqlbase.DescriptorVersion,
 	dropped []sqlbase.IndexDescriptor,
@@ -259,13 +349,13 @@ func (sc *SchemaChanger) truncateIndexes(
 				}
 
 				rd, err := sqlbase.MakeRowDeleter(
-					txn, tableDesc, nil, nil, sqlbase.SkipFKs, nil /* *tree.EvalContext */, alloc,
+					txn, tableDesc, nil, nil, sqlbase.SkipFKs, &evalCtx.EvalContext, alloc,
 				)
 				if err != nil {
 					return err
 				}
 				td := tableDeleter{rd: rd, alloc: alloc}
-				if err := td.init(txn, nil /* *tree.EvalContext */); err != nil {
+				if err := td.init(txn, &evalCtx.EvalContext); err != nil {
 					return err
 				}
 				resume, err = td.deleteIndex(
@@ -617,7 +707,8 @@ func runSchemaChangesInTxn(
 	// Only needed because columnBackfillInTxn() backfills
 	// all column mutations.
 	doneColumnBackfill := false
-	for _, m := range tableDesc.Mutations {
+	mutations := tableDesc.Mutations
+	for _, m := range mutations {
 		switch m.Direction {
 		case sqlbase.DescriptorMutation_ADD:
 			switch m.Descriptor_.(type) {
@@ -631,7 +722,18 @@ func runSchemaChangesInTxn(
 				doneColumnBackfill = true
 
 			case *sqlbase.DescriptorMutation_Index:
-				if err := indexBackfillInTxn(ctx, txn, tableDesc, traceKV); err != nil {
+				if err := indexBackfillInTxn(ctx, txn, evalCtx, tableDesc, traceKV); err != nil {
+					return err
+				}
+
+			case *sqlbase.DescriptorMutation_ColumnTypeSwap:
+				// Completing the mutation below queues up the removal of the
+				// replaced column and indexes, which is run afterwards.
+
+			case *sqlbase.DescriptorMutation_NotNullConstraint:
+				if err := validateNotNullInTxn(
+					ctx, txn, tableDesc, m.GetNotNullConstraint().ColumnID,
+				); err != nil {
 					return err
 				}
 
@@ -652,10 +754,17 @@ func runSchemaChangesInTxn(
 				doneColumnBackfill = true
 
 			case *sqlbase.DescriptorMutation_Index:
-				if err := indexTruncateInTxn(ctx, txn, execCfg, tableDesc, traceKV); err != nil {
+				if err := indexTruncateInTxn(ctx, txn, execCfg, evalCtx, tableDesc, traceKV); err != nil {
 					return err
 				}
 
+			case *sqlbase.DescriptorMutation_ColumnTypeSwap:
+				// Nothing to do; the shadow column and indexes are dropped by
+				// their own mutations.
+
+			case *sqlbase.DescriptorMutation_NotNullConstraint:
+				// Nothing to do; the column stays nullable.
+
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -663,6 +772,11 @@ func runSchemaChangesInTxn(
 		}
 		tableDesc.MakeMutationComplete(m)
 	}
+	// Completing a mutation can queue up new ones, which are run in turn.
+	tableDesc.Mutations = tableDesc.Mutations[len(mutations):]
+	if len(tableDesc.Mutations) > 0 {
+		return runSchemaChangesInTxn(ctx, txn, tc, execCfg, evalCtx, tableDesc, traceKV)
+	}
 	tableDesc.Mutations = nil
 
 	return nil
@@ -724,10 +838,14 @@ func columnBackfillInTxn(
 }
 
 func indexBackfillInTxn(
-	ctx context.Context, txn *client.Txn, tableDesc *sqlbase.TableDescriptor, traceKV bool,
+	ctx context.Context,
+	txn *client.Txn,
+	evalCtx *tree.EvalContext,
+	tableDesc *sqlbase.TableDescriptor,
+	traceKV bool,
 ) error {
 	var backfiller backfill.IndexBackfiller
-	if err := backfiller.Init(*tableDesc); err != nil {
+	if err := backfiller.Init(evalCtx, *tableDesc); err != nil {
 		return err
 	}
 	sp := tableDesc.PrimaryIndexSpan()
@@ -746,6 +864,7 @@ func indexTruncateInTxn(
 	ctx context.Context,
 	txn *client.Txn,
 	execCfg *ExecutorConfig,
+	evalCtx *tree.EvalContext,
 	tableDesc *sqlbase.TableDescriptor,
 	traceKV bool,
 ) error {
@@ -754,13 +873,13 @@ func indexTruncateInTxn(
 	var sp roachpb.Span
 	for done := false; !done; done = sp.Key == nil {
 		rd, err := sqlbase.MakeRowDeleter(
-			txn, tableDesc, nil, nil, sqlbase.SkipFKs, nil /* *tree.EvalContext */, alloc,
+			txn, tableDesc, nil, nil, sqlbase.SkipFKs, evalCtx, alloc,
 		)
 		if err != nil {
 			return err
 		}
 		td := tableDeleter{rd: rd, alloc: alloc}
-		if err := td.init(txn, nil /* *tree.EvalContext */); err != nil {
+		if err := td.init(txn, evalCtx); err != nil {
 			return err
 		}
 		sp, err = td.deleteIndex(
@@ -230,6 +230,11 @@ func (cb *ColumnBackfiller) RunColumnBackfillChunk(
 			if j < len(cb.added) && !cb.added[j].Nullable && val == tree.DNull {
 				return roachpb.Key{}, sqlbase.NewNonNullViolationError(cb.added[j].Name)
 			}
+			if j < len(cb.added) {
+				if err := sqlbase.CheckValueWidth(cb.added[j].Type, val, cb.added[j].Name); err != nil {
+					return roachpb.Key{}, err
+				}
+			}
 			updateValues[j] = val
 		}
 		copy(oldValues, datums)
@@ -286,13 +291,16 @@ type IndexBackfiller struct {
 	added []sqlbase.IndexDescriptor
 	// colIdxMap maps ColumnIDs to indices into desc.Columns and desc.Mutations.
 	colIdxMap map[sqlbase.ColumnID]int
+	// partialIndexes is used to only backfill the rows of partial indexes
+	// that satisfy their predicates.
+	partialIndexes sqlbase.PartialIndexPredicates
 
 	types   []sqlbase.ColumnType
 	rowVals tree.Datums
 }
 
 // Init initializes an IndexBackfiller.
-func (ib *IndexBackfiller) Init(desc sqlbase.TableDescriptor) error {
+func (ib *IndexBackfiller) Init(evalCtx *tree.EvalContext, desc sqlbase.TableDescriptor) error {
 	numCols := len(desc.Columns)
 	cols := desc.Columns
 	if len(desc.Mutations) > 0 {
@@ -314,14 +322,31 @@ func (ib *IndexBackfiller) Init(desc sqlbase.TableDescriptor) error {
 		if IndexMutationFilter(m) {
 			idx := m.GetIndex()
 			ib.added = append(ib.added, *idx)
+			var predCols []sqlbase.ColumnID
+			if idx.IsPartial() {
+				var err error
+				if predCols, err = desc.PartialIndexPredicateColumnIDs(idx); err != nil {
+					return err
+				}
+			}
 			for i, col := range cols {
 				if idx.ContainsColumnID(col.ID) {
 					valNeededForCol.Add(i)
 				}
+				for _, id := range predCols {
+					if id == col.ID {
+						valNeededForCol.Add(i)
+					}
+				}
 			}
 		}
 	}
 
+	var err error
+	if ib.partialIndexes, err = sqlbase.MakePartialIndexPredicates(&desc, ib.added, evalCtx); err != nil {
+		return err
+	}
+
 	ib.types = make([]sqlbase.ColumnType, len(cols))
 	for i := range cols {
 		ib.types[i] = cols[i].Type
@@ -397,7 +422,20 @@ func (ib *IndexBackfiller) BuildIndexEntriesChunk(
 			ib.rowVals, buffer); err != nil {
 			return nil, nil, err
 		}
-		entries = append(entries, buffer...)
+		if ib.partialIndexes.Empty() {
+			entries = append(entries, buffer...)
+			continue
+		}
+		// Leave out the entries of the partial indexes whose predicate the row
+		// does not satisfy.
+		if err := ib.partialIndexes.FilterIndexEntries(ib.colIdxMap, ib.rowVals, buffer); err != nil {
+			return nil, nil, err
+		}
+		for i := range buffer {
+			if buffer[i].Key != nil {
+				entries = append(entries, buffer[i])
+			}
+		}
 	}
 	return entries, ib.fetcher.Key(), nil
 }
@@ -22,10 +22,13 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -73,6 +76,80 @@ func (p *planner) validateCheckExpr(
 	return nil
 }
 
+// validateNotNull returns an error if the column with the given ID contains
+// NULLs. The table is scanned by a query run through the internal executor,
+// which distributes it like any other query.
+func validateNotNull(
+	ctx context.Context,
+	ie *InternalExecutor,
+	tableDesc *sqlbase.TableDescriptor,
+	colID sqlbase.ColumnID,
+) error {
+	col, err := tableDesc.FindActiveColumnByID(colID)
+	if err != nil {
+		return err
+	}
+	row, err := ie.QueryRow(ctx, "validate-not-null", nil, /* txn */
+		fmt.Sprintf(`SELECT 1 FROM [%d(%d) AS t (c)] WHERE c IS NULL LIMIT 1`, tableDesc.ID, colID),
+	)
+	if err != nil {
+		return err
+	}
+	if row != nil {
+		return newNotNullValidationError(col.Name)
+	}
+	return nil
+}
+
+// validateNotNullInTxn is like validateNotNull, for a table created in the
+// transaction txn. Such a table cannot be queried by name or ID yet, so its
+// primary index is scanned directly.
+func validateNotNullInTxn(
+	ctx context.Context, txn *client.Txn, tableDesc *sqlbase.TableDescriptor, colID sqlbase.ColumnID,
+) error {
+	col, err := tableDesc.FindActiveColumnByID(colID)
+	if err != nil {
+		return err
+	}
+	var valNeededForCol util.FastIntSet
+	valNeededForCol.Add(0)
+	var rf sqlbase.RowFetcher
+	tableArgs := sqlbase.RowFetcherTableArgs{
+		Desc:            tableDesc,
+		Index:           &tableDesc.PrimaryIndex,
+		ColIdxMap:       map[sqlbase.ColumnID]int{colID: 0},
+		Cols:            []sqlbase.ColumnDescriptor{*col},
+		ValNeededForCol: valNeededForCol,
+	}
+	if err := rf.Init(
+		false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, &sqlbase.DatumAlloc{}, tableArgs,
+	); err != nil {
+		return err
+	}
+	if err := rf.StartScan(
+		ctx, txn, roachpb.Spans{tableDesc.PrimaryIndexSpan()}, true /* limit batches */, 0, false, /* traceKV */
+	); err != nil {
+		return err
+	}
+	for {
+		datums, _, _, err := rf.NextRowDecoded(ctx)
+		if err != nil {
+			return err
+		}
+		if datums == nil {
+			return nil
+		}
+		if datums[0] == tree.DNull {
+			return newNotNullValidationError(col.Name)
+		}
+	}
+}
+
+func newNotNullValidationError(colName string) error {
+	return pgerror.NewErrorf(pgerror.CodeNotNullViolationError,
+		"validation of NOT NULL constraint failed: column %q contains null values", colName)
+}
+
 func (p *planner) validateForeignKey(
 	ctx context.Context, srcTable *sqlbase.TableDescriptor, srcIdx *sqlbase.IndexDescriptor,
 ) error {
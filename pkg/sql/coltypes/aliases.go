@@ -124,6 +124,11 @@ var (
 	// JSONB is an immutable T instance.
 	JSONB = &TJSON{Name: "JSONB"}
 
+	// TSVector is an immutable T instance.
+	TSVector = &TTSVector{}
+	// TSQuery is an immutable T instance.
+	TSQuery = &TTSQuery{}
+
 	// Oid is an immutable T instance.
 	Oid = &TOid{Name: "OID"}
 	// RegClass is an immutable T instance.
@@ -68,6 +68,8 @@ func (*TTimestamp) columnType()      {}
 func (*TTimestampTZ) columnType()    {}
 func (*TInterval) columnType()       {}
 func (*TJSON) columnType()           {}
+func (*TTSVector) columnType()       {}
+func (*TTSQuery) columnType()        {}
 func (*TUUID) columnType()           {}
 func (*TIPAddr) columnType()         {}
 func (*TString) columnType()         {}
@@ -91,6 +93,8 @@ func (*TTimestamp) castTargetType()      {}
 func (*TTimestampTZ) castTargetType()    {}
 func (*TInterval) castTargetType()       {}
 func (*TJSON) castTargetType()           {}
+func (*TTSVector) castTargetType()       {}
+func (*TTSQuery) castTargetType()        {}
 func (*TUUID) castTargetType()           {}
 func (*TIPAddr) castTargetType()         {}
 func (*TString) castTargetType()         {}
@@ -113,6 +117,8 @@ func (node *TTimestamp) String() string      { return ColTypeAsString(node) }
 func (node *TTimestampTZ) String() string    { return ColTypeAsString(node) }
 func (node *TInterval) String() string       { return ColTypeAsString(node) }
 func (node *TJSON) String() string           { return ColTypeAsString(node) }
+func (node *TTSVector) String() string       { return ColTypeAsString(node) }
+func (node *TTSQuery) String() string        { return ColTypeAsString(node) }
 func (node *TUUID) String() string           { return ColTypeAsString(node) }
 func (node *TIPAddr) String() string         { return ColTypeAsString(node) }
 func (node *TString) String() string         { return ColTypeAsString(node) }
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -441,14 +442,15 @@ func (sp sessionParams) sessionData(
 		curDb = sessiondata.DefaultDatabaseName
 	}
 	sd := sessiondata.SessionData{
-		ApplicationName: sp.args.ApplicationName,
-		Database:        curDb,
-		DistSQLMode:     sessiondata.DistSQLExecMode(DistSQLClusterExecMode.Get(&settings.SV)),
-		OptimizerMode:   sessiondata.OptimizerMode(OptimizerClusterMode.Get(&settings.SV)),
-		SearchPath:      sqlbase.DefaultSearchPath,
-		User:            sp.args.User,
-		RemoteAddr:      sp.args.RemoteAddr,
-		SequenceState:   sessiondata.NewSequenceState(),
+		ApplicationName:   sp.args.ApplicationName,
+		Database:          curDb,
+		DistSQLMode:       sessiondata.DistSQLExecMode(DistSQLClusterExecMode.Get(&settings.SV)),
+		OptimizerMode:     sessiondata.OptimizerMode(OptimizerClusterMode.Get(&settings.SV)),
+		ReorderJoinsLimit: xform.DefaultReorderJoinsLimit,
+		SearchPath:        sqlbase.DefaultSearchPath,
+		User:              sp.args.User,
+		RemoteAddr:        sp.args.RemoteAddr,
+		SequenceState:     sessiondata.NewSequenceState(),
 		DataConversion: sessiondata.DataConversionConfig{
 			Location: time.UTC,
 		},
@@ -531,6 +533,7 @@ func (s *Server) newConnExecutor(
 		dbCacheSubscriber: s.dbCache,
 	}
 	ex.extraTxnState.txnRewindPos = -1
+	ex.notificationListener.comm = clientComm
 
 	ex.mu.ActiveQueries = make(map[ClusterWideID]*queryMeta)
 	ex.machine = fsm.MakeMachine(TxnStateTransitions, stateNoTxn{}, &ex.state)
@@ -702,6 +705,9 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		if err := ex.machine.ApplyWithPayload(ctx, ev, payload); err != nil {
 			log.Warningf(ctx, "error while cleaning up connExecutor: %s", err)
 		}
+		// A transaction left in the Aborted state might still have its KV txn
+		// open, waiting for a ROLLBACK TO SAVEPOINT.
+		ex.state.cleanupRetainedTxn()
 	} else if closeType == externalTxnClose {
 		ex.state.finishExternalTxn()
 	}
@@ -710,6 +716,25 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		log.Warningf(ctx, "error while cleaning up connExecutor: %s", err)
 	}
 
+	if bus := ex.server.cfg.NotificationBus; bus != nil {
+		bus.unlistenAll(&ex.notificationListener)
+	}
+
+	if closeType != externalTxnClose {
+		// The session's temporary tables go away with it, also when it is
+		// closed because of a panic: the tables are dropped in a new
+		// transaction that does not depend on the session's state. Tables
+		// left behind because the node crashed, or because this fails, are
+		// dropped by the TemporaryObjectCleaner. The session's context may
+		// be canceled already, so a fresh one is used.
+		cleanupCtx := ex.server.cfg.AmbientCtx.AnnotateCtx(context.Background())
+		if err := cleanupSessionTemporarySchemas(
+			cleanupCtx, ex.server.cfg, &ex.extraTxnState.tables.temporarySchemas,
+		); err != nil {
+			log.Warningf(ctx, "error while dropping temporary tables: %s", err)
+		}
+	}
+
 	if closeType != panicClose {
 		// Close all statements and prepared portals by first unifying the namespaces
 		// and the closing what remains.
@@ -785,6 +810,10 @@ type connExecutor struct {
 	// "internal" SQL executors.
 	stmtCounterDisabled bool
 
+	// notificationListener registers the session with the NotificationBus
+	// for the channels it listens on.
+	notificationListener notificationListener
+
 	// extraTxnState groups fields scoped to a SQL txn that are not handled by
 	// ex.state, above. The rule of thumb is that, if the state influences state
 	// transitions, it should live in state, otherwise it can live here.
@@ -800,11 +829,25 @@ type connExecutor struct {
 		// is done if the statement was executed in an implicit txn).
 		schemaChangers schemaChangerCollection
 
+		// notifications accumulates the effects of the LISTEN, UNLISTEN and
+		// NOTIFY statements of the transaction, which are applied once it
+		// commits.
+		notifications txnNotifications
+
+		// cursors holds the cursors declared by the transaction, and the ones
+		// created for its suspended portals. They are closed when the
+		// transaction ends.
+		cursors cursorMap
+
 		// autoRetryCounter keeps track of the which iteration of a transaction
 		// auto-retry we're currently in. It's 0 whenever the transaction state is not
 		// stateOpen.
 		autoRetryCounter int
 
+		// numDDL counts the DDL statements executed by the current transaction.
+		// It's used to prevent ROLLBACK TO SAVEPOINT over DDL statements.
+		numDDL int
+
 		// txnRewindPos is the position within stmtBuf to which we'll rewind when
 		// performing automatic retries. This is more or less the position where the
 		// current transaction started.
@@ -980,12 +1023,19 @@ func (ex *connExecutor) resetExtraTxnState(
 	ctx context.Context, dbCacheHolder *databaseCacheHolder,
 ) error {
 	ex.extraTxnState.schemaChangers.reset()
+	ex.extraTxnState.notifications.reset()
+	ex.extraTxnState.cursors.closeAll(ctx)
 
 	ex.extraTxnState.tables.releaseTables(ctx)
 
 	ex.extraTxnState.tables.databaseCache = dbCacheHolder.getDatabaseCache()
 
 	ex.extraTxnState.autoRetryCounter = 0
+	ex.extraTxnState.numDDL = 0
+
+	// Savepoints don't survive the restart of the KV txn, and we're not going to
+	// roll back to them after the txn finishes.
+	ex.state.savepoints = nil
 	return nil
 }
 
@@ -1028,6 +1078,8 @@ func (ex *connExecutor) run(ctx context.Context, cancel context.CancelFunc) erro
 	ex.ctxHolder.cancel = cancel
 
 	ex.sessionID = ex.generateID()
+	ex.extraTxnState.tables.temporarySchemas.init(ex.sessionID)
+	ex.dataMutator.SetTemporarySchemaName(temporarySchemaName(ex.sessionID))
 	ex.server.cfg.SessionRegistry.register(ex.sessionID, ex)
 	defer ex.server.cfg.SessionRegistry.deregister(ex.sessionID)
 
@@ -1123,9 +1175,16 @@ func (ex *connExecutor) run(ctx context.Context, cancel context.CancelFunc) erro
 				AST:           portal.Stmt.Statement,
 				ExpectedTypes: portal.Stmt.Columns,
 				AnonymizedStr: portal.Stmt.AnonymizedStr,
+				Prepared:      portal.Stmt,
 			}
 			ctx := withStatement(ex.Ctx(), ex.curStmt)
-			ev, payload, err = ex.execStmt(ctx, curStmt, stmtRes, pinfo, pos)
+			if ex.portalCanSuspend(portal.PreparedPortal) &&
+				(tcmd.Limit > 0 || ex.extraTxnState.cursors.get(tcmd.Name) != nil) {
+				ev, payload, err = ex.execSuspendablePortal(
+					ctx, tcmd.Name, portal.PreparedPortal, curStmt, pinfo, stmtRes, tcmd.Limit, pos)
+			} else {
+				ev, payload, err = ex.execStmt(ctx, curStmt, stmtRes, pinfo, pos)
+			}
 			if err != nil {
 				return err
 			}
@@ -1717,6 +1776,8 @@ func (ex *connExecutor) evalCtx(
 		EvalContext: tree.EvalContext{
 			Planner:       p,
 			Sequence:      p,
+			Notifier:      p,
+			PlanBaselines: p,
 			StmtTimestamp: stmtTS,
 
 			Txn:              txn,
@@ -1744,6 +1805,8 @@ func (ex *connExecutor) evalCtx(
 		DistSQLPlanner:  ex.server.cfg.DistSQLPlanner,
 		TxnModesSetter:  ex,
 		SchemaChangers:  &ex.extraTxnState.schemaChangers,
+		Notifications:   &ex.extraTxnState.notifications,
+		Cursors:         &ex.extraTxnState.cursors,
 		schemaAccessors: scInterface,
 	}
 }
@@ -1791,6 +1854,7 @@ func (ex *connExecutor) resetPlanner(
 	p.semaCtx = tree.MakeSemaContext(ex.sessionData.User == security.RootUser)
 	p.semaCtx.Location = &ex.sessionData.DataConversion.Location
 	p.semaCtx.SearchPath = ex.sessionData.SearchPath
+	p.semaCtx.FunctionResolver = p
 	p.semaCtx.AsOfTimestamp = nil
 
 	p.extendedEvalCtx = ex.evalCtx(ctx, p, stmtTS)
@@ -1859,6 +1923,9 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 		// Wait for the cache to reflect the dropped databases if any.
 		ex.extraTxnState.tables.waitForCacheToDropDatabases(ctx)
 
+		ex.extraTxnState.notifications.commit(
+			ex.Ctx(), ex.server.cfg.NotificationBus, &ex.notificationListener)
+
 		fallthrough
 	case txnRestart, txnAborted:
 		if err := ex.resetExtraTxnState(ex.Ctx(), ex.server.dbCache); err != nil {
@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -220,8 +221,11 @@ func (ex *connExecutor) execStmtInOpenState(
 		return ev, payload, nil
 
 	case *tree.ReleaseSavepoint:
-		if err := tree.ValidateRestartCheckpoint(s.Savepoint); err != nil {
-			return makeErrEvent(err)
+		if !tree.IsRestartSavepointName(s.Savepoint) {
+			if err := ex.execReleaseSavepointInOpenState(s); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
 		}
 		if !ex.machine.CurState().(stateOpen).RetryIntent.Get() {
 			return makeErrEvent(errSavepointNotUsed)
@@ -238,8 +242,11 @@ func (ex *connExecutor) execStmtInOpenState(
 		return ev, payload, nil
 
 	case *tree.Savepoint:
-		if err := tree.ValidateRestartCheckpoint(s.Name); err != nil {
-			return makeErrEvent(err)
+		if !tree.IsRestartSavepointName(s.Name) {
+			if err := ex.execSavepointInOpenState(ctx, s); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
 		}
 		// We want to disallow SAVEPOINTs to be issued after a transaction has
 		// started running. The client txn's statement count indicates how many
@@ -254,8 +261,14 @@ func (ex *connExecutor) execStmtInOpenState(
 		return eventRetryIntentSet{}, nil /* payload */, nil
 
 	case *tree.RollbackToSavepoint:
-		if err := tree.ValidateRestartCheckpoint(s.Savepoint); err != nil {
-			return makeErrEvent(err)
+		if !tree.IsRestartSavepointName(s.Savepoint) {
+			if os.ImplicitTxn.Get() {
+				return makeErrEvent(errNoTransactionInProgress)
+			}
+			if err := ex.rollbackToSavepoint(ctx, s.Savepoint); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
 		}
 		if !os.RetryIntent.Get() {
 			return makeErrEvent(errSavepointNotUsed)
@@ -264,6 +277,33 @@ func (ex *connExecutor) execStmtInOpenState(
 		res.ResetStmtType((*tree.Savepoint)(nil))
 		return eventTxnRestart{}, nil /* payload */, nil
 
+	case *tree.CopyTo:
+		// COPY ... TO STDOUT is executed as the corresponding query, whose rows
+		// are delivered to the client through the Copy-out subprotocol.
+		copyRes, ok := res.(CopyOutResult)
+		if !ok {
+			return makeErrEvent(pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+				"COPY TO STDOUT is not supported in this context"))
+		}
+		opts, err := parseCopyOptions(s.Options)
+		if err != nil {
+			return makeErrEvent(err)
+		}
+		copyRes.SetCopyOut(opts)
+		stmt.AST = copyToQuery(s)
+
+	case *tree.DeclareCursor:
+		// The cursor belongs to the transaction, so it can't be declared by an
+		// implicit one that would close it right away.
+		if os.ImplicitTxn.Get() {
+			return makeErrEvent(pgerror.NewError(pgerror.CodeNoActiveSQLTransactionError,
+				"DECLARE CURSOR can only be used in transaction blocks"))
+		}
+		if err := ex.execDeclareCursor(ctx, s, pinfo); err != nil {
+			return makeErrEvent(err)
+		}
+		return nil, nil, nil
+
 	case *tree.Prepare:
 		// This is handling the SQL statement "PREPARE". See execPrepare for
 		// handling of the protocol-level command for preparing statements.
@@ -305,6 +345,7 @@ func (ex *connExecutor) execStmtInOpenState(
 		stmt.AST = ps.Statement
 		stmt.ExpectedTypes = ps.Columns
 		stmt.AnonymizedStr = ps.AnonymizedStr
+		stmt.Prepared = ps.PreparedStatement
 		res.ResetStmtType(ps.Statement)
 
 		// Check again if the statement should be parallelized.
@@ -317,6 +358,10 @@ func (ex *connExecutor) execStmtInOpenState(
 	// For regular statements (the ones that get to this point), we don't return
 	// any event unless an an error happens.
 
+	if stmt.AST.StatementType() == tree.DDL {
+		ex.extraTxnState.numDDL++
+	}
+
 	var p *planner
 	stmtTS := ex.server.cfg.Clock.PhysicalTime()
 	// Only run statements asynchronously through the parallelize queue if the
@@ -847,10 +892,12 @@ func canFallbackFromOpt(err error, optMode sessiondata.OptimizerMode, stmt State
 	}
 
 	if optMode == sessiondata.OptimizerAlways {
-		// In Always mode we never fallback, with one exception: SET commands (or
-		// else we can't switch to another mode).
+		// In Always mode we never fallback, with two exceptions: SET commands (or
+		// else we can't switch to another mode), and mutations of tables that are
+		// undergoing a schema change (or else they would fail until the schema
+		// change completes).
 		_, isSetVar := stmt.AST.(*tree.SetVar)
-		return isSetVar
+		return isSetVar || pgerr.InternalCommand == optbuilder.MutationSchemaChangeFeature
 	}
 
 	// If the statement is EXPLAIN (OPT), then don't fallback (we want to return
@@ -1028,8 +1075,10 @@ func (ex *connExecutor) execStmtInNoTxnState(
 // execStmtInAbortedState executes a statement in a txn that's in state
 // Aborted or RestartWait. All statements result in error events except:
 // - COMMIT / ROLLBACK: aborts the current transaction.
-// - ROLLBACK TO SAVEPOINT / SAVEPOINT: reopens the current transaction,
-//   allowing it to be retried.
+// - ROLLBACK TO SAVEPOINT / SAVEPOINT cockroach_restart: reopens the current
+//   transaction, allowing it to be retried.
+// - ROLLBACK TO SAVEPOINT <name>: resumes the current transaction from the named
+//   savepoint, if that savepoint was active when the transaction was aborted.
 func (ex *connExecutor) execStmtInAbortedState(
 	ctx context.Context, stmt Statement, res RestrictedCommandResult,
 ) (fsm.Event, fsm.EventPayload) {
@@ -1060,12 +1109,11 @@ func (ex *connExecutor) execStmtInAbortedState(
 		default:
 			panic("unreachable")
 		}
-		if err := tree.ValidateRestartCheckpoint(spName); err != nil {
-			ev := eventNonRetriableErr{IsCommit: fsm.False}
-			payload := eventNonRetriableErrPayload{
-				err: err,
+		if !tree.IsRestartSavepointName(spName) {
+			if _, ok := s.(*tree.RollbackToSavepoint); ok && !inRestartWait {
+				return ex.rollbackToSavepointInAbortedState(ctx, spName)
 			}
-			return ev, payload
+			return makeAbortedStateErrEvent(inRestartWait)
 		}
 
 		if !(inRestartWait || ex.machine.CurState().(stateAborted).RetryIntent.Get()) {
@@ -1102,19 +1150,27 @@ func (ex *connExecutor) execStmtInAbortedState(
 			ex.transitionCtx)
 		return ev, payload
 	default:
-		ev := eventNonRetriableErr{IsCommit: fsm.False}
-		if inRestartWait {
-			payload := eventNonRetriableErrPayload{
-				err: sqlbase.NewTransactionAbortedError(
-					"Expected \"ROLLBACK TO SAVEPOINT COCKROACH_RESTART\"" /* customMsg */),
-			}
-			return ev, payload
-		}
+		return makeAbortedStateErrEvent(inRestartWait)
+	}
+}
+
+// makeAbortedStateErrEvent creates the error event for a statement that is not
+// allowed in the Aborted or RestartWait state.
+func makeAbortedStateErrEvent(
+	inRestartWait bool,
+) (fsm.Event, fsm.EventPayload) {
+	ev := eventNonRetriableErr{IsCommit: fsm.False}
+	if inRestartWait {
 		payload := eventNonRetriableErrPayload{
-			err: sqlbase.NewTransactionAbortedError("" /* customMsg */),
+			err: sqlbase.NewTransactionAbortedError(
+				"Expected \"ROLLBACK TO SAVEPOINT COCKROACH_RESTART\"" /* customMsg */),
 		}
 		return ev, payload
 	}
+	payload := eventNonRetriableErrPayload{
+		err: sqlbase.NewTransactionAbortedError("" /* customMsg */),
+	}
+	return ev, payload
 }
 
 // execStmtInCommitWaitState executes a statement in a txn that's in state
@@ -438,6 +438,10 @@ func (ex *connExecutor) deletePortal(ctx context.Context, name string) {
 	}
 	delete(ex.prepStmtsNamespace.portals, name)
 	delete(ex.prepStmtsNamespace.prepStmts[portalEntry.psName].portals, name)
+	// Close the cursor of the portal if its execution was suspended.
+	if c := ex.extraTxnState.cursors.get(name); c != nil && c.portal == portalEntry.PreparedPortal {
+		ex.extraTxnState.cursors.close(ctx, name)
+	}
 }
 
 func (ex *connExecutor) execDelPrepStmt(
@@ -0,0 +1,125 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/fsm"
+)
+
+// savepoint represents a SAVEPOINT established in a SQL transaction. The
+// special cockroach_restart savepoint (see tree.RestartSavepointName) is not
+// represented by one of these; it is tracked by the RetryIntent of the state
+// machine instead.
+type savepoint struct {
+	name string
+	// kvToken identifies the point in the KV txn that a ROLLBACK TO SAVEPOINT
+	// reverts to.
+	kvToken client.SavepointToken
+	// numDDL is the number of DDL statements that the transaction had executed
+	// when the savepoint was established. The effects of DDL statements are not
+	// all captured by the KV txn (e.g. leases and staged schema changes), so we
+	// refuse to roll back over them.
+	numDDL int
+}
+
+// savepointStack is the list of savepoints active in a SQL transaction, oldest
+// first.
+type savepointStack []savepoint
+
+// find returns the index of the most recent savepoint with the given name, or
+// -1 if there's no such savepoint.
+func (s savepointStack) find(name string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func errSavepointDoesNotExist(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeInvalidSavepointSpecificationError,
+		"savepoint %s does not exist", name)
+}
+
+var errRollbackToSavepointAfterDDL = pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+	"ROLLBACK TO SAVEPOINT not supported after DDL statements in the same savepoint")
+
+// execSavepointInOpenState establishes a new savepoint. Savepoints with the
+// same name as an existing one are allowed; the new one shadows the old one
+// until it is released.
+func (ex *connExecutor) execSavepointInOpenState(ctx context.Context, s *tree.Savepoint) error {
+	token, err := ex.state.mu.txn.CreateSavepoint(ctx)
+	if err != nil {
+		return err
+	}
+	ex.state.savepoints = append(ex.state.savepoints, savepoint{
+		name:    s.Name,
+		kvToken: token,
+		numDDL:  ex.extraTxnState.numDDL,
+	})
+	return nil
+}
+
+// execReleaseSavepointInOpenState destroys a savepoint, together with all the
+// savepoints established after it. The writes performed since the savepoint
+// was established remain part of the transaction.
+func (ex *connExecutor) execReleaseSavepointInOpenState(s *tree.ReleaseSavepoint) error {
+	idx := ex.state.savepoints.find(s.Savepoint)
+	if idx < 0 {
+		return errSavepointDoesNotExist(s.Savepoint)
+	}
+	ex.state.savepoints = ex.state.savepoints[:idx]
+	return nil
+}
+
+// rollbackToSavepoint discards the writes performed by the transaction since
+// the named savepoint was established. The savepoint itself remains active;
+// the savepoints established after it are destroyed.
+func (ex *connExecutor) rollbackToSavepoint(ctx context.Context, name string) error {
+	idx := ex.state.savepoints.find(name)
+	if idx < 0 {
+		return errSavepointDoesNotExist(name)
+	}
+	sp := &ex.state.savepoints[idx]
+	if ex.extraTxnState.numDDL > sp.numDDL {
+		return errRollbackToSavepointAfterDDL
+	}
+	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, sp.kvToken); err != nil {
+		return err
+	}
+	ex.state.savepoints = ex.state.savepoints[:idx+1]
+	return nil
+}
+
+// rollbackToSavepointInAbortedState handles a ROLLBACK TO SAVEPOINT for a
+// savepoint other than cockroach_restart in the Aborted state. If the savepoint
+// was active when the error that aborted the transaction happened, the KV txn
+// has been kept open and the transaction goes back to the Open state.
+func (ex *connExecutor) rollbackToSavepointInAbortedState(
+	ctx context.Context, name string,
+) (fsm.Event, fsm.EventPayload) {
+	if err := ex.rollbackToSavepoint(ctx, name); err != nil {
+		ev := eventNonRetriableErr{IsCommit: fsm.False}
+		payload := eventNonRetriableErrPayload{err: err}
+		return ev, payload
+	}
+	return eventSavepointRollback{}, nil
+}
@@ -204,18 +204,24 @@ var _ payloadWithError = eventRetriableErrPayload{}
 // cockroach_restart. It moves the state to CommitWait.
 type eventTxnReleased struct{}
 
+// eventSavepointRollback is generated in the Aborted state after a successful
+// ROLLBACK TO SAVEPOINT (for a savepoint other than cockroach_restart). It moves
+// the state back to Open.
+type eventSavepointRollback struct{}
+
 // payloadWithError is a common interface for the payloads that wrap an error.
 type payloadWithError interface {
 	errorCause() error
 }
 
-func (eventRetryIntentSet) Event()  {}
-func (eventTxnStart) Event()        {}
-func (eventTxnFinish) Event()       {}
-func (eventTxnRestart) Event()      {}
-func (eventNonRetriableErr) Event() {}
-func (eventRetriableErr) Event()    {}
-func (eventTxnReleased) Event()     {}
+func (eventRetryIntentSet) Event()    {}
+func (eventTxnStart) Event()          {}
+func (eventTxnFinish) Event()         {}
+func (eventTxnRestart) Event()        {}
+func (eventNonRetriableErr) Event()   {}
+func (eventRetriableErr) Event()      {}
+func (eventTxnReleased) Event()       {}
+func (eventSavepointRollback) Event() {}
 
 // TxnStateTransitions describe the transitions used by a connExecutor's
 // fsm.Machine. Args.Extended is a txnState, which is muted by the Actions.
@@ -321,9 +327,16 @@ var TxnStateTransitions = Compile(Pattern{
 			Next: stateAborted{RetryIntent: Var("retryIntent")},
 			Action: func(args Args) error {
 				ts := args.Extended.(*txnState)
+				ts.txnAbortCount.Inc(1)
+				if len(ts.savepoints) > 0 {
+					// Keep the KV txn open; the client might resume it with a ROLLBACK
+					// TO SAVEPOINT.
+					ts.retainedAbortErr = args.Payload.(payloadWithError).errorCause()
+					ts.setAdvanceInfo(skipBatch, noRewind, noEvent)
+					return nil
+				}
 				ts.mu.txn.CleanupOnError(ts.Ctx, args.Payload.(payloadWithError).errorCause())
 				ts.setAdvanceInfo(skipBatch, noRewind, txnAborted)
-				ts.txnAbortCount.Inc(1)
 				return nil
 			},
 		},
@@ -401,6 +414,7 @@ var TxnStateTransitions = Compile(Pattern{
 			Next:        stateNoTxn{},
 			Action: func(args Args) error {
 				ts := args.Extended.(*txnState)
+				ts.cleanupRetainedTxn()
 				ts.finishSQLTxn()
 				ts.setAdvanceInfo(
 					advanceOne, noRewind, args.Payload.(eventTxnFinishPayload).toEvent())
@@ -416,6 +430,16 @@ var TxnStateTransitions = Compile(Pattern{
 				return nil
 			},
 		},
+		eventSavepointRollback{}: {
+			Description: "ROLLBACK TO SAVEPOINT <name>",
+			Next:        stateOpen{ImplicitTxn: False, RetryIntent: Var("retryIntent")},
+			Action: func(args Args) error {
+				ts := args.Extended.(*txnState)
+				ts.retainedAbortErr = nil
+				ts.setAdvanceInfo(advanceOne, noRewind, noEvent)
+				return nil
+			},
+		},
 	},
 	stateAborted{RetryIntent: True}: {
 		// ROLLBACK TO SAVEPOINT. We accept this in the Aborted state for the
@@ -426,6 +450,7 @@ var TxnStateTransitions = Compile(Pattern{
 			Next:        stateOpen{ImplicitTxn: False, RetryIntent: True},
 			Action: func(args Args) error {
 				ts := args.Extended.(*txnState)
+				ts.cleanupRetainedTxn()
 				ts.finishSQLTxn()
 
 				payload := args.Payload.(eventTxnStartPayload)
@@ -605,6 +605,12 @@ type ClientComm interface {
 	// Flush delivers all the previous results to the client. The results might
 	// have been buffered, in which case this flushes the buffer.
 	Flush(pos CmdPos) error
+
+	// SendNotification delivers a notification received on a channel the
+	// session listens on to the client. It is called asynchronously with
+	// respect to the execution of commands, and must not block: the
+	// notification is sent once the session is not in a transaction.
+	SendNotification(n Notification)
 }
 
 // CommandResult represents the result of a statement. It which needs to be
@@ -614,10 +620,17 @@ type CommandResult interface {
 	CommandResultClose
 
 	// SetLimit is used when executing a portal to set a limit on the number of
-	// rows to be returned. We don't currently properly support this feature of
-	// the Postgres protocol; instead, we'll return an error if the number of rows
-	// produced is larger than this limit.
+	// rows to be returned. The execution of a query in an explicit transaction
+	// is suspended once the limit is reached (see SetPortalSuspended); in other
+	// cases, we'll return an error if the number of rows produced is larger
+	// than this limit.
 	SetLimit(n int)
+
+	// SetPortalSuspended is used when the execution of a portal was suspended
+	// after it returned the number of rows set by SetLimit. The client is
+	// told that more rows can be fetched by executing the portal again,
+	// instead of receiving the completion of the command.
+	SetPortalSuspended()
 }
 
 // CommandResultErrBase is the subset of CommandResult dealing with setting a
@@ -779,6 +792,17 @@ type CopyInResult interface {
 	ResultBase
 }
 
+// CopyOutResult is implemented by the statement results that can deliver their
+// rows through the Copy-out subprotocol, as needed by COPY ... TO STDOUT.
+type CopyOutResult interface {
+	RestrictedCommandResult
+
+	// SetCopyOut switches the result to the Copy-out subprotocol: the rows are
+	// sent to the client as CopyData messages encoded according to opts,
+	// instead of as DataRow messages. It needs to be called before SetColumns.
+	SetCopyOut(opts CopyOptions)
+}
+
 // ClientLock is an interface returned by ClientComm.lockCommunication(). It
 // represents a lock on the delivery of results to a SQL client. While such a
 // lock is used, no more results are delivered. The lock itself can be used to
@@ -908,6 +932,11 @@ func (r *bufferedCommandResult) SetLimit(limit int) {
 	}
 }
 
+// SetPortalSuspended is part of the CommandResult interface.
+func (r *bufferedCommandResult) SetPortalSuspended() {
+	panic("unimplemented")
+}
+
 // Close is part of the CommandResult interface.
 func (r *bufferedCommandResult) Close(TransactionStatusIndicator) {
 	if r.closeCallback != nil {
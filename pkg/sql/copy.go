@@ -17,13 +17,16 @@ package sql
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -43,6 +46,7 @@ import (
 //
 // Incoming data is buffered and batched; batches are turned into insertNodes
 // that are executed. INSERT privileges are required on the destination table.
+// The data can be sent either in the text or in the binary format.
 //
 // See: https://www.postgresql.org/docs/current/static/sql-copy.html
 // and: https://www.postgresql.org/docs/current/static/protocol-flow.html#PROTOCOL-COPY
@@ -50,6 +54,7 @@ type copyMachine struct {
 	table         tree.TableExpr
 	columns       tree.NameList
 	resultColumns sqlbase.ResultColumns
+	opts          CopyOptions
 	// buf is used to parse input data into rows. It also accumulates a partial
 	// row between protocol messages.
 	buf bytes.Buffer
@@ -58,6 +63,11 @@ type copyMachine struct {
 	// insertedRows keeps track of the total number of rows inserted by the
 	// machine.
 	insertedRows int
+	// binaryHeaderRead is set once the header of binary data has been consumed.
+	binaryHeaderRead bool
+	// binaryTrailerRead is set once the trailer of binary data has been
+	// consumed. Any data following it is ignored.
+	binaryTrailerRead bool
 	// rowsMemAcc accounts for memory used by `rows`.
 	rowsMemAcc mon.BoundAccount
 	// bufMemAcc accounts for memory used by `buf`; it is kept in sync with
@@ -92,10 +102,19 @@ func newCopyMachine(
 	execCfg *ExecutorConfig,
 	resetPlanner func(p *planner, txn *client.Txn, txnTS time.Time, stmtTS time.Time),
 ) (_ *copyMachine, retErr error) {
+	opts, err := parseCopyOptions(n.Options)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Format == CopyFormatCSV {
+		return nil, pgerror.Unimplemented("copy-from-csv",
+			"COPY FROM with the CSV format is not supported")
+	}
 	c := &copyMachine{
 		conn:    conn,
 		table:   &n.Table,
 		columns: n.Columns,
+		opts:    opts,
 		txnOpt:  txnOpt,
 		// The planner will be prepared before use.
 		p:            planner{execCfg: execCfg},
@@ -134,6 +153,27 @@ func newCopyMachine(
 	return c, nil
 }
 
+// copyToQuery returns the query whose results are sent to the client by a
+// COPY ... TO STDOUT statement.
+func copyToQuery(n *tree.CopyTo) *tree.Select {
+	if n.Statement != nil {
+		return n.Statement
+	}
+	exprs := tree.SelectExprs{tree.StarSelectExpr()}
+	if len(n.Columns) > 0 {
+		exprs = make(tree.SelectExprs, len(n.Columns))
+		for i, col := range n.Columns {
+			exprs[i] = tree.SelectExpr{Expr: tree.NewUnresolvedName(string(col))}
+		}
+	}
+	return &tree.Select{
+		Select: &tree.SelectClause{
+			Exprs: exprs,
+			From:  &tree.From{Tables: tree.TableExprs{&n.Table}},
+		},
+	}
+}
+
 // copyTxnOpt contains information about the transaction in which the copying
 // should take place. Can be empty, in which case the copyMachine is responsible
 // for managing its own transactions.
@@ -154,7 +194,11 @@ func (c *copyMachine) run(ctx context.Context) error {
 	defer c.bufMemAcc.Close(ctx)
 
 	// Send the message describing the columns to the client.
-	if err := c.conn.BeginCopyIn(ctx, c.resultColumns); err != nil {
+	format := pgwirebase.FormatText
+	if c.opts.Format == CopyFormatBinary {
+		format = pgwirebase.FormatBinary
+	}
+	if err := c.conn.BeginCopyIn(ctx, c.resultColumns, format); err != nil {
 		return err
 	}
 
@@ -203,12 +247,134 @@ Loop:
 const (
 	nullString = `\N`
 	lineDelim  = '\n'
+	fieldDelim = '\t'
 )
 
-var (
-	fieldDelim = []byte{'\t'}
+// CopyFormat identifies the format of the data transferred by a COPY
+// statement.
+type CopyFormat int
+
+const (
+	// CopyFormatText is the default format. Rows are separated by newlines and
+	// fields by a delimiter; special characters are escaped with backslashes.
+	CopyFormatText CopyFormat = iota
+	// CopyFormatCSV is the comma-separated values format.
+	CopyFormatCSV
+	// CopyFormatBinary is the binary format, in which values are encoded like
+	// binary pgwire parameters and results.
+	CopyFormatBinary
 )
 
+// CopyOptions are the options of a COPY statement.
+type CopyOptions struct {
+	Format CopyFormat
+	// Delimiter separates the fields of a row. Not used by the binary format.
+	Delimiter byte
+	// Null is the string representing NULL values. Not used by the binary
+	// format.
+	Null string
+	// Header is set if the first line of CSV data contains the names of the
+	// columns.
+	Header bool
+}
+
+// parseCopyOptions validates the options of a COPY statement and fills in the
+// defaults of the options that were not specified.
+func parseCopyOptions(kvOpts tree.KVOptions) (CopyOptions, error) {
+	var opts CopyOptions
+	var delimiter, null *string
+	seen := make(map[tree.Name]bool, len(kvOpts))
+	for _, kv := range kvOpts {
+		if seen[kv.Key] {
+			return opts, pgerror.NewError(pgerror.CodeSyntaxError, "conflicting or redundant options")
+		}
+		seen[kv.Key] = true
+
+		var val string
+		hasVal := kv.Value != nil
+		if hasVal {
+			s, ok := kv.Value.(*tree.StrVal)
+			if !ok {
+				return opts, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"invalid value for option %q: %s", kv.Key, kv.Value)
+			}
+			val = s.RawString()
+		}
+		switch kv.Key {
+		case "format":
+			switch val {
+			case "text":
+				opts.Format = CopyFormatText
+			case "csv":
+				opts.Format = CopyFormatCSV
+			case "binary":
+				opts.Format = CopyFormatBinary
+			default:
+				return opts, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+					"COPY format %q not recognized", val)
+			}
+		case "delimiter":
+			if !hasVal {
+				return opts, pgerror.NewError(pgerror.CodeSyntaxError, "delimiter requires a value")
+			}
+			delimiter = &val
+		case "null":
+			if !hasVal {
+				return opts, pgerror.NewError(pgerror.CodeSyntaxError, "null requires a value")
+			}
+			null = &val
+		case "header":
+			opts.Header = true
+			if hasVal {
+				b, err := tree.ParseDBool(val)
+				if err != nil {
+					return opts, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+						"header requires a Boolean value")
+				}
+				opts.Header = bool(*b)
+			}
+		default:
+			return opts, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"option %q not recognized", kv.Key)
+		}
+	}
+
+	switch opts.Format {
+	case CopyFormatText:
+		opts.Delimiter, opts.Null = fieldDelim, nullString
+	case CopyFormatCSV:
+		opts.Delimiter, opts.Null = ',', ""
+	case CopyFormatBinary:
+		if delimiter != nil || null != nil {
+			return opts, pgerror.NewError(pgerror.CodeSyntaxError,
+				"cannot specify DELIMITER or NULL in BINARY mode")
+		}
+	}
+	if opts.Header && opts.Format != CopyFormatCSV {
+		return opts, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"COPY HEADER available only in CSV mode")
+	}
+	if delimiter != nil {
+		if len(*delimiter) != 1 {
+			return opts, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+				"COPY delimiter must be a single one-byte character")
+		}
+		opts.Delimiter = (*delimiter)[0]
+		if opts.Delimiter == '\n' || opts.Delimiter == '\r' {
+			return opts, pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+				"COPY delimiter cannot be newline or carriage return")
+		}
+	}
+	if null != nil {
+		opts.Null = *null
+		if strings.ContainsAny(opts.Null, "\r\n") {
+			return opts, pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+				"COPY null representation cannot use newline or carriage return")
+		}
+	}
+	return opts, nil
+}
+
 // processCopyData buffers incoming data and, once the buffer fills up, inserts
 // the accumulated rows.
 //
@@ -236,7 +402,12 @@ func (c *copyMachine) processCopyData(
 		}
 	}
 	c.buf.WriteString(data)
-	for c.buf.Len() > 0 {
+	if c.opts.Format == CopyFormatBinary {
+		if err := c.processBinaryCopyData(ctx, final); err != nil {
+			return err
+		}
+	}
+	for c.opts.Format != CopyFormatBinary && c.buf.Len() > 0 {
 		line, err := c.buf.ReadBytes(lineDelim)
 		if err != nil {
 			if err != io.EOF {
@@ -356,14 +527,14 @@ func (c *copyMachine) insertRows(ctx context.Context) (retErr error) {
 
 func (c *copyMachine) addRow(ctx context.Context, line []byte) error {
 	var err error
-	parts := bytes.Split(line, fieldDelim)
+	parts := bytes.Split(line, []byte{c.opts.Delimiter})
 	if len(parts) != len(c.resultColumns) {
 		return fmt.Errorf("expected %d values, got %d", len(c.resultColumns), len(parts))
 	}
 	exprs := make(tree.Exprs, len(parts))
 	for i, part := range parts {
 		s := string(part)
-		if s == nullString {
+		if s == c.opts.Null {
 			exprs[i] = tree.DNull
 			continue
 		}
@@ -393,6 +564,11 @@ func (c *copyMachine) addRow(ctx context.Context, line []byte) error {
 
 		exprs[i] = d
 	}
+	return c.appendRow(ctx, exprs)
+}
+
+// appendRow adds a decoded row to the batch of rows to be inserted.
+func (c *copyMachine) appendRow(ctx context.Context, exprs tree.Exprs) error {
 	if err := c.rowsMemAcc.Grow(ctx, int64(unsafe.Sizeof(exprs))); err != nil {
 		return err
 	}
@@ -401,6 +577,135 @@ func (c *copyMachine) addRow(ctx context.Context, line []byte) error {
 	return nil
 }
 
+// binaryCopySignature is the signature at the beginning of data in the binary
+// COPY format.
+var binaryCopySignature = []byte("PGCOPY\n\377\r\n\000")
+
+// binaryCopyOIDsFlag is the bit in the flags field of the binary COPY header
+// indicating that the tuples contain OIDs.
+const binaryCopyOIDsFlag = 1 << 16
+
+// processBinaryCopyData consumes the binary COPY data accumulated in the
+// buffer. Tuples that haven't been received completely are left in the
+// buffer, to be processed once more data arrives.
+//
+// See: https://www.postgresql.org/docs/current/static/sql-copy.html#id-1.9.3.52.10.6
+func (c *copyMachine) processBinaryCopyData(ctx context.Context, final bool) error {
+	if c.binaryTrailerRead {
+		// The data following the trailer is ignored.
+		c.buf.Reset()
+		return nil
+	}
+	if !c.binaryHeaderRead {
+		data := c.buf.Bytes()
+		// The header consists of the signature, a 32-bit flags field and the
+		// 32-bit length of the header extension area, followed by the extension.
+		const fixedHeaderLen = 19
+		if len(data) < fixedHeaderLen {
+			if final {
+				return errBinaryCopyEOF
+			}
+			return nil
+		}
+		if !bytes.Equal(data[:len(binaryCopySignature)], binaryCopySignature) {
+			return pgerror.NewError(pgerror.CodeBadCopyFileFormatError,
+				"COPY file signature not recognized")
+		}
+		flags := binary.BigEndian.Uint32(data[11:])
+		if flags&binaryCopyOIDsFlag != 0 {
+			return pgerror.NewError(pgerror.CodeBadCopyFileFormatError,
+				"COPY data with OIDs is not supported")
+		}
+		extLen := int(binary.BigEndian.Uint32(data[15:]))
+		if len(data) < fixedHeaderLen+extLen {
+			if final {
+				return errBinaryCopyEOF
+			}
+			return nil
+		}
+		c.buf.Next(fixedHeaderLen + extLen)
+		c.binaryHeaderRead = true
+	}
+	for c.buf.Len() > 0 {
+		exprs, n, err := c.decodeBinaryTuple(c.buf.Bytes())
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			// The tuple is incomplete.
+			if final {
+				return errBinaryCopyEOF
+			}
+			return nil
+		}
+		c.buf.Next(n)
+		if exprs == nil {
+			c.binaryTrailerRead = true
+			c.buf.Reset()
+			return nil
+		}
+		for _, e := range exprs {
+			if err := c.rowsMemAcc.Grow(ctx, int64(e.(tree.Datum).Size())); err != nil {
+				return err
+			}
+		}
+		if err := c.appendRow(ctx, exprs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errBinaryCopyEOF = pgerror.NewError(pgerror.CodeBadCopyFileFormatError,
+	"unexpected EOF in COPY data")
+
+// decodeBinaryTuple decodes the tuple at the beginning of data. It returns the
+// number of bytes making up the tuple, or 0 if data doesn't contain the whole
+// tuple. If the tuple is the trailer marking the end of the data, the returned
+// row is nil.
+func (c *copyMachine) decodeBinaryTuple(data []byte) (tree.Exprs, int, error) {
+	if len(data) < 2 {
+		return nil, 0, nil
+	}
+	numFields := int16(binary.BigEndian.Uint16(data))
+	if numFields == -1 {
+		return nil, 2, nil
+	}
+	if int(numFields) != len(c.resultColumns) {
+		return nil, 0, pgerror.NewErrorf(pgerror.CodeBadCopyFileFormatError,
+			"expected %d values, got %d", len(c.resultColumns), numFields)
+	}
+	exprs := make(tree.Exprs, numFields)
+	pos := 2
+	for i := range exprs {
+		if len(data) < pos+4 {
+			return nil, 0, nil
+		}
+		fieldLen := int32(binary.BigEndian.Uint32(data[pos:]))
+		pos += 4
+		if fieldLen == -1 {
+			exprs[i] = tree.DNull
+			continue
+		}
+		if fieldLen < 0 {
+			return nil, 0, pgerror.NewErrorf(pgerror.CodeBadCopyFileFormatError,
+				"invalid field size %d", fieldLen)
+		}
+		if len(data) < pos+int(fieldLen) {
+			return nil, 0, nil
+		}
+		d, err := pgwirebase.DecodeOidDatum(
+			c.resultColumns[i].Typ.Oid(), pgwirebase.FormatBinary, data[pos:pos+int(fieldLen)],
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		pos += int(fieldLen)
+		exprs[i] = d
+	}
+	return exprs, pos, nil
+}
+
 // decodeCopy unescapes a single COPY field.
 //
 // See: https://www.postgresql.org/docs/9.5/static/sql-copy.html#AEN74432
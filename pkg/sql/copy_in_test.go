@@ -16,9 +16,11 @@ package sql_test
 
 import (
 	"context"
+	gosql "database/sql"
 	"fmt"
 	"math"
 	"math/rand"
+	"net"
 	"reflect"
 	"strconv"
 	"testing"
@@ -30,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/jackc/pgx"
 	"github.com/lib/pq"
 )
 
@@ -432,3 +435,94 @@ func TestCopyTransaction(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestCopyBinary verifies that COPY FROM STDIN accepts data in the binary
+// format. The pgx driver uses the binary format for its CopyFrom method.
+func TestCopyBinary(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	// Telling pgx about the test certs is not worth the trouble.
+	params.Insecure = true
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+		CREATE DATABASE d;
+		CREATE TABLE d.t (
+			i INT PRIMARY KEY,
+			s STRING NULL,
+			f FLOAT NULL,
+			b BOOL NULL
+		);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	host, ports, err := net.SplitHostPort(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(ports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := pgx.Connect(pgx.ConnConfig{
+		Host:     host,
+		Port:     uint16(port),
+		User:     "root",
+		Database: "d",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	rows := [][]interface{}{
+		{int64(1), "a", 1.5, true},
+		{int64(2), nil, nil, nil},
+		{int64(3), "tab\tnewline\n", -2.0, false},
+	}
+	n, err := conn.CopyFrom(pgx.Identifier{"t"}, []string{"i", "s", "f", "b"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(rows) {
+		t.Fatalf("expected %d rows to be copied, got %d", len(rows), n)
+	}
+
+	res, err := db.Query(`SELECT i, s, f, b FROM d.t ORDER BY i`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Close()
+	var i int
+	for ; res.Next(); i++ {
+		var id int64
+		var s gosql.NullString
+		var f gosql.NullFloat64
+		var b gosql.NullBool
+		if err := res.Scan(&id, &s, &f, &b); err != nil {
+			t.Fatal(err)
+		}
+		got := []interface{}{id, nil, nil, nil}
+		if s.Valid {
+			got[1] = s.String
+		}
+		if f.Valid {
+			got[2] = f.Float64
+		}
+		if b.Valid {
+			got[3] = b.Bool
+		}
+		if !reflect.DeepEqual(got, rows[i]) {
+			t.Fatalf("expected row %v, got %v", rows[i], got)
+		}
+	}
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(rows) {
+		t.Fatalf("expected %d rows, got %d", len(rows), i)
+	}
+}
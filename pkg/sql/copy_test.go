@@ -15,9 +15,20 @@
 package sql
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"reflect"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 )
 
 func TestDecodeCopy(t *testing.T) {
@@ -88,3 +99,173 @@ func TestDecodeCopy(t *testing.T) {
 		}
 	}
 }
+
+func TestParseCopyOptions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	str := func(s string) tree.Expr { return tree.NewStrVal(s) }
+	tests := []struct {
+		opts   tree.KVOptions
+		expect CopyOptions
+		err    string
+	}{
+		{
+			opts:   nil,
+			expect: CopyOptions{Format: CopyFormatText, Delimiter: '\t', Null: `\N`},
+		},
+		{
+			opts:   tree.KVOptions{{Key: "format", Value: str("csv")}},
+			expect: CopyOptions{Format: CopyFormatCSV, Delimiter: ',', Null: ""},
+		},
+		{
+			opts: tree.KVOptions{
+				{Key: "format", Value: str("csv")},
+				{Key: "header"},
+				{Key: "delimiter", Value: str("|")},
+				{Key: "null", Value: str("NULL")},
+			},
+			expect: CopyOptions{Format: CopyFormatCSV, Delimiter: '|', Null: "NULL", Header: true},
+		},
+		{
+			opts:   tree.KVOptions{{Key: "format", Value: str("csv")}, {Key: "header", Value: str("off")}},
+			expect: CopyOptions{Format: CopyFormatCSV, Delimiter: ',', Null: ""},
+		},
+		{
+			opts:   tree.KVOptions{{Key: "format", Value: str("binary")}},
+			expect: CopyOptions{Format: CopyFormatBinary},
+		},
+
+		// Error cases.
+
+		{
+			opts: tree.KVOptions{{Key: "format", Value: str("xml")}},
+			err:  `COPY format "xml" not recognized`,
+		},
+		{
+			opts: tree.KVOptions{{Key: "foo"}},
+			err:  `option "foo" not recognized`,
+		},
+		{
+			opts: tree.KVOptions{{Key: "format", Value: str("csv")}, {Key: "format", Value: str("csv")}},
+			err:  `conflicting or redundant options`,
+		},
+		{
+			opts: tree.KVOptions{{Key: "format", Value: str("binary")}, {Key: "delimiter", Value: str(",")}},
+			err:  `cannot specify DELIMITER or NULL in BINARY mode`,
+		},
+		{
+			opts: tree.KVOptions{{Key: "header"}},
+			err:  `COPY HEADER available only in CSV mode`,
+		},
+		{
+			opts: tree.KVOptions{{Key: "delimiter", Value: str("ab")}},
+			err:  `COPY delimiter must be a single one-byte character`,
+		},
+		{
+			opts: tree.KVOptions{{Key: "delimiter", Value: str("\n")}},
+			err:  `COPY delimiter cannot be newline or carriage return`,
+		},
+	}
+
+	for _, test := range tests {
+		opts, err := parseCopyOptions(test.opts)
+		if !testutils.IsError(err, test.err) {
+			t.Errorf("%s: expected error %q, got %v", &test.opts, test.err, err)
+			continue
+		}
+		if test.err == "" && opts != test.expect {
+			t.Errorf("%s: got %+v, expected %+v", &test.opts, opts, test.expect)
+		}
+	}
+}
+
+func TestProcessBinaryCopyData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+
+	appendInt16 := func(b []byte, v int16) []byte {
+		return append(b, byte(uint16(v)>>8), byte(v))
+	}
+	appendInt32 := func(b []byte, v int32) []byte {
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], uint32(v))
+		return append(b, buf[:]...)
+	}
+	appendField := func(b []byte, v []byte) []byte {
+		if v == nil {
+			return appendInt32(b, -1)
+		}
+		return append(appendInt32(b, int32(len(v))), v...)
+	}
+
+	// The data contains the header with a 3-byte extension, two rows and the
+	// trailer, followed by some garbage that is to be ignored.
+	var data []byte
+	data = append(data, binaryCopySignature...)
+	data = appendInt32(data, 0)
+	data = appendInt32(data, 3)
+	data = append(data, "ext"...)
+	data = appendInt16(data, 2)
+	data = appendField(data, []byte{0, 0, 0, 0, 0, 0, 0, 1})
+	data = appendField(data, []byte("a"))
+	data = appendInt16(data, 2)
+	data = appendField(data, []byte{0, 0, 0, 0, 0, 0, 0, 2})
+	data = appendField(data, nil)
+	data = appendInt16(data, -1)
+	data = append(data, "garbage"...)
+
+	expected := []tree.Exprs{
+		{tree.NewDInt(1), tree.NewDString("a")},
+		{tree.NewDInt(2), tree.DNull},
+	}
+
+	// Deliver the data in two messages split at every possible position.
+	for split := 0; split <= len(data); split++ {
+		monitor := mon.MakeUnlimitedMonitor(
+			ctx, "test", mon.MemoryResource, nil, nil, math.MaxInt64, st,
+		)
+		c := &copyMachine{
+			resultColumns: sqlbase.ResultColumns{{Typ: types.Int}, {Typ: types.String}},
+			opts:          CopyOptions{Format: CopyFormatBinary},
+			rowsMemAcc:    monitor.MakeBoundAccount(),
+		}
+		for i, msg := range [][]byte{data[:split], data[split:]} {
+			c.buf.Write(msg)
+			if err := c.processBinaryCopyData(ctx, i == 1 /* final */); err != nil {
+				t.Fatalf("split %d: %v", split, err)
+			}
+		}
+		if !reflect.DeepEqual(c.rows, expected) {
+			t.Fatalf("split %d: got %v, expected %v", split, c.rows, expected)
+		}
+		c.rowsMemAcc.Close(ctx)
+		monitor.Stop(ctx)
+	}
+
+	// Truncated data is an error.
+	for _, tc := range []struct {
+		data []byte
+		err  string
+	}{
+		{data: []byte("PGCOPY"), err: "unexpected EOF in COPY data"},
+		{data: bytes.Repeat([]byte("x"), 20), err: "COPY file signature not recognized"},
+		{data: data[:len(data)-20], err: "unexpected EOF in COPY data"},
+	} {
+		monitor := mon.MakeUnlimitedMonitor(
+			ctx, "test", mon.MemoryResource, nil, nil, math.MaxInt64, st,
+		)
+		c := &copyMachine{
+			resultColumns: sqlbase.ResultColumns{{Typ: types.Int}, {Typ: types.String}},
+			opts:          CopyOptions{Format: CopyFormatBinary},
+			rowsMemAcc:    monitor.MakeBoundAccount(),
+		}
+		c.buf.Write(tc.data)
+		if err := c.processBinaryCopyData(ctx, true /* final */); !testutils.IsError(err, tc.err) {
+			t.Errorf("expected error %q, got %v", tc.err, err)
+		}
+		c.rowsMemAcc.Close(ctx)
+		monitor.Stop(ctx)
+	}
+}
@@ -74,6 +74,7 @@ var crdbInternal = virtualSchema{
 		crdbInternalLocalSessionsTable,
 		crdbInternalLocalMetricsTable,
 		crdbInternalPartitionsTable,
+		crdbInternalPlanBaselinesTable,
 		crdbInternalRangesTable,
 		crdbInternalRuntimeInfoTable,
 		crdbInternalSchemaChangesTable,
@@ -303,6 +304,22 @@ CREATE TABLE crdb_internal.schema_changes (
 					mutType = "INDEX"
 					targetID = tree.NewDInt(tree.DInt(int64(d.Index.ID)))
 					targetName = tree.NewDString(d.Index.Name)
+				case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+					mutType = "REFRESH"
+					targetID = tree.NewDInt(tree.DInt(int64(d.MaterializedViewRefresh.NewPrimaryIndex.ID)))
+					targetName = tree.NewDString(d.MaterializedViewRefresh.NewPrimaryIndex.Name)
+				case *sqlbase.DescriptorMutation_ColumnTypeSwap:
+					mutType = "COLUMN TYPE"
+					targetID = tree.NewDInt(tree.DInt(int64(d.ColumnTypeSwap.OldColumnID)))
+					if col, err := table.FindColumnByID(d.ColumnTypeSwap.OldColumnID); err == nil {
+						targetName = tree.NewDString(col.Name)
+					}
+				case *sqlbase.DescriptorMutation_NotNullConstraint:
+					mutType = "NOT NULL"
+					targetID = tree.NewDInt(tree.DInt(int64(d.NotNullConstraint.ColumnID)))
+					if col, err := table.FindColumnByID(d.NotNullConstraint.ColumnID); err == nil {
+						targetName = tree.NewDString(col.Name)
+					}
 				}
 				if err := addRow(
 					tableID,
@@ -654,6 +671,33 @@ CREATE TABLE crdb_internal.session_trace (
 	},
 }
 
+// crdbInternalPlanBaselinesTable exposes the plan baselines created with
+// crdb_internal.create_plan_baseline().
+var crdbInternalPlanBaselinesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.plan_baselines (
+  database    STRING NOT NULL,
+  fingerprint STRING NOT NULL,
+  statement   STRING NOT NULL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.plan_baselines"); err != nil {
+			return err
+		}
+		for _, b := range p.ExecCfg().PlanBaselines.list() {
+			if err := addRow(
+				tree.NewDString(b.key.database),
+				tree.NewDString(b.key.fingerprint),
+				tree.NewDString(b.stmt),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalClusterSettingsTable exposes the list of current
 // cluster settings.
 var crdbInternalClusterSettingsTable = virtualSchemaTable{
@@ -1021,6 +1065,7 @@ CREATE TABLE crdb_internal.create_statements (
 		typeView := tree.NewDString("view")
 		typeTable := tree.NewDString("table")
 		typeSequence := tree.NewDString("sequence")
+		typeFunction := tree.NewDString("function")
 
 		return forEachTableDescWithTableLookupInternal(ctx, p, dbContext, virtualOnce, true, /*allowAdding*/
 			func(db *DatabaseDescriptor, scName string, table *TableDescriptor, lCtx tableLookupFn) error {
@@ -1041,6 +1086,9 @@ CREATE TABLE crdb_internal.create_statements (
 				} else if table.IsSequence() {
 					descType = typeSequence
 					stmt, err = p.showCreateSequence(ctx, (*tree.Name)(&table.Name), table)
+				} else if table.IsFunction() {
+					descType = typeFunction
+					stmt, err = p.showCreateFunction(ctx, (*tree.Name)(&table.Name), table)
 				} else {
 					descType = typeTable
 					tn := (*tree.Name)(&table.Name)
@@ -0,0 +1,369 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type createFunctionNode struct {
+	n      *tree.CreateFunction
+	dbDesc *sqlbase.DatabaseDescriptor
+	opts   *sqlbase.TableDescriptor_FunctionOpts
+}
+
+// CreateFunction creates a user-defined function.
+// Privileges: CREATE on database, and DROP on the function if it is
+// replaced.
+//   Notes: postgres requires CREATE on the schema and ownership of a
+//          replaced function.
+func (p *planner) CreateFunction(ctx context.Context, n *tree.CreateFunction) (planNode, error) {
+	name, err := n.Name.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var dbDesc *DatabaseDescriptor
+	p.runWithOptions(resolveFlags{skipCache: true}, func() {
+		dbDesc, err = ResolveTargetObject(ctx, p, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	opts, err := makeFunctionOpts(n)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.analyzeFunctionBody(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	return &createFunctionNode{
+		n:      n,
+		dbDesc: dbDesc,
+		opts:   opts,
+	}, nil
+}
+
+func (n *createFunctionNode) startExec(params runParams) error {
+	funcName := n.n.Name.TableName().Table()
+	tKey := tableKey{parentID: n.dbDesc.ID, name: funcName}
+	key := tKey.Key()
+
+	if n.n.Replace {
+		var existing *sqlbase.TableDescriptor
+		var err error
+		params.p.runWithOptions(resolveFlags{skipCache: true}, func() {
+			existing, err = ResolveExistingObject(
+				params.ctx, params.p, n.n.Name.TableName(), false /*required*/, anyDescType)
+		})
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return n.replaceFunction(params, existing)
+		}
+	}
+
+	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
+		return sqlbase.NewRelationAlreadyExistsError(tKey.Name())
+	} else if err != nil {
+		return err
+	}
+
+	id, err := GenerateUniqueDescID(params.ctx, params.p.ExecCfg().DB)
+	if err != nil {
+		return err
+	}
+
+	// Inherit permissions from the database descriptor.
+	privs := n.dbDesc.GetPrivileges()
+
+	desc := InitTableDescriptor(id, n.dbDesc.ID, funcName, params.p.txn.CommitTimestamp(), privs)
+	desc.FunctionOpts = n.opts
+
+	if err = desc.ValidateTable(params.EvalContext().Settings); err != nil {
+		return err
+	}
+
+	if err = params.p.createDescriptorWithID(params.ctx, key, id, &desc); err != nil {
+		return err
+	}
+
+	if err := desc.Validate(params.ctx, params.p.txn, params.extendedEvalCtx.Settings); err != nil {
+		return err
+	}
+
+	return n.logEvent(params, desc.ID)
+}
+
+// replaceFunction replaces the definition of an existing function. The
+// new version of the descriptor is published through the lease manager
+// like any other schema change.
+func (n *createFunctionNode) replaceFunction(
+	params runParams, existing *sqlbase.TableDescriptor,
+) error {
+	if !existing.IsFunction() {
+		return sqlbase.NewRelationAlreadyExistsError(existing.Name)
+	}
+	if err := params.p.CheckPrivilege(params.ctx, existing, privilege.DROP); err != nil {
+		return err
+	}
+	if !sameFunctionArguments(existing.FunctionOpts, n.opts) {
+		return pgerror.NewErrorf(pgerror.CodeDuplicateFunctionError,
+			"function %q already exists with different argument types", existing.Name)
+	}
+	if !existing.FunctionOpts.ReturnType.Equal(n.opts.ReturnType) {
+		return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+			"cannot change return type of existing function %q", existing.Name).SetHintf(
+			"Use DROP FUNCTION %s first.", existing.Name)
+	}
+	existing.FunctionOpts = n.opts
+
+	if err := existing.ValidateTable(params.EvalContext().Settings); err != nil {
+		return err
+	}
+	if err := params.p.writeSchemaChange(params.ctx, existing, sqlbase.InvalidMutationID); err != nil {
+		return err
+	}
+	return n.logEvent(params, existing.ID)
+}
+
+// logEvent records the creation or replacement of the function in the
+// event log. This is an auditable log event and is recorded in the same
+// transaction as the descriptor update.
+func (n *createFunctionNode) logEvent(params runParams, id sqlbase.ID) error {
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreateFunction,
+		int32(id),
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			FunctionName string
+			Statement    string
+			User         string
+		}{n.n.Name.TableName().FQString(), n.n.String(), params.SessionData().User},
+	)
+}
+
+func (*createFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*createFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createFunctionNode) Close(context.Context)        {}
+
+// makeFunctionOpts converts the signature and options of a CREATE
+// FUNCTION statement to their descriptor representation.
+func makeFunctionOpts(n *tree.CreateFunction) (*sqlbase.TableDescriptor_FunctionOpts, error) {
+	opts := &sqlbase.TableDescriptor_FunctionOpts{
+		Arguments: make([]sqlbase.TableDescriptor_FunctionOpts_Argument, len(n.Args)),
+	}
+	for i, arg := range n.Args {
+		typ, err := functionColumnType(arg.Type)
+		if err != nil {
+			return nil, err
+		}
+		opts.Arguments[i] = sqlbase.TableDescriptor_FunctionOpts_Argument{
+			Name: string(arg.Name),
+			Type: typ,
+		}
+	}
+	retType, err := functionColumnType(n.ReturnType)
+	if err != nil {
+		return nil, err
+	}
+	opts.ReturnType = retType
+
+	var seenBody, seenLanguage, seenVolatility, seenStrictness bool
+	for _, opt := range n.Options {
+		var seen *bool
+		switch opt.Name {
+		case tree.FuncOptAs:
+			seen = &seenBody
+			opts.Body = opt.StrVal
+		case tree.FuncOptLanguage:
+			seen = &seenLanguage
+			if !strings.EqualFold(opt.StrVal, "sql") {
+				return nil, pgerror.Unimplemented("create function language "+opt.StrVal,
+					"only LANGUAGE SQL is supported")
+			}
+		case tree.FuncOptImmutable:
+			seen = &seenVolatility
+			opts.Volatility = sqlbase.TableDescriptor_FunctionOpts_IMMUTABLE
+		case tree.FuncOptStable:
+			seen = &seenVolatility
+			opts.Volatility = sqlbase.TableDescriptor_FunctionOpts_STABLE
+		case tree.FuncOptVolatile:
+			seen = &seenVolatility
+			opts.Volatility = sqlbase.TableDescriptor_FunctionOpts_VOLATILE
+		case tree.FuncOptStrict, tree.FuncOptReturnsNullOnNullInput:
+			seen = &seenStrictness
+			opts.Strict = true
+		case tree.FuncOptCalledOnNullInput:
+			seen = &seenStrictness
+			opts.Strict = false
+		default:
+			return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
+				"unexpected function option %q", opt.Name)
+		}
+		if *seen {
+			return nil, pgerror.NewError(pgerror.CodeSyntaxError,
+				"conflicting or redundant options")
+		}
+		*seen = true
+	}
+	if !seenLanguage {
+		return nil, pgerror.NewError(pgerror.CodeInvalidFunctionDefinitionError,
+			"no language specified")
+	}
+	if !seenBody {
+		return nil, pgerror.NewError(pgerror.CodeInvalidFunctionDefinitionError,
+			"no function body specified")
+	}
+	return opts, nil
+}
+
+// functionColumnType converts the type of an argument or of the result
+// of a function to its descriptor representation.
+func functionColumnType(t coltypes.T) (sqlbase.ColumnType, error) {
+	typ, err := sqlbase.DatumTypeToColumnType(coltypes.CastTargetToDatumType(t))
+	if err != nil {
+		return sqlbase.ColumnType{}, err
+	}
+	return sqlbase.PopulateTypeAttrs(typ, t)
+}
+
+// sameFunctionArguments returns whether two function definitions have
+// the same argument types.
+func sameFunctionArguments(a, b *sqlbase.TableDescriptor_FunctionOpts) bool {
+	if len(a.Arguments) != len(b.Arguments) {
+		return false
+	}
+	for i := range a.Arguments {
+		if !a.Arguments[i].Type.Equal(b.Arguments[i].Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// analyzeFunctionBody verifies that the body of a function consists of
+// valid statements, which only refer to the declared arguments, and
+// that its last statement returns the declared return type.
+func (p *planner) analyzeFunctionBody(
+	ctx context.Context, opts *sqlbase.TableDescriptor_FunctionOpts,
+) error {
+	stmts, err := parser.Parse(opts.Body)
+	if err != nil {
+		return err
+	}
+	if len(stmts) == 0 {
+		return pgerror.NewError(pgerror.CodeInvalidFunctionDefinitionError,
+			"function body must contain at least one statement")
+	}
+
+	// Make sure the most recent versions of the descriptors used by the
+	// body are checked, and restore the placeholder state of the CREATE
+	// FUNCTION statement itself afterwards.
+	defer func(prev bool) { p.avoidCachedDescriptors = prev }(p.avoidCachedDescriptors)
+	p.avoidCachedDescriptors = true
+	defer func(prev tree.PlaceholderInfo) { p.semaCtx.Placeholders = prev }(p.semaCtx.Placeholders)
+
+	retType := opts.ReturnType.ToDatumType()
+	for i, stmt := range stmts {
+		last := i == len(stmts)-1
+		switch stmt.(type) {
+		case *tree.Select:
+		case *tree.Insert, *tree.Update, *tree.Delete:
+			if opts.Volatility != sqlbase.TableDescriptor_FunctionOpts_VOLATILE {
+				return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"%s is not allowed in a non-volatile function", stmt.StatementTag())
+			}
+		default:
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"%s is not allowed in a function body", stmt.StatementTag())
+		}
+		if last {
+			if _, ok := stmt.(*tree.Select); !ok {
+				return pgerror.NewError(pgerror.CodeInvalidFunctionDefinitionError,
+					"the last statement of a function body must be a SELECT")
+			}
+		}
+
+		if err := checkFunctionPlaceholders(stmt, len(opts.Arguments)); err != nil {
+			return err
+		}
+		hints := make(tree.PlaceholderTypes, len(opts.Arguments))
+		for j := range opts.Arguments {
+			hints[strconv.Itoa(j+1)] = opts.Arguments[j].Type.ToDatumType()
+		}
+		p.semaCtx.Placeholders.SetTypeHints(hints)
+
+		var desiredTypes []types.T
+		if last {
+			desiredTypes = []types.T{retType}
+		}
+		plan, err := p.newPlan(ctx, stmt, desiredTypes)
+		if err != nil {
+			return err
+		}
+		cols := planColumns(plan)
+		plan.Close(ctx)
+
+		if last {
+			if len(cols) != 1 {
+				return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+					"return type mismatch in function declared to return %s", retType).SetDetailf(
+					"Final statement must return exactly one column.")
+			}
+			if typ := cols[0].Typ; typ != types.Unknown && !typ.Equivalent(retType) {
+				return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+					"return type mismatch in function declared to return %s", retType).SetDetailf(
+					"Actual return type is %s.", typ)
+			}
+		}
+	}
+	return nil
+}
+
+// checkFunctionPlaceholders verifies that the placeholders in a
+// statement of a function body refer to declared arguments.
+func checkFunctionPlaceholders(stmt tree.Statement, numArgs int) error {
+	var err error
+	f := tree.NewFmtCtxWithBuf(tree.FmtSimple)
+	f.WithPlaceholderFormat(func(_ *tree.FmtCtx, p *tree.Placeholder) {
+		if idx, convErr := strconv.Atoi(p.Name); err == nil && (convErr != nil || idx < 1 || idx > numArgs) {
+			err = pgerror.NewErrorf(pgerror.CodeUndefinedParameterError,
+				"there is no parameter $%s", p.Name)
+		}
+	})
+	f.FormatNode(stmt)
+	f.Close()
+	return err
+}
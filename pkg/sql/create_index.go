@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
@@ -80,6 +81,10 @@ func MakeIndexDescriptor(n *tree.CreateIndex) (*sqlbase.IndexDescriptor, error)
 		if n.Unique {
 			return nil, pgerror.NewError(pgerror.CodeInvalidSQLStatementNameError, "inverted indexes can't be unique")
 		}
+
+		if n.Predicate != nil {
+			return nil, pgerror.NewError(pgerror.CodeInvalidSQLStatementNameError, "inverted indexes can't be partial")
+		}
 		indexDesc.Type = sqlbase.IndexDescriptor_INVERTED
 	}
 
@@ -105,6 +110,18 @@ func (n *createIndexNode) startExec(params runParams) error {
 		return err
 	}
 
+	if n.n.Predicate != nil {
+		tn, err := n.n.Table.Normalize()
+		if err != nil {
+			return err
+		}
+		indexDesc.Predicate, err = makePartialIndexPredicate(params.ctx,
+			*n.tableDesc, n.n.Predicate, &params.p.semaCtx, params.EvalContext(), *tn)
+		if err != nil {
+			return err
+		}
+	}
+
 	if n.n.PartitionBy != nil {
 		partitioning, err := CreatePartitioning(params.ctx, params.p.ExecCfg().Settings,
 			params.EvalContext(), n.tableDesc, indexDesc, n.n.PartitionBy)
@@ -163,6 +180,49 @@ func (n *createIndexNode) startExec(params runParams) error {
 	)
 }
 
+// makePartialIndexPredicate validates the predicate of a partial index on the
+// given table and returns its serialized form. The predicate must be a boolean
+// expression over the columns of the table that does not contain subqueries or
+// impure functions, so that it evaluates the same way every time the row is
+// written.
+func makePartialIndexPredicate(
+	ctx context.Context,
+	desc sqlbase.TableDescriptor,
+	predicate tree.Expr,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	tableName tree.TableName,
+) (string, error) {
+	if _, err := tree.SimpleVisit(predicate, func(expr tree.Expr) (error, bool, tree.Expr) {
+		if _, ok := expr.(*tree.Subquery); ok {
+			return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+				"subqueries are not allowed in index predicate"), false, expr
+		}
+		return nil, true, expr
+	}); err != nil {
+		return "", err
+	}
+
+	expr, _, err := replaceVars(desc, predicate)
+	if err != nil {
+		return "", err
+	}
+	if _, err := sqlbase.SanitizeVarFreeExpr(
+		expr, types.Bool, "index predicate", semaCtx, evalCtx, false, /* allowImpure */
+	); err != nil {
+		return "", err
+	}
+
+	sourceInfo := sqlbase.NewSourceInfoForSingleTable(
+		tableName, sqlbase.ResultColumnsFromColDescs(desc.Columns),
+	)
+	expr, err = dequalifyColumnRefs(ctx, sqlbase.MultiSourceInfo{sourceInfo}, predicate)
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
+
 func (*createIndexNode) Next(runParams) (bool, error) { return false, nil }
 func (*createIndexNode) Values() tree.Datums          { return tree.Datums{} }
 func (*createIndexNode) Close(context.Context)        {}
@@ -17,12 +17,21 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/pkg/errors"
 )
 
+func init() {
+	jobs.AddResumeHook(createStatsResumeHook)
+}
+
 type createStatsNode struct {
 	tree.CreateStats
 	tableDesc *sqlbase.TableDescriptor
@@ -74,3 +83,80 @@ func (p *planner) CreateStatistics(ctx context.Context, n *tree.CreateStats) (pl
 func (*createStatsNode) Next(runParams) (bool, error) { panic("not implemented") }
 func (*createStatsNode) Close(context.Context)        {}
 func (*createStatsNode) Values() tree.Datums          { panic("not implemented") }
+
+// createStatsResumer implements the jobs.Resumer interface for statistics
+// jobs, which are started by the automatic statistics refresher (see
+// stats.Refresher).
+type createStatsResumer struct{}
+
+var _ jobs.Resumer = &createStatsResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *createStatsResumer) Resume(
+	ctx context.Context, job *jobs.Job, phs interface{}, resultsCh chan<- tree.Datums,
+) error {
+	p := phs.(*planner)
+	details := job.Details().(jobspb.CreateStatsDetails)
+	execCfg := p.ExecCfg()
+	dsp := p.DistSQLPlanner()
+	evalCtx := p.ExtendedEvalContext()
+
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		// Read the table at a fixed timestamp so that the (possibly long) scan
+		// never needs to be restarted.
+		txn.SetFixedTimestamp(ctx, execCfg.Clock.Now())
+
+		tableDesc, err := sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		if err != nil {
+			return err
+		}
+		if tableDesc.Dropped() {
+			return errors.Errorf("table %q is being dropped", tableDesc.Name)
+		}
+
+		evalCtx.Txn = txn
+		planCtx := dsp.NewPlanningCtx(ctx, evalCtx, txn)
+		plan, err := dsp.createPlanForCreateStatsJob(&planCtx, tableDesc, &details)
+		if err != nil {
+			return err
+		}
+		dsp.FinalizePlan(&planCtx, &plan)
+
+		rw := &errOnlyResultWriter{}
+		recv := MakeDistSQLReceiver(
+			ctx,
+			rw,
+			tree.Rows, /* stmtType - doesn't matter here since no result are produced */
+			execCfg.RangeDescriptorCache,
+			execCfg.LeaseHolderCache,
+			txn,
+			func(ts hlc.Timestamp) {
+				_ = execCfg.Clock.Update(ts)
+			},
+			evalCtx.Tracing,
+		)
+		dsp.Run(&planCtx, txn, &plan, recv, evalCtx)
+		return rw.Err()
+	})
+}
+
+// OnSuccess is part of the jobs.Resumer interface.
+func (r *createStatsResumer) OnSuccess(context.Context, *client.Txn, *jobs.Job) error { return nil }
+
+// OnTerminal is part of the jobs.Resumer interface.
+func (r *createStatsResumer) OnTerminal(
+	context.Context, *jobs.Job, jobs.Status, chan<- tree.Datums,
+) {
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *createStatsResumer) OnFailOrCancel(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+
+func createStatsResumeHook(typ jobspb.Type, _ *cluster.Settings) jobs.Resumer {
+	if typ != jobspb.TypeCreateStats {
+		return nil
+	}
+	return &createStatsResumer{}
+}
@@ -39,6 +39,7 @@ import (
 type createTableNode struct {
 	n          *tree.CreateTable
 	dbDesc     *sqlbase.DatabaseDescriptor
+	temporary  bool
 	sourcePlan planNode
 
 	run createTableRun
@@ -53,9 +54,15 @@ func (p *planner) CreateTable(ctx context.Context, n *tree.CreateTable) (planNod
 		return nil, err
 	}
 
+	temporary := n.Temporary || (tn.ExplicitSchema && isTemporarySchemaName(tn.Schema()))
+
 	var dbDesc *DatabaseDescriptor
 	p.runWithOptions(resolveFlags{skipCache: true}, func() {
-		dbDesc, err = ResolveTargetObject(ctx, p, tn)
+		if temporary {
+			dbDesc, err = p.resolveTemporaryTableTarget(ctx, tn)
+		} else {
+			dbDesc, err = ResolveTargetObject(ctx, p, tn)
+		}
 	})
 	if err != nil {
 		return nil, err
@@ -99,7 +106,7 @@ func (p *planner) CreateTable(ctx context.Context, n *tree.CreateTable) (planNod
 		}
 	}
 
-	return &createTableNode{n: n, dbDesc: dbDesc, sourcePlan: sourcePlan}, nil
+	return &createTableNode{n: n, dbDesc: dbDesc, temporary: temporary, sourcePlan: sourcePlan}, nil
 }
 
 // createTableRun contains the run-time state of createTableNode
@@ -110,7 +117,19 @@ type createTableRun struct {
 }
 
 func (n *createTableNode) startExec(params runParams) error {
-	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Table.TableName().Table()}
+	// The names of temporary tables are registered under their schema
+	// instead of their database.
+	var tempSchema *sqlbase.TemporarySchema
+	parentID := n.dbDesc.ID
+	if n.temporary {
+		var err error
+		if tempSchema, err = params.p.getOrCreateTemporarySchema(params.ctx, n.dbDesc.ID); err != nil {
+			return err
+		}
+		parentID = tempSchema.ID
+	}
+
+	tKey := tableKey{parentID: parentID, name: n.n.Table.TableName().Table()}
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
 		if n.n.IfNotExists {
@@ -147,6 +166,18 @@ func (n *createTableNode) startExec(params runParams) error {
 	if err != nil {
 		return err
 	}
+	desc.TemporarySchema = tempSchema
+	if desc.IsTemporary() {
+		for _, index := range desc.AllNonDropIndexes() {
+			if len(index.Interleave.Ancestors) > 0 {
+				return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+					"temporary tables cannot be interleaved")
+			}
+		}
+	}
+	if err := checkTemporaryReferences(&desc, affected); err != nil {
+		return err
+	}
 
 	// We need to validate again after adding the FKs.
 	// Only validate the table because backreferences aren't created yet.
@@ -224,7 +255,7 @@ func (n *createTableNode) startExec(params runParams) error {
 		// Instantiate a row inserter and table writer. It has a 1-1
 		// mapping to the definitions in the descriptor.
 		ri, err := sqlbase.MakeRowInserter(
-			params.p.txn, &desc, nil, desc.Columns, sqlbase.SkipFKs, &params.p.alloc)
+			params.p.txn, &desc, nil, desc.Columns, sqlbase.SkipFKs, params.EvalContext(), &params.p.alloc)
 		if err != nil {
 			return err
 		}
@@ -277,6 +308,10 @@ func (n *createTableNode) startExec(params runParams) error {
 				if err != nil {
 					return err
 				}
+				// Possibly initiate a refresh of the table statistics.
+				params.extendedEvalCtx.ExecCfg.StatsRefresher.NotifyMutation(
+					desc.ID, int64(n.run.rowsAffected),
+				)
 				break
 			}
 
@@ -388,6 +423,11 @@ const (
 func matchesIndex(
 	cols []sqlbase.ColumnDescriptor, idx sqlbase.IndexDescriptor, exact indexMatch,
 ) bool {
+	// A partial index doesn't contain every row of the table, so it can
+	// neither enforce nor look up a foreign key.
+	if idx.IsPartial() {
+		return false
+	}
 	if len(cols) > len(idx.ColumnIDs) || (exact && len(cols) != len(idx.ColumnIDs)) {
 		return false
 	}
@@ -1107,6 +1147,17 @@ func MakeTableDesc(
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				if d.Inverted {
+					return desc, pgerror.NewError(pgerror.CodeInvalidSQLStatementNameError,
+						"inverted indexes can't be partial")
+				}
+				if idx.Predicate, err = makePartialIndexPredicate(
+					ctx, desc, d.Predicate, semaCtx, evalCtx, *tableName,
+				); err != nil {
+					return desc, err
+				}
+			}
 			if d.PartitionBy != nil {
 				partitioning, err := CreatePartitioning(ctx, st, evalCtx, &desc, &idx, d.PartitionBy)
 				if err != nil {
@@ -1129,6 +1180,13 @@ func MakeTableDesc(
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				if idx.Predicate, err = makePartialIndexPredicate(
+					ctx, desc, d.Predicate, semaCtx, evalCtx, *tableName,
+				); err != nil {
+					return desc, err
+				}
+			}
 			if d.PartitionBy != nil {
 				partitioning, err := CreatePartitioning(ctx, st, evalCtx, &desc, &idx, d.PartitionBy)
 				if err != nil {
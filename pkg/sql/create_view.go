@@ -18,8 +18,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -94,12 +98,21 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 	if err != nil {
 		return nil, err
 	}
+	for _, dep := range planDeps {
+		// Views are permanent: they cannot outlive the session owning the
+		// temporary tables they would depend on.
+		if dep.desc.IsTemporary() {
+			return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"views cannot depend on temporary table %q", dep.desc.Name)
+		}
+	}
 
 	numColNames := len(n.ColumnNames)
 	numColumns := len(sourceColumns)
 	if numColNames != 0 && numColNames != numColumns {
 		return nil, sqlbase.NewSyntaxError(fmt.Sprintf(
-			"CREATE VIEW specifies %d column name%s, but data source has %d column%s",
+			"%s specifies %d column name%s, but data source has %d column%s",
+			n.StatementTag(),
 			numColNames, util.Pluralize(int64(numColNames)),
 			numColumns, util.Pluralize(int64(numColumns))))
 	}
@@ -180,6 +193,24 @@ func (n *createViewNode) startExec(params runParams) error {
 		return err
 	}
 
+	if desc.IsMaterializedView {
+		// Populate the view with the result of its query as of the creation
+		// of the view.
+		if err := streamMaterializedViewRows(
+			params.ctx, params.extendedEvalCtx.ExecCfg, params.p.txn,
+			params.extendedEvalCtx.MemMetrics, "create-materialized-view",
+			params.SessionData().User, desc.ViewQuery, materializedViewChunkSize,
+			func(rows []tree.Datums) error {
+				return insertMaterializedViewRows(
+					params.ctx, params.p.txn, params.EvalContext(), &desc, rows,
+					params.extendedEvalCtx.Tracing.KVTracingEnabled(),
+				)
+			},
+		); err != nil {
+			return err
+		}
+	}
+
 	// Log Create View event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
@@ -219,12 +250,17 @@ func (n *createViewNode) makeViewTableDesc(
 	desc := InitTableDescriptor(id, parentID, viewName,
 		params.p.txn.CommitTimestamp(), privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.n.AsSource, tree.FmtParsable)
+	desc.IsMaterializedView = n.n.Materialized
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
 			return desc, err
 		}
 		columnTableDef := tree.ColumnTableDef{Name: tree.Name(colRes.Name), Type: colType}
+		if desc.IsMaterializedView {
+			// The stored contents of a materialized view may contain NULLs.
+			columnTableDef.Nullable.Nullability = tree.SilentNull
+		}
 		if len(columnNames) > i {
 			columnTableDef.Name = columnNames[i]
 		}
@@ -242,3 +278,126 @@ func (n *createViewNode) makeViewTableDesc(
 	err := desc.AllocateIDs()
 	return desc, err
 }
+
+// materializedViewChunkSize is the maximum number of rows of the query of
+// a materialized view that are held in memory and written in one batch
+// when the view is created.
+const materializedViewChunkSize = 1000
+
+// streamMaterializedViewRows runs the query of a materialized view in the
+// given transaction, with the privileges of the given user, and passes its
+// result rows to fn in chunks of at most chunkSize rows. Only the rows of the
+// current chunk are held in memory, where they are accounted for by the
+// memory monitor of the planner that runs the query.
+func streamMaterializedViewRows(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	txn *client.Txn,
+	memMetrics *MemoryMetrics,
+	opName string,
+	user string,
+	viewQuery string,
+	chunkSize int,
+	fn func(rows []tree.Datums) error,
+) error {
+	stmt, err := parser.ParseOne(viewQuery)
+	if err != nil {
+		return err
+	}
+	p, cleanup := newInternalPlanner(opName, txn, user, memMetrics, execCfg)
+	defer cleanup()
+
+	if err := p.makePlan(ctx, Statement{AST: stmt}); err != nil {
+		return err
+	}
+	defer p.curPlan.close(ctx)
+	params := runParams{ctx: ctx, extendedEvalCtx: &p.extendedEvalCtx, p: p}
+	if err := p.curPlan.start(params); err != nil {
+		return err
+	}
+
+	chunk := sqlbase.NewRowContainer(
+		p.EvalContext().Mon.MakeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(p.curPlan.columns()),
+		chunkSize,
+	)
+	defer chunk.Close(ctx)
+	rows := make([]tree.Datums, 0, chunkSize)
+	flush := func() error {
+		rows = rows[:0]
+		for i, n := 0, chunk.Len(); i < n; i++ {
+			rows = append(rows, chunk.At(i))
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		chunk.Clear(ctx)
+		return nil
+	}
+
+	for {
+		if err := p.cancelChecker.Check(); err != nil {
+			return err
+		}
+		next, err := p.curPlan.plan.Next(params)
+		if err != nil {
+			return err
+		}
+		if !next {
+			break
+		}
+		if _, err := chunk.AddRow(ctx, p.curPlan.plan.Values()); err != nil {
+			return err
+		}
+		if chunk.Len() >= chunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if chunk.Len() > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// insertMaterializedViewRows writes the given result rows of the query of a
+// materialized view into the view's primary index. The rows hold a value for
+// each visible column of the view; the hidden primary key column is filled
+// in from its default expression.
+func insertMaterializedViewRows(
+	ctx context.Context,
+	txn *client.Txn,
+	evalCtx *tree.EvalContext,
+	desc *sqlbase.TableDescriptor,
+	rows []tree.Datums,
+	traceKV bool,
+) error {
+	ri, err := sqlbase.MakeRowInserter(
+		txn, desc, nil, desc.Columns, sqlbase.SkipFKs, evalCtx, &sqlbase.DatumAlloc{})
+	if err != nil {
+		return err
+	}
+	defaultExprs, err := sqlbase.MakeDefaultExprs(
+		desc.Columns, &transform.ExprTransformContext{}, evalCtx)
+	if err != nil {
+		return err
+	}
+
+	b := txn.NewBatch()
+	rowBuffer := make(tree.Datums, len(desc.Columns))
+	for _, row := range rows {
+		copy(rowBuffer, row)
+		for i := len(row); i < len(rowBuffer); i++ {
+			if rowBuffer[i], err = defaultExprs[i].Eval(evalCtx); err != nil {
+				return err
+			}
+		}
+		if err := ri.InsertRow(
+			ctx, b, rowBuffer, false /* overwrite */, sqlbase.SkipFKs, traceKV,
+		); err != nil {
+			return err
+		}
+	}
+	return txn.Run(ctx, b)
+}
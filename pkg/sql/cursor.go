@@ -0,0 +1,458 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/fsm"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+var errCursorScanBackward = pgerror.NewError(pgerror.CodeObjectNotInPrerequisiteStateError,
+	"cursor can only scan forward")
+
+// sqlCursor is a cursor declared with DECLARE, or created to suspend the
+// execution of a portal whose rows are fetched in batches (see
+// execSuspendablePortal).
+//
+// The cursor's query is planned when the cursor is declared, with a
+// planner of its own that is not affected by the statements executed
+// afterwards. The plan is started by the first FETCH or MOVE statement,
+// and each statement then pulls the rows it needs from it: the plan is
+// paused in between, so the results are never materialized.
+//
+// Cursors are closed when the transaction in which they were declared
+// ends. Like the NO SCROLL cursors of Postgres, they can only move
+// forward. Unlike Postgres, the rows written by the transaction after a
+// cursor was declared may be visible to it.
+type sqlCursor struct {
+	name string
+	p    *planner
+
+	// mon accounts for the memory used by the cursor's plan. It is not
+	// tied to the transaction's monitor, which is stopped before the
+	// cursors are closed when the transaction ends.
+	mon mon.BytesMonitor
+	// rowAcc accounts for the memory used by the current row.
+	rowAcc mon.BoundAccount
+
+	// portal is the portal the cursor was created for, if any.
+	portal *PreparedPortal
+
+	started bool
+	// exhausted is set once the plan doesn't have any more rows.
+	exhausted bool
+	// pos is the number of rows read from the plan.
+	pos int64
+	// row is a copy of the last row read from the plan if the cursor is
+	// positioned on it, and nil if the cursor is positioned before the
+	// first row or after the last one.
+	row tree.Datums
+}
+
+// columns returns the columns of the rows returned by the cursor.
+func (c *sqlCursor) columns() sqlbase.ResultColumns {
+	return planColumns(c.p.curPlan.plan)
+}
+
+// next moves the cursor to the next row and returns whether there was
+// one.
+func (c *sqlCursor) next(ctx context.Context) (bool, error) {
+	if c.exhausted {
+		c.row = nil
+		return false, nil
+	}
+	params := runParams{
+		ctx:             ctx,
+		extendedEvalCtx: &c.p.extendedEvalCtx,
+		p:               c.p,
+	}
+	if !c.started {
+		c.started = true
+		if err := c.p.curPlan.start(params); err != nil {
+			c.exhausted = true
+			return false, err
+		}
+	}
+	c.rowAcc.Clear(ctx)
+	ok, err := c.p.curPlan.plan.Next(params)
+	if err != nil || !ok {
+		c.exhausted = true
+		c.row = nil
+		return false, err
+	}
+	c.pos++
+	c.row = append(c.row[:0], c.p.curPlan.plan.Values()...)
+	return true, nil
+}
+
+// close releases the resources of the cursor.
+func (c *sqlCursor) close(ctx context.Context) {
+	c.p.curPlan.close(ctx)
+	c.rowAcc.Close(ctx)
+	c.mon.Stop(ctx)
+}
+
+// cursorMove describes how a FETCH or MOVE statement moves a cursor:
+// either it returns the row the cursor is positioned on, or it skips
+// some rows and then returns up to count rows, or only the last row if
+// last is set.
+type cursorMove struct {
+	current bool
+	skip    int64
+	count   int64
+	last    bool
+}
+
+// makeCursorMove computes how a FETCH or MOVE statement moves a cursor
+// from its current position.
+func makeCursorMove(c *sqlCursor, s *tree.CursorStmt) (cursorMove, error) {
+	switch s.FetchType {
+	case tree.FetchNormal:
+		switch {
+		case s.Count > 0:
+			return cursorMove{count: s.Count}, nil
+		case s.Count == 0:
+			return cursorMove{current: true}, nil
+		}
+	case tree.FetchRelative:
+		switch {
+		case s.Count > 0:
+			return cursorMove{skip: s.Count - 1, count: 1}, nil
+		case s.Count == 0:
+			return cursorMove{current: true}, nil
+		}
+	case tree.FetchAbsolute, tree.FetchFirst:
+		n := s.Count
+		if s.FetchType == tree.FetchFirst {
+			n = 1
+		}
+		switch {
+		case n > c.pos:
+			return cursorMove{skip: n - c.pos - 1, count: 1}, nil
+		case n == c.pos && (c.row != nil || n == 0):
+			return cursorMove{current: true}, nil
+		}
+	case tree.FetchLast:
+		return cursorMove{last: true}, nil
+	case tree.FetchAll:
+		return cursorMove{count: math.MaxInt64}, nil
+	}
+	return cursorMove{}, errCursorScanBackward
+}
+
+// cursorMap holds the cursors of a transaction, by name. The cursors
+// created for portals are named after them.
+type cursorMap struct {
+	cursors map[string]*sqlCursor
+}
+
+func (m *cursorMap) get(name string) *sqlCursor {
+	return m.cursors[name]
+}
+
+func (m *cursorMap) add(c *sqlCursor) {
+	if m.cursors == nil {
+		m.cursors = make(map[string]*sqlCursor)
+	}
+	m.cursors[c.name] = c
+}
+
+// close closes the cursor with the given name, if any.
+func (m *cursorMap) close(ctx context.Context, name string) {
+	if c, ok := m.cursors[name]; ok {
+		c.close(ctx)
+		delete(m.cursors, name)
+	}
+}
+
+// closeAll closes all the cursors.
+func (m *cursorMap) closeAll(ctx context.Context) {
+	for _, c := range m.cursors {
+		c.close(ctx)
+	}
+	m.cursors = nil
+}
+
+// declareCursor plans the query of a new cursor and adds the cursor to the
+// transaction. portal is set if the cursor is created for a portal.
+func (ex *connExecutor) declareCursor(
+	ctx context.Context,
+	name string,
+	stmt Statement,
+	pinfo *tree.PlaceholderInfo,
+	portal *PreparedPortal,
+) error {
+	if ex.extraTxnState.cursors.get(name) != nil {
+		return pgerror.NewErrorf(pgerror.CodeDuplicateCursorError, "cursor %q already exists", name)
+	}
+
+	p := ex.newPlanner(ctx, ex.state.mu.txn, ex.server.cfg.Clock.PhysicalTime())
+	c := &sqlCursor{name: name, p: p, portal: portal}
+	c.mon = mon.MakeMonitor("cursor",
+		mon.MemoryResource,
+		ex.memMetrics.TxnCurBytesCount,
+		ex.memMetrics.TxnMaxBytesHist,
+		-1 /* increment */, noteworthyMemoryUsageBytes, ex.server.cfg.Settings)
+	c.mon.Start(ctx, ex.sessionMon, mon.BoundAccount{})
+	p.extendedEvalCtx.Mon = &c.mon
+	c.rowAcc = c.mon.MakeBoundAccount()
+	p.extendedEvalCtx.ActiveMemAcc = &c.rowAcc
+
+	p.semaCtx.Placeholders.Assign(pinfo)
+	p.extendedEvalCtx.Placeholders = &p.semaCtx.Placeholders
+	p.stmt = &stmt
+	optimizerPlanned, err := p.optionallyUseOptimizer(ctx, ex.sessionData, stmt)
+	if !optimizerPlanned && err == nil {
+		err = p.makePlan(ctx, stmt)
+	}
+	if err != nil {
+		c.rowAcc.Close(ctx)
+		c.mon.Stop(ctx)
+		return err
+	}
+	ex.extraTxnState.cursors.add(c)
+	return nil
+}
+
+// execDeclareCursor executes a DECLARE statement.
+func (ex *connExecutor) execDeclareCursor(
+	ctx context.Context, s *tree.DeclareCursor, pinfo *tree.PlaceholderInfo,
+) error {
+	switch {
+	case s.Binary:
+		return pgerror.Unimplemented("binary cursor", "DECLARE BINARY CURSOR is not supported")
+	case s.Scroll == tree.Scroll:
+		return pgerror.Unimplemented("scroll cursor", "DECLARE SCROLL CURSOR is not supported")
+	case s.Hold:
+		return pgerror.Unimplemented("holdable cursor", "DECLARE CURSOR WITH HOLD is not supported")
+	}
+	return ex.declareCursor(ctx, string(s.Name), Statement{AST: s.Select}, pinfo, nil /* portal */)
+}
+
+// portalCanSuspend returns whether the execution of a portal can be
+// suspended once it has returned the number of rows requested by the
+// client, to be resumed by the next execution of the portal. This is
+// supported for queries in explicit transactions.
+func (ex *connExecutor) portalCanSuspend(portal *PreparedPortal) bool {
+	os, ok := ex.machine.CurState().(stateOpen)
+	if !ok || os.ImplicitTxn.Get() {
+		return false
+	}
+	_, ok = portal.Stmt.Statement.(*tree.Select)
+	return ok
+}
+
+// execSuspendablePortal executes a portal whose rows are returned in
+// batches of at most limit rows (or all the remaining rows if limit is
+// 0). The first execution declares a cursor for the portal's query, and
+// every execution then fetches the next rows from it. If the batch is
+// full, the portal is suspended; otherwise the cursor is closed.
+func (ex *connExecutor) execSuspendablePortal(
+	ctx context.Context,
+	name string,
+	portal *PreparedPortal,
+	stmt Statement,
+	pinfo *tree.PlaceholderInfo,
+	res CommandResult,
+	limit int,
+	pos CmdPos,
+) (fsm.Event, fsm.EventPayload, error) {
+	c := ex.extraTxnState.cursors.get(name)
+	if c != nil && c.portal != portal {
+		if c.portal == nil {
+			ev, payload := ex.makeErrEvent(pgerror.NewErrorf(pgerror.CodeDuplicateCursorError,
+				"cursor %q already exists", name), stmt.AST)
+			return ev, payload, nil
+		}
+		// The cursor was created for a previous portal with the same name.
+		ex.extraTxnState.cursors.close(ctx, name)
+		c = nil
+	}
+	if c == nil {
+		if err := ex.declareCursor(ctx, name, stmt, pinfo, portal); err != nil {
+			ev, payload := ex.makeErrEvent(err, stmt.AST)
+			return ev, payload, nil
+		}
+	}
+
+	fetch := &tree.FetchCursor{CursorStmt: tree.CursorStmt{
+		Name:  tree.Name(name),
+		Count: int64(limit),
+	}}
+	if limit == 0 {
+		fetch.FetchType = tree.FetchAll
+	}
+	ev, payload, err := ex.execStmt(ctx, Statement{AST: fetch}, res, nil /* pinfo */, pos)
+	if err != nil || ev != nil || res.Err() != nil {
+		return ev, payload, err
+	}
+	if limit > 0 && res.RowsAffected() == limit {
+		res.SetPortalSuspended()
+	} else {
+		ex.extraTxnState.cursors.close(ctx, name)
+	}
+	return nil, nil, nil
+}
+
+// lookupCursor returns the cursor with the given name.
+func (p *planner) lookupCursor(name tree.Name) (*sqlCursor, error) {
+	if cursors := p.extendedEvalCtx.Cursors; cursors != nil {
+		if c := cursors.get(string(name)); c != nil {
+			return c, nil
+		}
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeInvalidCursorNameError,
+		"cursor %q does not exist", string(name))
+}
+
+type fetchNode struct {
+	cursor *sqlCursor
+	stmt   tree.CursorStmt
+	// move is set for MOVE statements, which only count the rows.
+	move bool
+
+	run struct {
+		cursorMove
+		// row is the current row, if any.
+		row tree.Datums
+		// rowCount is the number of rows moved over by MOVE.
+		rowCount int
+	}
+}
+
+// FetchCursor implements the FETCH statement.
+// See https://www.postgresql.org/docs/10/static/sql-fetch.html for details.
+func (p *planner) FetchCursor(ctx context.Context, n *tree.FetchCursor) (planNode, error) {
+	c, err := p.lookupCursor(n.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &fetchNode{cursor: c, stmt: n.CursorStmt}, nil
+}
+
+// MoveCursor implements the MOVE statement.
+// See https://www.postgresql.org/docs/10/static/sql-move.html for details.
+func (p *planner) MoveCursor(ctx context.Context, n *tree.MoveCursor) (planNode, error) {
+	c, err := p.lookupCursor(n.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &fetchNode{cursor: c, stmt: n.CursorStmt, move: true}, nil
+}
+
+func (n *fetchNode) startExec(params runParams) error {
+	var err error
+	if n.run.cursorMove, err = makeCursorMove(n.cursor, &n.stmt); err != nil {
+		return err
+	}
+	if !n.move {
+		return nil
+	}
+	next, err := n.Next(params)
+	for ; next; next, err = n.Next(params) {
+		n.run.rowCount++
+	}
+	return err
+}
+
+func (n *fetchNode) Next(params runParams) (bool, error) {
+	m := &n.run.cursorMove
+	c := n.cursor
+	n.run.row = nil
+	if m.current {
+		m.current = false
+		n.run.row = c.row
+		return n.run.row != nil, nil
+	}
+	for ; m.skip > 0; m.skip-- {
+		if ok, err := c.next(params.ctx); !ok || err != nil {
+			return false, err
+		}
+	}
+	if m.last {
+		m.last = false
+		last := c.row
+		for {
+			ok, err := c.next(params.ctx)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				break
+			}
+			last = append(last[:0:0], c.row...)
+		}
+		// The cursor remains positioned on the last row.
+		c.row = last
+		n.run.row = last
+		return last != nil, nil
+	}
+	if m.count == 0 {
+		return false, nil
+	}
+	m.count--
+	ok, err := c.next(params.ctx)
+	if ok {
+		n.run.row = c.row
+	}
+	return ok, err
+}
+
+func (n *fetchNode) Values() tree.Datums { return n.run.row }
+func (*fetchNode) Close(context.Context) {}
+
+// FastPathResults implements the planNodeFastPath interface.
+func (n *fetchNode) FastPathResults() (int, bool) {
+	return n.run.rowCount, n.move
+}
+
+type closeCursorNode struct {
+	// name is empty for CLOSE ALL.
+	name tree.Name
+}
+
+// CloseCursor implements the CLOSE statement.
+// See https://www.postgresql.org/docs/10/static/sql-close.html for details.
+func (p *planner) CloseCursor(ctx context.Context, n *tree.CloseCursor) (planNode, error) {
+	if n.Name != "" {
+		if _, err := p.lookupCursor(n.Name); err != nil {
+			return nil, err
+		}
+	}
+	return &closeCursorNode{name: n.Name}, nil
+}
+
+func (n *closeCursorNode) startExec(params runParams) error {
+	cursors := params.extendedEvalCtx.Cursors
+	if cursors == nil {
+		return nil
+	}
+	if n.name == "" {
+		cursors.closeAll(params.ctx)
+	} else {
+		cursors.close(params.ctx, string(n.name))
+	}
+	return nil
+}
+
+func (*closeCursorNode) Next(runParams) (bool, error) { return false, nil }
+func (*closeCursorNode) Values() tree.Datums          { return nil }
+func (*closeCursorNode) Close(context.Context)        {}
@@ -0,0 +1,64 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestMakeCursorMove(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	row := tree.Datums{tree.NewDInt(1)}
+	testCases := []struct {
+		pos      int64
+		row      tree.Datums
+		stmt     tree.CursorStmt
+		expected cursorMove
+		err      string
+	}{
+		{0, nil, tree.CursorStmt{Count: 3}, cursorMove{count: 3}, ""},
+		{2, row, tree.CursorStmt{Count: 0}, cursorMove{current: true}, ""},
+		{2, row, tree.CursorStmt{Count: -1}, cursorMove{}, "can only scan forward"},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchRelative, Count: 3}, cursorMove{skip: 2, count: 1}, ""},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchRelative}, cursorMove{current: true}, ""},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchAbsolute, Count: 5}, cursorMove{skip: 2, count: 1}, ""},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchAbsolute, Count: 2}, cursorMove{current: true}, ""},
+		{2, nil, tree.CursorStmt{FetchType: tree.FetchAbsolute, Count: 2}, cursorMove{}, "can only scan forward"},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchAbsolute, Count: 1}, cursorMove{}, "can only scan forward"},
+		{0, nil, tree.CursorStmt{FetchType: tree.FetchAbsolute}, cursorMove{current: true}, ""},
+		{0, nil, tree.CursorStmt{FetchType: tree.FetchFirst}, cursorMove{count: 1}, ""},
+		{1, row, tree.CursorStmt{FetchType: tree.FetchFirst}, cursorMove{current: true}, ""},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchFirst}, cursorMove{}, "can only scan forward"},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchLast}, cursorMove{last: true}, ""},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchAll}, cursorMove{count: math.MaxInt64}, ""},
+		{2, row, tree.CursorStmt{FetchType: tree.FetchBackwardAll}, cursorMove{}, "can only scan forward"},
+	}
+	for i, tc := range testCases {
+		c := &sqlCursor{pos: tc.pos, row: tc.row}
+		m, err := makeCursorMove(c, &tc.stmt)
+		if !testutils.IsError(err, tc.err) {
+			t.Fatalf("%d: expected error %q, got %v", i, tc.err, err)
+		}
+		if m != tc.expected {
+			t.Fatalf("%d: expected %+v, got %+v", i, tc.expected, m)
+		}
+	}
+}
@@ -125,6 +125,9 @@ func (p *planner) getDataSource(
 		if desc.IsVirtualTable() {
 			return p.getVirtualDataSource(ctx, tn)
 		}
+		if desc.IsFunction() {
+			return planDataSource{}, sqlbase.NewWrongObjectTypeError(tn, requiredTypeNames[requireTableDesc])
+		}
 
 		colCfg := scanColumnsConfig{visibility: scanVisibility}
 		return p.getPlanForDesc(ctx, desc, tn, indexFlags, colCfg)
@@ -136,6 +139,10 @@ func (p *planner) getDataSource(
 		return p.getSubqueryPlan(ctx, sqlbase.AnonymousTable, t.Select, nil)
 
 	case *tree.JoinTableExpr:
+		if t.Hint != "" {
+			return planDataSource{}, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"join hints are only supported by the cost-based optimizer")
+		}
 		// Joins: two sources.
 		left, err := p.getDataSource(ctx, t.Left, nil, scanVisibility)
 		if err != nil {
@@ -180,6 +187,17 @@ func (p *planner) getDataSource(
 			return src, err
 		}
 
+		if t.Sample != nil {
+			scan, ok := src.plan.(*scanNode)
+			if !ok {
+				return planDataSource{}, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+					"TABLESAMPLE clause can only be applied to tables and materialized views")
+			}
+			if scan.sample, err = p.analyzeTableSample(ctx, t.Sample); err != nil {
+				return planDataSource{}, err
+			}
+		}
+
 		if t.Ordinality {
 			// The WITH ORDINALITY clause numbers the rows coming out of the
 			// data source. See the comments next to the definition of
@@ -325,7 +343,7 @@ func (p *planner) getPlanForDesc(
 	indexFlags *tree.IndexFlags,
 	colCfg scanColumnsConfig,
 ) (planDataSource, error) {
-	if desc.IsView() {
+	if desc.IsView() && !desc.IsMaterializedView {
 		if colCfg.wantedColumns != nil {
 			return planDataSource{},
 				errors.Errorf("cannot specify an explicit column list when accessing a view by reference")
@@ -335,12 +353,12 @@ func (p *planner) getPlanForDesc(
 	if desc.IsSequence() {
 		return p.getSequenceSource(ctx, *tn, desc)
 	}
-	if !desc.IsTable() {
+	if !desc.IsTable() && !desc.IsMaterializedView {
 		return planDataSource{}, errors.Errorf(
 			"unexpected table descriptor of type %s for %q", desc.TypeName(), tree.ErrString(tn))
 	}
 
-	// This name designates a real table.
+	// This name designates a real table or a materialized view.
 	scan := p.Scan()
 	if err := scan.initTable(ctx, p, desc, indexFlags, colCfg); err != nil {
 		return planDataSource{}, err
@@ -293,6 +293,10 @@ func (d *deleteNode) BatchedNext(params runParams) (bool, error) {
 		if _, err := d.run.td.finalize(params.ctx, d.run.autoCommit, d.run.traceKV); err != nil {
 			return false, err
 		}
+		// Possibly initiate a refresh of the table statistics.
+		params.extendedEvalCtx.ExecCfg.StatsRefresher.NotifyMutation(
+			d.run.td.tableDesc().ID, d.run.td.rowsWritten(),
+		)
 		// Remember we're done for the next call to BatchedNext().
 		d.run.done = true
 	}
@@ -403,7 +407,14 @@ func (d *deleteNode) fastDelete(params runParams, scan *scanNode) error {
 	var err error
 	d.run.rowCount, err = d.run.td.fastDelete(
 		params.ctx, scan, d.run.autoCommit, d.run.traceKV)
-	return err
+	if err != nil {
+		return err
+	}
+	// Possibly initiate a refresh of the table statistics.
+	params.extendedEvalCtx.ExecCfg.StatsRefresher.NotifyMutation(
+		d.run.td.tableDesc().ID, int64(d.run.rowCount),
+	)
+	return nil
 }
 
 // enableAutoCommit is part of the autoCommitNode interface.
@@ -42,6 +42,20 @@ func (p *planner) Discard(ctx context.Context, s *tree.Discard) (planNode, error
 
 		// DEALLOCATE ALL
 		p.preparedStatements.DeleteAll(ctx)
+
+		// UNLISTEN *
+		if tn := p.extendedEvalCtx.Notifications; tn != nil {
+			tn.listenActions = append(tn.listenActions, listenAction{})
+		}
+
+		// DISCARD TEMP
+		if err := p.dropSessionTemporaryTables(ctx); err != nil {
+			return nil, err
+		}
+	case tree.DiscardModeTemp:
+		if err := p.dropSessionTemporaryTables(ctx); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
 			"unknown mode for DISCARD: %d", s.Mode)
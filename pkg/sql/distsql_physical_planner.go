@@ -356,6 +356,12 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		}
 		return shouldDistribute, nil
 
+	case *zigzagJoinNode:
+		if err := dsp.checkExpr(n.onCond); err != nil {
+			return cannotDistribute, err
+		}
+		return shouldDistribute, nil
+
 	case *groupNode:
 		rec, err := dsp.checkSupportForNode(n.plan)
 		if err != nil {
@@ -455,6 +461,10 @@ type PlanningCtx struct {
 	// root of the planNode tree. It's used to keep track of whether it's valid to
 	// run that root node in a special fast path mode.
 	finishedPlanningFirstNode bool
+
+	// processorPlanNodes, if set, records the planNodes for which each
+	// processor was planned. It is used by EXPLAIN ANALYZE.
+	processorPlanNodes *processorPlanNodes
 }
 
 // EvalContext returns the associated EvalContext, or nil if there isn't one.
@@ -998,12 +1008,20 @@ func (dsp *DistSQLPlanner) createTableReaders(
 		return PhysicalPlan{}, err
 	}
 
+	spans, bernoulliSample, err := n.sampledSpans(planCtx.ctx, planCtx.EvalContext(), planCtx.spanIter)
+	if err != nil {
+		return PhysicalPlan{}, err
+	}
+	spec.BernoulliSample = bernoulliSample
+
 	var spanPartitions []spanPartition
-	if planCtx.isLocal {
-		spanPartitions = []spanPartition{{dsp.nodeDesc.NodeID, n.spans}}
+	if planCtx.isLocal || len(spans) == 0 {
+		// Note that TABLESAMPLE SYSTEM can leave us without any spans to read, in
+		// which case a single TableReader on the gateway produces no rows.
+		spanPartitions = []spanPartition{{dsp.nodeDesc.NodeID, spans}}
 	} else if n.hardLimit == 0 && n.softLimit == 0 {
 		// No limit - plan all table readers where their data live.
-		spanPartitions, err = dsp.partitionSpans(planCtx, n.spans)
+		spanPartitions, err = dsp.partitionSpans(planCtx, spans)
 		if err != nil {
 			return PhysicalPlan{}, err
 		}
@@ -1013,11 +1031,11 @@ func (dsp *DistSQLPlanner) createTableReaders(
 		// limits since the TableReader will still read too eagerly in the soft
 		// limit case. To prevent this we'll need a new mechanism on the execution
 		// side to modulate table reads.
-		nodeID, err := dsp.getNodeIDForScan(planCtx, n.spans, n.reverse)
+		nodeID, err := dsp.getNodeIDForScan(planCtx, spans, n.reverse)
 		if err != nil {
 			return PhysicalPlan{}, err
 		}
-		spanPartitions = []spanPartition{{nodeID, n.spans}}
+		spanPartitions = []spanPartition{{nodeID, spans}}
 	}
 
 	var p PhysicalPlan
@@ -1193,6 +1211,9 @@ func (dsp *DistSQLPlanner) addAggregators(
 		if fholder.argRenderIdx != noRenderIdx {
 			aggregations[i].ColIdx = []uint32{uint32(p.PlanToStreamColMap[fholder.argRenderIdx])}
 		}
+		for _, idx := range fholder.groupingArgs {
+			aggregations[i].ColIdx = append(aggregations[i].ColIdx, uint32(p.PlanToStreamColMap[idx]))
+		}
 		if fholder.hasFilter() {
 			col := uint32(p.PlanToStreamColMap[fholder.filterRenderIdx])
 			aggregations[i].FilterColIdx = &col
@@ -1216,6 +1237,14 @@ func (dsp *DistSQLPlanner) addAggregators(
 		orderedGroupCols[i] = uint32(p.PlanToStreamColMap[idx])
 		orderedGroupColSet.Add(i)
 	}
+	var groupingSets []distsqlrun.AggregatorSpec_GroupingSet
+	for _, set := range n.groupingSets {
+		cols := make([]uint32, 0, set.Len())
+		set.ForEach(func(idx int) {
+			cols = append(cols, uint32(p.PlanToStreamColMap[idx]))
+		})
+		groupingSets = append(groupingSets, distsqlrun.AggregatorSpec_GroupingSet{Cols: cols})
+	}
 
 	// We either have a local stage on each stream followed by a final stage, or
 	// just a final stage. We only use a local stage if:
@@ -1226,6 +1255,8 @@ func (dsp *DistSQLPlanner) addAggregators(
 	//  - we have a mix of aggregations that use distinct and aggregations that
 	//    don't use distinct. TODO(arjun): This would require doing the same as
 	//    the todo as above.
+	//  - there are no grouping sets; the final stage would need to know from
+	//    which set each local result comes.
 	multiStage := false
 	allDistinct := true
 	anyDistinct := false
@@ -1239,7 +1270,7 @@ func (dsp *DistSQLPlanner) addAggregators(
 		}
 	}
 
-	if prevStageNode == 0 {
+	if prevStageNode == 0 && n.groupingSets == nil {
 		// Check that all aggregation functions support a local stage.
 		multiStage = true
 		for _, e := range aggregations {
@@ -1316,6 +1347,7 @@ func (dsp *DistSQLPlanner) addAggregators(
 			Aggregations:     aggregations,
 			GroupCols:        groupCols,
 			OrderedGroupCols: orderedGroupCols,
+			GroupingSets:     groupingSets,
 		}
 	} else {
 		// Some aggregations might need multiple aggregation as part of
@@ -1719,6 +1751,22 @@ func (dsp *DistSQLPlanner) createPlanForIndexJoin(
 		return PhysicalPlan{}, err
 	}
 
+	if n.mayProduceDuplicates() {
+		// Remove the duplicate primary keys before the lookups, first on each
+		// stream and then on a single node.
+		distinctColumns := make([]uint32, len(plan.ResultTypes))
+		for i := range distinctColumns {
+			distinctColumns[i] = uint32(i)
+		}
+		distinctSpec := distsqlrun.ProcessorCoreUnion{
+			Distinct: &distsqlrun.DistinctSpec{DistinctColumns: distinctColumns},
+		}
+		plan.AddNoGroupingStage(distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes, plan.MergeOrdering)
+		if len(plan.ResultRouters) > 1 {
+			plan.AddSingleGroupStage(dsp.nodeDesc.NodeID, distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes)
+		}
+	}
+
 	joinReaderSpec := distsqlrun.JoinReaderSpec{
 		Table:    *n.index.desc,
 		IndexIdx: 0,
@@ -1861,6 +1909,111 @@ func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 	return plan, nil
 }
 
+// createPlanForZigzagJoin creates a plan with a single ZigzagJoiner processor
+// for a zigzagJoinNode, located on the gateway node.
+func (dsp *DistSQLPlanner) createPlanForZigzagJoin(
+	planCtx *PlanningCtx, n *zigzagJoinNode,
+) (PhysicalPlan, error) {
+	var a sqlbase.DatumAlloc
+	zigzagJoinerSpec := distsqlrun.ZigzagJoinerSpec{
+		Tables:      make([]sqlbase.TableDescriptor, len(n.sides)),
+		EqColumns:   make([]distsqlrun.Columns, len(n.sides)),
+		IndexIds:    make([]uint32, len(n.sides)),
+		FixedValues: make([]distsqlrun.ValuesCoreSpec, len(n.sides)),
+		Type:        sqlbase.InnerJoin,
+	}
+
+	// The internal schema of the zigzag joiner is:
+	//    <side 1 table columns> ... <side 2 table columns> ...
+	// Apply a projection to produce the columns of the scan nodes.
+	post := distsqlrun.PostProcessSpec{Projection: true}
+	post.OutputColumns = make([]uint32, len(n.columns))
+	types := make([]sqlbase.ColumnType, len(n.columns))
+	planToStreamColMap := makePlanToStreamColMap(len(n.columns))
+	numInternalCols := 0
+	numOutCols := 0
+	for i := range n.sides {
+		side := &n.sides[i]
+		desc := side.scan.desc
+		zigzagJoinerSpec.Tables[i] = *desc
+		var err error
+		zigzagJoinerSpec.IndexIds[i], err = getIndexIdx(side.scan)
+		if err != nil {
+			return PhysicalPlan{}, err
+		}
+
+		// The equality columns are given as table ordinals.
+		eqCols := make([]uint32, len(side.eqCols))
+		for j, col := range side.eqCols {
+			eqCols[j] = uint32(tableOrdinal(desc, side.scan.cols[col].ID))
+		}
+		zigzagJoinerSpec.EqColumns[i].Columns = eqCols
+
+		// The fixed values are encoded as a single row, like a Values processor.
+		fixedVals := distsqlrun.ValuesCoreSpec{
+			Columns: make([]distsqlrun.DatumInfo, len(side.fixedVals)),
+			NumRows: 1,
+		}
+		var buf []byte
+		for j, val := range side.fixedVals {
+			colID := side.scan.index.ColumnIDs[j]
+			typ := desc.Columns[tableOrdinal(desc, colID)].Type
+			fixedVals.Columns[j].Encoding = sqlbase.DatumEncoding_VALUE
+			fixedVals.Columns[j].Type = typ
+			datum := sqlbase.DatumToEncDatum(typ, val)
+			buf, err = datum.Encode(&typ, &a, sqlbase.DatumEncoding_VALUE, buf)
+			if err != nil {
+				return PhysicalPlan{}, err
+			}
+		}
+		fixedVals.RawBytes = [][]byte{buf}
+		zigzagJoinerSpec.FixedValues[i] = fixedVals
+
+		for j := range side.scan.cols {
+			types[numOutCols] = side.scan.cols[j].Type
+			ord := tableOrdinal(desc, side.scan.cols[j].ID)
+			post.OutputColumns[numOutCols] = uint32(numInternalCols + ord)
+			planToStreamColMap[numOutCols] = numOutCols
+			numOutCols++
+		}
+		numInternalCols += len(desc.Columns)
+	}
+
+	// Set the ON condition.
+	if n.onCond != nil {
+		// Note that the ON condition refers to the *internal* columns of the
+		// processor (before the OutputColumns projection).
+		indexVarMap := makePlanToStreamColMap(len(n.columns))
+		for i := range n.columns {
+			indexVarMap[i] = int(post.OutputColumns[i])
+		}
+		var err error
+		zigzagJoinerSpec.OnExpr, err = distsqlplan.MakeExpression(
+			n.onCond, planCtx.EvalContext(), indexVarMap,
+		)
+		if err != nil {
+			return PhysicalPlan{}, err
+		}
+	}
+
+	plan := distsqlplan.PhysicalPlan{
+		Processors: []distsqlplan.Processor{{
+			Node: dsp.nodeDesc.NodeID,
+			Spec: distsqlrun.ProcessorSpec{
+				Core:   distsqlrun.ProcessorCoreUnion{ZigzagJoiner: &zigzagJoinerSpec},
+				Post:   post,
+				Output: []distsqlrun.OutputRouterSpec{{Type: distsqlrun.OutputRouterSpec_PASS_THROUGH}},
+			},
+		}},
+		ResultRouters: []distsqlplan.ProcessorIdx{0},
+		ResultTypes:   types,
+	}
+	return PhysicalPlan{
+		PhysicalPlan:       plan,
+		PlanToStreamColMap: planToStreamColMap,
+	}, nil
+}
+
 // getTypesForPlanResult returns the types of the elements in the result streams
 // of a plan that corresponds to a given planNode. If planToStreamColMap is nil,
 // a 1-1 mapping is assumed.
@@ -2156,6 +2309,10 @@ func verifyLookupJoin(
 		return false, nil, "scan node was generated by the optimizer"
 	}
 
+	if lookupJoinScan.sample != nil {
+		return false, nil, "scan node uses TABLESAMPLE"
+	}
+
 	// Check if rightEqCols are prefix of index columns in scanNode lookupJoinScan.
 	rightEqColsMap := make(map[int]bool, len(n.pred.rightEqualityIndices))
 	for _, rightColID := range n.pred.rightEqualityIndices {
@@ -2210,6 +2367,9 @@ func (dsp *DistSQLPlanner) createPlanForNode(
 	case *lookupJoinNode:
 		plan, err = dsp.createPlanForLookupJoin(planCtx, n)
 
+	case *zigzagJoinNode:
+		plan, err = dsp.createPlanForZigzagJoin(planCtx, n)
+
 	case *joinNode:
 		plan, err = dsp.createPlanForJoin(planCtx, n)
 
@@ -2308,6 +2468,10 @@ func (dsp *DistSQLPlanner) createPlanForNode(
 		return plan, err
 	}
 
+	if planCtx.processorPlanNodes != nil {
+		planCtx.processorPlanNodes.record(&plan, node)
+	}
+
 	if dsp.shouldPlanTestMetadata() {
 		if err := plan.CheckLastStagePost(); err != nil {
 			log.Fatal(planCtx.ctx, err)
@@ -3164,6 +3328,10 @@ func (dsp *DistSQLPlanner) FinalizePlan(planCtx *PlanningCtx, plan *PhysicalPlan
 		Type: distsqlrun.StreamEndpointSpec_SYNC_RESPONSE,
 	})
 
+	if planCtx.processorPlanNodes != nil {
+		planCtx.processorPlanNodes.finalize(plan)
+	}
+
 	// Assign processor IDs.
 	for i := range plan.Processors {
 		plan.Processors[i].Spec.ProcessorID = int32(i)
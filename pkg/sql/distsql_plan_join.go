@@ -367,6 +367,11 @@ func useInterleavedJoin(n *joinNode) bool {
 		return false
 	}
 
+	// The interleaved reader doesn't support TABLESAMPLE.
+	if ancestor.sample != nil || descendant.sample != nil {
+		return false
+	}
+
 	var ancestorEqIndices []int
 	var descendantEqIndices []int
 	// We are guaranteed that both of the sources are scan nodes from
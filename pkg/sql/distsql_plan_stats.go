@@ -15,6 +15,7 @@
 package sql
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -31,8 +32,14 @@ type requestedStat struct {
 const histogramSamples = 10000
 const histogramBuckets = 200
 
+// createStatsPlan creates the physical plan that collects the requested
+// statistics on the given table. If maxFractionIdle is non-zero, the sampler
+// processors are throttled (see SamplerSpec.MaxFractionIdle).
 func (dsp *DistSQLPlanner) createStatsPlan(
-	planCtx *PlanningCtx, desc *sqlbase.TableDescriptor, stats []requestedStat,
+	planCtx *PlanningCtx,
+	desc *sqlbase.TableDescriptor,
+	stats []requestedStat,
+	maxFractionIdle float64,
 ) (PhysicalPlan, error) {
 	// Create the table readers; for this we initialize a dummy scanNode.
 	scan := scanNode{desc: desc}
@@ -104,7 +111,10 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	}
 
 	// Set up the samplers.
-	sampler := &distsqlrun.SamplerSpec{Sketches: sketchSpecs}
+	sampler := &distsqlrun.SamplerSpec{
+		Sketches:        sketchSpecs,
+		MaxFractionIdle: maxFractionIdle,
+	}
 	for _, s := range stats {
 		if s.histogram {
 			sampler.SampleSize = histogramSamples
@@ -168,5 +178,24 @@ func (dsp *DistSQLPlanner) createPlanForCreateStats(
 		},
 	}
 
-	return dsp.createStatsPlan(planCtx, n.tableDesc, stats)
+	return dsp.createStatsPlan(planCtx, n.tableDesc, stats, 0 /* maxFractionIdle */)
+}
+
+// createPlanForCreateStatsJob creates the plan for a statistics job started by
+// the automatic statistics refresher. A histogram is collected for each
+// single-column statistic.
+func (dsp *DistSQLPlanner) createPlanForCreateStatsJob(
+	planCtx *PlanningCtx, desc *sqlbase.TableDescriptor, details *jobspb.CreateStatsDetails,
+) (PhysicalPlan, error) {
+	stats := make([]requestedStat, len(details.ColumnLists))
+	for i := range details.ColumnLists {
+		stats[i] = requestedStat{
+			columns:             details.ColumnLists[i].IDs,
+			histogram:           len(details.ColumnLists[i].IDs) == 1,
+			histogramMaxBuckets: histogramBuckets,
+			name:                details.Name,
+		}
+	}
+
+	return dsp.createStatsPlan(planCtx, desc, stats, details.MaxFractionIdle)
 }
@@ -25,6 +25,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -51,6 +53,14 @@ func GetAggregateInfo(
 		}
 		return builtins.NewAnyNotNullAggregate, inputTypes[0], nil
 	}
+	if fn == AggregatorSpec_GROUPING {
+		// GROUPING is not accumulated; the aggregator adds its result to an
+		// ANY_NOT_NULL aggregate when the bucket is created.
+		if len(inputTypes) == 0 {
+			return nil, sqlbase.ColumnType{}, errors.Errorf("grouping aggregate needs at least 1 input")
+		}
+		return builtins.NewAnyNotNullAggregate, sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}, nil
+	}
 	datumTypes := make([]types.T, len(inputTypes))
 	for i := range inputTypes {
 		datumTypes[i] = inputTypes[i].ToDatumType()
@@ -128,6 +138,12 @@ type aggregatorBase struct {
 	orderedGroupCols columns
 	aggregations     []AggregatorSpec_Aggregation
 
+	// groupingSets are the grouping sets of the aggregation, if any (see
+	// AggregatorSpec.GroupingSets), and excludedGroupCols[i] is the set of
+	// group columns that are not part of groupingSets[i].
+	groupingSets      []columns
+	excludedGroupCols []util.FastIntSet
+
 	lastOrdGroupCols sqlbase.EncDatumRow
 	arena            stringarena.Arena
 	row              sqlbase.EncDatumRow
@@ -168,6 +184,27 @@ func (ag *aggregatorBase) init(
 	}
 	ag.groupCols = spec.GroupCols
 	ag.orderedGroupCols = spec.OrderedGroupCols
+	if len(spec.GroupingSets) > 0 {
+		if len(spec.OrderedGroupCols) > 0 {
+			return errors.Errorf("ordered group columns are not supported with grouping sets")
+		}
+		var groupCols util.FastIntSet
+		for _, c := range spec.GroupCols {
+			groupCols.Add(int(c))
+		}
+		ag.groupingSets = make([]columns, len(spec.GroupingSets))
+		ag.excludedGroupCols = make([]util.FastIntSet, len(spec.GroupingSets))
+		for i, set := range spec.GroupingSets {
+			ag.excludedGroupCols[i] = groupCols.Copy()
+			for _, c := range set.Cols {
+				if !groupCols.Contains(int(c)) {
+					return errors.Errorf("grouping set column %d is not a group column", c)
+				}
+				ag.excludedGroupCols[i].Remove(int(c))
+			}
+			ag.groupingSets[i] = set.Cols
+		}
+	}
 	ag.aggregations = spec.Aggregations
 	ag.funcs = make([]*aggregateFuncHolder, len(spec.Aggregations))
 	ag.outputTypes = make([]sqlbase.ColumnType, len(spec.Aggregations))
@@ -310,7 +347,7 @@ func newAggregator(
 	post *PostProcessSpec,
 	output RowReceiver,
 ) (Processor, error) {
-	if len(spec.OrderedGroupCols) == len(spec.GroupCols) {
+	if len(spec.OrderedGroupCols) == len(spec.GroupCols) && len(spec.GroupingSets) == 0 {
 		return newOrderedAggregator(flowCtx, processorID, spec, input, post, output)
 	}
 
@@ -469,14 +506,28 @@ func (ag *hashAggregator) accumulateRows() (aggregatorState, sqlbase.EncDatumRow
 
 	// Queries like `SELECT MAX(n) FROM t` expect a row of NULLs if nothing was
 	// aggregated.
-	if len(ag.buckets) < 1 && len(ag.groupCols) == 0 {
-		bucket, err := ag.createAggregateFuncs()
+	if len(ag.buckets) < 1 && len(ag.groupCols) == 0 && len(ag.groupingSets) == 0 {
+		bucket, err := ag.createAggregateFuncs(util.FastIntSet{})
 		if err != nil {
 			ag.MoveToDraining(err)
 			return aggStateUnknown, nil, nil
 		}
 		ag.buckets[""] = bucket
 	}
+	// Similarly, each empty grouping set produces a row.
+	if len(ag.buckets) < 1 && ag.isScalar {
+		for i, set := range ag.groupingSets {
+			if len(set) > 0 {
+				continue
+			}
+			bucket, err := ag.createAggregateFuncs(ag.excludedGroupCols[i])
+			if err != nil {
+				ag.MoveToDraining(err)
+				return aggStateUnknown, nil, nil
+			}
+			ag.buckets[string(encoding.EncodeUvarintAscending(nil, uint64(i)))] = bucket
+		}
+	}
 
 	ag.bucketsIter = make([]string, 0, len(ag.buckets))
 	for bucket := range ag.buckets {
@@ -530,7 +581,7 @@ func (ag *orderedAggregator) accumulateRows() (aggregatorState, sqlbase.EncDatum
 	// aggregated.
 	if ag.bucket == nil && ag.isScalar {
 		var err error
-		ag.bucket, err = ag.createAggregateFuncs()
+		ag.bucket, err = ag.createAggregateFuncs(util.FastIntSet{})
 		if err != nil {
 			ag.MoveToDraining(err)
 			return aggStateUnknown, nil, nil
@@ -713,11 +764,23 @@ func (ag *orderedAggregator) ConsumerClosed() {
 	ag.close()
 }
 
+// accumulateRowIntoBucket feeds a row to the aggregate functions of a bucket.
+// excludedGroupCols are the group columns that are not part of the grouping
+// set of the bucket; they are NULL in the output.
 func (ag *aggregatorBase) accumulateRowIntoBucket(
-	row sqlbase.EncDatumRow, groupKey []byte, bucket aggregateFuncs,
+	row sqlbase.EncDatumRow, groupKey []byte, bucket aggregateFuncs, excludedGroupCols util.FastIntSet,
 ) error {
 	// Feed the func holders for this bucket the non-grouping datums.
 	for i, a := range ag.aggregations {
+		switch a.Func {
+		case AggregatorSpec_GROUPING:
+			// The result was set up when the bucket was created.
+			continue
+		case AggregatorSpec_ANY_NOT_NULL:
+			if len(a.ColIdx) == 1 && excludedGroupCols.Contains(int(a.ColIdx[0])) {
+				continue
+			}
+		}
 		if a.FilterColIdx != nil {
 			col := *a.FilterColIdx
 			if err := row[col].EnsureDecoded(&ag.inputTypes[col], &ag.datumAlloc); err != nil {
@@ -772,9 +835,28 @@ func (ag *hashAggregator) accumulateRow(row sqlbase.EncDatumRow) error {
 		return err
 	}
 
+	if len(ag.groupingSets) == 0 {
+		return ag.accumulateRowForGroupCols(row, ag.scratch, ag.groupCols, util.FastIntSet{})
+	}
+	// The row is accumulated once for each grouping set, into a bucket keyed by
+	// the index of the set and the values of its columns.
+	for i, set := range ag.groupingSets {
+		prefix := encoding.EncodeUvarintAscending(ag.scratch, uint64(i))
+		if err := ag.accumulateRowForGroupCols(row, prefix, set, ag.excludedGroupCols[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accumulateRowForGroupCols accumulates a single row into the bucket for the
+// given group columns; the bucket key is appended to prefix.
+func (ag *hashAggregator) accumulateRowForGroupCols(
+	row sqlbase.EncDatumRow, prefix []byte, groupCols columns, excludedGroupCols util.FastIntSet,
+) error {
 	// The encoding computed here determines which bucket the non-grouping
 	// datums are accumulated to.
-	encoded, err := ag.encode(ag.scratch, row)
+	encoded, err := ag.encode(prefix, groupCols, row)
 	if err != nil {
 		return err
 	}
@@ -786,14 +868,14 @@ func (ag *hashAggregator) accumulateRow(row sqlbase.EncDatumRow) error {
 		if err != nil {
 			return err
 		}
-		bucket, err = ag.createAggregateFuncs()
+		bucket, err = ag.createAggregateFuncs(excludedGroupCols)
 		if err != nil {
 			return err
 		}
 		ag.buckets[s] = bucket
 	}
 
-	return ag.accumulateRowIntoBucket(row, encoded, bucket)
+	return ag.accumulateRowIntoBucket(row, encoded, bucket, excludedGroupCols)
 }
 
 // accumulateRow accumulates a single row, returning an error if accumulation
@@ -805,13 +887,13 @@ func (ag *orderedAggregator) accumulateRow(row sqlbase.EncDatumRow) error {
 
 	if ag.bucket == nil {
 		var err error
-		ag.bucket, err = ag.createAggregateFuncs()
+		ag.bucket, err = ag.createAggregateFuncs(util.FastIntSet{})
 		if err != nil {
 			return err
 		}
 	}
 
-	return ag.accumulateRowIntoBucket(row, nil /* groupKey */, ag.bucket)
+	return ag.accumulateRowIntoBucket(row, nil /* groupKey */, ag.bucket, util.FastIntSet{})
 }
 
 type aggregateFuncHolder struct {
@@ -866,9 +948,9 @@ func (a *aggregateFuncHolder) canAdd(
 // encode returns the encoding for the grouping columns, this is then used as
 // our group key to determine which bucket to add to.
 func (ag *aggregatorBase) encode(
-	appendTo []byte, row sqlbase.EncDatumRow,
+	appendTo []byte, groupCols columns, row sqlbase.EncDatumRow,
 ) (encoding []byte, err error) {
-	for _, colIdx := range ag.groupCols {
+	for _, colIdx := range groupCols {
 		appendTo, err = row[colIdx].Encode(
 			&ag.inputTypes[colIdx], &ag.datumAlloc, sqlbase.DatumEncoding_ASCENDING_KEY, appendTo)
 		if err != nil {
@@ -878,7 +960,12 @@ func (ag *aggregatorBase) encode(
 	return appendTo, nil
 }
 
-func (ag *aggregatorBase) createAggregateFuncs() (aggregateFuncs, error) {
+// createAggregateFuncs creates the aggregate functions of a bucket.
+// excludedGroupCols are the group columns that are not part of the grouping
+// set of the bucket, which determine the result of GROUPING aggregations.
+func (ag *aggregatorBase) createAggregateFuncs(
+	excludedGroupCols util.FastIntSet,
+) (aggregateFuncs, error) {
 	if err := ag.bucketsAcc.Grow(ag.Ctx, sizeOfAggregateFunc*int64(len(ag.funcs))); err != nil {
 		return nil, err
 	}
@@ -887,6 +974,19 @@ func (ag *aggregatorBase) createAggregateFuncs() (aggregateFuncs, error) {
 		// TODO(radu): we should account for the size of impl (this needs to be done
 		// in each aggregate constructor).
 		bucket[i] = f.create(ag.flowCtx.EvalCtx)
+		if a := &ag.aggregations[i]; a.Func == AggregatorSpec_GROUPING {
+			// The leftmost argument is the most significant bit.
+			var mask int64
+			for _, c := range a.ColIdx {
+				mask <<= 1
+				if excludedGroupCols.Contains(int(c)) {
+					mask |= 1
+				}
+			}
+			if err := bucket[i].Add(ag.Ctx, tree.NewDInt(tree.DInt(mask))); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return bucket, nil
 }
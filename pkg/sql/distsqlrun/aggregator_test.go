@@ -52,6 +52,41 @@ func TestAggregator(t *testing.T) {
 
 	colPtr := func(idx uint32) *uint32 { return &idx }
 
+	rollupSpec := AggregatorSpec{
+		GroupCols: []uint32{1, 2},
+		GroupingSets: []AggregatorSpec_GroupingSet{
+			{Cols: []uint32{1, 2}},
+			{Cols: []uint32{1}},
+			{},
+		},
+		Aggregations: []AggregatorSpec_Aggregation{
+			{
+				Func:   AggregatorSpec_ANY_NOT_NULL,
+				ColIdx: []uint32{1},
+			},
+			{
+				Func:   AggregatorSpec_ANY_NOT_NULL,
+				ColIdx: []uint32{2},
+			},
+			{
+				Func:   AggregatorSpec_SUM,
+				ColIdx: []uint32{0},
+			},
+			{
+				Func:   AggregatorSpec_GROUPING,
+				ColIdx: []uint32{1, 2},
+			},
+		},
+	}
+	scalarRollupSpec := rollupSpec
+	scalarRollupSpec.Type = AggregatorSpec_SCALAR
+	rollupOutputTypes := []sqlbase.ColumnType{
+		intType, // ANY_NOT_NULL
+		intType, // ANY_NOT_NULL
+		decType, // SUM
+		intType, // GROUPING
+	}
+
 	testCases := []struct {
 		spec        AggregatorSpec
 		inputTypes  []sqlbase.ColumnType
@@ -407,6 +442,36 @@ func TestAggregator(t *testing.T) {
 				{v[2], v[3], v[3]},
 			},
 		},
+		{
+			// SELECT @2, @3, sum(@1), grouping(@2, @3) GROUP BY ROLLUP (@2, @3).
+			spec:       rollupSpec,
+			inputTypes: threeIntCols,
+			input: sqlbase.EncDatumRows{
+				{v[1], v[2], v[3]},
+				{v[2], v[2], v[4]},
+				{v[4], v[3], v[3]},
+			},
+			outputTypes: rollupOutputTypes,
+			expected: sqlbase.EncDatumRows{
+				{v[2], v[3], v[1], v[0]},
+				{v[2], v[4], v[2], v[0]},
+				{v[3], v[3], v[4], v[0]},
+				{v[2], null, v[3], v[1]},
+				{v[3], null, v[4], v[1]},
+				{null, null, v[7], v[3]},
+			},
+		},
+		{
+			// SELECT @2, @3, sum(@1), grouping(@2, @3) GROUP BY ROLLUP (@2, @3)
+			// (no rows).
+			spec:        scalarRollupSpec,
+			inputTypes:  threeIntCols,
+			input:       sqlbase.EncDatumRows{},
+			outputTypes: rollupOutputTypes,
+			expected: sqlbase.EncDatumRows{
+				{null, null, null, v[3]},
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -103,6 +103,13 @@ func (a *AggregatorSpec) summary() (string, []string) {
 	if len(a.OrderedGroupCols) > 0 {
 		details = append(details, fmt.Sprintf("Ordered: %s", colListStr(a.OrderedGroupCols)))
 	}
+	if len(a.GroupingSets) > 0 {
+		sets := make([]string, len(a.GroupingSets))
+		for i, set := range a.GroupingSets {
+			sets[i] = fmt.Sprintf("(%s)", colListStr(set.Cols))
+		}
+		details = append(details, fmt.Sprintf("Grouping sets: %s", strings.Join(sets, ", ")))
+	}
 	for _, agg := range a.Aggregations {
 		var buf bytes.Buffer
 		buf.WriteString(agg.Func.String())
@@ -229,6 +236,30 @@ func (mj *MergeJoinerSpec) summary() (string, []string) {
 	return name, orderedJoinDetails(mj.Type, mj.LeftOrdering, mj.RightOrdering, mj.OnExpr)
 }
 
+// summary implements the diagramCellType interface.
+func (zj *ZigzagJoinerSpec) summary() (string, []string) {
+	details := make([]string, 0, len(zj.Tables)*2+2)
+	for i := range zj.Tables {
+		// As of right now, we only plan zigzag joins with two sides.
+		tableLabel := "Left"
+		if i > 0 {
+			tableLabel = "Right"
+		}
+		details = append(details, tableLabel)
+		details = append(details, indexDetails(zj.IndexIds[i], &zj.Tables[i])...)
+	}
+	if len(zj.EqColumns) > 1 {
+		details = append(details, fmt.Sprintf(
+			"left(%s)=right(%s)",
+			colListStr(zj.EqColumns[0].Columns), colListStr(zj.EqColumns[1].Columns),
+		))
+	}
+	if zj.OnExpr.Expr != "" {
+		details = append(details, fmt.Sprintf("ON %s", zj.OnExpr.Expr))
+	}
+	return "ZigzagJoiner", details
+}
+
 // summary implements the diagramCellType interface.
 func (irj *InterleavedReaderJoinerSpec) summary() (string, []string) {
 	// As of right now, we only plan InterleaveReaderJoiner with two
@@ -56,7 +56,7 @@ func newIndexBackfiller(
 	}
 	ib.backfiller.chunkBackfiller = ib
 
-	if err := ib.IndexBackfiller.Init(ib.spec.Table); err != nil {
+	if err := ib.IndexBackfiller.Init(ib.flowCtx.NewEvalCtx(), ib.spec.Table); err != nil {
 		return nil, err
 	}
 
@@ -35,6 +35,11 @@ import (
 // processorIDTagKey is the key used for processor id tags in tracing spans.
 const processorIDTagKey = tracing.TagPrefix + "processorid"
 
+// outputRowsTagKey is the key used for the number of rows emitted by a
+// processor in tracing spans. It is not prefixed with tracing.TagPrefix so
+// that it is not output in SHOW TRACE.
+const outputRowsTagKey = "distsql.outputrows"
+
 // Processor is a common interface implemented by all processors, used by the
 // higher-level flow orchestration code.
 type Processor interface {
@@ -383,6 +388,15 @@ func (h *ProcOutputHelper) Close() {
 	h.output.ProducerDone()
 }
 
+// numRowsEmitted returns the number of rows that passed the filter and were
+// not suppressed by the offset.
+func (h *ProcOutputHelper) numRowsEmitted() uint64 {
+	if h.rowIdx <= h.offset {
+		return 0
+	}
+	return h.rowIdx - h.offset
+}
+
 // consumerClosed stops output of additional rows from ProcessRow.
 func (h *ProcOutputHelper) consumerClosed() {
 	h.rowIdx = h.maxRowIdx
@@ -836,6 +850,9 @@ func (pb *ProcessorBase) InternalClose() bool {
 		}
 
 		pb.closed = true
+		if pb.span != nil && tracing.IsRecording(pb.span) {
+			pb.span.SetTag(outputRowsTagKey, pb.out.numRowsEmitted())
+		}
 		tracing.FinishSpan(pb.span)
 		pb.span = nil
 		// Reset the context so that any incidental uses after this point do not
@@ -953,6 +970,13 @@ func newProcessor(
 		}
 		return newJoinReader(flowCtx, processorID, core.JoinReader, inputs[0], post, outputs[0])
 	}
+	if core.ZigzagJoiner != nil {
+		if err := checkNumInOut(inputs, outputs, 0, 1); err != nil {
+			return nil, err
+		}
+		return newZigzagJoiner(
+			flowCtx, processorID, core.ZigzagJoiner, nil /* fixedValues */, post, outputs[0])
+	}
 	if core.Sorter != nil {
 		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
 			return nil, err
@@ -17,6 +17,7 @@ package distsqlrun
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/axiomhq/hyperloglog"
 	"github.com/pkg/errors"
@@ -25,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
@@ -53,12 +55,20 @@ type samplerProcessor struct {
 	numRowsCol   int
 	numNullsCol  int
 	sketchCol    int
+
+	// maxFractionIdle is the fraction of time the processor spends idle; see
+	// SamplerSpec.MaxFractionIdle.
+	maxFractionIdle float64
 }
 
 var _ Processor = &samplerProcessor{}
 
 const samplerProcName = "sampler"
 
+// samplerThrottleRows is the number of rows processed between throttling
+// checks when SamplerSpec.MaxFractionIdle is set.
+const samplerThrottleRows = 10000
+
 var supportedSketchTypes = map[SketchType]struct{}{
 	// The code currently hardcodes the use of this single type of sketch
 	// (which avoids the extra complexity until we actually have multiple types).
@@ -77,15 +87,16 @@ func newSamplerProcessor(
 		if _, ok := supportedSketchTypes[s.SketchType]; !ok {
 			return nil, errors.Errorf("unsupported sketch type %s", s.SketchType)
 		}
-		if len(s.Columns) != 1 {
-			return nil, errors.Errorf("multi-column sketches not supported yet")
+		if len(s.Columns) == 0 {
+			return nil, errors.Errorf("no columns")
 		}
 	}
 
 	s := &samplerProcessor{
-		flowCtx:  flowCtx,
-		input:    input,
-		sketches: make([]sketchInfo, len(spec.Sketches)),
+		flowCtx:         flowCtx,
+		input:           input,
+		sketches:        make([]sketchInfo, len(spec.Sketches)),
+		maxFractionIdle: spec.MaxFractionIdle,
 	}
 	for i := range spec.Sketches {
 		s.sketches[i] = sketchInfo{
@@ -165,6 +176,10 @@ func (s *samplerProcessor) mainLoop(ctx context.Context) (earlyExit bool, _ erro
 	var da sqlbase.DatumAlloc
 	var ra sqlbase.EncDatumRowAlloc
 	var buf []byte
+	rowCount := 0
+	lastWakeup := timeutil.Now()
+	var timer timeutil.Timer
+	defer timer.Stop()
 	for {
 		row, meta := s.input.Next()
 		if meta != nil {
@@ -178,22 +193,47 @@ func (s *samplerProcessor) mainLoop(ctx context.Context) (earlyExit bool, _ erro
 			break
 		}
 
+		rowCount++
+		if s.maxFractionIdle > 0 && rowCount%samplerThrottleRows == 0 {
+			// Sleep for a duration proportional to the time spent working since
+			// the last wakeup, so that the processor is idle for the requested
+			// fraction of time.
+			elapsed := timeutil.Since(lastWakeup)
+			wait := time.Duration(float64(elapsed) * s.maxFractionIdle / (1 - s.maxFractionIdle))
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-timer.C:
+				timer.Read = true
+			}
+			lastWakeup = timeutil.Now()
+		}
+
 		for i := range s.sketches {
-			// TODO(radu): for multi-column sketches, we will need to do this for all
-			// columns.
-			col := s.sketches[i].spec.Columns[0]
 			s.sketches[i].numRows++
-			if row[col].IsNull() {
+			hasNull := false
+			for _, col := range s.sketches[i].spec.Columns {
+				if row[col].IsNull() {
+					hasNull = true
+					break
+				}
+			}
+			if hasNull {
 				s.sketches[i].numNulls++
 				continue
 			}
 			// We need to use a KEY encoding because equal values should have the same
-			// encoding.
+			// encoding. Key encodings are self-delimiting, so the values of multiple
+			// columns can be concatenated.
 			// TODO(radu): a fast path for simple columns (like integer)?
-			var err error
-			buf, err = row[col].Encode(&s.outTypes[col], &da, sqlbase.DatumEncoding_ASCENDING_KEY, buf[:0])
-			if err != nil {
-				return false, err
+			buf = buf[:0]
+			for _, col := range s.sketches[i].spec.Columns {
+				var err error
+				buf, err = row[col].Encode(&s.outTypes[col], &da, sqlbase.DatumEncoding_ASCENDING_KEY, buf)
+				if err != nil {
+					return false, err
+				}
 			}
 			s.sketches[i].sketch.Insert(buf)
 		}
@@ -27,7 +27,7 @@ import (
 )
 
 // runSampler runs the sampler aggregator on numRows and returns numSamples rows.
-func runSampler(t *testing.T, numRows, numSamples int) []int {
+func runSampler(t *testing.T, numRows, numSamples int, maxFractionIdle float64) []int {
 	rows := make([]sqlbase.EncDatumRow, numRows)
 	for i := range rows {
 		rows[i] = sqlbase.EncDatumRow{intEncDatum(i)}
@@ -52,7 +52,7 @@ func runSampler(t *testing.T, numRows, numSamples int) []int {
 		EvalCtx:  &evalCtx,
 	}
 
-	spec := &SamplerSpec{SampleSize: uint32(numSamples)}
+	spec := &SamplerSpec{SampleSize: uint32(numSamples), MaxFractionIdle: maxFractionIdle}
 	p, err := newSamplerProcessor(&flowCtx, 0 /* processorID */, spec, in, &PostProcessSpec{}, out)
 	if err != nil {
 		t.Fatal(err)
@@ -98,7 +98,7 @@ func TestSampler(t *testing.T) {
 
 	freq := make([]int, numRows)
 	for r := 0; r < numRuns; r++ {
-		for _, v := range runSampler(t, numRows, numSamples) {
+		for _, v := range runSampler(t, numRows, numSamples, 0 /* maxFractionIdle */) {
 			freq[v]++
 		}
 	}
@@ -116,6 +116,14 @@ func TestSampler(t *testing.T) {
 	}
 }
 
+func TestSamplerThrottle(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Process enough rows to go through a few throttling checks; the sampler
+	// should still produce a full sample.
+	runSampler(t, 3*samplerThrottleRows, 100 /* numSamples */, 0.5 /* maxFractionIdle */)
+}
+
 func TestSamplerSketch(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -131,9 +139,10 @@ func TestSamplerSketch(t *testing.T) {
 		{-1, 1},
 		{-1, 3},
 		{1, -1},
+		{2, 1},
 	}
-	cardinalities := []int{2, 8}
-	numNulls := []int{2, 1}
+	cardinalities := []int{2, 8, 9}
+	numNulls := []int{2, 1, 3}
 
 	rows := genEncDatumRowsInt(inputRows)
 	in := NewRowBuffer(twoIntCols, rows, RowBufferArgs{})
@@ -168,6 +177,10 @@ func TestSamplerSketch(t *testing.T) {
 				SketchType: SketchType_HLL_PLUS_PLUS_V1,
 				Columns:    []uint32{1},
 			},
+			{
+				SketchType: SketchType_HLL_PLUS_PLUS_V1,
+				Columns:    []uint32{0, 1},
+			},
 		},
 	}
 	p, err := newSamplerProcessor(&flowCtx, 0 /* processorID */, spec, in, &PostProcessSpec{}, out)
@@ -177,9 +190,9 @@ func TestSamplerSketch(t *testing.T) {
 	p.Run(context.Background(), nil /* wg */)
 
 	rows = out.GetRowsNoMeta(t)
-	// We expect one sampled row and two sketch rows.
-	if len(rows) != 3 {
-		t.Fatalf("expected 3 rows, got %v\n", rows.String(outTypes))
+	// We expect one sampled row and three sketch rows.
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %v\n", rows.String(outTypes))
 	}
 	rows = rows[1:]
 
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
@@ -76,7 +77,7 @@ type DistSQLVersion uint32
 //
 // ATTENTION: When updating these fields, add to version_history.txt explaining
 // what changed.
-const Version DistSQLVersion = 20
+const Version DistSQLVersion = 21
 
 // MinAcceptedVersion is the oldest version that the server is
 // compatible with; see above.
@@ -362,11 +363,12 @@ func (ds *ServerImpl) setupFlow(
 				req.EvalContext.BytesEncodeFormat.String())
 		}
 		sd := &sessiondata.SessionData{
-			ApplicationName: req.EvalContext.ApplicationName,
-			Database:        req.EvalContext.Database,
-			User:            req.EvalContext.User,
-			SearchPath:      sessiondata.MakeSearchPath(req.EvalContext.SearchPath),
-			SequenceState:   sessiondata.NewSequenceState(),
+			ApplicationName:   req.EvalContext.ApplicationName,
+			Database:          req.EvalContext.Database,
+			User:              req.EvalContext.User,
+			ReorderJoinsLimit: xform.DefaultReorderJoinsLimit,
+			SearchPath:        sessiondata.MakeSearchPath(req.EvalContext.SearchPath),
+			SequenceState:     sessiondata.NewSequenceState(),
 			DataConversion: sessiondata.DataConversionConfig{
 				Location:          location,
 				BytesEncodeFormat: be,
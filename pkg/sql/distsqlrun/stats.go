@@ -18,10 +18,12 @@ import (
 	"time"
 
 	"fmt"
+	"strconv"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/gogo/protobuf/types"
 )
 
 // DistSQLSpanStats is a tracing.SpanStats that returns a list of stats to
@@ -95,3 +97,66 @@ func (is InputStats) StatsForQueryPlan(prefix string) []string {
 func (is InputStats) RoundStallTime() time.Duration {
 	return is.StallTime.Round(time.Microsecond)
 }
+
+// ProcessorStats summarizes the execution of a processor, as recorded in its
+// tracing span. It is used by EXPLAIN ANALYZE.
+type ProcessorStats struct {
+	// OutputRows is the number of rows emitted by the processor.
+	OutputRows int64
+	// ReadsTable is set if the processor reads rows from a table (e.g. a
+	// tableReader), in which case RowsRead is the number of rows read.
+	ReadsTable bool
+	RowsRead   int64
+	// Time is the duration of the processor's span.
+	Time time.Duration
+	// MaxAllocatedMem and MaxAllocatedDisk are the maximum memory and disk
+	// used by the processor, for processors that keep track of them.
+	MaxAllocatedMem  int64
+	MaxAllocatedDisk int64
+}
+
+// ExtractProcessorStats returns the ProcessorStats of each processor which
+// has a span in the given recording, keyed by processor ID.
+func ExtractProcessorStats(spans []tracing.RecordedSpan) map[int]ProcessorStats {
+	res := make(map[int]ProcessorStats)
+	for _, span := range spans {
+		pid, ok := span.Tags[processorIDTagKey]
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(pid)
+		if err != nil {
+			continue
+		}
+		ps := ProcessorStats{Time: span.Duration}
+		if rows, ok := span.Tags[outputRowsTagKey]; ok {
+			if ps.OutputRows, err = strconv.ParseInt(rows, 10, 64); err != nil {
+				continue
+			}
+		}
+		if span.Stats != nil {
+			var da types.DynamicAny
+			if err := types.UnmarshalAny(span.Stats, &da); err == nil {
+				switch s := da.Message.(type) {
+				case *TableReaderStats:
+					ps.ReadsTable = true
+					ps.RowsRead = s.InputStats.NumRows
+				case *HashJoinerStats:
+					ps.MaxAllocatedMem, ps.MaxAllocatedDisk = s.MaxAllocatedMem, s.MaxAllocatedDisk
+				case *SorterStats:
+					ps.MaxAllocatedMem, ps.MaxAllocatedDisk = s.MaxAllocatedMem, s.MaxAllocatedDisk
+				case *AggregatorStats:
+					ps.MaxAllocatedMem = s.MaxAllocatedMem
+				case *DistinctStats:
+					ps.MaxAllocatedMem = s.MaxAllocatedMem
+				case *MergeJoinerStats:
+					ps.MaxAllocatedMem = s.MaxAllocatedMem
+				case *WindowerStats:
+					ps.MaxAllocatedMem = s.MaxAllocatedMem
+				}
+			}
+		}
+		res[id] = ps
+	}
+	return res
+}
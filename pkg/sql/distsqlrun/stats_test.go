@@ -15,9 +15,13 @@
 package distsqlrun
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/gogo/protobuf/types"
 )
 
 // TestInputStatCollector verifies that an InputStatCollector correctly collects
@@ -36,3 +40,43 @@ func TestInputStatCollector(t *testing.T) {
 		t.Fatalf("counted %d rows but expected %d", isc.NumRows, numRows)
 	}
 }
+
+// TestExtractProcessorStats verifies that ExtractProcessorStats summarizes the
+// stats found in the spans of processors.
+func TestExtractProcessorStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tableReaderStats, err := types.MarshalAny(&TableReaderStats{InputStats: InputStats{NumRows: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorterStats, err := types.MarshalAny(&SorterStats{MaxAllocatedMem: 100, MaxAllocatedDisk: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := []tracing.RecordedSpan{
+		{
+			Tags:     map[string]string{processorIDTagKey: "0", outputRowsTagKey: "5"},
+			Duration: time.Second,
+			Stats:    tableReaderStats,
+		},
+		{
+			Tags:     map[string]string{processorIDTagKey: "1", outputRowsTagKey: "5"},
+			Duration: 2 * time.Second,
+			Stats:    sorterStats,
+		},
+		{
+			// Spans that don't belong to a processor are ignored.
+			Tags:     map[string]string{streamIDTagKey: "0"},
+			Duration: 3 * time.Second,
+		},
+	}
+
+	expected := map[int]ProcessorStats{
+		0: {OutputRows: 5, ReadsTable: true, RowsRead: 10, Time: time.Second},
+		1: {OutputRows: 5, Time: 2 * time.Second, MaxAllocatedMem: 100, MaxAllocatedDisk: 200},
+	}
+	if res := ExtractProcessorStats(spans); !reflect.DeepEqual(res, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, res)
+	}
+}
@@ -16,6 +16,7 @@ package distsqlrun
 
 import (
 	"context"
+	"math/rand"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
@@ -37,6 +38,11 @@ type tableReader struct {
 	spans     roachpb.Spans
 	limitHint int64
 
+	// If set, each row is only output with probability sampleFraction
+	// (TABLESAMPLE BERNOULLI).
+	sampleRng      *rand.Rand
+	sampleFraction float64
+
 	// input is really the fetcher below, possibly wrapped in a stats generator.
 	input RowSource
 	// fetcher is the underlying RowFetcher, should only be used for
@@ -105,6 +111,11 @@ func newTableReader(
 	}
 	tr.input = &rowFetcherWrapper{RowFetcher: &tr.fetcher}
 
+	if s := spec.BernoulliSample; s != nil {
+		tr.sampleRng = rand.New(rand.NewSource(s.Seed))
+		tr.sampleFraction = s.Fraction
+	}
+
 	if sp := opentracing.SpanFromContext(flowCtx.EvalCtx.Ctx()); sp != nil && tracing.IsRecording(sp) {
 		tr.input = NewInputStatCollector(tr.input)
 		tr.finishTrace = tr.outputStatsToTrace
@@ -203,6 +214,12 @@ func (tr *tableReader) Start(ctx context.Context) context.Context {
 
 	// This call doesn't do much; the real "starting" is below.
 	tr.input.Start(fetcherCtx)
+	if len(tr.spans) == 0 {
+		// There is nothing to read (e.g. TABLESAMPLE SYSTEM did not select any
+		// range).
+		tr.MoveToDraining(nil /* err */)
+		return ctx
+	}
 	if err := tr.fetcher.StartScan(
 		fetcherCtx, tr.flowCtx.txn, tr.spans,
 		true /* limit batches */, tr.limitHint, tr.flowCtx.traceKV,
@@ -225,6 +242,10 @@ func (tr *tableReader) Next() (sqlbase.EncDatumRow, *ProducerMetadata) {
 			break
 		}
 
+		if tr.sampleRng != nil && tr.sampleRng.Float64() >= tr.sampleFraction {
+			continue
+		}
+
 		if outRow := tr.processRowHelper(row); outRow != nil {
 			return outRow, nil
 		}
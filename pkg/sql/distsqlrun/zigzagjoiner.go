@@ -260,6 +260,7 @@ const zigzagJoinerProcName = "zigzagJoiner"
 
 // newZigzagJoiner creates a new zigzag joiner given a spec and an EncDatumRow
 // holding the values of the prefix columns of the index specified in the spec.
+// If fixedValues is nil, the values are decoded from the spec.
 func newZigzagJoiner(
 	flowCtx *FlowCtx,
 	processorID int32,
@@ -300,6 +301,15 @@ func newZigzagJoiner(
 		z.infos[i] = &zigzagJoinerInfo{}
 	}
 
+	if fixedValues == nil {
+		fixedValues = make([]sqlbase.EncDatumRow, len(spec.FixedValues))
+		for i := range spec.FixedValues {
+			if fixedValues[i], err = valuesSpecToEncDatum(&spec.FixedValues[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	colOffset := 0
 	for i := 0; i < z.numTables; i++ {
 		if i < len(fixedValues) {
@@ -314,6 +324,24 @@ func newZigzagJoiner(
 	return z, nil
 }
 
+// valuesSpecToEncDatum converts a ValuesCoreSpec with a single row to an
+// EncDatumRow.
+func valuesSpecToEncDatum(valuesSpec *ValuesCoreSpec) (sqlbase.EncDatumRow, error) {
+	if valuesSpec.NumRows != 1 {
+		return nil, errors.Errorf("expected one row of fixed values, got %d", valuesSpec.NumRows)
+	}
+	res := make(sqlbase.EncDatumRow, len(valuesSpec.Columns))
+	rem := valuesSpec.RawBytes[0]
+	for i, colInfo := range valuesSpec.Columns {
+		var err error
+		res[i], rem, err = sqlbase.EncDatumFromBuffer(&colInfo.Type, colInfo.Encoding, rem)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
 // Start is part of the RowSource interface.
 func (z *zigzagJoiner) Start(ctx context.Context) context.Context {
 	ctx = z.StartInternal(ctx, zigzagJoinerProcName)
@@ -377,9 +405,12 @@ func (z *zigzagJoiner) setupInfo(spec *ZigzagJoinerSpec, side int, colOffset int
 	}
 
 	// Add the fixed columns.
-	indexCols := append(info.index.ColumnIDs, info.index.ExtraColumnIDs...)
+	colIdxMap := info.table.ColumnIdxMap()
+	indexCols := make([]sqlbase.ColumnID, 0, len(info.index.ColumnIDs)+len(info.index.ExtraColumnIDs))
+	indexCols = append(indexCols, info.index.ColumnIDs...)
+	indexCols = append(indexCols, info.index.ExtraColumnIDs...)
 	for i := 0; i < len(info.fixedValues); i++ {
-		neededCols.Add(int(indexCols[i]) - 1)
+		neededCols.Add(colIdxMap[indexCols[i]])
 	}
 
 	// Add the equality columns.
@@ -394,8 +425,8 @@ func (z *zigzagJoiner) setupInfo(spec *ZigzagJoinerSpec, side int, colOffset int
 	_, _, err := initRowFetcher(
 		&(info.fetcher),
 		info.table,
-		int(info.index.ID)-1,
-		info.table.ColumnIdxMap(),
+		int(indexID),
+		colIdxMap,
 		false, /* reverse */
 		neededCols,
 		false, /* check */
@@ -497,9 +528,10 @@ func (z *zigzagJoiner) explicitTypes() []sqlbase.ColumnType {
 	curInfo := z.infos[z.side]
 	indexDescriptor := curInfo.index
 	allTypes := curInfo.table.ColumnTypes()
+	colIdxMap := curInfo.table.ColumnIdxMap()
 	explicitTypes := make([]sqlbase.ColumnType, len(indexDescriptor.ColumnIDs))
 	for i, id := range indexDescriptor.ColumnIDs {
-		explicitTypes[i] = allTypes[id-1]
+		explicitTypes[i] = allTypes[colIdxMap[id]]
 	}
 	return explicitTypes
 }
@@ -563,17 +595,18 @@ func (zi *zigzagJoinerInfo) eqColTypes() []sqlbase.ColumnType {
 // Returns the ordering of the equality columns.
 func (zi *zigzagJoinerInfo) eqOrdering() (sqlbase.ColumnOrdering, error) {
 	ordering := make(sqlbase.ColumnOrdering, len(zi.eqColumnIDs))
-	for i, colID := range zi.eqColumnIDs {
+	for i, colIdx := range zi.eqColumnIDs {
 		// Search the index columns, then the primary keys to find an ordering for
 		// the current column, 'colID'.
+		colID := zi.table.Columns[colIdx].ID
 		var direction encoding.Direction
 		var err error
-		if idx := findColumnID(zi.index.ColumnIDs, sqlbase.ColumnID(colID+1)); idx != -1 {
+		if idx := findColumnID(zi.index.ColumnIDs, colID); idx != -1 {
 			direction, err = zi.index.ColumnDirections[idx].ToEncodingDirection()
 			if err != nil {
 				return nil, err
 			}
-		} else if idx := findColumnID(zi.table.PrimaryIndex.ColumnIDs, sqlbase.ColumnID(colID+1)); idx != -1 {
+		} else if idx := findColumnID(zi.table.PrimaryIndex.ColumnIDs, colID); idx != -1 {
 			direction, err = zi.table.PrimaryIndex.ColumnDirections[idx].ToEncodingDirection()
 			if err != nil {
 				return nil, err
@@ -0,0 +1,117 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type dropFunctionNode struct {
+	n  *tree.DropFunction
+	td []toDelete
+}
+
+// DropFunction drops user-defined functions.
+// Privileges: DROP on function.
+//   Notes: postgres requires ownership of the function.
+func (p *planner) DropFunction(ctx context.Context, n *tree.DropFunction) (planNode, error) {
+	td := make([]toDelete, 0, len(n.Functions))
+	for i := range n.Functions {
+		sig := &n.Functions[i]
+		tn, err := sig.Name.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		droppedDesc, err := p.prepareDrop(ctx, tn, !n.IfExists, requireFunctionDesc)
+		if err != nil {
+			return nil, err
+		}
+		if droppedDesc == nil {
+			// IfExists specified and descriptor does not exist.
+			continue
+		}
+		if sig.HasArgTypes && !functionSignatureMatches(droppedDesc.FunctionOpts, sig.ArgTypes) {
+			if n.IfExists {
+				continue
+			}
+			return nil, pgerror.NewErrorf(pgerror.CodeUndefinedFunctionError,
+				"unknown signature: %s", tree.ErrString(sig))
+		}
+
+		td = append(td, toDelete{tn, droppedDesc})
+	}
+
+	if len(td) == 0 {
+		return newZeroNode(nil /* columns */), nil
+	}
+
+	return &dropFunctionNode{
+		n:  n,
+		td: td,
+	}, nil
+}
+
+func (n *dropFunctionNode) startExec(params runParams) error {
+	ctx := params.ctx
+	for _, toDel := range n.td {
+		droppedDesc := toDel.desc
+		if err := params.p.initiateDropTable(ctx, droppedDesc, true /* drainName */); err != nil {
+			return err
+		}
+		// Log a Drop Function event for this function. This is an auditable
+		// log event and is recorded in the same transaction as the
+		// descriptor update.
+		if err := MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+			ctx,
+			params.p.txn,
+			EventLogDropFunction,
+			int32(droppedDesc.ID),
+			int32(params.extendedEvalCtx.NodeID),
+			struct {
+				FunctionName string
+				Statement    string
+				User         string
+			}{toDel.tn.FQString(), n.n.String(), params.SessionData().User},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropFunctionNode) Close(context.Context)        {}
+
+// functionSignatureMatches returns whether the argument types given in
+// a DROP FUNCTION statement designate the given function.
+func functionSignatureMatches(
+	opts *sqlbase.TableDescriptor_FunctionOpts, argTypes []coltypes.T,
+) bool {
+	if len(opts.Arguments) != len(argTypes) {
+		return false
+	}
+	for i, t := range argTypes {
+		if !coltypes.CastTargetToDatumType(t).Equivalent(opts.Arguments[i].Type.ToDatumType()) {
+			return false
+		}
+	}
+	return true
+}
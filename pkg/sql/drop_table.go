@@ -350,7 +350,7 @@ func (p *planner) initiateDropTable(
 	if drainName {
 		// Queue up name for draining.
 		nameDetails := sqlbase.TableDescriptor_NameInfo{
-			ParentID: tableDesc.ParentID,
+			ParentID: tableDesc.NamespaceParentID(),
 			Name:     tableDesc.Name}
 		tableDesc.DrainingNames = append(tableDesc.DrainingNames, nameDetails)
 	}
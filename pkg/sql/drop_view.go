@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -35,13 +36,17 @@ type dropViewNode struct {
 //   Notes: postgres allows only the view owner to DROP a view.
 //          mysql requires the DROP privilege on the view.
 func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, error) {
+	requiredType := requireViewDesc
+	if n.IsMaterialized {
+		requiredType = requireMaterializedViewDesc
+	}
 	td := make([]toDelete, 0, len(n.Names))
 	for i := range n.Names {
 		tn, err := n.Names[i].Normalize()
 		if err != nil {
 			return nil, err
 		}
-		droppedDesc, err := p.prepareDrop(ctx, tn, !n.IfExists, requireViewDesc)
+		droppedDesc, err := p.prepareDrop(ctx, tn, !n.IfExists, requiredType)
 		if err != nil {
 			return nil, err
 		}
@@ -49,6 +54,11 @@ func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, err
 			// IfExists specified and the view did not exist.
 			continue
 		}
+		if droppedDesc.IsMaterializedView && !n.IsMaterialized {
+			return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+				"%q is a materialized view", tree.ErrString(tn),
+			).SetHintf("use DROP MATERIALIZED VIEW to remove a materialized view")
+		}
 
 		td = append(td, toDelete{tn, droppedDesc})
 	}
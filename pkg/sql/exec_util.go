@@ -279,6 +279,9 @@ type ExecutorConfig struct {
 	VirtualSchemas   *VirtualSchemaHolder
 	DistSQLPlanner   *DistSQLPlanner
 	TableStatsCache  *stats.TableStatisticsCache
+	StatsRefresher   *stats.Refresher
+	NotificationBus  *NotificationBus
+	PlanBaselines    *PlanBaselines
 	ExecLogger       *log.SecondaryLogger
 	AuditLogger      *log.SecondaryLogger
 	InternalExecutor *InternalExecutor
@@ -581,6 +584,8 @@ func checkResultType(typ types.T) error {
 	case types.TimestampTZ:
 	case types.Interval:
 	case types.JSON:
+	case types.TSVector:
+	case types.TSQuery:
 	case types.UUID:
 	case types.INet:
 	case types.NameArray:
@@ -1560,12 +1565,23 @@ func (m *sessionDataMutator) SetOptimizerMode(val sessiondata.OptimizerMode) {
 	m.data.OptimizerMode = val
 }
 
+func (m *sessionDataMutator) SetReorderJoinsLimit(val int) {
+	m.data.ReorderJoinsLimit = val
+}
+
 func (m *sessionDataMutator) SetSafeUpdates(val bool) {
 	m.data.SafeUpdates = val
 }
 
+// SetSearchPath sets the search path. The session's temporary schema, if
+// any, remains part of the search path.
 func (m *sessionDataMutator) SetSearchPath(val sessiondata.SearchPath) {
-	m.data.SearchPath = val
+	m.data.SearchPath = val.WithTemporarySchemaName(m.data.SearchPath.GetTemporarySchemaName())
+}
+
+// SetTemporarySchemaName sets the name of the session's temporary schema.
+func (m *sessionDataMutator) SetTemporarySchemaName(scName string) {
+	m.data.SearchPath = m.data.SearchPath.WithTemporarySchemaName(scName)
 }
 
 func (m *sessionDataMutator) SetLocation(loc *time.Location) {
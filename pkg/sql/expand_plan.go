@@ -140,6 +140,11 @@ func doExpandPlan(
 		explainParams.atTop = true
 		n.plan, err = doExpandPlan(ctx, p, explainParams, n.plan)
 
+	case *explainAnalyzeNode:
+		explainParams := noParamsBase
+		explainParams.atTop = true
+		n.plan, err = doExpandPlan(ctx, p, explainParams, n.plan)
+
 	case *showTraceReplicaNode:
 		n.plan, err = doExpandPlan(ctx, p, noParams, n.plan)
 
@@ -261,11 +266,15 @@ func doExpandPlan(
 			n.props.addWeakKey(groupColSet)
 		}
 
-		groupColProps := planPhysicalProps(n.plan)
-		groupColProps = groupColProps.project(n.groupCols)
-		n.orderedGroupCols = make([]int, len(groupColProps.ordering))
-		for i, o := range groupColProps.ordering {
-			n.orderedGroupCols[i] = o.ColIdx
+		// With grouping sets, the rows of each set are grouped by a different
+		// subset of the group columns, so no ordering can be used.
+		if n.groupingSets == nil {
+			groupColProps := planPhysicalProps(n.plan)
+			groupColProps = groupColProps.project(n.groupCols)
+			n.orderedGroupCols = make([]int, len(groupColProps.ordering))
+			for i, o := range groupColProps.ordering {
+				n.orderedGroupCols[i] = o.ColIdx
+			}
 		}
 
 	case *windowNode:
@@ -340,12 +349,19 @@ func doExpandPlan(
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createFunctionNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
 	case *dropSequenceNode:
+	case *dropFunctionNode:
+	case *listenNode:
+	case *fetchNode:
+	case *closeCursorNode:
+	case *notifyNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -669,6 +685,9 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *explainDistSQLNode:
 		n.plan = p.simplifyOrderings(n.plan, nil)
 
+	case *explainAnalyzeNode:
+		n.plan = p.simplifyOrderings(n.plan, nil)
+
 	case *showTraceReplicaNode:
 		n.plan = p.simplifyOrderings(n.plan, nil)
 
@@ -841,12 +860,19 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createFunctionNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
 	case *dropSequenceNode:
+	case *dropFunctionNode:
+	case *listenNode:
+	case *fetchNode:
+	case *closeCursorNode:
+	case *notifyNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -49,7 +49,12 @@ func (p *planner) Explain(ctx context.Context, n *tree.Explain) (planNode, error
 
 	case tree.ExplainPlan:
 		if opts.Flags.Contains(tree.ExplainFlagAnalyze) {
-			return nil, errors.New("EXPLAIN ANALYZE only supported with (DISTSQL) option")
+			// Without the optimizer, there are no estimates to show.
+			plan, err := p.newPlan(ctx, n.Statement, nil)
+			if err != nil {
+				return nil, err
+			}
+			return p.makeExplainAnalyzeNode(plan, n.Statement.StatementType(), nil /* estimates */), nil
 		}
 		// We may want to show placeholder types, so allow missing values.
 		p.semaCtx.Placeholders.PermitUnassigned()
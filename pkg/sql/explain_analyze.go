@@ -0,0 +1,268 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/treeprinter"
+)
+
+// explainAnalyzeNode is a planNode that implements EXPLAIN ANALYZE: it runs a
+// plan under DistSQL with statistics collection enabled and returns the plan
+// tree, showing for each node the optimizer's estimates next to the statistics
+// collected for the processors that were planned for it.
+type explainAnalyzeNode struct {
+	optColumnsSlot
+
+	plan planNode
+
+	stmtType tree.StatementType
+
+	// estimates contains the optimizer's estimates for the nodes of the plan. It
+	// is nil if the plan was not built by the optimizer.
+	estimates map[exec.Node]exec.Estimates
+
+	run explainAnalyzeRun
+}
+
+// explainAnalyzeRun contains the run-time state of explainAnalyzeNode during
+// local execution.
+type explainAnalyzeRun struct {
+	// results is the container for EXPLAIN ANALYZE's output.
+	results *valuesNode
+}
+
+func (n *explainAnalyzeNode) startExec(params runParams) error {
+	// Trigger limit propagation.
+	params.p.prepareForDistSQLSupportCheck()
+
+	distSQLPlanner := params.extendedEvalCtx.DistSQLPlanner
+	recommendation, _ := distSQLPlanner.checkSupportForNode(n.plan)
+
+	planCtx := distSQLPlanner.NewPlanningCtx(params.ctx, params.extendedEvalCtx, params.p.txn)
+	planCtx.isLocal = !shouldDistributeGivenRecAndMode(recommendation, params.SessionData().DistSQLMode)
+	planCtx.planner = params.p
+	planCtx.stmtType = n.stmtType
+	planCtx.validExtendedEvalCtx = true
+	planCtx.processorPlanNodes = &processorPlanNodes{}
+
+	// This stanza ensures that EXPLAIN ANALYZE won't include metadata test
+	// senders or receivers.
+	curTol := distSQLPlanner.metadataTestTolerance
+	distSQLPlanner.metadataTestTolerance = distsqlrun.On
+	defer func() { distSQLPlanner.metadataTestTolerance = curTol }()
+
+	plan, err := distSQLPlanner.createPlanForNode(&planCtx, n.plan)
+	if err != nil {
+		return err
+	}
+	distSQLPlanner.FinalizePlan(&planCtx, &plan)
+
+	spans, err := runPlanWithStats(params, &planCtx, &plan)
+	if err != nil {
+		return err
+	}
+	stats := planCtx.processorPlanNodes.aggregate(distsqlrun.ExtractProcessorStats(spans))
+	return n.populate(params.ctx, stats)
+}
+
+// populate adds a row to the results for each node of the plan.
+func (n *explainAnalyzeNode) populate(
+	ctx context.Context, stats map[planNode]*planNodeStats,
+) error {
+	var e explainer
+	e.populateEntries(ctx, n.plan, nil /* subqueryPlans */)
+
+	// We only show the nodes; their attributes are available through EXPLAIN.
+	tp := treeprinter.New()
+	// nodes keeps track of the current node on each level.
+	nodes := []treeprinter.Node{tp}
+	var entries []explainEntry
+	for _, entry := range e.entries {
+		if entry.plan != nil {
+			nodes = append(nodes[:entry.level+1], nodes[entry.level].Child(entry.node))
+			entries = append(entries, entry)
+		}
+	}
+	treeRows := tp.FormattedRows()
+
+	for i, entry := range entries {
+		row := tree.Datums{
+			tree.NewDString(treeRows[i]), // Tree
+			tree.DNull,                   // EstimatedRows
+			tree.DNull,                   // EstimatedCost
+			tree.DNull,                   // ActualRows
+			tree.DNull,                   // Time
+			tree.DNull,                   // MaxMemory
+			tree.DNull,                   // MaxDisk
+		}
+		if est, ok := n.estimates[entry.plan]; ok {
+			row[1] = tree.NewDInt(tree.DInt(math.Round(est.RowCount)))
+			row[2] = tree.NewDFloat(tree.DFloat(est.Cost))
+		}
+		if s, ok := stats[entry.plan]; ok {
+			if s.hasRows {
+				row[3] = tree.NewDInt(tree.DInt(s.rows))
+			}
+			if s.hasProcessors {
+				row[4] = &tree.DInterval{Duration: duration.Duration{Nanos: s.time.Nanoseconds()}}
+				row[5] = tree.NewDString(humanizeutil.IBytes(s.maxMem))
+				row[6] = tree.NewDString(humanizeutil.IBytes(s.maxDisk))
+			}
+		}
+		if _, err := n.run.results.rows.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *explainAnalyzeNode) Next(params runParams) (bool, error) {
+	return n.run.results.Next(params)
+}
+
+func (n *explainAnalyzeNode) Values() tree.Datums {
+	return n.run.results.Values()
+}
+
+func (n *explainAnalyzeNode) Close(ctx context.Context) {
+	n.plan.Close(ctx)
+	n.run.results.Close(ctx)
+}
+
+// makeExplainAnalyzeNode instantiates a planNode that runs an EXPLAIN ANALYZE
+// query on the given plan.
+func (p *planner) makeExplainAnalyzeNode(
+	plan planNode, stmtType tree.StatementType, estimates map[exec.Node]exec.Estimates,
+) planNode {
+	return &explainAnalyzeNode{
+		plan:      plan,
+		stmtType:  stmtType,
+		estimates: estimates,
+		run: explainAnalyzeRun{
+			results: p.newContainerValuesNode(sqlbase.ExplainAnalyzeColumns, 0),
+		},
+	}
+}
+
+// processorPlanNodes records the planNodes for which the processors of a
+// physical plan were planned. It is used by EXPLAIN ANALYZE to map the
+// statistics collected for the processors back to the plan tree.
+//
+// Each processor is associated with the node whose planning created it, and
+// with the outermost node whose results it produces. The two differ when a
+// node (e.g. a filterNode) is merged into the post-processing stage of the
+// processors of its input.
+type processorPlanNodes struct {
+	// creators and outputs contain the nodes for each processor, indexed by the
+	// temporary processor IDs assigned during planning (minus one).
+	creators []planNode
+	outputs  []planNode
+
+	// byProcessorID maps the processor IDs assigned by FinalizePlan to indexes
+	// in creators and outputs.
+	byProcessorID map[int]int
+}
+
+// record is called once the given node has been planned. The processors that
+// are not associated with any node yet were created by the node, and the
+// processors of the last stage produce its results.
+func (m *processorPlanNodes) record(plan *PhysicalPlan, node planNode) {
+	for i := range plan.Processors {
+		// Processor IDs are only assigned by FinalizePlan; until then, we use them
+		// to identify the processors we have already seen.
+		if spec := &plan.Processors[i].Spec; spec.ProcessorID == 0 {
+			m.creators = append(m.creators, node)
+			m.outputs = append(m.outputs, node)
+			spec.ProcessorID = int32(len(m.creators))
+		}
+	}
+	for _, idx := range plan.ResultRouters {
+		m.outputs[plan.Processors[idx].Spec.ProcessorID-1] = node
+	}
+}
+
+// finalize is called by FinalizePlan before it assigns the processor IDs.
+func (m *processorPlanNodes) finalize(plan *PhysicalPlan) {
+	m.byProcessorID = make(map[int]int, len(plan.Processors))
+	for i := range plan.Processors {
+		if id := plan.Processors[i].Spec.ProcessorID; id > 0 {
+			m.byProcessorID[i] = int(id) - 1
+		}
+	}
+}
+
+// planNodeStats contains the statistics collected for the processors that
+// were planned for a node.
+type planNodeStats struct {
+	// hasRows is set if rows contains the number of rows produced by the node.
+	hasRows bool
+	rows    int64
+
+	// hasProcessors is set if the node created processors, in which case time
+	// is the longest time spent by one of them, and maxMem and maxDisk are the
+	// total memory and disk used by them.
+	hasProcessors bool
+	time          time.Duration
+	maxMem        int64
+	maxDisk       int64
+}
+
+// aggregate combines the statistics of the processors (keyed by processor ID)
+// into statistics for the nodes they were planned for.
+func (m *processorPlanNodes) aggregate(
+	stats map[int]distsqlrun.ProcessorStats,
+) map[planNode]*planNodeStats {
+	res := make(map[planNode]*planNodeStats)
+	get := func(n planNode) *planNodeStats {
+		s, ok := res[n]
+		if !ok {
+			s = &planNodeStats{}
+			res[n] = s
+		}
+		return s
+	}
+	for id, ps := range stats {
+		idx, ok := m.byProcessorID[id]
+		if !ok {
+			continue
+		}
+		creator, output := get(m.creators[idx]), get(m.outputs[idx])
+		output.hasRows = true
+		output.rows += ps.OutputRows
+		if creator != output && ps.ReadsTable {
+			// The results of the processor include the work of other nodes (e.g.
+			// a filter); the rows read from the table are the node's results.
+			creator.hasRows = true
+			creator.rows += ps.RowsRead
+		}
+		creator.hasProcessors = true
+		if ps.Time > creator.time {
+			creator.time = ps.Time
+		}
+		creator.maxMem += ps.MaxAllocatedMem
+		creator.maxDisk += ps.MaxAllocatedDisk
+	}
+	return res
+}
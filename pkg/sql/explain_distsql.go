@@ -77,44 +77,8 @@ func (n *explainDistSQLNode) startExec(params runParams) error {
 
 	var spans []tracing.RecordedSpan
 	if n.analyze {
-		if params.extendedEvalCtx.Tracing.Enabled() {
-			return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
-				"cannot run EXPLAIN ANALYZE while tracing is enabled")
-		}
-		// Start tracing. KV tracing is not enabled because we are only interested
-		// in stats present on the spans. Noop if tracing is already enabled.
-		if err := params.extendedEvalCtx.Tracing.StartTracing(
-			tracing.SnowballRecording,
-			false, /* kvTracingEnabled */
-			false, /* showResults */
-		); err != nil {
-			return err
-		}
-
-		planCtx.ctx = params.extendedEvalCtx.Tracing.ex.ctxHolder.ctx()
-
-		// Discard rows that are returned.
-		rw := newCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
-			return nil
-		})
-		execCfg := params.p.ExecCfg()
-		const stmtType = tree.Rows
-		recv := MakeDistSQLReceiver(
-			planCtx.ctx,
-			rw,
-			stmtType,
-			execCfg.RangeDescriptorCache,
-			execCfg.LeaseHolderCache,
-			params.p.txn,
-			func(ts hlc.Timestamp) {
-				_ = execCfg.Clock.Update(ts)
-			},
-			params.extendedEvalCtx.Tracing,
-		)
-		distSQLPlanner.Run(&planCtx, params.p.txn, &plan, recv, params.extendedEvalCtx)
-
-		spans = params.extendedEvalCtx.Tracing.getRecording()
-		if err := params.extendedEvalCtx.Tracing.StopTracing(); err != nil {
+		spans, err = runPlanWithStats(params, &planCtx, &plan)
+		if err != nil {
 			return err
 		}
 	}
@@ -145,3 +109,53 @@ func (n *explainDistSQLNode) Values() tree.Datums { return n.run.values }
 func (n *explainDistSQLNode) Close(ctx context.Context) {
 	n.plan.Close(ctx)
 }
+
+// runPlanWithStats runs the given physical plan with tracing enabled, so that
+// the processors collect statistics, and returns the recorded spans. The rows
+// returned by the plan are discarded.
+func runPlanWithStats(
+	params runParams, planCtx *PlanningCtx, plan *PhysicalPlan,
+) ([]tracing.RecordedSpan, error) {
+	if params.extendedEvalCtx.Tracing.Enabled() {
+		return nil, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"cannot run EXPLAIN ANALYZE while tracing is enabled")
+	}
+	// Start tracing. KV tracing is not enabled because we are only interested
+	// in stats present on the spans. Noop if tracing is already enabled.
+	if err := params.extendedEvalCtx.Tracing.StartTracing(
+		tracing.SnowballRecording,
+		false, /* kvTracingEnabled */
+		false, /* showResults */
+	); err != nil {
+		return nil, err
+	}
+
+	planCtx.ctx = params.extendedEvalCtx.Tracing.ex.ctxHolder.ctx()
+
+	// Discard rows that are returned.
+	rw := newCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
+		return nil
+	})
+	execCfg := params.p.ExecCfg()
+	const stmtType = tree.Rows
+	recv := MakeDistSQLReceiver(
+		planCtx.ctx,
+		rw,
+		stmtType,
+		execCfg.RangeDescriptorCache,
+		execCfg.LeaseHolderCache,
+		params.p.txn,
+		func(ts hlc.Timestamp) {
+			_ = execCfg.Clock.Update(ts)
+		},
+		params.extendedEvalCtx.Tracing,
+	)
+	distSQLPlanner := params.extendedEvalCtx.DistSQLPlanner
+	distSQLPlanner.Run(planCtx, params.p.txn, plan, recv, params.extendedEvalCtx)
+
+	spans := params.extendedEvalCtx.Tracing.getRecording()
+	if err := params.extendedEvalCtx.Tracing.StopTracing(); err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
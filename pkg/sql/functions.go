@@ -0,0 +1,250 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/pkg/errors"
+)
+
+// maxFunctionCallDepth bounds the nesting of calls to user-defined
+// functions, so that functions that (indirectly) call themselves fail
+// with an error instead of exhausting the stack.
+const maxFunctionCallDepth = 32
+
+// functionCallDepthKey is the context key under which the current
+// nesting depth of user-defined function calls is stored.
+type functionCallDepthKey struct{}
+
+// ResolveFunction implements the tree.FunctionResolver interface.
+//
+// User-defined functions are stored as table descriptors, so they are
+// looked up through the same (leased) path as tables, views and
+// sequences, and a new version of the function becomes visible to
+// running queries when the previous version's leases expire.
+func (p *planner) ResolveFunction(name *tree.UnresolvedName) (*tree.FunctionDefinition, error) {
+	desc, err := resolveFunctionDesc(p.EvalContext().Ctx(), p, name)
+	if err != nil || desc == nil {
+		return nil, err
+	}
+	return makeFunctionDefinition(desc)
+}
+
+// resolveFunctionDesc looks up the descriptor of the user-defined function
+// with the given name. It returns nil if there is no such function.
+func resolveFunctionDesc(
+	ctx context.Context, sc SchemaResolver, name *tree.UnresolvedName,
+) (*sqlbase.TableDescriptor, error) {
+	tn, err := tree.NormalizeTableName(name)
+	if err != nil {
+		// Not a valid function name; let the caller report the original
+		// resolution error.
+		return nil, nil
+	}
+	desc, err := ResolveExistingObject(ctx, sc, &tn, false /*required*/, anyDescType)
+	if err != nil || desc == nil || !desc.IsFunction() {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// makeFunctionDefinition builds the function definition used during
+// type checking and evaluation for a function descriptor.
+func makeFunctionDefinition(desc *sqlbase.TableDescriptor) (*tree.FunctionDefinition, error) {
+	opts := desc.FunctionOpts
+	stmts, err := parser.Parse(opts.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	argTypes := make(tree.ArgTypes, len(opts.Arguments))
+	castTypes := make([]coltypes.T, len(opts.Arguments))
+	for i := range opts.Arguments {
+		arg := &opts.Arguments[i]
+		argName := arg.Name
+		if argName == "" {
+			argName = "$" + strconv.Itoa(i+1)
+		}
+		typ := arg.Type.ToDatumType()
+		argTypes[i].Name = argName
+		argTypes[i].Typ = typ
+		castTypes[i], err = coltypes.DatumTypeToColumnType(typ)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	props := tree.FunctionProperties{
+		NullableArgs: !opts.Strict,
+		// The body is run through the session's internal executor, which
+		// is not available on remote nodes.
+		DistsqlBlacklist: true,
+		Category:         "User-defined",
+	}
+	if opts.Volatility == sqlbase.TableDescriptor_FunctionOpts_VOLATILE {
+		props.Impure = true
+		props.NeedsRepeatedEvaluation = true
+	}
+
+	retType := opts.ReturnType.ToDatumType()
+	retCastType, err := coltypes.DatumTypeToColumnType(retType)
+	if err != nil {
+		return nil, err
+	}
+
+	name := desc.Name
+	overload := tree.Overload{
+		Types:      argTypes,
+		ReturnType: tree.FixedReturnType(retType),
+		Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+			res, err := evalFunctionBody(evalCtx, name, stmts, castTypes, args)
+			if err != nil || res == tree.DNull || res.ResolvedType().Equivalent(retType) {
+				return res, err
+			}
+			// The body was type checked with the return type as desired
+			// type, but is run without it, so e.g. a numeric constant may
+			// have been typed differently.
+			return tree.PerformCast(evalCtx, res, retCastType)
+		},
+	}
+	if opts.Volatility == sqlbase.TableDescriptor_FunctionOpts_IMMUTABLE && !opts.Strict {
+		overload.InlineBody = inlinableFunctionBody(stmts)
+	}
+	return tree.NewFunctionDefinition(name, &props, []tree.Overload{overload}), nil
+}
+
+// evalFunctionBody runs the statements of a user-defined function with
+// the given arguments and returns the first column of the first row
+// produced by the last statement, or NULL if it produces no rows.
+func evalFunctionBody(
+	evalCtx *tree.EvalContext,
+	name string,
+	stmts tree.StatementList,
+	castTypes []coltypes.T,
+	args tree.Datums,
+) (tree.Datum, error) {
+	ctx := evalCtx.Ctx()
+	depth, _ := ctx.Value(functionCallDepthKey{}).(int)
+	if depth >= maxFunctionCallDepth {
+		return nil, pgerror.NewErrorf(pgerror.CodeStatementTooComplexError,
+			"function %s exceeded the maximum call depth of %d", name, maxFunctionCallDepth)
+	}
+	ctx = context.WithValue(ctx, functionCallDepthKey{}, depth+1)
+
+	var row tree.Datums
+	for i, stmt := range stmts {
+		if i == len(stmts)-1 {
+			stmt = limitFunctionResult(stmt)
+		}
+		var err error
+		row, err = evalCtx.InternalExecutor.QueryRow(
+			ctx, "user-defined-function", evalCtx.Txn, formatFunctionStatement(stmt, castTypes, args))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(row) == 0 {
+		return tree.DNull, nil
+	}
+	return row[0], nil
+}
+
+// limitFunctionResult adds a LIMIT 1 to the last statement of a
+// function body if it has no limit already: only the first row of its
+// result is used.
+func limitFunctionResult(stmt tree.Statement) tree.Statement {
+	sel, ok := stmt.(*tree.Select)
+	if !ok || sel.Limit != nil {
+		return stmt
+	}
+	limited := *sel
+	limited.Limit = &tree.Limit{Count: tree.NewDInt(1)}
+	return &limited
+}
+
+// formatFunctionStatement renders a statement of a function body, with
+// the references to the arguments replaced by their values cast to the
+// declared argument types.
+func formatFunctionStatement(stmt tree.Statement, castTypes []coltypes.T, args tree.Datums) string {
+	f := tree.NewFmtCtxWithBuf(tree.FmtParsable)
+	f.WithPlaceholderFormat(func(ctx *tree.FmtCtx, p *tree.Placeholder) {
+		// The body was validated at creation time, so the placeholder
+		// refers to an existing argument.
+		idx, _ := strconv.Atoi(p.Name)
+		ctx.FormatNode(&tree.CastExpr{
+			Expr:       &tree.ParenExpr{Expr: args[idx-1]},
+			Type:       castTypes[idx-1],
+			SyntaxMode: tree.CastShort,
+		})
+	})
+	f.FormatNode(stmt)
+	return f.CloseAndGetString()
+}
+
+// inlinableFunctionBody returns the expression computed by a function
+// body if it consists of a single SELECT of one scalar expression
+// without any clause, or nil otherwise. Such a body can be substituted
+// for the calls to the function by the optimizer.
+func inlinableFunctionBody(stmts tree.StatementList) tree.Expr {
+	if len(stmts) != 1 {
+		return nil
+	}
+	sel, ok := stmts[0].(*tree.Select)
+	if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil {
+		return nil
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok || len(clause.Exprs) != 1 || len(clause.From.Tables) != 0 ||
+		clause.Where != nil || clause.GroupBy != nil || clause.Having != nil ||
+		clause.Window != nil || clause.Distinct || clause.DistinctOn != nil {
+		return nil
+	}
+	expr := clause.Exprs[0].Expr
+	if _, err := tree.SimpleVisit(expr, checkInlinableExpr); err != nil {
+		return nil
+	}
+	return expr
+}
+
+var errNotInlinable = errors.New("expression cannot be inlined")
+
+// checkInlinableExpr verifies that an expression only consists of
+// scalar computations, i.e. contains no subqueries, stars, aggregates,
+// window functions or set-returning functions.
+func checkInlinableExpr(expr tree.Expr) (err error, recurse bool, newExpr tree.Expr) {
+	switch t := expr.(type) {
+	case *tree.Subquery, tree.UnqualifiedStar, *tree.AllColumnsSelector:
+		return errNotInlinable, false, expr
+	case *tree.FuncExpr:
+		if t.WindowDef != nil || t.Filter != nil {
+			return errNotInlinable, false, expr
+		}
+		// Only built-in functions can be checked here; calls to other
+		// user-defined functions remain calls in the inlined expression.
+		if def, err := t.Func.Resolve(sessiondata.SearchPath{}); err == nil &&
+			def.Class != tree.NormalClass {
+			return errNotInlinable, false, expr
+		}
+	}
+	return nil, true, expr
+}
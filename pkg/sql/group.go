@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -49,6 +50,10 @@ type groupNode struct {
 
 	// Indices of the group by columns in the source plan.
 	groupCols []int
+	// groupingSets is set when there are several grouping sets (GROUPING SETS,
+	// ROLLUP or CUBE); each set contains the indices of its group by columns.
+	// Rows are accumulated into separate buckets for each set.
+	groupingSets []util.FastIntSet
 	// Indices of the group by columns in the source plan that have an ordering.
 	orderedGroupCols []int
 
@@ -116,7 +121,24 @@ func (p *planner) groupBy(
 		return nil, nil, nil
 	}
 
-	groupByExprs := make([]tree.Expr, len(n.GroupBy))
+	// With GROUPING SETS, ROLLUP or CUBE, the expressions of all the grouping
+	// sets are rendered, and groupingSets records which of them belong to
+	// each set.
+	groupBy := n.GroupBy
+	var groupingSets []tree.Exprs
+	if groupBy.HasGroupingSets() {
+		var err error
+		groupingSets, err = groupBy.ExpandGroupingSets()
+		if err != nil {
+			return nil, nil, err
+		}
+		groupBy = nil
+		for _, set := range groupingSets {
+			groupBy = append(groupBy, set...)
+		}
+	}
+
+	groupByExprs := make([]tree.Expr, len(groupBy))
 
 	// In the construction of the renderNode, when renders are processed (via
 	// computeRender()), the expressions are normalized. In order to compare these
@@ -124,7 +146,7 @@ func (p *planner) groupBy(
 	// the GROUP BY expressions as well. This is done before determining if
 	// aggregation is being performed, because that determination is made during
 	// validation, which will require matching expressions.
-	for i, expr := range n.GroupBy {
+	for i, expr := range groupBy {
 		expr = tree.StripParens(expr)
 
 		// Check whether the GROUP BY clause refers to a rendered column
@@ -169,7 +191,8 @@ func (p *planner) groupBy(
 	// We need to ensure there are no special function in the clause.
 	p.semaCtx.Properties.Require("GROUP BY", tree.RejectSpecial)
 
-	for _, g := range groupByExprs {
+	groupByCols := make([][]int, len(groupByExprs))
+	for i, g := range groupByExprs {
 		cols, exprs, hasStar, err := p.computeRenderAllowingStars(
 			ctx, tree.SelectExpr{Expr: g}, types.Any, r.sourceInfo, r.ivarHelper,
 			autoGenerateRenderOutputName)
@@ -182,6 +205,7 @@ func (p *planner) groupBy(
 		cols, exprs = flattenTuples(cols, exprs, &r.ivarHelper)
 
 		colIdxs := r.addOrReuseRenders(cols, exprs, true /* reuseExistingRender */)
+		groupByCols[i] = colIdxs
 		if len(colIdxs) == 1 {
 			// We only remember the render if there is a 1:1 correspondence with
 			// the expression written after GROUP BY and the computed renders.
@@ -201,6 +225,24 @@ func (p *planner) groupBy(
 	for i := range group.groupCols {
 		group.groupCols[i] = i
 	}
+	// Queries like `SELECT MAX(n) FROM t` expect a row of NULLs if nothing was
+	// aggregated, and so does each empty grouping set.
+	group.isScalar = len(groupByExprs) == 0
+	if len(groupingSets) > 1 {
+		group.groupingSets = make([]util.FastIntSet, len(groupingSets))
+		i := 0
+		for j, set := range groupingSets {
+			for range set {
+				for _, c := range groupByCols[i] {
+					group.groupingSets[j].Add(c)
+				}
+				i++
+			}
+			if group.groupingSets[j].Empty() {
+				group.isScalar = true
+			}
+		}
+	}
 
 	var havingNode *filterNode
 	plan := planNode(group)
@@ -289,9 +331,6 @@ func (p *planner) groupBy(
 	)
 	postRender.sourceInfo = sqlbase.MultiSourceInfo{postRender.source.info}
 
-	// Queries like `SELECT MAX(n) FROM t` expect a row of NULLs if nothing was aggregated.
-	group.isScalar = len(groupByExprs) == 0
-
 	if log.V(2) {
 		strs := make([]string, 0, len(group.funcs))
 		for _, f := range group.funcs {
@@ -341,8 +380,31 @@ func (n *groupNode) matchLastGroupKey(ctx *tree.EvalContext, row tree.Datums) bo
 // accumulateRow takes a row and accumulates it into all the aggregate
 // functions.
 func (n *groupNode) accumulateRow(params runParams, values tree.Datums) error {
-	bucket := n.run.scratch
+	if n.groupingSets == nil {
+		return n.accumulateRowForGroupingSet(params, values, n.run.scratch, -1 /* setIdx */)
+	}
+	// The row is accumulated once for each grouping set, into a bucket keyed by
+	// the index of the set and the values of its columns.
+	for i := range n.groupingSets {
+		prefix := encoding.EncodeUvarintAscending(n.run.scratch, uint64(i))
+		if err := n.accumulateRowForGroupingSet(params, values, prefix, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accumulateRowForGroupingSet accumulates a row into the bucket of the given
+// grouping set, or of the group by columns if setIdx is -1. The bucket key is
+// appended to prefix.
+func (n *groupNode) accumulateRowForGroupingSet(
+	params runParams, values tree.Datums, prefix []byte, setIdx int,
+) error {
+	bucket := prefix
 	for _, idx := range n.groupCols {
+		if setIdx != -1 && !n.groupingSets[setIdx].Contains(idx) {
+			continue
+		}
 		var err error
 		bucket, err = sqlbase.EncodeDatum(bucket, values[idx])
 		if err != nil {
@@ -357,6 +419,16 @@ func (n *groupNode) accumulateRow(params runParams, values tree.Datums) error {
 		if f.hasFilter() && values[f.filterRenderIdx] != tree.DBoolTrue {
 			continue
 		}
+		if f.isGrouping() {
+			// The result is computed from the bucket in Next.
+			continue
+		}
+		if setIdx != -1 && f.funcName == builtins.AnyNotNull &&
+			n.isGroupCol(f.argRenderIdx) && !n.groupingSets[setIdx].Contains(f.argRenderIdx) {
+			// The group by column is not part of this grouping set; its value
+			// is NULL.
+			continue
+		}
 
 		var value tree.Datum
 		if f.argRenderIdx != noRenderIdx {
@@ -447,6 +519,10 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 	// buckets to a slice and then releasing the buckets map.
 	delete(n.run.buckets, bucket)
 	for i, f := range n.funcs {
+		if f.isGrouping() {
+			n.run.values[i] = n.groupingResult(f, bucket)
+			continue
+		}
 		aggregateFunc, ok := f.run.buckets[bucket]
 		if !ok {
 			// No input for this bucket (possible if f has a FILTER).
@@ -475,11 +551,49 @@ func (n *groupNode) Close(ctx context.Context) {
 	n.run.buckets = nil
 }
 
+// groupingResult returns the result of a GROUPING function for the given
+// bucket: a bit mask of the arguments that are not part of the grouping set of
+// the bucket, the leftmost argument being the most significant bit.
+func (n *groupNode) groupingResult(f *aggregateFuncHolder, bucket string) tree.Datum {
+	var mask tree.DInt
+	if n.groupingSets == nil {
+		return tree.NewDInt(mask)
+	}
+	_, setIdx, err := encoding.DecodeUvarintAscending([]byte(bucket))
+	if err != nil {
+		panic(fmt.Sprintf("invalid grouping set bucket: %v", err))
+	}
+	for _, c := range f.groupingArgs {
+		mask <<= 1
+		if !n.groupingSets[setIdx].Contains(c) {
+			mask |= 1
+		}
+	}
+	return tree.NewDInt(mask)
+}
+
+// isGroupCol returns whether the given render is a group by column.
+func (n *groupNode) isGroupCol(renderIdx int) bool {
+	for _, c := range n.groupCols {
+		if c == renderIdx {
+			return true
+		}
+	}
+	return false
+}
+
 // setupOutput runs once after all the input rows have been processed. It sets
 // up the necessary state to start iterating through the buckets in Next().
 func (n *groupNode) setupOutput() {
 	if len(n.run.buckets) < 1 && n.isScalar {
-		n.run.buckets[""] = struct{}{}
+		if n.groupingSets == nil {
+			n.run.buckets[""] = struct{}{}
+		}
+		for i, set := range n.groupingSets {
+			if set.Empty() {
+				n.run.buckets[string(encoding.EncodeUvarintAscending(nil, uint64(i)))] = struct{}{}
+			}
+		}
 	}
 	if n.run.values == nil {
 		n.run.values = make(tree.Datums, len(n.funcs))
@@ -526,7 +640,7 @@ func (n *groupNode) addIsDistinctFromNullFilter(where *filterNode, render *rende
 // instead have another variable (e.g. from the AST) tell us what type
 // of aggregation we're dealing with, and test that here.
 func (n *groupNode) desiredAggregateOrdering(evalCtx *tree.EvalContext) sqlbase.ColumnOrdering {
-	if len(n.groupCols) > 0 {
+	if len(n.groupCols) > 0 || n.groupingSets != nil {
 		return nil
 	}
 
@@ -547,7 +661,14 @@ func (n *groupNode) desiredAggregateOrdering(evalCtx *tree.EvalContext) sqlbase.
 // aggIsGroupingColumn returns true if the given output aggregation is an
 // any_not_null aggregation for a grouping column. The grouping column
 // index is also returned.
+//
+// With grouping sets, the values of the grouping columns are NULL in the rows
+// of the sets that don't include them, so the aggregations are never
+// considered to be grouping columns.
 func (n *groupNode) aggIsGroupingColumn(aggIdx int) (colIdx int, ok bool) {
+	if n.groupingSets != nil {
+		return -1, false
+	}
 	if holder := n.funcs[aggIdx]; holder.funcName == builtins.AnyNotNull {
 		for _, c := range n.groupCols {
 			if c == holder.argRenderIdx {
@@ -630,6 +751,14 @@ func (v *extractAggregatesVisitor) VisitPre(expr tree.Expr) (recurse bool, newEx
 
 	switch t := expr.(type) {
 	case *tree.FuncExpr:
+		if isGroupingFunc(t) {
+			f, err := v.groupingFuncHolder(t)
+			if err != nil {
+				v.err = err
+				return false, expr
+			}
+			return false, v.addAggregation(f)
+		}
 		if agg := t.GetAggregateConstructor(); agg != nil {
 			var f *aggregateFuncHolder
 			switch len(t.Exprs) {
@@ -726,6 +855,41 @@ func (v *extractAggregatesVisitor) VisitPre(expr tree.Expr) (recurse bool, newEx
 
 func (*extractAggregatesVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
 
+// isGroupingFunc returns whether the expression is a GROUPING() function.
+func isGroupingFunc(t *tree.FuncExpr) bool {
+	def, ok := t.Func.FunctionReference.(*tree.FunctionDefinition)
+	return ok && def.Name == builtins.Grouping
+}
+
+// groupingFuncHolder returns the aggregateFuncHolder of a GROUPING() function.
+// Its arguments must be grouping expressions.
+func (v *extractAggregatesVisitor) groupingFuncHolder(
+	t *tree.FuncExpr,
+) (*aggregateFuncHolder, error) {
+	if t.Type == tree.DistinctFuncType || t.Filter != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"DISTINCT and FILTER are not allowed with GROUPING")
+	}
+	args := make([]int, len(t.Exprs))
+	for i, e := range t.Exprs {
+		groupIdx, ok := v.groupStrs[symbolicExprStr(e)]
+		if !ok || groupIdx == -1 {
+			return nil, pgerror.NewErrorf(pgerror.CodeGroupingError,
+				"arguments to GROUPING must be grouping expressions of the associated query level")
+		}
+		args[i] = groupIdx
+	}
+	f := v.groupNode.newAggregateFuncHolder(
+		builtins.Grouping,
+		t.ResolvedType(),
+		noRenderIdx,
+		builtins.NewAnyNotNullAggregate,
+		v.planner.EvalContext().Mon.MakeBoundAccount(),
+	)
+	f.groupingArgs = args
+	return f, nil
+}
+
 // extract aggregateFuncHolders from exprs that use aggregation and add them to
 // the groupNode.
 func (v extractAggregatesVisitor) extract(typedExpr tree.TypedExpr) (tree.TypedExpr, error) {
@@ -749,6 +913,11 @@ type aggregateFuncHolder struct {
 	// renderNode underneath. If there is no filter, it is set to noRenderIdx.
 	filterRenderIdx int
 
+	// groupingArgs is set for the GROUPING function; it contains the indices
+	// of the group by columns that are its arguments. The result is computed
+	// from the grouping set of each bucket rather than accumulated.
+	groupingArgs []int
+
 	// create instantiates the built-in execution context for the
 	// aggregation function.
 	create func(*tree.EvalContext) tree.AggregateFunc
@@ -813,7 +982,20 @@ func (a *aggregateFuncHolder) isDistinct() bool {
 	return a.run.seen != nil
 }
 
+// isGrouping returns true if this is the GROUPING function.
+func (a *aggregateFuncHolder) isGrouping() bool {
+	return a.groupingArgs != nil
+}
+
 func aggregateFuncsEqual(a, b *aggregateFuncHolder) bool {
+	if len(a.groupingArgs) != len(b.groupingArgs) {
+		return false
+	}
+	for i := range a.groupingArgs {
+		if a.groupingArgs[i] != b.groupingArgs[i] {
+			return false
+		}
+	}
 	return a.funcName == b.funcName && a.resultType == b.resultType &&
 		a.argRenderIdx == b.argRenderIdx && a.filterRenderIdx == b.filterRenderIdx
 }
@@ -238,6 +238,41 @@ type indexJoinRun struct {
 	// setNeededColumns(). So there may be more columns in
 	// colIDtoRowIndex than effectively accessed.
 	colIDtoRowIndex map[sqlbase.ColumnID]int
+
+	// seenPrimaryKeys contains the primary index keys that were already
+	// looked up in the table, when the index scan may produce the same
+	// primary key more than once (see mayProduceDuplicates).
+	seenPrimaryKeys map[string]struct{}
+}
+
+// mayProduceDuplicates returns true if the index scanNode can return the
+// same primary key more than once. This happens with an inverted index on an
+// array or TSVECTOR column or a trigram index when the scan has several spans
+// or a span that isn't a single key: an array with more than one matching
+// element, a string with more than one matching trigram, or a text search
+// vector with more than one matching lexeme, has an index entry in more than
+// one of them.
+func (n *indexJoinNode) mayProduceDuplicates() bool {
+	index := n.index.index
+	if index.Type != sqlbase.IndexDescriptor_INVERTED || len(index.ColumnIDs) != 1 {
+		return false
+	}
+	if !index.Trigram {
+		col, err := n.index.desc.FindColumnByID(index.ColumnIDs[0])
+		if err != nil || (col.Type.SemanticType != sqlbase.ColumnType_ARRAY &&
+			col.Type.SemanticType != sqlbase.ColumnType_TSVECTOR) {
+			return false
+		}
+	}
+	spans := n.index.spans
+	return !(len(spans) == 1 && spans[0].EndKey.Equal(spans[0].Key.PrefixEnd()))
+}
+
+func (n *indexJoinNode) startExec(params runParams) error {
+	if n.mayProduceDuplicates() {
+		n.run.seenPrimaryKeys = make(map[string]struct{})
+	}
+	return nil
 }
 
 const indexJoinBatchSize = 100
@@ -282,6 +317,12 @@ func (n *indexJoinNode) Next(params runParams) (bool, error) {
 			if err != nil {
 				return false, err
 			}
+			if n.run.seenPrimaryKeys != nil {
+				if _, ok := n.run.seenPrimaryKeys[string(primaryIndexKey)]; ok {
+					continue
+				}
+				n.run.seenPrimaryKeys[string(primaryIndexKey)] = struct{}{}
+			}
 			key := roachpb.Key(primaryIndexKey)
 			n.table.spans = append(n.table.spans, roachpb.Span{
 				Key:    key,
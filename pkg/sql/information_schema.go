@@ -1270,14 +1270,14 @@ func forEachTableDescAll(
 ) error {
 	return forEachTableDescWithTableLookupInternal(ctx,
 		p, dbContext, virtualOpts, true, /* allowAdding */
-		func(
+		skipFunctionDescs(func(
 			db *sqlbase.DatabaseDescriptor,
 			scName string,
 			table *sqlbase.TableDescriptor,
 			_ tableLookupFn,
 		) error {
 			return fn(db, scName, table)
-		})
+		}))
 }
 
 // forEachTableDescWithTableLookup acts like forEachTableDesc, except it also provides a
@@ -1296,7 +1296,22 @@ func forEachTableDescWithTableLookup(
 	virtualOpts virtualOpts,
 	fn func(*sqlbase.DatabaseDescriptor, string, *sqlbase.TableDescriptor, tableLookupFn) error,
 ) error {
-	return forEachTableDescWithTableLookupInternal(ctx, p, dbContext, virtualOpts, false /* allowAdding */, fn)
+	return forEachTableDescWithTableLookupInternal(
+		ctx, p, dbContext, virtualOpts, false /* allowAdding */, skipFunctionDescs(fn))
+}
+
+// skipFunctionDescs wraps the given iteration function so that it is
+// not called for the descriptors of user-defined functions, which are
+// stored like relations but must not be listed as such.
+func skipFunctionDescs(
+	fn func(*DatabaseDescriptor, string, *TableDescriptor, tableLookupFn) error,
+) func(*DatabaseDescriptor, string, *TableDescriptor, tableLookupFn) error {
+	return func(db *DatabaseDescriptor, scName string, table *TableDescriptor, lCtx tableLookupFn) error {
+		if table.IsFunction() {
+			return nil
+		}
+		return fn(db, scName, table, lCtx)
+	}
 }
 
 // forEachTableDescWithTableLookupInternal is the logic that supports
@@ -1358,7 +1373,15 @@ func forEachTableDescWithTableLookupInternal(
 		if table.Dropped() || !userCanSeeTable(ctx, p, table, allowAdding) || !parentExists {
 			continue
 		}
-		if err := fn(dbDesc, tree.PublicSchema, table, lCtx); err != nil {
+		scName := tree.PublicSchema
+		if table.IsTemporary() {
+			// The temporary tables of other sessions are not visible.
+			if !p.Tables().temporarySchemas.owns(table) {
+				continue
+			}
+			scName = p.Tables().temporarySchemas.name
+		}
+		if err := fn(dbDesc, scName, table, lCtx); err != nil {
 			return err
 		}
 	}
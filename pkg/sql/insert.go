@@ -225,7 +225,7 @@ func (p *planner) Insert(
 
 	// Create the table insert, which does the bulk of the work.
 	ri, err := sqlbase.MakeRowInserter(p.txn, desc, fkTables, insertCols,
-		sqlbase.CheckFKs, &p.alloc)
+		sqlbase.CheckFKs, p.EvalContext(), &p.alloc)
 	if err != nil {
 		return nil, err
 	}
@@ -249,7 +249,7 @@ func (p *planner) Insert(
 		// This is an UPSERT, or INSERT ... ON CONFLICT.
 		// The upsert path has a separate constructor.
 		node, err = p.newUpsertNode(
-			ctx, n, desc, ri, tn, alias, rows, rowsNeeded, columns,
+			ctx, n.OnConflict, desc, ri, tn, alias, rows, rowsNeeded, columns,
 			defaultExprs, computeExprs, computedCols, fkTables, desiredTypes)
 		if err != nil {
 			return nil, err
@@ -457,6 +457,10 @@ func (n *insertNode) BatchedNext(params runParams) (bool, error) {
 		if _, err := n.run.ti.finalize(params.ctx, n.run.autoCommit, n.run.traceKV); err != nil {
 			return false, err
 		}
+		// Possibly initiate a refresh of the table statistics.
+		params.extendedEvalCtx.ExecCfg.StatsRefresher.NotifyMutation(
+			n.run.ti.tableDesc().ID, n.run.ti.rowsWritten(),
+		)
 		// Remember we're done for the next call to BatchedNext().
 		n.run.done = true
 	}
@@ -605,9 +609,10 @@ func GenerateInsertRow(
 		evalCtx.PopIVarContainer()
 	}
 
-	// Check to see if NULL is being inserted into any non-nullable column.
+	// Check to see if NULL is being inserted into any non-nullable column,
+	// including the columns that a NOT NULL constraint is being added to.
 	for _, col := range tableDesc.Columns {
-		if !col.Nullable {
+		if !col.Nullable || tableDesc.ValidatingNotNull(col.ID) {
 			if i, ok := rowContainerForComputedVals.Mapping[col.ID]; !ok || rowVals[i] == tree.DNull {
 				return nil, sqlbase.NewNonNullViolationError(col.Name)
 			}
@@ -582,6 +582,11 @@ func (icc *internalClientComm) Flush(pos CmdPos) error {
 	return nil
 }
 
+// SendNotification is part of the ClientComm interface.
+func (icc *internalClientComm) SendNotification(Notification) {
+	// Internal executors don't receive notifications.
+}
+
 // CreateDescribeResult is part of the ClientComm interface.
 func (icc *internalClientComm) CreateDescribeResult(pos CmdPos) DescribeResult {
 	panic("unimplemented")
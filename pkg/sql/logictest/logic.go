@@ -984,6 +984,14 @@ func (t *logicTest) setup(cfg testClusterConfig) {
 		t.cluster.Server(t.nodeIdx).SetDistSQLSpanResolver(fakeResolver)
 	}
 
+	// Disable automatic statistics collection, since the statistics jobs would
+	// make the plans and SHOW JOBS output of the tests nondeterministic.
+	if _, err := t.cluster.ServerConn(0).Exec(
+		"SET CLUSTER SETTING sql.stats.automatic_collection.enabled = false",
+	); err != nil {
+		t.Fatal(err)
+	}
+
 	if cfg.overrideDistSQLMode != "" {
 		if _, err := t.cluster.ServerConn(0).Exec(
 			"SET CLUSTER SETTING sql.defaults.distsql = $1::string", cfg.overrideDistSQLMode,
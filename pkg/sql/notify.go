@@ -0,0 +1,372 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// maxNotificationPayloadLength is the maximum length in bytes of the
+// payload of a notification, like in Postgres.
+const maxNotificationPayloadLength = 8000
+
+// notificationGossipTTL is the duration for which a batch of notifications
+// is kept in gossip. It needs to be long enough for the batch to reach
+// every node of the cluster.
+const notificationGossipTTL = time.Minute
+
+// Notification is a message sent on a channel with NOTIFY or pg_notify(),
+// which is delivered to the sessions listening on that channel.
+type Notification struct {
+	// NodeID is the ID of the node on which the notification was sent. It is
+	// reported to clients in place of the process ID of the notifying
+	// backend.
+	NodeID  roachpb.NodeID
+	Channel string
+	Payload string
+}
+
+// NotificationBus delivers the notifications sent by committed
+// transactions to the sessions listening on their channels, on every node
+// of the cluster.
+//
+// The notifications sent by a transaction are gossiped together under a
+// unique key, and are delivered to the local sessions by the gossip
+// callback on each node, including the sending node. Gossip doesn't order
+// the infos added under different keys, so the notifications of different
+// transactions can be delivered in a different order than the one in which
+// the transactions committed.
+type NotificationBus struct {
+	gossip *gossip.Gossip
+	nodeID *base.NodeIDContainer
+
+	mu struct {
+		syncutil.Mutex
+		// listeners maps each channel to the sessions listening on it.
+		listeners map[string]map[*notificationListener]struct{}
+	}
+}
+
+// NewNotificationBus creates a NotificationBus. If g is nil, notifications
+// are only delivered to the sessions on the local node.
+func NewNotificationBus(g *gossip.Gossip, nodeID *base.NodeIDContainer) *NotificationBus {
+	b := &NotificationBus{gossip: g, nodeID: nodeID}
+	b.mu.listeners = make(map[string]map[*notificationListener]struct{})
+	if g != nil {
+		g.RegisterCallback(
+			gossip.MakePrefixPattern(gossip.KeySQLNotificationPrefix),
+			b.notificationGossipUpdate,
+		)
+	}
+	return b
+}
+
+// notificationListener is the registration of a session with the
+// NotificationBus.
+type notificationListener struct {
+	// comm is the client of the session, to which notifications are sent.
+	comm ClientComm
+	// channels is the set of channels the session listens on. It is only
+	// accessed by the session's goroutine.
+	channels map[string]struct{}
+}
+
+// listen makes the session listen on the given channel.
+func (b *NotificationBus) listen(l *notificationListener, channel string) {
+	if _, ok := l.channels[channel]; ok {
+		return
+	}
+	if l.channels == nil {
+		l.channels = make(map[string]struct{})
+	}
+	l.channels[channel] = struct{}{}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	listeners, ok := b.mu.listeners[channel]
+	if !ok {
+		listeners = make(map[*notificationListener]struct{})
+		b.mu.listeners[channel] = listeners
+	}
+	listeners[l] = struct{}{}
+}
+
+// unlisten makes the session stop listening on the given channel.
+func (b *NotificationBus) unlisten(l *notificationListener, channel string) {
+	if _, ok := l.channels[channel]; !ok {
+		return
+	}
+	delete(l.channels, channel)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.unlistenLocked(l, channel)
+}
+
+// unlistenAll makes the session stop listening on all channels.
+func (b *NotificationBus) unlistenAll(l *notificationListener) {
+	if len(l.channels) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for channel := range l.channels {
+		b.unlistenLocked(l, channel)
+	}
+	l.channels = nil
+}
+
+func (b *NotificationBus) unlistenLocked(l *notificationListener, channel string) {
+	listeners := b.mu.listeners[channel]
+	delete(listeners, l)
+	if len(listeners) == 0 {
+		delete(b.mu.listeners, channel)
+	}
+}
+
+// publish sends the notifications of a committed transaction to the
+// listening sessions of all nodes.
+func (b *NotificationBus) publish(notifications []Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	nodeID := b.nodeID.Get()
+	if b.gossip == nil {
+		for i := range notifications {
+			notifications[i].NodeID = nodeID
+		}
+		b.deliver(notifications)
+		return nil
+	}
+	return b.gossip.AddInfo(
+		gossip.MakeSQLNotificationKey(uuid.MakeV4()),
+		encodeNotifications(nodeID, notifications),
+		notificationGossipTTL,
+	)
+}
+
+// notificationGossipUpdate is the gossip callback that fires when a batch
+// of notifications is sent by a transaction on any node.
+func (b *NotificationBus) notificationGossipUpdate(key string, value roachpb.Value) {
+	ctx := context.Background()
+	buf, err := value.GetBytes()
+	if err != nil {
+		log.Errorf(ctx, "notificationGossipUpdate(%s) error: %v", key, err)
+		return
+	}
+	notifications, err := decodeNotifications(buf)
+	if err != nil {
+		log.Errorf(ctx, "notificationGossipUpdate(%s) error: %v", key, err)
+		return
+	}
+	b.deliver(notifications)
+}
+
+// deliver sends notifications to the local sessions listening on their
+// channels.
+func (b *NotificationBus) deliver(notifications []Notification) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, n := range notifications {
+		for l := range b.mu.listeners[n.Channel] {
+			l.comm.SendNotification(n)
+		}
+	}
+}
+
+// encodeNotifications encodes a batch of notifications sent on the given
+// node, for gossip.
+func encodeNotifications(nodeID roachpb.NodeID, notifications []Notification) []byte {
+	buf := encoding.EncodeUvarintAscending(nil, uint64(nodeID))
+	for _, n := range notifications {
+		buf = encoding.EncodeBytesAscending(buf, []byte(n.Channel))
+		buf = encoding.EncodeBytesAscending(buf, []byte(n.Payload))
+	}
+	return buf
+}
+
+// decodeNotifications decodes a batch of notifications encoded by
+// encodeNotifications.
+func decodeNotifications(buf []byte) ([]Notification, error) {
+	buf, nodeID, err := encoding.DecodeUvarintAscending(buf)
+	if err != nil {
+		return nil, err
+	}
+	var notifications []Notification
+	for len(buf) > 0 {
+		var channel, payload []byte
+		if buf, channel, err = encoding.DecodeBytesAscending(buf, nil); err != nil {
+			return nil, err
+		}
+		if buf, payload, err = encoding.DecodeBytesAscending(buf, nil); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, Notification{
+			NodeID:  roachpb.NodeID(nodeID),
+			Channel: string(channel),
+			Payload: string(payload),
+		})
+	}
+	return notifications, nil
+}
+
+// txnNotifications accumulates the effects of the LISTEN, UNLISTEN and
+// NOTIFY statements executed by a transaction. Like in Postgres, they only
+// take effect once the transaction commits, and are discarded if it
+// aborts.
+type txnNotifications struct {
+	// listenActions are the changes to the set of channels the session
+	// listens on, in order.
+	listenActions []listenAction
+	// pending are the notifications sent by the transaction, in order.
+	// Identical notifications are only sent once.
+	pending []Notification
+	// seen is the set of notifications in pending.
+	seen map[Notification]struct{}
+}
+
+// listenAction is a LISTEN or an UNLISTEN statement executed by a
+// transaction.
+type listenAction struct {
+	// channel is empty for UNLISTEN *.
+	channel string
+	listen  bool
+}
+
+// notify queues a notification to be sent when the transaction commits.
+func (tn *txnNotifications) notify(channel, payload string) error {
+	if channel == "" {
+		return pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"channel name cannot be empty")
+	}
+	if len(payload) >= maxNotificationPayloadLength {
+		return pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"payload string too long")
+	}
+	n := Notification{Channel: channel, Payload: payload}
+	if _, ok := tn.seen[n]; ok {
+		return nil
+	}
+	if tn.seen == nil {
+		tn.seen = make(map[Notification]struct{})
+	}
+	tn.seen[n] = struct{}{}
+	tn.pending = append(tn.pending, n)
+	return nil
+}
+
+// commit applies the effects of the transaction once it has committed.
+// The changes to the channels the session listens on are applied first,
+// so that the session receives the notifications it sent itself on the
+// channels it started listening on in the same transaction.
+func (tn *txnNotifications) commit(
+	ctx context.Context, bus *NotificationBus, l *notificationListener,
+) {
+	if bus == nil {
+		// Some test servers don't support notifications.
+		return
+	}
+	for _, a := range tn.listenActions {
+		switch {
+		case a.listen:
+			bus.listen(l, a.channel)
+		case a.channel == "":
+			bus.unlistenAll(l)
+		default:
+			bus.unlisten(l, a.channel)
+		}
+	}
+	// The transaction has already committed, so errors can't be reported to
+	// the client anymore.
+	if err := bus.publish(tn.pending); err != nil {
+		log.Warningf(ctx, "failed to send notifications: %v", err)
+	}
+}
+
+// reset discards the effects of the transaction.
+func (tn *txnNotifications) reset() {
+	*tn = txnNotifications{}
+}
+
+// SendNotification implements the tree.NotificationSender interface.
+func (p *planner) SendNotification(channel, payload string) error {
+	if p.extendedEvalCtx.Notifications == nil {
+		return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"notifications cannot be sent in this context")
+	}
+	return p.extendedEvalCtx.Notifications.notify(channel, payload)
+}
+
+type listenNode struct {
+	// channel is empty for UNLISTEN *.
+	channel string
+	listen  bool
+}
+
+// Listen implements the LISTEN statement.
+// See https://www.postgresql.org/docs/10/static/sql-listen.html for details.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	return &listenNode{channel: string(n.Channel), listen: true}, nil
+}
+
+// Unlisten implements the UNLISTEN statement.
+// See https://www.postgresql.org/docs/10/static/sql-unlisten.html for details.
+func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
+	return &listenNode{channel: string(n.Channel)}, nil
+}
+
+func (n *listenNode) startExec(params runParams) error {
+	tn := params.extendedEvalCtx.Notifications
+	if tn == nil {
+		return pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"cannot listen for notifications in this context")
+	}
+	tn.listenActions = append(tn.listenActions, listenAction{channel: n.channel, listen: n.listen})
+	return nil
+}
+
+func (*listenNode) Next(runParams) (bool, error) { return false, nil }
+func (*listenNode) Values() tree.Datums          { return nil }
+func (*listenNode) Close(context.Context)        {}
+
+type notifyNode struct {
+	channel string
+	payload string
+}
+
+// Notify implements the NOTIFY statement.
+// See https://www.postgresql.org/docs/10/static/sql-notify.html for details.
+func (p *planner) Notify(ctx context.Context, n *tree.Notify) (planNode, error) {
+	return &notifyNode{channel: string(n.Channel), payload: n.Payload}, nil
+}
+
+func (n *notifyNode) startExec(params runParams) error {
+	return params.p.SendNotification(n.channel, n.payload)
+}
+
+func (*notifyNode) Next(runParams) (bool, error) { return false, nil }
+func (*notifyNode) Values() tree.Datums          { return nil }
+func (*notifyNode) Close(context.Context)        {}
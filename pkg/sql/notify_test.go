@@ -0,0 +1,123 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// notificationsRecorder is a ClientComm recording the notifications it
+// receives. Its other methods are not implemented.
+type notificationsRecorder struct {
+	ClientComm
+	notifications []Notification
+}
+
+func (r *notificationsRecorder) SendNotification(n Notification) {
+	r.notifications = append(r.notifications, n)
+}
+
+func TestEncodeDecodeNotifications(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	notifications := []Notification{
+		{NodeID: 3, Channel: "foo", Payload: ""},
+		{NodeID: 3, Channel: "bar", Payload: "baz"},
+	}
+	decoded, err := decodeNotifications(encodeNotifications(3, notifications))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(notifications, decoded) {
+		t.Fatalf("expected %v, got %v", notifications, decoded)
+	}
+}
+
+func TestTxnNotifications(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	nodeID := &base.NodeIDContainer{}
+	nodeID.Set(context.TODO(), 2)
+	bus := NewNotificationBus(nil /* gossip */, nodeID)
+	var rec1, rec2 notificationsRecorder
+	l1 := &notificationListener{comm: &rec1}
+	l2 := &notificationListener{comm: &rec2}
+
+	var tn txnNotifications
+	tn.listenActions = append(tn.listenActions,
+		listenAction{channel: "a", listen: true}, listenAction{channel: "b", listen: true})
+	tn.commit(context.TODO(), bus, l2)
+	tn.reset()
+
+	// Identical notifications are only sent once per transaction.
+	for _, n := range []struct{ channel, payload string }{
+		{"a", "x"}, {"b", ""}, {"a", "x"}, {"a", "y"}, {"c", "x"},
+	} {
+		if err := tn.notify(n.channel, n.payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tn.notify("", "x"); !testutils.IsError(err, "channel name cannot be empty") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tn.notify("a", strings.Repeat("x", maxNotificationPayloadLength)); !testutils.IsError(
+		err, "payload string too long") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The session starts listening before its own notifications are sent.
+	tn.listenActions = append(tn.listenActions, listenAction{channel: "c", listen: true})
+	tn.commit(context.TODO(), bus, l1)
+	tn.reset()
+
+	expected1 := []Notification{{NodeID: 2, Channel: "c", Payload: "x"}}
+	if !reflect.DeepEqual(expected1, rec1.notifications) {
+		t.Fatalf("expected %v, got %v", expected1, rec1.notifications)
+	}
+	expected2 := []Notification{
+		{NodeID: 2, Channel: "a", Payload: "x"},
+		{NodeID: 2, Channel: "b", Payload: ""},
+		{NodeID: 2, Channel: "a", Payload: "y"},
+	}
+	if !reflect.DeepEqual(expected2, rec2.notifications) {
+		t.Fatalf("expected %v, got %v", expected2, rec2.notifications)
+	}
+
+	// UNLISTEN * stops all deliveries, and an aborted transaction sends
+	// nothing.
+	tn.listenActions = append(tn.listenActions, listenAction{})
+	tn.commit(context.TODO(), bus, l2)
+	tn.reset()
+	if err := tn.notify("c", "aborted"); err != nil {
+		t.Fatal(err)
+	}
+	tn.reset()
+	if err := tn.notify("a", "z"); err != nil {
+		t.Fatal(err)
+	}
+	tn.commit(context.TODO(), bus, l1)
+	if len(rec2.notifications) != len(expected2) || len(rec1.notifications) != len(expected1) {
+		t.Fatalf("unexpected notifications: %v, %v", rec1.notifications, rec2.notifications)
+	}
+	if len(bus.mu.listeners) != 1 {
+		t.Fatalf("expected only the listener on c, got %v", bus.mu.listeners)
+	}
+}
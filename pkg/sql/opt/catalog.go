@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util/treeprinter"
@@ -51,6 +52,10 @@ type Column interface {
 	// IsHidden returns true if the column is hidden (e.g., there is always a
 	// hidden column called rowid if there is no primary key on the table).
 	IsHidden() bool
+
+	// IsComputed returns true if the column is a computed column. Computed
+	// columns cannot be written to directly by mutation statements.
+	IsComputed() bool
 }
 
 // IndexColumn describes a single column that is part of an index definition.
@@ -78,7 +83,8 @@ type Index interface {
 	// IdxName is the name of the index.
 	IdxName() string
 
-	// IsInverted returns true if this is a JSON inverted index.
+	// IsInverted returns true if this is an inverted index on a JSON, ARRAY or
+	// TSVECTOR column, or a trigram index.
 	IsInverted() bool
 
 	// ColumnCount returns the number of columns in the index. This includes
@@ -144,6 +150,13 @@ type Index interface {
 	// Column returns the ith IndexColumn within the index definition, where
 	// i < ColumnCount.
 	Column(i int) IndexColumn
+
+	// Predicate returns the predicate of a partial index, or nil if the index
+	// is not partial. A partial index only contains entries for the rows that
+	// satisfy its predicate, so it can only be used by queries whose filter
+	// implies the predicate. Column references in the predicate are unqualified
+	// names of columns in the index's table.
+	Predicate() tree.Expr
 }
 
 // TableStatistic is an interface to a table statistic. Each statistic is
@@ -172,7 +185,24 @@ type TableStatistic interface {
 	// any column in the statistic.
 	NullCount() uint64
 
-	// TODO(radu): add Histogram().
+	// Histogram returns the buckets of the histogram collected for the column of
+	// the statistic, ordered by increasing upper bound. It returns nil if the
+	// statistic has more than one column or if no histogram was collected.
+	Histogram() []HistogramBucket
+}
+
+// HistogramBucket contains the data for a single bucket of a table statistic
+// histogram. See stats.HistogramData for more details.
+type HistogramBucket struct {
+	// NumEq is the estimated number of rows equal to UpperBound.
+	NumEq uint64
+
+	// NumRange is the estimated number of rows strictly between the upper bound
+	// of the previous bucket and UpperBound.
+	NumRange uint64
+
+	// UpperBound is the upper boundary of the bucket. It is never NULL.
+	UpperBound tree.Datum
 }
 
 // Table is an interface to a database table, exposing only the information
@@ -188,6 +218,11 @@ type Table interface {
 	// information_schema tables.
 	IsVirtualTable() bool
 
+	// HasPendingMutations returns true if columns or indexes are being added to
+	// or dropped from the table by a schema change. Such columns and indexes
+	// are not exposed by this interface.
+	HasPendingMutations() bool
+
 	// ColumnCount returns the number of columns in the table.
 	ColumnCount() int
 
@@ -228,6 +263,13 @@ type Catalog interface {
 	// FindTableByID returns a Table interface for the database table
 	// matching the given table ID. Returns an error if the table does not exist.
 	FindTableByID(ctx context.Context, tableID int64) (Table, error)
+
+	// CheckPrivilege verifies that the current user has the given privilege on
+	// the given table. Returns an error if the user does not have the privilege.
+	// FindTable and FindTableByID do not check any privileges, so callers must
+	// check for the privilege that matches the way the table is used (e.g.
+	// SELECT for scans, INSERT for insert targets).
+	CheckPrivilege(ctx context.Context, tab Table, priv privilege.Kind) error
 }
 
 // FormatCatalogTable nicely formats a catalog table using a treeprinter for
@@ -278,6 +320,10 @@ func formatCatalogIndex(idx Index, isPrimary bool, tp treeprinter.Node) {
 
 		child.Child(buf.String())
 	}
+
+	if pred := idx.Predicate(); pred != nil {
+		child.Childf("WHERE %s", pred)
+	}
 }
 
 func formatColumn(col Column, buf *bytes.Buffer) {
@@ -31,6 +31,15 @@ type Builder struct {
 	// expressions we built. Each entry is associated with a tree.Subquery
 	// expression node.
 	subqueries []exec.Subquery
+
+	// withExprs maps the ID of each WITH binding to the expression that it
+	// binds. The binding is built at the (single) WithScan that references it.
+	withExprs map[int]memo.ExprView
+
+	// estimates, if set, accumulates the optimizer's estimates for each node
+	// that we built. It is only set while building the input of EXPLAIN
+	// ANALYZE.
+	estimates map[exec.Node]exec.Estimates
 }
 
 // New constructs an instance of the execution node builder using the
@@ -122,6 +122,9 @@ func (b *Builder) buildRelational(ev memo.ExprView) (execPlan, error) {
 	case opt.LookupJoinOp:
 		ep, err = b.buildLookupJoin(ev)
 
+	case opt.ZigzagJoinOp:
+		ep, err = b.buildZigzagJoin(ev)
+
 	case opt.ExplainOp:
 		ep, err = b.buildExplain(ev)
 
@@ -137,6 +140,18 @@ func (b *Builder) buildRelational(ev memo.ExprView) (execPlan, error) {
 	case opt.ZipOp:
 		ep, err = b.buildZip(ev)
 
+	case opt.WindowOp:
+		ep, err = b.buildWindow(ev)
+
+	case opt.WithOp:
+		ep, err = b.buildWith(ev)
+
+	case opt.WithScanOp:
+		ep, err = b.buildWithScan(ev)
+
+	case opt.InsertOp, opt.UpdateOp, opt.UpsertOp, opt.DeleteOp:
+		ep, err = b.buildMutation(ev)
+
 	default:
 		if ev.IsJoinNonApply() {
 			ep, err = b.buildHashJoin(ev)
@@ -156,8 +171,17 @@ func (b *Builder) buildRelational(ev memo.ExprView) (execPlan, error) {
 	}
 	if p := ev.Physical().Presentation; !p.Any() {
 		ep, err = b.applyPresentation(ep, ev.Metadata(), p)
+		if err != nil {
+			return execPlan{}, err
+		}
 	}
-	return ep, err
+	if b.estimates != nil {
+		b.estimates[ep.root] = exec.Estimates{
+			RowCount: ev.Logical().Relational.Stats.RowCount,
+			Cost:     float64(ev.Cost()),
+		}
+	}
+	return ep, nil
 }
 
 func (b *Builder) buildValues(ev memo.ExprView) (execPlan, error) {
@@ -724,6 +748,160 @@ func (b *Builder) buildRowNumber(ev memo.ExprView) (execPlan, error) {
 	return execPlan{root: node, outputCols: outputCols}, nil
 }
 
+func (b *Builder) buildWindow(ev memo.ExprView) (execPlan, error) {
+	input, err := b.buildRelational(ev.Child(0))
+	if err != nil {
+		return execPlan{}, err
+	}
+	md := ev.Metadata()
+	def := ev.Private().(*memo.WindowDef)
+	windows := ev.Child(1)
+	windowCols := windows.Private().(opt.ColList)
+
+	// The windowing node expects its input to produce the passthrough columns,
+	// followed by the arguments of each window function, followed by the
+	// partition and ordering columns. Add a projection that produces the
+	// columns in that order.
+	numPassthrough := input.outputCols.Len()
+	passthroughCols := make(opt.ColList, numPassthrough)
+	input.outputCols.ForEach(func(col, ord int) {
+		passthroughCols[ord] = opt.ColumnID(col)
+	})
+	projCols := make([]exec.ColumnOrdinal, numPassthrough)
+	for i := range projCols {
+		projCols[i] = exec.ColumnOrdinal(i)
+	}
+	addCol := func(col opt.ColumnID) *tree.IndexedVar {
+		ord := len(projCols)
+		projCols = append(projCols, input.getColumnOrdinal(col))
+		return tree.NewTypedOrdinalReference(ord, md.ColumnType(col))
+	}
+
+	exprs := make([]*tree.FuncExpr, windows.ChildCount())
+	argIdxs := make([][]exec.ColumnOrdinal, len(exprs))
+	for i := range exprs {
+		fn := windows.Child(i)
+		args := make(tree.TypedExprs, fn.ChildCount())
+		argIdxs[i] = make([]exec.ColumnOrdinal, len(args))
+		for j := range args {
+			arg := fn.Child(j)
+			if arg.Operator() != opt.VariableOp {
+				return execPlan{}, errors.Errorf("only VariableOp args supported")
+			}
+			argIdxs[i][j] = exec.ColumnOrdinal(len(projCols))
+			args[j] = addCol(arg.Private().(opt.ColumnID))
+		}
+		exprs[i] = b.buildWindowFunction(fn, args)
+	}
+
+	windowDef := &tree.WindowDef{Frame: def.Frame}
+	partition := make([]exec.ColumnOrdinal, 0, def.Partition.Len())
+	def.Partition.ForEach(func(col int) {
+		partition = append(partition, exec.ColumnOrdinal(len(projCols)))
+		windowDef.Partitions = append(windowDef.Partitions, addCol(opt.ColumnID(col)))
+	})
+	ordering := make(sqlbase.ColumnOrdering, len(def.Ordering))
+	windowDef.OrderBy = make(tree.OrderBy, len(def.Ordering))
+	for i, col := range def.Ordering {
+		ordering[i].ColIdx = len(projCols)
+		order := &tree.Order{Expr: addCol(col.ID())}
+		if col.Descending() {
+			ordering[i].Direction = encoding.Descending
+			order.Direction = tree.Descending
+		} else {
+			ordering[i].Direction = encoding.Ascending
+		}
+		windowDef.OrderBy[i] = order
+	}
+	for i := range exprs {
+		exprs[i].WindowDef = windowDef
+	}
+
+	node, err := b.factory.ConstructSimpleProject(input.root, projCols, nil /* colNames */)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The windowing node passes through the input columns, followed by one
+	// column for each window function.
+	var ep execPlan
+	resultCols := make(sqlbase.ResultColumns, 0, numPassthrough+len(windowCols))
+	for i, col := range append(passthroughCols, windowCols...) {
+		ep.outputCols.Set(int(col), i)
+		resultCols = append(resultCols, sqlbase.ResultColumn{
+			Name: md.ColumnLabel(col),
+			Typ:  md.ColumnType(col),
+		})
+	}
+
+	ep.root, err = b.factory.ConstructWindow(node, exec.WindowInfo{
+		Cols:      resultCols,
+		Exprs:     exprs,
+		ArgIdxs:   argIdxs,
+		Partition: partition,
+		Ordering:  ordering,
+	})
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
+// buildWindowFunction constructs the FuncExpr for the given WindowFunction
+// operator, using the given (already built) arguments.
+func (b *Builder) buildWindowFunction(ev memo.ExprView, args tree.TypedExprs) *tree.FuncExpr {
+	funcDef := ev.Private().(*memo.FuncOpDef)
+	funcRef := tree.ResolvableFunctionReference{FunctionReference: funcDef.Def}
+	return tree.NewTypedFuncExpr(
+		funcRef,
+		0, /* aggQualifier */
+		args,
+		nil, /* filter */
+		nil, /* windowDef */
+		ev.Logical().Scalar.Type,
+		funcDef.Properties,
+		funcDef.Overload,
+	)
+}
+
+func (b *Builder) buildWith(ev memo.ExprView) (execPlan, error) {
+	def := ev.Private().(*memo.WithDef)
+	if b.withExprs == nil {
+		b.withExprs = make(map[int]memo.ExprView)
+	}
+	b.withExprs[def.ID] = ev.Child(0)
+	return b.buildRelational(ev.Child(1))
+}
+
+func (b *Builder) buildWithScan(ev memo.ExprView) (execPlan, error) {
+	def := ev.Private().(*memo.WithScanDef)
+	binding, ok := b.withExprs[def.ID]
+	if !ok {
+		return execPlan{}, errors.Errorf("couldn't find WITH expression %q", def.Name)
+	}
+	// Each WITH binding is referenced at most once, so the binding can be built
+	// in place of the WithScan.
+	input, err := b.buildRelational(binding)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	md := ev.Metadata()
+	cols := make([]exec.ColumnOrdinal, len(def.InCols))
+	colNames := make([]string, len(def.OutCols))
+	var ep execPlan
+	for i := range def.InCols {
+		cols[i] = input.getColumnOrdinal(def.InCols[i])
+		colNames[i] = md.ColumnLabel(def.OutCols[i])
+		ep.outputCols.Set(int(def.OutCols[i]), i)
+	}
+	ep.root, err = b.factory.ConstructSimpleProject(input.root, cols, colNames)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
 func (b *Builder) buildIndexJoin(ev memo.ExprView) (execPlan, error) {
 	var err error
 	// If the index join child is a limit and/or sort operator then flip the order
@@ -836,6 +1014,75 @@ func (b *Builder) buildLookupJoin(ev memo.ExprView) (execPlan, error) {
 	return res, nil
 }
 
+func (b *Builder) buildZigzagJoin(ev memo.ExprView) (execPlan, error) {
+	md := ev.Metadata()
+	def := ev.Private().(*memo.ZigzagJoinDef)
+	tab := md.Table(def.Table)
+	eqCols := opt.ColListToSet(def.EqCols)
+
+	// Each side produces the output columns that are part of its index, as
+	// well as the equality columns.
+	leftCols := def.Cols.Intersection(md.IndexColumns(def.Table, def.LeftIndex))
+	leftCols.UnionWith(eqCols)
+	rightCols := def.Cols.Intersection(md.IndexColumns(def.Table, def.RightIndex))
+	rightCols.UnionWith(eqCols)
+
+	leftOrdinals, leftColMap := b.getColumns(md, leftCols, def.Table)
+	rightOrdinals, rightColMap := b.getColumns(md, rightCols, def.Table)
+	allCols := joinOutputMap(leftColMap, rightColMap)
+	res := execPlan{outputCols: allCols}
+
+	leftEqCols := make([]exec.ColumnOrdinal, len(def.EqCols))
+	rightEqCols := make([]exec.ColumnOrdinal, len(def.EqCols))
+	for i, col := range def.EqCols {
+		leftOrd, _ := leftColMap.Get(int(col))
+		rightOrd, _ := rightColMap.Get(int(col))
+		leftEqCols[i] = exec.ColumnOrdinal(leftOrd)
+		rightEqCols[i] = exec.ColumnOrdinal(rightOrd)
+	}
+
+	// The fixed values of the left index are followed by those of the right
+	// index.
+	fixedVals := ev.Child(1)
+	leftFixedVals := make(tree.Datums, len(def.LeftFixedCols))
+	for i := range leftFixedVals {
+		leftFixedVals[i] = memo.ExtractConstDatum(fixedVals.Child(i))
+	}
+	rightFixedVals := make(tree.Datums, len(def.RightFixedCols))
+	for i := range rightFixedVals {
+		rightFixedVals[i] = memo.ExtractConstDatum(fixedVals.Child(len(leftFixedVals) + i))
+	}
+
+	// The equality columns are produced by both sides, so the number of
+	// columns is larger than the size of the output column map.
+	numCols := leftOrdinals.Len() + rightOrdinals.Len()
+	ctx := buildScalarCtx{
+		ivh:     tree.MakeIndexedVarHelper(nil /* container */, numCols),
+		ivarMap: allCols,
+	}
+	onExpr, err := b.buildScalar(&ctx, ev.Child(0))
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	res.root, err = b.factory.ConstructZigzagJoin(
+		tab,
+		tab.Index(def.LeftIndex),
+		leftOrdinals,
+		leftFixedVals,
+		leftEqCols,
+		tab.Index(def.RightIndex),
+		rightOrdinals,
+		rightFixedVals,
+		rightEqCols,
+		onExpr,
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return res, nil
+}
+
 // initZipBuild builds the expressions in a Zip operation and initializes the
 // data structures needed to build a projectSetNode.
 // Note: this function modifies outputCols.
@@ -1003,6 +1250,16 @@ func (b *Builder) buildExplain(ev memo.ExprView) (execPlan, error) {
 		return b.constructValues(ev.Metadata(), rows, def.ColList)
 	}
 
+	analyze := def.Options.Mode == tree.ExplainPlan &&
+		def.Options.Flags.Contains(tree.ExplainFlagAnalyze)
+	if analyze {
+		// Special case: EXPLAIN ANALYZE. Remember the estimates for the nodes we
+		// build, so they can be shown next to the statistics collected when the
+		// plan runs.
+		b.estimates = make(map[exec.Node]exec.Estimates)
+		defer func() { b.estimates = nil }()
+	}
+
 	input, err := b.buildRelational(ev.Child(0))
 	if err != nil {
 		return execPlan{}, err
@@ -1012,7 +1269,12 @@ func (b *Builder) buildExplain(ev memo.ExprView) (execPlan, error) {
 	if err != nil {
 		return execPlan{}, err
 	}
-	node, err := b.factory.ConstructExplain(&def.Options, plan)
+	var node exec.Node
+	if analyze {
+		node, err = b.factory.ConstructExplainAnalyze(plan, b.estimates)
+	} else {
+		node, err = b.factory.ConstructExplain(&def.Options, plan)
+	}
 	if err != nil {
 		return execPlan{}, err
 	}
@@ -1041,6 +1303,63 @@ func (b *Builder) buildShowTrace(ev memo.ExprView) (execPlan, error) {
 	return ep, nil
 }
 
+func (b *Builder) buildMutation(ev memo.ExprView) (execPlan, error) {
+	def := ev.Private().(*memo.MutationOpDef)
+	tab := ev.Metadata().Table(def.Table)
+
+	input, err := b.buildRelational(ev.Child(0))
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// Project the input columns in the order expected by the factory: the
+	// fetched columns (if any) in table order, followed by the inserted or
+	// updated columns (if any) in table order.
+	var inputCols opt.ColList
+	var ords []int
+	appendCols := func(colList opt.ColList, addOrds bool) {
+		for ord, col := range colList {
+			if col != 0 {
+				inputCols = append(inputCols, col)
+				if addOrds {
+					ords = append(ords, ord)
+				}
+			}
+		}
+	}
+	appendCols(def.FetchCols, false /* addOrds */)
+	appendCols(def.InsertCols, true /* addOrds */)
+	appendCols(def.UpdateCols, true /* addOrds */)
+
+	node, err := b.ensureColumns(input, inputCols)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	rowsNeeded := def.NeedResults()
+	switch ev.Operator() {
+	case opt.InsertOp:
+		node, err = b.factory.ConstructInsert(node, tab, ords, rowsNeeded)
+	case opt.UpdateOp:
+		node, err = b.factory.ConstructUpdate(node, tab, ords, rowsNeeded)
+	case opt.UpsertOp:
+		node, err = b.factory.ConstructUpsert(node, tab, ords, def.OnConflict, rowsNeeded)
+	case opt.DeleteOp:
+		node, err = b.factory.ConstructDelete(node, tab, rowsNeeded)
+	}
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// If the rows are needed, the mutation returns every column of the table,
+	// in table order.
+	ep := execPlan{root: node}
+	for i, col := range def.ReturnCols {
+		ep.outputCols.Set(int(col), i)
+	}
+	return ep, nil
+}
+
 // buildSortedInput is a helper method that can be reused to sort any input plan
 // by the given ordering.
 func (b *Builder) buildSortedInput(
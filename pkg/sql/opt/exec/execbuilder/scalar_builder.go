@@ -57,6 +57,10 @@ func init() {
 		opt.AnyOp:             (*Builder).buildAny,
 		opt.UnsupportedExprOp: (*Builder).buildUnsupportedExpr,
 
+		// Inlinable calls to user-defined functions that were not inlined are
+		// built as regular calls.
+		opt.InlinableFunctionOp: (*Builder).buildInlinableFunction,
+
 		// Subquery operators.
 		opt.ExistsOp:   (*Builder).buildExistsSubquery,
 		opt.SubqueryOp: (*Builder).buildSubquery,
@@ -217,7 +221,7 @@ func (b *Builder) buildFunction(ctx *buildScalarCtx, ev memo.ExprView) (tree.Typ
 		}
 	}
 	funcDef := ev.Private().(*memo.FuncOpDef)
-	funcRef := tree.WrapFunction(funcDef.Name)
+	funcRef := tree.ResolvableFunctionReference{FunctionReference: funcDef.Def}
 	return tree.NewTypedFuncExpr(
 		funcRef,
 		0, /* aggQualifier */
@@ -230,6 +234,12 @@ func (b *Builder) buildFunction(ctx *buildScalarCtx, ev memo.ExprView) (tree.Typ
 	), nil
 }
 
+func (b *Builder) buildInlinableFunction(
+	ctx *buildScalarCtx, ev memo.ExprView,
+) (tree.TypedExpr, error) {
+	return b.buildScalar(ctx, ev.Child(0))
+}
+
 func (b *Builder) buildCase(ctx *buildScalarCtx, ev memo.ExprView) (tree.TypedExpr, error) {
 	input, err := b.buildScalar(ctx, ev.Child(0))
 	if err != nil {
@@ -161,6 +161,29 @@ type Factory interface {
 		reqOrder sqlbase.ColumnOrdering,
 	) (Node, error)
 
+	// ConstructZigzagJoin returns a node that performs a zigzag join between
+	// two indexes of the same table. Each index has a prefix of its columns
+	// fixed to the given values (leftFixedVals and rightFixedVals), and the
+	// indexes are joined on the equality columns that follow the prefix;
+	// leftEqCols and rightEqCols are ordinals into the columns produced by each
+	// side, which are given by leftCols and rightCols.
+	//
+	// The node produces the columns in leftCols followed by the columns in
+	// rightCols (ordered by ordinal). The ON condition can refer to these using
+	// IndexedVars.
+	ConstructZigzagJoin(
+		table opt.Table,
+		leftIndex opt.Index,
+		leftCols ColumnOrdinalSet,
+		leftFixedVals tree.Datums,
+		leftEqCols []ColumnOrdinal,
+		rightIndex opt.Index,
+		rightCols ColumnOrdinalSet,
+		rightFixedVals tree.Datums,
+		rightEqCols []ColumnOrdinal,
+		onCond tree.TypedExpr,
+	) (Node, error)
+
 	// ConstructLimit returns a node that implements LIMIT and/or OFFSET on the
 	// results of the given node. If one or the other is not needed, then it is
 	// set to nil.
@@ -173,9 +196,47 @@ type Factory interface {
 		n Node, exprs tree.TypedExprs, zipCols sqlbase.ResultColumns, numColsPerGen []int,
 	) (Node, error)
 
+	// ConstructWindow returns a node that computes window functions over the
+	// rows produced by the given node. All window functions share the same
+	// partitioning and ordering (see WindowInfo).
+	ConstructWindow(input Node, window WindowInfo) (Node, error)
+
 	// RenameColumns modifies the column names of a node.
 	RenameColumns(input Node, colNames []string) (Node, error)
 
+	// ConstructInsert returns a node that inserts the rows produced by the
+	// input node into the given table. The input node produces one column for
+	// each entry in insertCols, which holds the ordinal of the corresponding
+	// table column; the remaining table columns receive their default (or
+	// computed) values. If rowsNeeded is true, then the node returns the
+	// inserted rows, with one column for each column in the table. Otherwise
+	// it returns the number of inserted rows.
+	ConstructInsert(input Node, table opt.Table, insertCols []int, rowsNeeded bool) (Node, error)
+
+	// ConstructUpdate returns a node that updates rows in the given table. The
+	// input node produces the existing values of the rows to update, with one
+	// column for each column in the table, followed by one column with the new
+	// value for each entry in updateCols, which holds the ordinal of the
+	// updated table column. If rowsNeeded is true, then the node returns the
+	// updated rows. Otherwise it returns the number of updated rows.
+	ConstructUpdate(input Node, table opt.Table, updateCols []int, rowsNeeded bool) (Node, error)
+
+	// ConstructUpsert returns a node that inserts the rows produced by the
+	// input node into the given table, updating existing rows according to the
+	// given ON CONFLICT clause when they conflict with the inserted rows. The
+	// input node is structured like the input of ConstructInsert. If rowsNeeded
+	// is true, then the node returns the inserted or updated rows. Otherwise it
+	// returns the number of affected rows.
+	ConstructUpsert(
+		input Node, table opt.Table, insertCols []int, onConflict *tree.OnConflict, rowsNeeded bool,
+	) (Node, error)
+
+	// ConstructDelete returns a node that deletes rows from the given table.
+	// The input node produces the values of the rows to delete, with one column
+	// for each column in the table. If rowsNeeded is true, then the node
+	// returns the deleted rows. Otherwise it returns the number of deleted rows.
+	ConstructDelete(input Node, table opt.Table, rowsNeeded bool) (Node, error)
+
 	// ConstructPlan creates a plan enclosing the given plan and (optionally)
 	// subqueries.
 	ConstructPlan(root Node, subqueries []Subquery) (Plan, error)
@@ -184,11 +245,26 @@ type Factory interface {
 	// information about the given plan.
 	ConstructExplain(options *tree.ExplainOptions, plan Plan) (Node, error)
 
+	// ConstructExplainAnalyze returns a node that implements EXPLAIN ANALYZE:
+	// it runs the given plan and shows its tree, annotating each node with the
+	// optimizer's estimates (if any) and the statistics collected during
+	// execution.
+	ConstructExplainAnalyze(plan Plan, estimates map[Node]Estimates) (Node, error)
+
 	// ConstructShowTrace returns a node that implements a SHOW TRACE
 	// FOR SESSION statement.
 	ConstructShowTrace(typ tree.ShowTraceType, compact bool) (Node, error)
 }
 
+// Estimates contains the estimates made by the optimizer for a node; they are
+// shown by EXPLAIN ANALYZE next to the statistics collected during execution.
+type Estimates struct {
+	// RowCount is the estimated number of rows produced by the node.
+	RowCount float64
+	// Cost is the estimated cost of the node, including the cost of its inputs.
+	Cost float64
+}
+
 // Subquery encapsulates information about a subquery that is part of a plan.
 type Subquery struct {
 	// ExprNode is a reference to a tree.Subquery node that has been created for
@@ -217,6 +293,31 @@ const (
 	SubqueryAnyRows
 )
 
+// WindowInfo represents the information about a window function computation
+// (see ConstructWindow). The input node produces the columns that are passed
+// through, followed by the arguments of each window function (in order),
+// followed by the partition and ordering columns.
+type WindowInfo struct {
+	// Cols is the set of columns returned by the window operator: the
+	// passthrough columns followed by one column for each window function.
+	Cols sqlbase.ResultColumns
+
+	// Exprs is the list of window function expressions. The arguments are
+	// ordinal references to the input columns.
+	Exprs []*tree.FuncExpr
+
+	// ArgIdxs are the ordinals of the input columns holding the arguments of
+	// each window function.
+	ArgIdxs [][]ColumnOrdinal
+
+	// Partition is the set of input column ordinals by which the rows are
+	// partitioned.
+	Partition []ColumnOrdinal
+
+	// Ordering is the ordering of the rows within each partition.
+	Ordering sqlbase.ColumnOrdering
+}
+
 // ColumnOrdinal is the 0-based ordinal index of a column produced by a Node.
 type ColumnOrdinal int32
 
@@ -28,6 +28,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/trigram"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 )
 
 // Convenience aliases to avoid the constraint prefix everywhere.
@@ -745,6 +747,176 @@ func (c *indexConstraintCtx) makeSpansForOr(
 	return tight
 }
 
+// makeInvertedIndexSpansForArrayContains generates spans for an "@>" with a
+// constant array on its right side. An inverted index on an array has one
+// entry for each distinct non-NULL element of the array, so the rows that
+// contain any one element of arr can be looked up with a single span. The span
+// is only tight if arr has a single distinct element.
+func (c *indexConstraintCtx) makeInvertedIndexSpansForArrayContains(
+	arr *tree.DArray, out *constraint.Constraint,
+) (tight bool) {
+	for _, elem := range arr.Array {
+		if elem == tree.DNull {
+			// NULL elements are not contained in any array.
+			c.contradiction(0 /* offset */, out)
+			return true
+		}
+	}
+	elems := c.distinctArrayElements(arr)
+	if len(elems) == 0 {
+		// Every array contains the empty array, including the ones that have no
+		// entries in the index.
+		c.unconstrained(0 /* offset */, out)
+		return false
+	}
+	c.eqSpan(0 /* offset */, elems[0], out)
+	return len(elems) == 1
+}
+
+// makeInvertedIndexSpansForArrayOverlaps generates spans for an "&&" with a
+// constant array on its right side: the union of the spans for each distinct
+// non-NULL element of arr. A row can be found in more than one of the spans,
+// so the primary keys returned by a scan over them must be deduplicated.
+func (c *indexConstraintCtx) makeInvertedIndexSpansForArrayOverlaps(
+	arr *tree.DArray, out *constraint.Constraint,
+) (tight bool) {
+	elems := c.distinctArrayElements(arr)
+	if len(elems) == 0 {
+		// No array overlaps an array without non-NULL elements.
+		c.contradiction(0 /* offset */, out)
+		return true
+	}
+	c.eqSpan(0 /* offset */, elems[0], out)
+	for _, elem := range elems[1:] {
+		var other constraint.Constraint
+		c.eqSpan(0 /* offset */, elem, &other)
+		out.UnionWith(c.evalCtx, &other)
+	}
+	return true
+}
+
+// distinctArrayElements returns a single-element array for each distinct
+// non-NULL element of arr. Such an array is the value of the inverted index
+// entries for the element.
+func (c *indexConstraintCtx) distinctArrayElements(arr *tree.DArray) []tree.Datum {
+	elems := make([]tree.Datum, 0, len(arr.Array))
+	for _, elem := range arr.Array {
+		if elem == tree.DNull {
+			continue
+		}
+		dup := false
+		for _, prev := range elems {
+			if prev.(*tree.DArray).Array[0].Compare(c.evalCtx, elem) == 0 {
+				dup = true
+				break
+			}
+		}
+		if dup {
+			continue
+		}
+		d := tree.NewDArray(arr.ParamTyp)
+		if err := d.Append(elem); err != nil {
+			panic(err)
+		}
+		elems = append(elems, d)
+	}
+	return elems
+}
+
+// makeTrigramIndexSpansForLike generates spans on a trigram index for a LIKE
+// or ILIKE with a constant pattern. Every matching string has all of the
+// trigrams returned by trigram.LikeTrigrams, so the rows can be looked up with
+// the span of any one of them; the trigram with the least padding is chosen,
+// as it is usually the most selective. The span is never tight.
+func (c *indexConstraintCtx) makeTrigramIndexSpansForLike(
+	pattern string, out *constraint.Constraint,
+) (tight bool) {
+	trigrams := trigram.LikeTrigrams(pattern)
+	if len(trigrams) == 0 {
+		c.unconstrained(0 /* offset */, out)
+		return false
+	}
+	best := trigrams[0]
+	for _, t := range trigrams[1:] {
+		if strings.Count(t, " ") < strings.Count(best, " ") {
+			best = t
+		}
+	}
+	c.eqSpan(0 /* offset */, tree.NewDString(best), out)
+	return false
+}
+
+// makeTrigramIndexSpansForSimilar generates spans on a trigram index for a
+// "%" with a constant string: the union of the spans for each trigram of s,
+// since a similar string shares at least one of them. A row can be found in
+// more than one of the spans, so the primary keys returned by a scan over them
+// must be deduplicated. The spans are never tight.
+func (c *indexConstraintCtx) makeTrigramIndexSpansForSimilar(
+	s string, out *constraint.Constraint,
+) (tight bool) {
+	trigrams := trigram.MakeTrigrams(s)
+	if len(trigrams) == 0 {
+		// A string without trigrams isn't similar to any string.
+		c.contradiction(0 /* offset */, out)
+		return true
+	}
+	c.eqSpan(0 /* offset */, tree.NewDString(trigrams[0]), out)
+	for _, t := range trigrams[1:] {
+		var other constraint.Constraint
+		c.eqSpan(0 /* offset */, tree.NewDString(t), &other)
+		out.UnionWith(c.evalCtx, &other)
+	}
+	return false
+}
+
+// makeTSVectorIndexSpansForQuery generates spans on the inverted index of a
+// TSVECTOR column for an "@@" with a constant query rooted at n. A lexeme
+// gets the span of its word, or of the words it is a prefix of; a conjunction
+// gets the spans of one of its operands and a disjunction the union of the
+// spans of both. A negation can't be constrained, and the empty query matches
+// nothing. The spans are never tight, since they disregard weights and
+// negations, and a vector can be found more than once in them.
+func (c *indexConstraintCtx) makeTSVectorIndexSpansForQuery(
+	n *tsearch.Node, out *constraint.Constraint,
+) (tight bool) {
+	if n == nil {
+		c.contradiction(0 /* offset */, out)
+		return true
+	}
+	switch n.Op {
+	case tsearch.OpLexeme:
+		if n.Prefix {
+			c.makeStringPrefixSpan(0 /* offset */, n.Word, out)
+		} else {
+			c.eqSpan(0 /* offset */, tree.NewDString(n.Word), out)
+		}
+		return false
+
+	case tsearch.OpAnd:
+		c.makeTSVectorIndexSpansForQuery(n.Left, out)
+		if out.IsUnconstrained() {
+			c.makeTSVectorIndexSpansForQuery(n.Right, out)
+		}
+		return false
+
+	case tsearch.OpOr:
+		c.makeTSVectorIndexSpansForQuery(n.Left, out)
+		if out.IsUnconstrained() {
+			return false
+		}
+		var other constraint.Constraint
+		c.makeTSVectorIndexSpansForQuery(n.Right, &other)
+		if other.IsUnconstrained() {
+			c.unconstrained(0 /* offset */, out)
+			return false
+		}
+		out.UnionWith(c.evalCtx, &other)
+		return false
+	}
+	c.unconstrained(0 /* offset */, out)
+	return false
+}
+
 // makeInvertedIndexSpansForExpr is analogous to makeSpansForExpr, but it is
 // used for inverted indexes.
 func (c *indexConstraintCtx) makeInvertedIndexSpansForExpr(
@@ -754,7 +926,8 @@ func (c *indexConstraintCtx) makeInvertedIndexSpansForExpr(
 	case opt.ContainsOp:
 		lhs, rhs := ev.Child(0), ev.Child(1)
 
-		if !c.isIndexColumn(lhs, 0 /* index */) || !rhs.IsConstValue() {
+		if !c.isIndexColumn(lhs, 0 /* index */) ||
+			!(rhs.IsConstValue() || memo.MatchesArrayOfConstants(rhs)) {
 			c.unconstrained(0 /* offset */, out)
 			return false
 		}
@@ -766,6 +939,10 @@ func (c *indexConstraintCtx) makeInvertedIndexSpansForExpr(
 			return true
 		}
 
+		if arr, ok := rightDatum.(*tree.DArray); ok {
+			return c.makeInvertedIndexSpansForArrayContains(arr, out)
+		}
+
 		rd := rightDatum.(*tree.DJSON).JSON
 
 		switch rd.Type() {
@@ -827,6 +1004,78 @@ func (c *indexConstraintCtx) makeInvertedIndexSpansForExpr(
 			return true
 		}
 
+	case opt.OverlapsOp:
+		lhs, rhs := ev.Child(0), ev.Child(1)
+
+		if !c.isIndexColumn(lhs, 0 /* index */) ||
+			!(rhs.IsConstValue() || memo.MatchesArrayOfConstants(rhs)) {
+			c.unconstrained(0 /* offset */, out)
+			return false
+		}
+
+		rightDatum := memo.ExtractConstDatum(rhs)
+		if rightDatum == tree.DNull {
+			c.contradiction(0 /* offset */, out)
+			return true
+		}
+		return c.makeInvertedIndexSpansForArrayOverlaps(rightDatum.(*tree.DArray), out)
+
+	case opt.LikeOp, opt.ILikeOp:
+		// Only trigram indexes have string columns.
+		lhs, rhs := ev.Child(0), ev.Child(1)
+		if !c.isIndexColumn(lhs, 0 /* index */) || !rhs.IsConstValue() {
+			c.unconstrained(0 /* offset */, out)
+			return false
+		}
+
+		pattern := memo.ExtractConstDatum(rhs)
+		if pattern == tree.DNull {
+			c.contradiction(0 /* offset */, out)
+			return true
+		}
+		return c.makeTrigramIndexSpansForLike(string(*pattern.(*tree.DString)), out)
+
+	case opt.TrigramSimilarOp:
+		lhs, rhs := ev.Child(0), ev.Child(1)
+		// The operator is commutative.
+		if !c.isIndexColumn(lhs, 0 /* index */) {
+			lhs, rhs = rhs, lhs
+		}
+		if !c.isIndexColumn(lhs, 0 /* index */) || !rhs.IsConstValue() {
+			c.unconstrained(0 /* offset */, out)
+			return false
+		}
+
+		s := memo.ExtractConstDatum(rhs)
+		if s == tree.DNull {
+			c.contradiction(0 /* offset */, out)
+			return true
+		}
+		return c.makeTrigramIndexSpansForSimilar(string(*s.(*tree.DString)), out)
+
+	case opt.TSMatchesOp:
+		lhs, rhs := ev.Child(0), ev.Child(1)
+		// The operator is commutative.
+		if !c.isIndexColumn(lhs, 0 /* index */) {
+			lhs, rhs = rhs, lhs
+		}
+		if !c.isIndexColumn(lhs, 0 /* index */) || c.colType(0) != types.TSVector ||
+			!rhs.IsConstValue() {
+			c.unconstrained(0 /* offset */, out)
+			return false
+		}
+
+		q := memo.ExtractConstDatum(rhs)
+		if q == tree.DNull {
+			c.contradiction(0 /* offset */, out)
+			return true
+		}
+		if q.ResolvedType() != types.TSQuery {
+			c.unconstrained(0 /* offset */, out)
+			return false
+		}
+		return c.makeTSVectorIndexSpansForQuery(tree.MustBeDTSQuery(q).Root, out)
+
 	case opt.AndOp, opt.FiltersOp:
 		for i, n := 0, ev.ChildCount(); i < n; i++ {
 			tight := c.makeInvertedIndexSpansForExpr(ev.Child(i), out)
@@ -172,6 +172,13 @@ func (e *Expr) ChildGroup(mem *Memo, nth int) GroupID {
 // use this method as a building block when searching and replacing expressions
 // in a tree.
 func (e *Expr) Replace(mem *Memo, replace ReplaceChildFunc) Expr {
+	return MakeExpr(e.op, e.ReplaceOperands(mem, replace))
+}
+
+// ReplaceOperands is similar to Replace, except that it returns the operands of
+// the new expression rather than the expression itself. Callers can pass the
+// operands to a factory, so that the new expression is normalized.
+func (e *Expr) ReplaceOperands(mem *Memo, replace ReplaceChildFunc) DynamicOperands {
 	var operands DynamicOperands
 	layout := opLayoutTable[e.op]
 
@@ -194,13 +201,12 @@ func (e *Expr) Replace(mem *Memo, replace ReplaceChildFunc) Expr {
 		nth++
 	}
 
-	// Append the private and construct a new Expr with possibly modified
-	// children.
+	// Append the private.
 	privateID := e.PrivateID()
 	if privateID != 0 {
 		operands[nth] = DynamicID(privateID)
 	}
-	return MakeExpr(e.op, operands)
+	return operands
 }
 
 // Private returns the value of this expression's private field, if it has one,
@@ -265,7 +265,14 @@ func (ev ExprView) formatRelational(f *opt.ExprFmtCtx, tp treeprinter.Node) {
 		formatter.formatPrivate(def, formatNormal)
 		buf.WriteByte(')')
 
-	case opt.ScanOp, opt.VirtualScanOp, opt.IndexJoinOp, opt.ShowTraceForSessionOp:
+	case opt.ZigzagJoinOp:
+		def := ev.Private().(*ZigzagJoinDef)
+		fmt.Fprintf(&buf, "%v (zigzag", opt.InnerJoinOp)
+		formatter.formatPrivate(def, formatNormal)
+		buf.WriteByte(')')
+
+	case opt.ScanOp, opt.VirtualScanOp, opt.IndexJoinOp, opt.ShowTraceForSessionOp,
+		opt.InsertOp, opt.UpdateOp, opt.UpsertOp, opt.DeleteOp, opt.WithOp, opt.WithScanOp:
 		fmt.Fprintf(&buf, "%v", ev.op)
 		formatter.formatPrivate(ev.Private(), formatNormal)
 
@@ -315,6 +322,10 @@ func (ev ExprView) formatRelational(f *opt.ExprFmtCtx, tp treeprinter.Node) {
 			colMap := ev.Private().(*SetOpColMap)
 			logProps.FormatColList(f, tp, "columns:", colMap.Out)
 
+		case opt.WithScanOp:
+			def := ev.Private().(*WithScanDef)
+			logProps.FormatColList(f, tp, "columns:", def.OutCols)
+
 		default:
 			// Fall back to writing output columns in column id order, with
 			// best guess label.
@@ -365,6 +376,53 @@ func (ev ExprView) formatRelational(f *opt.ExprFmtCtx, tp treeprinter.Node) {
 			idxCols[i] = def.Table.ColumnID(idx.Column(i).Ordinal)
 		}
 		tp.Childf("key columns: %v = %v", def.KeyCols, idxCols)
+
+	// Special-case handling for ZigzagJoin to show the equality columns and
+	// the values of the fixed columns of each index.
+	case opt.ZigzagJoinOp:
+		def := ev.Private().(*ZigzagJoinDef)
+		tp.Childf("eq columns: %v = %v", def.EqCols, def.EqCols)
+		fixedVals := ev.Child(1)
+		n := len(def.LeftFixedCols)
+		tp.Childf("left fixed columns: %v = %s", def.LeftFixedCols, formatTupleRange(fixedVals, 0, n))
+		tp.Childf("right fixed columns: %v = %s",
+			def.RightFixedCols, formatTupleRange(fixedVals, n, len(def.RightFixedCols)))
+
+	// Special-case handling for mutation operators to show the input columns
+	// that provide the values of the target table columns.
+	case opt.InsertOp, opt.UpsertOp:
+		def := ev.Private().(*MutationOpDef)
+		ev.formatMutationMapping(tp, "insert-mapping:", def.Table, def.InsertCols)
+
+	case opt.UpdateOp:
+		def := ev.Private().(*MutationOpDef)
+		ev.Child(0).Logical().FormatColList(f, tp, "fetch columns:", def.FetchCols)
+		ev.formatMutationMapping(tp, "update-mapping:", def.Table, def.UpdateCols)
+
+	case opt.DeleteOp:
+		def := ev.Private().(*MutationOpDef)
+		ev.Child(0).Logical().FormatColList(f, tp, "fetch columns:", def.FetchCols)
+
+	// Special-case handling for the Window private; print the partition
+	// columns, the ordering and the frame of the window.
+	case opt.WindowOp:
+		def := ev.Private().(*WindowDef)
+		if !def.Partition.Empty() {
+			logProps.FormatColSet(f, tp, "partition by:", def.Partition)
+		}
+		if len(def.Ordering) > 0 {
+			tp.Childf("window-ordering: %s", def.Ordering)
+		}
+		if def.Frame != nil {
+			tp.Childf("frame: %s", tree.AsString(def.Frame))
+		}
+
+	// Special-case handling for WithScan to show the binding columns that
+	// correspond to the output columns.
+	case opt.WithScanOp:
+		def := ev.Private().(*WithScanDef)
+		bindingProps := props.Logical{Relational: def.BindingProps}
+		bindingProps.FormatColList(f, tp, "binding columns:", def.InCols)
 	}
 
 	if !f.HasFlags(opt.ExprFmtHideMiscProps) {
@@ -422,14 +480,34 @@ func (ev ExprView) formatRelational(f *opt.ExprFmtCtx, tp treeprinter.Node) {
 		}
 	}
 
-	for i := 0; i < ev.ChildCount(); i++ {
+	childCount := ev.ChildCount()
+	if ev.Operator() == opt.ZigzagJoinOp {
+		// The fixed values were already shown with the fixed columns.
+		childCount = 1
+	}
+	for i := 0; i < childCount; i++ {
 		ev.Child(i).format(f, tp)
 	}
 }
 
+// formatTupleRange returns a string representation of n constant elements of
+// the given tuple, starting with the given element.
+func formatTupleRange(tuple ExprView, start, n int) string {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(ExtractConstDatum(tuple.Child(start + i)).String())
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
+
 func (ev ExprView) formatScalar(f *opt.ExprFmtCtx, tp treeprinter.Node) {
 	// Omit empty ProjectionsOp and AggregationsOp.
-	if (ev.op == opt.ProjectionsOp || ev.op == opt.AggregationsOp) &&
+	if (ev.op == opt.ProjectionsOp || ev.op == opt.AggregationsOp || ev.op == opt.WindowsOp) &&
 		ev.ChildCount() == 0 {
 		return
 	}
@@ -474,7 +552,7 @@ func (ev ExprView) FormatScalarProps(f *opt.ExprFmtCtx, buf *bytes.Buffer) {
 		}
 
 		switch ev.Operator() {
-		case opt.ProjectionsOp, opt.AggregationsOp:
+		case opt.ProjectionsOp, opt.AggregationsOp, opt.WindowsOp:
 			// Don't show the type of these ops because they are simply tuple
 			// types of their children's types, and the types of children are
 			// already listed.
@@ -518,9 +596,9 @@ func (ev ExprView) formatScalarPrivate(buf *bytes.Buffer, private interface{}) {
 		// Private is redundant with logical type property.
 		private = nil
 
-	case opt.ProjectionsOp, opt.AggregationsOp:
+	case opt.ProjectionsOp, opt.AggregationsOp, opt.WindowsOp:
 		// The private data of these ops was already used to print the output
-		// columns for their containing op (Project or GroupBy), so no need to
+		// columns for their containing op (Project, GroupBy or Window), so no need to
 		// print again.
 		private = nil
 	}
@@ -532,6 +610,29 @@ func (ev ExprView) formatScalarPrivate(buf *bytes.Buffer, private interface{}) {
 	}
 }
 
+// formatMutationMapping outputs a line for each column of the target table of
+// a mutation operator that is provided by the given list of input columns,
+// which is indexed by table column ordinal.
+func (ev ExprView) formatMutationMapping(
+	tp treeprinter.Node, heading string, tabID opt.TableID, colList opt.ColList,
+) {
+	md := ev.Metadata()
+	tab := md.Table(tabID)
+	var child treeprinter.Node
+	first := true
+	for ord, col := range colList {
+		if col == 0 {
+			continue
+		}
+		if first {
+			child = tp.Child(heading)
+			first = false
+		}
+		child.Childf("%s:%d => %s:%d",
+			md.ColumnLabel(col), col, tab.Column(ord).ColName(), tabID.ColumnID(ord))
+	}
+}
+
 func (ev ExprView) formatPresentation(
 	f *opt.ExprFmtCtx, tp treeprinter.Node, presentation props.Presentation,
 ) {
@@ -566,6 +667,12 @@ func MatchesTupleOfConstants(ev ExprView) bool {
 	return ev.Operator() == opt.TupleOp && HasOnlyConstChildren(ev)
 }
 
+// MatchesArrayOfConstants returns true if the expression is an ArrayOp with
+// constant values.
+func MatchesArrayOfConstants(ev ExprView) bool {
+	return ev.Operator() == opt.ArrayOp && HasOnlyConstChildren(ev)
+}
+
 // ExprFmtInterceptor is a callback that can be set to a custom formatting
 // function. If the function returns true, the normal formatting code is bypassed.
 var ExprFmtInterceptor func(f *opt.ExprFmtCtx, tp treeprinter.Node, ev ExprView) bool
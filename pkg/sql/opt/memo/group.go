@@ -72,6 +72,24 @@ func makeMemoGroup(id GroupID, norm Expr) group {
 	return group{id: id, normExpr: norm}
 }
 
+// copy returns a copy of the group. The logical properties are copied as well,
+// since some of them (e.g. statistics) are computed lazily.
+func (g *group) copy() group {
+	c := *g
+	if g.logical.Relational != nil {
+		rel := *g.logical.Relational
+		rel.Stats.CopyFrom(&g.logical.Relational.Stats)
+		c.logical.Relational = &rel
+	}
+	if g.logical.Scalar != nil {
+		scalar := *g.logical.Scalar
+		c.logical.Scalar = &scalar
+	}
+	c.otherExprs = append([]Expr(nil), g.otherExprs...)
+	c.otherBestExprs = append([]BestExpr(nil), g.otherBestExprs...)
+	return c
+}
+
 // isScalarGroup returns true if this group contains scalar expressions and not
 // relational expressions.
 func (g *group) isScalarGroup() bool {
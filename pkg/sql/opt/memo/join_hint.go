@@ -0,0 +1,65 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package memo
+
+import "github.com/cockroachdb/cockroach/pkg/sql/opt"
+
+// JoinHint forces the algorithm used to execute a join, overriding the choice
+// that the optimizer would otherwise make based on cost.
+type JoinHint uint8
+
+const (
+	// NoJoinHint allows the optimizer to choose any join algorithm.
+	NoJoinHint JoinHint = iota
+
+	// HashJoinHint forces a hash join.
+	HashJoinHint
+
+	// MergeJoinHint forces a merge join.
+	MergeJoinHint
+
+	// LookupJoinHint forces a lookup join into an index of one of the inputs.
+	LookupJoinHint
+)
+
+var joinHintNames = [...]string{
+	NoJoinHint:     "none",
+	HashJoinHint:   "hash",
+	MergeJoinHint:  "merge",
+	LookupJoinHint: "lookup",
+}
+
+func (h JoinHint) String() string {
+	return joinHintNames[h]
+}
+
+// Allows returns false if an expression with the given operator implements a
+// join using a different algorithm than the one forced by the hint. Operators
+// that do not implement joins (e.g. enforcers) are always allowed.
+func (h JoinHint) Allows(op opt.Operator) bool {
+	if h == NoJoinHint {
+		return true
+	}
+	switch op {
+	case opt.InnerJoinOp, opt.LeftJoinOp, opt.RightJoinOp, opt.FullJoinOp,
+		opt.SemiJoinOp, opt.AntiJoinOp:
+		return h == HashJoinHint
+	case opt.MergeJoinOp:
+		return h == MergeJoinHint
+	case opt.LookupJoinOp:
+		return h == LookupJoinHint
+	}
+	return true
+}
@@ -90,6 +90,16 @@ type listStorageKey struct {
 	item   GroupID
 }
 
+// copyFrom initializes the list storage with a copy of the lists in the
+// given storage.
+func (ls *listStorage) copyFrom(other *listStorage) {
+	ls.index = make(map[listStorageKey]uint32, len(other.index))
+	for k, v := range other.index {
+		ls.index[k] = v
+	}
+	ls.lists = append([]GroupID(nil), other.lists...)
+}
+
 // intern adds the given list to storage and returns an id that can later be
 // used to retrieve the list by calling the lookup method. If the list has been
 // previously added to storage, then intern always returns the same list id
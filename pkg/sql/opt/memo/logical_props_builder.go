@@ -76,6 +76,12 @@ func (b *logicalPropsBuilder) buildRelationalProps(ev ExprView) props.Logical {
 	case opt.IndexJoinOp:
 		logical = b.buildIndexJoinProps(ev)
 
+	case opt.LookupJoinOp:
+		logical = b.buildLookupJoinProps(ev)
+
+	case opt.ZigzagJoinOp:
+		logical = b.buildZigzagJoinProps(ev)
+
 	case opt.UnionOp, opt.IntersectOp, opt.ExceptOp,
 		opt.UnionAllOp, opt.IntersectAllOp, opt.ExceptAllOp:
 		logical = b.buildSetProps(ev)
@@ -104,6 +110,18 @@ func (b *logicalPropsBuilder) buildRelationalProps(ev ExprView) props.Logical {
 	case opt.ZipOp:
 		logical = b.buildZipProps(ev)
 
+	case opt.WindowOp:
+		logical = b.buildWindowProps(ev)
+
+	case opt.WithOp:
+		logical = b.buildWithProps(ev)
+
+	case opt.WithScanOp:
+		logical = b.buildWithScanProps(ev)
+
+	case opt.InsertOp, opt.UpdateOp, opt.UpsertOp, opt.DeleteOp:
+		logical = b.buildMutationProps(ev)
+
 	default:
 		panic(fmt.Sprintf("unrecognized relational expression type: %v", ev.op))
 	}
@@ -517,6 +535,13 @@ func (b *logicalPropsBuilder) buildJoinProps(ev ExprView) props.Logical {
 		relational.Cardinality = relational.Cardinality.AtMost(1)
 	}
 
+	// Join Size
+	// ---------
+	// Count the inner joins in the tree of inner joins rooted at this join.
+	if ev.Operator() == opt.InnerJoinOp {
+		relational.JoinSize = leftProps.JoinSize + rightProps.JoinSize + 1
+	}
+
 	// Statistics
 	// ----------
 	b.sb.init(b.evalCtx, &keyBuffer{})
@@ -570,6 +595,150 @@ func (b *logicalPropsBuilder) buildIndexJoinProps(ev ExprView) props.Logical {
 	return logical
 }
 
+func (b *logicalPropsBuilder) buildLookupJoinProps(ev ExprView) props.Logical {
+	logical := props.Logical{Relational: &props.Relational{}}
+	relational := logical.Relational
+
+	inputProps := ev.childGroup(0).logical.Relational
+	onProps := ev.childGroup(1).logical.Scalar
+	md := ev.Metadata()
+	def := ev.Private().(*LookupJoinDef)
+	index := md.Table(def.Table).Index(def.Index)
+
+	// Output Columns
+	// --------------
+	// Output columns are the input columns plus the columns retrieved from the
+	// index.
+	relational.OutputCols = inputProps.OutputCols.Union(def.LookupCols)
+
+	// Not Null Columns
+	// ----------------
+	// Propagate not null setting from the input, and add not-NULL columns from
+	// the table schema, unless they can be null-extended by a left join.
+	relational.NotNullCols = inputProps.NotNullCols.Copy()
+	if def.JoinType == opt.InnerJoinOp {
+		tableNotNullCols := b.tableNotNullCols(md, def.Table)
+		relational.NotNullCols.UnionWith(tableNotNullCols.Intersection(def.LookupCols))
+		b.applyNotNullConstraint(relational, onProps.Constraints)
+	}
+
+	// Outer Columns
+	// -------------
+	// Any outer columns from the ON condition that are not bound by the output
+	// columns are outer columns for the lookup join, in addition to any outer
+	// columns inherited from the input.
+	if !onProps.OuterCols.SubsetOf(relational.OutputCols) {
+		relational.OuterCols = onProps.OuterCols.Difference(relational.OutputCols)
+	}
+	relational.OuterCols.UnionWith(inputProps.OuterCols)
+
+	// Functional Dependencies
+	// -----------------------
+	// The lookup join is modeled as a join between the input and a scan of the
+	// table, with an equality condition between each key column and the
+	// corresponding index column.
+	relational.FuncDeps.CopyFrom(&inputProps.FuncDeps)
+	relational.FuncDeps.MakeProduct(b.makeTableFuncDep(md, def.Table))
+	for i, keyCol := range def.KeyCols {
+		indexCol := def.Table.ColumnID(index.Column(i).Ordinal)
+		if keyCol != indexCol {
+			relational.FuncDeps.AddEquivalency(keyCol, indexCol)
+		}
+	}
+	switch def.JoinType {
+	case opt.InnerJoinOp:
+		relational.FuncDeps.AddFrom(&onProps.FuncDeps)
+		b.applyOuterColConstants(relational)
+
+	case opt.LeftJoinOp:
+		notNullCols := inputProps.NotNullCols.Union(b.tableNotNullCols(md, def.Table))
+		relational.FuncDeps.MakeOuter(def.LookupCols, notNullCols)
+	}
+	relational.FuncDeps.MakeNotNull(relational.NotNullCols)
+	relational.FuncDeps.ProjectCols(relational.OutputCols)
+
+	// Cardinality
+	// -----------
+	// Each input row matches at most one row if the key columns cover the
+	// index key. A left join returns at least one row for each input row.
+	relational.Cardinality = props.AnyCardinality
+	if len(def.KeyCols) >= index.LaxKeyColumnCount() {
+		relational.Cardinality = relational.Cardinality.AtMost(inputProps.Cardinality.Max)
+	}
+	if def.JoinType == opt.LeftJoinOp {
+		relational.Cardinality = relational.Cardinality.AtLeast(inputProps.Cardinality.Min)
+	}
+	if relational.FuncDeps.HasMax1Row() {
+		relational.Cardinality = relational.Cardinality.AtMost(1)
+	}
+
+	// Statistics
+	// ----------
+	b.sb.init(b.evalCtx, &keyBuffer{})
+	b.sb.buildLookupJoin(ev, relational)
+
+	return logical
+}
+
+func (b *logicalPropsBuilder) buildZigzagJoinProps(ev ExprView) props.Logical {
+	logical := props.Logical{Relational: &props.Relational{}}
+	relational := logical.Relational
+
+	onProps := ev.childGroup(0).logical.Scalar
+	md := ev.Metadata()
+	def := ev.Private().(*ZigzagJoinDef)
+
+	// Output Columns
+	// --------------
+	// Zigzag join output columns are stored in the definition.
+	relational.OutputCols = def.Cols
+
+	// Not Null Columns
+	// ----------------
+	// Initialize not-NULL columns from the table schema, and add any columns
+	// that are made not-NULL by the ON condition (which includes the equality
+	// conditions on the fixed columns).
+	relational.NotNullCols = b.tableNotNullCols(md, def.Table)
+	relational.NotNullCols.IntersectionWith(relational.OutputCols)
+	b.applyNotNullConstraint(relational, onProps.Constraints)
+
+	// Outer Columns
+	// -------------
+	// Any outer columns from the ON condition that are not bound by the output
+	// columns are outer columns for the zigzag join.
+	if !onProps.OuterCols.SubsetOf(relational.OutputCols) {
+		relational.OuterCols = onProps.OuterCols.Difference(relational.OutputCols)
+	}
+
+	// Functional Dependencies
+	// -----------------------
+	// Since both indexes belong to the same table and are joined on its primary
+	// key, the result is equivalent to a Select over a Scan of the table. Start
+	// with the table's FD set, and add the FDs from the ON condition.
+	relational.FuncDeps.CopyFrom(b.makeTableFuncDep(md, def.Table))
+	relational.FuncDeps.AddFrom(&onProps.FuncDeps)
+	b.applyOuterColConstants(relational)
+	relational.FuncDeps.MakeNotNull(relational.NotNullCols)
+	relational.FuncDeps.ProjectCols(relational.OutputCols)
+
+	// Cardinality
+	// -----------
+	// The ON condition can filter any or all rows.
+	relational.Cardinality = props.AnyCardinality
+	if onProps.Constraints == constraint.Contradiction {
+		relational.Cardinality = props.ZeroCardinality
+	} else if relational.FuncDeps.HasMax1Row() {
+		relational.Cardinality = relational.Cardinality.AtMost(1)
+	}
+
+	// Statistics
+	// ----------
+	b.sb.init(b.evalCtx, &keyBuffer{})
+	b.sb.buildZigzagJoin(ev, relational)
+
+	return logical
+}
+
 func (b *logicalPropsBuilder) buildGroupByProps(ev ExprView) props.Logical {
 	logical := props.Logical{Relational: &props.Relational{}}
 	relational := logical.Relational
@@ -767,6 +936,56 @@ func (b *logicalPropsBuilder) buildExplainProps(ev ExprView) props.Logical {
 	return logical
 }
 
+func (b *logicalPropsBuilder) buildMutationProps(ev ExprView) props.Logical {
+	logical := props.Logical{Relational: &props.Relational{}}
+	relational := logical.Relational
+
+	md := ev.Metadata()
+	inputProps := ev.Child(0).Logical().Relational
+	def := ev.Private().(*MutationOpDef)
+
+	// Output Columns
+	// --------------
+	// Output columns are stored in the definition. If there is no RETURNING
+	// clause, then the mutation does not return any columns.
+	relational.OutputCols = opt.ColListToSet(def.ReturnCols)
+
+	// Not Null Columns
+	// ----------------
+	// The returned rows are always consistent with the schema of the target
+	// table, so not-NULL columns are derived from the table.
+	if def.NeedResults() {
+		relational.NotNullCols = b.tableNotNullCols(md, def.Table)
+	}
+
+	// Outer Columns
+	// -------------
+	// Outer columns are inherited from input.
+	relational.OuterCols = inputProps.OuterCols
+
+	// Functional Dependencies
+	// -----------------------
+	// Mutation operators have an empty FD set.
+
+	// Cardinality
+	// -----------
+	// Don't make any assumptions about cardinality of output, so that the
+	// mutation is never eliminated because of its cardinality.
+	relational.Cardinality = props.AnyCardinality
+
+	// Side Effects
+	// ------------
+	// Mutations always modify state outside their own scope.
+	relational.CanHaveSideEffects = true
+
+	// Statistics
+	// ----------
+	b.sb.init(b.evalCtx, &keyBuffer{})
+	b.sb.buildMutation(ev, relational)
+
+	return logical
+}
+
 func (b *logicalPropsBuilder) buildShowTraceProps(ev ExprView) props.Logical {
 	logical := props.Logical{Relational: &props.Relational{}}
 	relational := logical.Relational
@@ -1043,6 +1262,140 @@ func (b *logicalPropsBuilder) buildZipProps(ev ExprView) props.Logical {
 	return logical
 }
 
+func (b *logicalPropsBuilder) buildWindowProps(ev ExprView) props.Logical {
+	logical := props.Logical{Relational: &props.Relational{}}
+	relational := logical.Relational
+
+	inputProps := ev.Child(0).Logical().Relational
+	windows := ev.Child(1)
+
+	// Output Columns
+	// --------------
+	// The window function columns are added to those projected by the input
+	// operator.
+	relational.OutputCols = inputProps.OutputCols.Union(
+		opt.ColListToSet(windows.Private().(opt.ColList)),
+	)
+
+	// Not Null Columns
+	// ----------------
+	// Window function columns are assumed to be nullable; other columns
+	// inherit the not null property from the input.
+	relational.NotNullCols = inputProps.NotNullCols
+
+	// Outer Columns
+	// -------------
+	// Outer columns were derived by buildRelationalProps; now filter out any
+	// that are output columns.
+	relational.OuterCols = windows.Logical().OuterCols().Union(inputProps.OuterCols)
+	relational.OuterCols.DifferenceWith(inputProps.OutputCols)
+
+	// Functional Dependencies
+	// -----------------------
+	// Inherit functional dependencies from input. Any existing keys are still
+	// keys, since a window operator never changes the number of rows.
+	relational.FuncDeps.CopyFrom(&inputProps.FuncDeps)
+	if key, ok := relational.FuncDeps.Key(); ok {
+		relational.FuncDeps.AddStrictKey(key, relational.OutputCols)
+	}
+
+	// Cardinality
+	// -----------
+	// Inherit cardinality from input.
+	relational.Cardinality = inputProps.Cardinality
+
+	// Statistics
+	// ----------
+	b.sb.init(b.evalCtx, &keyBuffer{})
+	b.sb.buildWindow(ev, relational)
+
+	return logical
+}
+
+func (b *logicalPropsBuilder) buildWithProps(ev ExprView) props.Logical {
+	logical := props.Logical{Relational: &props.Relational{}}
+	relational := logical.Relational
+
+	bindingProps := ev.Child(0).Logical().Relational
+	inputProps := ev.Child(1).Logical().Relational
+
+	// Output Columns
+	// --------------
+	// Passthrough all columns from the input expression.
+	relational.OutputCols = inputProps.OutputCols
+
+	// Not Null Columns
+	// ----------------
+	// Inherit the not null columns from the input expression.
+	relational.NotNullCols = inputProps.NotNullCols
+
+	// Outer Columns
+	// -------------
+	// Outer columns of both the binding and the input expression are outer
+	// columns of the With expression.
+	relational.OuterCols = bindingProps.OuterCols.Union(inputProps.OuterCols)
+
+	// Functional Dependencies
+	// -----------------------
+	// Inherit functional dependencies from the input expression.
+	relational.FuncDeps.CopyFrom(&inputProps.FuncDeps)
+
+	// Cardinality
+	// -----------
+	// Inherit cardinality from the input expression.
+	relational.Cardinality = inputProps.Cardinality
+
+	// Statistics
+	// ----------
+	b.sb.init(b.evalCtx, &keyBuffer{})
+	b.sb.buildWith(ev, relational)
+
+	return logical
+}
+
+func (b *logicalPropsBuilder) buildWithScanProps(ev ExprView) props.Logical {
+	logical := props.Logical{Relational: &props.Relational{}}
+	relational := logical.Relational
+
+	def := ev.Private().(*WithScanDef)
+	bindingProps := def.BindingProps
+
+	// Output Columns
+	// --------------
+	// Output columns are stored in the definition.
+	relational.OutputCols = opt.ColListToSet(def.OutCols)
+
+	// Not Null Columns
+	// ----------------
+	// Map the not null columns of the binding to the output columns.
+	for i, col := range def.InCols {
+		if bindingProps.NotNullCols.Contains(int(col)) {
+			relational.NotNullCols.Add(int(def.OutCols[i]))
+		}
+	}
+
+	// Outer Columns
+	// -------------
+	// No outer columns.
+
+	// Functional Dependencies
+	// -----------------------
+	// The output columns are fresh columns, so the functional dependencies of
+	// the binding do not apply; the FD set is empty.
+
+	// Cardinality
+	// -----------
+	// Inherit cardinality from the binding.
+	relational.Cardinality = bindingProps.Cardinality
+
+	// Statistics
+	// ----------
+	b.sb.init(b.evalCtx, &keyBuffer{})
+	b.sb.buildWithScan(ev, relational)
+
+	return logical
+}
+
 func (b *logicalPropsBuilder) buildScalarProps(ev ExprView) props.Logical {
 	logical := props.Logical{Scalar: &props.Scalar{Type: InferType(ev)}}
 	scalar := logical.Scalar
@@ -1105,7 +1458,7 @@ func (b *logicalPropsBuilder) buildScalarProps(ev ExprView) props.Logical {
 			}
 		}
 
-	case opt.FunctionOp:
+	case opt.FunctionOp, opt.WindowFunctionOp:
 		funcOpDef := ev.Private().(*FuncOpDef)
 		if funcOpDef.Properties.Impure {
 			// Impure functions can return different value on each call.
@@ -1148,6 +1501,11 @@ func (b *logicalPropsBuilder) makeTableFuncDep(
 			// Skip inverted indexes for now.
 			continue
 		}
+		if index.Predicate() != nil {
+			// The key of a partial index only holds for the rows that satisfy its
+			// predicate, not for the whole table.
+			continue
+		}
 
 		// If index has a separate lax key, add a lax key FD. Otherwise, add a
 		// strict key. See the comment for opt.Index.LaxKeyColumnCount.
@@ -129,6 +129,10 @@ type Memo struct {
 	// there are so many duplicates.
 	privateStorage privateStorage
 
+	// joinHints maps the groups of joins that were given a join hint to that
+	// hint. It is only allocated once the first hint is set.
+	joinHints map[GroupID]JoinHint
+
 	// root is the root of the lowest cost expression tree in the memo. It is
 	// set once after optimization is complete.
 	root BestExprID
@@ -150,6 +154,33 @@ func New() *Memo {
 	return m
 }
 
+// Copy returns a copy of the memo, to which expressions can be added without
+// affecting the original. The copy is typically used to optimize a normalized
+// expression tree that is cached, without invalidating the cached tree.
+func (m *Memo) Copy() *Memo {
+	c := &Memo{
+		metadata: m.metadata.Copy(),
+		exprMap:  make(map[Fingerprint]GroupID, len(m.exprMap)),
+		groups:   make([]group, len(m.groups)),
+		root:     m.root,
+	}
+	for f, g := range m.exprMap {
+		c.exprMap[f] = g
+	}
+	for i := range m.groups {
+		c.groups[i] = m.groups[i].copy()
+	}
+	c.listStorage.copyFrom(&m.listStorage)
+	c.privateStorage.copyFrom(&m.privateStorage)
+	if m.joinHints != nil {
+		c.joinHints = make(map[GroupID]JoinHint, len(m.joinHints))
+		for g, hint := range m.joinHints {
+			c.joinHints[g] = hint
+		}
+	}
+	return c
+}
+
 // Metadata returns the metadata instance associated with the memo.
 func (m *Memo) Metadata() *opt.Metadata {
 	return m.metadata
@@ -211,6 +242,27 @@ func (m *Memo) AddAltFingerprint(alt Fingerprint, group GroupID) {
 	}
 }
 
+// SetJoinHint records that the expressions in the given join group must use the
+// join algorithm selected by the hint. If the group already has a hint, it is
+// replaced.
+func (m *Memo) SetJoinHint(group GroupID, hint JoinHint) {
+	if m.joinHints == nil {
+		m.joinHints = make(map[GroupID]JoinHint)
+	}
+	m.joinHints[group] = hint
+}
+
+// JoinHint returns the join hint recorded for the given group, or NoJoinHint if
+// there is none.
+func (m *Memo) JoinHint(group GroupID) JoinHint {
+	return m.joinHints[group]
+}
+
+// HasJoinHints returns true if a join hint was recorded for any group.
+func (m *Memo) HasJoinHints() bool {
+	return len(m.joinHints) != 0
+}
+
 // newGroup creates a new group and adds it to the memo.
 func (m *Memo) newGroup(norm Expr) *group {
 	id := GroupID(len(m.groups))
@@ -328,6 +328,33 @@ func (f exprFormatter) formatPrivate(private interface{}, mode formatMode) {
 			fmt.Fprintf(f.buf, ",keyCols=%v,lookupCols=%s", t.KeyCols, t.LookupCols)
 		}
 
+	case *ZigzagJoinDef:
+		tab := f.mem.metadata.Table(t.Table)
+		fmt.Fprintf(f.buf, " %s@%s,%s@%s",
+			tab.TabName().TableName, tab.Index(t.LeftIndex).IdxName(),
+			tab.TabName().TableName, tab.Index(t.RightIndex).IdxName(),
+		)
+		if mode == formatMemo {
+			fmt.Fprintf(f.buf, ",eqCols=%v,cols=%s", t.EqCols, t.Cols)
+		}
+
+	case *MutationOpDef:
+		fmt.Fprintf(f.buf, " %s", f.mem.metadata.Table(t.Table).TabName().TableName)
+
+	case *WindowDef:
+		if mode == formatMemo {
+			fmt.Fprintf(f.buf, " partition=%s", t.Partition)
+			if len(t.Ordering) > 0 {
+				fmt.Fprintf(f.buf, ",ordering=%s", t.Ordering)
+			}
+		}
+
+	case *WithDef:
+		fmt.Fprintf(f.buf, " &%d (%s)", t.ID, t.Name)
+
+	case *WithScanDef:
+		fmt.Fprintf(f.buf, " &%d (%s)", t.ID, t.Name)
+
 	case *ExplainOpDef:
 		if mode == formatMemo {
 			propsStr := t.Props.String()
@@ -0,0 +1,140 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package memo
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// PartialIndexPredicatesAnnID is the annotation under which the scalar groups
+// of the predicates of a table's partial indexes are stored in the metadata.
+// The annotation is set by optbuilder when the table is scanned, and has type
+// PartialIndexPredicates.
+var PartialIndexPredicatesAnnID = opt.NewTableAnnID()
+
+// PartialIndexPredicates maps the ordinal of each partial index of a table to
+// the Filters group of its predicate.
+type PartialIndexPredicates map[int]GroupID
+
+// PartialIndexPredicate returns the Filters group of the predicate of the
+// given partial index of the given table. It returns ok=false if the index is
+// not partial, or if no predicate was built for it.
+func (m *Memo) PartialIndexPredicate(tabID opt.TableID, index int) (_ GroupID, ok bool) {
+	preds, _ := m.metadata.TableAnnotation(tabID, PartialIndexPredicatesAnnID).(PartialIndexPredicates)
+	pred, ok := preds[index]
+	return pred, ok
+}
+
+// ImpliesPartialIndexPredicate returns ok=true if every row that satisfies the
+// given filter also satisfies the given partial index predicate, in which case
+// the partial index can be scanned in place of the table. The check is
+// conservative: a predicate conjunct is implied only if it is also a conjunct
+// of the filter, or if it is exactly equivalent to a single constraint which
+// contains a constraint implied by the filter. For example, the filter
+// "a > 10 AND b = 1" implies the predicates "b = 1" and "a > 0".
+//
+// remaining contains the conjuncts of the filter which are not conjuncts of
+// the predicate; those still need to be applied to the rows of the partial
+// index.
+func (m *Memo) ImpliesPartialIndexPredicate(
+	evalCtx *tree.EvalContext, filter, pred GroupID,
+) (remaining []GroupID, ok bool) {
+	filterConjuncts := m.appendConjuncts(nil, filter)
+	predConjuncts := m.appendConjuncts(nil, pred)
+
+	var filterConstraints *constraint.Set
+	cb := constraintsBuilder{md: m.metadata, evalCtx: evalCtx}
+	var matched util.FastIntSet
+	for _, p := range predConjuncts {
+		found := false
+		for i, f := range filterConjuncts {
+			if f == p {
+				matched.Add(i)
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		if filterConstraints == nil {
+			filterConstraints, _ = cb.buildConstraints(MakeNormExprView(m, filter))
+			if filterConstraints == nil {
+				filterConstraints = unconstrained
+			}
+		}
+		if !constraintsImply(evalCtx, &cb, filterConstraints, MakeNormExprView(m, p)) {
+			return nil, false
+		}
+	}
+
+	remaining = make([]GroupID, 0, len(filterConjuncts)-matched.Len())
+	for i, f := range filterConjuncts {
+		if !matched.Contains(i) {
+			remaining = append(remaining, f)
+		}
+	}
+	return remaining, true
+}
+
+// appendConjuncts appends the conjuncts of the given boolean expression to the
+// given list and returns it.
+func (m *Memo) appendConjuncts(conjuncts []GroupID, group GroupID) []GroupID {
+	ev := MakeNormExprView(m, group)
+	switch ev.Operator() {
+	case opt.FiltersOp, opt.AndOp:
+		for i, n := 0, ev.ChildCount(); i < n; i++ {
+			conjuncts = m.appendConjuncts(conjuncts, ev.ChildGroup(i))
+		}
+	case opt.TrueOp:
+	default:
+		conjuncts = append(conjuncts, group)
+	}
+	return conjuncts
+}
+
+// constraintsImply returns true if the given scalar expression is exactly
+// equivalent to a single constraint, and one of the constraints in the given
+// set is contained in it.
+func constraintsImply(
+	evalCtx *tree.EvalContext, cb *constraintsBuilder, cs *constraint.Set, ev ExprView,
+) bool {
+	predConstraints, tight := cb.buildConstraints(ev)
+	if !tight || predConstraints == nil || predConstraints.Length() != 1 {
+		return false
+	}
+	pc := predConstraints.Constraint(0)
+	for i, n := 0, cs.Length(); i < n; i++ {
+		c := cs.Constraint(i)
+		if !c.Columns.Equals(&pc.Columns) {
+			continue
+		}
+		contained := true
+		for j, m := 0, c.Spans.Count(); j < m; j++ {
+			if !pc.ContainsSpan(evalCtx, c.Spans.Get(j)) {
+				contained = false
+				break
+			}
+		}
+		if contained {
+			return true
+		}
+	}
+	return false
+}
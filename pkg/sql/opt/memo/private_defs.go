@@ -33,12 +33,16 @@ type TupleOrdinal uint32
 
 // FuncOpDef defines the value of the Def private field of the Function
 // operator. It provides the name and return type of the function, as well as a
-// pointer to an already resolved builtin overload definition.
+// pointer to an already resolved overload definition. The function may be a
+// builtin or a user-defined function.
 type FuncOpDef struct {
 	Name       string
 	Type       types.T
 	Properties *tree.FunctionProperties
 	Overload   *tree.Overload
+
+	// Def is the resolved definition of the function.
+	Def *tree.FunctionDefinition
 }
 
 func (f FuncOpDef) String() string {
@@ -207,6 +211,47 @@ type LookupJoinDef struct {
 	LookupCols opt.ColSet
 }
 
+// ZigzagJoinDef defines the value of the Def private field of the ZigzagJoin
+// operator.
+//
+// Example:
+//
+//    CREATE TABLE abc (a INT, b INT, c INT, INDEX (a), INDEX (b))
+//    SELECT * FROM abc WHERE a = 1 AND b = 2
+//
+//    Table: abc
+//    LeftIndex: the index on a
+//    RightIndex: the index on b
+//    LeftFixedCols: a
+//    RightFixedCols: b
+//    EqCols: rowid
+//
+type ZigzagJoinDef struct {
+	// Table identifies the table in which both indexes are defined.
+	Table opt.TableID
+
+	// LeftIndex and RightIndex identify the two indexes that are joined. They
+	// can be passed to the opt.Table.Index(i int) method in order to fetch the
+	// opt.Index metadata.
+	LeftIndex  int
+	RightIndex int
+
+	// LeftFixedCols and RightFixedCols are the columns of the left and right
+	// indexes that are fixed to constant values. They are a prefix of the
+	// index columns, and are listed in the same order.
+	LeftFixedCols  opt.ColList
+	RightFixedCols opt.ColList
+
+	// EqCols are the columns on which the two indexes are joined. In both
+	// indexes, they are the key columns that follow the fixed columns, and are
+	// listed in the same order. They include all the primary key columns.
+	EqCols opt.ColList
+
+	// Cols is the set of columns produced by the zigzag join. Each column must
+	// be part of the left or the right index.
+	Cols opt.ColSet
+}
+
 // ExplainOpDef defines the value of the Def private field of the Explain operator.
 type ExplainOpDef struct {
 	Options tree.ExplainOptions
@@ -218,6 +263,50 @@ type ExplainOpDef struct {
 	Props props.Physical
 }
 
+// MutationOpDef defines the value of the Def private field of the Insert,
+// Update, Upsert and Delete operators. Each of the column lists is indexed by
+// the ordinal position of a column in the target table, and holds the ID of
+// the input column that provides the corresponding value (or zero if the
+// input does not provide a value for that table column).
+type MutationOpDef struct {
+	// Table identifies the target table in the query metadata. It is a
+	// separate instance of the table from any instance that is scanned by the
+	// input expression, so that its column IDs can be used as the output
+	// columns of the mutation.
+	Table opt.TableID
+
+	// InsertCols lists the input columns that provide the values of the
+	// inserted rows. It is only used by Insert and Upsert. Table columns that
+	// have no corresponding input column are set to their default value (or
+	// computed value) by the execution engine.
+	InsertCols opt.ColList
+
+	// FetchCols lists the input columns that hold the existing values of the
+	// rows that are updated or deleted. It is only used by Update and Delete,
+	// and always provides a column for every column in the table.
+	FetchCols opt.ColList
+
+	// UpdateCols lists the input columns that hold the new values of the
+	// updated columns. It is only used by Update.
+	UpdateCols opt.ColList
+
+	// ReturnCols lists the columns returned by the mutation operator if it has
+	// a RETURNING clause (every column in the table is returned). If there is
+	// no RETURNING clause, then ReturnCols is nil and the operator does not
+	// return any columns.
+	ReturnCols opt.ColList
+
+	// OnConflict is the ON CONFLICT clause of an Upsert operator. The UPSERT
+	// statement is represented by a clause for which IsUpsertAlias is true.
+	OnConflict *tree.OnConflict
+}
+
+// NeedResults returns true if the mutation operator returns the affected rows
+// (i.e. if the statement has a RETURNING clause).
+func (m *MutationOpDef) NeedResults() bool {
+	return m.ReturnCols != nil
+}
+
 // ShowTraceOpDef defines the value of the Def private field of the Explain operator.
 type ShowTraceOpDef struct {
 	Type tree.ShowTraceType
@@ -276,6 +365,56 @@ func (w *RowNumberDef) CanProvideOrdering(required *props.OrderingChoice) bool {
 	return w.Ordering.Implies(required)
 }
 
+// WindowDef defines the value of the Def private field of the Window operator.
+// It describes the window over which all of the operator's window functions are
+// computed.
+type WindowDef struct {
+	// Partition is the set of input columns that divide the input rows into
+	// partitions. Window functions are computed separately for each partition.
+	Partition opt.ColSet
+
+	// Ordering is the ordering of the rows within each partition. Rows that are
+	// equal according to the ordering are peers of each other.
+	Ordering opt.Ordering
+
+	// Frame is the optional frame clause that restricts the rows of the
+	// partition that are visible to each window function. A nil Frame denotes
+	// the default frame.
+	Frame *tree.WindowFrame
+}
+
+// WithDef defines the value of the Def private field of the With operator.
+type WithDef struct {
+	// ID uniquely identifies the binding within the query, and is referenced by
+	// the WithScan operators that read from it.
+	ID int
+
+	// Name is the name of the common table expression, used for formatting.
+	Name string
+}
+
+// WithScanDef defines the value of the Def private field of the WithScan
+// operator.
+type WithScanDef struct {
+	// ID identifies the binding of the enclosing With operator.
+	ID int
+
+	// Name is the name of the common table expression, used for formatting.
+	Name string
+
+	// InCols are the output columns of the binding, in the order in which they
+	// are mapped to OutCols.
+	InCols opt.ColList
+
+	// OutCols are the columns produced by WithScan. OutCols[i] has the value of
+	// InCols[i].
+	OutCols opt.ColList
+
+	// BindingProps are the relational properties of the binding, which are used
+	// to derive the properties of WithScan.
+	BindingProps *props.Relational
+}
+
 // SetOpColMap defines the value of the ColMap private field of the set
 // operators: Union, Intersect, Except, UnionAll, IntersectAll and ExceptAll.
 // It matches columns from the left and right inputs of the operator
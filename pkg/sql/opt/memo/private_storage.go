@@ -78,6 +78,16 @@ func (ps *privateStorage) init() {
 	ps.privates = make([]interface{}, 1)
 }
 
+// copyFrom initializes the private storage with a copy of the values in the
+// given storage. The values themselves are immutable, so they are shared.
+func (ps *privateStorage) copyFrom(other *privateStorage) {
+	ps.init()
+	for k, v := range other.privatesMap {
+		ps.privatesMap[k] = v
+	}
+	ps.privates = append(ps.privates[:0], other.privates...)
+}
+
 // lookup returns a private value previously interned by privateStorage.
 func (ps *privateStorage) lookup(id PrivateID) interface{} {
 	return ps.privates[id]
@@ -325,6 +335,33 @@ func (ps *privateStorage) internLookupJoinDef(def *LookupJoinDef) PrivateID {
 	return ps.addValue(privateKey{iface: typ, str: ps.keyBuf.String()}, def)
 }
 
+// internZigzagJoinDef adds the given value to storage and returns an id that
+// can later be used to retrieve the value by calling the lookup method. If the
+// value has been previously added to storage, then internZigzagJoinDef always
+// returns the same private id that was returned from the previous call.
+func (ps *privateStorage) internZigzagJoinDef(def *ZigzagJoinDef) PrivateID {
+	// The below code is carefully constructed to not allocate in the case where
+	// the value is already in the map. Be careful when modifying.
+	ps.keyBuf.Reset()
+	ps.keyBuf.writeUvarint(uint64(def.Table))
+	ps.keyBuf.writeUvarint(uint64(def.LeftIndex))
+	ps.keyBuf.writeUvarint(uint64(def.RightIndex))
+	// Prefix each list with its length, so that the boundaries between them are
+	// unambiguous.
+	ps.keyBuf.writeUvarint(uint64(len(def.LeftFixedCols)))
+	ps.keyBuf.writeColList(def.LeftFixedCols)
+	ps.keyBuf.writeUvarint(uint64(len(def.RightFixedCols)))
+	ps.keyBuf.writeColList(def.RightFixedCols)
+	ps.keyBuf.writeUvarint(uint64(len(def.EqCols)))
+	ps.keyBuf.writeColList(def.EqCols)
+	ps.keyBuf.writeColSet(def.Cols)
+	typ := (*ZigzagJoinDef)(nil)
+	if id, ok := ps.privatesMap[privateKey{iface: typ, str: ps.keyBuf.String()}]; ok {
+		return id
+	}
+	return ps.addValue(privateKey{iface: typ, str: ps.keyBuf.String()}, def)
+}
+
 // internExplainOpDef adds the given value to storage and returns an id that can
 // later be used to retrieve the value by calling the lookup method. If the
 // value has been previously added to storage, then internExplainOpDef always
@@ -348,6 +385,37 @@ func (ps *privateStorage) internExplainOpDef(def *ExplainOpDef) PrivateID {
 	return ps.addValue(privateKey{iface: typ, str: ps.keyBuf.String()}, def)
 }
 
+// internMutationOpDef adds the given value to storage and returns an id that
+// can later be used to retrieve the value by calling the lookup method. If the
+// value has been previously added to storage, then internMutationOpDef always
+// returns the same private id that was returned from the previous call.
+func (ps *privateStorage) internMutationOpDef(def *MutationOpDef) PrivateID {
+	// The below code is carefully constructed to not allocate in the case where
+	// the value is already in the map. Be careful when modifying.
+	ps.keyBuf.Reset()
+	ps.keyBuf.writeUvarint(uint64(def.Table))
+
+	// Prefix each list with its length, since any of the lists can be empty.
+	ps.keyBuf.writeUvarint(uint64(len(def.InsertCols)))
+	ps.keyBuf.writeColList(def.InsertCols)
+	ps.keyBuf.writeUvarint(uint64(len(def.FetchCols)))
+	ps.keyBuf.writeColList(def.FetchCols)
+	ps.keyBuf.writeUvarint(uint64(len(def.UpdateCols)))
+	ps.keyBuf.writeColList(def.UpdateCols)
+	ps.keyBuf.writeUvarint(uint64(len(def.ReturnCols)))
+	ps.keyBuf.writeColList(def.ReturnCols)
+
+	// The ON CONFLICT clause is always built together with a new instance of
+	// the target table, so there is no need to encode it by value.
+	ps.keyBuf.writeUvarint(uint64(uintptr(unsafe.Pointer(def.OnConflict))))
+
+	typ := (*MutationOpDef)(nil)
+	if id, ok := ps.privatesMap[privateKey{iface: typ, str: ps.keyBuf.String()}]; ok {
+		return id
+	}
+	return ps.addValue(privateKey{iface: typ, str: ps.keyBuf.String()}, def)
+}
+
 // internShowTraceOpDef adds the given value to storage and returns an id that can
 // later be used to retrieve the value by calling the lookup method. If the
 // value has been previously added to storage, then internShowTraceOpDef always
@@ -408,6 +476,70 @@ func (ps *privateStorage) internRowNumberDef(def *RowNumberDef) PrivateID {
 	return ps.addValue(privateKey{iface: typ, str: ps.keyBuf.String()}, def)
 }
 
+// internWindowDef adds the given value to storage and returns an id that can
+// later be used to retrieve the value by calling the lookup method. If the
+// value has been previously added to storage, then internWindowDef always
+// returns the same private id that was returned from the previous call.
+func (ps *privateStorage) internWindowDef(def *WindowDef) PrivateID {
+	// The below code is carefully constructed to not allocate in the case where
+	// the value is already in the map. Be careful when modifying.
+	ps.keyBuf.Reset()
+	ps.keyBuf.writeColSet(def.Partition)
+	ps.keyBuf.writeUvarint(uint64(len(def.Ordering)))
+	ps.keyBuf.writeOrdering(def.Ordering)
+
+	// The frame is taken from the window definition in the statement, which is
+	// shared by every window function that uses it, so there is no need to
+	// encode it by value.
+	ps.keyBuf.writeUvarint(uint64(uintptr(unsafe.Pointer(def.Frame))))
+
+	typ := (*WindowDef)(nil)
+	if id, ok := ps.privatesMap[privateKey{iface: typ, str: ps.keyBuf.String()}]; ok {
+		return id
+	}
+	return ps.addValue(privateKey{iface: typ, str: ps.keyBuf.String()}, def)
+}
+
+// internWithDef adds the given value to storage and returns an id that can
+// later be used to retrieve the value by calling the lookup method. If the
+// value has been previously added to storage, then internWithDef always
+// returns the same private id that was returned from the previous call.
+func (ps *privateStorage) internWithDef(def *WithDef) PrivateID {
+	// The below code is carefully constructed to not allocate in the case where
+	// the value is already in the map. Be careful when modifying.
+	ps.keyBuf.Reset()
+	ps.keyBuf.writeUvarint(uint64(def.ID))
+
+	typ := (*WithDef)(nil)
+	if id, ok := ps.privatesMap[privateKey{iface: typ, str: ps.keyBuf.String()}]; ok {
+		return id
+	}
+	return ps.addValue(privateKey{iface: typ, str: ps.keyBuf.String()}, def)
+}
+
+// internWithScanDef adds the given value to storage and returns an id that can
+// later be used to retrieve the value by calling the lookup method. If the
+// value has been previously added to storage, then internWithScanDef always
+// returns the same private id that was returned from the previous call.
+func (ps *privateStorage) internWithScanDef(def *WithScanDef) PrivateID {
+	// The below code is carefully constructed to not allocate in the case where
+	// the value is already in the map. Be careful when modifying.
+	ps.keyBuf.Reset()
+	ps.keyBuf.writeUvarint(uint64(def.ID))
+
+	// The binding properties are determined by the ID, so there is no need to
+	// encode them.
+	ps.keyBuf.writeUvarint(uint64(len(def.InCols)))
+	ps.keyBuf.writeColList(def.InCols)
+	ps.keyBuf.writeColList(def.OutCols)
+
+	typ := (*WithScanDef)(nil)
+	if id, ok := ps.privatesMap[privateKey{iface: typ, str: ps.keyBuf.String()}]; ok {
+		return id
+	}
+	return ps.addValue(privateKey{iface: typ, str: ps.keyBuf.String()}, def)
+}
+
 // internSetOpColMap adds the given value to storage and returns an id that can
 // later be used to retrieve the value by calling the lookup method. If the
 // value has been previously added to storage, then internSetOpColMap always
@@ -614,8 +746,15 @@ func (kb *keyBuffer) writeGroupList(groupList []GroupID) {
 }
 
 // writePhysProps writes the presentation columns, followed by the ordering
-// spec.
+// spec. The presentation is prefixed by its length plus one, or by zero if it
+// is undefined, so that an empty presentation is distinguished from an
+// undefined one.
 func (kb *keyBuffer) writePhysProps(physical *props.Physical) {
+	if physical.Presentation.Any() {
+		kb.writeUvarint(0)
+	} else {
+		kb.writeUvarint(uint64(len(physical.Presentation)) + 1)
+	}
 	for _, col := range physical.Presentation {
 		kb.writeUvarint(uint64(col.ID))
 		kb.WriteString(col.Label)
@@ -470,6 +470,15 @@ func TestInternPhysProps(t *testing.T) {
 	if id1 == id2 {
 		t.Errorf("different physical property instances didn't return different private IDs")
 	}
+
+	// An empty presentation is not the same as an undefined presentation.
+	p4 := props.Physical{Presentation: props.Presentation{}, Ordering: ordering}
+	p5 := props.Physical{Ordering: ordering}
+	id1 = ps.internPhysProps(&p4)
+	id2 = ps.internPhysProps(&p5)
+	if id1 == id2 {
+		t.Errorf("different physical property instances didn't return different private IDs")
+	}
 }
 
 // Ensure that interning values that already exist does not cause unexpected
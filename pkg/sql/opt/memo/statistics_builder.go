@@ -17,6 +17,7 @@ package memo
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
@@ -179,19 +180,21 @@ func (sb *statisticsBuilder) init(evalCtx *tree.EvalContext, keyBuf *keyBuffer)
 // constant for the current expression.
 //
 // If the expression is a scan or virtual scan (and therefore has no children),
-// the statistics are retrieved from the metadata via a call to
-// colStatMetadata.
+// or a zigzag join (which reads directly from the table), the statistics are
+// retrieved from the metadata via a call to colStatMetadata.
 func (sb *statisticsBuilder) colStatFromChild(
 	colSet opt.ColSet, ev ExprView, relProps *props.Relational,
 ) *props.ColumnStatistic {
 	switch t := ev.Operator(); t {
-	case opt.ScanOp, opt.VirtualScanOp:
+	case opt.ScanOp, opt.VirtualScanOp, opt.ZigzagJoinOp:
 		var table opt.TableID
 		switch t {
 		case opt.ScanOp:
 			table = ev.Private().(*ScanOpDef).Table
 		case opt.VirtualScanOp:
 			table = ev.Private().(*VirtualScanOpDef).Table
+		case opt.ZigzagJoinOp:
+			table = ev.Private().(*ZigzagJoinDef).Table
 		}
 
 		s := sb.makeTableStatistics(table, ev.Metadata())
@@ -207,6 +210,17 @@ func (sb *statisticsBuilder) colStatFromChild(
 		}
 	}
 
+	// The columns retrieved by a lookup join are not produced by its children,
+	// so their statistics are retrieved from the metadata.
+	if ev.Operator() == opt.LookupJoinOp {
+		def := ev.Private().(*LookupJoinDef)
+		if def.LookupCols.Intersects(colSet) {
+			s := sb.makeTableStatistics(def.Table, ev.Metadata())
+			fd := &relProps.FuncDeps
+			return sb.colStatMetadata(def.LookupCols.Intersection(colSet), s, fd, ev.Metadata())
+		}
+	}
+
 	// If we could not get the colStat from a child, that means all the columns
 	// in colSet are outer columns. Therefore, we can treat them as a constant.
 	return &props.ColumnStatistic{Cols: colSet, DistinctCount: 1}
@@ -245,6 +259,12 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, ev ExprView) *props.Colu
 	case opt.ValuesOp:
 		return sb.colStatValues(colSet, ev)
 
+	case opt.LookupJoinOp:
+		return sb.colStatLookupJoin(colSet, ev)
+
+	case opt.ZigzagJoinOp:
+		return sb.colStatZigzagJoin(colSet, ev)
+
 	case opt.InnerJoinOp, opt.LeftJoinOp, opt.RightJoinOp, opt.FullJoinOp,
 		opt.SemiJoinOp, opt.AntiJoinOp, opt.InnerJoinApplyOp, opt.LeftJoinApplyOp,
 		opt.RightJoinApplyOp, opt.FullJoinApplyOp, opt.SemiJoinApplyOp, opt.AntiJoinApplyOp:
@@ -272,7 +292,14 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, ev ExprView) *props.Colu
 	case opt.ZipOp:
 		return sb.colStatZip(colSet, ev)
 
-	case opt.ExplainOp, opt.ShowTraceForSessionOp:
+	case opt.WindowOp:
+		return sb.colStatWindow(colSet, ev)
+
+	case opt.WithOp:
+		return sb.colStatWith(colSet, ev)
+
+	case opt.ExplainOp, opt.WithScanOp, opt.ShowTraceForSessionOp,
+		opt.InsertOp, opt.UpdateOp, opt.UpsertOp, opt.DeleteOp:
 		relProps := ev.Logical().Relational
 		return sb.colStatMetadata(colSet, &relProps.Stats, &relProps.FuncDeps, ev.Metadata())
 	}
@@ -755,6 +782,138 @@ func (sb *statisticsBuilder) buildIndexJoin(ev ExprView, relProps *props.Relatio
 	sb.finalizeFromCardinality(relProps)
 }
 
+// +-------------+
+// | Lookup Join |
+// +-------------+
+
+func (sb *statisticsBuilder) buildLookupJoin(ev ExprView, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// Assume that each input row matches one row in the index, which is the
+	// case when the key columns cover the index key.
+	// TODO(optimizer): Use the distinct count of the key columns in the table
+	// to estimate the number of matches when they don't.
+	inputStats := &ev.childGroup(0).logical.Relational.Stats
+	if ev.Private().(*LookupJoinDef).JoinType == opt.LeftJoinOp {
+		// The ON condition doesn't filter any input rows.
+		s.RowCount = inputStats.RowCount
+		sb.finalizeFromCardinality(relProps)
+		return
+	}
+
+	// Update stats based on the ON condition, in the same way as a Select.
+
+	on := ev.Child(1)
+	onFD := &on.Logical().Scalar.FuncDeps
+	equivReps := onFD.EquivReps()
+
+	// Calculate distinct counts for constrained columns
+	// -------------------------------------------------
+	numUnappliedConstraints, constrainedCols := sb.applyFilter(on, equivReps, ev, relProps)
+
+	// Calculate selectivity
+	// ---------------------
+	s.Selectivity = sb.selectivityFromDistinctCounts(constrainedCols, ev, relProps)
+	s.Selectivity *= sb.selectivityFromEquivalencies(equivReps, onFD, ev, relProps)
+	s.Selectivity *= sb.selectivityFromUnappliedConstraints(numUnappliedConstraints)
+
+	// Calculate row count
+	// -------------------
+	sb.applySelectivity(inputStats.RowCount, s)
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatLookupJoin(
+	colSet opt.ColSet, ev ExprView,
+) *props.ColumnStatistic {
+	relProps := ev.Logical().Relational
+	s := &relProps.Stats
+	inputStats := &ev.childGroup(0).logical.Relational.Stats
+	colStat := sb.copyColStatFromChild(colSet, ev, relProps)
+	if ev.Private().(*LookupJoinDef).JoinType == opt.InnerJoinOp {
+		sb.applySelectivityToColStat(colStat, s.Selectivity, inputStats.RowCount)
+	}
+	return colStat
+}
+
+// +-------------+
+// | Zigzag Join |
+// +-------------+
+
+func (sb *statisticsBuilder) buildZigzagJoin(ev ExprView, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// A zigzag join is equivalent to a Select over a Scan of its table, with
+	// the ON condition as the filter, so its statistics are calculated in the
+	// same way as those of a Select.
+
+	on := ev.Child(0)
+	onFD := &on.Logical().Scalar.FuncDeps
+	equivReps := onFD.EquivReps()
+
+	// Calculate distinct counts for constrained columns
+	// -------------------------------------------------
+	numUnappliedConstraints, constrainedCols := sb.applyFilter(on, equivReps, ev, relProps)
+
+	// Calculate selectivity
+	// ---------------------
+	s.Selectivity = sb.selectivityFromDistinctCounts(constrainedCols, ev, relProps)
+	s.Selectivity *= sb.selectivityFromEquivalencies(equivReps, onFD, ev, relProps)
+	s.Selectivity *= sb.selectivityFromUnappliedConstraints(numUnappliedConstraints)
+
+	// Calculate row count
+	// -------------------
+	inputStats := sb.makeTableStatistics(ev.Private().(*ZigzagJoinDef).Table, ev.Metadata())
+	sb.applySelectivity(inputStats.RowCount, s)
+	sb.finalizeFromCardinality(relProps)
+}
+
+// ZigzagJoinScanRowCounts returns the estimated number of rows read by the
+// left and right index scans of a zigzag join with the given definition. Each
+// scan is constrained to a single value of its fixed columns.
+func (m *Memo) ZigzagJoinScanRowCounts(
+	evalCtx *tree.EvalContext, def *ZigzagJoinDef,
+) (leftRows, rightRows float64) {
+	var sb statisticsBuilder
+	sb.init(evalCtx, &keyBuffer{})
+	leftRows = sb.fixedColsRowCount(def.Table, def.LeftFixedCols, m.metadata)
+	rightRows = sb.fixedColsRowCount(def.Table, def.RightFixedCols, m.metadata)
+	return leftRows, rightRows
+}
+
+// fixedColsRowCount estimates the number of rows of the given table in which
+// each of the given columns is equal to a constant value.
+func (sb *statisticsBuilder) fixedColsRowCount(
+	tabID opt.TableID, fixedCols opt.ColList, md *opt.Metadata,
+) float64 {
+	var cols opt.ColSet
+	for _, col := range fixedCols {
+		cols.Add(int(col))
+	}
+	inputStats := sb.makeTableStatistics(tabID, md)
+	colStat := sb.colStatMetadata(cols, inputStats, &props.FuncDepSet{}, md)
+	return inputStats.RowCount / max(colStat.DistinctCount, 1)
+}
+
+func (sb *statisticsBuilder) colStatZigzagJoin(
+	colSet opt.ColSet, ev ExprView,
+) *props.ColumnStatistic {
+	relProps := ev.Logical().Relational
+	s := &relProps.Stats
+	inputStats := sb.makeTableStatistics(ev.Private().(*ZigzagJoinDef).Table, ev.Metadata())
+	colStat := sb.copyColStatFromChild(colSet, ev, relProps)
+	sb.applySelectivityToColStat(colStat, s.Selectivity, inputStats.RowCount)
+	return colStat
+}
+
 // +----------+
 // | Group By |
 // +----------+
@@ -1064,6 +1223,101 @@ func (sb *statisticsBuilder) colStatRowNumber(
 	return colStat
 }
 
+// +--------+
+// | Window |
+// +--------+
+
+func (sb *statisticsBuilder) buildWindow(ev ExprView, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// A window operator returns exactly one row for each input row.
+	inputStats := &ev.childGroup(0).logical.Relational.Stats
+
+	s.RowCount = inputStats.RowCount
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatWindow(colSet opt.ColSet, ev ExprView) *props.ColumnStatistic {
+	relProps := ev.Logical().Relational
+	inputCols := ev.childGroup(0).logical.Relational.OutputCols
+
+	if colSet.SubsetOf(inputCols) {
+		colStat := sb.makeColStat(colSet, &relProps.Stats)
+		inputColStat := sb.colStatFromChild(colSet, ev, relProps)
+		colStat.DistinctCount = inputColStat.DistinctCount
+		return colStat
+	}
+
+	// Nothing is known about the distribution of window function results, so
+	// fall back to estimates based on the metadata.
+	return sb.colStatMetadata(colSet, &relProps.Stats, &relProps.FuncDeps, ev.Metadata())
+}
+
+// +------+
+// | With |
+// +------+
+
+func (sb *statisticsBuilder) buildWith(ev ExprView, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// The With operator returns the rows of its input expression.
+	inputStats := &ev.childGroup(1).logical.Relational.Stats
+
+	s.RowCount = inputStats.RowCount
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatWith(colSet opt.ColSet, ev ExprView) *props.ColumnStatistic {
+	relProps := ev.Logical().Relational
+	colStat := sb.makeColStat(colSet, &relProps.Stats)
+	inputColStat := sb.colStat(colSet, ev.Child(1))
+	colStat.DistinctCount = inputColStat.DistinctCount
+	return colStat
+}
+
+// +-----------+
+// | With Scan |
+// +-----------+
+
+func (sb *statisticsBuilder) buildWithScan(ev ExprView, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// A WithScan returns every row of the bound expression.
+	s.RowCount = ev.Private().(*WithScanDef).BindingProps.Stats.RowCount
+	sb.finalizeFromCardinality(relProps)
+}
+
+// +----------+
+// | Mutation |
+// +----------+
+
+func (sb *statisticsBuilder) buildMutation(ev ExprView, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// Every input row is inserted, updated or deleted, and (if there is a
+	// RETURNING clause) returned.
+	inputStats := &ev.childGroup(0).logical.Relational.Stats
+
+	s.RowCount = inputStats.RowCount
+	sb.finalizeFromCardinality(relProps)
+}
+
 // +-----+
 // | Zip |
 // +-----+
@@ -1124,6 +1378,7 @@ func (sb *statisticsBuilder) copyColStatFromChild(
 	inputColStat := sb.colStatFromChild(colSet, ev, relProps)
 	colStat := sb.makeColStat(colSet, &relProps.Stats)
 	*colStat = *inputColStat
+	colStat.HistogramFiltered = false
 	return colStat
 }
 
@@ -1200,10 +1455,16 @@ func (sb *statisticsBuilder) makeTableStatistics(
 				key := opt.ColumnID(col)
 
 				if _, ok := stats.ColStats[key]; !ok {
-					stats.ColStats[key] = &props.ColumnStatistic{
+					colStat := &props.ColumnStatistic{
 						Cols:          cols,
 						DistinctCount: float64(stat.DistinctCount()),
 					}
+					if histogram := stat.Histogram(); len(histogram) > 0 {
+						colStat.Histogram = props.NewHistogram(
+							sb.evalCtx, histogram, colStat.DistinctCount,
+						)
+					}
+					stats.ColStats[key] = colStat
 				}
 			} else {
 				// Get a unique key for this column set.
@@ -1463,6 +1724,10 @@ func (sb *statisticsBuilder) applyConstraintSet(
 func (sb *statisticsBuilder) updateDistinctCountsFromConstraint(
 	c *constraint.Constraint, ev ExprView, relProps *props.Relational,
 ) (applied bool) {
+	// If the first column has a histogram, the histogram determines its
+	// distinct count even if the constraint is an inequality.
+	applied = sb.applyConstraintToHistogram(c, ev, relProps)
+
 	// All of the columns that are part of the prefix have a finite number of
 	// distinct values.
 	prefix := c.Prefix(sb.evalCtx)
@@ -1533,13 +1798,68 @@ func (sb *statisticsBuilder) updateDistinctCountsFromConstraint(
 		}
 
 		colID := c.Columns.Get(col).ID()
-		sb.ensureColStat(colID, distinctCount, ev, relProps)
+		colStat := sb.ensureColStat(colID, distinctCount, ev, relProps)
+		if col > 0 {
+			// Only the histogram of the first column is filtered by the
+			// constraint, so the histograms of the other columns are stale.
+			colStat.Histogram = nil
+			colStat.HistogramFiltered = false
+		}
 		applied = true
 	}
 
 	return applied
 }
 
+// applyConstraintToHistogram filters the histogram of the first column of the
+// given constraint, if the column has one, so that it only describes the
+// values within the constraint spans. The distinct count of the column is
+// updated accordingly, and the filtered histogram is later used to calculate
+// the selectivity of the constraint (see selectivityFromDistinctCount). It
+// returns true if the histogram was filtered.
+//
+// Unlike distinct counts, histograms allow the selectivity of range
+// predicates such as x > 10 to be estimated, as well as the selectivity of
+// equality predicates on values that are much more (or less) frequent than
+// the average value.
+func (sb *statisticsBuilder) applyConstraintToHistogram(
+	c *constraint.Constraint, ev ExprView, relProps *props.Relational,
+) (applied bool) {
+	col := c.Columns.Get(0).ID()
+	colStat, ok := relProps.Stats.ColStats[col]
+	if !ok {
+		// Avoid deriving the column statistic if there is no histogram to be
+		// found for the column.
+		if !sb.tableHasHistogram(col, ev.Metadata()) {
+			return false
+		}
+		colStat = sb.copyColStatFromChild(util.MakeFastIntSet(int(col)), ev, relProps)
+	}
+	if colStat.Histogram == nil {
+		return false
+	}
+
+	histogram := colStat.Histogram.Filter(c)
+	if histogram == nil {
+		return false
+	}
+	colStat.Histogram = histogram
+	colStat.HistogramFiltered = true
+	colStat.DistinctCount = min(colStat.DistinctCount, histogram.DistinctCount())
+	return true
+}
+
+// tableHasHistogram returns true if the given column belongs to a table that
+// has a histogram on the column.
+func (sb *statisticsBuilder) tableHasHistogram(col opt.ColumnID, md *opt.Metadata) bool {
+	tabID := md.ColumnTableID(col)
+	if tabID == 0 {
+		return false
+	}
+	colStat, ok := sb.makeTableStatistics(tabID, md).ColStats[col]
+	return ok && colStat.Histogram != nil
+}
+
 func (sb *statisticsBuilder) applyEquivalencies(
 	equivReps opt.ColSet, filterFD *props.FuncDepSet, ev ExprView, relProps *props.Relational,
 ) {
@@ -1554,14 +1874,17 @@ func (sb *statisticsBuilder) updateDistinctCountsFromEquivalency(
 ) {
 	s := &relProps.Stats
 
+	equivGroup.ForEach(func(i int) {
+		if _, ok := s.ColStats[opt.ColumnID(i)]; !ok {
+			sb.copyColStatFromChild(util.MakeFastIntSet(i), ev, relProps)
+		}
+	})
+	sb.applyEquivalencyToHistograms(equivGroup, s)
+
 	// Find the minimum distinct count for all columns in this equivalency group.
 	minDistinctCount := math.MaxFloat64
 	equivGroup.ForEach(func(i int) {
-		col := opt.ColumnID(i)
-		colStat, ok := s.ColStats[col]
-		if !ok {
-			colStat = sb.copyColStatFromChild(util.MakeFastIntSet(i), ev, relProps)
-		}
+		colStat := s.ColStats[opt.ColumnID(i)]
 		if colStat.DistinctCount < minDistinctCount {
 			minDistinctCount = colStat.DistinctCount
 		}
@@ -1575,6 +1898,69 @@ func (sb *statisticsBuilder) updateDistinctCountsFromEquivalency(
 	})
 }
 
+// applyEquivalencyToHistograms filters the histograms of the columns in the
+// given equivalency group, such as the columns of an equality join condition.
+// A row can only satisfy the equalities if its value is in the range of
+// values of every column, so each histogram is restricted to the
+// intersection of the value ranges of the histograms in the group. The
+// histograms are discarded if the columns don't have the same type or if
+// their ranges don't intersect, since the histograms can't describe the
+// result in that case.
+func (sb *statisticsBuilder) applyEquivalencyToHistograms(
+	equivGroup opt.ColSet, s *props.Statistics,
+) {
+	var lo, hi tree.Datum
+	var typ types.T
+	ok, numHistograms := true, 0
+	equivGroup.ForEach(func(i int) {
+		colStat := s.ColStats[opt.ColumnID(i)]
+		if !ok || colStat.Histogram == nil {
+			return
+		}
+		colLo, colHi, nonEmpty := colStat.Histogram.ValueRange()
+		if !nonEmpty {
+			ok = false
+			return
+		}
+		if typ == nil {
+			typ = colHi.ResolvedType()
+		} else if !typ.Equivalent(colHi.ResolvedType()) {
+			ok = false
+			return
+		}
+		numHistograms++
+		if colLo != nil && (lo == nil || colLo.Compare(sb.evalCtx, lo) > 0) {
+			lo = colLo
+		}
+		if hi == nil || colHi.Compare(sb.evalCtx, hi) < 0 {
+			hi = colHi
+		}
+	})
+	if numHistograms < 2 {
+		// A single histogram already describes the range of values that can
+		// satisfy the equalities.
+		return
+	}
+	if lo != nil && lo.Compare(sb.evalCtx, hi) > 0 {
+		ok = false
+	}
+
+	equivGroup.ForEach(func(i int) {
+		colStat := s.ColStats[opt.ColumnID(i)]
+		if colStat.Histogram == nil {
+			return
+		}
+		if !ok {
+			colStat.Histogram = nil
+			colStat.HistogramFiltered = false
+			return
+		}
+		colStat.Histogram = colStat.Histogram.FilterRange(lo, hi)
+		colStat.HistogramFiltered = true
+		colStat.DistinctCount = min(colStat.DistinctCount, colStat.Histogram.DistinctCount())
+	})
+}
+
 // selectivityFromDistinctCounts calculates the selectivity of a filter by
 // taking the product of selectivities of each constrained column. In the general case,
 // this can be represented by the formula:
@@ -1591,8 +1977,10 @@ func (sb *statisticsBuilder) updateDistinctCountsFromEquivalency(
 //
 // If some of the columns are equivalent, this algorithm only uses one column
 // from each equivalency group, and chooses the most selective column
-// (i.e., the one with lowest selectivity). Otherwise, this algorithm assumes
-// the columns are completely independent.
+// (i.e., the one with lowest selectivity). If a table has a multi-column
+// statistic on some of the columns, those columns are considered together
+// (see selectivityFromMultiColDistinctCount). Otherwise, this algorithm
+// assumes the columns are completely independent.
 //
 func (sb *statisticsBuilder) selectivityFromDistinctCounts(
 	cols opt.ColSet, ev ExprView, relProps *props.Relational,
@@ -1602,6 +1990,18 @@ func (sb *statisticsBuilder) selectivityFromDistinctCounts(
 	var seen opt.ColSet
 
 	selectivity = 1.0
+	for _, statCols := range sb.multiColStatCols(cols, ev.Metadata()) {
+		if statCols.Intersects(seen) {
+			continue
+		}
+		localSelectivity, ok := sb.selectivityFromMultiColDistinctCount(statCols, ev, relProps)
+		if !ok {
+			continue
+		}
+		seen.UnionWith(fd.ComputeEquivClosure(statCols))
+		selectivity *= localSelectivity
+	}
+
 	for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
 		if seen.Contains(col) {
 			// If an equivalent column was already included in the selectivity
@@ -1635,12 +2035,117 @@ func (sb *statisticsBuilder) selectivityFromDistinctCount(
 	colStat *props.ColumnStatistic, ev ExprView, relProps *props.Relational,
 ) float64 {
 	inputStat := sb.colStatFromChild(colStat.Cols, ev, relProps)
+	if colStat.HistogramFiltered && inputStat.Histogram != nil {
+		// The histogram was filtered, so use it to estimate the fraction of rows
+		// that satisfy the filter.
+		inputRowCount := inputStat.Histogram.RowCount()
+		if inputRowCount != 0 {
+			return min(colStat.Histogram.RowCount()/inputRowCount, 1.0)
+		}
+		return 1.0
+	}
 	if inputStat.DistinctCount != 0 && colStat.DistinctCount < inputStat.DistinctCount {
 		return colStat.DistinctCount / inputStat.DistinctCount
 	}
 	return 1.0
 }
 
+// multiColStatCols returns the column sets of the multi-column table
+// statistics that only contain columns in the given set, with the largest
+// column sets first.
+func (sb *statisticsBuilder) multiColStatCols(cols opt.ColSet, md *opt.Metadata) []opt.ColSet {
+	if cols.Len() < 2 {
+		return nil
+	}
+	var tables util.FastIntSet
+	cols.ForEach(func(i int) {
+		if tabID := md.ColumnTableID(opt.ColumnID(i)); tabID != 0 {
+			tables.Add(int(tabID))
+		}
+	})
+
+	var res []opt.ColSet
+	tables.ForEach(func(i int) {
+		tabID := opt.TableID(i)
+		tab := md.Table(tabID)
+		for j := 0; j < tab.StatisticCount(); j++ {
+			stat := tab.Statistic(j)
+			if stat.ColumnCount() < 2 {
+				continue
+			}
+			statCols := sb.colSetFromTableStatistic(stat, tabID, md)
+			if !statCols.SubsetOf(cols) {
+				continue
+			}
+			dup := false
+			for k := range res {
+				if res[k].Equals(statCols) {
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				res = append(res, statCols)
+			}
+		}
+	})
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Len() > res[j].Len() })
+	return res
+}
+
+// selectivityFromMultiColDistinctCount calculates the selectivity of the
+// filter on a set of columns that have a multi-column statistic. Columns such
+// as (country, city) are often correlated, in which case the product of the
+// selectivities of the individual columns is a drastic underestimate. Instead,
+// the selectivity is estimated as:
+//
+//                   ┬-┬ new distinct(i)
+//                   │ │
+//                  i in cols
+//   selectivity = -----------------------
+//                   old distinct(cols)
+//
+// where old distinct(cols) is the distinct count of the column set in the
+// input. The result is bounded below by the product of the single-column
+// selectivities (i.e., assuming the columns are independent), and above by
+// the selectivity of the most selective column.
+//
+// It returns ok=false if the selectivity cannot be calculated this way, for
+// example because one of the columns isn't constrained.
+func (sb *statisticsBuilder) selectivityFromMultiColDistinctCount(
+	cols opt.ColSet, ev ExprView, relProps *props.Relational,
+) (selectivity float64, ok bool) {
+	s := &relProps.Stats
+	inputStat := sb.colStatFromChild(cols, ev, relProps)
+	if !inputStat.Cols.Equals(cols) || inputStat.DistinctCount == 0 {
+		return 0, false
+	}
+
+	newDistinctCount := 1.0
+	independentSelectivity := 1.0
+	maxSelectivity := 1.0
+	for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
+		colStat, ok := s.ColStats[opt.ColumnID(col)]
+		if !ok {
+			return 0, false
+		}
+		newDistinctCount *= colStat.DistinctCount
+		independentSelectivity *= sb.selectivityFromDistinctCount(colStat, ev, relProps)
+
+		// The distinct count of a column can't be larger than the distinct count
+		// of the column set. It may be if there is no statistic on the column,
+		// in which case it is only a rough estimate.
+		inputColStat := sb.colStatFromChild(util.MakeFastIntSet(col), ev, relProps)
+		inputDistinctCount := min(inputColStat.DistinctCount, inputStat.DistinctCount)
+		if inputDistinctCount != 0 {
+			maxSelectivity = min(maxSelectivity, colStat.DistinctCount/inputDistinctCount)
+		}
+	}
+
+	selectivity = min(newDistinctCount/inputStat.DistinctCount, maxSelectivity)
+	return max(selectivity, independentSelectivity), true
+}
+
 func (sb *statisticsBuilder) selectivityFromEquivalencies(
 	equivReps opt.ColSet, filterFD *props.FuncDepSet, ev ExprView, relProps *props.Relational,
 ) (selectivity float64) {
@@ -1681,9 +2186,15 @@ func (sb *statisticsBuilder) applySelectivityToColStat(
 ) {
 	if selectivity == 0 || colStat.DistinctCount == 0 {
 		colStat.DistinctCount = 0
+		colStat.Histogram = nil
+		colStat.HistogramFiltered = false
 		return
 	}
 
+	if colStat.Histogram != nil {
+		colStat.Histogram = colStat.Histogram.ApplySelectivity(selectivity)
+	}
+
 	n := inputRows
 	d := colStat.DistinctCount
 
@@ -1721,6 +2232,8 @@ func (sb *statisticsBuilder) updateStatsFromContradiction(s *props.Statistics) {
 	s.RowCount = 0
 	for i := range s.ColStats {
 		s.ColStats[i].DistinctCount = 0
+		s.ColStats[i].Histogram = nil
+		s.ColStats[i].HistogramFiltered = false
 	}
 	for i := range s.MultiColStats {
 		s.MultiColStats[i].DistinctCount = 0
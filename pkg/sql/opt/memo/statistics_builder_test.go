@@ -187,11 +187,13 @@ func TestGetStatsFromConstraint(t *testing.T) {
 		1.0/500,
 	)
 
+	// The selectivity is calculated using the multi-column statistic on
+	// (a,b,c), rather than assuming that the columns are independent.
 	cs123 := constraint.SingleConstraint(&c123)
 	statsFunc(
 		cs123,
-		"[rows=400, distinct(1)=1, distinct(2)=1, distinct(3)=5]",
-		5.0/125000000,
+		"[rows=5050505.05, distinct(1)=1, distinct(2)=1, distinct(3)=5]",
+		5.0/9900,
 	)
 
 	cs32 := constraint.SingleConstraint(&c32)
@@ -211,22 +213,22 @@ func TestGetStatsFromConstraint(t *testing.T) {
 	cs312 := constraint.SingleConstraint(&c312)
 	statsFunc(
 		cs312,
-		"[rows=2240, distinct(1)=2, distinct(2)=7, distinct(3)=2]",
-		28.0/125000000,
+		"[rows=28282828.3, distinct(1)=2, distinct(2)=7, distinct(3)=2]",
+		28.0/9900,
 	)
 
 	cs := cs3.Intersect(&evalCtx, cs123)
 	statsFunc(
 		cs,
-		"[rows=80, distinct(1)=1, distinct(2)=1, distinct(3)=1]",
-		1.0/125000000,
+		"[rows=1010101.01, distinct(1)=1, distinct(2)=1, distinct(3)=1]",
+		1.0/9900,
 	)
 
 	cs = cs32.Intersect(&evalCtx, cs123)
 	statsFunc(
 		cs,
-		"[rows=80, distinct(1)=1, distinct(2)=1, distinct(3)=1]",
-		1.0/125000000,
+		"[rows=1010101.01, distinct(1)=1, distinct(2)=1, distinct(3)=1]",
+		1.0/9900,
 	)
 
 	cs45 := constraint.SingleSpanConstraint(&keyCtx45, &sp45)
@@ -116,10 +116,12 @@ func init() {
 		opt.TupleOp:           typeAsPrivate,
 		opt.ProjectionsOp:     typeAsAny,
 		opt.AggregationsOp:    typeAsAny,
+		opt.WindowsOp:         typeAsAny,
 		opt.MergeOnOp:         typeAsAny,
 		opt.ExistsOp:          typeAsBool,
 		opt.AnyOp:             typeAsBool,
 		opt.FunctionOp:        typeFunction,
+		opt.WindowFunctionOp:  typeFunction,
 		opt.CoalesceOp:        typeCoalesce,
 		opt.CaseOp:            typeCase,
 		opt.WhenOp:            typeWhen,
@@ -128,6 +130,9 @@ func init() {
 		opt.ArrayOp:           typeAsPrivate,
 		opt.ColumnAccessOp:    typeColumnAccess,
 
+		// Inlinable calls to user-defined functions are typed like the call.
+		opt.InlinableFunctionOp: typeAsFirstArg,
+
 		// Override default typeAsAggregate behavior for aggregate functions with
 		// a large number of possible overloads or where ReturnType depends on
 		// argument types.
@@ -77,6 +77,7 @@ func (t TableID) index() int {
 // Currently, the following annotations are in use:
 //   - WeakKeys: weak keys derived from the base table
 //   - Stats: statistics derived from the base table
+//   - PartialIndexPredicates: scalar groups of partial index predicates
 //
 // To add an additional annotation, increase the value of maxTableAnnIDCount and
 // add a call to NewTableAnnID.
@@ -89,7 +90,7 @@ var tableAnnIDCount TableAnnID
 // called. Calling more than this number of times results in a panic. Having
 // a maximum enables a static annotation array to be inlined into the metadata
 // table struct.
-const maxTableAnnIDCount = 2
+const maxTableAnnIDCount = 3
 
 // Metadata assigns unique ids to the columns, tables, and other metadata used
 // within the scope of a particular query. Because it is specific to one query,
@@ -158,6 +159,15 @@ func NewMetadata() *Metadata {
 	return &Metadata{}
 }
 
+// Copy returns a copy of the metadata, to which columns and tables can be
+// added without affecting the original. The table annotations are shared.
+func (md *Metadata) Copy() *Metadata {
+	return &Metadata{
+		cols:   append([]mdColumn(nil), md.cols...),
+		tables: append([]mdTable(nil), md.tables...),
+	}
+}
+
 // AddColumn assigns a new unique id to a column within the query and records
 // its label and type.
 func (md *Metadata) AddColumn(label string, typ types.T) ColumnID {
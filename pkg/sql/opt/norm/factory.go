@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/xfunc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 )
 
@@ -102,7 +103,12 @@ type Factory struct {
 // NewFactory returns a new Factory structure with a new, blank memo structure
 // inside.
 func NewFactory(evalCtx *tree.EvalContext) *Factory {
-	mem := memo.New()
+	return NewFactoryWithMemo(evalCtx, memo.New())
+}
+
+// NewFactoryWithMemo returns a new Factory structure that adds expressions to
+// the given memo, which may already contain a normalized expression tree.
+func NewFactoryWithMemo(evalCtx *tree.EvalContext, mem *memo.Memo) *Factory {
 	f := &Factory{
 		mem:        mem,
 		evalCtx:    evalCtx,
@@ -159,6 +165,76 @@ func (f *Factory) InternList(items []memo.GroupID) memo.ListID {
 	return f.mem.InternList(items)
 }
 
+// AssignPlaceholders replaces the Placeholder operators in the expression tree
+// rooted at the given group with the values bound in the eval context, and
+// returns the root of the resulting tree. Expressions that have a placeholder
+// in their subtree are reconstructed, so that normalization rules that depend
+// on constant values (e.g. constant folding) are applied to them. The original
+// tree is not changed, so it can be reused with different placeholder values.
+func (f *Factory) AssignPlaceholders(root memo.GroupID) (memo.GroupID, error) {
+	if !f.evalCtx.HasPlaceholders() {
+		return root, nil
+	}
+
+	var err error
+	replaced := make(map[memo.GroupID]memo.GroupID)
+	var replace memo.ReplaceChildFunc
+	replace = func(group memo.GroupID) memo.GroupID {
+		if newGroup, ok := replaced[group]; ok {
+			return newGroup
+		}
+		newGroup := group
+		expr := f.mem.NormExpr(group)
+		if expr.Operator() == opt.PlaceholderOp {
+			placeholder := f.mem.LookupPrivate(expr.AsPlaceholder().Value()).(tree.TypedExpr)
+			d, evalErr := placeholder.Eval(f.evalCtx)
+			if evalErr != nil {
+				if err == nil {
+					err = evalErr
+				}
+				return group
+			}
+			newGroup = f.constructDatum(d)
+		} else if expr.ChildCount() > 0 {
+			changed := false
+			operands := expr.ReplaceOperands(f.mem, func(child memo.GroupID) memo.GroupID {
+				newChild := replace(child)
+				changed = changed || newChild != child
+				return newChild
+			})
+			if changed {
+				newGroup = f.DynamicConstruct(expr.Operator(), operands)
+				if hint := f.mem.JoinHint(group); hint != memo.NoJoinHint {
+					f.mem.SetJoinHint(newGroup, hint)
+				}
+			}
+		}
+		replaced[group] = newGroup
+		return newGroup
+	}
+
+	root = replace(root)
+	if err != nil {
+		return 0, err
+	}
+	return root, nil
+}
+
+// constructDatum constructs the operator that represents the given datum.
+func (f *Factory) constructDatum(d tree.Datum) memo.GroupID {
+	if d == tree.DNull {
+		return f.ConstructNull(f.InternType(types.Unknown))
+	}
+	if boolVal, ok := d.(*tree.DBool); ok {
+		// Map True/False datums to True/False operator.
+		if *boolVal {
+			return f.ConstructTrue()
+		}
+		return f.ConstructFalse()
+	}
+	return f.ConstructConst(f.InternDatum(d))
+}
+
 // onConstruct is called as a final step by each factory construction method,
 // so that any custom manual pattern matching/replacement code can be run.
 func (f *Factory) onConstruct(e memo.Expr) memo.GroupID {
@@ -163,6 +163,69 @@ func TestSimplifyFilters(t *testing.T) {
 	}
 }
 
+// TestAssignPlaceholders tests that Factory.AssignPlaceholders replaces
+// placeholders with their values in a copy of the memo, without changing the
+// original memo.
+func TestAssignPlaceholders(t *testing.T) {
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	f := NewFactory(&evalCtx)
+
+	cat := createFiltersCatalog(t)
+	a := f.Metadata().AddTable(cat.Table("a"))
+	ax := a.ColumnID(0)
+	ay := a.ColumnID(1)
+	aCols := util.MakeFastIntSet(int(ax), int(ay))
+
+	scan := f.ConstructScan(f.InternScanOpDef(&memo.ScanOpDef{Table: a, Cols: aCols}))
+	eq := f.ConstructEq(
+		f.ConstructVariable(f.InternColumnID(ax)),
+		f.ConstructPlaceholder(f.InternTypedExpr(tree.NewPlaceholder("1"))),
+	)
+	root := f.ConstructSelect(scan, f.ConstructFilters(f.InternList([]memo.GroupID{eq})))
+	before := memo.MakeNormExprView(f.Memo(), root).String()
+
+	// Without placeholder values, the tree is unchanged.
+	if actual, err := f.AssignPlaceholders(root); err != nil || actual != root {
+		t.Fatalf("expected unchanged root %d, got %d (err: %v)", root, actual, err)
+	}
+
+	evalCtx.Placeholders = &tree.PlaceholderInfo{
+		Values: tree.QueryArguments{"1": tree.NewDInt(2)},
+		Types:  tree.PlaceholderTypes{"1": types.Int},
+	}
+	f2 := NewFactoryWithMemo(&evalCtx, f.Memo().Copy())
+	assigned, err := f2.AssignPlaceholders(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hasPlaceholder func(ev memo.ExprView) bool
+	hasPlaceholder = func(ev memo.ExprView) bool {
+		if ev.Operator() == opt.PlaceholderOp {
+			return true
+		}
+		for i, n := 0, ev.ChildCount(); i < n; i++ {
+			if hasPlaceholder(ev.Child(i)) {
+				return true
+			}
+		}
+		return false
+	}
+	if ev := memo.MakeNormExprView(f2.Memo(), assigned); hasPlaceholder(ev) {
+		t.Fatalf("expected placeholder to be replaced:\n%s", ev)
+	}
+	if after := memo.MakeNormExprView(f.Memo(), root).String(); after != before {
+		t.Fatalf("expected original memo to be unchanged:\n%s\n%s", before, after)
+	}
+
+	// A placeholder without a value is an error.
+	evalCtx.Placeholders = &tree.PlaceholderInfo{}
+	f3 := NewFactoryWithMemo(&evalCtx, f.Memo().Copy())
+	if _, err := f3.AssignPlaceholders(root); err == nil {
+		t.Fatal("expected error for placeholder without a value")
+	}
+}
+
 func createFiltersCatalog(t *testing.T) *testcat.Catalog {
 	cat := testcat.New()
 	if _, err := cat.ExecuteDDL("CREATE TABLE a (x INT PRIMARY KEY, y INT)"); err != nil {
@@ -311,11 +311,11 @@ func DerivePruneCols(ev memo.ExprView) opt.ColSet {
 		ordering := ev.Private().(*memo.RowNumberDef).Ordering.ColSet()
 		relational.Rule.PruneCols = inputPruneCols.Difference(ordering)
 
-	case opt.IndexJoinOp, opt.LookupJoinOp:
-		// There is no need to prune columns projected by Index or Lookup joins,
-		// since its parent will always be an "alternate" expression in the memo.
-		// Any pruneable columns should have already been pruned at the time the
-		// IndexJoin is constructed. Additionally, there is not currently a
+	case opt.IndexJoinOp, opt.LookupJoinOp, opt.ZigzagJoinOp:
+		// There is no need to prune columns projected by Index, Lookup or Zigzag
+		// joins, since its parent will always be an "alternate" expression in the
+		// memo. Any pruneable columns should have already been pruned at the time
+		// the join is constructed. Additionally, there is not currently a
 		// PruneCols rule for these operators.
 
 	default:
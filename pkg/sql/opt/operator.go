@@ -68,6 +68,9 @@ var ComparisonOpReverseMap = map[Operator]tree.ComparisonOperator{
 	IsOp:             tree.IsNotDistinctFrom,
 	IsNotOp:          tree.IsDistinctFrom,
 	ContainsOp:       tree.Contains,
+	OverlapsOp:       tree.Overlaps,
+	TrigramSimilarOp: tree.TrigramSimilar,
+	TSMatchesOp:      tree.TSMatches,
 	JsonExistsOp:     tree.JSONExists,
 	JsonSomeExistsOp: tree.JSONSomeExists,
 	JsonAllExistsOp:  tree.JSONAllExists,
@@ -62,6 +62,18 @@ type Builder struct {
 	// case.
 	FmtFlags tree.FmtFlags
 
+	// KeepPlaceholders is a control knob: if set, placeholders are built as
+	// Placeholder operators even if their values are available, rather than
+	// being replaced with those values. The resulting plan does not depend on
+	// the placeholder values, so it can be reused for different values.
+	KeepPlaceholders bool
+
+	// BaselineJoinHints, if set, replaces the join hints of the statement with
+	// the hints of a plan baseline: the i-th join that is built is given the
+	// i-th hint, in the order reported by JoinHints. The baseline hints are
+	// ignored if the number of joins in the statement does not match.
+	BaselineJoinHints []memo.JoinHint
+
 	// IsCorrelated is set to true during semantic analysis if a scalar variable was
 	// pulled from an outer scope, that is, if the query was found to be correlated.
 	IsCorrelated bool
@@ -73,6 +85,19 @@ type Builder struct {
 	semaCtx *tree.SemaContext
 	evalCtx *tree.EvalContext
 	catalog opt.Catalog
+
+	// inlineDepth is the number of function bodies currently being inlined.
+	// It bounds the inlining of functions that call each other.
+	inlineDepth int
+
+	// numWiths is the number of CTEs built so far. It is used to assign a
+	// unique ID to each CTE.
+	numWiths int
+
+	// joins contains the inputs and the hint of each join built so far, in
+	// order. The hints are recorded in the memo once the statement is built
+	// (see setJoinHints).
+	joins []builtJoin
 }
 
 // New creates a new Builder structure initialized with the given
@@ -120,10 +145,24 @@ func (b *Builder) Build() (root memo.GroupID, required *props.Physical, err erro
 	}()
 
 	outScope := b.buildStmt(b.stmt, &scope{builder: b})
+	b.setJoinHints(outScope.group)
 	physicalProps := outScope.makePhysicalProps()
 	return outScope.group, &physicalProps, nil
 }
 
+// JoinHints returns the join hints given in the statement, with one entry
+// (possibly memo.NoJoinHint) for each join, in the order in which the joins
+// were built. Since the order only depends on the structure of the
+// statement, these hints can be applied to any statement with the same
+// fingerprint using BaselineJoinHints.
+func (b *Builder) JoinHints() []memo.JoinHint {
+	hints := make([]memo.JoinHint, len(b.joins))
+	for i := range b.joins {
+		hints[i] = b.joins[i].hint
+	}
+	return hints
+}
+
 // builderError is used for semantic errors that occur during the build process
 // and is passed as an argument to panic. These panics are caught and converted
 // back to errors inside Builder.Build.
@@ -157,11 +196,17 @@ func unimplementedf(format string, a ...interface{}) builderError {
 func (b *Builder) buildStmt(stmt tree.Statement, inScope *scope) (outScope *scope) {
 	// NB: The case statements are sorted lexicographically.
 	switch stmt := stmt.(type) {
+	case *tree.Delete:
+		return b.buildDelete(stmt, inScope)
+
+	case *tree.Insert:
+		return b.buildInsert(stmt, inScope)
+
 	case *tree.ParenSelect:
-		return b.buildSelect(stmt.Select, inScope)
+		return b.buildSelect(stmt.Select, nil /* desiredTypes */, inScope)
 
 	case *tree.Select:
-		return b.buildSelect(stmt, inScope)
+		return b.buildSelect(stmt, nil /* desiredTypes */, inScope)
 
 	case *tree.Explain:
 		return b.buildExplain(stmt, inScope)
@@ -169,6 +214,9 @@ func (b *Builder) buildStmt(stmt tree.Statement, inScope *scope) (outScope *scop
 	case *tree.ShowTraceForSession:
 		return b.buildShowTrace(stmt, inScope)
 
+	case *tree.Update:
+		return b.buildUpdate(stmt, inScope)
+
 	default:
 		panic(unimplementedf("unsupported statement: %T", stmt))
 	}
@@ -0,0 +1,56 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// buildDelete builds a memo group for a DeleteOp expression. The input of the
+// mutation scans all the columns of the target table, and filters the rows
+// using the WHERE clause.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope) {
+	// UX friendliness safeguard.
+	if del.Where == nil && b.evalCtx.SessionData.SafeUpdates {
+		panic(builderError{pgerror.NewDangerousStatementErrorf("DELETE without WHERE clause")})
+	}
+	var ctes []*cteSource
+	if del.With != nil {
+		inScope, ctes = b.buildCTEs(del.With, inScope)
+	}
+	if del.OrderBy != nil || del.Limit != nil {
+		panic(unimplementedf("DELETE with ORDER BY or LIMIT is not supported"))
+	}
+
+	tab, tn, alias := b.resolveMutationTable(del.Table, privilege.DELETE)
+	scanScope := b.buildMutationScan(tab, tn, alias, del.Where, inScope)
+
+	def := memo.MutationOpDef{
+		Table:     b.addMutationTable(tab, tn),
+		FetchCols: colsToColList(scanScope.cols),
+	}
+	outScope = b.finishBuildMutation(
+		opt.DeleteOp, scanScope.group, &def, tab, alias, del.Returning, inScope,
+	)
+	b.wrapWithCTEs(outScope, ctes)
+	return outScope
+}
@@ -36,7 +36,9 @@ func (b *Builder) buildExplain(explain *tree.Explain, inScope *scope) (outScope
 	var cols sqlbase.ResultColumns
 	switch opts.Mode {
 	case tree.ExplainPlan:
-		if opts.Flags.Contains(tree.ExplainFlagVerbose) || opts.Flags.Contains(tree.ExplainFlagTypes) {
+		if opts.Flags.Contains(tree.ExplainFlagAnalyze) {
+			cols = sqlbase.ExplainAnalyzeColumns
+		} else if opts.Flags.Contains(tree.ExplainFlagVerbose) || opts.Flags.Contains(tree.ExplainFlagTypes) {
 			cols = sqlbase.ExplainPlanVerboseColumns
 		} else {
 			cols = sqlbase.ExplainPlanColumns
@@ -44,6 +44,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -162,7 +163,7 @@ func (b *Builder) constructGroupBy(
 //  - the output scope for the aggregation operation.
 //  - the output scope the post-projections.
 func (b *Builder) buildAggregation(
-	sel *tree.SelectClause, orderBy tree.OrderBy, fromScope *scope,
+	sel *tree.SelectClause, orderBy tree.OrderBy, desiredTypes []types.T, fromScope *scope,
 ) (outScope *scope, projectionsScope *scope) {
 	// We use two scopes:
 	//   - aggInScope contains columns that are used as input by the
@@ -217,7 +218,7 @@ func (b *Builder) buildAggregation(
 	// This is where the magic happens. When this call reaches an aggregate
 	// function that refers to variables in fromScope, buildAggregateFunction is
 	// called which adds columns to the aggInScope and aggOutScope.
-	b.buildProjectionList(sel.Exprs, fromScope, projectionsScope)
+	b.buildProjectionList(sel.Exprs, desiredTypes, fromScope, projectionsScope)
 
 	// Any additional columns or aggregates in the ORDER BY and DISTINCT ON
 	// clauses (if they exist) will be added here.
@@ -302,6 +303,9 @@ func (b *Builder) buildHaving(having tree.Expr, inScope *scope) memo.GroupID {
 func (b *Builder) buildGroupingList(
 	groupBy tree.GroupBy, selects tree.SelectExprs, inScope *scope, outScope *scope,
 ) {
+	if groupBy.HasGroupingSets() {
+		panic(unimplementedf("GROUPING SETS, ROLLUP and CUBE are not supported yet"))
+	}
 	inScope.groupby.groupings = make([]memo.GroupID, 0, len(groupBy))
 	inScope.groupby.groupStrs = make(groupByStrSet, len(groupBy))
 	for _, e := range groupBy {
@@ -370,6 +374,9 @@ func (b *Builder) buildGrouping(
 func (b *Builder) buildAggregateFunction(
 	f *tree.FuncExpr, funcDef memo.FuncOpDef, label string, inScope, outScope *scope,
 ) (out memo.GroupID) {
+	if funcDef.Name == builtins.Grouping {
+		panic(unimplementedf("GROUPING is not supported yet"))
+	}
 	if len(f.Exprs) > 1 {
 		// TODO: #10495
 		panic(builderError{pgerror.UnimplementedWithIssueError(
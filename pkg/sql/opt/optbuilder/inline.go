@@ -0,0 +1,124 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// maxInlineDepth bounds the nesting of inlined function bodies.
+const maxInlineDepth = 32
+
+// buildInlinedBody builds the body of a user-defined function that can be
+// inlined, with the references to the arguments replaced by the already built
+// argument expressions. It returns false if the call cannot be inlined, in
+// which case the function is evaluated as a regular call.
+func (b *Builder) buildInlinedBody(
+	f *tree.FuncExpr, body tree.Expr, argList []memo.GroupID, inScope *scope,
+) (_ memo.GroupID, ok bool) {
+	if b.inlineDepth >= maxInlineDepth {
+		return 0, false
+	}
+
+	args := make([]inlinedArg, len(argList))
+	refs := make([]int, len(argList))
+	for i := range args {
+		args[i] = inlinedArg{expr: f.Exprs[i].(tree.TypedExpr), group: argList[i]}
+	}
+	replaced, err := tree.SimpleVisit(body, func(expr tree.Expr) (error, bool, tree.Expr) {
+		if p, ok := expr.(*tree.Placeholder); ok {
+			idx, err := strconv.Atoi(p.Name)
+			if err != nil || idx < 1 || idx > len(args) {
+				return fmt.Errorf("invalid argument reference $%s", p.Name), false, expr
+			}
+			refs[idx-1]++
+			return nil, false, &args[idx-1]
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return 0, false
+	}
+
+	// An argument that is referenced several times would be evaluated once per
+	// reference, so only inline it if that is cheap and does not change the
+	// result.
+	for i, n := range refs {
+		if n > 1 && !isSimpleArg(args[i].expr) {
+			return 0, false
+		}
+	}
+
+	b.inlineDepth++
+	defer func() { b.inlineDepth-- }()
+	texpr := inScope.resolveAndRequireType(replaced, f.ResolvedType(), "function body")
+	return b.buildScalar(texpr, inScope), true
+}
+
+// isSimpleArg returns true if the given argument expression is a constant or
+// a column reference.
+func isSimpleArg(expr tree.TypedExpr) bool {
+	switch expr.(type) {
+	case tree.Datum, *tree.Placeholder, *scopeColumn:
+		return true
+	}
+	return false
+}
+
+// inlinedArg replaces a reference to an argument in the body of an inlined
+// function. It refers to the argument expression, which has already been built
+// as part of the call.
+type inlinedArg struct {
+	expr  tree.TypedExpr
+	group memo.GroupID
+}
+
+var _ tree.TypedExpr = &inlinedArg{}
+
+func (a *inlinedArg) String() string {
+	return tree.AsString(a)
+}
+
+// Format implements the NodeFormatter interface.
+func (a *inlinedArg) Format(ctx *tree.FmtCtx) {
+	ctx.WriteByte('(')
+	ctx.FormatNode(a.expr)
+	ctx.WriteByte(')')
+}
+
+// Walk is part of the tree.Expr interface.
+func (a *inlinedArg) Walk(v tree.Visitor) tree.Expr {
+	return a
+}
+
+// TypeCheck is part of the tree.Expr interface.
+func (a *inlinedArg) TypeCheck(_ *tree.SemaContext, desired types.T) (tree.TypedExpr, error) {
+	return a, nil
+}
+
+// ResolvedType is part of the tree.TypedExpr interface.
+func (a *inlinedArg) ResolvedType() types.T {
+	return a.expr.ResolvedType()
+}
+
+// Eval is part of the tree.TypedExpr interface.
+func (*inlinedArg) Eval(_ *tree.EvalContext) (tree.Datum, error) {
+	panic(fmt.Errorf("inlinedArg must be replaced before evaluation"))
+}
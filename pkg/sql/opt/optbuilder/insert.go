@@ -0,0 +1,191 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// buildInsert builds a memo group for an InsertOp or UpsertOp expression
+// (the latter if the statement has an ON CONFLICT clause, or is an UPSERT
+// statement). The input of the mutation is built from the source rows of the
+// statement, and provides one column for each target column. Values for the
+// remaining columns of the table (default and computed values) are filled in
+// by the execution engine.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope) {
+	var ctes []*cteSource
+	if ins.With != nil {
+		inScope, ctes = b.buildCTEs(ins.With, inScope)
+	}
+
+	tab, tn, alias := b.resolveMutationTable(ins.Table, privilege.INSERT)
+	op := opt.InsertOp
+	if ins.OnConflict != nil {
+		op = opt.UpsertOp
+		if !ins.OnConflict.DoNothing {
+			b.checkPrivilege(tab, privilege.UPDATE)
+		}
+		checkOnConflict(ins.OnConflict)
+	}
+
+	def := memo.MutationOpDef{
+		Table:      b.addMutationTable(tab, tn),
+		InsertCols: make(opt.ColList, tab.ColumnCount()),
+		OnConflict: ins.OnConflict,
+	}
+
+	var inputScope *scope
+	if ins.DefaultValues() {
+		// All the columns are set to their default values, so the input is a
+		// single row with no columns.
+		rows := []memo.GroupID{b.factory.ConstructTuple(
+			b.factory.InternList(nil), b.factory.InternType(memo.EmptyTupleType),
+		)}
+		inputScope = inScope.push()
+		inputScope.group = b.factory.ConstructValues(
+			b.factory.InternList(rows),
+			b.factory.InternColList(opt.ColList{}),
+		)
+	} else {
+		// Determine which columns we're inserting into. If no target columns
+		// were specified, the source provides values for a prefix of the
+		// visible columns of the table.
+		var targetOrds []int
+		if ins.Columns != nil {
+			targetOrds = resolveMutationCols(tab, ins.Columns)
+		} else {
+			for ord, n := 0, tab.ColumnCount(); ord < n; ord++ {
+				if !tab.Column(ord).IsHidden() {
+					targetOrds = append(targetOrds, ord)
+				}
+			}
+		}
+
+		// The types of the target columns are used to type the source
+		// expressions (e.g. constants and placeholders).
+		desiredTypes := make([]types.T, len(targetOrds))
+		for i, ord := range targetOrds {
+			desiredTypes[i] = tab.Column(ord).DatumType()
+		}
+
+		checkNoDefaultVals(ins.Rows)
+		inputScope = b.buildSelect(ins.Rows, desiredTypes, inScope)
+
+		var inputCols []scopeColumn
+		for i := range inputScope.cols {
+			if !inputScope.cols[i].hidden {
+				inputCols = append(inputCols, inputScope.cols[i])
+			}
+		}
+		checkNumExprs(len(inputCols), len(targetOrds), ins.Columns != nil)
+
+		for i := range inputCols {
+			ord := targetOrds[i]
+			checkMutationCol(tab.Column(ord), inputCols[i].typ)
+			def.InsertCols[ord] = inputCols[i].id
+		}
+	}
+
+	outScope = b.finishBuildMutation(
+		op, inputScope.group, &def, tab, alias, ins.Returning, inScope,
+	)
+	b.wrapWithCTEs(outScope, ctes)
+	return outScope
+}
+
+// checkNumExprs ensures that the number of source expressions of an INSERT
+// statement matches the number of target columns. It is ok to be missing
+// expressions if no target columns were specified, because the missing
+// columns are filled in with their default values.
+func checkNumExprs(numExprs, numCols int, specifiedTargets bool) {
+	extraExprs := numExprs > numCols
+	missingExprs := specifiedTargets && numExprs < numCols
+	if extraExprs || missingExprs {
+		more, less := "expressions", "target columns"
+		if missingExprs {
+			more, less = less, more
+		}
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"INSERT has more %s than %s, %d expressions for %d targets",
+			more, less, numExprs, numCols,
+		)})
+	}
+}
+
+// checkNoDefaultVals ensures that a VALUES clause used as the source of an
+// INSERT statement does not contain DEFAULT expressions, which are not yet
+// supported by the optimizer.
+func checkNoDefaultVals(rows *tree.Select) {
+	stmt := rows.Select
+	for s, ok := stmt.(*tree.ParenSelect); ok; s, ok = stmt.(*tree.ParenSelect) {
+		stmt = s.Select.Select
+	}
+	values, ok := stmt.(*tree.ValuesClause)
+	if !ok {
+		return
+	}
+	for _, tuple := range values.Rows {
+		for _, expr := range tuple {
+			if _, ok := expr.(tree.DefaultVal); ok {
+				panic(unimplementedf("DEFAULT expressions in VALUES are not supported"))
+			}
+		}
+	}
+}
+
+// checkOnConflict ensures that the expressions of an ON CONFLICT clause can
+// be evaluated by the execution engine. These expressions are not built by
+// the optimizer, so they cannot contain subqueries, nor placeholders (whose
+// types would not be inferred).
+func checkOnConflict(onConflict *tree.OnConflict) {
+	var v unsupportedExprFinder
+	for _, expr := range onConflict.Exprs {
+		tree.WalkExprConst(&v, expr.Expr)
+	}
+	if onConflict.Where != nil {
+		tree.WalkExprConst(&v, onConflict.Where.Expr)
+	}
+	if v.found {
+		panic(unimplementedf("subqueries and placeholders in ON CONFLICT are not supported"))
+	}
+}
+
+// unsupportedExprFinder is a tree.Visitor that finds subqueries and
+// placeholders.
+type unsupportedExprFinder struct {
+	found bool
+}
+
+var _ tree.Visitor = &unsupportedExprFinder{}
+
+// VisitPre is part of the tree.Visitor interface.
+func (v *unsupportedExprFinder) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch expr.(type) {
+	case *tree.Subquery, *tree.Placeholder:
+		v.found = true
+	}
+	return !v.found, expr
+}
+
+// VisitPost is part of the tree.Visitor interface.
+func (*unsupportedExprFinder) VisitPost(expr tree.Expr) tree.Expr { return expr }
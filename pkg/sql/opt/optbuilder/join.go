@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
 )
 
 // buildJoin builds a set of memo groups that represent the given join table
@@ -42,6 +43,11 @@ func (b *Builder) buildJoin(join *tree.JoinTableExpr, inScope *scope) (outScope
 	b.validateJoinTableNames(leftTables, rightScope)
 
 	joinType := sqlbase.JoinTypeFromAstString(join.Join)
+	hint := joinHintFromAst(join.Hint)
+	if hint == memo.LookupJoinHint && joinType != sqlbase.InnerJoin && joinType != sqlbase.LeftOuterJoin {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"%s hint is only supported for inner and left joins", join.Hint)})
+	}
 
 	switch cond := join.Cond.(type) {
 	case tree.NaturalJoinCond, *tree.UsingJoinCond:
@@ -54,7 +60,7 @@ func (b *Builder) buildJoin(join *tree.JoinTableExpr, inScope *scope) (outScope
 			usingColNames = t.Cols
 		}
 
-		return b.buildUsingJoin(joinType, usingColNames, leftScope, rightScope, inScope)
+		return b.buildUsingJoin(joinType, hint, usingColNames, leftScope, rightScope, inScope)
 
 	case *tree.OnJoinCond, nil:
 		// Append columns added by the children, as they are visible to the filter.
@@ -69,7 +75,7 @@ func (b *Builder) buildJoin(join *tree.JoinTableExpr, inScope *scope) (outScope
 			filter = b.factory.ConstructTrue()
 		}
 
-		outScope.group = b.constructJoin(joinType, leftScope.group, rightScope.group, filter)
+		outScope.group = b.constructJoin(joinType, hint, leftScope.group, rightScope.group, filter)
 		return outScope
 
 	default:
@@ -129,6 +135,7 @@ func commonColumns(leftScope, rightScope *scope) (common tree.NameList) {
 // USING and NATURAL joins.
 //
 // joinType    The join type (inner, left, right or outer)
+// hint        The join hint, if any
 // names       The list of `USING` column names
 // leftScope   The outScope from the left table
 // rightScope  The outScope from the right table
@@ -136,14 +143,17 @@ func commonColumns(leftScope, rightScope *scope) (common tree.NameList) {
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
 func (b *Builder) buildUsingJoin(
-	joinType sqlbase.JoinType, names tree.NameList, leftScope, rightScope, inScope *scope,
+	joinType sqlbase.JoinType,
+	hint memo.JoinHint,
+	names tree.NameList,
+	leftScope, rightScope, inScope *scope,
 ) (outScope *scope) {
 	// Build the join predicate.
 	mergedCols, filter, outScope := b.buildUsingJoinPredicate(
 		joinType, leftScope.cols, rightScope.cols, names, inScope,
 	)
 
-	outScope.group = b.constructJoin(joinType, leftScope.group, rightScope.group, filter)
+	outScope.group = b.constructJoin(joinType, hint, leftScope.group, rightScope.group, filter)
 
 	if len(mergedCols) > 0 {
 		// Wrap in a projection to include the merged columns and ensure that all
@@ -336,23 +346,130 @@ func (b *Builder) constructFilter(conditions []memo.GroupID) memo.GroupID {
 	}
 }
 
+// constructJoin constructs a join of the given type, and records the join and
+// its hint (see Builder.JoinHints).
 func (b *Builder) constructJoin(
-	joinType sqlbase.JoinType, left, right, filter memo.GroupID,
+	joinType sqlbase.JoinType, hint memo.JoinHint, left, right, filter memo.GroupID,
 ) memo.GroupID {
 	// Wrap the ON condition in a FiltersOp.
 	filter = b.factory.ConstructFilters(b.factory.InternList([]memo.GroupID{filter}))
+	var group memo.GroupID
 	switch joinType {
 	case sqlbase.InnerJoin:
-		return b.factory.ConstructInnerJoin(left, right, filter)
+		group = b.factory.ConstructInnerJoin(left, right, filter)
 	case sqlbase.LeftOuterJoin:
-		return b.factory.ConstructLeftJoin(left, right, filter)
+		group = b.factory.ConstructLeftJoin(left, right, filter)
 	case sqlbase.RightOuterJoin:
-		return b.factory.ConstructRightJoin(left, right, filter)
+		group = b.factory.ConstructRightJoin(left, right, filter)
 	case sqlbase.FullOuterJoin:
-		return b.factory.ConstructFullJoin(left, right, filter)
+		group = b.factory.ConstructFullJoin(left, right, filter)
 	default:
 		panic(fmt.Errorf("unsupported JOIN type %d", joinType))
 	}
+	b.joins = append(b.joins, builtJoin{left: left, right: right, hint: hint})
+	return group
+}
+
+// builtJoin is a join built by the builder.
+type builtJoin struct {
+	left, right memo.GroupID
+	hint        memo.JoinHint
+}
+
+// setJoinHints records the hints of the joins that were built in the memo,
+// where the optimizer enforces them. The hints are given by the statement or
+// by the plan baseline (see Builder.BaselineJoinHints).
+//
+// Normalization rules can replace a join with a new one (e.g. when columns
+// are pruned from its inputs), so the hints can't be recorded when the joins
+// are built. Instead, the normalized expression tree is searched for joins
+// between the same tables as the hinted joins.
+func (b *Builder) setJoinHints(root memo.GroupID) {
+	hints := b.JoinHints()
+	if b.BaselineJoinHints != nil && len(b.BaselineJoinHints) == len(hints) {
+		hints = b.BaselineJoinHints
+	}
+	var hinted []joinTables
+	tables := make(map[memo.GroupID]util.FastIntSet)
+	for i := range b.joins {
+		if hints[i] != memo.NoJoinHint {
+			hinted = append(hinted, joinTables{
+				left:  b.joinInputTables(b.joins[i].left, tables),
+				right: b.joinInputTables(b.joins[i].right, tables),
+				hint:  hints[i],
+			})
+		}
+	}
+	if len(hinted) > 0 {
+		b.setJoinHintsRec(memo.MakeNormExprView(b.factory.Memo(), root), hinted, tables)
+	}
+}
+
+// joinTables identifies a join by the tables referenced by its inputs.
+type joinTables struct {
+	left, right util.FastIntSet
+	hint        memo.JoinHint
+}
+
+func (b *Builder) setJoinHintsRec(
+	ev memo.ExprView, hinted []joinTables, tables map[memo.GroupID]util.FastIntSet,
+) {
+	switch ev.Operator() {
+	case opt.InnerJoinOp, opt.LeftJoinOp, opt.RightJoinOp, opt.FullJoinOp,
+		opt.SemiJoinOp, opt.AntiJoinOp:
+		left := b.joinInputTables(ev.ChildGroup(0), tables)
+		right := b.joinInputTables(ev.ChildGroup(1), tables)
+		for i := range hinted {
+			h := &hinted[i]
+			if (left.Equals(h.left) && right.Equals(h.right)) ||
+				(left.Equals(h.right) && right.Equals(h.left)) {
+				b.factory.Memo().SetJoinHint(ev.Group(), h.hint)
+				break
+			}
+		}
+	}
+	for i, n := 0, ev.ChildCount(); i < n; i++ {
+		b.setJoinHintsRec(ev.Child(i), hinted, tables)
+	}
+}
+
+// joinInputTables returns the set of tables scanned by the normalized
+// expression tree of the given group.
+func (b *Builder) joinInputTables(
+	group memo.GroupID, tables map[memo.GroupID]util.FastIntSet,
+) util.FastIntSet {
+	if res, ok := tables[group]; ok {
+		return res
+	}
+	var res util.FastIntSet
+	ev := memo.MakeNormExprView(b.factory.Memo(), group)
+	switch ev.Operator() {
+	case opt.ScanOp:
+		res.Add(int(ev.Private().(*memo.ScanOpDef).Table))
+	case opt.VirtualScanOp:
+		res.Add(int(ev.Private().(*memo.VirtualScanOpDef).Table))
+	}
+	for i, n := 0, ev.ChildCount(); i < n; i++ {
+		res.UnionWith(b.joinInputTables(ev.ChildGroup(i), tables))
+	}
+	tables[group] = res
+	return res
+}
+
+// joinHintFromAst returns the join hint for the given tree.JoinTableExpr hint.
+func joinHintFromAst(hint string) memo.JoinHint {
+	switch hint {
+	case "":
+		return memo.NoJoinHint
+	case tree.AstHash:
+		return memo.HashJoinHint
+	case tree.AstMerge:
+		return memo.MergeJoinHint
+	case tree.AstLookup:
+		return memo.LookupJoinHint
+	default:
+		panic(fmt.Errorf("unsupported join hint %s", hint))
+	}
 }
 
 // findUsingColumn finds the column in cols that has the given name. If the
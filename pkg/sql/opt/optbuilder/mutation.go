@@ -0,0 +1,271 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// MutationSchemaChangeFeature identifies the unimplemented error that is
+// returned when the target of a mutation is undergoing a schema change. Such
+// mutations are always planned by the heuristic planner.
+const MutationSchemaChangeFeature = "opt-mutation-schema-change"
+
+// resolveMutationTable returns the target table of an INSERT, UPSERT, UPDATE
+// or DELETE statement, after checking that the current user has the given
+// privilege on it. It also returns the name of the table, and the alias by
+// which the table is referenced in the rest of the statement (which is the
+// same as the table name if the statement does not specify an alias).
+func (b *Builder) resolveMutationTable(
+	texpr tree.TableExpr, priv privilege.Kind,
+) (tab opt.Table, tn, alias *tree.TableName) {
+	if ate, ok := texpr.(*tree.AliasedTableExpr); ok {
+		texpr = ate.Expr
+		// It's okay to ignore the As columns here, as they're not permitted in
+		// DML aliases.
+		alias = tree.NewUnqualifiedTableName(ate.As.Alias)
+	}
+
+	ntn, ok := texpr.(*tree.NormalizableTableName)
+	if !ok {
+		panic(unimplementedf("unsupported mutation target: %s", texpr))
+	}
+	tn, err := ntn.Normalize()
+	if err != nil {
+		panic(builderError{err})
+	}
+	if alias == nil {
+		alias = tn
+	}
+
+	tab = b.resolveTable(tn, priv)
+	if tab.IsVirtualTable() {
+		panic(unimplementedf("mutations of virtual tables are not supported"))
+	}
+	if tab.HasPendingMutations() {
+		// The optimizer only knows about public columns, so it cannot provide
+		// values for the columns that are being added or dropped. Fail with an
+		// unimplemented error so that the heuristic planner is used instead.
+		panic(builderError{pgerror.Unimplemented(MutationSchemaChangeFeature,
+			"mutations of tables undergoing schema changes are not supported")})
+	}
+	return tab, tn, alias
+}
+
+// addMutationTable adds a new instance of the target table of a mutation to
+// the metadata, and returns its ID. The columns of this instance hold the
+// values returned by the mutation operator (if any).
+func (b *Builder) addMutationTable(tab opt.Table, tn *tree.TableName) opt.TableID {
+	tabName := tree.AsStringWithFlags(tn, b.FmtFlags)
+	return b.factory.Metadata().AddTableWithName(tab, tabName)
+}
+
+// buildMutationScan builds a scan of all the columns of the target table of
+// an UPDATE or DELETE statement, and filters it using the WHERE clause (if
+// any). Since the scan reads the table, the SELECT privilege is required in
+// addition to the privilege that is required for the mutation.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildMutationScan(
+	tab opt.Table, tn, alias *tree.TableName, where *tree.Where, inScope *scope,
+) (outScope *scope) {
+	b.checkPrivilege(tab, privilege.SELECT)
+	outScope = b.buildScan(tab, tn, nil /* ordinals */, inScope)
+	if alias != tn {
+		outScope.setTableAlias(alias.TableName)
+	}
+
+	if where != nil {
+		// We need to save and restore the previous value of the field in
+		// semaCtx in case we are recursively called within a subquery
+		// context.
+		defer b.semaCtx.Properties.Restore(b.semaCtx.Properties)
+		b.semaCtx.Properties.Require("WHERE", tree.RejectSpecial)
+
+		texpr := outScope.resolveAndRequireType(where.Expr, types.Bool, "WHERE")
+		filter := b.buildScalar(texpr, outScope)
+		// Wrap the filter in a FiltersOp.
+		filter = b.factory.ConstructFilters(b.factory.InternList([]memo.GroupID{filter}))
+		outScope.group = b.factory.ConstructSelect(outScope.group, filter)
+	}
+	return outScope
+}
+
+// resolveMutationCols returns the ordinals of the target table columns with
+// the given names. It is an error for a name to appear more than once.
+func resolveMutationCols(tab opt.Table, names tree.NameList) []int {
+	ords := make([]int, len(names))
+	var seen util.FastIntSet
+	for i, name := range names {
+		ord := -1
+		for j, n := 0, tab.ColumnCount(); j < n; j++ {
+			if tab.Column(j).ColName() == opt.ColumnName(name) {
+				ord = j
+				break
+			}
+		}
+		if ord == -1 {
+			panic(builderError{sqlbase.NewUndefinedColumnError(string(name))})
+		}
+		if seen.Contains(ord) {
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"multiple assignments to the same column %q", &names[i])})
+		}
+		seen.Add(ord)
+		ords[i] = ord
+	}
+	return ords
+}
+
+// checkMutationCol ensures that the given column of the target table can be
+// assigned a value of the given type.
+func checkMutationCol(col opt.Column, typ types.T) {
+	if col.IsComputed() {
+		panic(builderError{sqlbase.CannotWriteToComputedColError(
+			sqlbase.ColumnDescriptor{Name: string(col.ColName())},
+		)})
+	}
+
+	colTyp := col.DatumType()
+	if typ == types.Unknown || typ.Equivalent(colTyp) {
+		return
+	}
+	var colTypStr interface{} = colTyp
+	if semTyp, err := sqlbase.DatumTypeToColumnSemanticType(colTyp); err == nil {
+		colTypStr = semTyp
+	}
+	panic(builderError{pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+		"value type %s doesn't match type %s of column %q", typ, colTypStr, col.ColName(),
+	)})
+}
+
+// finishBuildMutation constructs the mutation operator with the given input
+// and definition, and then builds the RETURNING clause (if any) on top of it.
+// If the statement has no RETURNING clause, the resulting scope has no
+// columns.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) finishBuildMutation(
+	op opt.Operator,
+	input memo.GroupID,
+	def *memo.MutationOpDef,
+	tab opt.Table,
+	alias *tree.TableName,
+	returning tree.ReturningClause,
+	inScope *scope,
+) (outScope *scope) {
+	exprs, needResults := returning.(*tree.ReturningExprs)
+	if needResults {
+		def.ReturnCols = make(opt.ColList, tab.ColumnCount())
+		for ord := range def.ReturnCols {
+			def.ReturnCols[ord] = def.Table.ColumnID(ord)
+		}
+	}
+
+	private := b.factory.InternMutationOpDef(def)
+	var group memo.GroupID
+	switch op {
+	case opt.InsertOp:
+		group = b.factory.ConstructInsert(input, private)
+	case opt.UpdateOp:
+		group = b.factory.ConstructUpdate(input, private)
+	case opt.UpsertOp:
+		group = b.factory.ConstructUpsert(input, private)
+	case opt.DeleteOp:
+		group = b.factory.ConstructDelete(input, private)
+	default:
+		panic(fmt.Sprintf("unexpected mutation operator: %s", op))
+	}
+
+	mutScope := inScope.push()
+	mutScope.group = group
+	if !needResults {
+		return mutScope
+	}
+
+	// The mutation returns all the columns of the table, which are visible to
+	// the RETURNING expressions under the table alias.
+	mutScope.cols = make([]scopeColumn, 0, len(def.ReturnCols))
+	for ord, colID := range def.ReturnCols {
+		col := tab.Column(ord)
+		name := tree.Name(col.ColName())
+		mutScope.cols = append(mutScope.cols, scopeColumn{
+			id:       colID,
+			origName: name,
+			name:     name,
+			table:    *alias,
+			typ:      col.DatumType(),
+			hidden:   col.IsHidden(),
+		})
+	}
+
+	outScope = mutScope.replace()
+	b.buildReturning(*exprs, mutScope, outScope)
+	b.constructProjectForScope(mutScope, outScope)
+	return outScope
+}
+
+// buildReturning builds a set of memo groups that represent the expressions
+// of a RETURNING clause. It is similar to buildProjectionList, except that
+// aggregates, window functions and generators are not allowed.
+//
+// See Builder.buildStmt for a description of the remaining input values.
+func (b *Builder) buildReturning(exprs tree.ReturningExprs, inScope, outScope *scope) {
+	// We need to save and restore the previous value of the field in
+	// semaCtx in case we are recursively called within a subquery
+	// context.
+	defer b.semaCtx.Properties.Restore(b.semaCtx.Properties)
+
+	// Ensure there are no special functions in the RETURNING clause.
+	b.semaCtx.Properties.Require("RETURNING", tree.RejectSpecial)
+
+	for _, e := range exprs {
+		if err := e.NormalizeTopLevelVarName(); err != nil {
+			panic(builderError{err})
+		}
+
+		// Special handling for "*", "<table>.*" and "(Expr).*".
+		if v, ok := e.Expr.(tree.VarName); ok {
+			switch v.(type) {
+			case tree.UnqualifiedStar, *tree.AllColumnsSelector, *tree.TupleStar:
+				if e.As != "" {
+					panic(builderError{pgerror.NewErrorf(pgerror.CodeSyntaxError,
+						"%q cannot be aliased", tree.ErrString(v))})
+				}
+
+				labels, starExprs := b.expandStar(e.Expr, inScope)
+				for i, e := range starExprs {
+					b.buildScalarProjection(e, labels[i], inScope, outScope)
+				}
+				continue
+			}
+		}
+
+		texpr := inScope.resolveType(e.Expr, types.Any)
+		label := b.getColName(e)
+		b.buildScalarProjection(texpr, label, inScope, outScope)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 )
@@ -101,7 +102,7 @@ func (b *Builder) buildOrderByIndex(
 		panic(builderError{err})
 	}
 
-	tab := b.resolveTable(tn)
+	tab := b.resolveTable(tn, privilege.SELECT)
 
 	index, err := b.findIndexByName(tab, order.Index)
 	if err != nil {
@@ -239,7 +240,8 @@ func (b *Builder) buildExtraArgument(
 }
 
 func ensureColumnOrderable(e tree.TypedExpr) {
-	if _, ok := e.ResolvedType().(types.TArray); ok || e.ResolvedType() == types.JSON {
+	typ := e.ResolvedType()
+	if _, ok := typ.(types.TArray); ok || typ == types.JSON || typ == types.TSVector || typ == types.TSQuery {
 		panic(unimplementedf("can't order by column type %s", e.ResolvedType()))
 	}
 }
@@ -70,11 +70,14 @@ func (b *Builder) constructProject(input memo.GroupID, cols []scopeColumn) memo.
 // buildProjectionList builds a set of memo groups that represent the given
 // list of select expressions.
 //
-// See Builder.buildStmt for a description of the remaining input values.
+// See Builder.buildSelect for a description of the desiredTypes parameter, and
+// Builder.buildStmt for a description of the remaining input values.
 //
 // As a side-effect, the appropriate scopes are updated with aggregations
 // (scope.groupby.aggs)
-func (b *Builder) buildProjectionList(selects tree.SelectExprs, inScope *scope, outScope *scope) {
+func (b *Builder) buildProjectionList(
+	selects tree.SelectExprs, desiredTypes []types.T, inScope *scope, outScope *scope,
+) {
 	// We need to save and restore the previous values of the replaceSRFs field
 	// and the field in semaCtx in case we are recursively called within a
 	// subquery context.
@@ -111,7 +114,11 @@ func (b *Builder) buildProjectionList(selects tree.SelectExprs, inScope *scope,
 				}
 			}
 
-			texpr = inScope.resolveType(e.Expr, types.Any)
+			desired := types.Any
+			if i := len(outScope.cols); i < len(desiredTypes) {
+				desired = desiredTypes[i]
+			}
+			texpr = inScope.resolveType(e.Expr, desired)
 		}
 
 		// Output column names should exactly match the original expression, so we
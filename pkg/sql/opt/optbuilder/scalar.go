@@ -66,6 +66,9 @@ var comparisonOpMap = [tree.NumComparisonOperators]binaryFactoryFunc{
 		// This is just syntatic sugar that reverses the operands.
 		return f.ConstructContains(right, left)
 	},
+	tree.Overlaps:       (*norm.Factory).ConstructOverlaps,
+	tree.TrigramSimilar: (*norm.Factory).ConstructTrigramSimilar,
+	tree.TSMatches:      (*norm.Factory).ConstructTSMatches,
 	tree.JSONExists:     (*norm.Factory).ConstructJsonExists,
 	tree.JSONAllExists:  (*norm.Factory).ConstructJsonAllExists,
 	tree.JSONSomeExists: (*norm.Factory).ConstructJsonSomeExists,
@@ -357,7 +360,7 @@ func (b *Builder) buildScalarHelper(
 		return b.buildScalarHelper(t.TypedInnerExpr(), label, inScope, outScope)
 
 	case *tree.Placeholder:
-		if b.evalCtx.HasPlaceholders() {
+		if b.evalCtx.HasPlaceholders() && !b.KeepPlaceholders {
 			// Replace placeholders with their value.
 			d, err := t.Eval(b.evalCtx)
 			if err != nil {
@@ -387,6 +390,9 @@ func (b *Builder) buildScalarHelper(
 	case *subquery:
 		out, _ = b.buildSingleRowSubquery(t, inScope)
 
+	case *inlinedArg:
+		out = t.group
+
 	case *tree.Tuple:
 		list := make([]memo.GroupID, len(t.Exprs))
 		for i := range t.Exprs {
@@ -448,14 +454,11 @@ func (b *Builder) buildDatum(d tree.Datum) memo.GroupID {
 func (b *Builder) buildFunction(
 	f *tree.FuncExpr, label string, inScope, outScope *scope,
 ) (out memo.GroupID) {
-	if f.WindowDef != nil {
-		if inScope.groupby.inAgg {
-			panic(builderError{sqlbase.NewWindowInAggError()})
-		}
-		panic(unimplementedf("window functions are not supported"))
+	if f.WindowDef != nil && inScope.groupby.inAgg {
+		panic(builderError{sqlbase.NewWindowInAggError()})
 	}
 
-	def, err := f.Func.Resolve(b.semaCtx.SearchPath)
+	def, err := b.semaCtx.ResolveFunction(&f.Func)
 	if err != nil {
 		panic(builderError{err})
 	}
@@ -465,6 +468,11 @@ func (b *Builder) buildFunction(
 		Type:       f.ResolvedType(),
 		Properties: &def.FunctionProperties,
 		Overload:   f.ResolvedOverload(),
+		Def:        def,
+	}
+
+	if f.WindowDef != nil {
+		return b.buildWindowFunction(f, funcDef, label, inScope, outScope)
 	}
 
 	if isAggregate(def) {
@@ -481,6 +489,12 @@ func (b *Builder) buildFunction(
 		b.factory.InternList(argList), b.factory.InternFuncOpDef(&funcDef),
 	)
 
+	if body := funcDef.Overload.InlineBody; body != nil {
+		if inlined, ok := b.buildInlinedBody(f, body, argList, inScope); ok {
+			out = b.factory.ConstructInlinableFunction(out, inlined)
+		}
+	}
+
 	if isGenerator(def) {
 		columns := len(def.ReturnLabels)
 		return b.finishBuildGeneratorFunction(f, out, columns, label, inScope, outScope)
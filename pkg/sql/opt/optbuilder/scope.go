@@ -68,6 +68,15 @@ type scope struct {
 	// used by the Builder to convert the input from the FROM clause to a lateral
 	// cross join between the input and a Zip of all the srfs in this slice.
 	srfs []*srf
+
+	// ctes contains the CTEs defined by the WITH clause of the statement built
+	// with this scope, by name (see buildCTEs).
+	ctes map[tree.Name]*cteSource
+
+	// windowing contains information about the window functions encountered in
+	// this scope. It is only set for the scope of a SELECT clause, since window
+	// functions are not allowed elsewhere.
+	windowing *windowing
 }
 
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
@@ -655,7 +664,7 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 		return false, colI.(*scopeColumn)
 
 	case *tree.FuncExpr:
-		def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
+		def, err := s.builder.semaCtx.ResolveFunction(&t.Func)
 		if err != nil {
 			panic(builderError{err})
 		}
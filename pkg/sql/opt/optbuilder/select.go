@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -43,6 +44,9 @@ func (b *Builder) buildTable(texpr tree.TableExpr, inScope *scope) (outScope *sc
 		if source.IndexFlags != nil {
 			panic(unimplementedf("index flags are not supported"))
 		}
+		if source.Sample != nil {
+			panic(unimplementedf("TABLESAMPLE is not supported"))
+		}
 
 		outScope = b.buildTable(source.Expr, inScope)
 
@@ -64,7 +68,11 @@ func (b *Builder) buildTable(texpr tree.TableExpr, inScope *scope) (outScope *sc
 			panic(builderError{err})
 		}
 
-		tab := b.resolveTable(tn)
+		if cte := inScope.resolveCTE(tn); cte != nil {
+			return b.buildCTEScan(cte, tn, inScope)
+		}
+
+		tab := b.resolveTable(tn, privilege.SELECT)
 		return b.buildScan(tab, tn, nil /* ordinals */, inScope)
 
 	case *tree.ParenTableExpr:
@@ -86,10 +94,17 @@ func (b *Builder) buildTable(texpr tree.TableExpr, inScope *scope) (outScope *sc
 
 	case *tree.StatementSource:
 		outScope = b.buildStmt(source.Statement, inScope)
+		if len(outScope.cols) == 0 {
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"statement source \"%v\" does not return any columns", source.Statement)})
+		}
+		// The columns of a statement source are not qualified by a table name,
+		// even if the statement is a mutation that returns columns of a table.
+		outScope.setTableAlias("")
 		return outScope
 
 	case *tree.TableRef:
-		tab := b.resolveTableRef(source)
+		tab := b.resolveTableRef(source, privilege.SELECT)
 		outScope = b.buildScanFromTableRef(tab, source, inScope)
 		b.renameSource(source.As, outScope)
 		return outScope
@@ -228,10 +243,52 @@ func (b *Builder) buildScan(
 	} else {
 		def := memo.ScanOpDef{Table: tabID, Cols: tabColIDs}
 		outScope.group = b.factory.ConstructScan(b.factory.InternScanOpDef(&def))
+		b.buildPartialIndexPredicates(tab, tabID, tn)
 	}
 	return outScope
 }
 
+// buildPartialIndexPredicates builds a Filters group for the predicate of each
+// partial index on the given table, and stores them as an annotation on the
+// table metadata. Exploration rules use them to determine whether a query
+// filter implies the predicate of a partial index, in which case the index can
+// be scanned instead of the table.
+func (b *Builder) buildPartialIndexPredicates(tab opt.Table, tabID opt.TableID, tn *tree.TableName) {
+	var preds memo.PartialIndexPredicates
+	var predScope *scope
+	for i, n := 1, tab.IndexCount(); i < n; i++ {
+		pred := tab.Index(i).Predicate()
+		if pred == nil {
+			continue
+		}
+		if predScope == nil {
+			// The predicate can refer to any column of the table, including the
+			// columns which are not projected by the scan.
+			predScope = &scope{builder: b}
+			predScope.cols = make([]scopeColumn, tab.ColumnCount())
+			for ord := range predScope.cols {
+				col := tab.Column(ord)
+				name := tree.Name(col.ColName())
+				predScope.cols[ord] = scopeColumn{
+					id:       tabID.ColumnID(ord),
+					origName: name,
+					name:     name,
+					table:    *tn,
+					typ:      col.DatumType(),
+					hidden:   col.IsHidden(),
+				}
+			}
+			preds = make(memo.PartialIndexPredicates)
+		}
+		texpr := predScope.resolveAndRequireType(pred, types.Bool, "index predicate")
+		group := b.buildScalar(texpr, predScope)
+		preds[i] = b.factory.ConstructFilters(b.factory.InternList([]memo.GroupID{group}))
+	}
+	if preds != nil {
+		b.factory.Metadata().SetTableAnnotation(tabID, memo.PartialIndexPredicatesAnnID, preds)
+	}
+}
+
 // buildWithOrdinality builds a group which appends an increasing integer column to
 // the output. colName optionally denotes the name this column is given, or can
 // be blank for none.
@@ -258,11 +315,19 @@ func (b *Builder) buildWithOrdinality(colName string, inScope *scope) (outScope
 // buildSelect builds a set of memo groups that represent the given select
 // statement.
 //
+// desiredTypes is an optional list with the desired types of the output
+// columns of the statement, which is used to type constants and placeholders
+// in the projection list (e.g. when building the source of an INSERT). The
+// list can be shorter than the number of output columns, or nil.
+//
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
-func (b *Builder) buildSelect(stmt *tree.Select, inScope *scope) (outScope *scope) {
+func (b *Builder) buildSelect(
+	stmt *tree.Select, desiredTypes []types.T, inScope *scope,
+) (outScope *scope) {
+	var ctes []*cteSource
 	if stmt.With != nil {
-		panic(unimplementedf("with clause not supported"))
+		inScope, ctes = b.buildCTEs(stmt.With, inScope)
 	}
 
 	wrapped := stmt.Select
@@ -293,13 +358,13 @@ func (b *Builder) buildSelect(stmt *tree.Select, inScope *scope) (outScope *scop
 	// NB: The case statements are sorted lexicographically.
 	switch t := stmt.Select.(type) {
 	case *tree.SelectClause:
-		outScope = b.buildSelectClause(t, orderBy, inScope)
+		outScope = b.buildSelectClause(t, orderBy, desiredTypes, inScope)
 
 	case *tree.UnionClause:
-		outScope = b.buildUnion(t, inScope)
+		outScope = b.buildUnion(t, desiredTypes, inScope)
 
 	case *tree.ValuesClause:
-		outScope = b.buildValuesClause(t, inScope)
+		outScope = b.buildValuesClause(t, desiredTypes, inScope)
 
 	default:
 		panic(fmt.Errorf("unknown select statement: %T", stmt.Select))
@@ -320,6 +385,8 @@ func (b *Builder) buildSelect(stmt *tree.Select, inScope *scope) (outScope *scop
 		b.buildLimit(limit, inScope, outScope)
 	}
 
+	b.wrapWithCTEs(outScope, ctes)
+
 	// TODO(rytaft): Support FILTER expression.
 	return outScope
 }
@@ -330,19 +397,21 @@ func (b *Builder) buildSelect(stmt *tree.Select, inScope *scope) (outScope *scop
 // results using columns from the FROM/GROUP BY clause and/or from the
 // projection list.
 //
-// See Builder.buildStmt for a description of the remaining input and
-// return values.
+// See Builder.buildSelect for a description of the desiredTypes parameter,
+// and Builder.buildStmt for a description of the remaining input and return
+// values.
 func (b *Builder) buildSelectClause(
-	sel *tree.SelectClause, orderBy tree.OrderBy, inScope *scope,
+	sel *tree.SelectClause, orderBy tree.OrderBy, desiredTypes []types.T, inScope *scope,
 ) (outScope *scope) {
 	fromScope := b.buildFrom(sel.From, sel.Where, inScope)
+	b.initWindowing(sel.Window, fromScope)
 
 	var projectionsScope *scope
 	if b.needsAggregation(sel, orderBy) {
-		outScope, projectionsScope = b.buildAggregation(sel, orderBy, fromScope)
+		outScope, projectionsScope = b.buildAggregation(sel, orderBy, desiredTypes, fromScope)
 	} else {
 		projectionsScope = fromScope.replace()
-		b.buildProjectionList(sel.Exprs, fromScope, projectionsScope)
+		b.buildProjectionList(sel.Exprs, desiredTypes, fromScope, projectionsScope)
 		b.buildOrderBy(orderBy, fromScope, projectionsScope)
 		b.buildDistinctOnArgs(sel.DistinctOn, fromScope, projectionsScope)
 		outScope = fromScope
@@ -352,6 +421,13 @@ func (b *Builder) buildSelectClause(
 		outScope.group = b.constructProjectSet(outScope.group, fromScope.srfs)
 	}
 
+	if len(fromScope.windowing.windows) > 0 {
+		if len(fromScope.srfs) > 0 {
+			panic(unimplementedf("window functions with set-returning functions are not supported"))
+		}
+		b.constructWindows(fromScope.windowing, outScope)
+	}
+
 	// Construct the projection.
 	b.constructProjectForScope(outScope, projectionsScope)
 	outScope = projectionsScope
@@ -25,13 +25,16 @@ import (
 )
 
 // buildUnion builds a set of memo groups that represent the given union
-// clause.
+// clause. The desired types are passed through to both inputs of the union.
 //
-// See Builder.buildStmt for a description of the remaining input and
-// return values.
-func (b *Builder) buildUnion(clause *tree.UnionClause, inScope *scope) (outScope *scope) {
-	leftScope := b.buildSelect(clause.Left, inScope)
-	rightScope := b.buildSelect(clause.Right, inScope)
+// See Builder.buildSelect for a description of the desiredTypes parameter,
+// and Builder.buildStmt for a description of the remaining input and return
+// values.
+func (b *Builder) buildUnion(
+	clause *tree.UnionClause, desiredTypes []types.T, inScope *scope,
+) (outScope *scope) {
+	leftScope := b.buildSelect(clause.Left, desiredTypes, inScope)
+	rightScope := b.buildSelect(clause.Right, desiredTypes, inScope)
 
 	// Remove any hidden columns, as they are not included in the Union.
 	leftScope.removeHiddenCols()
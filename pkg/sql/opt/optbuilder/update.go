@@ -0,0 +1,97 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// buildUpdate builds a memo group for an UpdateOp expression. The input of the
+// mutation scans all the columns of the target table, filters the rows using
+// the WHERE clause, and projects the new values of the updated columns. The
+// values of any computed columns are recomputed by the execution engine.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope) {
+	// UX friendliness safeguard.
+	if upd.Where == nil && b.evalCtx.SessionData.SafeUpdates {
+		panic(builderError{pgerror.NewDangerousStatementErrorf("UPDATE without WHERE clause")})
+	}
+	var ctes []*cteSource
+	if upd.With != nil {
+		inScope, ctes = b.buildCTEs(upd.With, inScope)
+	}
+	if upd.OrderBy != nil || upd.Limit != nil {
+		panic(unimplementedf("UPDATE with ORDER BY or LIMIT is not supported"))
+	}
+
+	tab, tn, alias := b.resolveMutationTable(upd.Table, privilege.UPDATE)
+	scanScope := b.buildMutationScan(tab, tn, alias, upd.Where, inScope)
+
+	def := memo.MutationOpDef{
+		Table:      b.addMutationTable(tab, tn),
+		FetchCols:  colsToColList(scanScope.cols),
+		UpdateCols: make(opt.ColList, tab.ColumnCount()),
+	}
+
+	// We need to save and restore the previous value of the field in
+	// semaCtx in case we are recursively called within a subquery
+	// context.
+	defer b.semaCtx.Properties.Restore(b.semaCtx.Properties)
+	b.semaCtx.Properties.Require("UPDATE SET", tree.RejectSpecial)
+
+	// Project the existing values of all the columns, followed by the new
+	// values of the updated columns.
+	projectionsScope := scanScope.replace()
+	projectionsScope.appendColumns(scanScope)
+	var names tree.NameList
+	var exprs []tree.Expr
+	for _, setExpr := range upd.Exprs {
+		if setExpr.Tuple {
+			panic(unimplementedf("tuple assignments in UPDATE are not supported"))
+		}
+		names = append(names, setExpr.Names...)
+		exprs = append(exprs, setExpr.Expr)
+	}
+	for i, ord := range resolveMutationCols(tab, names) {
+		if _, ok := exprs[i].(tree.DefaultVal); ok {
+			panic(unimplementedf("DEFAULT expressions in UPDATE are not supported"))
+		}
+		col := tab.Column(ord)
+		texpr := scanScope.resolveType(exprs[i], col.DatumType())
+		checkMutationCol(col, texpr.ResolvedType())
+
+		// Always synthesize a new column for the new value, even if it is a
+		// reference to an existing column, so that it can be distinguished
+		// from the fetched columns.
+		group := b.buildScalar(texpr, scanScope)
+		newCol := b.synthesizeColumn(
+			projectionsScope, string(col.ColName()), texpr.ResolvedType(), texpr, group,
+		)
+		def.UpdateCols[ord] = newCol.id
+	}
+	b.constructProjectForScope(scanScope, projectionsScope)
+
+	outScope = b.finishBuildMutation(
+		opt.UpdateOp, projectionsScope.group, &def, tab, alias, upd.Returning, inScope,
+	)
+	b.wrapWithCTEs(outScope, ctes)
+	return outScope
+}
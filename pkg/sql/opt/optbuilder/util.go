@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -341,7 +342,9 @@ func (b *Builder) assertNoAggregationOrWindowing(expr tree.Expr, op string) {
 	}
 }
 
-func (b *Builder) resolveTableRef(ref *tree.TableRef) opt.Table {
+// resolveTableRef returns the table in the catalog with the given ID, after
+// checking that the current user has the given privilege on it.
+func (b *Builder) resolveTableRef(ref *tree.TableRef, priv privilege.Kind) opt.Table {
 	tab, err := b.catalog.FindTableByID(b.ctx, ref.TableID)
 	if err != nil {
 		// TODO(madhavsuresh): This branching statement is a hack to maintain compatibility
@@ -357,11 +360,13 @@ func (b *Builder) resolveTableRef(ref *tree.TableRef) opt.Table {
 			panic(builderError{err})
 		}
 	}
+	b.checkPrivilege(tab, priv)
 	return tab
 }
 
-// resolveTable returns the table in the catalog with the given name.
-func (b *Builder) resolveTable(tn *tree.TableName) opt.Table {
+// resolveTable returns the table in the catalog with the given name, after
+// checking that the current user has the given privilege on it.
+func (b *Builder) resolveTable(tn *tree.TableName, priv privilege.Kind) opt.Table {
 	tab, err := b.catalog.FindTable(b.ctx, tn)
 	if err != nil {
 		pgerr, ok := err.(*pgerror.Error)
@@ -372,5 +377,14 @@ func (b *Builder) resolveTable(tn *tree.TableName) opt.Table {
 
 		panic(builderError{err})
 	}
+	b.checkPrivilege(tab, priv)
 	return tab
 }
+
+// checkPrivilege ensures that the current user has the given privilege on the
+// given table.
+func (b *Builder) checkPrivilege(tab opt.Table, priv privilege.Kind) {
+	if err := b.catalog.CheckPrivilege(b.ctx, tab, priv); err != nil {
+		panic(builderError{err})
+	}
+}
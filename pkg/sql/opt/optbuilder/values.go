@@ -26,9 +26,12 @@ import (
 // buildValuesClause builds a set of memo groups that represent the given values
 // clause.
 //
-// See Builder.buildStmt for a description of the remaining input and
-// return values.
-func (b *Builder) buildValuesClause(values *tree.ValuesClause, inScope *scope) (outScope *scope) {
+// See Builder.buildSelect for a description of the desiredTypes parameter,
+// and Builder.buildStmt for a description of the remaining input and return
+// values.
+func (b *Builder) buildValuesClause(
+	values *tree.ValuesClause, desiredTypes []types.T, inScope *scope,
+) (outScope *scope) {
 	var numCols int
 	if len(values.Rows) > 0 {
 		numCols = len(values.Rows[0])
@@ -61,7 +64,11 @@ func (b *Builder) buildValuesClause(values *tree.ValuesClause, inScope *scope) (
 		}
 
 		for i, expr := range tuple {
-			texpr := inScope.resolveType(expr, types.Any)
+			desired := types.Any
+			if i < len(desiredTypes) {
+				desired = desiredTypes[i]
+			}
+			texpr := inScope.resolveType(expr, desired)
 			typ := texpr.ResolvedType()
 			elems[i] = b.buildScalar(texpr, inScope)
 
@@ -0,0 +1,314 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+// This file has builder code specific to window functions (function
+// applications with an OVER clause).
+//
+// We build such queries using the following operators:
+//
+//  - a pre-projection: a ProjectOp which passes through the columns of its
+//    input (the FROM clause or the aggregation) and generates the columns
+//    needed by the window functions:
+//      - arguments to window functions
+//      - PARTITION BY and ORDER BY expressions of the window definitions
+//
+//  - the windows: a WindowOp for each distinct window definition, stacked on
+//    top of each other. Each WindowOp passes through the columns of its input
+//    and produces a column with the result of each window function that uses
+//    the definition.
+//
+//  - a projection: calculates expressions using the results of the window
+//    functions; this is the same ProjectOp that we would use for a Select
+//    without window functions.
+//
+// For example:
+//   SELECT 1 + rank() OVER (PARTITION BY k+3 ORDER BY v) FROM kv
+//
+//   pre-projection:  k, v, k+3 (as col1)
+//   window:          partition by col1, order by v, rank() (as col2)
+//   projection:      1 + col2
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// windowing information stored in scopes.
+type windowing struct {
+	// argScope contains the columns that are added to the input of the window
+	// operators by the pre-projection: the arguments of the window functions,
+	// and the PARTITION BY and ORDER BY expressions of the window definitions.
+	argScope *scope
+
+	// outScope contains a column with the result of each window function.
+	outScope *scope
+
+	// windows contains information about the window functions encountered,
+	// in the same order as the columns in outScope.
+	windows []windowInfo
+
+	// namedWindows contains the window specifications in the WINDOW clause of
+	// the SELECT statement, by name.
+	namedWindows map[string]*tree.WindowDef
+
+	// inWindow is true while building the arguments or window definition of a
+	// window function. It is used to ensure that nested window functions are
+	// disallowed.
+	inWindow bool
+}
+
+// windowInfo stores information about a window function call.
+type windowInfo struct {
+	def       memo.FuncOpDef
+	args      opt.ColList
+	partition opt.ColSet
+	ordering  opt.Ordering
+	frame     *tree.WindowFrame
+}
+
+// sameWindow returns true if the given window function can be computed by the
+// same Window operator as this one.
+func (w *windowInfo) sameWindow(other *windowInfo) bool {
+	return w.partition.Equals(other.partition) &&
+		w.ordering.Equals(other.ordering) &&
+		w.frame == other.frame
+}
+
+// initWindowing prepares the given scope for building window functions found
+// in the SELECT list or the ORDER BY clause. window is the WINDOW clause of
+// the SELECT statement.
+func (b *Builder) initWindowing(window tree.Window, fromScope *scope) {
+	w := &windowing{
+		argScope: fromScope.replace(),
+		outScope: fromScope.replace(),
+	}
+	if len(window) > 0 {
+		w.namedWindows = make(map[string]*tree.WindowDef, len(window))
+		for _, def := range window {
+			name := string(def.Name)
+			if _, ok := w.namedWindows[name]; ok {
+				panic(builderError{pgerror.NewErrorf(
+					pgerror.CodeWindowingError, "window %q is already defined", name,
+				)})
+			}
+			w.namedWindows[name] = def
+		}
+	}
+	fromScope.windowing = w
+}
+
+// buildWindowFunction is called when we are building a function application
+// with an OVER clause. The arguments of the window function, and the
+// expressions in its window definition, are extracted and added to the
+// argScope of the windowing. A column for the result of the window function is
+// added to the outScope of the windowing.
+//
+// If outScope is nil, then buildWindowFunction returns a Variable reference
+// to the window function column. Otherwise, it adds that column to the
+// outScope projection, and returns 0.
+func (b *Builder) buildWindowFunction(
+	f *tree.FuncExpr, funcDef memo.FuncOpDef, label string, inScope, outScope *scope,
+) (out memo.GroupID) {
+	w := inScope.windowing
+	if w == nil {
+		panic(unimplementedf("window functions are not supported in this context"))
+	}
+	if w.inWindow {
+		panic(builderError{pgerror.NewErrorf(
+			pgerror.CodeWindowingError, "window function calls cannot be nested",
+		)})
+	}
+	if f.Filter != nil {
+		panic(unimplementedf("window functions with FILTER are not supported yet"))
+	}
+
+	windowDef, named := b.constructWindowDef(*f.WindowDef, w.namedWindows)
+
+	w.inWindow = true
+	info := windowInfo{
+		def:   funcDef,
+		args:  make(opt.ColList, len(f.Exprs)),
+		frame: windowDef.Frame,
+	}
+	for i, pexpr := range f.Exprs {
+		// This synthesizes a new argScope column, unless the argument is a simple
+		// VariableOp.
+		col := b.buildScalarProjection(pexpr.(tree.TypedExpr), "" /* label */, inScope, w.argScope)
+		info.args[i] = col.id
+	}
+
+	// The expressions in a window definition from the WINDOW clause have not
+	// been resolved yet.
+	resolve := func(expr tree.Expr) tree.TypedExpr {
+		if named {
+			return inScope.resolveType(expr, types.Any)
+		}
+		return expr.(tree.TypedExpr)
+	}
+
+	for _, partition := range windowDef.Partitions {
+		col := b.buildScalarProjection(resolve(partition), "" /* label */, inScope, w.argScope)
+		info.partition.Add(int(col.id))
+	}
+
+	for i, orderBy := range windowDef.OrderBy {
+		if orderBy.OrderType != tree.OrderByColumn {
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"ORDER BY INDEX in window definition is not supported")})
+		}
+		texpr := resolve(orderBy.Expr)
+		windowDef.OrderBy[i].Expr = texpr
+		col := b.buildScalarProjection(texpr, "" /* label */, inScope, w.argScope)
+		info.ordering = append(info.ordering, opt.MakeOrderingColumn(
+			col.id, orderBy.Direction == tree.Descending,
+		))
+	}
+
+	if named && windowDef.Frame != nil {
+		if err := windowDef.Frame.TypeCheck(b.semaCtx, &windowDef); err != nil {
+			panic(builderError{err})
+		}
+	}
+	w.inWindow = false
+
+	col := b.synthesizeColumn(w.outScope, label, f.ResolvedType(), f, 0 /* group */)
+	w.windows = append(w.windows, info)
+
+	// Either wrap the column in a Variable expression if it's part of a larger
+	// expression, or else add it to the outScope if it needs to be projected.
+	return b.finishBuildScalarRef(col, label, w.outScope, outScope)
+}
+
+// constructWindowDef constructs a WindowDef using the provided WindowDef value
+// and the set of named window specifications on the current SELECT clause. If
+// the provided WindowDef does not reference a named window spec, then it will
+// simply be returned without modification. If the provided WindowDef does
+// reference a named window spec, then the referenced spec will be overridden
+// with any extra clauses from the WindowDef and returned, and named will be
+// true.
+func (b *Builder) constructWindowDef(
+	def tree.WindowDef, namedWindowSpecs map[string]*tree.WindowDef,
+) (_ tree.WindowDef, named bool) {
+	modifyRef := false
+	var refName string
+	switch {
+	case def.RefName != "":
+		// SELECT rank() OVER (w) FROM t WINDOW w as (...)
+		// We copy the referenced window specification, and modify it if necessary.
+		refName = string(def.RefName)
+		modifyRef = true
+	case def.Name != "":
+		// SELECT rank() OVER w FROM t WINDOW w as (...)
+		// We use the referenced window specification directly, without modification.
+		refName = string(def.Name)
+	}
+	if refName == "" {
+		return def, false
+	}
+
+	referencedSpec, ok := namedWindowSpecs[refName]
+	if !ok {
+		panic(builderError{pgerror.NewErrorf(
+			pgerror.CodeUndefinedObjectError, "window %q does not exist", refName,
+		)})
+	}
+	if !modifyRef {
+		return *referencedSpec, true
+	}
+
+	// referencedSpec.Partitions is always used.
+	if len(def.Partitions) > 0 {
+		panic(builderError{pgerror.NewErrorf(
+			pgerror.CodeWindowingError, "cannot override PARTITION BY clause of window %q", refName,
+		)})
+	}
+	def.Partitions = referencedSpec.Partitions
+
+	// referencedSpec.OrderBy is used if set.
+	if len(referencedSpec.OrderBy) > 0 {
+		if len(def.OrderBy) > 0 {
+			panic(builderError{pgerror.NewErrorf(
+				pgerror.CodeWindowingError, "cannot override ORDER BY clause of window %q", refName,
+			)})
+		}
+		def.OrderBy = referencedSpec.OrderBy
+	}
+
+	if referencedSpec.Frame != nil {
+		panic(builderError{pgerror.NewErrorf(
+			pgerror.CodeWindowingError, "cannot copy window %q because it has a frame clause", refName,
+		)})
+	}
+
+	return def, true
+}
+
+// constructWindows builds the pre-projection and the window operators on top
+// of the given scope, which is the input of the window functions. The group of
+// the scope is updated to refer to the top-most window operator.
+func (b *Builder) constructWindows(w *windowing, inScope *scope) {
+	// Construct the pre-projection, which passes through the input columns and
+	// renders the arguments of the window functions and the window definition
+	// expressions.
+	preProjScope := inScope.replace()
+	preProjScope.appendColumns(inScope)
+	preProjScope.cols = append(preProjScope.cols, w.argScope.cols...)
+	b.constructProjectForScope(inScope, preProjScope)
+
+	// Construct a window operator for each distinct window definition, in the
+	// order in which the definitions were first encountered.
+	group := preProjScope.group
+	done := make([]bool, len(w.windows))
+	for i := range w.windows {
+		if done[i] {
+			continue
+		}
+		info := &w.windows[i]
+
+		var fnList []memo.GroupID
+		var colList opt.ColList
+		for j := i; j < len(w.windows); j++ {
+			if done[j] || !info.sameWindow(&w.windows[j]) {
+				continue
+			}
+			done[j] = true
+
+			fn := &w.windows[j]
+			argList := make([]memo.GroupID, len(fn.args))
+			for k, col := range fn.args {
+				argList[k] = b.factory.ConstructVariable(b.factory.InternColumnID(col))
+			}
+			fnList = append(fnList, b.factory.ConstructWindowFunction(
+				b.factory.InternList(argList), b.factory.InternFuncOpDef(&fn.def),
+			))
+			colList = append(colList, w.outScope.cols[j].id)
+		}
+
+		group = b.factory.ConstructWindow(
+			group,
+			b.factory.ConstructWindows(b.factory.InternList(fnList), b.factory.InternColList(colList)),
+			b.factory.InternWindowDef(&memo.WindowDef{
+				Partition: info.partition,
+				Ordering:  info.ordering,
+				Frame:     info.frame,
+			}),
+		)
+	}
+	inScope.group = group
+}
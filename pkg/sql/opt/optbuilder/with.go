@@ -0,0 +1,155 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+// This file has builder code specific to common table expressions (the WITH
+// clause of a statement).
+//
+// Each CTE is built as a separate expression (the "binding"), which is given a
+// unique ID. References to the CTE in the statement are built as WithScan
+// operators, which return the rows of the binding with fresh column IDs.
+// Finally, the statement is wrapped in a With operator for each CTE that was
+// referenced. For example:
+//
+//   WITH t AS (SELECT a FROM ab) SELECT * FROM t
+//
+//   with &1 (t)
+//    ├── binding: scan ab
+//    └── with-scan &1 (t)
+//
+// As in the heuristic planner, a CTE can be referenced at most once. This
+// allows the binding to be executed in place of its reference.
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// cteSource represents a CTE in the given query.
+type cteSource struct {
+	id    int
+	name  tree.AliasClause
+	cols  []scopeColumn
+	group memo.GroupID
+
+	// used is set to true if this CTE has been referenced. It is used to
+	// prevent multiple use of a CTE, which is currently not supported.
+	used bool
+}
+
+// buildCTEs builds the bindings of the CTEs in the given WITH clause. It
+// returns a new scope in which the CTEs are visible, along with the CTEs (in
+// the order they were defined). Each CTE is visible to the CTEs defined after
+// it. Once the statement has been built, wrapWithCTEs must be called to add the
+// With operators for the referenced CTEs.
+func (b *Builder) buildCTEs(with *tree.With, inScope *scope) (outScope *scope, ctes []*cteSource) {
+	outScope = inScope.push()
+	outScope.ctes = make(map[tree.Name]*cteSource, len(with.CTEList))
+	ctes = make([]*cteSource, 0, len(with.CTEList))
+	for _, cte := range with.CTEList {
+		if _, ok := outScope.ctes[cte.Name.Alias]; ok {
+			panic(builderError{pgerror.NewErrorf(
+				pgerror.CodeDuplicateAliasError,
+				"WITH query name %s specified more than once", cte.Name.Alias,
+			)})
+		}
+
+		cteScope := b.buildStmt(cte.Stmt, outScope)
+		cteScope.removeHiddenCols()
+
+		b.numWiths++
+		source := &cteSource{
+			id:    b.numWiths,
+			name:  cte.Name,
+			cols:  cteScope.cols,
+			group: cteScope.group,
+		}
+		outScope.ctes[cte.Name.Alias] = source
+		ctes = append(ctes, source)
+	}
+	return outScope, ctes
+}
+
+// wrapWithCTEs wraps the expression in the given scope with a With operator
+// for each of the given CTEs that was referenced.
+func (b *Builder) wrapWithCTEs(outScope *scope, ctes []*cteSource) {
+	// Wrap in reverse order, so that each CTE is in scope for the bindings of
+	// the CTEs defined after it.
+	for i := len(ctes) - 1; i >= 0; i-- {
+		cte := ctes[i]
+		if !cte.used {
+			continue
+		}
+		outScope.group = b.factory.ConstructWith(
+			cte.group,
+			outScope.group,
+			b.factory.InternWithDef(&memo.WithDef{ID: cte.id, Name: string(cte.name.Alias)}),
+		)
+	}
+}
+
+// resolveCTE looks up the given table name among the CTEs that are visible in
+// this scope, and returns the CTE if it was found.
+func (s *scope) resolveCTE(tn *tree.TableName) *cteSource {
+	if tn.ExplicitSchema {
+		// If the name was prefixed, it cannot be a CTE.
+		return nil
+	}
+	for curr := s; curr != nil; curr = curr.parent {
+		if cte, ok := curr.ctes[tn.TableName]; ok {
+			return cte
+		}
+	}
+	return nil
+}
+
+// buildCTEScan builds a WithScan operator that returns the rows of the given
+// CTE, which is referenced using the given table name.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildCTEScan(cte *cteSource, tn *tree.TableName, inScope *scope) (outScope *scope) {
+	if cte.used {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"unsupported multiple use of CTE clause %q", tree.ErrString(tn))})
+	}
+	if len(cte.cols) == 0 {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"WITH clause %q does not have a RETURNING clause", tree.ErrString(tn))})
+	}
+	cte.used = true
+
+	outScope = inScope.push()
+	def := memo.WithScanDef{
+		ID:           cte.id,
+		Name:         string(cte.name.Alias),
+		InCols:       make(opt.ColList, len(cte.cols)),
+		OutCols:      make(opt.ColList, len(cte.cols)),
+		BindingProps: memo.MakeNormExprView(b.factory.Memo(), cte.group).Logical().Relational,
+	}
+	for i := range cte.cols {
+		col := &cte.cols[i]
+		newCol := b.synthesizeColumn(outScope, string(col.name), col.typ, nil /* expr */, 0 /* group */)
+		newCol.table = *tn
+		def.InCols[i] = col.id
+		def.OutCols[i] = newCol.id
+	}
+	outScope.group = b.factory.ConstructWithScan(b.factory.InternWithScanDef(&def))
+
+	b.renameSource(cte.name, outScope)
+	return outScope
+}
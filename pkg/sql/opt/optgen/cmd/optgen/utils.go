@@ -70,12 +70,22 @@ func mapPrivateType(typ string) string {
 		return "*memo.IndexJoinDef"
 	case "LookupJoinDef":
 		return "*memo.LookupJoinDef"
+	case "ZigzagJoinDef":
+		return "*memo.ZigzagJoinDef"
 	case "RowNumberDef":
 		return "*memo.RowNumberDef"
+	case "WindowDef":
+		return "*memo.WindowDef"
+	case "WithDef":
+		return "*memo.WithDef"
+	case "WithScanDef":
+		return "*memo.WithScanDef"
 	case "SetOpColMap":
 		return "*memo.SetOpColMap"
 	case "ExplainOpDef":
 		return "*memo.ExplainOpDef"
+	case "MutationOpDef":
+		return "*memo.MutationOpDef"
 	case "ShowTraceOpDef":
 		return "*memo.ShowTraceOpDef"
 	case "MergeOnDef":
@@ -0,0 +1,411 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package props
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// unknownBucketFraction is the fraction of the rows in the range of a
+// histogram bucket that are assumed to satisfy a span which only partially
+// overlaps the bucket, when the fraction cannot be interpolated from the
+// bucket boundaries (e.g., for string columns).
+const unknownBucketFraction = 0.5
+
+// Histogram captures the distribution of the non-NULL values of a single
+// column. It is derived from the equi-depth histograms that are collected
+// with table statistics (see sql/stats/histogram.go), and is filtered and
+// scaled as it is propagated up the expression tree by the statistics builder.
+//
+// Each bucket describes the values that are greater than the upper bound of
+// the previous bucket and less than or equal to its own upper bound. The lower
+// bound of the first bucket is unknown.
+//
+// A Histogram is immutable once it has been constructed; Filter and
+// ApplySelectivity return new histograms.
+type Histogram struct {
+	evalCtx *tree.EvalContext
+	buckets []HistogramBucket
+}
+
+// HistogramBucket contains the estimated row counts for a single bucket of a
+// Histogram.
+type HistogramBucket struct {
+	// NumEq is the estimated number of rows equal to UpperBound.
+	NumEq float64
+
+	// NumRange is the estimated number of rows strictly between the upper
+	// bound of the previous bucket and UpperBound.
+	NumRange float64
+
+	// DistinctRange is the estimated number of distinct values strictly
+	// between the upper bound of the previous bucket and UpperBound.
+	DistinctRange float64
+
+	// UpperBound is the upper boundary of the bucket.
+	UpperBound tree.Datum
+}
+
+// NewHistogram creates a histogram from the buckets of a table statistic.
+// Table statistics don't include the number of distinct values in the range
+// of each bucket, so it is estimated from the distinct count of the column:
+// the distinct values that are not bucket upper bounds are spread across the
+// buckets in proportion to the number of rows in their ranges.
+func NewHistogram(
+	evalCtx *tree.EvalContext, buckets []opt.HistogramBucket, distinctCount float64,
+) *Histogram {
+	h := &Histogram{evalCtx: evalCtx, buckets: make([]HistogramBucket, len(buckets))}
+	var totalRange float64
+	for i := range buckets {
+		totalRange += float64(buckets[i].NumRange)
+	}
+	distinctRanges := math.Max(distinctCount-float64(len(buckets)), 0)
+	for i := range buckets {
+		b := &h.buckets[i]
+		b.NumEq = float64(buckets[i].NumEq)
+		b.NumRange = float64(buckets[i].NumRange)
+		b.UpperBound = buckets[i].UpperBound
+		if b.NumRange == 0 {
+			continue
+		}
+		b.DistinctRange = distinctRanges * b.NumRange / totalRange
+		if i > 0 {
+			// The range of an integer bucket can't contain more distinct values
+			// than there are integers between its boundaries.
+			lower := buckets[i-1].UpperBound
+			if width, ok := intRangeWidth(lower, b.UpperBound); ok {
+				b.DistinctRange = math.Min(b.DistinctRange, width)
+			}
+		}
+		b.DistinctRange = math.Max(math.Min(b.DistinctRange, b.NumRange), 1)
+	}
+	return h
+}
+
+// BucketCount returns the number of buckets in the histogram.
+func (h *Histogram) BucketCount() int {
+	return len(h.buckets)
+}
+
+// Bucket returns the ith bucket of the histogram, where i < BucketCount.
+func (h *Histogram) Bucket(i int) *HistogramBucket {
+	return &h.buckets[i]
+}
+
+// RowCount returns the estimated number of rows described by the histogram.
+func (h *Histogram) RowCount() float64 {
+	var rowCount float64
+	for i := range h.buckets {
+		rowCount += h.buckets[i].NumEq + h.buckets[i].NumRange
+	}
+	return rowCount
+}
+
+// DistinctCount returns the estimated number of distinct values described by
+// the histogram.
+func (h *Histogram) DistinctCount() float64 {
+	var distinctCount float64
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		if b.NumEq > 0 {
+			distinctCount++
+		}
+		distinctCount += b.DistinctRange
+	}
+	return distinctCount
+}
+
+// ApplySelectivity returns a new histogram in which the row count of every
+// bucket is scaled by the given selectivity. The distinct counts are reduced
+// using the same formula as the distinct count of a column (see
+// statisticsBuilder.applySelectivityToColStat).
+func (h *Histogram) ApplySelectivity(selectivity float64) *Histogram {
+	res := &Histogram{evalCtx: h.evalCtx, buckets: make([]HistogramBucket, len(h.buckets))}
+	for i := range h.buckets {
+		b := h.buckets[i]
+		b.NumEq *= selectivity
+		if b.DistinctRange > 0 {
+			d := b.DistinctRange
+			b.DistinctRange = d - d*math.Pow(1-selectivity, b.NumRange/d)
+		}
+		b.NumRange *= selectivity
+		res.buckets[i] = b
+	}
+	return res
+}
+
+// Filter returns a new histogram that only describes the values that are
+// within the spans of the given constraint. Only the first column of the
+// constraint is taken into account, and it must be the column described by
+// the histogram. Filter returns nil if the constraint cannot be applied to the
+// histogram, which is the case if any of its spans contains NULL values
+// (NULLs are not described by histograms).
+func (h *Histogram) Filter(c *constraint.Constraint) *Histogram {
+	ranges, ok := makeValueRanges(c)
+	if !ok {
+		return nil
+	}
+	res := &Histogram{evalCtx: h.evalCtx}
+	for i := range ranges {
+		for j := range h.buckets {
+			res.filterBucket(h, j, &ranges[i])
+		}
+	}
+	return res
+}
+
+// FilterRange returns a new histogram that only describes the values between
+// lo and hi, inclusive. A nil boundary is unbounded.
+func (h *Histogram) FilterRange(lo, hi tree.Datum) *Histogram {
+	r := valueRange{lo: lo, hi: hi, loIncl: true, hiIncl: true}
+	res := &Histogram{evalCtx: h.evalCtx}
+	for j := range h.buckets {
+		res.filterBucket(h, j, &r)
+	}
+	return res
+}
+
+// ValueRange returns the smallest and largest values that can be described
+// by the histogram, ignoring empty buckets. lo is nil if the smallest value
+// is unknown, which is the case if the first non-empty bucket is the first
+// bucket and has values in its range. ok is false if the histogram is empty.
+func (h *Histogram) ValueRange() (lo, hi tree.Datum, ok bool) {
+	first, last := -1, -1
+	for i := range h.buckets {
+		if h.buckets[i].NumEq+h.buckets[i].NumRange > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return nil, nil, false
+	}
+	if h.buckets[first].NumRange == 0 {
+		lo = h.buckets[first].UpperBound
+	} else if first > 0 {
+		lo = h.buckets[first-1].UpperBound
+	}
+	return lo, h.buckets[last].UpperBound, true
+}
+
+// filterBucket appends the buckets that describe the intersection of the jth
+// bucket of h with the given value range to the histogram. If the range
+// starts or ends strictly inside the bucket, the bucket is split at the range
+// boundaries. Rows equal to a new boundary are assumed to occur with the
+// average frequency of the values in the range of the original bucket.
+func (h *Histogram) filterBucket(from *Histogram, j int, r *valueRange) {
+	b := &from.buckets[j]
+	var lower tree.Datum
+	if j > 0 {
+		lower = from.buckets[j-1].UpperBound
+	}
+
+	// Skip the bucket if it is entirely outside of the range.
+	if r.hi != nil && lower != nil && r.hi.Compare(h.evalCtx, lower) <= 0 {
+		return
+	}
+	if r.lo != nil {
+		if cmp := r.lo.Compare(h.evalCtx, b.UpperBound); cmp > 0 {
+			return
+		} else if cmp == 0 {
+			// Only the upper bound of the bucket can be in the range. Keep the
+			// bucket even if it is empty, so that the lower bound of the next
+			// bucket is still known.
+			res := HistogramBucket{UpperBound: b.UpperBound}
+			if r.loIncl {
+				res.NumEq = b.NumEq
+			}
+			h.buckets = append(h.buckets, res)
+			return
+		}
+	}
+
+	var avgFreq float64
+	if b.DistinctRange > 0 {
+		avgFreq = b.NumRange / b.DistinctRange
+	}
+	startInside := r.lo != nil && r.lo.Compare(h.evalCtx, b.UpperBound) < 0 &&
+		(lower == nil || r.lo.Compare(h.evalCtx, lower) > 0)
+	endInside := r.hi != nil && r.hi.Compare(h.evalCtx, b.UpperBound) < 0 &&
+		(lower == nil || r.hi.Compare(h.evalCtx, lower) > 0)
+
+	if startInside {
+		numEq := 0.0
+		if r.loIncl {
+			numEq = avgFreq
+		}
+		h.buckets = append(h.buckets, HistogramBucket{NumEq: numEq, UpperBound: r.lo})
+		if endInside && r.lo.Compare(h.evalCtx, r.hi) == 0 {
+			// The range contains a single value.
+			return
+		}
+	}
+
+	fraction := 1.0
+	if startInside || endInside {
+		lo, hi := lower, b.UpperBound
+		if startInside {
+			lo = r.lo
+		}
+		if endInside {
+			hi = r.hi
+		}
+		fraction = h.rangeFraction(lower, b.UpperBound, lo, hi)
+	}
+
+	res := HistogramBucket{
+		NumRange:      b.NumRange * fraction,
+		DistinctRange: b.DistinctRange * fraction,
+		UpperBound:    b.UpperBound,
+	}
+	if endInside {
+		res.UpperBound = r.hi
+		if r.hiIncl {
+			res.NumEq = avgFreq
+		}
+	} else if r.hi == nil || r.hiIncl || b.UpperBound.Compare(h.evalCtx, r.hi) < 0 {
+		res.NumEq = b.NumEq
+	}
+	h.buckets = append(h.buckets, res)
+}
+
+// rangeFraction returns the estimated fraction of the values strictly between
+// lower and upper that are also strictly between lo and hi, where lower <= lo
+// <= hi <= upper. The fraction is interpolated for numeric and time types,
+// assuming that values are uniformly distributed within the bucket. lower is
+// nil if the bucket is the first bucket of the histogram.
+func (h *Histogram) rangeFraction(lower, upper, lo, hi tree.Datum) float64 {
+	if lower == nil {
+		return unknownBucketFraction
+	}
+	if width, ok := intRangeWidth(lower, upper); ok {
+		if width <= 0 {
+			return 0
+		}
+		selected, _ := intRangeWidth(lo, hi)
+		return math.Max(selected, 0) / width
+	}
+	lowerVal, ok1 := datumToFloat(lower)
+	upperVal, ok2 := datumToFloat(upper)
+	loVal, ok3 := datumToFloat(lo)
+	hiVal, ok4 := datumToFloat(hi)
+	if !ok1 || !ok2 || !ok3 || !ok4 || upperVal <= lowerVal {
+		return unknownBucketFraction
+	}
+	return math.Max(math.Min((hiVal-loVal)/(upperVal-lowerVal), 1), 0)
+}
+
+func (h *Histogram) String() string {
+	var buf bytes.Buffer
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		fmt.Fprintf(&buf, "{%.9g %.9g %.9g %s}", b.NumEq, b.NumRange, b.DistinctRange, b.UpperBound)
+	}
+	return buf.String()
+}
+
+// valueRange is a range of values of a single column, in ascending order. A
+// nil boundary is unbounded.
+type valueRange struct {
+	lo, hi         tree.Datum
+	loIncl, hiIncl bool
+}
+
+// makeValueRanges returns the ranges of values of the first column of the
+// given constraint, in ascending order. If a span boundary has more than one
+// column, the range of the first column is treated as inclusive of the
+// boundary value, so the ranges may contain values that are not in the
+// constraint. ok is false if any of the ranges contains NULL values.
+func makeValueRanges(c *constraint.Constraint) (ranges []valueRange, ok bool) {
+	ranges = make([]valueRange, c.Spans.Count())
+	for i := 0; i < c.Spans.Count(); i++ {
+		sp := c.Spans.Get(i)
+		r := &ranges[i]
+		start, startIncl := spanBoundaryValue(sp.StartKey(), sp.StartBoundary())
+		end, endIncl := spanBoundaryValue(sp.EndKey(), sp.EndBoundary())
+		if c.Columns.Get(0).Descending() {
+			start, startIncl, end, endIncl = end, endIncl, start, startIncl
+		}
+		r.lo, r.loIncl, r.hi, r.hiIncl = start, startIncl, end, endIncl
+		if r.hi == tree.DNull || (r.lo == tree.DNull && r.loIncl) {
+			return nil, false
+		}
+		if r.lo == tree.DNull {
+			// An exclusive NULL start boundary is equivalent to no boundary, since
+			// NULL sorts before all other values.
+			r.lo = nil
+		}
+	}
+	if c.Columns.Get(0).Descending() {
+		for i, j := 0, len(ranges)-1; i < j; i, j = i+1, j-1 {
+			ranges[i], ranges[j] = ranges[j], ranges[i]
+		}
+	}
+	return ranges, true
+}
+
+// spanBoundaryValue returns the value of the first column in the given span
+// key, or nil if the key is empty.
+func spanBoundaryValue(
+	key constraint.Key, boundary constraint.SpanBoundary,
+) (val tree.Datum, inclusive bool) {
+	if key.IsEmpty() {
+		return nil, false
+	}
+	return key.Value(0), boundary == constraint.IncludeBoundary || key.Length() > 1
+}
+
+// intRangeWidth returns the number of integers strictly between the two given
+// values, if they are both integers.
+func intRangeWidth(lo, hi tree.Datum) (width float64, ok bool) {
+	loInt, ok1 := lo.(*tree.DInt)
+	hiInt, ok2 := hi.(*tree.DInt)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return float64(*hiInt) - float64(*loInt) - 1, true
+}
+
+// datumToFloat converts a datum of a numeric or time type to a float64 that
+// preserves the ordering of values of the type.
+func datumToFloat(d tree.Datum) (float64, bool) {
+	switch t := d.(type) {
+	case *tree.DInt:
+		return float64(*t), true
+	case *tree.DFloat:
+		return float64(*t), true
+	case *tree.DDecimal:
+		f, err := t.Float64()
+		return f, err == nil
+	case *tree.DDate:
+		return float64(*t), true
+	case *tree.DTimestamp:
+		return float64(t.UnixNano()), true
+	case *tree.DTimestampTZ:
+		return float64(t.UnixNano()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package props_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func TestHistogram(t *testing.T) {
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+
+	//   0  1  3  3   4  5   0  0   40  35
+	// <--- 1 --- 10 --- 25 --- 30 ---- 42
+	buckets := []opt.HistogramBucket{
+		{NumRange: 0, NumEq: 1, UpperBound: tree.NewDInt(1)},
+		{NumRange: 3, NumEq: 3, UpperBound: tree.NewDInt(10)},
+		{NumRange: 4, NumEq: 5, UpperBound: tree.NewDInt(25)},
+		{NumRange: 0, NumEq: 0, UpperBound: tree.NewDInt(30)},
+		{NumRange: 40, NumEq: 35, UpperBound: tree.NewDInt(42)},
+	}
+	h := props.NewHistogram(&evalCtx, buckets, 20 /* distinctCount */)
+
+	if h.RowCount() != 91 {
+		t.Fatalf("expected 91 rows, got %g", h.RowCount())
+	}
+	if expected := "{1 0 0 1} {3 3 1 10} {5 4 1.27659574 25} {0 0 0 30} {35 40 11 42}"; h.String() != expected {
+		t.Fatalf("expected %s, got %s", expected, h.String())
+	}
+
+	testData := []struct {
+		constraint string
+		buckets    string
+		rowCount   float64
+		distinct   float64
+	}{
+		{
+			constraint: "/1: [/10 - /10]",
+			buckets:    "{3 0 0 10}",
+			rowCount:   3,
+			distinct:   1,
+		},
+		{
+			// The value is inside the range of the last bucket, so it is assumed to
+			// have the average frequency of the values in the range.
+			constraint: "/1: [/35 - /35]",
+			buckets:    "{3.63636364 0 0 35}",
+			rowCount:   3.63636364,
+			distinct:   1,
+		},
+		{
+			constraint: "/1: [/0 - /0]",
+			buckets:    "{0 0 0 0}",
+			rowCount:   0,
+			distinct:   0,
+		},
+		{
+			constraint: "/1: [/32 - ]",
+			buckets:    "{3.63636364 0 0 32} {35 32.7272727 9 42}",
+			rowCount:   71.3636364,
+			distinct:   11,
+		},
+		{
+			constraint: "/1: [/10 - /25] [/42 - /42]",
+			buckets:    "{3 0 0 10} {5 4 1.27659574 25} {35 0 0 42}",
+			rowCount:   47,
+			distinct:   4.27659574,
+		},
+		{
+			constraint: "/1: (/NULL - /1]",
+			buckets:    "{1 0 0 1}",
+			rowCount:   1,
+			distinct:   1,
+		},
+		{
+			constraint: "/-1: [/25 - /10]",
+			buckets:    "{3 0 0 10} {5 4 1.27659574 25}",
+			rowCount:   12,
+			distinct:   3.27659574,
+		},
+		{
+			constraint: "/1/2: [/5/1 - /5/2]",
+			buckets:    "{3 0 0 5}",
+			rowCount:   3,
+			distinct:   1,
+		},
+	}
+
+	for i := range testData {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			c := constraint.ParseConstraint(&evalCtx, testData[i].constraint)
+			filtered := h.Filter(&c)
+			if filtered.String() != testData[i].buckets {
+				t.Fatalf("expected %s, got %s", testData[i].buckets, filtered.String())
+			}
+			if rowCount := fmt.Sprintf("%.9g", filtered.RowCount()); rowCount != fmt.Sprintf("%.9g", testData[i].rowCount) {
+				t.Fatalf("expected %g rows, got %s", testData[i].rowCount, rowCount)
+			}
+			if distinct := fmt.Sprintf("%.9g", filtered.DistinctCount()); distinct != fmt.Sprintf("%.9g", testData[i].distinct) {
+				t.Fatalf("expected %g distinct values, got %s", testData[i].distinct, distinct)
+			}
+		})
+	}
+
+	// Histograms cannot describe NULL values.
+	c := constraint.ParseConstraint(&evalCtx, "/1: [/NULL - /5]")
+	if filtered := h.Filter(&c); filtered != nil {
+		t.Fatalf("expected nil histogram, got %s", filtered)
+	}
+
+	// Scaling a histogram scales the row counts of all buckets.
+	if scaled := h.ApplySelectivity(0.5); scaled.RowCount() != 45.5 {
+		t.Fatalf("expected 45.5 rows, got %g", scaled.RowCount())
+	}
+
+	// The value range of the histogram spans its non-empty buckets.
+	if lo, hi, ok := h.ValueRange(); !ok || lo.String() != "1" || hi.String() != "42" {
+		t.Fatalf("expected range [1 - 42], got [%s - %s]", lo, hi)
+	}
+	filtered := h.FilterRange(tree.NewDInt(5), tree.NewDInt(25))
+	if expected := "{3 0 0 5} {3 1.5 0.5 10} {5 4 1.27659574 25}"; filtered.String() != expected {
+		t.Fatalf("expected %s, got %s", expected, filtered.String())
+	}
+	if lo, hi, ok := filtered.ValueRange(); !ok || lo.String() != "5" || hi.String() != "25" {
+		t.Fatalf("expected range [5 - 25], got [%s - %s]", lo, hi)
+	}
+	if _, _, ok := h.FilterRange(tree.NewDInt(26), tree.NewDInt(29)).ValueRange(); ok {
+		t.Fatalf("expected empty histogram")
+	}
+}
@@ -134,6 +134,12 @@ type Relational struct {
 	// rows returned by the expression.
 	Cardinality Cardinality
 
+	// JoinSize is the number of inner joins in the tree of inner joins rooted at
+	// this relational expression (so it is zero for any expression other than
+	// an inner join). It is used to bound the number of joins that are
+	// reordered by the AssociateJoin exploration rule.
+	JoinSize int
+
 	// Stats is the set of statistics that apply to this relational expression.
 	// See statistics.go and memo/statistics_builder.go for more details.
 	Stats Statistics
@@ -120,7 +120,9 @@ func (p Presentation) Any() bool {
 // Equals returns true iff this presentation exactly matches the given
 // presentation.
 func (p Presentation) Equals(rhs Presentation) bool {
-	if len(p) != len(rhs) {
+	// An empty presentation (which requires that no columns are returned) is
+	// not the same as an undefined presentation.
+	if p.Any() != rhs.Any() || len(p) != len(rhs) {
 		return false
 	}
 
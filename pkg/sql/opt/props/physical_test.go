@@ -50,6 +50,10 @@ func TestPhysicalProps(t *testing.T) {
 		t.Error("presentation should not equal the empty presentation")
 	}
 
+	if props.Presentation(nil).Equals(props.Presentation{}) {
+		t.Error("undefined presentation should not equal the empty presentation")
+	}
+
 	// Add ordering props.
 	ordering := props.ParseOrderingChoice("+1,+5")
 	phys.Ordering = ordering
@@ -100,6 +100,23 @@ func (s *Statistics) Init(relProps *Relational) (zeroCardinality bool) {
 	return false
 }
 
+// CopyFrom initializes the statistics as a copy of the given statistics.
+// Column statistics are copied as well, so that they can be added and updated
+// independently of the original.
+func (s *Statistics) CopyFrom(other *Statistics) {
+	*s = *other
+	s.ColStats = make(map[opt.ColumnID]*ColumnStatistic, len(other.ColStats))
+	for col, colStat := range other.ColStats {
+		colStatCopy := *colStat
+		s.ColStats[col] = &colStatCopy
+	}
+	s.MultiColStats = make(map[string]*ColumnStatistic, len(other.MultiColStats))
+	for cols, colStat := range other.MultiColStats {
+		colStatCopy := *colStat
+		s.MultiColStats[cols] = &colStatCopy
+	}
+}
+
 // ColumnStatistic is a collection of statistics that applies to a particular
 // set of columns. In theory, a table could have a ColumnStatistic object
 // for every possible subset of columns. In practice, it is only worth
@@ -113,6 +130,18 @@ type ColumnStatistic struct {
 	// DistinctCount is the estimated number of distinct values of this
 	// set of columns for this expression.
 	DistinctCount float64
+
+	// Histogram is the estimated distribution of the values of the column for
+	// this expression. It is nil if the statistic has more than one column or
+	// if no histogram is available.
+	Histogram *Histogram
+
+	// HistogramFiltered is true if Histogram was filtered by the conditions of
+	// the expression the statistic belongs to, rather than copied (and possibly
+	// scaled) from the statistic of its input. The selectivity of the
+	// conditions on the column is then estimated from the fraction of the rows
+	// of the input histogram that remain in the filtered histogram.
+	HistogramFiltered bool
 }
 
 // ColumnStatistics is a slice of ColumnStatistic values.
@@ -17,6 +17,7 @@ package testutils
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -87,18 +88,29 @@ type OptTesterFlags struct {
 	// ExploreTraceRule restricts the ExploreTrace output to only show the effects
 	// of a specific rule.
 	ExploreTraceRule string
+
+	// JoinLimit is the maximum number of joins in a tree of inner joins that
+	// the optimizer reorders (the reorder_joins_limit session setting).
+	JoinLimit int
 }
 
 // NewOptTester constructs a new instance of the OptTester for the given SQL
 // statement. Metadata used by the SQL query is accessed via the catalog.
 func NewOptTester(catalog opt.Catalog, sql string) *OptTester {
-	return &OptTester{
+	ot := &OptTester{
 		catalog: catalog,
 		sql:     sql,
 		ctx:     context.Background(),
 		semaCtx: tree.MakeSemaContext(false /* privileged */),
 		evalCtx: tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings()),
 	}
+	ot.Flags.JoinLimit = xform.DefaultReorderJoinsLimit
+	if testCatalog, ok := catalog.(*testcat.Catalog); ok {
+		// Calls to the functions created in the test catalog are resolved
+		// during type checking.
+		ot.semaCtx.FunctionResolver = testCatalog
+	}
+	return ot
 }
 
 // RunCommand implements commands that are used by most tests:
@@ -158,6 +170,10 @@ func NewOptTester(catalog opt.Catalog, sql string) *OptTester {
 //    specified, the exploretrace output is filtered to only show expression
 //    changes due to that specific rule.
 //
+//  - join-limit: sets the value of the reorder_joins_limit session setting
+//    used by the optimizer. Example:
+//      opt join-limit=0
+//
 func (ot *OptTester) RunCommand(tb testing.TB, d *datadriven.TestData) string {
 	// Allow testcases to override the flags.
 	for _, a := range d.CmdArgs {
@@ -167,6 +183,7 @@ func (ot *OptTester) RunCommand(tb testing.TB, d *datadriven.TestData) string {
 	}
 
 	ot.Flags.Verbose = testing.Verbose()
+	ot.evalCtx.SessionData.ReorderJoinsLimit = ot.Flags.JoinLimit
 
 	switch d.Cmd {
 	case "exec-ddl":
@@ -302,6 +319,16 @@ func (f *OptTesterFlags) Set(arg datadriven.CmdArg) error {
 		}
 		f.ExploreTraceRule = arg.Vals[0]
 
+	case "join-limit":
+		if len(arg.Vals) != 1 {
+			return fmt.Errorf("join-limit requires one argument")
+		}
+		limit, err := strconv.ParseInt(arg.Vals[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		f.JoinLimit = int(limit)
+
 	default:
 		return fmt.Errorf("unknown argument: %s", arg.Key)
 	}
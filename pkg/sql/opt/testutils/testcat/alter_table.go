@@ -20,6 +20,9 @@ import (
 	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -80,7 +83,11 @@ func injectTableStats(tt *Table, statsExpr tree.Expr) {
 	}
 	tt.Stats = make([]*TableStat, len(stats))
 	for i := range stats {
-		tt.Stats[i] = &TableStat{js: stats[i], tt: tt}
+		tt.Stats[i] = &TableStat{
+			js:        stats[i],
+			tt:        tt,
+			histogram: makeHistogram(&evalCtx, &stats[i]),
+		}
 	}
 	// Call ColumnOrdinal on all possible columns to assert that
 	// the column names are valid.
@@ -93,3 +100,30 @@ func injectTableStats(tt *Table, statsExpr tree.Expr) {
 	// Finally, sort the stats with most recent first.
 	sort.Sort(tt.Stats)
 }
+
+// makeHistogram converts the histogram of the given JSON statistic, if it has
+// one, into histogram buckets.
+func makeHistogram(evalCtx *tree.EvalContext, js *stats.JSONStatistic) []opt.HistogramBucket {
+	if len(js.HistogramBuckets) == 0 {
+		return nil
+	}
+	colType, err := parser.ParseType(js.HistogramColumnType)
+	if err != nil {
+		panic(err)
+	}
+	typ := coltypes.CastTargetToDatumType(colType)
+	buckets := make([]opt.HistogramBucket, len(js.HistogramBuckets))
+	for i := range js.HistogramBuckets {
+		b := &js.HistogramBuckets[i]
+		upperBound, err := tree.ParseStringAs(typ, b.UpperBound, evalCtx)
+		if err != nil {
+			panic(err)
+		}
+		buckets[i] = opt.HistogramBucket{
+			NumEq:      uint64(b.NumEq),
+			NumRange:   uint64(b.NumRange),
+			UpperBound: upperBound,
+		}
+	}
+	return buckets
+}
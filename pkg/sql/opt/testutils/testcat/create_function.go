@@ -0,0 +1,93 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testcat
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/pkg/errors"
+)
+
+// CreateFunction is a partial implementation of the CREATE FUNCTION statement.
+// Only the information needed to type check and inline calls is kept: the
+// functions cannot be evaluated.
+func (tc *Catalog) CreateFunction(stmt *tree.CreateFunction) {
+	tn, err := stmt.Name.Normalize()
+	if err != nil {
+		panic(err)
+	}
+	name := string(tn.TableName)
+	if _, ok := tc.functions[name]; ok {
+		panic(fmt.Errorf("function %q already exists", name))
+	}
+
+	argTypes := make(tree.ArgTypes, len(stmt.Args))
+	for i, arg := range stmt.Args {
+		argTypes[i].Name = string(arg.Name)
+		if argTypes[i].Name == "" {
+			argTypes[i].Name = "$" + strconv.Itoa(i+1)
+		}
+		argTypes[i].Typ = coltypes.CastTargetToDatumType(arg.Type)
+	}
+
+	var body string
+	immutable := false
+	props := tree.FunctionProperties{NullableArgs: true}
+	for _, opt := range stmt.Options {
+		switch opt.Name {
+		case tree.FuncOptAs:
+			body = opt.StrVal
+		case tree.FuncOptImmutable:
+			immutable = true
+		case tree.FuncOptVolatile:
+			props.Impure = true
+			props.NeedsRepeatedEvaluation = true
+		case tree.FuncOptStrict, tree.FuncOptReturnsNullOnNullInput:
+			props.NullableArgs = false
+		}
+	}
+
+	overload := tree.Overload{
+		Types:      argTypes,
+		ReturnType: tree.FixedReturnType(coltypes.CastTargetToDatumType(stmt.ReturnType)),
+		Fn: func(*tree.EvalContext, tree.Datums) (tree.Datum, error) {
+			return nil, errors.Errorf("cannot evaluate function %s in the test catalog", name)
+		},
+	}
+	if immutable && props.NullableArgs {
+		stmt, err := parser.ParseOne(body)
+		if err != nil {
+			panic(err)
+		}
+		if sel, ok := stmt.(*tree.Select); ok {
+			if clause, ok := sel.Select.(*tree.SelectClause); ok && len(clause.Exprs) == 1 {
+				overload.InlineBody = clause.Exprs[0].Expr
+			}
+		}
+	}
+	tc.functions[name] = tree.NewFunctionDefinition(name, &props, []tree.Overload{overload})
+}
+
+// ResolveFunction is part of the tree.FunctionResolver interface.
+func (tc *Catalog) ResolveFunction(name *tree.UnresolvedName) (*tree.FunctionDefinition, error) {
+	if name.NumParts != 1 {
+		return nil, nil
+	}
+	return tc.functions[name.Parts[0]], nil
+}
@@ -125,14 +125,20 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 func (tt *Table) addColumn(def *tree.ColumnTableDef) {
 	nullable := !def.PrimaryKey && def.Nullable.Nullability != tree.NotNull
 	typ := coltypes.CastTargetToDatumType(def.Type)
-	col := &Column{Name: string(def.Name), Type: typ, Nullable: nullable}
+	col := &Column{
+		Name:     string(def.Name),
+		Type:     typ,
+		Nullable: nullable,
+		Computed: def.IsComputed(),
+	}
 	tt.Columns = append(tt.Columns, col)
 }
 
 func (tt *Table) addIndex(def *tree.IndexTableDef, typ indexType) {
 	idx := &Index{
-		Name:     tt.makeIndexName(def.Name, typ),
-		Inverted: def.Inverted,
+		Name:          tt.makeIndexName(def.Name, typ),
+		Inverted:      def.Inverted,
+		PredicateExpr: def.Predicate,
 	}
 
 	// Add explicit columns and mark primary key columns as not null.
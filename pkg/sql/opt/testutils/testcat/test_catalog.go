@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -31,14 +32,19 @@ import (
 
 // Catalog implements the opt.Catalog interface for testing purposes.
 type Catalog struct {
-	tables map[string]*Table
+	tables    map[string]*Table
+	functions map[string]*tree.FunctionDefinition
 }
 
 var _ opt.Catalog = &Catalog{}
+var _ tree.FunctionResolver = &Catalog{}
 
 // New creates a new empty instance of the test catalog.
 func New() *Catalog {
-	return &Catalog{tables: make(map[string]*Table)}
+	return &Catalog{
+		tables:    make(map[string]*Table),
+		functions: make(map[string]*tree.FunctionDefinition),
+	}
 }
 
 const (
@@ -89,6 +95,12 @@ func (tc *Catalog) FindTable(ctx context.Context, name *tree.TableName) (opt.Tab
 	return tc.findTable(&toFind, name)
 }
 
+// CheckPrivilege is part of the opt.Catalog interface. The test catalog does
+// not model users, so all privileges are granted.
+func (tc *Catalog) CheckPrivilege(ctx context.Context, tab opt.Table, priv privilege.Kind) error {
+	return nil
+}
+
 // findTable checks if the table `toFind` exists among the tables in this
 // Catalog. If it does, findTable updates `name` to match `toFind`, and
 // returns the corresponding table. Otherwise, it returns an error.
@@ -144,6 +156,10 @@ func (tc *Catalog) ExecuteDDL(sql string) (string, error) {
 		tc.DropTable(stmt)
 		return "", nil
 
+	case *tree.CreateFunction:
+		tc.CreateFunction(stmt)
+		return "", nil
+
 	default:
 		return "", fmt.Errorf("expected CREATE TABLE or ALTER TABLE statement but found: %v", stmt)
 	}
@@ -208,6 +224,11 @@ func (tt *Table) IsVirtualTable() bool {
 	return tt.IsVirtual
 }
 
+// HasPendingMutations is part of the opt.Table interface.
+func (tt *Table) HasPendingMutations() bool {
+	return false
+}
+
 // ColumnCount is part of the opt.Table interface.
 func (tt *Table) ColumnCount() int {
 	return len(tt.Columns)
@@ -277,6 +298,10 @@ type Index struct {
 
 	// Inverted is true when this index is an inverted index.
 	Inverted bool
+
+	// PredicateExpr is the predicate of a partial index, or nil if the index is
+	// not partial.
+	PredicateExpr tree.Expr
 }
 
 // IdxName is part of the opt.Index interface.
@@ -309,10 +334,16 @@ func (ti *Index) Column(i int) opt.IndexColumn {
 	return ti.Columns[i]
 }
 
+// Predicate is part of the opt.Index interface.
+func (ti *Index) Predicate() tree.Expr {
+	return ti.PredicateExpr
+}
+
 // Column implements the opt.Column interface for testing purposes.
 type Column struct {
 	Hidden   bool
 	Nullable bool
+	Computed bool
 	Name     string
 	Type     types.T
 }
@@ -339,10 +370,16 @@ func (tc *Column) IsHidden() bool {
 	return tc.Hidden
 }
 
+// IsComputed is part of the opt.Column interface.
+func (tc *Column) IsComputed() bool {
+	return tc.Computed
+}
+
 // TableStat implements the opt.TableStatistic interface for testing purposes.
 type TableStat struct {
-	js stats.JSONStatistic
-	tt *Table
+	js        stats.JSONStatistic
+	tt        *Table
+	histogram []opt.HistogramBucket
 }
 
 var _ opt.TableStatistic = &TableStat{}
@@ -381,6 +418,11 @@ func (ts *TableStat) NullCount() uint64 {
 	return ts.js.NullCount
 }
 
+// Histogram is part of the opt.TableStatistic interface.
+func (ts *TableStat) Histogram() []opt.HistogramBucket {
+	return ts.histogram
+}
+
 // TableStats is a slice of TableStat pointers.
 type TableStats []*TableStat
 
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 )
 
 // Coster is used by the optimizer to assign a cost to a candidate expression
@@ -47,7 +48,8 @@ type Coster interface {
 // and index statistics that are propagated throughout the logical expression
 // tree.
 type coster struct {
-	mem *memo.Memo
+	evalCtx *tree.EvalContext
+	mem     *memo.Memo
 }
 
 const (
@@ -60,6 +62,10 @@ const (
 	cpuCostFactor    = 0.01
 	seqIOCostFactor  = 1
 	randIOCostFactor = 4
+
+	// joinHintPenalty is added to the cost of join expressions that do not
+	// satisfy the join hint of their group (see memo.JoinHint).
+	joinHintPenalty = 1e100
 )
 
 // computeCost calculates the estimated cost of the candidate best expression,
@@ -106,6 +112,9 @@ func (c *coster) ComputeCost(candidate *memo.BestExpr, logical *props.Logical) m
 	case opt.LookupJoinOp:
 		cost = c.computeLookupJoinCost(candidate, logical)
 
+	case opt.ZigzagJoinOp:
+		cost = c.computeZigzagJoinCost(candidate, logical)
+
 	case opt.UnionOp, opt.IntersectOp, opt.ExceptOp,
 		opt.UnionAllOp, opt.IntersectAllOp, opt.ExceptAllOp:
 		cost = c.computeSetOpCost(candidate, logical)
@@ -125,6 +134,12 @@ func (c *coster) ComputeCost(candidate *memo.BestExpr, logical *props.Logical) m
 	case opt.ZipOp:
 		cost = c.computeZipCost(candidate, logical)
 
+	case opt.WindowOp:
+		cost = c.computeWindowCost(candidate, logical)
+
+	case opt.WithScanOp:
+		cost = c.computeWithScanCost(candidate, logical)
+
 	case opt.ExplainOp:
 		// Technically, the cost of an Explain operation is independent of the cost
 		// of the underlying plan. However, we want to explain the plan we would get
@@ -136,6 +151,12 @@ func (c *coster) ComputeCost(candidate *memo.BestExpr, logical *props.Logical) m
 		// By default, cost of parent is sum of child costs.
 		cost = c.computeChildrenCost(candidate)
 	}
+	if !c.mem.JoinHint(candidate.Group()).Allows(candidate.Operator()) {
+		// The join hint of the group forces a different join algorithm. The
+		// penalty is large enough that the expression will only be chosen if
+		// no expression in the group satisfies the hint.
+		cost += joinHintPenalty
+	}
 	if !cost.Less(memo.MaxCost) {
 		// Optsteps uses MaxCost to suppress expressions in the memo. When an
 		// expression with MaxCost is added to the memo, it can lead to an obscure
@@ -303,6 +324,34 @@ func (c *coster) computeLookupJoinCost(candidate *memo.BestExpr, logical *props.
 	return cost
 }
 
+func (c *coster) computeZigzagJoinCost(candidate *memo.BestExpr, logical *props.Logical) memo.Cost {
+	rowCount := logical.Relational.Stats.RowCount
+	def := candidate.Private(c.mem).(*memo.ZigzagJoinDef)
+
+	// The zigzag join alternates between the two indexes, seeking into one
+	// index using the primary key of the last row read from the other, and
+	// reading a row after each seek. Each seek skips at least one row of the
+	// index, so the number of seeks into each index is bounded by the number of
+	// rows in the smaller of the two constrained index scans. The seeks only
+	// move forward within the constrained span of each index, so they count as
+	// sequential I/O.
+	//
+	// TODO(optimizer): the number of seeks depends on how the rows of the two
+	// indexes are interleaved, which is not captured by the statistics. For
+	// now, assume the worst case.
+	leftRows, rightRows := c.mem.ZigzagJoinScanRowCounts(c.evalCtx, def)
+	numSeeks := 1 + math.Min(leftRows, rightRows)
+	numCols := def.Cols.Len()
+	perSeekCost := 2*seqIOCostFactor +
+		c.rowScanCost(def.Table, def.LeftIndex, numCols) +
+		c.rowScanCost(def.Table, def.RightIndex, numCols)
+	cost := memo.Cost(numSeeks) * perSeekCost
+
+	// Add the CPU cost of emitting the rows.
+	cost += memo.Cost(rowCount) * cpuCostFactor
+	return cost + c.computeChildrenCost(candidate)
+}
+
 func (c *coster) computeSetOpCost(candidate *memo.BestExpr, logical *props.Logical) memo.Cost {
 	// Add the CPU cost of emitting the rows.
 	cost := memo.Cost(logical.Relational.Stats.RowCount) * cpuCostFactor
@@ -361,6 +410,21 @@ func (c *coster) computeZipCost(candidate *memo.BestExpr, logical *props.Logical
 	return cost + c.computeChildrenCost(candidate)
 }
 
+func (c *coster) computeWindowCost(candidate *memo.BestExpr, logical *props.Logical) memo.Cost {
+	// Add the CPU cost of buffering the rows and computing each window function
+	// over them.
+	rowCount := memo.Cost(logical.Relational.Stats.RowCount)
+	numFns := memo.Cost(memo.MakeExprView(c.mem, candidate.Child(1)).ChildCount())
+	cost := rowCount * cpuCostFactor * (numFns + 1)
+	return cost + c.computeChildrenCost(candidate)
+}
+
+func (c *coster) computeWithScanCost(candidate *memo.BestExpr, logical *props.Logical) memo.Cost {
+	// Add the CPU cost of emitting the buffered rows. The cost of computing the
+	// binding is accounted for by the With operator.
+	return memo.Cost(logical.Relational.Stats.RowCount) * cpuCostFactor
+}
+
 func (c *coster) computeChildrenCost(candidate *memo.BestExpr) memo.Cost {
 	var cost memo.Cost
 	for i := 0; i < candidate.ChildCount(); i++ {
@@ -15,12 +15,15 @@
 package xform
 
 import (
+	"sort"
+
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/idxconstraint"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 )
 
@@ -94,6 +97,11 @@ func (c *CustomFuncs) GenerateIndexScans(def memo.PrivateID) []memo.Expr {
 			// Ignore inverted indexes.
 			continue
 		}
+		if tab.Index(i).Predicate() != nil {
+			// Ignore partial indexes, which can only be scanned when the filter
+			// implies their predicate. See GeneratePartialIndexScans.
+			continue
+		}
 		indexCols := md.IndexColumns(scanOpDef.Table, i)
 
 		// If the alternate index includes the set of needed columns (def.Cols),
@@ -189,7 +197,7 @@ func (c *CustomFuncs) GenerateInvertedIndexScans(
 		preDef := &memo.ScanOpDef{
 			Table: scanOpDef.Table,
 			Index: i,
-			// Though the index is marked as containing the JSONB column being
+			// Though the index is marked as containing the JSONB or ARRAY column being
 			// indexed, it doesn't actually, and it's only valid to extract the
 			// primary key columns from it.
 			Cols: pkColSet,
@@ -229,6 +237,115 @@ func (c *CustomFuncs) GenerateInvertedIndexScans(
 	return c.e.exprs
 }
 
+// CanGeneratePartialIndexScans returns true if new index Scan operators can
+// be generated on partial indexes. Same as CanGenerateIndexScans, but with the
+// additional check that we have at least one partial index on the table.
+func (c *CustomFuncs) CanGeneratePartialIndexScans(def memo.PrivateID) bool {
+	if !c.CanGenerateIndexScans(def) {
+		return false
+	}
+
+	// Don't bother matching unless there's a partial index.
+	scanOpDef := c.e.mem.LookupPrivate(def).(*memo.ScanOpDef)
+	preds, _ := c.e.mem.Metadata().TableAnnotation(
+		scanOpDef.Table, memo.PartialIndexPredicatesAnnID,
+	).(memo.PartialIndexPredicates)
+	return len(preds) != 0
+}
+
+// GeneratePartialIndexScans enumerates all partial indexes on the scan
+// operator's table and generates an alternate scan operator for each partial
+// index whose predicate is implied by the filter. As in GenerateIndexScans,
+// the scan is wrapped in an index join if the index does not include the set
+// of needed columns. The conjuncts of the filter which are not conjuncts of
+// the predicate are applied by a Select on top of the scan; if there are none,
+// the scan is added to the same group as the original Select.
+func (c *CustomFuncs) GeneratePartialIndexScans(
+	def memo.PrivateID, filter memo.GroupID,
+) []memo.Expr {
+	c.e.exprs = c.e.exprs[:0]
+	scanOpDef := c.e.mem.LookupPrivate(def).(*memo.ScanOpDef)
+	md := c.e.mem.Metadata()
+	tab := md.Table(scanOpDef.Table)
+
+	var pkCols opt.ColList
+	for i := 1; i < tab.IndexCount(); i++ {
+		pred, ok := c.e.mem.PartialIndexPredicate(scanOpDef.Table, i)
+		if !ok {
+			continue
+		}
+		remaining, ok := c.e.mem.ImpliesPartialIndexPredicate(c.e.evalCtx, filter, pred)
+		if !ok {
+			continue
+		}
+
+		var remainingFilter memo.GroupID
+		if len(remaining) != 0 {
+			remainingFilter = c.e.f.ConstructFilters(c.e.f.InternList(remaining))
+		}
+
+		indexCols := md.IndexColumns(scanOpDef.Table, i)
+		for _, reverse := range []bool{false, true} {
+			// If there is no remaining filter, the scan is added to the group of
+			// the original select. Otherwise, the scan is constructed in a new
+			// group and a select node is added to the group of the original select.
+			var scan memo.Expr
+			var scanGroup memo.GroupID
+			if scanOpDef.Cols.SubsetOf(indexCols) {
+				private := c.e.mem.InternScanOpDef(&memo.ScanOpDef{
+					Table:   scanOpDef.Table,
+					Index:   i,
+					Cols:    scanOpDef.Cols,
+					Reverse: reverse,
+				})
+				if remainingFilter == 0 {
+					scan = memo.Expr(memo.MakeScanExpr(private))
+				} else {
+					scanGroup = c.e.f.ConstructScan(private)
+				}
+			} else {
+				// The partial index was missing columns, so in order to satisfy
+				// the requirements, we need to perform an index join with the
+				// primary index.
+				if pkCols == nil {
+					primaryIndex := tab.Index(opt.PrimaryIndex)
+					pkCols = make(opt.ColList, primaryIndex.KeyColumnCount())
+					for i := range pkCols {
+						pkCols[i] = scanOpDef.Table.ColumnID(primaryIndex.Column(i).Ordinal)
+					}
+				}
+				scanCols := indexCols.Intersection(scanOpDef.Cols)
+				for _, c := range pkCols {
+					scanCols.Add(int(c))
+				}
+				input := c.e.f.ConstructScan(c.e.mem.InternScanOpDef(&memo.ScanOpDef{
+					Table:   scanOpDef.Table,
+					Index:   i,
+					Cols:    scanCols,
+					Reverse: reverse,
+				}))
+				private := c.e.mem.InternIndexJoinDef(&memo.IndexJoinDef{
+					Table: scanOpDef.Table,
+					Cols:  scanOpDef.Cols,
+				})
+				if remainingFilter == 0 {
+					scan = memo.Expr(memo.MakeIndexJoinExpr(input, private))
+				} else {
+					scanGroup = c.e.f.ConstructIndexJoin(input, private)
+				}
+			}
+
+			if remainingFilter == 0 {
+				c.e.exprs = append(c.e.exprs, scan)
+			} else {
+				c.e.exprs = append(c.e.exprs, memo.Expr(memo.MakeSelectExpr(scanGroup, remainingFilter)))
+			}
+		}
+	}
+
+	return c.e.exprs
+}
+
 // ----------------------------------------------------------------------
 //
 // Select Rules
@@ -368,6 +485,252 @@ func (c *CustomFuncs) ConstrainIndexJoinScan(
 	return c.e.exprs
 }
 
+// CanGenerateZigzagJoins returns true if zigzag joins can be generated from
+// the given scan. Like index scans, zigzag joins are only generated from the
+// original unaltered primary index Scan operator, and only if the table has at
+// least two secondary indexes.
+func (c *CustomFuncs) CanGenerateZigzagJoins(def memo.PrivateID) bool {
+	if !c.CanGenerateIndexScans(def) {
+		return false
+	}
+	scanOpDef := c.e.mem.LookupPrivate(def).(*memo.ScanOpDef)
+	return c.e.mem.Metadata().Table(scanOpDef.Table).IndexCount() > 2
+}
+
+// zigzagIndexInfo describes a secondary index that can be one side of a zigzag
+// join.
+type zigzagIndexInfo struct {
+	index int
+
+	// fixedCols are the columns in the prefix of the index that are fixed to
+	// constant values by the filter.
+	fixedCols opt.ColList
+
+	// eqCols are the key columns of the index that follow the fixed prefix.
+	eqCols opt.ColList
+}
+
+// GenerateZigzagJoins generates zigzag join alternatives for a Select over a
+// Scan. A zigzag join is possible between two secondary indexes when the
+// filter contains equality conditions between constants and a prefix of the
+// columns of each index, and the remaining key columns of both indexes are
+// the same (they include the primary key columns, on which the indexes are
+// joined). For example:
+//
+//   CREATE TABLE abc (a INT, b INT, c INT, INDEX (a), INDEX (b))
+//   SELECT * FROM abc WHERE a = 1 AND b = 2
+//
+// Both indexes are fixed to a single value, and are followed by the rowid
+// column, so they can be joined on rowid. The conditions of the filter that
+// can be evaluated on the columns of the zigzag join become its ON condition.
+// If the zigzag join does not produce all the columns needed by the Select, it
+// becomes the input of a lookup join into the primary index, which retrieves
+// the remaining columns and applies the remaining conditions.
+//
+// The zigzag join reads from each index only the rows that match its fixed
+// prefix, and can skip over the rows that have no match in the other index.
+// This makes it much cheaper than a constrained scan of either index when the
+// conditions on each column are not selective but their combination is.
+func (c *CustomFuncs) GenerateZigzagJoins(def memo.PrivateID, filter memo.GroupID) []memo.Expr {
+	c.e.exprs = c.e.exprs[:0]
+	filterExpr := c.e.mem.NormExpr(filter)
+	if filterExpr.Operator() != opt.FiltersOp {
+		// There must be at least two conditions.
+		return nil
+	}
+
+	scanOpDef := c.e.mem.LookupPrivate(def).(*memo.ScanOpDef)
+	md := c.e.mem.Metadata()
+	tab := md.Table(scanOpDef.Table)
+
+	// Find the columns that are fixed to constant values by the filter.
+	fixedVals := make(map[opt.ColumnID]memo.GroupID)
+	for _, cond := range c.e.mem.LookupList(filterExpr.AsFilters().Conditions()) {
+		ev := memo.MakeNormExprView(c.e.mem, cond)
+		if ev.Operator() != opt.EqOp {
+			continue
+		}
+		left, right := ev.Child(0), ev.Child(1)
+		if left.Operator() != opt.VariableOp || !right.IsConstValue() {
+			continue
+		}
+		col := left.Private().(opt.ColumnID)
+		if !scanOpDef.Cols.Contains(int(col)) {
+			continue
+		}
+		// The value is encoded using the type of the column, so the types must
+		// match exactly.
+		if !md.ColumnType(col).Equivalent(memo.ExtractConstDatum(right).ResolvedType()) {
+			continue
+		}
+		if _, ok := fixedVals[col]; !ok {
+			fixedVals[col] = right.Group()
+		}
+	}
+	if len(fixedVals) < 2 {
+		return nil
+	}
+
+	// The indexes are joined on the primary key columns.
+	primaryIndex := tab.Index(opt.PrimaryIndex)
+	var pkCols opt.ColSet
+	for i := 0; i < primaryIndex.KeyColumnCount(); i++ {
+		pkCols.Add(int(scanOpDef.Table.ColumnID(primaryIndex.Column(i).Ordinal)))
+	}
+
+	// Find the secondary indexes that have a fixed prefix, followed by key
+	// columns that include all the primary key columns.
+	var infos []zigzagIndexInfo
+	for i := 1; i < tab.IndexCount(); i++ {
+		index := tab.Index(i)
+		if index.IsInverted() || index.Predicate() != nil {
+			continue
+		}
+		if index.LaxKeyColumnCount() != index.KeyColumnCount() {
+			// The primary key columns of a UNIQUE index with nullable columns are
+			// only part of the key if one of the columns is NULL, but the fixed
+			// columns are never NULL.
+			continue
+		}
+		info := zigzagIndexInfo{index: i}
+		n := index.KeyColumnCount()
+		for j := 0; j < n; j++ {
+			colID := scanOpDef.Table.ColumnID(index.Column(j).Ordinal)
+			if _, ok := fixedVals[colID]; !ok {
+				break
+			}
+			info.fixedCols = append(info.fixedCols, colID)
+		}
+		if len(info.fixedCols) == 0 || len(info.fixedCols) == n {
+			continue
+		}
+		info.eqCols = make(opt.ColList, n-len(info.fixedCols))
+		for j := range info.eqCols {
+			info.eqCols[j] = scanOpDef.Table.ColumnID(index.Column(len(info.fixedCols) + j).Ordinal)
+		}
+		if !pkCols.SubsetOf(opt.ColListToSet(info.eqCols)) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	for i := range infos {
+		for j := i + 1; j < len(infos); j++ {
+			if expr, ok := c.makeZigzagJoin(scanOpDef, filter, fixedVals, &infos[i], &infos[j]); ok {
+				c.e.exprs = append(c.e.exprs, expr)
+			}
+		}
+	}
+	return c.e.exprs
+}
+
+// makeZigzagJoin returns a zigzag join between the given indexes, wrapped in
+// a lookup join and a Project or a Select as needed to produce the same result
+// as the Select over the given scan. It returns ok=false if the indexes cannot be
+// joined, or if a constrained scan of one of the indexes would be at least as
+// good as the zigzag join.
+func (c *CustomFuncs) makeZigzagJoin(
+	scanOpDef *memo.ScanOpDef,
+	filter memo.GroupID,
+	fixedVals map[opt.ColumnID]memo.GroupID,
+	left, right *zigzagIndexInfo,
+) (_ memo.Expr, ok bool) {
+	md := c.e.mem.Metadata()
+	tab := md.Table(scanOpDef.Table)
+	leftIndex, rightIndex := tab.Index(left.index), tab.Index(right.index)
+
+	// Both indexes must be ordered the same way on the equality columns.
+	if len(left.eqCols) != len(right.eqCols) {
+		return memo.Expr{}, false
+	}
+	for k := range left.eqCols {
+		leftCol := leftIndex.Column(len(left.fixedCols) + k)
+		rightCol := rightIndex.Column(len(right.fixedCols) + k)
+		if left.eqCols[k] != right.eqCols[k] || leftCol.Descending != rightCol.Descending {
+			return memo.Expr{}, false
+		}
+	}
+
+	// If all the fixed columns of one index are also fixed in the other index,
+	// the other index alone is at least as selective as the zigzag join.
+	leftFixed, rightFixed := opt.ColListToSet(left.fixedCols), opt.ColListToSet(right.fixedCols)
+	if leftFixed.SubsetOf(rightFixed) || rightFixed.SubsetOf(leftFixed) {
+		return memo.Expr{}, false
+	}
+
+	// The zigzag join produces the needed columns that are part of either
+	// index. If that is not all the needed columns, it also produces the
+	// primary key columns, so that a lookup join can retrieve the rest.
+	indexCols := md.IndexColumns(scanOpDef.Table, left.index)
+	indexCols.UnionWith(md.IndexColumns(scanOpDef.Table, right.index))
+	zigzagCols := scanOpDef.Cols.Intersection(indexCols)
+	needLookupJoin := !scanOpDef.Cols.SubsetOf(indexCols)
+	if needLookupJoin {
+		zigzagCols.UnionWith(opt.ColListToSet(left.eqCols))
+	}
+
+	vals := make([]memo.GroupID, 0, len(left.fixedCols)+len(right.fixedCols))
+	typ := types.TTuple{Types: make([]types.T, 0, cap(vals))}
+	for _, cols := range []opt.ColList{left.fixedCols, right.fixedCols} {
+		for _, col := range cols {
+			vals = append(vals, fixedVals[col])
+			typ.Types = append(typ.Types, md.ColumnType(col))
+		}
+	}
+
+	zigzagDef := c.e.mem.InternZigzagJoinDef(&memo.ZigzagJoinDef{
+		Table:          scanOpDef.Table,
+		LeftIndex:      left.index,
+		RightIndex:     right.index,
+		LeftFixedCols:  left.fixedCols,
+		RightFixedCols: right.fixedCols,
+		EqCols:         left.eqCols,
+		Cols:           zigzagCols,
+	})
+	on := c.ExtractBoundFilters(filter, zigzagCols)
+	fixedValsTuple := c.e.f.ConstructTuple(c.e.f.InternList(vals), c.e.f.InternType(typ))
+	remainingFilter := c.ExtractUnboundFilters(filter, zigzagCols)
+
+	if !needLookupJoin {
+		if c.e.mem.NormExpr(remainingFilter).Operator() == opt.TrueOp {
+			// Add the zigzag join to the select's group.
+			return memo.Expr(memo.MakeZigzagJoinExpr(on, fixedValsTuple, zigzagDef)), true
+		}
+		// Some conditions refer to outer columns; apply them in a Select.
+		zigzag := c.e.f.ConstructZigzagJoin(on, fixedValsTuple, zigzagDef)
+		return memo.Expr(memo.MakeSelectExpr(zigzag, remainingFilter)), true
+	}
+
+	// Look up the remaining columns in the primary index. An index join would
+	// require a Scan input, so use the more general lookup join.
+	pkIndex := tab.Index(opt.PrimaryIndex)
+	lookupJoinDef := memo.LookupJoinDef{
+		JoinType:   opt.InnerJoinOp,
+		Table:      scanOpDef.Table,
+		Index:      opt.PrimaryIndex,
+		KeyCols:    make(opt.ColList, pkIndex.KeyColumnCount()),
+		LookupCols: scanOpDef.Cols.Difference(zigzagCols),
+	}
+	for i := range lookupJoinDef.KeyCols {
+		lookupJoinDef.KeyCols[i] = scanOpDef.Table.ColumnID(pkIndex.Column(i).Ordinal)
+	}
+	zigzag := c.e.f.ConstructZigzagJoin(on, fixedValsTuple, zigzagDef)
+	lookupJoinDefID := c.e.mem.InternLookupJoinDef(&lookupJoinDef)
+	if zigzagCols.SubsetOf(scanOpDef.Cols) {
+		// Add the lookup join to the select's group.
+		return memo.Expr(memo.MakeLookupJoinExpr(zigzag, remainingFilter, lookupJoinDefID)), true
+	}
+
+	// The primary key columns are not needed by the Select, so project them
+	// away.
+	lookupJoin := c.e.f.ConstructLookupJoin(zigzag, remainingFilter, lookupJoinDefID)
+	projections := c.e.f.ConstructProjections(
+		memo.EmptyList,
+		c.e.mem.InternProjectionsOpDef(&memo.ProjectionsOpDef{PassthroughCols: scanOpDef.Cols}),
+	)
+	return memo.Expr(memo.MakeProjectExpr(lookupJoin, projections)), true
+}
+
 // ----------------------------------------------------------------------
 //
 // Limit Rules
@@ -679,6 +1042,65 @@ func (c *CustomFuncs) HoistIndexJoinDef(
 	return c.e.mem.InternLookupJoinDef(&lookupJoinDef)
 }
 
+// ShouldReorderJoins returns true if the AssociateJoin rule should be applied to
+// an inner join with the given inputs. The number of join orders that are
+// explored grows exponentially with the number of joins, so reordering is
+// limited to trees of at most reorder_joins_limit inner joins (a limit of zero
+// disables join reordering). Joins are not reordered in a query with join
+// hints, since the hinted joins would not be part of the new join trees; the
+// join order is fixed by the query instead.
+func (c *CustomFuncs) ShouldReorderJoins(left, right memo.GroupID) bool {
+	if c.e.mem.HasJoinHints() {
+		return false
+	}
+	joinSize := c.e.mem.GroupProperties(left).Relational.JoinSize +
+		c.e.mem.GroupProperties(right).Relational.JoinSize + 1
+	return joinSize <= c.e.evalCtx.SessionData.ReorderJoinsLimit
+}
+
+// ExtractBoundFilters returns a filter expression containing the conditions of
+// the given filter expression that are bound by the given columns (see
+// ExtractBoundConditions). The filter expression can be a Filters operator, in
+// which case its conditions are considered separately, or any other boolean
+// expression.
+func (c *CustomFuncs) ExtractBoundFilters(filters memo.GroupID, cols opt.ColSet) memo.GroupID {
+	return c.extractFilters(filters, cols, true /* bound */)
+}
+
+// ExtractUnboundFilters is the opposite of ExtractBoundFilters: it returns a
+// filter expression containing the conditions that are *not* bound by the given
+// columns.
+func (c *CustomFuncs) ExtractUnboundFilters(filters memo.GroupID, cols opt.ColSet) memo.GroupID {
+	return c.extractFilters(filters, cols, false /* bound */)
+}
+
+func (c *CustomFuncs) extractFilters(
+	filters memo.GroupID, cols opt.ColSet, bound bool,
+) memo.GroupID {
+	filtersExpr := c.e.mem.NormExpr(filters)
+	var conditions []memo.GroupID
+	switch filtersExpr.Operator() {
+	case opt.FiltersOp:
+		conditions = c.e.mem.LookupList(filtersExpr.AsFilters().Conditions())
+	case opt.TrueOp:
+	default:
+		conditions = []memo.GroupID{filters}
+	}
+
+	var extracted []memo.GroupID
+	for _, cond := range conditions {
+		if c.IsBoundBy(cond, cols) == bound {
+			extracted = append(extracted, cond)
+		}
+	}
+
+	// The same join can be reached by associating in different directions,
+	// which concatenates the conditions in different orders. Sort them so that
+	// equivalent filters are interned in the same memo group.
+	sort.Slice(extracted, func(i, j int) bool { return extracted[i] < extracted[j] })
+	return c.e.f.ConstructFilters(c.e.mem.InternList(extracted))
+}
+
 // ----------------------------------------------------------------------
 //
 // GroupBy Rules
@@ -16,11 +16,13 @@ package xform
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
 )
@@ -35,6 +37,11 @@ type MatchedRuleFunc = norm.MatchedRuleFunc
 // details.
 type AppliedRuleFunc = norm.AppliedRuleFunc
 
+// DefaultReorderJoinsLimit is the default value of the reorder_joins_limit
+// session setting: the maximum number of joins in a tree of inner joins for
+// which the optimizer explores the different join orders.
+const DefaultReorderJoinsLimit = 4
+
 // Optimizer transforms an input expression tree into the logically equivalent
 // output expression tree with the lowest possible execution cost.
 //
@@ -78,12 +85,19 @@ type Optimizer struct {
 
 // NewOptimizer constructs an instance of the optimizer.
 func NewOptimizer(evalCtx *tree.EvalContext) *Optimizer {
-	f := norm.NewFactory(evalCtx)
+	return NewOptimizerWithMemo(evalCtx, memo.New())
+}
+
+// NewOptimizerWithMemo constructs an instance of the optimizer that adds
+// expressions to the given memo, which may already contain a normalized
+// expression tree (e.g. a copy of a cached memo).
+func NewOptimizerWithMemo(evalCtx *tree.EvalContext, mem *memo.Memo) *Optimizer {
+	f := norm.NewFactoryWithMemo(evalCtx, mem)
 	o := &Optimizer{
 		evalCtx:  evalCtx,
 		f:        f,
 		mem:      f.Memo(),
-		coster:   coster{mem: f.Memo()},
+		coster:   coster{evalCtx: evalCtx, mem: f.Memo()},
 		stateMap: make(map[optStateKey]*optState),
 	}
 	o.explorer.init(o)
@@ -184,6 +198,31 @@ func (o *Optimizer) Optimize(root memo.GroupID, requiredProps *props.Physical) m
 	return ev
 }
 
+// CheckJoinHints returns an error if the optimized expression tree contains a
+// join that does not satisfy the join hint of its group. The coster penalizes
+// such joins, so this only happens when no join algorithm allowed by the hint
+// can be used (e.g. a lookup join without a suitable index).
+func (o *Optimizer) CheckJoinHints() error {
+	if !o.mem.HasJoinHints() {
+		return nil
+	}
+	return o.checkJoinHints(o.mem.Root())
+}
+
+func (o *Optimizer) checkJoinHints(ev memo.ExprView) error {
+	if hint := o.mem.JoinHint(ev.Group()); !hint.Allows(ev.Operator()) {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"could not produce a query plan conforming to the %s JOIN hint",
+			strings.ToUpper(hint.String()))
+	}
+	for i, n := 0, ev.ChildCount(); i < n; i++ {
+		if err := o.checkJoinHints(ev.Child(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // optimizeGroup enumerates expression trees rooted in the given memo group and
 // finds the expression tree with the lowest cost (i.e. the "best") that
 // provides the given required physical properties. Enforcers are added as
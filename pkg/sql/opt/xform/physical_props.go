@@ -107,6 +107,11 @@ func (o *Optimizer) canProvideOrdering(eid memo.ExprID, required *props.Ordering
 		// These operators require a certain ordering of their input, but can also
 		// pass through a stronger ordering.
 		return required.Intersects(o.internalOrdering(mexpr))
+
+	case opt.WithOp:
+		// With operator can always pass through ordering to its input (but not
+		// to its binding).
+		return true
 	}
 
 	return false
@@ -184,6 +189,11 @@ func (o *Optimizer) buildChildPhysicalProps(
 			childProps.Ordering = parentProps.Ordering.Intersection(o.internalOrdering(mexpr))
 		}
 
+	case opt.WithOp:
+		if nth == 1 {
+			childProps.Ordering = parentProps.Ordering
+		}
+
 	case opt.ExplainOp:
 		if nth == 0 {
 			childProps = o.mem.LookupPrivate(mexpr.AsExplain().Def()).(*memo.ExplainOpDef).Props
@@ -19,12 +19,15 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/pkg/errors"
 )
 
 // optCatalog implements the opt.Catalog interface over the SchemaResolver
@@ -40,9 +43,25 @@ type optCatalog struct {
 	// wrappers is a cache of table wrappers that's used to satisfy repeated
 	// calls to the FindTable method for the same table.
 	wrappers map[*sqlbase.TableDescriptor]*optTable
+
+	// privChecks records the privilege checks made through the catalog, so that
+	// they can be repeated when a cached plan is reused (see memoCache).
+	privChecks []optPrivilegeCheck
+
+	// functions records the user-defined functions resolved through the
+	// catalog, so that a cached plan is discarded when they change (see
+	// memoCache).
+	functions []IDVersion
+}
+
+// optPrivilegeCheck is a privilege check on a table made by the optimizer.
+type optPrivilegeCheck struct {
+	tableID sqlbase.ID
+	priv    privilege.Kind
 }
 
 var _ opt.Catalog = &optCatalog{}
+var _ tree.FunctionResolver = &optCatalog{}
 
 // init allows the optCatalog wrapper to be inlined.
 func (oc *optCatalog) init(statsCache *stats.TableStatisticsCache, resolver LogicalSchema) {
@@ -66,12 +85,6 @@ func (oc *optCatalog) FindTableByID(ctx context.Context, tableID int64) (opt.Tab
 		return nil, err
 	}
 
-	// TODO(madhavsuresh, knz): The way privileges are being checked is not satisfactory
-	// for future features. Privilege checking needs to be cleaned up.
-	if err := oc.resolver.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
-		return nil, err
-	}
-
 	if err := filterTableState(desc); err != nil {
 		return nil, err
 	}
@@ -87,7 +100,10 @@ func (oc *optCatalog) FindTableByID(ctx context.Context, tableID int64) (opt.Tab
 	wrapper, ok := oc.wrappers[desc]
 	if !ok {
 		tbName := tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(desc.Name))
-		wrapper = newOptTable(&tbName, oc.statsCache, desc)
+		wrapper, err = newOptTable(&tbName, oc.statsCache, desc)
+		if err != nil {
+			return nil, err
+		}
 		oc.wrappers[desc] = wrapper
 	}
 	return wrapper, nil
@@ -95,15 +111,14 @@ func (oc *optCatalog) FindTableByID(ctx context.Context, tableID int64) (opt.Tab
 
 // FindTable is part of the opt.Catalog interface.
 func (oc *optCatalog) FindTable(ctx context.Context, name *tree.TableName) (opt.Table, error) {
-	desc, err := ResolveExistingObject(ctx, oc.resolver, name, true /*required*/, requireTableDesc)
+	desc, err := ResolveExistingObject(ctx, oc.resolver, name, true /*required*/, anyDescType)
 	if err != nil {
 		return nil, err
 	}
-
-	// TODO(madhavsuresh, knz): The way privileges are being checked is not satisfactory
-	// for future features. Privilege checking needs to be cleaned up.
-	if err := oc.resolver.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
-		return nil, err
+	// Materialized views are stored like tables, so they can be scanned like
+	// tables as well.
+	if !desc.IsTable() && !desc.IsMaterializedView {
+		return nil, sqlbase.NewWrongObjectTypeError(name, requiredTypeNames[requireTableDesc])
 	}
 
 	// Check to see if there's already a wrapper for this table descriptor.
@@ -112,12 +127,45 @@ func (oc *optCatalog) FindTable(ctx context.Context, name *tree.TableName) (opt.
 	}
 	wrapper, ok := oc.wrappers[desc]
 	if !ok {
-		wrapper = newOptTable(name, oc.statsCache, desc)
+		var err error
+		wrapper, err = newOptTable(name, oc.statsCache, desc)
+		if err != nil {
+			return nil, err
+		}
 		oc.wrappers[desc] = wrapper
 	}
 	return wrapper, nil
 }
 
+// ResolveFunction is part of the tree.FunctionResolver interface. The
+// optimizer resolves function names through the catalog while it builds a
+// memo (see makeOptimizerPlan).
+func (oc *optCatalog) ResolveFunction(
+	name *tree.UnresolvedName,
+) (*tree.FunctionDefinition, error) {
+	desc, err := resolveFunctionDesc(context.TODO(), oc.resolver, name)
+	if err != nil || desc == nil {
+		return nil, err
+	}
+	found := false
+	for i := range oc.functions {
+		found = found || oc.functions[i].id == desc.ID
+	}
+	if !found {
+		oc.functions = append(oc.functions, NewIDVersion(desc.Name, desc.ID, desc.Version))
+	}
+	return makeFunctionDefinition(desc)
+}
+
+// CheckPrivilege is part of the opt.Catalog interface.
+func (oc *optCatalog) CheckPrivilege(
+	ctx context.Context, tab opt.Table, priv privilege.Kind,
+) error {
+	desc := tab.(*optTable).desc
+	oc.privChecks = append(oc.privChecks, optPrivilegeCheck{tableID: desc.ID, priv: priv})
+	return oc.resolver.CheckPrivilege(ctx, desc, priv)
+}
+
 // optTable is a wrapper around sqlbase.TableDescriptor that caches index
 // wrappers and maintains a ColumnID => Column mapping for fast lookup.
 type optTable struct {
@@ -143,28 +191,57 @@ type optTable struct {
 	// wrappers is a cache of index wrappers that's used to satisfy repeated
 	// calls to the SecondaryIndex method for the same index.
 	wrappers map[*sqlbase.IndexDescriptor]*optIndex
+
+	// predicates maps the ID of each partial index of the table to its parsed
+	// predicate.
+	predicates map[sqlbase.IndexID]tree.Expr
 }
 
 var _ opt.Table = &optTable{}
 
 func newOptTable(
 	name *tree.TableName, statsCache *stats.TableStatisticsCache, desc *sqlbase.TableDescriptor,
-) *optTable {
+) (*optTable, error) {
 	ot := &optTable{name: *name}
 
 	// The opt.Table interface requires that table names be fully qualified.
 	ot.name.ExplicitSchema = true
 	ot.name.ExplicitCatalog = true
 
-	ot.init(statsCache, desc)
-	return ot
+	if err := ot.init(statsCache, desc); err != nil {
+		return nil, err
+	}
+	return ot, nil
 }
 
 // init allows the optTable wrapper to be inlined.
-func (ot *optTable) init(statsCache *stats.TableStatisticsCache, desc *sqlbase.TableDescriptor) {
+func (ot *optTable) init(
+	statsCache *stats.TableStatisticsCache, desc *sqlbase.TableDescriptor,
+) error {
 	ot.desc = desc
-	ot.primary.init(ot, &desc.PrimaryIndex)
 	ot.statsCache = statsCache
+
+	// Parse the predicates of partial indexes up front, so that an invalid
+	// predicate is reported as an error rather than silently making the index
+	// unusable.
+	for i := range desc.Indexes {
+		idx := &desc.Indexes[i]
+		if !idx.IsPartial() {
+			continue
+		}
+		pred, err := parser.ParseExpr(idx.Predicate)
+		if err != nil {
+			return errors.Wrapf(err, "invalid predicate for partial index %q of table %q",
+				idx.Name, desc.Name)
+		}
+		if ot.predicates == nil {
+			ot.predicates = make(map[sqlbase.IndexID]tree.Expr)
+		}
+		ot.predicates[idx.ID] = pred
+	}
+
+	ot.primary.init(ot, &desc.PrimaryIndex)
+	return nil
 }
 
 // TabName is part of the opt.Table interface.
@@ -177,6 +254,11 @@ func (ot *optTable) IsVirtualTable() bool {
 	return ot.desc.IsVirtualTable()
 }
 
+// HasPendingMutations is part of the opt.Table interface.
+func (ot *optTable) HasPendingMutations() bool {
+	return len(ot.desc.Mutations) > 0
+}
+
 // ColumnCount is part of the opt.Table interface.
 func (ot *optTable) ColumnCount() int {
 	return len(ot.desc.Columns)
@@ -293,6 +375,10 @@ type optIndex struct {
 	numCols       int
 	numKeyCols    int
 	numLaxKeyCols int
+
+	// predicate is the parsed predicate of a partial index, or nil if the index
+	// is not partial.
+	predicate tree.Expr
 }
 
 var _ opt.Index = &optIndex{}
@@ -354,6 +440,10 @@ func (oi *optIndex) init(tab *optTable, desc *sqlbase.IndexDescriptor) {
 		oi.numLaxKeyCols = len(desc.ColumnIDs) + len(desc.ExtraColumnIDs)
 		oi.numKeyCols = oi.numLaxKeyCols
 	}
+
+	if desc.IsPartial() {
+		oi.predicate = tab.predicates[desc.ID]
+	}
 }
 
 // IdxName is part of the opt.Index interface.
@@ -381,6 +471,11 @@ func (oi *optIndex) LaxKeyColumnCount() int {
 	return oi.numLaxKeyCols
 }
 
+// Predicate is part of the opt.Index interface.
+func (oi *optIndex) Predicate() tree.Expr {
+	return oi.predicate
+}
+
 // Column is part of the opt.Index interface.
 func (oi *optIndex) Column(i int) opt.IndexColumn {
 	length := len(oi.desc.ColumnIDs)
@@ -411,6 +506,7 @@ type optTableStat struct {
 	rowCount       uint64
 	distinctCount  uint64
 	nullCount      uint64
+	histogram      []opt.HistogramBucket
 }
 
 var _ opt.TableStatistic = &optTableStat{}
@@ -431,9 +527,34 @@ func (os *optTableStat) init(tab *optTable, stat *stats.TableStatistic) (ok bool
 			return false
 		}
 	}
+	if stat.Histogram != nil && len(os.columnOrdinals) == 1 {
+		os.histogram = decodeHistogram(stat.Histogram)
+	}
 	return true
 }
 
+// decodeHistogram decodes the upper bounds of the buckets of the given
+// histogram. It returns nil if any of the bounds cannot be decoded, in which
+// case the optimizer falls back to estimates that don't use the histogram.
+func decodeHistogram(h *stats.HistogramData) []opt.HistogramBucket {
+	typ := h.ColumnType.ToDatumType()
+	buckets := make([]opt.HistogramBucket, len(h.Buckets))
+	var a sqlbase.DatumAlloc
+	for i := range h.Buckets {
+		b := &h.Buckets[i]
+		datum, _, err := sqlbase.DecodeTableKey(&a, typ, b.UpperBound, encoding.Ascending)
+		if err != nil {
+			return nil
+		}
+		buckets[i] = opt.HistogramBucket{
+			NumEq:      uint64(b.NumEq),
+			NumRange:   uint64(b.NumRange),
+			UpperBound: datum,
+		}
+	}
+	return buckets
+}
+
 // CreatedAt is part of the opt.TableStatistic interface.
 func (os *optTableStat) CreatedAt() time.Time {
 	return os.createdAt
@@ -463,3 +584,8 @@ func (os *optTableStat) DistinctCount() uint64 {
 func (os *optTableStat) NullCount() uint64 {
 	return os.nullCount
 }
+
+// Histogram is part of the opt.TableStatistic interface.
+func (os *optTableStat) Histogram() []opt.HistogramBucket {
+	return os.histogram
+}
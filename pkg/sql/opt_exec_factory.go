@@ -24,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -560,6 +562,73 @@ func (ef *execFactory) ConstructLookupJoin(
 	return n, nil
 }
 
+// ConstructZigzagJoin is part of the exec.Factory interface.
+func (ef *execFactory) ConstructZigzagJoin(
+	table opt.Table,
+	leftIndex opt.Index,
+	leftCols exec.ColumnOrdinalSet,
+	leftFixedVals tree.Datums,
+	leftEqCols []exec.ColumnOrdinal,
+	rightIndex opt.Index,
+	rightCols exec.ColumnOrdinalSet,
+	rightFixedVals tree.Datums,
+	rightEqCols []exec.ColumnOrdinal,
+	onCond tree.TypedExpr,
+) (exec.Node, error) {
+	tabDesc := table.(*optTable).desc
+	n := &zigzagJoinNode{}
+	if onCond != nil && onCond != tree.DBoolTrue {
+		n.onCond = onCond
+	}
+
+	makeSide := func(
+		index opt.Index, cols exec.ColumnOrdinalSet, fixedVals tree.Datums, eqCols []exec.ColumnOrdinal,
+	) (zigzagJoinSide, error) {
+		indexDesc := index.(*optIndex).desc
+		colCfg := scanColumnsConfig{
+			wantedColumns: make([]tree.ColumnID, 0, cols.Len()),
+		}
+		for c, ok := cols.Next(0); ok; c, ok = cols.Next(c + 1) {
+			colCfg.wantedColumns = append(colCfg.wantedColumns, tree.ColumnID(tabDesc.Columns[c].ID))
+		}
+
+		scan := ef.planner.Scan()
+		if err := scan.initTable(context.TODO(), ef.planner, tabDesc, nil, colCfg); err != nil {
+			return zigzagJoinSide{}, err
+		}
+		scan.index = indexDesc
+		scan.run.isSecondaryIndex = (indexDesc != &tabDesc.PrimaryIndex)
+		scan.disableBatchLimit()
+
+		side := zigzagJoinSide{
+			scan:      scan,
+			eqCols:    make([]int, len(eqCols)),
+			fixedVals: fixedVals,
+		}
+		for i, c := range eqCols {
+			side.eqCols[i] = int(c)
+		}
+		return side, nil
+	}
+
+	left, err := makeSide(leftIndex, leftCols, leftFixedVals, leftEqCols)
+	if err != nil {
+		return nil, err
+	}
+	right, err := makeSide(rightIndex, rightCols, rightFixedVals, rightEqCols)
+	if err != nil {
+		return nil, err
+	}
+	n.sides = []zigzagJoinSide{left, right}
+
+	leftScanCols := planColumns(left.scan)
+	rightScanCols := planColumns(right.scan)
+	n.columns = make(sqlbase.ResultColumns, 0, len(leftScanCols)+len(rightScanCols))
+	n.columns = append(n.columns, leftScanCols...)
+	n.columns = append(n.columns, rightScanCols...)
+	return n, nil
+}
+
 // ConstructLimit is part of the exec.Factory interface.
 func (ef *execFactory) ConstructLimit(
 	input exec.Node, limit, offset tree.TypedExpr,
@@ -611,12 +680,352 @@ func (ef *execFactory) ConstructProjectSet(
 	return p, nil
 }
 
+// ConstructWindow is part of the exec.Factory interface.
+func (ef *execFactory) ConstructWindow(root exec.Node, wi exec.WindowInfo) (exec.Node, error) {
+	numPassthrough := len(wi.Cols) - len(wi.Exprs)
+	p := &windowNode{
+		plan:         root.(planNode),
+		windowRender: make([]tree.TypedExpr, len(wi.Cols)),
+		funcs:        make([]*windowFuncHolder, len(wi.Exprs)),
+		run: windowRun{
+			values:       valuesNode{columns: wi.Cols},
+			windowFrames: make([]*tree.WindowFrame, len(wi.Exprs)),
+		},
+	}
+
+	partitionIdxs := make([]int, len(wi.Partition))
+	for i, idx := range wi.Partition {
+		partitionIdxs[i] = int(idx)
+	}
+
+	argIdxStart := numPassthrough
+	for i, expr := range wi.Exprs {
+		argIdxs := wi.ArgIdxs[i]
+		for j, idx := range argIdxs {
+			if int(idx) != argIdxStart+j {
+				return nil, errors.Errorf("arguments of window function %s must follow the previous ones", expr)
+			}
+		}
+		holder := &windowFuncHolder{
+			window:         p,
+			expr:           expr,
+			args:           expr.Exprs,
+			funcIdx:        i,
+			argIdxStart:    argIdxStart,
+			argCount:       len(argIdxs),
+			filterColIdx:   noFilterIdx,
+			partitionIdxs:  partitionIdxs,
+			columnOrdering: wi.Ordering,
+		}
+		argIdxStart += len(argIdxs)
+
+		p.funcs[i] = holder
+		p.windowRender[numPassthrough+i] = holder
+		p.run.windowFrames[i] = expr.WindowDef.Frame
+	}
+
+	// The window renders only contain windowFuncHolders, so no IndexedVars or
+	// aggregate functions need to be remapped above the windowing level.
+	p.colAndAggContainer = makeWindowNodeColAndAggContainer(&p.run, nil /* sourceInfo */, 0 /* numColVars */)
+
+	acc := ef.planner.EvalContext().Mon.MakeBoundAccount()
+	p.run.wrappedRenderVals = sqlbase.NewRowContainer(
+		acc, sqlbase.ColTypeInfoFromResCols(planColumns(p.plan)), 0,
+	)
+	return p, nil
+}
+
+// ConstructInsert is part of the exec.Factory interface.
+func (ef *execFactory) ConstructInsert(
+	input exec.Node, table opt.Table, insertCols []int, rowsNeeded bool,
+) (exec.Node, error) {
+	ins, err := ef.makeMutationInserter(table, insertCols, sqlbase.CheckInserts)
+	if err != nil {
+		return nil, err
+	}
+
+	in := insertNodePool.Get().(*insertNode)
+	*in = insertNode{
+		source:  input.(planNode),
+		columns: mutationResultColumns(ins.desc, rowsNeeded),
+		run: insertRun{
+			ti:           tableInserter{ri: ins.ri},
+			checkHelper:  ins.fkTables[ins.desc.ID].CheckHelper,
+			rowsNeeded:   rowsNeeded,
+			computedCols: ins.computedCols,
+			computeExprs: ins.computeExprs,
+			iVarContainerForComputedCols: sqlbase.RowIndexedVarContainer{
+				Cols:    ins.desc.Columns,
+				Mapping: ins.ri.InsertColIDtoRowIndex,
+			},
+			defaultExprs: ins.defaultExprs,
+			insertCols:   ins.ri.InsertCols,
+		},
+	}
+	return wrapMutation(in, rowsNeeded), nil
+}
+
+// ConstructUpdate is part of the exec.Factory interface.
+func (ef *execFactory) ConstructUpdate(
+	input exec.Node, table opt.Table, updateCols []int, rowsNeeded bool,
+) (exec.Node, error) {
+	p := ef.planner
+	ctx := p.EvalContext().Ctx()
+	desc, err := mutationTableDesc(table)
+	if err != nil {
+		return nil, err
+	}
+
+	fkTables, err := sqlbase.TablesNeededForFKs(
+		ctx, *desc, sqlbase.CheckUpdates, p.lookupFKTable, p.CheckPrivilege, p.analyzeExpr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]sqlbase.ColumnDescriptor, len(updateCols))
+	for i, ord := range updateCols {
+		cols[i] = desc.Columns[ord]
+	}
+
+	// Extend the updated columns with all the computed columns; their values
+	// are recomputed from the new values of the updated columns.
+	tn := table.TabName()
+	allUpdateCols, computedCols, computeExprs, err := sqlbase.ProcessComputedColumns(
+		ctx, cols, tn, desc, &p.txCtx, p.EvalContext(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The input always provides all the columns of the table, so request all
+	// of them. Since the table is not undergoing a schema change, the fetched
+	// columns are exactly the columns of the table, in order.
+	ru, err := sqlbase.MakeRowUpdater(
+		p.txn,
+		desc,
+		fkTables,
+		allUpdateCols,
+		desc.Columns,
+		sqlbase.RowUpdaterDefault,
+		p.EvalContext(),
+		&p.alloc,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The new values of the updated columns follow the fetched columns in the
+	// input.
+	sourceSlots := make([]sourceSlot, len(cols))
+	for i := range cols {
+		sourceSlots[i] = scalarSlot{column: cols[i], sourceIndex: len(ru.FetchCols) + i}
+	}
+
+	updateColsIdx := make(map[sqlbase.ColumnID]int, len(ru.UpdateCols))
+	for i, col := range ru.UpdateCols {
+		updateColsIdx[col.ID] = i
+	}
+
+	un := updateNodePool.Get().(*updateNode)
+	*un = updateNode{
+		source:  input.(planNode),
+		columns: mutationResultColumns(desc, rowsNeeded),
+		run: updateRun{
+			tu:           tableUpdater{ru: ru},
+			checkHelper:  fkTables[desc.ID].CheckHelper,
+			rowsNeeded:   rowsNeeded,
+			computedCols: computedCols,
+			computeExprs: computeExprs,
+			iVarContainerForComputedCols: sqlbase.RowIndexedVarContainer{
+				CurSourceRow: make(tree.Datums, len(ru.FetchCols)),
+				Cols:         desc.Columns,
+				Mapping:      ru.FetchColIDtoRowIndex,
+			},
+			sourceSlots:   sourceSlots,
+			updateValues:  make(tree.Datums, len(ru.UpdateCols)),
+			updateColsIdx: updateColsIdx,
+		},
+	}
+	return wrapMutation(un, rowsNeeded), nil
+}
+
+// ConstructUpsert is part of the exec.Factory interface.
+func (ef *execFactory) ConstructUpsert(
+	input exec.Node,
+	table opt.Table,
+	insertCols []int,
+	onConflict *tree.OnConflict,
+	rowsNeeded bool,
+) (exec.Node, error) {
+	fkCheckType := sqlbase.CheckUpdates
+	if onConflict.DoNothing {
+		fkCheckType = sqlbase.CheckInserts
+	}
+	ins, err := ef.makeMutationInserter(table, insertCols, fkCheckType)
+	if err != nil {
+		return nil, err
+	}
+
+	tn := table.TabName()
+	node, err := ef.planner.newUpsertNode(
+		ef.planner.EvalContext().Ctx(), onConflict, ins.desc, ins.ri, tn, tn, input.(planNode), rowsNeeded,
+		mutationResultColumns(ins.desc, rowsNeeded), ins.defaultExprs, ins.computeExprs,
+		ins.computedCols, ins.fkTables, nil, /* desiredTypes */
+	)
+	if err != nil {
+		return nil, err
+	}
+	return wrapMutation(node, rowsNeeded), nil
+}
+
+// ConstructDelete is part of the exec.Factory interface.
+func (ef *execFactory) ConstructDelete(
+	input exec.Node, table opt.Table, rowsNeeded bool,
+) (exec.Node, error) {
+	p := ef.planner
+	ctx := p.EvalContext().Ctx()
+	desc, err := mutationTableDesc(table)
+	if err != nil {
+		return nil, err
+	}
+
+	fkTables, err := sqlbase.TablesNeededForFKs(
+		ctx, *desc, sqlbase.CheckDeletes, p.lookupFKTable, p.CheckPrivilege, p.analyzeExpr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The input always provides all the columns of the table.
+	rd, err := sqlbase.MakeRowDeleter(
+		p.txn, desc, fkTables, desc.Columns, sqlbase.CheckFKs, p.EvalContext(), &p.alloc,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dn := deleteNodePool.Get().(*deleteNode)
+	*dn = deleteNode{
+		source:  input.(planNode),
+		columns: mutationResultColumns(desc, rowsNeeded),
+		run: deleteRun{
+			td:         tableDeleter{rd: rd, alloc: &p.alloc},
+			rowsNeeded: rowsNeeded,
+		},
+	}
+	return wrapMutation(dn, rowsNeeded), nil
+}
+
+// mutationInserter collects the state that is needed to construct both
+// insert and upsert nodes.
+type mutationInserter struct {
+	desc         *sqlbase.TableDescriptor
+	fkTables     sqlbase.TableLookupsByID
+	ri           sqlbase.RowInserter
+	defaultExprs []tree.TypedExpr
+	computedCols []sqlbase.ColumnDescriptor
+	computeExprs []tree.TypedExpr
+}
+
+// makeMutationInserter prepares a row inserter for the given table. The
+// columns with the given ordinals are provided by the input of the mutation;
+// they are extended with the computed columns and the columns that have a
+// default value, in the same way as the heuristic planner does for INSERT.
+func (ef *execFactory) makeMutationInserter(
+	table opt.Table, insertCols []int, fkCheckType sqlbase.FKCheck,
+) (mutationInserter, error) {
+	p := ef.planner
+	ctx := p.EvalContext().Ctx()
+	var res mutationInserter
+	desc, err := mutationTableDesc(table)
+	if err != nil {
+		return res, err
+	}
+	res.desc = desc
+
+	res.fkTables, err = sqlbase.TablesNeededForFKs(
+		ctx, *desc, fkCheckType, p.lookupFKTable, p.CheckPrivilege, p.analyzeExpr,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	cols := make([]sqlbase.ColumnDescriptor, len(insertCols))
+	for i, ord := range insertCols {
+		cols[i] = desc.Columns[ord]
+	}
+
+	tn := table.TabName()
+	cols, res.computedCols, res.computeExprs, err = sqlbase.ProcessComputedColumns(
+		ctx, cols, tn, desc, &p.txCtx, p.EvalContext(),
+	)
+	if err != nil {
+		return res, err
+	}
+
+	// The default expressions line up with the final set of columns, so this
+	// needs to happen after the computed columns are processed.
+	cols, res.defaultExprs, err = sqlbase.ProcessDefaultColumns(
+		cols, desc, &p.txCtx, p.EvalContext(),
+	)
+	if err != nil {
+		return res, err
+	}
+
+	res.ri, err = sqlbase.MakeRowInserter(
+		p.txn, desc, res.fkTables, cols, sqlbase.CheckFKs, p.EvalContext(), &p.alloc,
+	)
+	return res, err
+}
+
+// mutationTableDesc returns the descriptor of the target table of a mutation,
+// after verifying that the table can be mutated by a plan built by the
+// optimizer.
+func mutationTableDesc(table opt.Table) (*sqlbase.TableDescriptor, error) {
+	desc := table.(*optTable).desc
+	if !desc.IsTable() {
+		return nil, sqlbase.NewWrongObjectTypeError(table.TabName(), requiredTypeNames[requireTableDesc])
+	}
+	if len(desc.Mutations) > 0 {
+		// The optimizer only knows about public columns, so it cannot provide
+		// values for the columns that are being added or dropped.
+		return nil, pgerror.Unimplemented(optbuilder.MutationSchemaChangeFeature,
+			"mutations of tables undergoing schema changes are not supported")
+	}
+	return desc, nil
+}
+
+// mutationResultColumns returns the columns of a mutation node: all the
+// columns of the table if the affected rows are needed, or none otherwise.
+func mutationResultColumns(
+	desc *sqlbase.TableDescriptor, rowsNeeded bool,
+) sqlbase.ResultColumns {
+	if !rowsNeeded {
+		return nil
+	}
+	return sqlbase.ResultColumnsFromColDescs(desc.Columns)
+}
+
+// wrapMutation wraps a mutation node in the same way as planner.Returning: if
+// the affected rows are not needed, then the resulting node only reports the
+// number of affected rows. Otherwise, the results are serialized and spooled,
+// so that the mutation always runs to completion, even if its results are not
+// all consumed (see doExpandPlan). The spool is removed by elideTopLevelSpool
+// if the mutation ends up at the top of the plan.
+func wrapMutation(node batchedPlanNode, rowsNeeded bool) planNode {
+	if !rowsNeeded {
+		return &rowCountNode{source: node}
+	}
+	return &spoolNode{source: &serializeNode{source: node}}
+}
+
 // ConstructPlan is part of the exec.Factory interface.
 func (ef *execFactory) ConstructPlan(
 	root exec.Node, subqueries []exec.Subquery,
 ) (exec.Plan, error) {
 	res := &planTop{
-		plan:        root.(planNode),
+		plan:        elideTopLevelSpool(root.(planNode)),
 		auditEvents: ef.planner.curPlan.auditEvents,
 	}
 	if len(subqueries) > 0 {
@@ -642,6 +1051,22 @@ func (ef *execFactory) ConstructPlan(
 	return res, nil
 }
 
+// elideTopLevelSpool removes the spool that wraps the results of a mutation
+// (see wrapMutation) if the mutation is at the top of the plan, possibly under
+// a projection of its results. All the results are consumed at the top level,
+// so the mutation always runs to completion.
+func elideTopLevelSpool(plan planNode) planNode {
+	switch n := plan.(type) {
+	case *spoolNode:
+		return n.source
+	case *renderNode:
+		if spool, ok := n.source.plan.(*spoolNode); ok {
+			n.source.plan = spool.source
+		}
+	}
+	return plan
+}
+
 // ConstructExplain is part of the exec.Factory interface.
 func (ef *execFactory) ConstructExplain(
 	options *tree.ExplainOptions, plan exec.Plan,
@@ -662,7 +1087,7 @@ func (ef *execFactory) ConstructExplain(
 
 	case tree.ExplainPlan:
 		if analyzeSet {
-			return nil, errors.New("EXPLAIN ANALYZE only supported with (DISTSQL) option")
+			return ef.ConstructExplainAnalyze(plan, nil /* estimates */)
 		}
 		// NOEXPAND and NOOPTIMIZE must always be set when using the optimizer to
 		// prevent the plans from being modified.
@@ -682,6 +1107,17 @@ func (ef *execFactory) ConstructExplain(
 	}
 }
 
+// ConstructExplainAnalyze is part of the exec.Factory interface.
+func (ef *execFactory) ConstructExplainAnalyze(
+	plan exec.Plan, estimates map[exec.Node]exec.Estimates,
+) (exec.Node, error) {
+	p := plan.(*planTop)
+	if len(p.subqueryPlans) > 0 {
+		return nil, fmt.Errorf("subqueries not supported yet")
+	}
+	return ef.planner.makeExplainAnalyzeNode(p.plan, tree.Rows, estimates), nil
+}
+
 // ConstructShowTrace is part of the exec.Factory interface.
 func (ef *execFactory) ConstructShowTrace(typ tree.ShowTraceType, compact bool) (exec.Node, error) {
 	var node planNode = ef.planner.makeShowTraceNode(compact, typ == tree.ShowTraceKV)
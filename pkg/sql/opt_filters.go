@@ -305,6 +305,11 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *explainAnalyzeNode:
+		if n.plan, err = p.triggerFilterPropagation(ctx, n.plan); err != nil {
+			return plan, extraFilter, err
+		}
+
 	case *explainPlanNode:
 		if n.optimized {
 			if n.plan, err = p.triggerFilterPropagation(ctx, n.plan); err != nil {
@@ -366,12 +371,19 @@ func (p *planner) propagateFilters(
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createFunctionNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
 	case *dropSequenceNode:
+	case *dropFunctionNode:
+	case *listenNode:
+	case *fetchNode:
+	case *closeCursorNode:
+	case *notifyNode:
 	case *DropUserNode:
 	case *hookFnNode:
 	case *valuesNode:
@@ -29,8 +29,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -132,6 +134,8 @@ func (p *planner) selectIndex(
 	}
 
 	var optimizer *xform.Optimizer
+	var bld *optbuilder.ScalarBuilder
+	var filterGroup memo.GroupID
 
 	if s.filter != nil {
 		optimizer = xform.NewOptimizer(p.EvalContext())
@@ -139,9 +143,10 @@ func (p *planner) selectIndex(
 		for i := range s.resultColumns {
 			md.AddColumn(s.resultColumns[i].Name, s.resultColumns[i].Typ)
 		}
-		bld := optbuilder.NewScalar(ctx, &p.semaCtx, p.EvalContext(), optimizer.Factory())
+		bld = optbuilder.NewScalar(ctx, &p.semaCtx, p.EvalContext(), optimizer.Factory())
 		bld.AllowUnsupportedExpr = true
-		filterGroup, err := bld.Build(s.filter)
+		var err error
+		filterGroup, err = bld.Build(s.filter)
 		if err != nil {
 			return nil, err
 		}
@@ -160,6 +165,40 @@ func (p *planner) selectIndex(
 		}
 	}
 
+	// Remove any partial indexes whose predicate is not implied by the filter;
+	// they don't contain entries for all the rows the query may need.
+	for i := 0; i < len(candidates); {
+		if candidates[i].index.IsPartial() {
+			implied := false
+			if s.filter != nil {
+				var err error
+				implied, err = p.partialIndexPredicateImplied(
+					ctx, s, candidates[i].index, bld, optimizer.Memo(), filterGroup,
+				)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if !implied {
+				candidates[i] = candidates[len(candidates)-1]
+				candidates = candidates[:len(candidates)-1]
+				continue
+			}
+		}
+		i++
+	}
+
+	if len(candidates) == 0 {
+		// The primary index is never partial. So the only way this can happen is
+		// if we had a specified index.
+		if s.specifiedIndex == nil {
+			return nil, pgerror.NewError(pgerror.CodeInternalError, "no non-partial indexes")
+		}
+		return nil, fmt.Errorf(
+			"index \"%s\" is a partial index whose predicate is not implied by the query filter",
+			s.specifiedIndex.Name)
+	}
+
 	// Remove any inverted indexes that don't generate any spans, a full-scan of
 	// an inverted index is always invalid.
 	for i := 0; i < len(candidates); {
@@ -284,6 +323,49 @@ func (p *planner) selectIndex(
 	return plan, nil
 }
 
+// partialIndexPredicateImplied returns true if the filter of the scanNode,
+// built into filterGroup, implies the predicate of the given partial index.
+func (p *planner) partialIndexPredicateImplied(
+	ctx context.Context,
+	s *scanNode,
+	index *sqlbase.IndexDescriptor,
+	bld *optbuilder.ScalarBuilder,
+	mem *memo.Memo,
+	filterGroup memo.GroupID,
+) (bool, error) {
+	colIDs, err := s.desc.PartialIndexPredicateColumnIDs(index)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range colIDs {
+		if _, ok := s.colIdxMap[id]; !ok {
+			// The predicate refers to a column which is not scanned, so the filter
+			// cannot imply it.
+			return false, nil
+		}
+	}
+
+	pred, err := parser.ParseExpr(index.Predicate)
+	if err != nil {
+		return false, err
+	}
+	sources := sqlbase.MultiSourceInfo{
+		sqlbase.NewSourceInfoForSingleTable(sqlbase.AnonymousTable, s.resultColumns),
+	}
+	typedPred, err := p.analyzeExpr(
+		ctx, pred, sources, s.filterVars, types.Bool, true /* requireType */, "index predicate",
+	)
+	if err != nil {
+		return false, err
+	}
+	predGroup, err := bld.Build(typedPred)
+	if err != nil {
+		return false, err
+	}
+	_, implied := mem.ImpliesPartialIndexPredicate(p.EvalContext(), filterGroup, predGroup)
+	return implied, nil
+}
+
 type indexInfo struct {
 	desc        *sqlbase.TableDescriptor
 	index       *sqlbase.IndexDescriptor
@@ -568,7 +650,15 @@ func encodeConstraintKey(
 			}
 		}
 
-		if index.Type == sqlbase.IndexDescriptor_INVERTED {
+		if _, ok := val.(*tree.DString); ok && index.Type == sqlbase.IndexDescriptor_INVERTED {
+			// The constraints on a trigram index are on the trigrams, and the
+			// constraints on the inverted index of a TSVECTOR column are on the
+			// words of the lexemes; both are encoded as they are.
+			key, err = sqlbase.EncodeTableKey(key, val, encoding.Ascending)
+			if err != nil {
+				return nil, err
+			}
+		} else if index.Type == sqlbase.IndexDescriptor_INVERTED {
 			keys, err := sqlbase.EncodeInvertedIndexTableKeys(val, key)
 			if err != nil {
 				return nil, err
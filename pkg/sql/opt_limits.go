@@ -175,6 +175,8 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 		}
 	case *explainDistSQLNode:
 		p.setUnlimited(n.plan)
+	case *explainAnalyzeNode:
+		p.setUnlimited(n.plan)
 	case *showTraceReplicaNode:
 		p.setUnlimited(n.plan)
 	case *explainPlanNode:
@@ -208,12 +210,19 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createFunctionNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
 	case *dropSequenceNode:
+	case *dropFunctionNode:
+	case *listenNode:
+	case *fetchNode:
+	case *closeCursorNode:
+	case *notifyNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -231,6 +240,9 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *lookupJoinNode:
 		// The lookup join node is only planned by the optimizer.
 
+	case *zigzagJoinNode:
+		// The zigzag join node is only planned by the optimizer.
+
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
 	}
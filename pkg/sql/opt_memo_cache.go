@@ -0,0 +1,296 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// customPlansBeforeGeneric is the number of custom plans that are built for a
+// prepared statement with placeholders before a generic plan is considered.
+const customPlansBeforeGeneric = 5
+
+// genericPlanCostFactor is the factor by which the cost of a generic plan may
+// exceed the average cost of the custom plans and still be preferred. The
+// generic plan saves the planning time of every execution, which is not
+// reflected in the optimizer's cost.
+const genericPlanCostFactor = 1.1
+
+// memoCache caches the optimizer's plan for a prepared statement, so that
+// executions of the statement can skip building and optimizing the memo.
+//
+// A statement without placeholders is planned on its first execution, and the
+// memo is reused for later executions. For a statement with placeholders, the
+// first execution builds a normalized memo in which the placeholders are kept
+// as Placeholder operators. Each execution assigns the placeholder values in a
+// copy of that memo and re-runs exploration on it (a "custom" plan), which
+// skips building and normalizing the expression tree. After
+// customPlansBeforeGeneric executions, a "generic" plan is optimized from the
+// normalized memo without assigning the placeholders, so that it does not
+// depend on their values. If the generic plan is not more expensive than the
+// average custom plan, it is cached and reused from then on. Otherwise, custom
+// plans continue to be used.
+//
+// A cached memo is only valid as long as the table and function descriptors
+// it was built from and the plan baselines are unchanged. Each reuse checks
+// that the leased versions of those descriptors still match, and discards the
+// memo otherwise.
+type memoCache struct {
+	mu syncutil.Mutex
+
+	// mem is the cached, fully optimized memo. It is nil if there is none.
+	mem *memo.Memo
+
+	// normMem is the cached normalized memo of a statement with placeholders,
+	// and normRoot and normProps are the root of its expression tree and the
+	// properties required of it. normMem is nil if there is none. It is never
+	// changed; it is copied before it is optimized.
+	normMem   *memo.Memo
+	normRoot  memo.GroupID
+	normProps *props.Physical
+
+	// normFailed is set if the normalized memo could not be built (e.g.
+	// because the statement needs the placeholder values to be built). Such
+	// statements are planned from scratch on each execution.
+	normFailed bool
+
+	// isCorrelated is set if the statement has correlated subqueries.
+	isCorrelated bool
+
+	// deps are the dependencies of mem and normMem.
+	deps memoDeps
+
+	// numCustomPlans and totalCustomCost track the custom plans that were built
+	// for a statement with placeholders.
+	numCustomPlans  int
+	totalCustomCost memo.Cost
+
+	// genericRejected is set once a generic plan was found to be more expensive
+	// than the custom plans, or could not be built.
+	genericRejected bool
+}
+
+// memoDeps are the schema objects and session settings that a cached memo
+// depends on.
+type memoDeps struct {
+	tables     []IDVersion
+	privChecks []optPrivilegeCheck
+	// functions are the user-defined functions; their bodies may have been
+	// inlined into the memo.
+	functions []IDVersion
+
+	database          string
+	searchPath        string
+	reorderJoinsLimit int
+	safeUpdates       bool
+
+	// planBaselines is the generation of the plan baselines.
+	planBaselines int64
+}
+
+// makeMemoDeps returns the dependencies of a memo that was built by the given
+// planner using the given catalog.
+func makeMemoDeps(p *planner, catalog *optCatalog) memoDeps {
+	sd := p.SessionData()
+	deps := memoDeps{
+		tables:            make([]IDVersion, 0, len(catalog.wrappers)),
+		privChecks:        catalog.privChecks,
+		functions:         catalog.functions,
+		database:          sd.Database,
+		searchPath:        sd.SearchPath.String() + "," + sd.SearchPath.GetTemporarySchemaName(),
+		reorderJoinsLimit: sd.ReorderJoinsLimit,
+		safeUpdates:       sd.SafeUpdates,
+		planBaselines:     p.execCfg.PlanBaselines.generation(),
+	}
+	for desc := range catalog.wrappers {
+		deps.tables = append(deps.tables, NewIDVersion(desc.Name, desc.ID, desc.Version))
+	}
+	return deps
+}
+
+// isValid returns true if the dependencies are unchanged for the given
+// planner. The descriptors are looked up through the planner's table
+// collection, so that the planner holds leases on them as if the memo had
+// been built. The privilege checks made while building the memo are repeated.
+func (d *memoDeps) isValid(ctx context.Context, p *planner) (bool, error) {
+	sd := p.SessionData()
+	if d.database != sd.Database ||
+		d.searchPath != sd.SearchPath.String()+","+sd.SearchPath.GetTemporarySchemaName() ||
+		d.reorderJoinsLimit != sd.ReorderJoinsLimit ||
+		d.safeUpdates != sd.SafeUpdates ||
+		d.planBaselines != p.execCfg.PlanBaselines.generation() {
+		return false, nil
+	}
+
+	descs := make([]*sqlbase.TableDescriptor, len(d.tables))
+	for i := range d.tables {
+		desc, err := p.Tables().getTableVersionByID(ctx, p.txn, d.tables[i].id)
+		if err != nil {
+			// The error is reported when the memo is rebuilt.
+			return false, nil
+		}
+		if desc.Version != d.tables[i].version {
+			return false, nil
+		}
+		descs[i] = desc
+	}
+
+	for i := range d.functions {
+		desc, err := p.Tables().getTableVersionByID(ctx, p.txn, d.functions[i].id)
+		if err != nil {
+			// The function was dropped; the error is reported when the memo is
+			// rebuilt.
+			return false, nil
+		}
+		if desc.Version != d.functions[i].version {
+			return false, nil
+		}
+	}
+
+	for _, check := range d.privChecks {
+		for i := range d.tables {
+			if d.tables[i].id == check.tableID {
+				if err := p.CheckPrivilege(ctx, descs[i], check.priv); err != nil {
+					return false, err
+				}
+				break
+			}
+		}
+	}
+	return true, nil
+}
+
+// lookup returns the root of the cached memo, if there is one and it is still
+// valid, and whether the statement is correlated. If the dependencies of the
+// cached memos changed, both mem and normMem are discarded. The cache must be
+// locked.
+func (c *memoCache) lookup(
+	ctx context.Context, p *planner,
+) (_ memo.ExprView, isCorrelated bool, ok bool, _ error) {
+	if c.mem == nil && c.normMem == nil {
+		return memo.ExprView{}, false, false, nil
+	}
+	valid, err := c.deps.isValid(ctx, p)
+	if err != nil {
+		return memo.ExprView{}, false, false, err
+	}
+	if !valid {
+		log.VEvent(ctx, 1, "cached optimizer plan is stale")
+		c.mem = nil
+		c.normMem = nil
+		c.normRoot = 0
+		c.normProps = nil
+		c.isCorrelated = false
+		c.deps = memoDeps{}
+		c.numCustomPlans = 0
+		c.totalCustomCost = 0
+		return memo.ExprView{}, false, false, nil
+	}
+	if c.mem == nil {
+		return memo.ExprView{}, false, false, nil
+	}
+	return c.mem.Root(), c.isCorrelated, true, nil
+}
+
+// add caches the memo of a statement without placeholders, which was built
+// using the given catalog. The cache must be locked.
+func (c *memoCache) add(p *planner, mem *memo.Memo, catalog *optCatalog, isCorrelated bool) {
+	c.mem = mem
+	c.isCorrelated = isCorrelated
+	c.deps = makeMemoDeps(p, catalog)
+}
+
+// buildNormalized builds the normalized memo of a statement with placeholders
+// and caches it. It returns false if the memo could not be built, in which
+// case the statement must be planned from scratch. The cache must be locked.
+func (c *memoCache) buildNormalized(ctx context.Context, p *planner, stmt Statement) bool {
+	if c.normMem != nil {
+		log.VEvent(ctx, 1, "reusing cached normalized memo")
+		return true
+	}
+	if c.normFailed {
+		return false
+	}
+
+	var catalog optCatalog
+	catalog.init(p.execCfg.TableStatsCache, p)
+	defer p.resolveFunctionsThrough(&catalog)()
+	f := norm.NewFactory(p.EvalContext())
+	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &catalog, f, stmt.AST)
+	bld.KeepPlaceholders = true
+	bld.BaselineJoinHints = p.execCfg.PlanBaselines.lookup(p.SessionData().Database, stmt.AST)
+	root, required, err := bld.Build()
+	if err != nil {
+		log.VEventf(ctx, 1, "normalized memo with placeholders failed: %v", err)
+		c.normFailed = true
+		return false
+	}
+	c.normMem = f.Memo()
+	c.normRoot = root
+	c.normProps = required
+	c.isCorrelated = bld.IsCorrelated
+	c.deps = makeMemoDeps(p, &catalog)
+	return true
+}
+
+// addCustom is called with the cost of each custom plan built for a statement
+// with placeholders, and decides whether a generic plan can be cached for
+// later executions. The cache must be locked, and the normalized memo must
+// have been built.
+func (c *memoCache) addCustom(ctx context.Context, p *planner, customCost memo.Cost) {
+	if c.genericRejected {
+		return
+	}
+
+	c.numCustomPlans++
+	c.totalCustomCost += customCost
+	if c.numCustomPlans < customPlansBeforeGeneric {
+		return
+	}
+
+	log.VEvent(ctx, 1, "building generic optimizer plan")
+	genericOpt := xform.NewOptimizerWithMemo(p.EvalContext(), c.normMem.Copy())
+	genericOpt.Optimize(c.normRoot, c.normProps)
+	if err := genericOpt.CheckJoinHints(); err != nil {
+		log.VEventf(ctx, 1, "generic optimizer plan failed: %v", err)
+		c.genericRejected = true
+		return
+	}
+	genericCost := genericOpt.Memo().Root().Cost()
+
+	avgCustomCost := c.totalCustomCost / memo.Cost(c.numCustomPlans)
+	if !preferGenericPlan(genericCost, avgCustomCost) {
+		log.VEventf(ctx, 1, "generic optimizer plan rejected: cost %f, average custom cost %f",
+			genericCost, avgCustomCost)
+		c.genericRejected = true
+		return
+	}
+	c.mem = genericOpt.Memo()
+}
+
+// preferGenericPlan returns true if a generic plan with the given cost should
+// be used instead of custom plans with the given average cost.
+func preferGenericPlan(genericCost, avgCustomCost memo.Cost) bool {
+	return genericCost <= avgCustomCost*genericPlanCostFactor
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPreparedStatementMemoCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// Prepared statements and session traces are per connection.
+	db.SetMaxOpenConns(1)
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `
+CREATE DATABASE t;
+CREATE TABLE t.kv (k INT PRIMARY KEY, v INT);
+INSERT INTO t.kv VALUES (1, 10), (2, 20);
+PREPARE a AS SELECT v FROM t.kv WHERE k = 1;
+PREPARE b AS SELECT v FROM t.kv WHERE k = $1;
+`)
+
+	// traced returns whether the last traced statement logged the given
+	// message.
+	traced := func(message string) bool {
+		var n int
+		r.QueryRow(t, `SELECT count(*) FROM [SHOW TRACE FOR SESSION] WHERE message = $1`,
+			message).Scan(&n)
+		return n > 0
+	}
+
+	// execute runs the given statement, checks its results, and returns whether
+	// it reused a cached optimizer plan.
+	execute := func(stmt string, expected [][]string) bool {
+		r.Exec(t, `SET tracing = on`)
+		r.CheckQueryResults(t, stmt, expected)
+		r.Exec(t, `SET tracing = off`)
+		return traced("reusing cached optimizer plan")
+	}
+
+	// A statement without placeholders is planned once.
+	if execute(`EXECUTE a`, [][]string{{"10"}}) {
+		t.Fatal("expected first execution to build a plan")
+	}
+	if !execute(`EXECUTE a`, [][]string{{"10"}}) {
+		t.Fatal("expected second execution to reuse the plan")
+	}
+
+	// A schema change invalidates the cached plan.
+	r.Exec(t, `ALTER TABLE t.kv ADD COLUMN w INT`)
+	if execute(`EXECUTE a`, [][]string{{"10"}}) {
+		t.Fatal("expected execution after schema change to build a plan")
+	}
+	if !execute(`EXECUTE a`, [][]string{{"10"}}) {
+		t.Fatal("expected plan to be reused after schema change")
+	}
+
+	// Replacing or dropping a function whose body was inlined into the plan
+	// invalidates the cached plan.
+	r.Exec(t, `
+CREATE FUNCTION t.f(x INT) RETURNS INT IMMUTABLE LANGUAGE SQL AS 'SELECT $1 + 1';
+PREPARE c AS SELECT t.f(v) FROM t.kv WHERE k = 1;
+`)
+	if execute(`EXECUTE c`, [][]string{{"11"}}) {
+		t.Fatal("expected first execution to build a plan")
+	}
+	if !execute(`EXECUTE c`, [][]string{{"11"}}) {
+		t.Fatal("expected second execution to reuse the plan")
+	}
+	r.Exec(t, `CREATE OR REPLACE FUNCTION t.f(x INT) RETURNS INT IMMUTABLE LANGUAGE SQL AS 'SELECT $1 + 2'`)
+	if execute(`EXECUTE c`, [][]string{{"12"}}) {
+		t.Fatal("expected execution after replacing the function to build a plan")
+	}
+	r.Exec(t, `DROP FUNCTION t.f`)
+	if _, err := db.Exec(`EXECUTE c`); !testutils.IsError(err, `unknown function: t.f\(\)`) {
+		t.Fatalf("expected unknown function error, got %v", err)
+	}
+
+	// A statement with placeholders is normalized once, and each execution
+	// optimizes a copy of the normalized memo with its placeholder values. A
+	// generic plan that scans the whole table is more expensive than the custom
+	// plans, which scan a single row, so it is never cached.
+	for i := 0; i < 2*customPlansBeforeGeneric; i++ {
+		stmt, expected := `EXECUTE b(1)`, [][]string{{"10"}}
+		if i%2 == 1 {
+			stmt, expected = `EXECUTE b(2)`, [][]string{{"20"}}
+		}
+		if execute(stmt, expected) {
+			t.Fatalf("execution %d unexpectedly reused a generic plan", i)
+		}
+		if reused := traced("reusing cached normalized memo"); reused != (i > 0) {
+			t.Fatalf("execution %d: expected normalized memo reuse to be %t", i, i > 0)
+		}
+	}
+}
+
+func TestPreferGenericPlan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		generic, custom memo.Cost
+		expected        bool
+	}{
+		{generic: 10, custom: 10, expected: true},
+		{generic: 10.5, custom: 10, expected: true},
+		{generic: 12, custom: 10, expected: false},
+		{generic: 1000, custom: 1, expected: false},
+	}
+	for _, tc := range testCases {
+		if actual := preferGenericPlan(tc.generic, tc.custom); actual != tc.expected {
+			t.Errorf("generic cost %f, custom cost %f: expected %t, got %t",
+				tc.generic, tc.custom, tc.expected, actual)
+		}
+	}
+}
@@ -34,6 +34,9 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *explainDistSQLNode:
 		setNeededColumns(n.plan, allColumns(n.plan))
 
+	case *explainAnalyzeNode:
+		setNeededColumns(n.plan, allColumns(n.plan))
+
 	case *showTraceReplicaNode:
 		setNeededColumns(n.plan, allColumns(n.plan))
 
@@ -258,12 +261,19 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createFunctionNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
 	case *dropSequenceNode:
+	case *dropFunctionNode:
+	case *listenNode:
+	case *fetchNode:
+	case *closeCursorNode:
+	case *notifyNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
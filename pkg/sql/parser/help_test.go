@@ -107,13 +107,20 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE VIEW blah AS (SELECT c FROM x) ??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS SELECT c FROM x ??`, `SELECT`},
 		{`CREATE VIEW blah AS (??`, `<SELECTCLAUSE>`},
+		{`CREATE MATERIALIZED VIEW blah (??`, `CREATE VIEW`},
+		{`CREATE MATERIALIZED VIEW blah AS SELECT c FROM x ??`, `SELECT`},
 
 		{`CREATE SEQUENCE ??`, `CREATE SEQUENCE`},
 
+		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
+		{`CREATE FUNCTION f ??`, `CREATE FUNCTION`},
+		{`CREATE OR REPLACE FUNCTION ??`, `CREATE FUNCTION`},
+
 		{`CREATE STATISTICS ??`, `CREATE STATISTICS`},
 
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
+		{`CREATE TEMP TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE blah (x, y) AS ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah (x INT) ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah AS ??`, `CREATE TABLE`},
@@ -126,8 +133,21 @@ func TestContextualHelp(t *testing.T) {
 		{`DELETE FROM blah WHERE x > 3 ??`, `DELETE`},
 
 		{`DISCARD ALL ??`, `DISCARD`},
+		{`DISCARD TEMP ??`, `DISCARD`},
 		{`DISCARD ??`, `DISCARD`},
 
+		{`LISTEN ??`, `LISTEN`},
+		{`NOTIFY ??`, `NOTIFY`},
+		{`NOTIFY foo, ??`, `NOTIFY`},
+		{`UNLISTEN ??`, `UNLISTEN`},
+
+		{`DECLARE ??`, `DECLARE`},
+		{`DECLARE c CURSOR ??`, `DECLARE`},
+		{`FETCH ??`, `FETCH`},
+		{`FETCH 1 ??`, `FETCH`},
+		{`MOVE ??`, `MOVE`},
+		{`CLOSE ??`, `CLOSE`},
+
 		{`DROP ??`, `DROP`},
 
 		{`DROP DATABASE IF ??`, `DROP DATABASE`},
@@ -144,6 +164,9 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP SEQUENCE IF ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF EXISTS blih, bloh ??`, `DROP SEQUENCE`},
 
+		{`DROP FUNCTION ??`, `DROP FUNCTION`},
+		{`DROP FUNCTION IF EXISTS f(INT) ??`, `DROP FUNCTION`},
+
 		{`DROP TABLE blah ??`, `DROP TABLE`},
 		{`DROP TABLE IF ??`, `DROP TABLE`},
 		{`DROP TABLE IF EXISTS blih, bloh ??`, `DROP TABLE`},
@@ -151,6 +174,7 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP VIEW blah ??`, `DROP VIEW`},
 		{`DROP VIEW IF ??`, `DROP VIEW`},
 		{`DROP VIEW IF EXISTS blih, bloh ??`, `DROP VIEW`},
+		{`DROP MATERIALIZED VIEW blah ??`, `DROP VIEW`},
 
 		{`DROP USER ??`, `DROP USER`},
 		{`DROP USER IF ??`, `DROP USER`},
@@ -349,6 +373,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SAVEPOINT blah ??`, `SAVEPOINT`},
 
+		{`REFRESH ??`, `REFRESH`},
+		{`REFRESH MATERIALIZED VIEW blah ??`, `REFRESH`},
+
 		{`RELEASE blah ??`, `RELEASE`},
 		{`RELEASE SAVEPOINT blah ??`, `RELEASE`},
 
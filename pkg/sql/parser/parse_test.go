@@ -85,8 +85,15 @@ func TestParse(t *testing.T) {
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d (e, f)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d.e (f, g)`},
 		{`CREATE UNIQUE INDEX a ON b.c (d)`},
+		{`CREATE INDEX a ON b (c) WHERE d > 1`},
+		{`CREATE TABLE a (b INT, c BOOL, INDEX (b) WHERE c)`},
+		{`CREATE TABLE a (b INT, c BOOL, UNIQUE (b) WHERE NOT c)`},
+		{`CREATE INDEX ON a (b) STORING (c) WHERE NOT deleted`},
+		{`CREATE UNIQUE INDEX IF NOT EXISTS a ON b (c) WHERE d IS NULL`},
 		{`CREATE INVERTED INDEX a ON b (c)`},
 		{`CREATE INVERTED INDEX a ON b.c (d)`},
+		{`CREATE INVERTED INDEX a ON b (c gin_trgm_ops)`},
+		{`CREATE INDEX a ON b (c gin_trgm_ops DESC)`},
 
 		{`CREATE TABLE a ()`},
 		{`CREATE TABLE a (b INT)`},
@@ -152,6 +159,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT, UNIQUE (b) STORING (c))`},
 		{`CREATE TABLE a (b INT, INDEX (b))`},
 		{`CREATE TABLE a (b INT, INVERTED INDEX (b))`},
+		{`CREATE TABLE a (b STRING, INVERTED INDEX (b gin_trgm_ops))`},
+		{`CREATE TABLE a (b TSVECTOR, INVERTED INDEX (b))`},
 		{`CREATE TABLE a (b INT, c INT REFERENCES foo)`},
 		{`CREATE TABLE a (b INT, c INT REFERENCES foo ON UPDATE RESTRICT)`},
 		{`CREATE TABLE a (b INT, c INT REFERENCES foo ON DELETE RESTRICT)`},
@@ -182,6 +191,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (c) CASCADE`},
 		{`CREATE TABLE a.b (b INT)`},
 		{`CREATE TABLE IF NOT EXISTS a (b INT)`},
+		{`CREATE TEMPORARY TABLE a (b INT)`},
+		{`CREATE TEMPORARY TABLE IF NOT EXISTS a (b INT)`},
 		{`CREATE TABLE a (b INT AS (a + b) STORED)`},
 		{`CREATE TABLE view (view INT)`},
 
@@ -219,6 +230,8 @@ func TestParse(t *testing.T) {
 
 		{`CREATE TABLE a AS SELECT * FROM b`},
 		{`CREATE TABLE IF NOT EXISTS a AS SELECT * FROM b`},
+		{`CREATE TEMPORARY TABLE a AS SELECT * FROM b`},
+		{`CREATE TEMPORARY TABLE IF NOT EXISTS a AS SELECT * FROM b`},
 		{`CREATE TABLE a AS SELECT * FROM b ORDER BY c`},
 		{`CREATE TABLE IF NOT EXISTS a AS SELECT * FROM b ORDER BY c`},
 		{`CREATE TABLE a AS SELECT * FROM b LIMIT 3`},
@@ -241,6 +254,8 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE MATERIALIZED VIEW a AS SELECT * FROM b`},
+		{`CREATE MATERIALIZED VIEW a (x, y) AS SELECT c, count(*) FROM b GROUP BY c`},
 
 		{`CREATE SEQUENCE a`},
 		{`CREATE SEQUENCE IF NOT EXISTS a`},
@@ -264,6 +279,13 @@ func TestParse(t *testing.T) {
 		{`CREATE STATISTICS a ON col1, col2 FROM t`},
 		{`CREATE STATISTICS a ON col1 FROM d.t`},
 
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE sql AS 'SELECT 1'`},
+		{`CREATE FUNCTION a.f(INT, b STRING) RETURNS STRING LANGUAGE sql IMMUTABLE AS 'SELECT $2'`},
+		{`CREATE OR REPLACE FUNCTION f(x INT) RETURNS INT LANGUAGE sql STABLE STRICT AS 'SELECT $1'`},
+		{`CREATE FUNCTION f(x INT) RETURNS INT VOLATILE CALLED ON NULL INPUT LANGUAGE sql AS 'SELECT 1'`},
+		{`CREATE FUNCTION f(x INT) RETURNS INT RETURNS NULL ON NULL INPUT LANGUAGE sql AS 'SELECT 1'`},
+		{`CREATE FUNCTION f(x INT) RETURNS INT LANGUAGE sql AS e'INSERT INTO t VALUES ($1); SELECT \'a\''`},
+
 		{`DELETE FROM a`},
 		{`DELETE FROM a.b`},
 		{`DELETE FROM a WHERE a = b`},
@@ -277,6 +299,33 @@ func TestParse(t *testing.T) {
 		{`DELETE FROM a WHERE a = b ORDER BY c LIMIT d RETURNING e`},
 
 		{`DISCARD ALL`},
+		{`DISCARD TEMPORARY`},
+
+		{`LISTEN foo`},
+		{`LISTEN "Foo"`},
+		{`UNLISTEN foo`},
+		{`UNLISTEN *`},
+		{`NOTIFY foo`},
+		{`NOTIFY foo, 'bar'`},
+		{`NOTIFY foo, e'it\'s'`},
+
+		{`DECLARE c CURSOR FOR SELECT * FROM t`},
+		{`DECLARE c BINARY INSENSITIVE NO SCROLL CURSOR FOR SELECT a FROM t ORDER BY a`},
+		{`DECLARE c SCROLL CURSOR WITH HOLD FOR VALUES (1)`},
+		{`FETCH 1 c`},
+		{`FETCH 10 c`},
+		{`FETCH -1 c`},
+		{`FETCH ALL c`},
+		{`FETCH BACKWARD ALL c`},
+		{`FETCH FIRST c`},
+		{`FETCH LAST c`},
+		{`FETCH ABSOLUTE 3 c`},
+		{`FETCH RELATIVE -3 c`},
+		{`FETCH 1 "my cursor"`},
+		{`MOVE 1 c`},
+		{`MOVE ALL c`},
+		{`CLOSE c`},
+		{`CLOSE ALL`},
 
 		{`DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
@@ -305,6 +354,11 @@ func TestParse(t *testing.T) {
 		{`DROP VIEW a`},
 		{`DROP VIEW a.b`},
 		{`DROP VIEW a, b`},
+		{`DROP MATERIALIZED VIEW a`},
+		{`DROP MATERIALIZED VIEW IF EXISTS a, b CASCADE`},
+
+		{`REFRESH MATERIALIZED VIEW a`},
+		{`REFRESH MATERIALIZED VIEW a.b`},
 		{`DROP VIEW IF EXISTS a`},
 		{`DROP VIEW a RESTRICT`},
 		{`DROP VIEW IF EXISTS a, b RESTRICT`},
@@ -318,6 +372,10 @@ func TestParse(t *testing.T) {
 		{`DROP SEQUENCE IF EXISTS a, b RESTRICT`},
 		{`DROP SEQUENCE a.b CASCADE`},
 		{`DROP SEQUENCE a, b CASCADE`},
+		{`DROP FUNCTION f`},
+		{`DROP FUNCTION a.f()`},
+		{`DROP FUNCTION IF EXISTS f(INT, STRING), g CASCADE`},
+		{`DROP FUNCTION f RESTRICT`},
 
 		{`CANCEL JOBS SELECT a`},
 		{`CANCEL QUERIES SELECT a`},
@@ -330,6 +388,7 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SELECT 1`},
 		{`EXPLAIN EXPLAIN SELECT 1`},
 		{`EXPLAIN (A, B, C) SELECT 1`},
+		{`EXPLAIN ANALYZE SELECT 1`},
 		{`EXPLAIN ANALYZE (A, B, C) SELECT 1`},
 		{`SELECT * FROM [EXPLAIN SELECT 1]`},
 		{`SELECT * FROM [SHOW TRANSACTION STATUS]`},
@@ -546,6 +605,7 @@ func TestParse(t *testing.T) {
 		{`SELECT 'Deutsch' COLLATE "DE"`},
 		{`SELECT a @> b`},
 		{`SELECT a <@ b`},
+		{`SELECT a && b`},
 		{`SELECT a ? b`},
 		{`SELECT a ?| b`},
 		{`SELECT a ?& b`},
@@ -556,6 +616,10 @@ func TestParse(t *testing.T) {
 		{`SELECT (a->'x')->>'y'`},
 		{`SELECT ''::JSON`},
 		{`SELECT ''::JSONB`},
+		{`SELECT a @@ b`},
+		{`SELECT 'a b'::TSVECTOR`},
+		{`SELECT 'a & b'::TSQUERY`},
+		{`SELECT TSVECTOR 'a' @@ TSQUERY 'a'`},
 
 		{`SELECT 1 FROM t`},
 		{`SELECT 1, 2 FROM t`},
@@ -650,6 +714,9 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t AS bar (bar1, bar2, bar3)`},
 		{`SELECT a FROM t WITH ORDINALITY`},
 		{`SELECT a FROM t WITH ORDINALITY AS bar`},
+		{`SELECT a FROM t TABLESAMPLE BERNOULLI (10)`},
+		{`SELECT a FROM t AS bar TABLESAMPLE SYSTEM (0.5) REPEATABLE (42)`},
+		{`SELECT a FROM t@idx TABLESAMPLE BERNOULLI ($1) REPEATABLE ($2)`},
 		{`SELECT a FROM (SELECT 1 FROM t)`},
 		{`SELECT a FROM (SELECT 1 FROM t) AS bar`},
 		{`SELECT a FROM (SELECT 1 FROM t) AS bar (bar1)`},
@@ -744,6 +811,14 @@ func TestParse(t *testing.T) {
 
 		{`SELECT 1 FROM t GROUP BY a`},
 		{`SELECT 1 FROM t GROUP BY a, b`},
+		{`SELECT 1 FROM t GROUP BY ()`},
+		{`SELECT a, b, count(*) FROM t GROUP BY ROLLUP (a, b)`},
+		{`SELECT a, b, count(*) FROM t GROUP BY CUBE (a, (b, c))`},
+		{`SELECT a, b, count(*) FROM t GROUP BY ROLLUP ((a), (b, c))`},
+		{`SELECT a, b, count(*) FROM t GROUP BY a, GROUPING SETS ((a, b), b, ())`},
+		{`SELECT a, b, count(*) FROM t GROUP BY GROUPING SETS (ROLLUP (a), CUBE (b))`},
+		{`SELECT a, grouping(a, b) FROM t GROUP BY ROLLUP (a, b)`},
+		{`SELECT cube(a), rollup(b) FROM t`},
 
 		{`SELECT a FROM t HAVING a = b`},
 
@@ -811,6 +886,11 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t1 NATURAL JOIN t2`},
 		{`SELECT a FROM t1 INNER JOIN t2 USING (a)`},
 		{`SELECT a FROM t1 FULL JOIN t2 USING (a)`},
+		{`SELECT a FROM t1 INNER HASH JOIN t2 ON a = b`},
+		{`SELECT a FROM t1 LEFT MERGE JOIN t2 USING (a)`},
+		{`SELECT a FROM t1 INNER LOOKUP JOIN t2 ON a = b`},
+		{`SELECT a FROM t1 NATURAL FULL HASH JOIN t2`},
+		{`SELECT hash, merge, lookup FROM t1`},
 		{`SELECT * FROM (t1 WITH ORDINALITY AS o1 CROSS JOIN t2 WITH ORDINALITY AS o2) WITH ORDINALITY AS o3`},
 
 		{`SELECT a FROM t1 AS OF SYSTEM TIME '2016-01-01'`},
@@ -930,6 +1010,7 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT NULL`},
 		{`ALTER TABLE a ALTER COLUMN b DROP DEFAULT`},
 		{`ALTER TABLE a ALTER COLUMN b DROP NOT NULL`},
+		{`ALTER TABLE a ALTER COLUMN b SET NOT NULL`},
 		{`ALTER TABLE a ALTER COLUMN b DROP STORED`},
 
 		{`ALTER TABLE a ALTER COLUMN b SET DATA TYPE INT`},
@@ -938,6 +1019,12 @@ func TestParse(t *testing.T) {
 
 		{`COPY t FROM STDIN`},
 		{`COPY t (a, b, c) FROM STDIN`},
+		{`COPY t FROM STDIN WITH (format 'binary')`},
+		{`COPY t FROM STDIN WITH (delimiter ',', "null" '')`},
+		{`COPY t TO STDOUT`},
+		{`COPY t (a, b) TO STDOUT WITH (format 'csv', header)`},
+		{`COPY (SELECT a FROM t WHERE b > 1) TO STDOUT WITH (format 'binary')`},
+		{`COPY (VALUES (1)) TO STDOUT`},
 
 		{`ALTER TABLE a SPLIT AT VALUES (1)`},
 		{`ALTER TABLE a SPLIT AT SELECT * FROM t`},
@@ -1061,6 +1148,41 @@ func TestParse2(t *testing.T) {
 	}{
 		{`CREATE DATABASE a WITH ENCODING = 'foo'`,
 			`CREATE DATABASE a ENCODING = 'foo'`},
+		{`CREATE TEMP TABLE a (b INT)`,
+			`CREATE TEMPORARY TABLE a (b INT)`},
+		{`CREATE FUNCTION f(x INT) RETURNS INT AS 'SELECT $1' LANGUAGE SQL`,
+			`CREATE FUNCTION f(x INT) RETURNS INT AS 'SELECT $1' LANGUAGE sql`},
+		{`CREATE FUNCTION f(x INT) RETURNS INT AS 'SELECT ''a''' LANGUAGE 'sql'`,
+			`CREATE FUNCTION f(x INT) RETURNS INT AS e'SELECT \'a\'' LANGUAGE sql`},
+		{`CREATE INDEX a ON b USING GIN (c gin_trgm_ops)`,
+			`CREATE INVERTED INDEX a ON b (c gin_trgm_ops)`},
+		{`COPY t FROM STDIN BINARY`,
+			`COPY t FROM STDIN WITH (format 'binary')`},
+		{`COPY t FROM STDIN (FORMAT binary)`,
+			`COPY t FROM STDIN WITH (format 'binary')`},
+		{`COPY t TO STDOUT WITH CSV HEADER DELIMITER AS '|' NULL 'x'`,
+			`COPY t TO STDOUT WITH (format 'csv', header, delimiter '|', "null" 'x')`},
+		{`COPY t TO STDOUT (FORMAT csv, HEADER true)`,
+			`COPY t TO STDOUT WITH (format 'csv', header 'true')`},
+		{`DISCARD TEMP`, `DISCARD TEMPORARY`},
+		{`NOTIFY foo, ''`, `NOTIFY foo`},
+		{`NOTIFY Foo, 'it''s'`, `NOTIFY foo, e'it\'s'`},
+
+		{`DECLARE c CURSOR WITHOUT HOLD FOR SELECT 1`, `DECLARE c CURSOR FOR SELECT 1`},
+		{`DECLARE c NO SCROLL CURSOR WITHOUT HOLD FOR SELECT 1`, `DECLARE c NO SCROLL CURSOR FOR SELECT 1`},
+		{`FETCH c`, `FETCH 1 c`},
+		{`FETCH FROM c`, `FETCH 1 c`},
+		{`FETCH NEXT IN c`, `FETCH 1 c`},
+		{`FETCH PRIOR FROM c`, `FETCH -1 c`},
+		{`FETCH FORWARD c`, `FETCH 1 c`},
+		{`FETCH FORWARD 5 FROM c`, `FETCH 5 c`},
+		{`FETCH FORWARD ALL c`, `FETCH ALL c`},
+		{`FETCH BACKWARD c`, `FETCH -1 c`},
+		{`FETCH BACKWARD 5 c`, `FETCH -5 c`},
+		{`FETCH next`, `FETCH 1 next`},
+		{`FETCH NEXT next`, `FETCH 1 next`},
+		{`MOVE FORWARD 2 IN c`, `MOVE 2 c`},
+		{`CLOSE C`, `CLOSE c`},
 		{`CREATE DATABASE a TEMPLATE = template0`,
 			`CREATE DATABASE a TEMPLATE = 'template0'`},
 		{`CREATE DATABASE a TEMPLATE = invalid`,
@@ -1112,7 +1234,6 @@ func TestParse2(t *testing.T) {
 
 		{`SELECT b <<= c`, `SELECT inet_contained_by_or_equals(b, c)`},
 		{`SELECT b >>= c`, `SELECT inet_contains_or_equals(b, c)`},
-		{`SELECT b && c`, `SELECT inet_contains_or_contained_by(b, c)`},
 
 		// Escaped string literals are not always escaped the same because
 		// '''' and e'\'' scan to the same token. It's more convenient to
@@ -1155,6 +1276,8 @@ func TestParse2(t *testing.T) {
 			`SELECT a FROM t1 LEFT JOIN t2 ON a = b`},
 		{`SELECT a FROM t1 RIGHT OUTER JOIN t2 ON a = b`,
 			`SELECT a FROM t1 RIGHT JOIN t2 ON a = b`},
+		{`SELECT a FROM t1 LEFT OUTER LOOKUP JOIN t2 ON a = b`,
+			`SELECT a FROM t1 LEFT LOOKUP JOIN t2 ON a = b`},
 		// Some functions are nearly keywords.
 		{`SELECT CURRENT_SCHEMA`,
 			`SELECT current_schema()`},
@@ -1307,6 +1430,7 @@ func TestParse2(t *testing.T) {
 		// Special extract syntax
 		{`SELECT EXTRACT(second from now())`,
 			`SELECT extract('second', now())`},
+		{`SELECT GROUPING(a) FROM t GROUP BY a`, `SELECT grouping(a) FROM t GROUP BY a`},
 		// Special trim syntax
 		{`SELECT TRIM('xy' from 'xyxtrimyyx')`,
 			`SELECT btrim('xyxtrimyyx', 'xy')`},
@@ -1341,6 +1465,8 @@ func TestParse2(t *testing.T) {
 			`SELECT a FROM ROWS FROM (generate_series(1, 32)) AS s (x)`},
 		{`SELECT a FROM generate_series(1, 32) WITH ORDINALITY AS s (x)`,
 			`SELECT a FROM ROWS FROM (generate_series(1, 32)) WITH ORDINALITY AS s (x)`},
+		{`SELECT a FROM t bar TABLESAMPLE bernoulli(10) repeatable(1)`,
+			`SELECT a FROM t AS bar TABLESAMPLE BERNOULLI (10) REPEATABLE (1)`},
 
 		// Tuples
 		{`SELECT 1 IN (b)`, `SELECT 1 IN (b,)`},
@@ -1354,6 +1480,8 @@ func TestParse2(t *testing.T) {
 			`SHOW CREATE t`},
 		{`SHOW CREATE SEQUENCE t`,
 			`SHOW CREATE t`},
+		{`SHOW CREATE FUNCTION f`,
+			`SHOW CREATE f`},
 		{`SHOW INDEX FROM t`,
 			`SHOW INDEXES FROM t`},
 		{`SHOW CONSTRAINT FROM t`,
@@ -1597,6 +1725,7 @@ func TestParse2(t *testing.T) {
 		{`ALTER TABLE a ADD b INT FAMILY fam_a`, `ALTER TABLE a ADD COLUMN b INT FAMILY fam_a`},
 		{`ALTER TABLE a DROP b`, `ALTER TABLE a DROP COLUMN b`},
 		{`ALTER TABLE a ALTER b DROP NOT NULL`, `ALTER TABLE a ALTER COLUMN b DROP NOT NULL`},
+		{`ALTER TABLE a ALTER b SET NOT NULL`, `ALTER TABLE a ALTER COLUMN b SET NOT NULL`},
 		{`ALTER TABLE a ALTER b TYPE INT`, `ALTER TABLE a ALTER COLUMN b SET DATA TYPE INT`},
 	}
 	for _, d := range testData {
@@ -1688,6 +1817,10 @@ SET TIME ZONE INTERVAL 'foobar'
 		{`SELECT INTERVAL 'foo'`, `could not parse "foo" as type interval: interval: missing unit at position 0: "foo" at or near "EOF"
 SELECT INTERVAL 'foo'
                      ^
+`},
+		{`DECLARE c SCROLL NO SCROLL CURSOR FOR SELECT 1`, `cannot specify both SCROLL and NO SCROLL at or near "scroll"
+DECLARE c SCROLL NO SCROLL CURSOR FOR SELECT 1
+                    ^
 `},
 		{`SELECT 1 /* hello`, `unterminated comment
 SELECT 1 /* hello
@@ -1954,6 +2087,13 @@ SELECT EXISTS(SELECT 1)[1]
 			`+ ANY <array> is invalid because "+" is not a boolean operator at or near "EOF"
 SELECT 1 + ANY ARRAY[1, 2, 3]
                              ^
+`,
+		},
+		{
+			`SELECT a FROM t TABLESAMPLE foo (10)`,
+			`tablesample method foo does not exist at or near "EOF"
+SELECT a FROM t TABLESAMPLE foo (10)
+                                    ^
 `,
 		},
 		{
@@ -449,6 +449,10 @@ func (s *Scanner) scan(lval *sqlSymType) {
 			s.pos++
 			lval.id = CONTAINS
 			return
+		case '@': // @@
+			s.pos++
+			lval.id = AT_AT
+			return
 		}
 		return
 
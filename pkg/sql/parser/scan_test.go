@@ -67,6 +67,7 @@ func TestScanner(t *testing.T) {
 		{`$`, []int{'$'}},
 		{`&`, []int{'&'}},
 		{`&&`, []int{INET_CONTAINS_OR_CONTAINED_BY}},
+		{`@@`, []int{AT_AT}},
 		{`|`, []int{'|'}},
 		{`||`, []int{CONCAT}},
 		{`#`, []int{'#'}},
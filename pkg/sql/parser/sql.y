@@ -147,6 +147,9 @@ func (u *sqlSymUnion) normalizableTableNames() tree.NormalizableTableNames {
 func (u *sqlSymUnion) indexFlags() *tree.IndexFlags {
     return u.val.(*tree.IndexFlags)
 }
+func (u *sqlSymUnion) tableSample() *tree.TableSample {
+    return u.val.(*tree.TableSample)
+}
 func (u *sqlSymUnion) arraySubscript() *tree.ArraySubscript {
     return u.val.(*tree.ArraySubscript)
 }
@@ -234,6 +237,30 @@ func (u *sqlSymUnion) seqOpt() tree.SequenceOption {
 func (u *sqlSymUnion) seqOpts() []tree.SequenceOption {
     return u.val.([]tree.SequenceOption)
 }
+func (u *sqlSymUnion) funcArg() tree.FunctionArg {
+    return u.val.(tree.FunctionArg)
+}
+func (u *sqlSymUnion) funcArgs() tree.FunctionArgs {
+    return u.val.(tree.FunctionArgs)
+}
+func (u *sqlSymUnion) funcOpt() tree.FunctionOption {
+    return u.val.(tree.FunctionOption)
+}
+func (u *sqlSymUnion) funcOpts() tree.FunctionOptions {
+    return u.val.(tree.FunctionOptions)
+}
+func (u *sqlSymUnion) declareCursor() *tree.DeclareCursor {
+    return u.val.(*tree.DeclareCursor)
+}
+func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
+    return u.val.(tree.CursorStmt)
+}
+func (u *sqlSymUnion) funcSig() tree.FunctionSignature {
+    return u.val.(tree.FunctionSignature)
+}
+func (u *sqlSymUnion) funcSigs() tree.FunctionSignatures {
+    return u.val.(tree.FunctionSignatures)
+}
 func (u *sqlSymUnion) expr() tree.Expr {
     if expr, ok := u.val.(tree.Expr); ok {
         return expr
@@ -461,24 +488,24 @@ func newNameFromStr(s string) *tree.Name {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str> ABORT ACTION ADD ADMIN
+%token <str> ABORT ABSOLUTE ACTION ADD ADMIN
 %token <str> ALL ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
-%token <str> ASYMMETRIC AT
+%token <str> ASYMMETRIC AT AT_AT
 
-%token <str> BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
+%token <str> BACKUP BACKWARD BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
 %token <str> BLOB BOOL BOOLEAN BOTH BTREE BY BYTEA BYTES
 
-%token <str> CACHE CANCEL CASCADE CASE CAST CHANGEFEED CHAR
+%token <str> CACHE CALLED CANCEL CASCADE CASE CAST CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK
-%token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMIT
+%token <str> CLOSE CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMIT
 %token <str> COMMITTED COMPACT CONCAT CONFIGURATION CONFIGURATIONS CONFIGURE
 %token <str> CONFLICT CONSTRAINT CONSTRAINTS CONTAINS COPY COVERING CREATE
-%token <str> CROSS CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
+%token <str> CROSS CSV CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str> CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
-%token <str> CURRENT_USER CYCLE
+%token <str> CURRENT_USER CURSOR CYCLE
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEC DECIMAL DEFAULT
-%token <str> DEALLOCATE DEFERRABLE DELETE DESC
+%token <str> DEALLOCATE DECLARE DEFERRABLE DELETE DELIMITER DESC
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
 %token <str> ELSE ENCODING END ENUM ESCAPE EXCEPT
@@ -489,62 +516,64 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
 %token <str> FILES FILTER
-%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FROM FULL
+%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FORWARD FROM FULL
+%token <str> FUNCTION
 
 %token <str> GIN GRANT GRANTS GREATEST GROUP GROUPING
 
-%token <str> HAVING HIGH HISTOGRAM HOUR
+%token <str> HASH HAVING HEADER HIGH HISTOGRAM HOLD HOUR
 
-%token <str> IMPORT INCREMENT INCREMENTAL IF IFERROR IFNULL ILIKE IN ISERROR
+%token <str> IMMUTABLE IMPORT INCREMENT INCREMENTAL IF IFERROR IFNULL ILIKE IN ISERROR
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS INET_CONTAINS_OR_CONTAINED_BY
 %token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INJECT INTERLEAVE INITIALLY
-%token <str> INNER INSERT INT INT2VECTOR INT2 INT4 INT8 INT64 INTEGER
+%token <str> INNER INPUT INSENSITIVE INSERT INT INT2VECTOR INT2 INT4 INT8 INT64 INTEGER
 %token <str> INTERSECT INTERVAL INTO INVERTED IS ISNULL ISOLATION
 
 %token <str> JOB JOBS JOIN JSON JSONB JSON_SOME_EXISTS JSON_ALL_EXISTS
 
 %token <str> KEY KEYS KV
 
-%token <str> LATERAL LC_CTYPE LC_COLLATE
-%token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
-%token <str> LOCALTIME LOCALTIMESTAMP LOW LSHIFT
+%token <str> LANGUAGE LAST LATERAL LC_CTYPE LC_COLLATE
+%token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT LIST LISTEN LOCAL
+%token <str> LOCALTIME LOCALTIMESTAMP LOOKUP LOW LSHIFT
 
-%token <str> MATCH MINVALUE MAXVALUE MINUTE MONTH
+%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE MINUTE MONTH MOVE
 
 %token <str> NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
-%token <str> NOT NOTHING NOTNULL NULL NULLIF NUMERIC
+%token <str> NOT NOTHING NOTIFY NOTNULL NULL NULLIF NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDVECTOR ON ONLY OPTION OPTIONS OR
 %token <str> ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY OWNED
 
 %token <str> PARENT PARTIAL PARTITION PASSWORD PAUSE PHYSICAL PLACING
-%token <str> PLANS POSITION PRECEDING PRECISION PREPARE PRIMARY PRIORITY
+%token <str> PLANS POSITION PRECEDING PRECISION PREPARE PRIMARY PRIOR PRIORITY
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES
+%token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES REFRESH
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
-%token <str> REMOVE_PATH RENAME REPEATABLE
-%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
+%token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
+%token <str> RELATIVE RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETURNS REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT
 
-%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCROLL SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIAL SERIAL2 SERIAL4 SERIAL8
-%token <str> SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
+%token <str> SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
 %token <str> SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 
-%token <str> START STATISTICS STATUS STDIN STRICT STRING STORE STORED STORING SUBSTRING
+%token <str> STABLE START STATISTICS STATUS STDIN STDOUT STRICT STRING STORE STORED STORING SUBSTRING
 %token <str> SYMMETRIC SYNTAX SYSTEM
 
-%token <str> TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES EXPERIMENTAL_RANGES TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
+%token <str> TABLE TABLES TABLESAMPLE TEMP TEMPLATE TEMPORARY TESTING_RANGES EXPERIMENTAL_RANGES TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
 %token <str> TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO TRAILING TRACE TRANSACTION TREAT TRIM TRUE
-%token <str> TRUNCATE TYPE
+%token <str> TRUNCATE TSQUERY TSVECTOR TYPE
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLISTEN
 %token <str> UPDATE UPSERT USE USER USERS USING UUID
 
 %token <str> VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING VIRTUAL
+%token <str> VOLATILE
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
 
@@ -637,6 +666,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> comment_stmt
 %type <tree.Statement> commit_stmt
 %type <tree.Statement> copy_from_stmt
+%type <tree.Statement> copy_to_stmt
 
 %type <tree.Statement> create_stmt
 %type <tree.Statement> create_changefeed_stmt
@@ -649,10 +679,21 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> create_user_stmt
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_sequence_stmt
+%type <tree.Statement> create_func_stmt
 %type <tree.Statement> create_stats_stmt
 %type <tree.Statement> create_type_stmt
 %type <tree.Statement> delete_stmt
 %type <tree.Statement> discard_stmt
+%type <tree.Statement> listen_stmt
+%type <tree.Statement> notify_stmt
+%type <tree.Statement> unlisten_stmt
+%type <tree.Statement> close_cursor_stmt
+%type <tree.Statement> declare_cursor_stmt
+%type <tree.Statement> fetch_cursor_stmt
+%type <tree.Statement> move_cursor_stmt
+%type <*tree.DeclareCursor> opt_cursor_options
+%type <bool> opt_hold
+%type <tree.CursorStmt> cursor_movement
 
 %type <tree.Statement> drop_stmt
 %type <tree.Statement> drop_ddl_stmt
@@ -663,6 +704,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> drop_user_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
+%type <tree.Statement> drop_func_stmt
 
 %type <tree.Statement> explain_stmt
 %type <tree.Statement> prepare_stmt
@@ -675,6 +717,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> insert_stmt
 %type <tree.Statement> import_stmt
 %type <tree.Statement> pause_stmt
+%type <tree.Statement> refresh_stmt
 %type <tree.Statement> release_stmt
 %type <tree.Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <tree.Statement> resume_stmt
@@ -732,6 +775,8 @@ func newNameFromStr(s string) *tree.Name {
 %type <[]string> opt_incremental
 %type <tree.KVOption> kv_option
 %type <[]tree.KVOption> kv_option_list opt_with_options
+%type <[]tree.KVOption> opt_copy_options copy_generic_option_list copy_legacy_option_list
+%type <tree.KVOption> copy_generic_option copy_legacy_option
 %type <str> import_format
 
 %type <*tree.Select> select_no_parens
@@ -765,6 +810,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.UnresolvedName> func_name
 %type <str> opt_collate
 %type <empty> opt_collate_unimpl
+%type <str> opt_opclass
 
 %type <str> database_name index_name opt_index_name column_name insert_column_item statistics_name window_name
 %type <str> family_name opt_family_name table_alias_name constraint_name target_name zone_name partition_name collation_name
@@ -810,6 +856,8 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.UpdateExpr> set_clause multiple_set_clause
 %type <tree.ArraySubscripts> array_subscripts
 %type <tree.GroupBy> group_clause
+%type <tree.Exprs> group_by_list
+%type <tree.Expr> group_by_item
 %type <*tree.Limit> select_limit
 %type <tree.NormalizableTableNames> relation_expr_list
 %type <tree.ReturningClause> returning_clause
@@ -817,10 +865,18 @@ func newNameFromStr(s string) *tree.Name {
 %type <[]tree.SequenceOption> sequence_option_list opt_sequence_option_list
 %type <tree.SequenceOption> sequence_option_elem
 
+%type <tree.FunctionArgs> opt_func_args func_args
+%type <tree.FunctionArg> func_arg
+%type <tree.FunctionOptions> func_option_list
+%type <tree.FunctionOption> func_option_elem
+%type <tree.FunctionSignatures> func_signature_list
+%type <tree.FunctionSignature> func_signature
+
 %type <bool> all_or_distinct
 %type <empty> join_outer
 %type <tree.JoinCond> join_qual
 %type <str> join_type
+%type <str> join_hint
 
 %type <tree.Exprs> extract_list
 %type <tree.Exprs> overlay_list
@@ -832,6 +888,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Expr> overlay_placing
 
 %type <bool> opt_unique
+%type <bool> opt_temp
 %type <bool> opt_using_gin_btree
 
 %type <*tree.Limit> limit_clause offset_clause opt_limit_clause
@@ -856,6 +913,8 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.IndexFlags> opt_index_flags
 %type <*tree.IndexFlags> index_flags_param
 %type <*tree.IndexFlags> index_flags_param_list
+%type <*tree.TableSample> opt_tablesample
+%type <tree.Expr> opt_repeatable_clause
 %type <tree.Expr> a_expr b_expr c_expr d_expr
 %type <tree.Expr> substr_from substr_for
 %type <tree.Expr> in_expr
@@ -928,7 +987,7 @@ func newNameFromStr(s string) *tree.Name {
 
 %type <tree.Expr> func_application func_expr_common_subexpr special_function
 %type <tree.Expr> func_expr func_expr_windowless
-%type <empty> opt_with
+%type <empty> opt_with opt_as
 %type <*tree.With> with_clause opt_with_clause
 %type <[]*tree.CTE> cte_list
 %type <*tree.CTE> common_table_expr
@@ -964,7 +1023,7 @@ func newNameFromStr(s string) *tree.Name {
 %left      AND
 %right     NOT
 %nonassoc  IS ISNULL NOTNULL   // IS sets precedence for IS NULL, etc
-%nonassoc  '<' '>' '=' LESS_EQUALS GREATER_EQUALS NOT_EQUALS CONTAINS CONTAINED_BY '?' JSON_SOME_EXISTS JSON_ALL_EXISTS
+%nonassoc  '<' '>' '=' LESS_EQUALS GREATER_EQUALS NOT_EQUALS CONTAINS CONTAINED_BY '?' JSON_SOME_EXISTS JSON_ALL_EXISTS AT_AT
 %nonassoc  '~' BETWEEN IN LIKE ILIKE SIMILAR NOT_REGMATCH REGIMATCH NOT_REGIMATCH NOT_LA
 %nonassoc  ESCAPE              // ESCAPE must be just above LIKE/ILIKE/SIMILAR
 %nonassoc  OVERLAPS
@@ -1051,21 +1110,29 @@ stmt:
 | alter_stmt      // help texts in sub-rule
 | backup_stmt     // EXTEND WITH HELP: BACKUP
 | cancel_stmt     // help texts in sub-rule
+| close_cursor_stmt // EXTEND WITH HELP: CLOSE
 | copy_from_stmt
+| copy_to_stmt
 | comment_stmt
 | create_stmt     // help texts in sub-rule
 | deallocate_stmt // EXTEND WITH HELP: DEALLOCATE
+| declare_cursor_stmt // EXTEND WITH HELP: DECLARE
 | delete_stmt     // EXTEND WITH HELP: DELETE
 | discard_stmt    // EXTEND WITH HELP: DISCARD
 | drop_stmt       // help texts in sub-rule
 | execute_stmt    // EXTEND WITH HELP: EXECUTE
 | explain_stmt    // EXTEND WITH HELP: EXPLAIN
 | export_stmt     // EXTEND WITH HELP: EXPORT
+| fetch_cursor_stmt // EXTEND WITH HELP: FETCH
 | grant_stmt      // EXTEND WITH HELP: GRANT
 | insert_stmt     // EXTEND WITH HELP: INSERT
 | import_stmt     // EXTEND WITH HELP: IMPORT
+| listen_stmt     // EXTEND WITH HELP: LISTEN
+| move_cursor_stmt // EXTEND WITH HELP: MOVE
+| notify_stmt     // EXTEND WITH HELP: NOTIFY
 | pause_stmt      // EXTEND WITH HELP: PAUSE JOBS
 | prepare_stmt    // EXTEND WITH HELP: PREPARE
+| refresh_stmt    // EXTEND WITH HELP: REFRESH
 | restore_stmt    // EXTEND WITH HELP: RESTORE
 | resume_stmt     // EXTEND WITH HELP: RESUME JOBS
 | revoke_stmt     // EXTEND WITH HELP: REVOKE
@@ -1081,6 +1148,7 @@ stmt:
 | show_stmt         // help texts in sub-rule
 | transaction_stmt  // help texts in sub-rule
 | truncate_stmt     // EXTEND WITH HELP: TRUNCATE
+| unlisten_stmt     // EXTEND WITH HELP: UNLISTEN
 | update_stmt       // EXTEND WITH HELP: UPDATE
 | upsert_stmt       // EXTEND WITH HELP: UPSERT
 | /* EMPTY */
@@ -1412,7 +1480,10 @@ alter_table_cmd:
     $$.val = &tree.AlterTableDropStored{Column: tree.Name($3)}
   }
   // ALTER TABLE <name> ALTER [COLUMN] <colname> SET NOT NULL
-| ALTER opt_column column_name SET NOT NULL { return unimplemented(sqllex, "alter set non null") }
+| ALTER opt_column column_name SET NOT NULL
+  {
+    $$.val = &tree.AlterTableSetNotNull{Column: tree.Name($3)}
+  }
   // ALTER TABLE <name> DROP [COLUMN] IF EXISTS <colname> [RESTRICT|CASCADE]
 | DROP opt_column IF EXISTS column_name opt_drop_behavior
   {
@@ -1763,14 +1834,110 @@ opt_with_options:
 | /* EMPTY */ {}
 
 copy_from_stmt:
-  COPY table_name opt_column_list FROM STDIN
+  COPY table_name opt_column_list FROM STDIN opt_copy_options
   {
     $$.val = &tree.CopyFrom{
        Table: $2.normalizableTableNameFromUnresolvedName(),
        Columns: $3.nameList(),
        Stdin: true,
+       Options: $6.kvOptions(),
+    }
+  }
+
+copy_to_stmt:
+  COPY table_name opt_column_list TO STDOUT opt_copy_options
+  {
+    $$.val = &tree.CopyTo{
+       Table: $2.normalizableTableNameFromUnresolvedName(),
+       Columns: $3.nameList(),
+       Options: $6.kvOptions(),
     }
   }
+| COPY select_with_parens TO STDOUT opt_copy_options
+  {
+    $$.val = &tree.CopyTo{
+       Statement: $2.selectStmt().(*tree.ParenSelect).Select,
+       Options: $5.kvOptions(),
+    }
+  }
+
+// The options of COPY can be specified either using the parenthesized generic
+// syntax, e.g. WITH (FORMAT csv, HEADER), or using the legacy syntax, e.g.
+// WITH CSV HEADER. Both are represented as a list of key-value options, the
+// legacy ones being translated to their generic equivalent.
+opt_copy_options:
+  opt_with '(' copy_generic_option_list ')'
+  {
+    $$.val = $3.kvOptions()
+  }
+| opt_with copy_legacy_option_list
+  {
+    $$.val = $2.kvOptions()
+  }
+| /* EMPTY */
+  {
+    $$.val = []tree.KVOption(nil)
+  }
+
+copy_generic_option_list:
+  copy_generic_option
+  {
+    $$.val = []tree.KVOption{$1.kvOption()}
+  }
+| copy_generic_option_list ',' copy_generic_option
+  {
+    $$.val = append($1.kvOptions(), $3.kvOption())
+  }
+
+copy_generic_option:
+  unrestricted_name non_reserved_word_or_sconst
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1), Value: tree.NewStrVal($2)}
+  }
+| unrestricted_name TRUE
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1), Value: tree.NewStrVal("true")}
+  }
+| unrestricted_name FALSE
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1), Value: tree.NewStrVal("false")}
+  }
+| unrestricted_name
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1)}
+  }
+
+copy_legacy_option_list:
+  copy_legacy_option
+  {
+    $$.val = []tree.KVOption{$1.kvOption()}
+  }
+| copy_legacy_option_list copy_legacy_option
+  {
+    $$.val = append($1.kvOptions(), $2.kvOption())
+  }
+
+copy_legacy_option:
+  BINARY
+  {
+    $$.val = tree.KVOption{Key: "format", Value: tree.NewStrVal("binary")}
+  }
+| CSV
+  {
+    $$.val = tree.KVOption{Key: "format", Value: tree.NewStrVal("csv")}
+  }
+| HEADER
+  {
+    $$.val = tree.KVOption{Key: "header"}
+  }
+| DELIMITER opt_as SCONST
+  {
+    $$.val = tree.KVOption{Key: "delimiter", Value: tree.NewStrVal($3)}
+  }
+| NULL opt_as SCONST
+  {
+    $$.val = tree.KVOption{Key: "null", Value: tree.NewStrVal($3)}
+  }
 
 // %Help: CANCEL
 // %Category: Group
@@ -1912,10 +2079,11 @@ create_ddl_stmt:
 | create_table_stmt    // EXTEND WITH HELP: CREATE TABLE
 | create_table_as_stmt // EXTEND WITH HELP: CREATE TABLE
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
-| CREATE TABLE error   // SHOW HELP: CREATE TABLE
+| CREATE opt_temp TABLE error // SHOW HELP: CREATE TABLE
 | create_type_stmt     { /* SKIP DOC */ }
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
+| create_func_stmt     // EXTEND WITH HELP: CREATE FUNCTION
 
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
@@ -1998,7 +2166,7 @@ delete_stmt:
 
 // %Help: DISCARD - reset the session to its initial state
 // %Category: Cfg
-// %Text: DISCARD ALL
+// %Text: DISCARD { ALL | TEMPORARY }
 discard_stmt:
   DISCARD ALL
   {
@@ -2006,10 +2174,246 @@ discard_stmt:
   }
 | DISCARD PLANS { return unimplemented(sqllex, "discard plans") }
 | DISCARD SEQUENCES { return unimplemented(sqllex, "discard sequences") }
-| DISCARD TEMP { return unimplemented(sqllex, "discard temp") }
-| DISCARD TEMPORARY { return unimplemented(sqllex, "discard temporary") }
+| DISCARD TEMP
+  {
+    $$.val = &tree.Discard{Mode: tree.DiscardModeTemp}
+  }
+| DISCARD TEMPORARY
+  {
+    $$.val = &tree.Discard{Mode: tree.DiscardModeTemp}
+  }
 | DISCARD error // SHOW HELP: DISCARD
 
+// %Help: LISTEN - listen for notifications on a channel
+// %Category: Misc
+// %Text: LISTEN <channel>
+// %SeeAlso: NOTIFY, UNLISTEN
+listen_stmt:
+  LISTEN name
+  {
+    $$.val = &tree.Listen{Channel: tree.Name($2)}
+  }
+| LISTEN error // SHOW HELP: LISTEN
+
+// %Help: NOTIFY - send a notification on a channel
+// %Category: Misc
+// %Text: NOTIFY <channel> [, <payload>]
+// %SeeAlso: LISTEN, UNLISTEN
+notify_stmt:
+  NOTIFY name
+  {
+    $$.val = &tree.Notify{Channel: tree.Name($2)}
+  }
+| NOTIFY name ',' SCONST
+  {
+    $$.val = &tree.Notify{Channel: tree.Name($2), Payload: $4}
+  }
+| NOTIFY error // SHOW HELP: NOTIFY
+
+// %Help: UNLISTEN - stop listening for notifications
+// %Category: Misc
+// %Text: UNLISTEN { <channel> | * }
+// %SeeAlso: LISTEN, NOTIFY
+unlisten_stmt:
+  UNLISTEN name
+  {
+    $$.val = &tree.Unlisten{Channel: tree.Name($2)}
+  }
+| UNLISTEN '*'
+  {
+    $$.val = &tree.Unlisten{}
+  }
+| UNLISTEN error // SHOW HELP: UNLISTEN
+
+// %Help: DECLARE - define a cursor
+// %Category: Misc
+// %Text:
+// DECLARE <name> [INSENSITIVE] [NO SCROLL] CURSOR [WITHOUT HOLD] FOR <selectclause>
+//
+// Cursors can only be declared in explicit transactions, and are closed
+// when the transaction ends.
+// %SeeAlso: FETCH, MOVE, CLOSE
+declare_cursor_stmt:
+  DECLARE name opt_cursor_options CURSOR opt_hold FOR select_stmt
+  {
+    n := $3.declareCursor()
+    n.Name = tree.Name($2)
+    n.Hold = $5.bool()
+    n.Select = $7.slct()
+    $$.val = n
+  }
+| DECLARE error // SHOW HELP: DECLARE
+
+opt_cursor_options:
+  /* EMPTY */
+  {
+    $$.val = &tree.DeclareCursor{}
+  }
+| opt_cursor_options BINARY
+  {
+    $1.declareCursor().Binary = true
+    $$.val = $1.declareCursor()
+  }
+| opt_cursor_options INSENSITIVE
+  {
+    $1.declareCursor().Insensitive = true
+    $$.val = $1.declareCursor()
+  }
+| opt_cursor_options SCROLL
+  {
+    if $1.declareCursor().Scroll == tree.NoScroll {
+      sqllex.Error("cannot specify both SCROLL and NO SCROLL")
+      return 1
+    }
+    $1.declareCursor().Scroll = tree.Scroll
+    $$.val = $1.declareCursor()
+  }
+| opt_cursor_options NO SCROLL
+  {
+    if $1.declareCursor().Scroll == tree.Scroll {
+      sqllex.Error("cannot specify both SCROLL and NO SCROLL")
+      return 1
+    }
+    $1.declareCursor().Scroll = tree.NoScroll
+    $$.val = $1.declareCursor()
+  }
+
+opt_hold:
+  /* EMPTY */
+  {
+    $$.val = false
+  }
+| WITH HOLD
+  {
+    $$.val = true
+  }
+| WITHOUT HOLD
+  {
+    $$.val = false
+  }
+
+// %Help: FETCH - retrieve rows from a cursor
+// %Category: Misc
+// %Text:
+// FETCH [ <direction> [ FROM | IN ] ] <cursor>
+//
+// Direction:
+//    NEXT | PRIOR | FIRST | LAST | ABSOLUTE <count> | RELATIVE <count>
+//    | <count> | ALL | FORWARD [ <count> | ALL ] | BACKWARD [ <count> | ALL ]
+//
+// Only forward movements are supported.
+// %SeeAlso: DECLARE, MOVE, CLOSE
+fetch_cursor_stmt:
+  FETCH cursor_movement
+  {
+    $$.val = &tree.FetchCursor{CursorStmt: $2.cursorStmt()}
+  }
+| FETCH error // SHOW HELP: FETCH
+
+// %Help: MOVE - position a cursor
+// %Category: Misc
+// %Text:
+// MOVE [ <direction> [ FROM | IN ] ] <cursor>
+//
+// MOVE accepts the same directions as FETCH, and returns the number of
+// rows FETCH would have returned.
+// %SeeAlso: DECLARE, FETCH, CLOSE
+move_cursor_stmt:
+  MOVE cursor_movement
+  {
+    $$.val = &tree.MoveCursor{CursorStmt: $2.cursorStmt()}
+  }
+| MOVE error // SHOW HELP: MOVE
+
+cursor_movement:
+  name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($1), Count: 1}
+  }
+| from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($2), Count: 1}
+  }
+| NEXT opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: 1}
+  }
+| PRIOR opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: -1}
+  }
+| FIRST opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), FetchType: tree.FetchFirst}
+  }
+| LAST opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), FetchType: tree.FetchLast}
+  }
+| ABSOLUTE signed_iconst64 opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchAbsolute, Count: $2.int64()}
+  }
+| RELATIVE signed_iconst64 opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchRelative, Count: $2.int64()}
+  }
+| signed_iconst64 opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: $1.int64()}
+  }
+| ALL opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), FetchType: tree.FetchAll}
+  }
+| FORWARD opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: 1}
+  }
+| FORWARD signed_iconst64 opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Count: $2.int64()}
+  }
+| FORWARD ALL opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchAll}
+  }
+| BACKWARD opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: -1}
+  }
+| BACKWARD signed_iconst64 opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Count: -$2.int64()}
+  }
+| BACKWARD ALL opt_from_or_in name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchBackwardAll}
+  }
+
+from_or_in:
+  FROM
+| IN
+
+opt_from_or_in:
+  from_or_in
+| /* EMPTY */
+
+// %Help: CLOSE - close a cursor
+// %Category: Misc
+// %Text: CLOSE { <cursor> | ALL }
+// %SeeAlso: DECLARE, FETCH, MOVE
+close_cursor_stmt:
+  CLOSE name
+  {
+    $$.val = &tree.CloseCursor{Name: tree.Name($2)}
+  }
+| CLOSE ALL
+  {
+    $$.val = &tree.CloseCursor{}
+  }
+| CLOSE error // SHOW HELP: CLOSE
+
 // %Help: DROP
 // %Category: Group
 // %Text:
@@ -2027,10 +2431,11 @@ drop_ddl_stmt:
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
+| drop_func_stmt     // EXTEND WITH HELP: DROP FUNCTION
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
-// %Text: DROP VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
+// %Text: DROP [MATERIALIZED] VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
 // %SeeAlso: WEBDOCS/drop-index.html
 drop_view_stmt:
   DROP VIEW table_name_list opt_drop_behavior
@@ -2041,7 +2446,16 @@ drop_view_stmt:
   {
     $$.val = &tree.DropView{Names: $5.normalizableTableNames(), IfExists: true, DropBehavior: $6.dropBehavior()}
   }
+| DROP MATERIALIZED VIEW table_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropView{Names: $4.normalizableTableNames(), IfExists: false, DropBehavior: $5.dropBehavior(), IsMaterialized: true}
+  }
+| DROP MATERIALIZED VIEW IF EXISTS table_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropView{Names: $6.normalizableTableNames(), IfExists: true, DropBehavior: $7.dropBehavior(), IsMaterialized: true}
+  }
 | DROP VIEW error // SHOW HELP: DROP VIEW
+| DROP MATERIALIZED VIEW error // SHOW HELP: DROP VIEW
 
 // %Help: DROP SEQUENCE - remove a sequence
 // %Category: DDL
@@ -2058,6 +2472,45 @@ drop_sequence_stmt:
   }
 | DROP SEQUENCE error // SHOW HELP: DROP VIEW
 
+// %Help: DROP FUNCTION - remove a function
+// %Category: DDL
+// %Text: DROP FUNCTION [IF EXISTS] <funcname> [ ( [<argtype> [, ...]] ) ] [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE FUNCTION
+drop_func_stmt:
+  DROP FUNCTION func_signature_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{Functions: $3.funcSigs(), IfExists: false, DropBehavior: $4.dropBehavior()}
+  }
+| DROP FUNCTION IF EXISTS func_signature_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{Functions: $5.funcSigs(), IfExists: true, DropBehavior: $6.dropBehavior()}
+  }
+| DROP FUNCTION error // SHOW HELP: DROP FUNCTION
+
+func_signature_list:
+  func_signature
+  {
+    $$.val = tree.FunctionSignatures{$1.funcSig()}
+  }
+| func_signature_list ',' func_signature
+  {
+    $$.val = append($1.funcSigs(), $3.funcSig())
+  }
+
+func_signature:
+  db_object_name
+  {
+    $$.val = tree.FunctionSignature{Name: $1.normalizableTableNameFromUnresolvedName()}
+  }
+| db_object_name '(' ')'
+  {
+    $$.val = tree.FunctionSignature{Name: $1.normalizableTableNameFromUnresolvedName(), HasArgTypes: true}
+  }
+| db_object_name '(' type_list ')'
+  {
+    $$.val = tree.FunctionSignature{Name: $1.normalizableTableNameFromUnresolvedName(), HasArgTypes: true, ArgTypes: $3.colTypes()}
+  }
+
 // %Help: DROP TABLE - remove a table
 // %Category: DDL
 // %Text: DROP TABLE [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
@@ -2164,6 +2617,7 @@ table_name_list:
 // %Text:
 // EXPLAIN <statement>
 // EXPLAIN ([PLAN ,] <planoptions...> ) <statement>
+// EXPLAIN ANALYZE [(PLAN)] <statement>
 // EXPLAIN [ANALYZE] (DISTSQL) <statement>
 //
 // Explainable statements:
@@ -2184,6 +2638,10 @@ explain_stmt:
   {
     $$.val = &tree.Explain{Options: $3.strs(), Statement: $5.stmt()}
   }
+| EXPLAIN ANALYZE explainable_stmt
+  {
+    $$.val = &tree.Explain{Options: []string{$2}, Statement: $3.stmt()}
+  }
 | EXPLAIN ANALYZE '(' explain_option_list ')' explainable_stmt
   {
     $$.val = &tree.Explain{Options: append($4.strs(), $2), Statement: $6.stmt()}
@@ -3179,9 +3637,9 @@ show_transaction_stmt:
   }
 | SHOW TRANSACTION error // SHOW HELP: SHOW TRANSACTION
 
-// %Help: SHOW CREATE - display the CREATE statement for a table, sequence or view
+// %Help: SHOW CREATE - display the CREATE statement for a table, sequence, view or function
 // %Category: DDL
-// %Text: SHOW CREATE [ TABLE | SEQUENCE | VIEW ] <tablename>
+// %Text: SHOW CREATE [ TABLE | SEQUENCE | VIEW | FUNCTION ] <tablename>
 // %SeeAlso: WEBDOCS/show-create-table.html
 show_create_stmt:
   SHOW CREATE table_name
@@ -3199,6 +3657,7 @@ create_kw:
   TABLE
 | VIEW
 | SEQUENCE
+| FUNCTION
 
 // %Help: SHOW USERS - list defined users
 // %Category: Priv
@@ -3529,8 +3988,8 @@ pause_stmt:
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
 // %Text:
-// CREATE TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
-// CREATE TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
+// CREATE [TEMPORARY] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
+// CREATE [TEMPORARY] TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
 //
 // Table elements:
 //    <name> <type> [<qualifiers...>]
@@ -3559,55 +4018,73 @@ pause_stmt:
 // WEBDOCS/create-table.html
 // WEBDOCS/create-table-as.html
 create_table_stmt:
-  CREATE TABLE table_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
+  CREATE opt_temp TABLE table_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
   {
     $$.val = &tree.CreateTable{
-      Table: $3.normalizableTableNameFromUnresolvedName(),
+      Table: $4.normalizableTableNameFromUnresolvedName(),
       IfNotExists: false,
-      Interleave: $7.interleave(),
-      Defs: $5.tblDefs(),
+      Temporary: $2.bool(),
+      Interleave: $8.interleave(),
+      Defs: $6.tblDefs(),
       AsSource: nil,
       AsColumnNames: nil,
-      PartitionBy: $8.partitionBy(),
+      PartitionBy: $9.partitionBy(),
     }
   }
-| CREATE TABLE IF NOT EXISTS table_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
+| CREATE opt_temp TABLE IF NOT EXISTS table_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
   {
     $$.val = &tree.CreateTable{
-      Table: $6.normalizableTableNameFromUnresolvedName(),
+      Table: $7.normalizableTableNameFromUnresolvedName(),
       IfNotExists: true,
-      Interleave: $10.interleave(),
-      Defs: $8.tblDefs(),
+      Temporary: $2.bool(),
+      Interleave: $11.interleave(),
+      Defs: $9.tblDefs(),
       AsSource: nil,
       AsColumnNames: nil,
-      PartitionBy: $11.partitionBy(),
+      PartitionBy: $12.partitionBy(),
     }
   }
 
 create_table_as_stmt:
-  CREATE TABLE table_name opt_column_list AS select_stmt
+  CREATE opt_temp TABLE table_name opt_column_list AS select_stmt
   {
     $$.val = &tree.CreateTable{
-      Table: $3.normalizableTableNameFromUnresolvedName(),
+      Table: $4.normalizableTableNameFromUnresolvedName(),
       IfNotExists: false,
+      Temporary: $2.bool(),
       Interleave: nil,
       Defs: nil,
-      AsSource: $6.slct(),
-      AsColumnNames: $4.nameList(),
+      AsSource: $7.slct(),
+      AsColumnNames: $5.nameList(),
     }
   }
-| CREATE TABLE IF NOT EXISTS table_name opt_column_list AS select_stmt
+| CREATE opt_temp TABLE IF NOT EXISTS table_name opt_column_list AS select_stmt
   {
     $$.val = &tree.CreateTable{
-      Table: $6.normalizableTableNameFromUnresolvedName(),
+      Table: $7.normalizableTableNameFromUnresolvedName(),
       IfNotExists: true,
+      Temporary: $2.bool(),
       Interleave: nil,
       Defs: nil,
-      AsSource: $9.slct(),
-      AsColumnNames: $7.nameList(),
+      AsSource: $10.slct(),
+      AsColumnNames: $8.nameList(),
     }
   }
 
+opt_temp:
+  TEMPORARY
+  {
+    $$.val = true
+  }
+| TEMP
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
 opt_table_elem_list:
   table_elem_list
 | /* EMPTY */
@@ -3860,7 +4337,7 @@ col_qualification_elem:
  }
 
 index_def:
-  INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
+  INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by where_clause
   {
     $$.val = &tree.IndexTableDef{
       Name:    tree.Name($2),
@@ -3868,9 +4345,10 @@ index_def:
       Storing: $6.nameList(),
       Interleave: $7.interleave(),
       PartitionBy: $8.partitionBy(),
+      Predicate: $9.expr(),
     }
   }
-| UNIQUE INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
+| UNIQUE INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by where_clause
   {
     $$.val = &tree.UniqueConstraintTableDef{
       IndexTableDef: tree.IndexTableDef {
@@ -3879,6 +4357,7 @@ index_def:
         Storing: $7.nameList(),
         Interleave: $8.interleave(),
         PartitionBy: $9.partitionBy(),
+        Predicate: $10.expr(),
       },
     }
   }
@@ -3921,7 +4400,7 @@ constraint_elem:
       Expr: $3.expr(),
     }
   }
-| UNIQUE '(' index_params ')' opt_storing opt_interleave opt_partition_by
+| UNIQUE '(' index_params ')' opt_storing opt_interleave opt_partition_by where_clause
   {
     $$.val = &tree.UniqueConstraintTableDef{
       IndexTableDef: tree.IndexTableDef{
@@ -3929,6 +4408,7 @@ constraint_elem:
         Storing: $5.nameList(),
         Interleave: $6.interleave(),
         PartitionBy: $7.partitionBy(),
+        Predicate: $8.expr(),
       },
     }
   }
@@ -4066,6 +4546,91 @@ numeric_only:
     $$.val = $1.numVal()
   }
 
+// %Help: CREATE FUNCTION - create a new function
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] FUNCTION <funcname> ( [ [<argname>] <argtype> [, ...] ] )
+//   RETURNS <rettype>
+//   LANGUAGE SQL
+//   [IMMUTABLE | STABLE | VOLATILE]
+//   [CALLED ON NULL INPUT | RETURNS NULL ON NULL INPUT | STRICT]
+//   AS '<body>'
+//
+// The body consists of one or more SQL statements separated by semicolons,
+// which refer to the arguments as $1, $2, etc. The function returns the
+// first column of the first row produced by the last statement.
+// %SeeAlso: DROP FUNCTION
+create_func_stmt:
+  CREATE FUNCTION db_object_name '(' opt_func_args ')' RETURNS typename func_option_list
+  {
+    $$.val = &tree.CreateFunction{
+      Name: $3.normalizableTableNameFromUnresolvedName(),
+      Args: $5.funcArgs(),
+      ReturnType: $8.colType(),
+      Options: $9.funcOpts(),
+    }
+  }
+| CREATE OR REPLACE FUNCTION db_object_name '(' opt_func_args ')' RETURNS typename func_option_list
+  {
+    $$.val = &tree.CreateFunction{
+      Replace: true,
+      Name: $5.normalizableTableNameFromUnresolvedName(),
+      Args: $7.funcArgs(),
+      ReturnType: $10.colType(),
+      Options: $11.funcOpts(),
+    }
+  }
+| CREATE FUNCTION error // SHOW HELP: CREATE FUNCTION
+| CREATE OR REPLACE FUNCTION error // SHOW HELP: CREATE FUNCTION
+
+opt_func_args:
+  func_args
+| /* EMPTY */
+  {
+    $$.val = tree.FunctionArgs(nil)
+  }
+
+func_args:
+  func_arg
+  {
+    $$.val = tree.FunctionArgs{$1.funcArg()}
+  }
+| func_args ',' func_arg
+  {
+    $$.val = append($1.funcArgs(), $3.funcArg())
+  }
+
+func_arg:
+  IDENT typename
+  {
+    $$.val = tree.FunctionArg{Name: tree.Name($1), Type: $2.colType()}
+  }
+| typename
+  {
+    $$.val = tree.FunctionArg{Type: $1.colType()}
+  }
+
+func_option_list:
+  func_option_elem
+  {
+    $$.val = tree.FunctionOptions{$1.funcOpt()}
+  }
+| func_option_list func_option_elem
+  {
+    $$.val = append($1.funcOpts(), $2.funcOpt())
+  }
+
+func_option_elem:
+  AS SCONST                    { $$.val = tree.FunctionOption{Name: tree.FuncOptAs, StrVal: $2} }
+| LANGUAGE non_reserved_word_or_sconst
+                               { $$.val = tree.FunctionOption{Name: tree.FuncOptLanguage, StrVal: $2} }
+| IMMUTABLE                    { $$.val = tree.FunctionOption{Name: tree.FuncOptImmutable} }
+| STABLE                       { $$.val = tree.FunctionOption{Name: tree.FuncOptStable} }
+| VOLATILE                     { $$.val = tree.FunctionOption{Name: tree.FuncOptVolatile} }
+| STRICT                       { $$.val = tree.FunctionOption{Name: tree.FuncOptStrict} }
+| CALLED ON NULL INPUT         { $$.val = tree.FunctionOption{Name: tree.FuncOptCalledOnNullInput} }
+| RETURNS NULL ON NULL INPUT   { $$.val = tree.FunctionOption{Name: tree.FuncOptReturnsNullOnNullInput} }
+
 // %Help: CREATE SEQUENCE - create a new sequence
 // %Category: DDL
 // %Text:
@@ -4183,8 +4748,8 @@ create_role_stmt:
 
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
-// %SeeAlso: CREATE TABLE, SHOW CREATE, WEBDOCS/create-view.html
+// %Text: CREATE [MATERIALIZED] VIEW <viewname> [( <colnames...> )] AS <source>
+// %SeeAlso: CREATE TABLE, REFRESH, SHOW CREATE, WEBDOCS/create-view.html
 create_view_stmt:
   CREATE VIEW view_name opt_column_list AS select_stmt
   {
@@ -4194,7 +4759,17 @@ create_view_stmt:
       AsSource: $6.slct(),
     }
   }
+| CREATE MATERIALIZED VIEW view_name opt_column_list AS select_stmt
+  {
+    $$.val = &tree.CreateView{
+      Name: $4.normalizableTableNameFromUnresolvedName(),
+      ColumnNames: $5.nameList(),
+      AsSource: $7.slct(),
+      Materialized: true,
+    }
+  }
 | CREATE VIEW error // SHOW HELP: CREATE VIEW
+| CREATE MATERIALIZED VIEW error // SHOW HELP: CREATE VIEW
 
 // TODO(a-robinson): CREATE OR REPLACE VIEW support (#2971).
 
@@ -4220,6 +4795,7 @@ create_type_stmt:
 // CREATE [UNIQUE | INVERTED] INDEX [IF NOT EXISTS] [<idxname>]
 //        ON <tablename> ( <colname> [ASC | DESC] [, ...] )
 //        [STORING ( <colnames...> )] [<interleave>]
+//        [WHERE <predicate>]
 //
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
@@ -4227,7 +4803,7 @@ create_type_stmt:
 // %SeeAlso: CREATE TABLE, SHOW INDEXES, SHOW CREATE,
 // WEBDOCS/create-index.html
 create_index_stmt:
-  CREATE opt_unique INDEX opt_index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by
+  CREATE opt_unique INDEX opt_index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by where_clause
   {
     $$.val = &tree.CreateIndex{
       Name:    tree.Name($4),
@@ -4238,9 +4814,10 @@ create_index_stmt:
       Interleave: $12.interleave(),
       PartitionBy: $13.partitionBy(),
       Inverted: $7.bool(),
+      Predicate: $14.expr(),
     }
   }
-| CREATE opt_unique INDEX IF NOT EXISTS index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by
+| CREATE opt_unique INDEX IF NOT EXISTS index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by where_clause
   {
     $$.val = &tree.CreateIndex{
       Name:        tree.Name($7),
@@ -4252,6 +4829,7 @@ create_index_stmt:
       Interleave: $15.interleave(),
       PartitionBy: $16.partitionBy(),
       Inverted: $10.bool(),
+      Predicate: $17.expr(),
     }
   }
 | CREATE INVERTED INDEX opt_index_name ON table_name '(' index_params ')'
@@ -4314,9 +4892,9 @@ index_params:
 // expressions in parens. For backwards-compatibility reasons, we allow an
 // expression that's just a function call to be written without parens.
 index_elem:
-  column_name opt_collate_unimpl opt_asc_desc
+  column_name opt_collate_unimpl opt_opclass opt_asc_desc
   {
-    $$.val = tree.IndexElem{Column: tree.Name($1), Direction: $3.dir()}
+    $$.val = tree.IndexElem{Column: tree.Name($1), OpClass: tree.Name($3), Direction: $4.dir()}
   }
 | func_expr_windowless opt_collate_unimpl opt_asc_desc { return unimplemented(sqllex, "index_elem func expr (computed indexes)") }
 | '(' a_expr ')' opt_collate_unimpl opt_asc_desc { return unimplemented(sqllex, "index_elem a_expr (computed indexes)") }
@@ -4329,6 +4907,12 @@ opt_collate_unimpl:
   COLLATE collation_name { return unimplementedWithIssue(sqllex, 16619) }
 | /* EMPTY */ {}
 
+// An operator class selects how an index stores the values of a column, for
+// instance gin_trgm_ops for the trigrams of a string.
+opt_opclass:
+  name { $$ = $1 }
+| /* EMPTY */ { $$ = "" }
+
 opt_asc_desc:
   ASC
   {
@@ -4420,9 +5004,20 @@ opt_set_data:
   SET DATA {}
 | /* EMPTY */ {}
 
-// %Help: RELEASE - complete a retryable block
+// %Help: REFRESH - recompute the contents of a materialized view
+// %Category: DDL
+// %Text: REFRESH MATERIALIZED VIEW <viewname>
+// %SeeAlso: CREATE VIEW
+refresh_stmt:
+  REFRESH MATERIALIZED VIEW table_name
+  {
+    $$.val = &tree.RefreshMaterializedView{Name: $4.normalizableTableNameFromUnresolvedName()}
+  }
+| REFRESH error // SHOW HELP: REFRESH
+
+// %Help: RELEASE - destroy a savepoint or complete a retryable block
 // %Category: Txn
-// %Text: RELEASE [SAVEPOINT] cockroach_restart
+// %Text: RELEASE [SAVEPOINT] <savepoint name>
 // %SeeAlso: SAVEPOINT, WEBDOCS/savepoint.html
 release_stmt:
   RELEASE savepoint_name
@@ -4453,9 +5048,9 @@ resume_stmt:
   }
 | RESUME error // SHOW HELP: RESUME JOBS
 
-// %Help: SAVEPOINT - start a retryable block
+// %Help: SAVEPOINT - define a savepoint or start a retryable block
 // %Category: Txn
-// %Text: SAVEPOINT cockroach_restart
+// %Text: SAVEPOINT <savepoint name>
 // %SeeAlso: RELEASE, WEBDOCS/savepoint.html
 savepoint_stmt:
   SAVEPOINT name
@@ -4525,7 +5120,7 @@ opt_abort_mod:
 
 // %Help: ROLLBACK - abort the current transaction
 // %Category: Txn
-// %Text: ROLLBACK [TRANSACTION] [TO [SAVEPOINT] cockroach_restart]
+// %Text: ROLLBACK [TRANSACTION] [TO [SAVEPOINT] <savepoint name>]
 // %SeeAlso: BEGIN, COMMIT, SAVEPOINT, WEBDOCS/rollback-transaction.html
 rollback_stmt:
   ROLLBACK opt_to_savepoint
@@ -5183,6 +5778,10 @@ opt_with:
   WITH {}
 | /* EMPTY */ {}
 
+opt_as:
+  AS {}
+| /* EMPTY */ {}
+
 opt_with_clause:
   with_clause
   {
@@ -5370,7 +5969,7 @@ first_or_next:
 // Each item in the group_clause list is either an expression tree or a
 // GroupingSet node of some type.
 group_clause:
-  GROUP BY expr_list
+  GROUP BY group_by_list
   {
     $$.val = tree.GroupBy($3.exprs())
   }
@@ -5379,6 +5978,32 @@ group_clause:
     $$.val = tree.GroupBy(nil)
   }
 
+group_by_list:
+  group_by_item
+  {
+    $$.val = tree.Exprs{$1.expr()}
+  }
+| group_by_list ',' group_by_item
+  {
+    $$.val = append($1.exprs(), $3.expr())
+  }
+
+// The empty grouping set () is parsed by a_expr as an empty tuple.
+group_by_item:
+  a_expr
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.Cube, Exprs: $3.exprs()}
+  }
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.Rollup, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.GroupingSetsList, Exprs: $4.exprs()}
+  }
+
 having_clause:
   HAVING a_expr
   {
@@ -5504,6 +6129,7 @@ opt_index_flags:
 //   <source> NATURAL { [INNER] | { LEFT | RIGHT | FULL } [OUTER] } JOIN <source>
 //   <source> CROSS JOIN <source>
 //   <source> WITH ORDINALITY
+//   <tablename> [AS <alias>] TABLESAMPLE { BERNOULLI | SYSTEM } ( <percent> ) [REPEATABLE ( <seed> )]
 //   '[' EXPLAIN ... ']'
 //   '[' SHOW ... ']'
 //
@@ -5527,13 +6153,14 @@ table_ref:
         As:         $8.aliasClause(),
     }
   }
-| relation_expr opt_index_flags opt_ordinality opt_alias_clause
+| relation_expr opt_index_flags opt_ordinality opt_alias_clause opt_tablesample
   {
     $$.val = &tree.AliasedTableExpr{
       Expr:       $1.newNormalizableTableNameFromUnresolvedName(),
       IndexFlags: $2.indexFlags(),
       Ordinality: $3.bool(),
       As:         $4.aliasClause(),
+      Sample:     $5.tableSample(),
     }
   }
 | select_with_parens opt_ordinality opt_alias_clause
@@ -5622,6 +6249,31 @@ tableref_col_list:
     $$.val = append($1.tableRefCols(), tree.ColumnID($3.int64()))
   }
 
+opt_tablesample:
+  TABLESAMPLE name '(' a_expr ')' opt_repeatable_clause
+  {
+    method, ok := tree.TableSampleMethodFromString($2)
+    if !ok {
+      sqllex.Error(fmt.Sprintf("tablesample method %s does not exist", $2))
+      return 1
+    }
+    $$.val = &tree.TableSample{Method: method, Percent: $4.expr(), Seed: $6.expr()}
+  }
+| /* EMPTY */
+  {
+    $$.val = (*tree.TableSample)(nil)
+  }
+
+opt_repeatable_clause:
+  REPEATABLE '(' a_expr ')'
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
 opt_ordinality:
   WITH_LA ORDINALITY
   {
@@ -5659,6 +6311,10 @@ joined_table:
   {
     $$.val = &tree.JoinTableExpr{Join: $2, Left: $1.tblExpr(), Right: $4.tblExpr(), Cond: $5.joinCond()}
   }
+| table_ref join_type join_hint JOIN table_ref join_qual
+  {
+    $$.val = &tree.JoinTableExpr{Join: $2, Hint: $3, Left: $1.tblExpr(), Right: $5.tblExpr(), Cond: $6.joinCond()}
+  }
 | table_ref JOIN table_ref join_qual
   {
     $$.val = &tree.JoinTableExpr{Join: tree.AstJoin, Left: $1.tblExpr(), Right: $3.tblExpr(), Cond: $4.joinCond()}
@@ -5667,6 +6323,10 @@ joined_table:
   {
     $$.val = &tree.JoinTableExpr{Join: $3, Left: $1.tblExpr(), Right: $5.tblExpr(), Cond: tree.NaturalJoinCond{}}
   }
+| table_ref NATURAL join_type join_hint JOIN table_ref
+  {
+    $$.val = &tree.JoinTableExpr{Join: $3, Hint: $4, Left: $1.tblExpr(), Right: $6.tblExpr(), Cond: tree.NaturalJoinCond{}}
+  }
 | table_ref NATURAL JOIN table_ref
   {
     $$.val = &tree.JoinTableExpr{Join: tree.AstJoin, Left: $1.tblExpr(), Right: $4.tblExpr(), Cond: tree.NaturalJoinCond{}}
@@ -5720,6 +6380,22 @@ join_type:
     $$ = tree.AstInnerJoin
   }
 
+// A join hint forces the algorithm used to execute a join. The join type must
+// be specified explicitly when a hint is used, e.g. INNER HASH JOIN.
+join_hint:
+  HASH
+  {
+    $$ = tree.AstHash
+  }
+| MERGE
+  {
+    $$ = tree.AstMerge
+  }
+| LOOKUP
+  {
+    $$ = tree.AstLookup
+  }
+
 // OUTER is just noise...
 join_outer:
   OUTER {}
@@ -5935,6 +6611,14 @@ const_typename:
   {
     $$.val = coltypes.INet
   }
+| TSQUERY
+  {
+    $$.val = coltypes.TSQuery
+  }
+| TSVECTOR
+  {
+    $$.val = coltypes.TSVector
+  }
 | BIGSERIAL
   {
     $$.val = coltypes.BigSerial
@@ -6402,6 +7086,10 @@ a_expr:
   {
     $$.val = &tree.ComparisonExpr{Operator: tree.ContainedBy, Left: $1.expr(), Right: $3.expr()}
   }
+| a_expr AT_AT a_expr
+  {
+    $$.val = &tree.ComparisonExpr{Operator: tree.TSMatches, Left: $1.expr(), Right: $3.expr()}
+  }
 | a_expr '=' a_expr
   {
     $$.val = &tree.ComparisonExpr{Operator: tree.EQ, Left: $1.expr(), Right: $3.expr()}
@@ -6444,7 +7132,7 @@ a_expr:
   }
 | a_expr INET_CONTAINS_OR_CONTAINED_BY a_expr
   {
-    $$.val = &tree.FuncExpr{Func: tree.WrapFunction("inet_contains_or_contained_by"), Exprs: tree.Exprs{$1.expr(), $3.expr()}}
+    $$.val = &tree.ComparisonExpr{Operator: tree.Overlaps, Left: $1.expr(), Right: $3.expr()}
   }
 | a_expr INET_CONTAINS_OR_EQUALS a_expr
   {
@@ -6927,9 +7615,6 @@ d_expr:
     $$.val = $2.expr()
   }
 
-// TODO(pmattis): Support this notation?
-// | GROUPING '(' expr_list ')' { return unimplemented(sqllex) }
-
 func_application:
   func_name '(' ')'
   {
@@ -7094,6 +7779,11 @@ special_function:
     $$.val = &tree.FuncExpr{Func: tree.WrapFunction($1)}
   }
 | CURRENT_USER '(' error { return helpWithFunctionByName(sqllex, $1) }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &tree.FuncExpr{Func: tree.WrapFunction($1), Exprs: $3.exprs()}
+  }
+| GROUPING '(' error { return helpWithFunctionByName(sqllex, $1) }
 | EXTRACT '(' extract_list ')'
   {
     $$.val = &tree.FuncExpr{Func: tree.WrapFunction($1), Exprs: $3.exprs()}
@@ -8136,14 +8826,17 @@ unrestricted_name:
 // "Unreserved" keywords --- available for use as any kind of name.
 unreserved_keyword:
   ABORT
+| ABSOLUTE
 | ACTION
 | ADD
 | ADMIN
 | ALTER
 | AT
 | BACKUP
+| BACKWARD
 | BEGIN
 | BIGSERIAL
+| BINARY
 | BLOB
 | BOOL
 | BTREE
@@ -8151,9 +8844,11 @@ unreserved_keyword:
 | BYTEA
 | BYTES
 | CACHE
+| CALLED
 | CANCEL
 | CASCADE
 | CHANGEFEED
+| CLOSE
 | CLUSTER
 | COLUMNS
 | COMMENT
@@ -8167,8 +8862,10 @@ unreserved_keyword:
 | CONSTRAINTS
 | COPY
 | COVERING
+| CSV
 | CUBE
 | CURRENT
+| CURSOR
 | CYCLE
 | DATA
 | DATABASE
@@ -8176,7 +8873,9 @@ unreserved_keyword:
 | DATE
 | DAY
 | DEALLOCATE
+| DECLARE
 | DELETE
+| DELIMITER
 | DISCARD
 | DOMAIN
 | DOUBLE
@@ -8200,17 +8899,25 @@ unreserved_keyword:
 | FLOAT8
 | FOLLOWING
 | FORCE_INDEX
+| FORWARD
+| FUNCTION
 | GIN
 | GRANTS
+| HASH
+| HEADER
 | HIGH
 | HISTOGRAM
+| HOLD
 | HOUR
+| IMMUTABLE
 | IMPORT
 | INCREMENT
 | INCREMENTAL
 | INDEXES
 | INET
 | INJECT
+| INPUT
+| INSENSITIVE
 | INSERT
 | INT2
 | INT2VECTOR
@@ -8227,17 +8934,24 @@ unreserved_keyword:
 | KEY
 | KEYS
 | KV
+| LANGUAGE
+| LAST
 | LC_COLLATE
 | LC_CTYPE
 | LEASE
 | LESS
 | LEVEL
 | LIST
+| LISTEN
 | LOCAL
+| LOOKUP
 | LOW
 | MATCH
+| MATERIALIZED
+| MERGE
 | MINUTE
 | MONTH
+| MOVE
 | NAMES
 | NAN
 | NAME
@@ -8245,6 +8959,7 @@ unreserved_keyword:
 | NO
 | NORMAL
 | NO_INDEX_JOIN
+| NOTIFY
 | OF
 | OFF
 | OID
@@ -8263,6 +8978,7 @@ unreserved_keyword:
 | PLANS
 | PRECEDING
 | PREPARE
+| PRIOR
 | PRIORITY
 | QUERIES
 | QUERY
@@ -8271,24 +8987,29 @@ unreserved_keyword:
 | READ
 | RECURSIVE
 | REF
+| REFRESH
 | REGCLASS
 | REGPROC
 | REGPROCEDURE
 | REGNAMESPACE
 | REGTYPE
+| RELATIVE
 | RELEASE
 | RENAME
 | REPEATABLE
+| REPLACE
 | RESET
 | RESTORE
 | RESTRICT
 | RESUME
+| RETURNS
 | REVOKE
 | ROLE
 | ROLES
 | ROLLBACK
 | ROLLUP
 | ROWS
+| SETS
 | SETTING
 | SETTINGS
 | STATUS
@@ -8296,6 +9017,7 @@ unreserved_keyword:
 | SCATTER
 | SCHEMA
 | SCHEMAS
+| SCROLL
 | SCRUB
 | SEARCH
 | SECOND
@@ -8314,9 +9036,11 @@ unreserved_keyword:
 | SMALLSERIAL
 | SNAPSHOT
 | SQL
+| STABLE
 | START
 | STATISTICS
 | STDIN
+| STDOUT
 | STORE
 | STORED
 | STORING
@@ -8336,10 +9060,13 @@ unreserved_keyword:
 | TRACE
 | TRANSACTION
 | TRUNCATE
+| TSQUERY
+| TSVECTOR
 | TYPE
 | UNBOUNDED
 | UNCOMMITTED
 | UNKNOWN
+| UNLISTEN
 | UPDATE
 | UPSERT
 | UUID
@@ -8350,6 +9077,7 @@ unreserved_keyword:
 | VALUE
 | VARYING
 | VIEW
+| VOLATILE
 | WITHIN
 | WITHOUT
 | WRITE
@@ -8442,6 +9170,7 @@ type_func_name_keyword:
 | OVERLAPS
 | RIGHT
 | SIMILAR
+| TABLESAMPLE
 
 // Reserved keyword --- these keywords are usable only as a unrestricted_name.
 //
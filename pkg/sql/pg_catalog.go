@@ -31,6 +31,7 @@ import (
 	"bytes"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -266,10 +267,11 @@ CREATE TABLE pg_catalog.pg_auth_members (
 }
 
 var (
-	relKindTable    = tree.NewDString("r")
-	relKindIndex    = tree.NewDString("i")
-	relKindView     = tree.NewDString("v")
-	relKindSequence = tree.NewDString("S")
+	relKindTable            = tree.NewDString("r")
+	relKindIndex            = tree.NewDString("i")
+	relKindView             = tree.NewDString("v")
+	relKindMaterializedView = tree.NewDString("m")
+	relKindSequence         = tree.NewDString("S")
 
 	relPersistencePermanent = tree.NewDString("p")
 )
@@ -313,7 +315,9 @@ CREATE TABLE pg_catalog.pg_class (
 			func(db *sqlbase.DatabaseDescriptor, scName string, table *sqlbase.TableDescriptor) error {
 				// The only difference between tables, views and sequences is the relkind column.
 				relKind := relKindTable
-				if table.IsView() {
+				if table.IsMaterializedView {
+					relKind = relKindMaterializedView
+				} else if table.IsView() {
 					relKind = relKindView
 				} else if table.IsSequence() {
 					relKind = relKindSequence
@@ -992,6 +996,10 @@ CREATE TABLE pg_catalog.pg_index (
 					if err != nil {
 						return err
 					}
+					indpred := tree.DNull
+					if index.IsPartial() {
+						indpred = tree.NewDString(index.Predicate)
+					}
 					return addRow(
 						h.IndexOid(db, scName, table, index), // indexrelid
 						tableOid, // indrelid
@@ -1011,7 +1019,7 @@ CREATE TABLE pg_catalog.pg_index (
 						indclass,                                 // indclass
 						indoption,                                // indoption
 						tree.DNull,                               // indexprs
-						tree.DNull,                               // indpred
+						indpred,                                  // indpred
 					)
 				})
 			})
@@ -1117,6 +1125,13 @@ func indexDefFromDescriptor(
 		}
 		indexDef.Interleave = intlDef
 	}
+	if index.IsPartial() {
+		pred, err := parser.ParseExpr(index.Predicate)
+		if err != nil {
+			return "", err
+		}
+		indexDef.Predicate = pred
+	}
 	return indexDef.String(), nil
 }
 
@@ -2061,6 +2076,8 @@ var datumToTypeCategory = map[reflect.Type]*tree.DString{
 	reflect.TypeOf(types.Int):         typCategoryNumeric,
 	reflect.TypeOf(types.Interval):    typCategoryTimespan,
 	reflect.TypeOf(types.JSON):        typCategoryUserDefined,
+	reflect.TypeOf(types.TSVector):    typCategoryUserDefined,
+	reflect.TypeOf(types.TSQuery):     typCategoryUserDefined,
 	reflect.TypeOf(types.Decimal):     typCategoryNumeric,
 	reflect.TypeOf(types.String):      typCategoryString,
 	reflect.TypeOf(types.Timestamp):   typCategoryDateTime,
@@ -2096,7 +2113,7 @@ CREATE TABLE pg_catalog.pg_views (
 		// because it does not distinguish views in separate databases.
 		return forEachTableDesc(ctx, p, dbContext, hideVirtual, /*virtual schemas do not have views*/
 			func(db *sqlbase.DatabaseDescriptor, scName string, desc *sqlbase.TableDescriptor) error {
-				if !desc.IsView() {
+				if !desc.IsView() || desc.IsMaterializedView {
 					return nil
 				}
 				// Note that the view query printed will not include any column aliases
@@ -64,6 +64,10 @@ type commandResult struct {
 	// If set, an error will be sent to the client if more rows are produced than
 	// this limit.
 	limit int
+	// suspended is set if the execution of the portal was suspended after
+	// producing limit rows. A PortalSuspended message is then sent instead of
+	// CommandComplete.
+	suspended bool
 
 	stmtType     tree.StatementType
 	descOpt      sql.RowDescOpt
@@ -76,8 +80,18 @@ type commandResult struct {
 	// case for queries executed through the simple protocol). Otherwise, it needs
 	// to have an entry for every column.
 	formatCodes []pgwirebase.FormatCode
+
+	// copyOut is set if the rows are to be delivered through the Copy-out
+	// subprotocol (COPY ... TO STDOUT), in which case it contains the options
+	// describing their encoding.
+	copyOut *sql.CopyOptions
+	// copyOutBuf is scratch space used to encode the values of the rows
+	// delivered through the Copy-out subprotocol.
+	copyOutBuf *writeBuffer
 }
 
+var _ sql.CopyOutResult = &commandResult{}
+
 func (c *conn) makeCommandResult(
 	descOpt sql.RowDescOpt,
 	pos sql.CmdPos,
@@ -132,6 +146,13 @@ func (r *commandResult) Close(t sql.TransactionStatusIndicator) {
 	// Send a completion message, specific to the type of result.
 	switch r.typ {
 	case commandComplete:
+		if r.suspended {
+			r.conn.bufferPortalSuspended()
+			break
+		}
+		if r.copyOut != nil {
+			r.conn.bufferCopyDone(*r.copyOut)
+		}
 		tag := cookTag(
 			r.cmdCompleteTag, r.conn.writerState.tagBuf[:0], r.stmtType, r.rowsAffected,
 		)
@@ -210,7 +231,11 @@ func (r *commandResult) AddRow(ctx context.Context, row tree.Datums) error {
 	}
 	r.rowsAffected++
 
-	r.conn.bufferRow(ctx, row, r.formatCodes, r.conv)
+	if r.copyOut != nil {
+		r.conn.bufferCopyData(ctx, row, *r.copyOut, r.conv, r.copyOutBuf)
+	} else {
+		r.conn.bufferRow(ctx, row, r.formatCodes, r.conv)
+	}
 	_ /* flushed */, err := r.conn.maybeFlush(r.pos)
 	return err
 }
@@ -218,11 +243,22 @@ func (r *commandResult) AddRow(ctx context.Context, row tree.Datums) error {
 // SetColumns is part of the CommandResult interface.
 func (r *commandResult) SetColumns(ctx context.Context, cols sqlbase.ResultColumns) {
 	r.conn.writerState.fi.registerCmd(r.pos)
+	if r.copyOut != nil {
+		// The CopyOutResponse message replaces the RowDescription.
+		r.conn.bufferCopyOutResponse(cols, *r.copyOut)
+		return
+	}
 	if r.descOpt == sql.NeedRowDesc {
 		_ /* err */ = r.conn.writeRowDescription(ctx, cols, r.formatCodes, &r.conn.writerState.buf)
 	}
 }
 
+// SetCopyOut is part of the sql.CopyOutResult interface.
+func (r *commandResult) SetCopyOut(opts sql.CopyOptions) {
+	r.copyOut = &opts
+	r.copyOutBuf = newWriteBuffer(nil /* bytecount */)
+}
+
 // SetInTypes is part of the DescribeResult interface.
 func (r *commandResult) SetInTypes(types []oid.Oid) {
 	r.conn.writerState.fi.registerCmd(r.pos)
@@ -264,6 +300,11 @@ func (r *commandResult) SetLimit(n int) {
 	r.limit = n
 }
 
+// SetPortalSuspended is part of the CommandResult interface.
+func (r *commandResult) SetPortalSuspended() {
+	r.suspended = true
+}
+
 // ResetStmtType is part of the CommandResult interface.
 func (r *commandResult) ResetStmtType(stmt tree.Statement) {
 	r.stmtType = stmt.StatementType()
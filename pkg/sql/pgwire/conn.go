@@ -44,6 +44,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
@@ -92,6 +93,27 @@ type conn struct {
 
 	readBuf    pgwirebase.ReadBuffer
 	msgBuilder *writeBuffer
+
+	// writeMu serializes the writes to the network connection done by the
+	// processor goroutine and by the notifications goroutine.
+	writeMu syncutil.Mutex
+
+	// notifications groups the state used to deliver the notifications
+	// received on the channels the session listens on. They are sent right
+	// away by the notifications goroutine while the session is idle, and
+	// before the next ReadyForQuery message otherwise.
+	notifications struct {
+		syncutil.Mutex
+		// pending are the notifications that haven't been sent yet.
+		pending []sql.Notification
+		// idle is set when the last ReadyForQuery message told the client that
+		// the session is outside of a transaction, until the client sends its
+		// next message.
+		idle bool
+	}
+	// notificationsSignal wakes up the notifications goroutine when
+	// notifications are pending.
+	notificationsSignal chan struct{}
 }
 
 // serveConn creates a conn that will serve the netConn. It returns once the
@@ -174,6 +196,8 @@ func newConn(
 		metrics:     metrics,
 		rd:          *bufio.NewReader(netConn),
 		execCfg:     execCfg,
+
+		notificationsSignal: make(chan struct{}, 1),
 	}
 	c.writerState.fi.buf = &c.writerState.buf
 	c.writerState.fi.lastFlushed = -1
@@ -258,6 +282,9 @@ func (c *conn) serveImpl(
 	})
 	c.rd = *bufio.NewReader(c.conn)
 
+	// The session is idle after the initial readyForQuery message.
+	c.setIdle(true)
+
 	var wg sync.WaitGroup
 	var writerErr error
 	processorCtx, stopProcessor := context.WithCancel(ctx)
@@ -271,6 +298,11 @@ func (c *conn) serveImpl(
 			wg.Done()
 			stopReader()
 		}()
+		wg.Add(1)
+		go func() {
+			c.sendNotifications(processorCtx)
+			wg.Done()
+		}()
 	}
 
 	var err error
@@ -289,6 +321,9 @@ Loop:
 		if log.V(2) {
 			log.Infof(ctx, "pgwire: processing %s", typ)
 		}
+		// Notifications are held back until the next readyForQuery message
+		// once the client has sent a message.
+		c.setIdle(false)
 		timeReceived := timeutil.Now()
 		switch typ {
 		case pgwirebase.ClientMsgSimpleQuery:
@@ -526,6 +561,11 @@ func (c *conn) handleParse(ctx context.Context, buf *pgwirebase.ReadBuffer) erro
 		// https://www.postgresql.org/message-id/flat/CAMsr%2BYGvp2wRx9pPSxaKFdaObxX8DzWse%2BOkWk2xpXSvT0rq-g%40mail.gmail.com#CAMsr+YGvp2wRx9pPSxaKFdaObxX8DzWse+OkWk2xpXSvT0rq-g@mail.gmail.com
 		return c.stmtBuf.Push(ctx, sql.SendError{Err: fmt.Errorf("CopyFrom not supported in extended protocol mode")})
 	}
+	if _, ok := stmt.(*tree.CopyTo); ok {
+		// COPY TO doesn't fit the extended protocol either: its results don't
+		// correspond to the row description produced when describing it.
+		return c.stmtBuf.Push(ctx, sql.SendError{Err: fmt.Errorf("CopyTo not supported in extended protocol mode")})
+	}
 
 	return c.stmtBuf.Push(
 		ctx,
@@ -724,13 +764,17 @@ func (c *conn) handleFlush(ctx context.Context) error {
 }
 
 // BeginCopyIn is part of the pgwirebase.Conn interface.
-func (c *conn) BeginCopyIn(ctx context.Context, columns []sqlbase.ResultColumn) error {
+func (c *conn) BeginCopyIn(
+	ctx context.Context, columns []sqlbase.ResultColumn, format pgwirebase.FormatCode,
+) error {
 	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyInResponse)
-	c.msgBuilder.writeByte(byte(pgwirebase.FormatText))
+	c.msgBuilder.writeByte(byte(format))
 	c.msgBuilder.putInt16(int16(len(columns)))
 	for range columns {
-		c.msgBuilder.putInt16(int16(pgwirebase.FormatText))
+		c.msgBuilder.putInt16(int16(format))
 	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
 	return c.msgBuilder.finishMsg(c.conn)
 }
 
@@ -860,7 +904,191 @@ func (c *conn) bufferRow(
 	}
 }
 
+// binaryCopyHeader is the header that starts data in the binary COPY format:
+// the signature followed by the (empty) flags field and the length of the
+// (empty) header extension area.
+var binaryCopyHeader = []byte("PGCOPY\n\377\r\n\000\000\000\000\000\000\000\000\000")
+
+// bufferCopyOutResponse starts the Copy-out subprotocol, in which the rows of
+// a COPY ... TO STDOUT statement are sent as CopyData messages. For the binary
+// format, the header of the data is sent too. For the CSV format, the header
+// line is sent if requested.
+func (c *conn) bufferCopyOutResponse(cols sqlbase.ResultColumns, opts sql.CopyOptions) {
+	format := pgwirebase.FormatText
+	if opts.Format == sql.CopyFormatBinary {
+		format = pgwirebase.FormatBinary
+	}
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyOutResponse)
+	c.msgBuilder.writeByte(byte(format))
+	c.msgBuilder.putInt16(int16(len(cols)))
+	for range cols {
+		c.msgBuilder.putInt16(int16(format))
+	}
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+
+	switch {
+	case opts.Format == sql.CopyFormatBinary:
+		c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+		c.msgBuilder.write(binaryCopyHeader)
+	case opts.Format == sql.CopyFormatCSV && opts.Header:
+		c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+		for i, col := range cols {
+			if i > 0 {
+				c.msgBuilder.writeByte(opts.Delimiter)
+			}
+			writeCopyCSVField(c.msgBuilder, []byte(col.Name), opts, len(cols) == 1)
+		}
+		c.msgBuilder.writeByte('\n')
+	default:
+		return
+	}
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+}
+
+// bufferCopyData serializes a row of a COPY ... TO STDOUT statement into a
+// CopyData message.
+func (c *conn) bufferCopyData(
+	ctx context.Context,
+	row tree.Datums,
+	opts sql.CopyOptions,
+	conv sessiondata.DataConversionConfig,
+	scratch *writeBuffer,
+) {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+	if opts.Format == sql.CopyFormatBinary {
+		c.msgBuilder.putInt16(int16(len(row)))
+		for _, col := range row {
+			c.msgBuilder.writeBinaryDatum(ctx, col, conv.Location)
+		}
+	} else {
+		for i, col := range row {
+			if i > 0 {
+				c.msgBuilder.writeByte(opts.Delimiter)
+			}
+			if col == tree.DNull {
+				c.msgBuilder.writeString(opts.Null)
+				continue
+			}
+			// Reuse the text encoding of result values, stripping the length
+			// prefix.
+			scratch.reset()
+			scratch.writeTextDatum(ctx, col, conv)
+			if scratch.err != nil {
+				c.msgBuilder.setError(scratch.err)
+				break
+			}
+			val := scratch.wrapped.Bytes()[4:]
+			if opts.Format == sql.CopyFormatCSV {
+				writeCopyCSVField(c.msgBuilder, val, opts, len(row) == 1)
+			} else {
+				writeCopyTextField(c.msgBuilder, val, opts.Delimiter)
+			}
+		}
+		c.msgBuilder.writeByte('\n')
+	}
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+}
+
+// bufferCopyDone ends the Copy-out subprotocol. For the binary format, the
+// trailer of the data is sent first.
+func (c *conn) bufferCopyDone(opts sql.CopyOptions) {
+	if opts.Format == sql.CopyFormatBinary {
+		c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+		c.msgBuilder.putInt16(-1)
+		if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+			panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+		}
+	}
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyDone)
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+}
+
+// writeCopyTextField writes a value in the text COPY format, escaping the
+// backslashes, the delimiter and the control characters that have a
+// backslash sequence.
+//
+// See: https://www.postgresql.org/docs/current/static/sql-copy.html#id-1.9.3.52.10.4
+func writeCopyTextField(b *writeBuffer, val []byte, delim byte) {
+	start := 0
+	for i, ch := range val {
+		var esc byte
+		switch ch {
+		case '\b':
+			esc = 'b'
+		case '\f':
+			esc = 'f'
+		case '\n':
+			esc = 'n'
+		case '\r':
+			esc = 'r'
+		case '\t':
+			esc = 't'
+		case '\v':
+			esc = 'v'
+		case '\\', delim:
+			esc = ch
+		default:
+			continue
+		}
+		b.write(val[start:i])
+		b.writeByte('\\')
+		b.writeByte(esc)
+		start = i + 1
+	}
+	b.write(val[start:])
+}
+
+// writeCopyCSVField writes a value in the CSV COPY format. The value is quoted
+// if it could otherwise be mistaken for NULL, for the end-of-data marker (when
+// the row has a single column) or if it contains characters with a special
+// meaning.
+func writeCopyCSVField(b *writeBuffer, val []byte, opts sql.CopyOptions, singleCol bool) {
+	quote := string(val) == opts.Null ||
+		(singleCol && string(val) == `\.`) ||
+		bytes.IndexByte(val, opts.Delimiter) >= 0 ||
+		bytes.ContainsAny(val, "\"\r\n")
+	if !quote {
+		b.write(val)
+		return
+	}
+	b.writeByte('"')
+	start := 0
+	for i, ch := range val {
+		if ch == '"' {
+			b.write(val[start : i+1])
+			b.writeByte('"')
+			start = i + 1
+		}
+	}
+	b.write(val[start:])
+	b.writeByte('"')
+}
+
 func (c *conn) bufferReadyForQuery(txnStatus byte) {
+	// The notifications received during the last command are sent before the
+	// readyForQuery message if the session is not in a transaction anymore.
+	c.notifications.Lock()
+	c.notifications.idle = txnStatus == byte(sql.IdleTxnBlock)
+	var pending []sql.Notification
+	if c.notifications.idle {
+		pending = c.notifications.pending
+		c.notifications.pending = nil
+	}
+	c.notifications.Unlock()
+	for i := range pending {
+		if err := writeNotification(&pending[i], c.msgBuilder, &c.writerState.buf); err != nil {
+			panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+		}
+	}
+
 	c.msgBuilder.initMsg(pgwirebase.ServerMsgReady)
 	c.msgBuilder.writeByte(txnStatus)
 	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
@@ -875,6 +1103,13 @@ func (c *conn) bufferParseComplete() {
 	}
 }
 
+func (c *conn) bufferPortalSuspended() {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgPortalSuspended)
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+	}
+}
+
 func (c *conn) bufferBindComplete() {
 	c.msgBuilder.initMsg(pgwirebase.ServerMsgBindComplete)
 	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
@@ -1043,7 +1278,9 @@ func (c *conn) Flush(pos sql.CmdPos) error {
 	c.writerState.fi.lastFlushed = pos
 	c.writerState.fi.cmdStarts = make(map[sql.CmdPos]int)
 
+	c.writeMu.Lock()
 	_ /* n */, err := c.writerState.buf.WriteTo(c.conn)
+	c.writeMu.Unlock()
 	if err != nil {
 		c.setErr(err)
 		return err
@@ -1051,6 +1288,80 @@ func (c *conn) Flush(pos sql.CmdPos) error {
 	return nil
 }
 
+// SendNotification is part of the sql.ClientComm interface.
+func (c *conn) SendNotification(n sql.Notification) {
+	c.notifications.Lock()
+	c.notifications.pending = append(c.notifications.pending, n)
+	c.notifications.Unlock()
+	select {
+	case c.notificationsSignal <- struct{}{}:
+	default:
+		// The notifications goroutine has already been signaled.
+	}
+}
+
+// setIdle records whether the session is idle, i.e. whether notifications
+// can be sent to the client right away.
+func (c *conn) setIdle(idle bool) {
+	c.notifications.Lock()
+	c.notifications.idle = idle
+	c.notifications.Unlock()
+}
+
+// sendNotifications runs in the notifications goroutine and sends the
+// pending notifications to the client whenever the session is idle, until
+// ctx is canceled or the network connection fails. The notifications
+// received while the session is not idle are sent by bufferReadyForQuery.
+func (c *conn) sendNotifications(ctx context.Context) {
+	msgBuilder := newWriteBuffer(c.metrics.BytesOutCount)
+	var buf bytes.Buffer
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.notificationsSignal:
+		}
+
+		c.notifications.Lock()
+		if !c.notifications.idle {
+			c.notifications.Unlock()
+			continue
+		}
+		pending := c.notifications.pending
+		c.notifications.pending = nil
+		c.notifications.Unlock()
+
+		buf.Reset()
+		for i := range pending {
+			if err := writeNotification(&pending[i], msgBuilder, &buf); err != nil {
+				panic(fmt.Sprintf("unexpected err from buffer: %s", err))
+			}
+		}
+		c.writeMu.Lock()
+		err := c.GetErr()
+		if err == nil {
+			if _ /* n */, err = buf.WriteTo(c.conn); err != nil {
+				c.setErr(err)
+			}
+		}
+		c.writeMu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeNotification writes a NotificationResponse message. The ID of the
+// node on which the notification was sent is reported in place of the
+// process ID of the notifying backend.
+func writeNotification(n *sql.Notification, msgBuilder *writeBuffer, w io.Writer) error {
+	msgBuilder.initMsg(pgwirebase.ServerMsgNotificationResponse)
+	msgBuilder.putInt32(int32(n.NodeID))
+	msgBuilder.writeTerminatedString(n.Channel)
+	msgBuilder.writeTerminatedString(n.Payload)
+	return msgBuilder.finishMsg(w)
+}
+
 // maybeFlush flushes the buffer to the network connection if it exceeded
 // connResultsBufferSizeBytes.
 func (c *conn) maybeFlush(pos sql.CmdPos) (bool, error) {
@@ -17,9 +17,11 @@ package pgwire
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"io"
 	"io/ioutil"
 	"net"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -35,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
@@ -841,3 +844,118 @@ func TestReadTimeoutConnExits(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// TestCopyOutEncoding checks the messages produced for the rows of a
+// COPY ... TO STDOUT statement in the different formats.
+func TestCopyOutEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.TODO()
+	cols := sqlbase.ResultColumns{{Name: "x", Typ: types.Int}, {Name: "y,z", Typ: types.String}}
+	rows := []tree.Datums{
+		{tree.NewDInt(1), tree.NewDString("a\tb\\c\n")},
+		{tree.NewDInt(2), tree.NewDString(`say "hi"`)},
+		{tree.NewDInt(3), tree.NewDString("")},
+		{tree.NewDInt(4), tree.DNull},
+	}
+
+	type msg struct {
+		typ  pgwirebase.ServerMessageType
+		data string
+	}
+	encode := func(opts sql.CopyOptions) []msg {
+		c := &conn{msgBuilder: newWriteBuffer(metric.NewCounter(metric.Metadata{}))}
+		scratch := newWriteBuffer(nil /* bytecount */)
+		c.bufferCopyOutResponse(cols, opts)
+		for _, row := range rows {
+			c.bufferCopyData(ctx, row, opts, sessiondata.DataConversionConfig{}, scratch)
+		}
+		c.bufferCopyDone(opts)
+
+		var msgs []msg
+		b := c.writerState.buf.Bytes()
+		for len(b) > 0 {
+			n := int(binary.BigEndian.Uint32(b[1:5]))
+			msgs = append(msgs, msg{typ: pgwirebase.ServerMessageType(b[0]), data: string(b[5 : n+1])})
+			b = b[n+1:]
+		}
+		return msgs
+	}
+
+	copyOutResponse := func(format byte) msg {
+		return msg{
+			typ:  pgwirebase.ServerMsgCopyOutResponse,
+			data: string([]byte{format, 0, 2, 0, format, 0, format}),
+		}
+	}
+	copyData := func(s string) msg {
+		return msg{typ: pgwirebase.ServerMsgCopyData, data: s}
+	}
+	copyDone := msg{typ: pgwirebase.ServerMsgCopyDone}
+
+	testCases := []struct {
+		name   string
+		opts   sql.CopyOptions
+		expect []msg
+	}{
+		{
+			name: "text",
+			opts: sql.CopyOptions{Format: sql.CopyFormatText, Delimiter: '\t', Null: `\N`},
+			expect: []msg{
+				copyOutResponse(0),
+				copyData("1\ta\\tb\\\\c\\n\n"),
+				copyData("2\tsay \"hi\"\n"),
+				copyData("3\t\n"),
+				copyData("4\t\\N\n"),
+				copyDone,
+			},
+		},
+		{
+			name: "text-delimiter",
+			opts: sql.CopyOptions{Format: sql.CopyFormatText, Delimiter: ' ', Null: "null"},
+			expect: []msg{
+				copyOutResponse(0),
+				copyData("1 a\\tb\\\\c\\n\n"),
+				copyData("2 say\\ \"hi\"\n"),
+				copyData("3 \n"),
+				copyData("4 null\n"),
+				copyDone,
+			},
+		},
+		{
+			name: "csv",
+			opts: sql.CopyOptions{Format: sql.CopyFormatCSV, Delimiter: ',', Header: true},
+			expect: []msg{
+				copyOutResponse(0),
+				copyData("x,\"y,z\"\n"),
+				copyData("1,\"a\tb\\c\n\"\n"),
+				copyData("2,\"say \"\"hi\"\"\"\n"),
+				copyData("3,\"\"\n"),
+				copyData("4,\n"),
+				copyDone,
+			},
+		},
+		{
+			name: "binary",
+			opts: sql.CopyOptions{Format: sql.CopyFormatBinary},
+			expect: []msg{
+				copyOutResponse(1),
+				copyData("PGCOPY\n\377\r\n\000\000\000\000\000\000\000\000\000"),
+				copyData("\000\002\000\000\000\010\000\000\000\000\000\000\000\001\000\000\000\006a\tb\\c\n"),
+				copyData("\000\002\000\000\000\010\000\000\000\000\000\000\000\002\000\000\000\010say \"hi\""),
+				copyData("\000\002\000\000\000\010\000\000\000\000\000\000\000\003\000\000\000\000"),
+				copyData("\000\002\000\000\000\010\000\000\000\000\000\000\000\004\377\377\377\377"),
+				copyData("\377\377"),
+				copyDone,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if msgs := encode(tc.opts); !reflect.DeepEqual(msgs, tc.expect) {
+				t.Fatalf("expected:\n%+v\ngot:\n%+v", tc.expect, msgs)
+			}
+		})
+	}
+}
@@ -91,6 +91,8 @@ const (
 	CodeInvalidRegularExpressionError              = "2201B"
 	CodeInvalidRowCountInLimitClauseError          = "2201W"
 	CodeInvalidRowCountInResultOffsetClauseError   = "2201X"
+	CodeInvalidTablesampleArgumentError            = "2202H"
+	CodeInvalidTablesampleRepeatError              = "2202G"
 	CodeInvalidTimeZoneDisplacementValueError      = "22009"
 	CodeInvalidUseOfEscapeCharacterError           = "2200C"
 	CodeMostSpecificTypeMismatchError              = "2200G"
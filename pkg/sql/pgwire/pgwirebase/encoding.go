@@ -36,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 )
 
@@ -320,6 +321,16 @@ func DecodeOidDatum(id oid.Oid, code FormatCode, b []byte) (tree.Datum, error) {
 				return nil, err
 			}
 			return tree.ParseDJSON(string(b))
+		case oid.T_tsvector:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return tree.ParseDTSVector(string(b))
+		case oid.T_tsquery:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return tree.ParseDTSQuery(string(b))
 		}
 		if _, ok := types.ArrayOids[id]; ok {
 			// Arrays come in in their string form, so we parse them as such and later
@@ -516,6 +527,10 @@ func DecodeOidDatum(id oid.Oid, code FormatCode, b []byte) (tree.Datum, error) {
 				return nil, err
 			}
 			return tree.ParseDJSON(string(b))
+		case oid.T_tsvector:
+			return pgBinaryToTSVector(b)
+		case oid.T_tsquery:
+			return pgBinaryToTSQuery(b)
 		default:
 			if _, ok := types.ArrayOids[id]; ok {
 				return decodeBinaryArray(b, code)
@@ -623,6 +638,136 @@ func pgBinaryToIPAddr(b []byte) (ipaddr.IPAddr, error) {
 	}, nil
 }
 
+var errInvalidTSBinary = errors.New("invalid binary text search value")
+
+// readTerminatedString reads a null-terminated UTF-8 string from r.
+func readTerminatedString(r *bytes.Buffer) (string, error) {
+	s, err := r.ReadBytes(0)
+	if err != nil {
+		return "", errInvalidTSBinary
+	}
+	s = s[:len(s)-1]
+	if err := validateStringBytes(s); err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
+
+// pgBinaryToTSVector interprets b as the Postgres binary format of a text
+// search vector: the number of lexemes, then for each lexeme its
+// null-terminated word, its number of positions and its positions, each of
+// which holds a weight in its top two bits.
+func pgBinaryToTSVector(b []byte) (tree.Datum, error) {
+	r := bytes.NewBuffer(b)
+	var n int32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil || n < 0 {
+		return nil, errInvalidTSBinary
+	}
+	v := make(tsearch.Vector, 0, n)
+	for i := int32(0); i < n; i++ {
+		word, err := readTerminatedString(r)
+		if err != nil {
+			return nil, err
+		}
+		var npos uint16
+		if err := binary.Read(r, binary.BigEndian, &npos); err != nil {
+			return nil, errInvalidTSBinary
+		}
+		l := tsearch.Lexeme{Word: word}
+		for j := uint16(0); j < npos; j++ {
+			var wep uint16
+			if err := binary.Read(r, binary.BigEndian, &wep); err != nil {
+				return nil, errInvalidTSBinary
+			}
+			pos := wep & (1<<PGTSWeightShift - 1)
+			if pos == 0 {
+				return nil, errInvalidTSBinary
+			}
+			l.Positions = append(l.Positions, tsearch.Position{
+				Pos: pos, Weight: tsearch.Weight(wep >> PGTSWeightShift),
+			})
+		}
+		v = append(v, l)
+	}
+	if r.Len() != 0 {
+		return nil, errInvalidTSBinary
+	}
+	// Go through the text representation to sort and merge the lexemes.
+	return tree.ParseDTSVector(v.String())
+}
+
+// pgBinaryToTSQuery interprets b as the Postgres binary format of a text
+// search query: the number of items, then the items, which are operands and
+// operators in prefix order, except that the right operand of a binary
+// operator comes before its left operand.
+func pgBinaryToTSQuery(b []byte) (tree.Datum, error) {
+	r := bytes.NewBuffer(b)
+	var n int32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil || n < 0 {
+		return nil, errInvalidTSBinary
+	}
+	var readItem func() (*tsearch.Node, error)
+	readItem = func() (*tsearch.Node, error) {
+		if n--; n < 0 {
+			return nil, errInvalidTSBinary
+		}
+		typ, err := r.ReadByte()
+		if err != nil {
+			return nil, errInvalidTSBinary
+		}
+		switch typ {
+		case PGTSQueryItemValue:
+			var hdr struct{ Weights, Prefix uint8 }
+			if err := binary.Read(r, binary.BigEndian, &hdr); err != nil {
+				return nil, errInvalidTSBinary
+			}
+			word, err := readTerminatedString(r)
+			if err != nil {
+				return nil, err
+			}
+			return &tsearch.Node{
+				Op: tsearch.OpLexeme, Word: word, Prefix: hdr.Prefix != 0, Weights: hdr.Weights & 0xf,
+			}, nil
+		case PGTSQueryItemOperator:
+			op, err := r.ReadByte()
+			if err != nil {
+				return nil, errInvalidTSBinary
+			}
+			node := &tsearch.Node{}
+			switch op {
+			case PGTSQueryOpNot:
+				node.Op = tsearch.OpNot
+				node.Left, err = readItem()
+				return node, err
+			case PGTSQueryOpAnd:
+				node.Op = tsearch.OpAnd
+			case PGTSQueryOpOr:
+				node.Op = tsearch.OpOr
+			default:
+				return nil, errors.Errorf("unsupported text search query operator: %d", op)
+			}
+			if node.Right, err = readItem(); err != nil {
+				return nil, err
+			}
+			node.Left, err = readItem()
+			return node, err
+		}
+		return nil, errInvalidTSBinary
+	}
+	var q tsearch.Query
+	if n > 0 {
+		root, err := readItem()
+		if err != nil {
+			return nil, err
+		}
+		q.Root = root
+	}
+	if n != 0 || r.Len() != 0 {
+		return nil, errInvalidTSBinary
+	}
+	return tree.NewDTSQuery(q), nil
+}
+
 func decodeBinaryArray(b []byte, code FormatCode) (tree.Datum, error) {
 	hdr := struct {
 		Ndims int32
@@ -681,3 +826,18 @@ const (
 	// AF_NET + 1.
 	PGBinaryIPv6family byte = 3
 )
+
+const (
+	// PGTSWeightShift is the position of the weight in a position of the
+	// binary format of text search vectors.
+	PGTSWeightShift = 14
+	// PGTSQueryItemValue and PGTSQueryItemOperator are the types of the items
+	// of the binary format of text search queries.
+	PGTSQueryItemValue    byte = 1
+	PGTSQueryItemOperator byte = 2
+	// PGTSQueryOpNot, PGTSQueryOpAnd and PGTSQueryOpOr are the operators of
+	// the binary format of text search queries.
+	PGTSQueryOpNot byte = 1
+	PGTSQueryOpAnd byte = 2
+	PGTSQueryOpOr  byte = 3
+)
@@ -36,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 )
 
 // pgType contains type metadata used in RowDescription messages.
@@ -152,6 +153,12 @@ func (b *writeBuffer) writeTextDatum(
 	case *tree.DJSON:
 		b.writeLengthPrefixedString(v.JSON.String())
 
+	case *tree.DTSVector:
+		b.writeLengthPrefixedString(v.Vector.String())
+
+	case *tree.DTSQuery:
+		b.writeLengthPrefixedString(v.Query.String())
+
 	case *tree.DTuple:
 		b.textFormatter.FormatNode(v)
 		b.writeLengthPrefixedVariablePutbuf()
@@ -377,6 +384,22 @@ func (b *writeBuffer) writeBinaryDatum(
 		// Postgres version number, as of writing, `1` is the only valid value.
 		b.writeByte(1)
 		b.writeString(s)
+	case *tree.DTSVector:
+		subWriter := newWriteBuffer(nil /* bytecount */)
+		subWriter.putInt32(int32(len(v.Vector)))
+		for _, l := range v.Vector {
+			subWriter.writeTerminatedString(l.Word)
+			subWriter.putInt16(int16(len(l.Positions)))
+			for _, p := range l.Positions {
+				subWriter.putInt16(int16(uint16(p.Weight)<<pgwirebase.PGTSWeightShift | p.Pos))
+			}
+		}
+		b.writeLengthPrefixedBuffer(&subWriter.wrapped)
+	case *tree.DTSQuery:
+		subWriter := newWriteBuffer(nil /* bytecount */)
+		subWriter.putInt32(int32(tsQueryItemCount(v.Root)))
+		writeTSQueryItems(subWriter, v.Root)
+		b.writeLengthPrefixedBuffer(&subWriter.wrapped)
 	case *tree.DOid:
 		b.putInt32(4)
 		b.putInt32(int32(v.DInt))
@@ -457,3 +480,45 @@ func timeToPgBinary(t time.Time, offset *time.Location) int64 {
 func dateToPgBinary(d *tree.DDate) int32 {
 	return int32(*d) - pgwirebase.PGEpochJDateFromUnix
 }
+
+// tsQueryItemCount returns the number of items of the Postgres binary format
+// of the text search query rooted at n, which has an item per node.
+func tsQueryItemCount(n *tsearch.Node) int {
+	if n == nil {
+		return 0
+	}
+	return 1 + tsQueryItemCount(n.Left) + tsQueryItemCount(n.Right)
+}
+
+// writeTSQueryItems writes the items of the Postgres binary format of the
+// text search query rooted at n. The items are in prefix order, except that
+// the right operand of a binary operator comes before its left operand.
+func writeTSQueryItems(b *writeBuffer, n *tsearch.Node) {
+	if n == nil {
+		return
+	}
+	switch n.Op {
+	case tsearch.OpLexeme:
+		b.writeByte(pgwirebase.PGTSQueryItemValue)
+		b.writeByte(n.Weights)
+		if n.Prefix {
+			b.writeByte(1)
+		} else {
+			b.writeByte(0)
+		}
+		b.writeTerminatedString(n.Word)
+	case tsearch.OpNot:
+		b.writeByte(pgwirebase.PGTSQueryItemOperator)
+		b.writeByte(pgwirebase.PGTSQueryOpNot)
+		writeTSQueryItems(b, n.Left)
+	default:
+		b.writeByte(pgwirebase.PGTSQueryItemOperator)
+		if n.Op == tsearch.OpAnd {
+			b.writeByte(pgwirebase.PGTSQueryOpAnd)
+		} else {
+			b.writeByte(pgwirebase.PGTSQueryOpOr)
+		}
+		writeTSQueryItems(b, n.Right)
+		writeTSQueryItems(b, n.Left)
+	}
+}
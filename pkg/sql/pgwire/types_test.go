@@ -231,6 +231,50 @@ func TestByteArrayRoundTrip(t *testing.T) {
 	}
 }
 
+func TestTextSearchRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var values tree.Datums
+	for _, s := range []string{``, `'a':1 'fat':2,4B 'cat':3A`, `'it''s' 'x'`} {
+		d, err := tree.ParseDTSVector(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, d)
+	}
+	for _, s := range []string{``, `fat & !(rat | cat:*AB)`, `!!a | b & c`} {
+		d, err := tree.ParseDTSQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, d)
+	}
+
+	defaultConv := makeTestingConvCfg()
+	for _, d := range values {
+		for _, code := range []pgwirebase.FormatCode{pgwirebase.FormatText, pgwirebase.FormatBinary} {
+			t.Run(fmt.Sprintf("%s/%s", code, d), func(t *testing.T) {
+				buf := newWriteBuffer(nil /* bytecount */)
+				if code == pgwirebase.FormatText {
+					buf.writeTextDatum(context.Background(), d, defaultConv)
+				} else {
+					buf.writeBinaryDatum(context.Background(), d, defaultConv.Location)
+				}
+				if buf.err != nil {
+					t.Fatal(buf.err)
+				}
+				got, err := pgwirebase.DecodeOidDatum(d.ResolvedType().Oid(), code, buf.wrapped.Bytes()[4:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.String() != d.String() {
+					t.Fatalf("expected %s, got %s", d, got)
+				}
+			})
+		}
+	}
+}
+
 func TestCanWriteAllDatums(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -83,7 +83,16 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 		return nil, nil
 	}
 
-	prefix := sqlbase.MakeNameMetadataKey(dbDesc.ID, "")
+	return getObjectNamesInNamespace(dbDesc, dbDesc.ID, scName, flags)
+}
+
+// getObjectNamesInNamespace returns the names of the objects registered in
+// system.namespace under the given parent ID, qualified with the given
+// database and schema names.
+func getObjectNamesInNamespace(
+	dbDesc *DatabaseDescriptor, parentID sqlbase.ID, scName string, flags DatabaseListFlags,
+) (TableNames, error) {
+	prefix := sqlbase.MakeNameMetadataKey(parentID, "")
 	sr, err := flags.txn.Scan(flags.ctx, prefix, prefix.PrefixEnd(), 0)
 	if err != nil {
 		return nil, err
@@ -96,7 +105,8 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 		if err != nil {
 			return nil, err
 		}
-		tn := tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(tableName))
+		tn := tree.MakeTableNameWithSchema(
+			tree.Name(dbDesc.Name), tree.Name(scName), tree.Name(tableName))
 		tn.ExplicitCatalog = flags.explicitPrefix
 		tn.ExplicitSchema = flags.explicitPrefix
 		tableNames = append(tableNames, tn)
@@ -125,33 +135,38 @@ func (a UncachedPhysicalAccessor) GetObjectDesc(
 	}
 
 	// Look up the table using the discovered database descriptor.
-	desc := &sqlbase.TableDescriptor{}
-	found, err := getDescriptor(flags.ctx, flags.txn,
-		tableKey{parentID: dbDesc.ID, name: name.Table()}, desc)
+	desc, err := getObjectDescInNamespace(flags, tableKey{parentID: dbDesc.ID, name: name.Table()})
 	if err != nil {
 		return nil, nil, err
 	}
-	if !found {
-		desc = nil
-	} else {
-		// We have a descriptor. Is it in the right state?
-		if err := filterTableState(desc); err != nil {
-			// No: let's see the flag.
-			if err == errTableAdding {
-				// We'll keep that despite the ADD state.
-				return desc, dbDesc, nil
-			}
-			// Bad state: the descriptor is essentially invisible.
-			desc = nil
-		}
-	}
-
 	if desc == nil && flags.required {
 		return nil, nil, sqlbase.NewUndefinedRelationError(name)
 	}
 	return desc, dbDesc, nil
 }
 
+// getObjectDescInNamespace looks up the descriptor of the object registered
+// under the given namespace key. A nil descriptor is returned if there is no
+// such object or if it is not in a state that makes it visible.
+func getObjectDescInNamespace(flags ObjectLookupFlags, key tableKey) (*ObjectDescriptor, error) {
+	desc := &sqlbase.TableDescriptor{}
+	found, err := getDescriptor(flags.ctx, flags.txn, key, desc)
+	if err != nil || !found {
+		return nil, err
+	}
+	// We have a descriptor. Is it in the right state?
+	if err := filterTableState(desc); err != nil {
+		// No: let's see the flag.
+		if err == errTableAdding {
+			// We'll keep that despite the ADD state.
+			return desc, nil
+		}
+		// Bad state: the descriptor is essentially invisible.
+		return nil, nil
+	}
+	return desc, nil
+}
+
 // CachedPhysicalAccessor adds a cache on top of any SchemaAccessor.
 type CachedPhysicalAccessor struct {
 	SchemaAccessor
@@ -191,10 +206,33 @@ func (a *CachedPhysicalAccessor) GetDatabaseDesc(
 	return a.SchemaAccessor.GetDatabaseDesc(name, flags)
 }
 
+// IsValidSchema implements the SchemaAccessor interface.
+func (a *CachedPhysicalAccessor) IsValidSchema(dbDesc *DatabaseDescriptor, scName string) bool {
+	if isTemporarySchemaName(scName) {
+		return a.tc.temporarySchemas.isOwnSchema(scName)
+	}
+	return a.SchemaAccessor.IsValidSchema(dbDesc, scName)
+}
+
+// GetObjectNames implements the SchemaAccessor interface.
+func (a *CachedPhysicalAccessor) GetObjectNames(
+	dbDesc *DatabaseDescriptor, scName string, flags DatabaseListFlags,
+) (TableNames, error) {
+	if isTemporarySchemaName(scName) {
+		return a.getTemporaryObjectNames(dbDesc, scName, flags)
+	}
+	return a.SchemaAccessor.GetObjectNames(dbDesc, scName, flags)
+}
+
 // GetObjectDesc implements the SchemaAccessor interface.
 func (a *CachedPhysicalAccessor) GetObjectDesc(
 	name *ObjectName, flags ObjectLookupFlags,
 ) (*ObjectDescriptor, *DatabaseDescriptor, error) {
+	// Temporary tables are never leased: only their session can use them.
+	if isTemporarySchemaName(name.Schema()) {
+		return a.getTemporaryObjectDesc(name, flags)
+	}
+
 	// Can we use the table cache?
 	// - avoidCached -> the caller said no.
 	if !flags.avoidCached {
@@ -22,6 +22,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/execbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -161,7 +162,9 @@ type planNodeFastPath interface {
 var _ planNode = &alterIndexNode{}
 var _ planNode = &alterSequenceNode{}
 var _ planNode = &alterTableNode{}
+var _ planNode = &closeCursorNode{}
 var _ planNode = &createDatabaseNode{}
+var _ planNode = &createFunctionNode{}
 var _ planNode = &createIndexNode{}
 var _ planNode = &createSequenceNode{}
 var _ planNode = &createStatsNode{}
@@ -172,13 +175,16 @@ var _ planNode = &delayedNode{}
 var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
 var _ planNode = &dropDatabaseNode{}
+var _ planNode = &dropFunctionNode{}
 var _ planNode = &dropIndexNode{}
 var _ planNode = &dropSequenceNode{}
 var _ planNode = &dropTableNode{}
 var _ planNode = &DropUserNode{}
 var _ planNode = &dropViewNode{}
+var _ planNode = &explainAnalyzeNode{}
 var _ planNode = &explainDistSQLNode{}
 var _ planNode = &explainPlanNode{}
+var _ planNode = &fetchNode{}
 var _ planNode = &filterNode{}
 var _ planNode = &groupNode{}
 var _ planNode = &hookFnNode{}
@@ -186,8 +192,11 @@ var _ planNode = &indexJoinNode{}
 var _ planNode = &insertNode{}
 var _ planNode = &joinNode{}
 var _ planNode = &limitNode{}
+var _ planNode = &listenNode{}
+var _ planNode = &notifyNode{}
 var _ planNode = &ordinalityNode{}
 var _ planNode = &projectSetNode{}
+var _ planNode = &refreshMaterializedViewNode{}
 var _ planNode = &relocateNode{}
 var _ planNode = &renderNode{}
 var _ planNode = &rowCountNode{}
@@ -212,6 +221,7 @@ var _ planNodeFastPath = &DropUserNode{}
 var _ planNodeFastPath = &alterUserSetPasswordNode{}
 var _ planNodeFastPath = &createTableNode{}
 var _ planNodeFastPath = &deleteNode{}
+var _ planNodeFastPath = &fetchNode{}
 var _ planNodeFastPath = &rowCountNode{}
 var _ planNodeFastPath = &serializeNode{}
 var _ planNodeFastPath = &setZoneConfigNode{}
@@ -364,43 +374,134 @@ func (p *planner) makeOptimizerPlan(ctx context.Context, stmt Statement) error {
 	// Start with fast check to see if top-level statement is supported.
 	switch stmt.AST.(type) {
 	case *tree.ParenSelect, *tree.Select, *tree.SelectClause,
-		*tree.UnionClause, *tree.ValuesClause, *tree.Explain:
+		*tree.UnionClause, *tree.ValuesClause, *tree.Explain,
+		*tree.Insert, *tree.Update, *tree.Delete:
 
 	default:
 		return pgerror.Unimplemented("statement", fmt.Sprintf("unsupported statement: %T", stmt.AST))
 	}
 
+	// Reuse the plan cached by a previous execution of the prepared statement,
+	// if there is one.
+	var cache *memoCache
+	if stmt.Prepared != nil && !p.extendedEvalCtx.PrepareOnly && !p.avoidCachedDescriptors {
+		cache = &stmt.Prepared.memo
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+
+		ev, isCorrelated, ok, err := cache.lookup(ctx, p)
+		if err != nil {
+			return err
+		}
+		if ok {
+			log.VEvent(ctx, 1, "reusing cached optimizer plan")
+			return p.runExecBuilder(ctx, stmt, ev, isCorrelated)
+		}
+		if len(stmt.Prepared.Types) != 0 {
+			if ok, err := p.makeCustomOptimizerPlan(ctx, stmt, cache); ok || err != nil {
+				return err
+			}
+		}
+	}
+
 	var catalog optCatalog
 	catalog.init(p.execCfg.TableStatsCache, p)
+	defer p.resolveFunctionsThrough(&catalog)()
 
-	o := xform.NewOptimizer(p.EvalContext())
-	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &catalog, o.Factory(), stmt.AST)
-	root, props, err := bld.Build()
+	baselineJoinHints := p.execCfg.PlanBaselines.lookup(p.SessionData().Database, stmt.AST)
+	for {
+		o := xform.NewOptimizer(p.EvalContext())
+		bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &catalog, o.Factory(), stmt.AST)
+		bld.BaselineJoinHints = baselineJoinHints
+		root, props, err := bld.Build()
 
-	// Remember whether the plan was correlated before processing the
-	// error. This way, the executor will abort early with a useful error message instead of trying
-	// the heuristic planner.
-	p.curPlan.isCorrelated = bld.IsCorrelated
+		// Remember whether the plan was correlated before processing the
+		// error. This way, the executor will abort early with a useful error message instead of trying
+		// the heuristic planner.
+		p.curPlan.isCorrelated = bld.IsCorrelated
 
-	if err != nil {
-		return err
-	}
+		if err != nil {
+			return err
+		}
+
+		// If in the PREPARE phase, construct a dummy plan that has correct output
+		// columns. Only output columns and placeholder types are needed.
+		if p.extendedEvalCtx.PrepareOnly {
+			md := o.Memo().Metadata()
+			resultCols := make(sqlbase.ResultColumns, len(props.Presentation))
+			for i, col := range props.Presentation {
+				resultCols[i].Name = col.Label
+				resultCols[i].Typ = md.ColumnType(col.ID)
+			}
+			p.curPlan.plan = &zeroNode{columns: resultCols}
+			return nil
+		}
 
-	// If in the PREPARE phase, construct a dummy plan that has correct output
-	// columns. Only output columns and placeholder types are needed.
-	if p.extendedEvalCtx.PrepareOnly {
-		md := o.Memo().Metadata()
-		resultCols := make(sqlbase.ResultColumns, len(props.Presentation))
-		for i, col := range props.Presentation {
-			resultCols[i].Name = col.Label
-			resultCols[i].Typ = md.ColumnType(col.ID)
+		ev := o.Optimize(root, props)
+		if err := o.CheckJoinHints(); err != nil {
+			if baselineJoinHints == nil {
+				return err
+			}
+			// The plan baseline can no longer be followed (e.g. because an index
+			// was dropped); plan the statement without it.
+			log.VEventf(ctx, 1, "ignoring plan baseline: %v", err)
+			baselineJoinHints = nil
+			continue
+		}
+		if err := p.runExecBuilder(ctx, stmt, ev, bld.IsCorrelated); err != nil {
+			return err
+		}
+		if cache != nil && len(stmt.Prepared.Types) == 0 {
+			cache.add(p, o.Memo(), &catalog, bld.IsCorrelated)
 		}
-		p.curPlan.plan = &zeroNode{columns: resultCols}
 		return nil
 	}
+}
+
+// makeCustomOptimizerPlan plans an execution of a prepared statement with
+// placeholders by assigning the placeholder values in a copy of the cached
+// normalized memo, and re-running exploration on it. It returns false if the
+// statement must be planned from scratch instead (e.g. because the plan
+// baseline can no longer be followed). The cache must be locked.
+func (p *planner) makeCustomOptimizerPlan(
+	ctx context.Context, stmt Statement, cache *memoCache,
+) (bool, error) {
+	if !cache.buildNormalized(ctx, p, stmt) {
+		return false, nil
+	}
+	p.curPlan.isCorrelated = cache.isCorrelated
+
+	o := xform.NewOptimizerWithMemo(p.EvalContext(), cache.normMem.Copy())
+	root, err := o.Factory().AssignPlaceholders(cache.normRoot)
+	if err != nil {
+		return false, err
+	}
+	ev := o.Optimize(root, cache.normProps)
+	if err := o.CheckJoinHints(); err != nil {
+		log.VEventf(ctx, 1, "ignoring plan baseline: %v", err)
+		return false, nil
+	}
+	if err := p.runExecBuilder(ctx, stmt, ev, cache.isCorrelated); err != nil {
+		return false, err
+	}
+	cache.addCustom(ctx, p, ev.Cost())
+	return true, nil
+}
 
-	ev := o.Optimize(root, props)
+// resolveFunctionsThrough makes the planner resolve function names through the
+// given optimizer catalog, which records the functions a memo depends on. The
+// returned function restores the previous resolver.
+func (p *planner) resolveFunctionsThrough(catalog *optCatalog) func() {
+	prev := p.semaCtx.FunctionResolver
+	p.semaCtx.FunctionResolver = catalog
+	return func() { p.semaCtx.FunctionResolver = prev }
+}
 
+// runExecBuilder builds the planNode tree for the given optimized expression
+// and installs it as the planner's current plan.
+func (p *planner) runExecBuilder(
+	ctx context.Context, stmt Statement, ev memo.ExprView, isCorrelated bool,
+) error {
 	factory := makeExecFactory(p)
 	plan, err := execbuilder.New(&factory, ev).Build()
 	if err != nil {
@@ -411,7 +512,13 @@ func (p *planner) makeOptimizerPlan(ctx context.Context, stmt Statement) error {
 	// Since the assignment above just cleared the AST and isCorrelated
 	// field, we need to set them again.
 	p.curPlan.AST = stmt.AST
-	p.curPlan.isCorrelated = bld.IsCorrelated
+	p.curPlan.isCorrelated = isCorrelated
+
+	if p.autoCommit {
+		if ac, ok := p.curPlan.plan.(autoCommitNode); ok {
+			ac.enableAutoCommit()
+		}
+	}
 
 	cols := planColumns(p.curPlan.plan)
 	if stmt.ExpectedTypes != nil {
@@ -552,7 +659,7 @@ func startExec(params runParams, plan planNode) error {
 	o := planObserver{
 		enterNode: func(ctx context.Context, _ string, p planNode) (bool, error) {
 			switch p.(type) {
-			case *explainPlanNode, *explainDistSQLNode:
+			case *explainPlanNode, *explainDistSQLNode, *explainAnalyzeNode:
 				// Do not recurse: we're not starting the plan if we just show its structure with EXPLAIN.
 				return false, nil
 			case *showTraceNode:
@@ -697,6 +804,8 @@ func (p *planner) newPlan(
 		return p.CancelQueries(ctx, n)
 	case *tree.CancelSessions:
 		return p.CancelSessions(ctx, n)
+	case *tree.CloseCursor:
+		return p.CloseCursor(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
 	case *tree.Scrub:
@@ -713,6 +822,8 @@ func (p *planner) newPlan(
 		return p.CreateView(ctx, n)
 	case *tree.CreateSequence:
 		return p.CreateSequence(ctx, n)
+	case *tree.CreateFunction:
+		return p.CreateFunction(ctx, n)
 	case *tree.CreateStats:
 		return p.CreateStatistics(ctx, n)
 	case *tree.Deallocate:
@@ -731,22 +842,34 @@ func (p *planner) newPlan(
 		return p.DropView(ctx, n)
 	case *tree.DropSequence:
 		return p.DropSequence(ctx, n)
+	case *tree.DropFunction:
+		return p.DropFunction(ctx, n)
 	case *tree.DropUser:
 		return p.DropUser(ctx, n)
 	case *tree.Execute:
 		return p.Execute(ctx, n)
 	case *tree.Explain:
 		return p.Explain(ctx, n)
+	case *tree.FetchCursor:
+		return p.FetchCursor(ctx, n)
 	case *tree.Grant:
 		return p.Grant(ctx, n)
 	case *tree.Insert:
 		return p.Insert(ctx, n, desiredTypes)
+	case *tree.Listen:
+		return p.Listen(ctx, n)
+	case *tree.MoveCursor:
+		return p.MoveCursor(ctx, n)
+	case *tree.Notify:
+		return p.Notify(ctx, n)
 	case *tree.ParenSelect:
 		return p.newPlan(ctx, n.Select, desiredTypes)
 	case *tree.Relocate:
 		return p.Relocate(ctx, n)
 	case *tree.RenameColumn:
 		return p.RenameColumn(ctx, n)
+	case *tree.RefreshMaterializedView:
+		return p.RefreshMaterializedView(ctx, n)
 	case *tree.RenameDatabase:
 		return p.RenameDatabase(ctx, n)
 	case *tree.RenameIndex:
@@ -827,6 +950,8 @@ func (p *planner) newPlan(
 			return nil, err
 		}
 		return p.Truncate(ctx, n)
+	case *tree.Unlisten:
+		return p.Unlisten(ctx, n)
 	case *tree.UnionClause:
 		return p.Union(ctx, n, desiredTypes)
 	case *tree.Update:
@@ -884,6 +1009,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.DropUser(ctx, n)
 	case *tree.Explain:
 		return p.Explain(ctx, n)
+	case *tree.FetchCursor:
+		return p.FetchCursor(ctx, n)
 	case *tree.Insert:
 		return p.Insert(ctx, n, nil)
 	case *tree.Select:
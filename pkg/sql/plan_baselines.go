@@ -0,0 +1,328 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// PlanBaselines stores the plan baselines of the cluster. A plan baseline
+// pins the join algorithms used by the optimizer for all the statements with
+// a given fingerprint (the statement with its constants and hints hidden) that
+// run in a given database. It is created from a statement with join hints
+// (e.g. INNER HASH JOIN), and lets the plan of a statement be fixed without
+// changing the application that issues it.
+//
+// The baselines are stored in system.plan_baselines. Each node keeps a copy of
+// them, which it loads when it starts. When a baseline is added or removed,
+// the node that made the change gossips a notification, upon which every node
+// reloads its copy.
+type PlanBaselines struct {
+	gossip *gossip.Gossip
+	ie     *InternalExecutor
+
+	// loadMu serializes the loads of the baselines, so that the copy is never
+	// replaced by the result of an earlier load.
+	loadMu syncutil.Mutex
+
+	mu struct {
+		syncutil.RWMutex
+
+		// baselines is the copy of system.plan_baselines.
+		baselines map[planBaselineKey]*planBaseline
+
+		// generation is incremented every time the baselines are reloaded.
+		// Cached memos record it, so that they are rebuilt when it changes.
+		generation int64
+	}
+}
+
+// planBaselineKey identifies the statements to which a plan baseline
+// applies.
+type planBaselineKey struct {
+	// database is the current database of the session that created the
+	// baseline. Statements with the same fingerprint can refer to different
+	// tables in different databases.
+	database    string
+	fingerprint string
+}
+
+// planBaseline is the plan baseline for a statement fingerprint.
+type planBaseline struct {
+	key planBaselineKey
+
+	// stmt is the statement from which the baseline was created.
+	stmt string
+
+	// joinHints are the join hints of stmt, in the order reported by
+	// optbuilder.Builder.JoinHints.
+	joinHints []memo.JoinHint
+}
+
+// NewPlanBaselines creates a PlanBaselines, which loads the baselines using
+// the given executor. If g is nil, changes to the baselines are not
+// propagated to the other nodes.
+func NewPlanBaselines(g *gossip.Gossip, ie *InternalExecutor) *PlanBaselines {
+	pb := &PlanBaselines{gossip: g, ie: ie}
+	pb.mu.baselines = make(map[planBaselineKey]*planBaseline)
+	return pb
+}
+
+// Start loads the baselines, and makes the node reload them whenever they are
+// changed on any node. It must be called once system.plan_baselines exists.
+func (pb *PlanBaselines) Start(ctx context.Context, stopper *stop.Stopper) error {
+	if err := pb.load(ctx); err != nil {
+		return err
+	}
+	pb.gossip.RegisterCallback(
+		regexp.QuoteMeta(gossip.KeySQLPlanBaselinesChanged),
+		func(_ string, _ roachpb.Value) {
+			// The callback must not block gossip, so the baselines are loaded
+			// asynchronously.
+			_ = stopper.RunAsyncTask(ctx, "reload-plan-baselines", func(ctx context.Context) {
+				if err := pb.load(ctx); err != nil {
+					log.Warningf(ctx, "failed to reload plan baselines: %v", err)
+				}
+			})
+		},
+	)
+	return nil
+}
+
+// planBaselineFingerprint returns the fingerprint under which the plan
+// baseline of the given statement is stored.
+func planBaselineFingerprint(stmt tree.Statement) string {
+	return tree.AsStringWithFlags(stmt, tree.FmtHideConstants|tree.FmtHideHints)
+}
+
+// lookup returns the join hints of the plan baseline for the given statement
+// run in the given database, or nil if there is none.
+func (pb *PlanBaselines) lookup(database string, stmt tree.Statement) []memo.JoinHint {
+	if pb == nil {
+		return nil
+	}
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+	if len(pb.mu.baselines) == 0 {
+		// Avoid computing the fingerprint in the common case.
+		return nil
+	}
+	key := planBaselineKey{database: database, fingerprint: planBaselineFingerprint(stmt)}
+	if b, ok := pb.mu.baselines[key]; ok {
+		return b.joinHints
+	}
+	return nil
+}
+
+// generation returns a number that changes whenever the baselines are
+// reloaded.
+func (pb *PlanBaselines) generation() int64 {
+	if pb == nil {
+		return 0
+	}
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+	return pb.mu.generation
+}
+
+// list returns the baselines, sorted by database and fingerprint.
+func (pb *PlanBaselines) list() []planBaseline {
+	if pb == nil {
+		return nil
+	}
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+	res := make([]planBaseline, 0, len(pb.mu.baselines))
+	for _, b := range pb.mu.baselines {
+		res = append(res, *b)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].key.database != res[j].key.database {
+			return res[i].key.database < res[j].key.database
+		}
+		return res[i].key.fingerprint < res[j].key.fingerprint
+	})
+	return res
+}
+
+// load reads the baselines from system.plan_baselines, and replaces the local
+// copy with them.
+func (pb *PlanBaselines) load(ctx context.Context) error {
+	pb.loadMu.Lock()
+	defer pb.loadMu.Unlock()
+	rows, _, err := pb.ie.Query(
+		ctx, "load-plan-baselines", nil, /* txn */
+		`SELECT database, fingerprint, statement, "joinHints" FROM system.plan_baselines`,
+	)
+	if err != nil {
+		return err
+	}
+	baselines := make([]*planBaseline, len(rows))
+	for i, row := range rows {
+		baselines[i] = planBaselineFromRow(row)
+	}
+	pb.set(baselines)
+	return nil
+}
+
+// set replaces the local copy of the baselines.
+func (pb *PlanBaselines) set(baselines []*planBaseline) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.mu.baselines = make(map[planBaselineKey]*planBaseline, len(baselines))
+	for _, b := range baselines {
+		pb.mu.baselines[b.key] = b
+	}
+	pb.mu.generation++
+}
+
+// changed is called after a baseline was added to or removed from
+// system.plan_baselines. It reloads the local copy, so that the change
+// applies to the following statements of the session that made it, and
+// notifies the other nodes.
+func (pb *PlanBaselines) changed(ctx context.Context) error {
+	if err := pb.load(ctx); err != nil {
+		return err
+	}
+	if pb.gossip == nil {
+		return nil
+	}
+	return pb.gossip.AddInfo(gossip.KeySQLPlanBaselinesChanged, nil /* value */, 0 /* ttl */)
+}
+
+// planBaselineFromRow decodes a row of system.plan_baselines.
+func planBaselineFromRow(row tree.Datums) *planBaseline {
+	hints := tree.MustBeDArray(row[3])
+	b := &planBaseline{
+		key: planBaselineKey{
+			database:    string(tree.MustBeDString(row[0])),
+			fingerprint: string(tree.MustBeDString(row[1])),
+		},
+		stmt: string(tree.MustBeDString(row[2])),
+	}
+	if hints.Len() > 0 {
+		b.joinHints = make([]memo.JoinHint, hints.Len())
+		for i, d := range hints.Array {
+			b.joinHints[i] = memo.JoinHint(tree.MustBeDInt(d))
+		}
+	}
+	return b
+}
+
+// joinHintsArray encodes join hints for the "joinHints" column of
+// system.plan_baselines.
+func joinHintsArray(joinHints []memo.JoinHint) (*tree.DArray, error) {
+	arr := tree.NewDArray(types.Int)
+	for _, h := range joinHints {
+		if err := arr.Append(tree.NewDInt(tree.DInt(h))); err != nil {
+			return nil, err
+		}
+	}
+	return arr, nil
+}
+
+var errPlanBaselinesUnavailable = pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+	"plan baselines are not available in this context")
+
+// CreatePlanBaseline implements the tree.PlanBaselineManager interface.
+func (p *planner) CreatePlanBaseline(ctx context.Context, sql string) (string, error) {
+	pb := p.execCfg.PlanBaselines
+	if pb == nil {
+		return "", errPlanBaselinesUnavailable
+	}
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		return "", err
+	}
+
+	// Build and optimize the statement, to collect its join hints and check
+	// that they can be satisfied. The statement is built with its own
+	// placeholders, so that it can't refer to those of the current statement.
+	semaCtx := p.semaCtx
+	semaCtx.Placeholders = tree.MakePlaceholderInfo()
+	var catalog optCatalog
+	catalog.init(p.execCfg.TableStatsCache, p)
+	o := xform.NewOptimizer(p.EvalContext())
+	bld := optbuilder.New(ctx, &semaCtx, p.EvalContext(), &catalog, o.Factory(), stmt)
+	root, props, err := bld.Build()
+	if err != nil {
+		return "", err
+	}
+	joinHints := bld.JoinHints()
+	hasHint := false
+	for _, h := range joinHints {
+		hasHint = hasHint || h != memo.NoJoinHint
+	}
+	if !hasHint {
+		return "", pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"a plan baseline must be created from a statement with join hints")
+	}
+	o.Optimize(root, props)
+	if err := o.CheckJoinHints(); err != nil {
+		return "", err
+	}
+
+	// The baseline is written in its own transaction, so that it applies
+	// as soon as this function returns.
+	fingerprint := planBaselineFingerprint(stmt)
+	hintsArray, err := joinHintsArray(joinHints)
+	if err != nil {
+		return "", err
+	}
+	if _, err := pb.ie.Exec(
+		ctx, "create-plan-baseline", nil, /* txn */
+		`UPSERT INTO system.plan_baselines (database, fingerprint, statement, "joinHints")
+		VALUES ($1, $2, $3, $4)`,
+		p.SessionData().Database, fingerprint, tree.AsString(stmt), hintsArray,
+	); err != nil {
+		return "", err
+	}
+	if err := pb.changed(ctx); err != nil {
+		return "", err
+	}
+	return fingerprint, nil
+}
+
+// DropPlanBaseline implements the tree.PlanBaselineManager interface.
+func (p *planner) DropPlanBaseline(ctx context.Context, fingerprint string) (bool, error) {
+	pb := p.execCfg.PlanBaselines
+	if pb == nil {
+		return false, errPlanBaselinesUnavailable
+	}
+	n, err := pb.ie.Exec(
+		ctx, "drop-plan-baseline", nil, /* txn */
+		`DELETE FROM system.plan_baselines WHERE database = $1 AND fingerprint = $2`,
+		p.SessionData().Database, fingerprint,
+	)
+	if err != nil || n == 0 {
+		return false, err
+	}
+	return true, pb.changed(ctx)
+}
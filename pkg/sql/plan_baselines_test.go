@@ -0,0 +1,158 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPlanBaselineFromRow(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, b := range []planBaseline{
+		{
+			key:       planBaselineKey{database: "db", fingerprint: "SELECT * FROM a JOIN b ON k = x"},
+			stmt:      "SELECT * FROM a INNER HASH JOIN b ON k = x",
+			joinHints: []memo.JoinHint{memo.HashJoinHint},
+		},
+		{
+			key:       planBaselineKey{database: "db", fingerprint: "SELECT * FROM a JOIN b ON k = x JOIN c ON y = z"},
+			stmt:      "SELECT * FROM a INNER MERGE JOIN b ON k = x JOIN c ON y = z",
+			joinHints: []memo.JoinHint{memo.MergeJoinHint, memo.NoJoinHint},
+		},
+	} {
+		hints, err := joinHintsArray(b.joinHints)
+		if err != nil {
+			t.Fatal(err)
+		}
+		row := tree.Datums{
+			tree.NewDString(b.key.database),
+			tree.NewDString(b.key.fingerprint),
+			tree.NewDString(b.stmt),
+			hints,
+		}
+		if decoded := planBaselineFromRow(row); !reflect.DeepEqual(&b, decoded) {
+			t.Fatalf("expected %v, got %v", b, *decoded)
+		}
+	}
+}
+
+func TestPlanBaselinesLookup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	parse := func(sql string) *planBaseline {
+		stmt, err := parser.ParseOne(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := planBaselineKey{database: "db", fingerprint: planBaselineFingerprint(stmt)}
+		return &planBaseline{key: key, stmt: sql}
+	}
+
+	pb := NewPlanBaselines(nil /* gossip */, nil /* ie */)
+	b := parse("SELECT * FROM a INNER LOOKUP JOIN b ON k = x WHERE y = 1")
+	b.joinHints = []memo.JoinHint{memo.LookupJoinHint}
+	gen := pb.generation()
+	pb.set([]*planBaseline{b})
+	if pb.generation() == gen {
+		t.Fatal("expected generation to change")
+	}
+
+	// The baseline applies to statements that only differ in their constants
+	// and hints, and that run in the same database.
+	for _, tc := range []struct {
+		database string
+		sql      string
+		expected []memo.JoinHint
+	}{
+		{"db", "SELECT * FROM a JOIN b ON k = x WHERE y = 2", b.joinHints},
+		{"db", "SELECT * FROM a INNER HASH JOIN b ON k = x WHERE y = 3", b.joinHints},
+		{"db", "SELECT * FROM a LEFT JOIN b ON k = x WHERE y = 1", nil},
+		{"other", "SELECT * FROM a JOIN b ON k = x WHERE y = 2", nil},
+	} {
+		stmt, err := parser.ParseOne(tc.sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hints := pb.lookup(tc.database, stmt); !reflect.DeepEqual(hints, tc.expected) {
+			t.Errorf("%s: %s: expected %v, got %v", tc.database, tc.sql, tc.expected, hints)
+		}
+	}
+
+	if l := pb.list(); len(l) != 1 || l[0].stmt != b.stmt {
+		t.Fatalf("unexpected baselines %v", l)
+	}
+}
+
+func TestPlanBaselinesPersisted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params := base.TestServerArgs{UseDatabase: "t"}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+	ctx := context.Background()
+
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `
+CREATE DATABASE t;
+CREATE TABLE t.a (k INT PRIMARY KEY, v INT);
+CREATE TABLE t.b (x INT PRIMARY KEY, y INT);
+`)
+	var fingerprint string
+	r.QueryRow(t, `SELECT crdb_internal.create_plan_baseline(`+
+		`'SELECT * FROM a INNER HASH JOIN b ON k = x WHERE v = 1')`).Scan(&fingerprint)
+	r.CheckQueryResults(t, `SELECT database, fingerprint FROM system.plan_baselines`,
+		[][]string{{"t", fingerprint}})
+
+	// A node that starts after the baseline was created loads it from the
+	// system table.
+	pb := NewPlanBaselines(nil /* gossip */, s.InternalExecutor().(*InternalExecutor))
+	if err := pb.load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := parser.ParseOne(`SELECT * FROM a JOIN b ON k = x WHERE v = 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hints := pb.lookup("t", stmt); !reflect.DeepEqual(hints, []memo.JoinHint{memo.HashJoinHint}) {
+		t.Fatalf("unexpected join hints %v", hints)
+	}
+
+	// Dropping the baseline removes it from the system table.
+	for _, expected := range []bool{true, false} {
+		var dropped bool
+		r.QueryRow(t, `SELECT crdb_internal.drop_plan_baseline($1)`, fingerprint).Scan(&dropped)
+		if dropped != expected {
+			t.Fatalf("expected drop_plan_baseline to return %t", expected)
+		}
+	}
+	r.CheckQueryResults(t, `SELECT count(*) FROM system.plan_baselines`, [][]string{{"0"}})
+	if err := pb.load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if l := pb.list(); len(l) != 0 {
+		t.Fatalf("unexpected baselines %v", l)
+	}
+}
@@ -91,12 +91,21 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.columns
 	case *lookupJoinNode:
 		return n.columns
+	case *zigzagJoinNode:
+		return n.columns
+	case *fetchNode:
+		if n.move {
+			return nil
+		}
+		return n.cursor.columns()
 
 	// Nodes with a fixed schema.
 	case *scrubNode:
 		return n.getColumns(mut, scrubColumns)
 	case *explainDistSQLNode:
 		return n.getColumns(mut, sqlbase.ExplainDistSQLColumns)
+	case *explainAnalyzeNode:
+		return n.getColumns(mut, sqlbase.ExplainAnalyzeColumns)
 	case *relocateNode:
 		return n.getColumns(mut, relocateNodeColumns)
 	case *scatterNode:
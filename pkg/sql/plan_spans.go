@@ -69,6 +69,8 @@ func collectSpans(params runParams, plan planNode) (reads, writes roachpb.Spans,
 		return collectSpans(params, n.plan)
 	case *explainDistSQLNode:
 		return collectSpans(params, n.plan)
+	case *explainAnalyzeNode:
+		return collectSpans(params, n.plan)
 	case *explainPlanNode:
 		return collectSpans(params, n.plan)
 	case *limitNode:
@@ -175,6 +177,10 @@ func insertNodeWithValuesSpans(
 		}
 		addWriteKey(primaryKey)
 		for _, secondaryKey := range secondaryKeys {
+			if secondaryKey.Key == nil {
+				// The row is not in this partial index.
+				continue
+			}
 			addWriteKey(secondaryKey.Key)
 		}
 
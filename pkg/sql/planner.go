@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -68,6 +69,14 @@ type extendedEvalContext struct {
 
 	SchemaChangers *schemaChangerCollection
 
+	// Notifications accumulates the notifications sent by the current
+	// transaction. It is nil outside of sessions.
+	Notifications *txnNotifications
+
+	// Cursors holds the cursors of the current transaction. It is nil
+	// outside of sessions.
+	Cursors *cursorMap
+
 	schemaAccessors *schemaInterface
 }
 
@@ -194,10 +203,11 @@ func newInternalPlanner(
 	ctx := log.WithLogTagStr(context.Background(), opName, "")
 
 	sd := &sessiondata.SessionData{
-		SearchPath:    sqlbase.DefaultSearchPath,
-		User:          user,
-		Database:      "system",
-		SequenceState: sessiondata.NewSequenceState(),
+		ReorderJoinsLimit: xform.DefaultReorderJoinsLimit,
+		SearchPath:        sqlbase.DefaultSearchPath,
+		User:              user,
+		Database:          "system",
+		SequenceState:     sessiondata.NewSequenceState(),
 		DataConversion: sessiondata.DataConversionConfig{
 			Location: time.UTC,
 		},
@@ -235,6 +245,7 @@ func newInternalPlanner(
 	p.semaCtx = tree.MakeSemaContext(sd.User == security.RootUser /* privileged */)
 	p.semaCtx.Location = &sd.DataConversion.Location
 	p.semaCtx.SearchPath = sd.SearchPath
+	p.semaCtx.FunctionResolver = p
 
 	plannerMon := mon.MakeUnlimitedMonitor(ctx,
 		"internal-planner",
@@ -247,6 +258,8 @@ func newInternalPlanner(
 	)
 	p.extendedEvalCtx.Planner = p
 	p.extendedEvalCtx.Sequence = p
+	p.extendedEvalCtx.Notifier = p
+	p.extendedEvalCtx.PlanBaselines = p
 	p.extendedEvalCtx.ClusterID = execCfg.ClusterID()
 	p.extendedEvalCtx.NodeID = execCfg.NodeID.Get()
 
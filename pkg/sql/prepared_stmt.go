@@ -53,6 +53,9 @@ type PreparedStatement struct {
 	// identifiers. Used for reporting on Describe.
 	InTypes []oid.Oid
 
+	// memo caches the optimizer's plan across executions of the statement.
+	memo memoCache
+
 	memAcc mon.BoundAccount
 }
 
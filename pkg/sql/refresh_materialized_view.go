@@ -0,0 +1,87 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type refreshMaterializedViewNode struct {
+	n    *tree.RefreshMaterializedView
+	desc *sqlbase.TableDescriptor
+}
+
+// RefreshMaterializedView recomputes the contents of a materialized view.
+// Privileges: CREATE on view, SELECT on the relations the view depends on.
+//   notes: postgres requires ownership of the view.
+func (p *planner) RefreshMaterializedView(
+	ctx context.Context, n *tree.RefreshMaterializedView,
+) (planNode, error) {
+	tn, err := n.Name.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var desc *TableDescriptor
+	// DDL statements avoid the cache to avoid leases, and can view non-public descriptors.
+	p.runWithOptions(resolveFlags{skipCache: true}, func() {
+		desc, err = ResolveExistingObject(ctx, p, tn, true /*required*/, requireMaterializedViewDesc)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, desc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	// The view's query is run with the privileges of the current user (see
+	// startExec), which is checked again when the query is planned. Checking
+	// here reports a missing privilege before the schema change is queued.
+	for _, id := range desc.DependsOn {
+		dependsOn, err := sqlbase.GetTableDescFromID(ctx, p.txn, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.CheckPrivilege(ctx, dependsOn, privilege.SELECT); err != nil {
+			return nil, err
+		}
+	}
+
+	return &refreshMaterializedViewNode{n: n, desc: desc}, nil
+}
+
+func (n *refreshMaterializedViewNode) startExec(params runParams) error {
+	// The view query is evaluated by the schema changer, which backfills its
+	// result into a new primary index and then swaps it in place of the
+	// current one. Readers see either the old or the new contents, never a
+	// mix of the two.
+	n.desc.AddMaterializedViewRefreshMutation(params.SessionData().User)
+
+	mutationID, err := params.p.createSchemaChangeJob(params.ctx, n.desc,
+		tree.AsStringWithFlags(n.n, tree.FmtAlwaysQualifyTableNames))
+	if err != nil {
+		return err
+	}
+	return params.p.writeSchemaChange(params.ctx, n.desc, mutationID)
+}
+
+func (*refreshMaterializedViewNode) Next(runParams) (bool, error) { return false, nil }
+func (*refreshMaterializedViewNode) Values() tree.Datums          { return tree.Datums{} }
+func (*refreshMaterializedViewNode) Close(context.Context)        {}
@@ -136,6 +136,20 @@ func (p *planner) RenameColumn(ctx context.Context, n *tree.RenameColumn) (planN
 		}
 	}
 
+	// Rename the column in the predicates of partial indexes.
+	for _, idxDesc := range tableDesc.AllNonDropIndexes() {
+		if !idxDesc.IsPartial() {
+			continue
+		}
+		idx, err := tableDesc.FindIndexByID(idxDesc.ID)
+		if err != nil {
+			return nil, err
+		}
+		if idx.Predicate, err = renameIn(idx.Predicate); err != nil {
+			return nil, err
+		}
+	}
+
 	// Rename the column in the indexes.
 	tableDesc.RenameColumnDescriptor(col, string(n.NewName))
 
@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -79,23 +80,23 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 			ctx, tableDesc.TypeName(), oldTn.String(), tableDesc.ParentID, tableDesc.DependedOnBy[0].ID)
 	}
 
-	var prevDbDesc *DatabaseDescriptor
-	p.runWithOptions(resolveFlags{skipCache: true}, func() {
-		prevDbDesc, err = ResolveDatabase(ctx, p, oldTn.Catalog(), true /*required*/)
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	// Check if target database exists.
 	// We also look at uncached descriptors here.
 	var targetDbDesc *DatabaseDescriptor
 	p.runWithOptions(resolveFlags{skipCache: true}, func() {
-		targetDbDesc, err = ResolveTargetObject(ctx, p, newTn)
+		if tableDesc.IsTemporary() {
+			targetDbDesc, err = p.resolveTemporaryTableTarget(ctx, newTn)
+		} else {
+			targetDbDesc, err = ResolveTargetObject(ctx, p, newTn)
+		}
 	})
 	if err != nil {
 		return nil, err
 	}
+	if tableDesc.IsTemporary() && targetDbDesc.ID != tableDesc.ParentID {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot move temporary table %q to another database", tree.ErrString(oldTn))
+	}
 
 	if err := p.CheckPrivilege(ctx, targetDbDesc, privilege.CREATE); err != nil {
 		return nil, err
@@ -109,11 +110,15 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 		return newZeroNode(nil /* columns */), nil
 	}
 
+	renameDetails := sqlbase.TableDescriptor_NameInfo{
+		ParentID: tableDesc.NamespaceParentID(),
+		Name:     oldTn.Table()}
+
 	tableDesc.SetName(newTn.Table())
 	tableDesc.ParentID = targetDbDesc.ID
 
 	descKey := sqlbase.MakeDescMetadataKey(tableDesc.GetID())
-	newTbKey := tableKey{targetDbDesc.ID, newTn.Table()}.Key()
+	newTbKey := tableKey{tableDesc.NamespaceParentID(), newTn.Table()}.Key()
 
 	if err := tableDesc.Validate(ctx, p.txn, p.EvalContext().Settings); err != nil {
 		return nil, err
@@ -122,9 +127,6 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 	descID := tableDesc.GetID()
 	descDesc := sqlbase.WrapDescriptor(tableDesc)
 
-	renameDetails := sqlbase.TableDescriptor_NameInfo{
-		ParentID: prevDbDesc.ID,
-		Name:     oldTn.Table()}
 	tableDesc.DrainingNames = append(tableDesc.DrainingNames, renameDetails)
 	if err := p.writeSchemaChange(ctx, tableDesc, sqlbase.InvalidMutationID); err != nil {
 		return nil, err
@@ -110,6 +110,10 @@ func ResolveExistingObject(
 		goodType = desc.IsTable() || desc.IsView()
 	case requireSequenceDesc:
 		goodType = desc.IsSequence()
+	case requireMaterializedViewDesc:
+		goodType = desc.IsMaterializedView
+	case requireFunctionDesc:
+		goodType = desc.IsFunction()
 	}
 	if !goodType {
 		return nil, sqlbase.NewWrongObjectTypeError(tn, requiredTypeNames[requiredType])
@@ -176,13 +180,17 @@ const (
 	requireViewDesc
 	requireTableOrViewDesc
 	requireSequenceDesc
+	requireMaterializedViewDesc
+	requireFunctionDesc
 )
 
 var requiredTypeNames = [...]string{
-	requireTableDesc:       "table",
-	requireViewDesc:        "view",
-	requireTableOrViewDesc: "table or view",
-	requireSequenceDesc:    "sequence",
+	requireTableDesc:            "table",
+	requireViewDesc:             "view",
+	requireTableOrViewDesc:      "table or view",
+	requireSequenceDesc:         "sequence",
+	requireMaterializedViewDesc: "materialized view",
+	requireFunctionDesc:         "function",
 }
 
 // LookupSchema implements the tree.TableNameTargetResolver interface.
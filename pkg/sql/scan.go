@@ -17,11 +17,13 @@ package sql
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 
 	"github.com/pkg/errors"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -47,6 +49,9 @@ type scanNode struct {
 	// Set if the NO_INDEX_JOIN hint was given.
 	noIndexJoin bool
 
+	// Set if a TABLESAMPLE clause was given.
+	sample *tableSample
+
 	colCfg scanColumnsConfig
 	// The table columns, possibly including ones currently in schema changes.
 	cols []sqlbase.ColumnDescriptor
@@ -178,6 +183,15 @@ type scanRun struct {
 	// only true when running SCRUB commands.
 	isCheck bool
 
+	// If set, each row is only returned with probability sampleFraction
+	// (TABLESAMPLE BERNOULLI).
+	sampleRng      *rand.Rand
+	sampleFraction float64
+
+	// Set if TABLESAMPLE SYSTEM did not select any range; there is nothing to
+	// scan.
+	emptySample bool
+
 	fetcher sqlbase.RowFetcher
 }
 
@@ -206,6 +220,9 @@ func (n *scanNode) Next(params runParams) (bool, error) {
 			return false, err
 		}
 	}
+	if n.run.emptySample {
+		return false, nil
+	}
 
 	// We fetch one row at a time until we find one that passes the filter.
 	for n.hardLimit == 0 || n.run.rowIndex < n.hardLimit {
@@ -214,6 +231,9 @@ func (n *scanNode) Next(params runParams) (bool, error) {
 		if err != nil || n.run.row == nil {
 			return false, err
 		}
+		if n.run.sampleRng != nil && n.run.sampleRng.Float64() >= n.run.sampleFraction {
+			continue
+		}
 		params.extendedEvalCtx.IVarContainer = n
 		passesFilter, err := sqlbase.RunFilter(n.filter, params.EvalContext())
 		if err != nil {
@@ -241,11 +261,33 @@ func (n *scanNode) disableBatchLimit() {
 
 // initScan sets up the rowFetcher and starts a scan.
 func (n *scanNode) initScan(params runParams) error {
+	spans := n.spans
+	if n.sample != nil {
+		var bernoulli *distsqlrun.BernoulliSampleSpec
+		var err error
+		spans, bernoulli, err = n.sampledSpans(
+			params.ctx, params.EvalContext(),
+			params.p.DistSQLPlanner().spanResolver.NewSpanResolverIterator(params.p.txn),
+		)
+		if err != nil {
+			return err
+		}
+		if bernoulli != nil {
+			n.run.sampleRng = rand.New(rand.NewSource(bernoulli.Seed))
+			n.run.sampleFraction = bernoulli.Fraction
+		}
+		if len(spans) == 0 {
+			n.run.emptySample = true
+			n.run.scanInitialized = true
+			return nil
+		}
+	}
+
 	limitHint := n.limitHint()
 	if err := n.run.fetcher.StartScan(
 		params.ctx,
 		params.p.txn,
-		n.spans,
+		spans,
 		!n.disableBatchLimits,
 		limitHint,
 		params.p.extendedEvalCtx.Tracing.KVTracingEnabled(),
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -302,6 +303,10 @@ func DropTableDesc(
 ) error {
 	descKey := sqlbase.MakeDescMetadataKey(tableDesc.ID)
 	zoneKeyPrefix := config.MakeZoneKeyPrefix(uint32(tableDesc.ID))
+	statsKeyPrefix := roachpb.Key(encoding.EncodeVarintAscending(
+		sqlbase.MakeIndexKeyPrefix(&sqlbase.TableStatisticsTable, sqlbase.TableStatisticsTable.PrimaryIndex.ID),
+		int64(tableDesc.ID),
+	))
 
 	// Finished deleting all the table data, now delete the table meta data.
 	return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -310,11 +315,14 @@ func DropTableDesc(
 		if traceKV {
 			log.VEventf(ctx, 2, "Del %s", descKey)
 			log.VEventf(ctx, 2, "DelRange %s", zoneKeyPrefix)
+			log.VEventf(ctx, 2, "DelRange %s", statsKeyPrefix)
 		}
 		// Delete the descriptor.
 		b.Del(descKey)
 		// Delete the zone config entry for this table.
 		b.DelRange(zoneKeyPrefix, zoneKeyPrefix.PrefixEnd(), false /* returnKeys */)
+		// Delete the statistics for this table.
+		b.DelRange(statsKeyPrefix, statsKeyPrefix.PrefixEnd(), false /* returnKeys */)
 		if err := txn.SetSystemConfigTrigger(); err != nil {
 			return err
 		}
@@ -332,7 +340,7 @@ func (sc *SchemaChanger) truncateTable(
 	// If DropTime isn't set, assume this drop request is from a version
 	// 1.1 server and invoke legacy code that uses DeleteRange and range GC.
 	if table.DropTime == 0 {
-		return truncateTableInChunks(ctx, table, sc.db, false /* traceKV */)
+		return truncateTableInChunks(ctx, table, sc.db, &evalCtx.EvalContext, false /* traceKV */)
 	}
 
 	tableKey := roachpb.RKey(keys.MakeTablePrefix(uint32(table.ID)))
@@ -759,10 +767,16 @@ func (sc *SchemaChanger) waitToUpdateLeases(ctx context.Context, tableID sqlbase
 // done finalizes the mutations (adds new cols/indexes to the table).
 // It ensures that all nodes are on the current (pre-update) version of the
 // schema.
-// Returns the updated of the descriptor.
-func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error) {
+// Returns the updated of the descriptor. If completing the mutations queued up
+// a follow-up mutation, its ID and job are returned as well.
+func (sc *SchemaChanger) done(
+	ctx context.Context,
+) (*sqlbase.Descriptor, sqlbase.MutationID, *jobs.Job, error) {
 	isRollback := false
-	return sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+	var followUpID sqlbase.MutationID
+	var followUpJob *jobs.Job
+	desc, err := sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+		followUpID = sqlbase.InvalidMutationID
 		i := 0
 		for _, mutation := range desc.Mutations {
 			if mutation.MutationID != sc.mutationID {
@@ -771,6 +785,11 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 				break
 			}
 			isRollback = mutation.Rollback
+			if mutation.Direction == sqlbase.DescriptorMutation_ADD &&
+				(mutation.GetColumnTypeSwap() != nil || mutation.GetMaterializedViewRefresh() != nil) {
+				// The replaced column and indexes are dropped by a new mutation.
+				followUpID = desc.NextMutationID
+			}
 			desc.MakeMutationComplete(mutation)
 			i++
 		}
@@ -795,6 +814,17 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 			return errors.Wrapf(err, "failed to mark job %d as as successful", *sc.job.ID())
 		}
 
+		followUpJob = nil
+		if followUpID != sqlbase.InvalidMutationID {
+			tableDesc, err := sqlbase.GetTableDescFromID(ctx, txn, sc.tableID)
+			if err != nil {
+				return err
+			}
+			if followUpJob, err = sc.createFollowUpJob(ctx, txn, tableDesc, followUpID); err != nil {
+				return err
+			}
+		}
+
 		schemaChangeEventType := EventLogFinishSchemaChange
 		if isRollback {
 			schemaChangeEventType = EventLogFinishSchemaRollback
@@ -815,6 +845,57 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 			}{uint32(sc.mutationID)},
 		)
 	})
+	return desc, followUpID, followUpJob, err
+}
+
+// createFollowUpJob creates the job for the mutations with the given ID,
+// queued up by the completion of the schema changer's mutations, and
+// returns it.
+func (sc *SchemaChanger) createFollowUpJob(
+	ctx context.Context,
+	txn *client.Txn,
+	tableDesc *sqlbase.TableDescriptor,
+	mutationID sqlbase.MutationID,
+) (*jobs.Job, error) {
+	// Initialize refresh spans to scan the entire table.
+	span := tableDesc.PrimaryIndexSpan()
+	var spanList []jobspb.ResumeSpanList
+	for _, m := range tableDesc.Mutations {
+		if m.MutationID == mutationID {
+			spanList = append(spanList,
+				jobspb.ResumeSpanList{
+					ResumeSpans: []roachpb.Span{span},
+				},
+			)
+		}
+	}
+	payload := sc.job.Payload()
+	job := sc.jobRegistry.NewJob(jobs.Record{
+		Description:   "CLEAN UP " + payload.Description,
+		Username:      payload.Username,
+		DescriptorIDs: payload.DescriptorIDs,
+		Details:       jobspb.SchemaChangeDetails{ResumeSpanList: spanList},
+		Progress:      jobspb.SchemaChangeProgress{},
+	})
+	if err := job.WithTxn(txn).Created(ctx); err != nil {
+		return nil, err
+	}
+	// Set the transaction back to nil so that this job can
+	// be used in other transactions.
+	job.WithTxn(nil)
+
+	tableDesc.MutationJobs = append(tableDesc.MutationJobs, sqlbase.TableDescriptor_MutationJob{
+		MutationID: mutationID, JobID: *job.ID()})
+
+	// write descriptor, the version has already been incremented.
+	descKey := sqlbase.MakeDescMetadataKey(tableDesc.GetID())
+	descVal := sqlbase.WrapDescriptor(tableDesc)
+	b := txn.NewBatch()
+	b.Put(descKey, descVal)
+	if err := txn.Run(ctx, b); err != nil {
+		return nil, err
+	}
+	return job, nil
 }
 
 // notFirstInLine returns true whenever the schema change has been queued
@@ -859,8 +940,33 @@ func (sc *SchemaChanger) runStateMachineAndBackfill(
 	}
 
 	// Mark the mutations as completed.
-	_, err := sc.done(ctx)
-	return err
+	_, followUpID, followUpJob, err := sc.done(ctx)
+	if err != nil {
+		return err
+	}
+
+	if followUpJob != nil {
+		// Run the follow-up mutation right away rather than leaving it to the
+		// asynchronous schema changer, so that its effects are visible once
+		// the schema change returns.
+		sc.mutationID = followUpID
+		sc.job = followUpJob
+		if notFirst, err := sc.notFirstInLine(ctx); err != nil || notFirst {
+			// Leave the follow-up mutation to the asynchronous schema changer.
+			return err
+		}
+		if err := sc.job.Started(ctx); err != nil {
+			if log.V(2) {
+				log.Infof(ctx, "Failed to mark job %d as started: %v", *sc.job.ID(), err)
+			}
+		}
+		// The schema change itself is complete at this point; a failure here
+		// is retried by the asynchronous schema changer.
+		if err := sc.runStateMachineAndBackfill(ctx, lease, evalCtx); err != nil {
+			log.Warningf(ctx, "failed to run follow-up schema change %d: %v", sc.mutationID, err)
+		}
+	}
+	return nil
 }
 
 // reverseMutations reverses the direction of all the mutations with the
@@ -1485,7 +1591,8 @@ func createSchemaChangeEvalCtx(ts hlc.Timestamp, tracing *SessionTracing) extend
 		Tracing: tracing,
 		EvalContext: tree.EvalContext{
 			SessionData: &sessiondata.SessionData{
-				SearchPath: sqlbase.DefaultSearchPath,
+				ReorderJoinsLimit: xform.DefaultReorderJoinsLimit,
+				SearchPath:        sqlbase.DefaultSearchPath,
 				// The database is not supposed to be needed in schema changes, as there
 				// shouldn't be unqualified identifiers in backfills, and the pure functions
 				// that need it should have already been evaluated.
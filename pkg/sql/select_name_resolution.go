@@ -42,5 +42,6 @@ func (p *planner) resolveNames(
 	if expr == nil {
 		return nil, false, false, nil
 	}
-	return sqlbase.ResolveNamesUsingVisitor(&p.nameResolutionVisitor, expr, sources, ivarHelper, p.SessionData().SearchPath)
+	return sqlbase.ResolveNamesUsingVisitor(
+		&p.nameResolutionVisitor, expr, sources, ivarHelper, p.SessionData().SearchPath, p)
 }
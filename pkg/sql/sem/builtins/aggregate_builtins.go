@@ -45,7 +45,9 @@ func initAggregateBuiltins() {
 				panic(fmt.Sprintf("%s: aggregate functions should have tree.AggregateFunc constructors, "+
 					"found %v", k, a))
 			}
-			if a.WindowFunc == nil {
+			// GROUPING() is only meaningful for the grouping sets of a GROUP BY
+			// and cannot be used as a window function.
+			if a.WindowFunc == nil && k != Grouping {
 				panic(fmt.Sprintf("%s: aggregate functions should have tree.WindowFunc constructors, "+
 					"found %v", k, a))
 			}
@@ -278,6 +280,16 @@ var aggregates = map[string]builtinDefinition{
 			"Aggregates values as a JSON or JSONB array."),
 	),
 
+	Grouping: makeBuiltin(aggPropsNullableArgs(),
+		tree.Overload{
+			Types:         tree.VariadicType{VarType: types.Any},
+			ReturnType:    tree.FixedReturnType(types.Int),
+			AggregateFunc: newAnyNotNullAggregate,
+			Info: "Returns a bit mask indicating which of the arguments are not part " +
+				"of the grouping set of the current row, the last argument being the " +
+				"least significant bit. The arguments must be grouping expressions.",
+		}),
+
 	AnyNotNull: makePrivate(makeBuiltin(aggProps(),
 		makeAggOverloadWithReturnType(
 			[]types.T{types.Any},
@@ -290,6 +302,11 @@ var aggregates = map[string]builtinDefinition{
 // AnyNotNull is the name of the aggregate returned by NewAnyNotNullAggregate.
 const AnyNotNull = "any_not_null"
 
+// Grouping is the name of the GROUPING() function. It is not evaluated by
+// accumulating rows: the planners compute its result from the grouping set
+// that produced each output row.
+const Grouping = "grouping"
+
 func makePrivate(b builtinDefinition) builtinDefinition {
 	b.props.Private = true
 	return b
@@ -52,6 +52,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/trigram"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
@@ -82,6 +84,7 @@ const (
 	categorySystemInfo    = "System info"
 	categoryGenerator     = "Set-returning"
 	categoryJSON          = "JSONB"
+	categoryTextSearch    = "Full text search"
 )
 
 func categorizeType(t types.T) string {
@@ -872,6 +875,148 @@ var builtins = map[string]builtinDefinition{
 		}, types.Int, "Calculates the position where the string `find` begins in `input`. \n\nFor"+
 			" example, `strpos('doggie', 'gie')` returns `4`.")),
 
+	"similarity": makeBuiltin(
+		tree.FunctionProperties{Category: categoryString},
+		stringOverload2("left", "right", func(_ *tree.EvalContext, s, t string) (tree.Datum, error) {
+			return tree.NewDFloat(tree.DFloat(trigram.Similarity(s, t))), nil
+		}, types.Float, "Calculates the trigram similarity of `left` and `right`: the number of "+
+			"trigrams they share divided by the number of distinct trigrams in both, from 0 "+
+			"to 1.\n\nFor example, `similarity('word', 'two words')` returns `0.3636...`.")),
+
+	"word_similarity": makeBuiltin(
+		tree.FunctionProperties{Category: categoryString},
+		stringOverload2("left", "right", func(_ *tree.EvalContext, s, t string) (tree.Datum, error) {
+			return tree.NewDFloat(tree.DFloat(trigram.WordSimilarity(s, t))), nil
+		}, types.Float, "Calculates the greatest trigram similarity between `left` and any "+
+			"contiguous extent of the trigrams of `right`.\n\nFor example, "+
+			"`word_similarity('word', 'two words')` returns `0.8`.")),
+
+	"to_tsvector": makeBuiltin(
+		tree.FunctionProperties{Category: categoryTextSearch},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"document", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return toTSVector(tsearch.DefaultConfigName, string(tree.MustBeDString(args[0])))
+			},
+			Info: "Converts `document` to a text search vector with the `english` configuration." +
+				"\n\nFor example, `to_tsvector('The Fat Rats')` returns `'fat':2 'rat':3`.",
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"config", types.String}, {"document", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return toTSVector(string(tree.MustBeDString(args[0])), string(tree.MustBeDString(args[1])))
+			},
+			Info: "Converts `document` to a text search vector with the configuration `config`, " +
+				"which is `simple` or `english`.",
+		},
+	),
+
+	"to_tsquery": makeBuiltin(
+		tree.FunctionProperties{Category: categoryTextSearch},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"query", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return toTSQuery(tsearch.DefaultConfigName, string(tree.MustBeDString(args[0])))
+			},
+			Info: "Converts `query` to a text search query with the `english` configuration, " +
+				"normalizing its lexemes.\n\nFor example, `to_tsquery('Fat & Rats:*')` returns " +
+				"`'fat' & 'rat':*`.",
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"config", types.String}, {"query", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return toTSQuery(string(tree.MustBeDString(args[0])), string(tree.MustBeDString(args[1])))
+			},
+			Info: "Converts `query` to a text search query with the configuration `config`, " +
+				"normalizing its lexemes.",
+		},
+	),
+
+	"plainto_tsquery": makeBuiltin(
+		tree.FunctionProperties{Category: categoryTextSearch},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"text", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return plainToTSQuery(tsearch.DefaultConfigName, string(tree.MustBeDString(args[0])))
+			},
+			Info: "Converts `text` to a text search query that matches all of its words with the " +
+				"`english` configuration.\n\nFor example, `plainto_tsquery('The Fat Rats')` " +
+				"returns `'fat' & 'rat'`.",
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"config", types.String}, {"text", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return plainToTSQuery(string(tree.MustBeDString(args[0])), string(tree.MustBeDString(args[1])))
+			},
+			Info: "Converts `text` to a text search query that matches all of its words with the " +
+				"configuration `config`.",
+		},
+	),
+
+	"ts_rank": makeBuiltin(
+		tree.FunctionProperties{Category: categoryTextSearch},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector", types.TSVector}, {"query", types.TSQuery}},
+			ReturnType: tree.FixedReturnType(types.Float),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tsRank(tsearch.DefaultRankWeights, args[0], args[1], 0)
+			},
+			Info: "Ranks how well `vector` matches `query`, based on the frequency of the " +
+				"matching lexemes.",
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"vector", types.TSVector}, {"query", types.TSQuery}, {"normalization", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Float),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tsRank(tsearch.DefaultRankWeights, args[0], args[1], int(tree.MustBeDInt(args[2])))
+			},
+			Info: "Ranks how well `vector` matches `query`, based on the frequency of the " +
+				"matching lexemes. `normalization` is a bit mask of the ways to divide the " +
+				"rank: 1 by 1 + the logarithm of the document length, 2 by the document length, " +
+				"8 by the number of unique words, 16 by 1 + the logarithm of the number of " +
+				"unique words and 32 by itself + 1.",
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"weights", types.TArray{Typ: types.Float}}, {"vector", types.TSVector}, {"query", types.TSQuery},
+			},
+			ReturnType: tree.FixedReturnType(types.Float),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				weights, err := tsRankWeights(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return tsRank(weights, args[1], args[2], 0)
+			},
+			Info: "Ranks how well `vector` matches `query` with the given factors for the " +
+				"weights D, C, B and A, which default to `{0.1, 0.2, 0.4, 1.0}`.",
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"weights", types.TArray{Typ: types.Float}}, {"vector", types.TSVector},
+				{"query", types.TSQuery}, {"normalization", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Float),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				weights, err := tsRankWeights(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return tsRank(weights, args[1], args[2], int(tree.MustBeDInt(args[3])))
+			},
+			Info: "Ranks how well `vector` matches `query` with the given factors for the " +
+				"weights D, C, B and A and the given normalization.",
+		},
+	),
+
 	"overlay": makeBuiltin(defProps(),
 		tree.Overload{
 			Types: tree.ArgTypes{
@@ -2870,6 +3015,60 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	"crdb_internal.create_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+			// The baselines are managed through the session's planner, which
+			// isn't available on remote nodes.
+			DistsqlBlacklist: true,
+			Impure:           true,
+			Privileged:       true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"statement", types.String}},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				fingerprint, err := ctx.PlanBaselines.CreatePlanBaseline(
+					ctx.Ctx(), string(tree.MustBeDString(args[0])),
+				)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDString(fingerprint), nil
+			},
+			Info: "Pins the join algorithms chosen by the join hints of `statement` " +
+				"(e.g. INNER HASH JOIN) for all the statements with the same fingerprint " +
+				"in the current database, and returns that fingerprint.",
+		},
+	),
+
+	"crdb_internal.drop_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+			// The baselines are managed through the session's planner, which
+			// isn't available on remote nodes.
+			DistsqlBlacklist: true,
+			Impure:           true,
+			Privileged:       true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"fingerprint", types.String}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				dropped, err := ctx.PlanBaselines.DropPlanBaseline(
+					ctx.Ctx(), string(tree.MustBeDString(args[0])),
+				)
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(tree.DBool(dropped)), nil
+			},
+			Info: "Removes the plan baseline for the statements with the given fingerprint " +
+				"in the current database. " +
+				"Returns false if there was none.",
+		},
+	),
+
 	"crdb_internal.set_vmodule": makeBuiltin(
 		tree.FunctionProperties{
 			Category:   categorySystemInfo,
@@ -3791,6 +3990,80 @@ func overlay(s, to string, pos, size int) (tree.Datum, error) {
 	return tree.NewDString(string(runes[:pos]) + to + string(runes[after:])), nil
 }
 
+// getTSConfig returns the text search configuration with the given name.
+func getTSConfig(name string) (*tsearch.Config, error) {
+	c, ok := tsearch.GetConfig(name)
+	if !ok {
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"text search configuration %q does not exist", name)
+	}
+	return c, nil
+}
+
+func toTSVector(config, document string) (tree.Datum, error) {
+	c, err := getTSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDTSVector(c.ToVector(document)), nil
+}
+
+func toTSQuery(config, query string) (tree.Datum, error) {
+	c, err := getTSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	q, err := c.ToQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDTSQuery(q), nil
+}
+
+func plainToTSQuery(config, text string) (tree.Datum, error) {
+	c, err := getTSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDTSQuery(c.PlainToQuery(text)), nil
+}
+
+// tsRankWeights returns the weights of a ts_rank weights array, which must
+// hold a factor for each of the weights D, C, B and A.
+func tsRankWeights(d tree.Datum) ([4]float64, error) {
+	var weights [4]float64
+	arr := tree.MustBeDArray(d)
+	if arr.Len() < len(weights) {
+		return weights, pgerror.NewError(pgerror.CodeArraySubscriptError, "array of weight is too short")
+	}
+	for i := range weights {
+		if arr.Array[i] == tree.DNull {
+			return weights, pgerror.NewError(pgerror.CodeNullValueNotAllowedError,
+				"array of weight must not contain nulls")
+		}
+		w := float64(*arr.Array[i].(*tree.DFloat))
+		if w > 1 {
+			return weights, pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+				"weight out of range")
+		}
+		if w < 0 {
+			w = tsearch.DefaultRankWeights[i]
+		}
+		weights[i] = w
+	}
+	return weights, nil
+}
+
+func tsRank(weights [4]float64, vector, query tree.Datum, normalization int) (tree.Datum, error) {
+	if normalization < 0 {
+		return nil, pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"normalization must not be negative")
+	}
+	v := tree.MustBeDTSVector(vector)
+	q := tree.MustBeDTSQuery(query)
+	return tree.NewDFloat(tree.DFloat(tsearch.Rank(weights, v.Vector, q.Query, normalization))), nil
+}
+
 func roundDecimal(x *apd.Decimal, n int32) (tree.Datum, error) {
 	dd := &tree.DDecimal{}
 	_, err := tree.HighPrecisionCtx.Quantize(&dd.Decimal, x, -n)
@@ -843,6 +843,37 @@ var pgBuiltins = map[string]builtinDefinition{
 		},
 	),
 
+	// pg_notify returns void in Postgres; NULL is returned instead.
+	// https://www.postgresql.org/docs/10/static/functions-info.html
+	"pg_notify": makeBuiltin(
+		tree.FunctionProperties{
+			// The notification is queued in the session's transaction, which
+			// isn't available on remote nodes.
+			DistsqlBlacklist: true,
+			Impure:           true,
+			NullableArgs:     true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"channel", types.String}, {"payload", types.String}},
+			ReturnType: tree.FixedReturnType(types.Unknown),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				var channel, payload string
+				if args[0] != tree.DNull {
+					channel = string(tree.MustBeDString(args[0]))
+				}
+				if args[1] != tree.DNull {
+					payload = string(tree.MustBeDString(args[1]))
+				}
+				if err := ctx.Notifier.SendNotification(channel, payload); err != nil {
+					return nil, err
+				}
+				return tree.DNull, nil
+			},
+			Info: "Sends a notification with the given payload to the sessions listening " +
+				"on the given channel, once the current transaction commits.",
+		},
+	),
+
 	// pg_is_in_recovery returns true if the Postgres database is currently in
 	// recovery.  This is not applicable so this can always return false.
 	// https://www.postgresql.org/docs/current/static/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
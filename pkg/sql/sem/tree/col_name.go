@@ -17,6 +17,7 @@ package tree
 import (
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 )
 
@@ -72,6 +73,14 @@ func ComputeColNameInternal(sp sessiondata.SearchPath, target Expr) (int, string
 	case *FuncExpr:
 		fd, err := e.Func.Resolve(sp)
 		if err != nil {
+			// The name may designate a user-defined function, which is
+			// only resolved during type checking.
+			if n, ok := e.Func.FunctionReference.(*UnresolvedName); ok {
+				if pgErr, ok := pgerror.GetPGCause(err); ok &&
+					pgErr.Code == pgerror.CodeUndefinedFunctionError {
+					return 2, n.Parts[0], nil
+				}
+			}
 			return 0, "", err
 		}
 		return 2, fd.Name, nil
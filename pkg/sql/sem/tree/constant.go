@@ -403,6 +403,8 @@ var (
 		types.UUID,
 		types.INet,
 		types.JSON,
+		types.TSVector,
+		types.TSQuery,
 	}
 	// StrValAvailBytes is the set of types convertible to byte array.
 	StrValAvailBytes = []types.T{types.Bytes, types.UUID, types.String}
@@ -225,6 +225,20 @@ func mustParseDJSON(t *testing.T, s string) tree.Datum {
 	}
 	return d
 }
+func mustParseDTSVector(t *testing.T, s string) tree.Datum {
+	d, err := tree.ParseDTSVector(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+func mustParseDTSQuery(t *testing.T, s string) tree.Datum {
+	d, err := tree.ParseDTSQuery(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
 
 var parseFuncs = map[types.T]func(*testing.T, string) tree.Datum{
 	types.String:      func(t *testing.T, s string) tree.Datum { return tree.NewDString(s) },
@@ -235,6 +249,8 @@ var parseFuncs = map[types.T]func(*testing.T, string) tree.Datum{
 	types.TimestampTZ: mustParseDTimestampTZ,
 	types.Interval:    mustParseDInterval,
 	types.JSON:        mustParseDJSON,
+	types.TSVector:    mustParseDTSVector,
+	types.TSQuery:     mustParseDTSQuery,
 }
 
 func typeSet(tys ...types.T) map[types.T]struct{} {
@@ -257,15 +273,15 @@ func TestStringConstantResolveAvailableTypes(t *testing.T) {
 	}{
 		{
 			c:            tree.NewStrVal("abc 世界"),
-			parseOptions: typeSet(types.String, types.Bytes),
+			parseOptions: typeSet(types.String, types.Bytes, types.TSVector),
 		},
 		{
 			c:            tree.NewStrVal("true"),
-			parseOptions: typeSet(types.String, types.Bytes, types.Bool, types.JSON),
+			parseOptions: typeSet(types.String, types.Bytes, types.Bool, types.JSON, types.TSVector, types.TSQuery),
 		},
 		{
 			c:            tree.NewStrVal("2010-09-28"),
-			parseOptions: typeSet(types.String, types.Bytes, types.Date, types.Timestamp, types.TimestampTZ),
+			parseOptions: typeSet(types.String, types.Bytes, types.Date, types.Timestamp, types.TimestampTZ, types.TSVector, types.TSQuery),
 		},
 		{
 			c:            tree.NewStrVal("2010-09-28 12:00:00.1"),
@@ -277,7 +293,7 @@ func TestStringConstantResolveAvailableTypes(t *testing.T) {
 		},
 		{
 			c:            tree.NewStrVal("PT12H2M"),
-			parseOptions: typeSet(types.String, types.Bytes, types.Interval),
+			parseOptions: typeSet(types.String, types.Bytes, types.Interval, types.TSVector, types.TSQuery),
 		},
 		{
 			c:            tree.NewBytesStrVal("abc 世界"),
@@ -19,6 +19,7 @@ type CopyFrom struct {
 	Table   NormalizableTableName
 	Columns NameList
 	Stdin   bool
+	Options KVOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -34,4 +35,53 @@ func (node *CopyFrom) Format(ctx *FmtCtx) {
 	if node.Stdin {
 		ctx.WriteString("STDIN")
 	}
+	formatCopyOptions(ctx, node.Options)
+}
+
+// CopyTo represents a COPY TO statement. Exactly one of Table and Statement
+// is set.
+type CopyTo struct {
+	Table     NormalizableTableName
+	Columns   NameList
+	Statement *Select
+	Options   KVOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CopyTo) Format(ctx *FmtCtx) {
+	ctx.WriteString("COPY ")
+	if node.Statement != nil {
+		ctx.WriteByte('(')
+		ctx.FormatNode(node.Statement)
+		ctx.WriteByte(')')
+	} else {
+		ctx.FormatNode(&node.Table)
+		if len(node.Columns) > 0 {
+			ctx.WriteString(" (")
+			ctx.FormatNode(&node.Columns)
+			ctx.WriteString(")")
+		}
+	}
+	ctx.WriteString(" TO STDOUT")
+	formatCopyOptions(ctx, node.Options)
+}
+
+// formatCopyOptions formats the options of a COPY statement using the
+// generic option syntax.
+func formatCopyOptions(ctx *FmtCtx, opts KVOptions) {
+	if len(opts) == 0 {
+		return
+	}
+	ctx.WriteString(" WITH (")
+	for i := range opts {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&opts[i].Key)
+		if opts[i].Value != nil {
+			ctx.WriteByte(' ')
+			ctx.FormatNode(opts[i].Value)
+		}
+	}
+	ctx.WriteByte(')')
 }
@@ -72,13 +72,19 @@ func (node *CreateDatabase) Format(ctx *FmtCtx) {
 
 // IndexElem represents a column with a direction in a CREATE INDEX statement.
 type IndexElem struct {
-	Column    Name
+	Column Name
+	// OpClass is the operator class of the column, if specified.
+	OpClass   Name
 	Direction Direction
 }
 
 // Format implements the NodeFormatter interface.
 func (node *IndexElem) Format(ctx *FmtCtx) {
 	ctx.FormatNode(&node.Column)
+	if node.OpClass != "" {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(&node.OpClass)
+	}
 	if node.Direction != DefaultDirection {
 		ctx.WriteByte(' ')
 		ctx.WriteString(node.Direction.String())
@@ -112,6 +118,9 @@ type CreateIndex struct {
 	Storing     NameList
 	Interleave  *InterleaveDef
 	PartitionBy *PartitionBy
+	// Predicate, if set, restricts the index to rows for which it evaluates
+	// to true (a partial index).
+	Predicate Expr
 }
 
 // Format implements the NodeFormatter interface.
@@ -147,6 +156,10 @@ func (node *CreateIndex) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // TableDef represents a column, index or constraint definition within a CREATE
@@ -508,6 +521,7 @@ type IndexTableDef struct {
 	Interleave  *InterleaveDef
 	Inverted    bool
 	PartitionBy *PartitionBy
+	Predicate   Expr
 }
 
 // SetName implements the TableDef interface.
@@ -539,6 +553,10 @@ func (node *IndexTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // ConstraintTableDef represents a constraint definition within a CREATE TABLE
@@ -585,6 +603,10 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // ReferenceAction is the method used to maintain referential integrity through
@@ -838,6 +860,7 @@ func (node *RangePartition) Format(ctx *FmtCtx) {
 // CreateTable represents a CREATE TABLE statement.
 type CreateTable struct {
 	IfNotExists   bool
+	Temporary     bool
 	Table         NormalizableTableName
 	Interleave    *InterleaveDef
 	PartitionBy   *PartitionBy
@@ -854,7 +877,11 @@ func (node *CreateTable) As() bool {
 
 // Format implements the NodeFormatter interface.
 func (node *CreateTable) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE TABLE ")
+	ctx.WriteString("CREATE ")
+	if node.Temporary {
+		ctx.WriteString("TEMPORARY ")
+	}
+	ctx.WriteString("TABLE ")
 	if node.IfNotExists {
 		ctx.WriteString("IF NOT EXISTS ")
 	}
@@ -1035,14 +1062,19 @@ func (node *CreateRole) Format(ctx *FmtCtx) {
 
 // CreateView represents a CREATE VIEW statement.
 type CreateView struct {
-	Name        NormalizableTableName
-	ColumnNames NameList
-	AsSource    *Select
+	Name         NormalizableTableName
+	ColumnNames  NameList
+	AsSource     *Select
+	Materialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateView) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE VIEW ")
+	ctx.WriteString("CREATE ")
+	if node.Materialized {
+		ctx.WriteString("MATERIALIZED ")
+	}
+	ctx.WriteString("VIEW ")
 	ctx.FormatNode(&node.Name)
 
 	if len(node.ColumnNames) > 0 {
@@ -1056,6 +1088,115 @@ func (node *CreateView) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.AsSource)
 }
 
+// RefreshMaterializedView represents a REFRESH MATERIALIZED VIEW statement.
+type RefreshMaterializedView struct {
+	Name NormalizableTableName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RefreshMaterializedView) Format(ctx *FmtCtx) {
+	ctx.WriteString("REFRESH MATERIALIZED VIEW ")
+	ctx.FormatNode(&node.Name)
+}
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	Replace    bool
+	Name       NormalizableTableName
+	Args       FunctionArgs
+	ReturnType coltypes.T
+	Options    FunctionOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("FUNCTION ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Args)
+	ctx.WriteString(") RETURNS ")
+	node.ReturnType.Format(ctx.Buffer, ctx.flags.EncodeFlags())
+	ctx.FormatNode(&node.Options)
+}
+
+// FunctionArgs represents a list of function arguments.
+type FunctionArgs []FunctionArg
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionArgs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// FunctionArg represents an argument in a CREATE FUNCTION statement.
+type FunctionArg struct {
+	// Name is empty for unnamed arguments.
+	Name Name
+	Type coltypes.T
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionArg) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	node.Type.Format(ctx.Buffer, ctx.flags.EncodeFlags())
+}
+
+// FunctionOptions represents a list of function options.
+type FunctionOptions []FunctionOption
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionOptions) Format(ctx *FmtCtx) {
+	for i := range *node {
+		option := &(*node)[i]
+		ctx.WriteByte(' ')
+		switch option.Name {
+		case FuncOptAs:
+			ctx.WriteString(option.Name)
+			ctx.WriteByte(' ')
+			lex.EncodeSQLStringWithFlags(ctx.Buffer, option.StrVal, ctx.flags.EncodeFlags())
+		case FuncOptLanguage:
+			ctx.WriteString(option.Name)
+			ctx.WriteByte(' ')
+			ctx.FormatNameP(&option.StrVal)
+		case FuncOptImmutable, FuncOptStable, FuncOptVolatile,
+			FuncOptStrict, FuncOptCalledOnNullInput, FuncOptReturnsNullOnNullInput:
+			ctx.WriteString(option.Name)
+		default:
+			panic(fmt.Sprintf("unexpected FunctionOption: %v", option))
+		}
+	}
+}
+
+// FunctionOption represents an option on a CREATE FUNCTION statement.
+type FunctionOption struct {
+	Name string
+
+	StrVal string
+}
+
+// Names of options on CREATE FUNCTION.
+const (
+	FuncOptAs                     = "AS"
+	FuncOptLanguage               = "LANGUAGE"
+	FuncOptImmutable              = "IMMUTABLE"
+	FuncOptStable                 = "STABLE"
+	FuncOptVolatile               = "VOLATILE"
+	FuncOptStrict                 = "STRICT"
+	FuncOptCalledOnNullInput      = "CALLED ON NULL INPUT"
+	FuncOptReturnsNullOnNullInput = "RETURNS NULL ON NULL INPUT"
+)
+
 // CreateStats represents a CREATE STATISTICS statement.
 type CreateStats struct {
 	Name        Name
@@ -0,0 +1,164 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "strconv"
+
+// CursorScrollOption represents the scroll option of a DECLARE statement.
+type CursorScrollOption int8
+
+// CursorScrollOption values.
+const (
+	UnspecifiedScroll CursorScrollOption = iota
+	Scroll
+	NoScroll
+)
+
+// DeclareCursor represents a DECLARE statement.
+type DeclareCursor struct {
+	Name        Name
+	Binary      bool
+	Insensitive bool
+	Scroll      CursorScrollOption
+	Hold        bool
+	Select      *Select
+}
+
+var _ Statement = &DeclareCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *DeclareCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("DECLARE ")
+	ctx.FormatNode(&node.Name)
+	if node.Binary {
+		ctx.WriteString(" BINARY")
+	}
+	if node.Insensitive {
+		ctx.WriteString(" INSENSITIVE")
+	}
+	switch node.Scroll {
+	case Scroll:
+		ctx.WriteString(" SCROLL")
+	case NoScroll:
+		ctx.WriteString(" NO SCROLL")
+	}
+	ctx.WriteString(" CURSOR")
+	if node.Hold {
+		ctx.WriteString(" WITH HOLD")
+	}
+	ctx.WriteString(" FOR ")
+	ctx.FormatNode(node.Select)
+}
+
+// FetchType is the direction of a FETCH or MOVE statement.
+type FetchType int8
+
+// FetchType values.
+const (
+	// FetchNormal moves Count rows forward, or backward if Count is
+	// negative. It is used for NEXT, PRIOR, FORWARD and BACKWARD.
+	FetchNormal FetchType = iota
+	// FetchRelative moves to the Count-th row after the current one, or
+	// before it if Count is negative.
+	FetchRelative
+	// FetchAbsolute moves to the Count-th row, counting from the end if
+	// Count is negative.
+	FetchAbsolute
+	// FetchFirst moves to the first row.
+	FetchFirst
+	// FetchLast moves to the last row.
+	FetchLast
+	// FetchAll moves forward through all the remaining rows.
+	FetchAll
+	// FetchBackwardAll moves backward through all the preceding rows.
+	FetchBackwardAll
+)
+
+// CursorStmt contains the cursor and direction of a FETCH or MOVE
+// statement.
+type CursorStmt struct {
+	Name      Name
+	FetchType FetchType
+	// Count is only used by FetchNormal, FetchRelative and FetchAbsolute.
+	Count int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CursorStmt) Format(ctx *FmtCtx) {
+	switch node.FetchType {
+	case FetchNormal:
+		ctx.WriteString(strconv.FormatInt(node.Count, 10))
+	case FetchRelative:
+		ctx.WriteString("RELATIVE ")
+		ctx.WriteString(strconv.FormatInt(node.Count, 10))
+	case FetchAbsolute:
+		ctx.WriteString("ABSOLUTE ")
+		ctx.WriteString(strconv.FormatInt(node.Count, 10))
+	case FetchFirst:
+		ctx.WriteString("FIRST")
+	case FetchLast:
+		ctx.WriteString("LAST")
+	case FetchAll:
+		ctx.WriteString("ALL")
+	case FetchBackwardAll:
+		ctx.WriteString("BACKWARD ALL")
+	}
+	ctx.WriteByte(' ')
+	ctx.FormatNode(&node.Name)
+}
+
+// FetchCursor represents a FETCH statement.
+type FetchCursor struct {
+	CursorStmt
+}
+
+var _ Statement = &FetchCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *FetchCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("FETCH ")
+	ctx.FormatNode(&node.CursorStmt)
+}
+
+// MoveCursor represents a MOVE statement.
+type MoveCursor struct {
+	CursorStmt
+}
+
+var _ Statement = &MoveCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *MoveCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("MOVE ")
+	ctx.FormatNode(&node.CursorStmt)
+}
+
+// CloseCursor represents a CLOSE statement.
+type CloseCursor struct {
+	// Name is empty for CLOSE ALL.
+	Name Name
+}
+
+var _ Statement = &CloseCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *CloseCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("CLOSE ")
+	if node.Name == "" {
+		ctx.WriteString("ALL")
+		return
+	}
+	ctx.FormatNode(&node.Name)
+}
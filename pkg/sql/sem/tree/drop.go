@@ -23,6 +23,8 @@
 
 package tree
 
+import "github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+
 // DropBehavior represents options for dropping schema elements.
 type DropBehavior int
 
@@ -105,14 +107,19 @@ func (node *DropTable) Format(ctx *FmtCtx) {
 
 // DropView represents a DROP VIEW statement.
 type DropView struct {
-	Names        NormalizableTableNames
-	IfExists     bool
-	DropBehavior DropBehavior
+	Names          NormalizableTableNames
+	IfExists       bool
+	DropBehavior   DropBehavior
+	IsMaterialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *DropView) Format(ctx *FmtCtx) {
-	ctx.WriteString("DROP VIEW ")
+	ctx.WriteString("DROP ")
+	if node.IsMaterialized {
+		ctx.WriteString("MATERIALIZED ")
+	}
+	ctx.WriteString("VIEW ")
 	if node.IfExists {
 		ctx.WriteString("IF EXISTS ")
 	}
@@ -143,6 +150,64 @@ func (node *DropSequence) Format(ctx *FmtCtx) {
 	}
 }
 
+// DropFunction represents a DROP FUNCTION statement.
+type DropFunction struct {
+	Functions    FunctionSignatures
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP FUNCTION ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Functions)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
+
+// FunctionSignatures represents a list of function signatures.
+type FunctionSignatures []FunctionSignature
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionSignatures) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// FunctionSignature designates a function by name and, optionally, by the
+// types of its arguments.
+type FunctionSignature struct {
+	Name NormalizableTableName
+	// HasArgTypes is set if the argument types were specified, in which
+	// case they must match the ones the function was created with.
+	HasArgTypes bool
+	ArgTypes    []coltypes.T
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionSignature) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.Name)
+	if node.HasArgTypes {
+		ctx.WriteByte('(')
+		for i, typ := range node.ArgTypes {
+			if i > 0 {
+				ctx.WriteString(", ")
+			}
+			typ.Format(ctx.Buffer, ctx.flags.EncodeFlags())
+		}
+		ctx.WriteByte(')')
+	}
+}
+
 // DropUser represents a DROP USER statement
 type DropUser struct {
 	Names    Exprs
@@ -45,6 +45,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/trigram"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -1308,6 +1310,17 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				return dd, err
 			},
 		},
+		// The % operator on strings is the trigram similarity comparison. The
+		// type checker turns it into a ComparisonExpr with TrigramSimilar (see
+		// BinaryExpr.TypeCheck).
+		BinOp{
+			LeftType:   types.String,
+			RightType:  types.String,
+			ReturnType: types.Bool,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return trigramSimilar(left, right), nil
+			},
+		},
 	},
 
 	Concat: {
@@ -1624,6 +1637,65 @@ func init() {
 			NullableArgs: true,
 		})
 	}
+
+	// Array containment and overlap comparisons.
+	for _, t := range types.AnyNonArray {
+		CmpOps[Contains] = append(CmpOps[Contains], CmpOp{
+			LeftType:  types.TArray{Typ: t},
+			RightType: types.TArray{Typ: t},
+			fn: func(ctx *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(ArrayContains(ctx, MustBeDArray(left), MustBeDArray(right)))), nil
+			},
+		})
+
+		CmpOps[ContainedBy] = append(CmpOps[ContainedBy], CmpOp{
+			LeftType:  types.TArray{Typ: t},
+			RightType: types.TArray{Typ: t},
+			fn: func(ctx *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(ArrayContains(ctx, MustBeDArray(right), MustBeDArray(left)))), nil
+			},
+		})
+
+		CmpOps[Overlaps] = append(CmpOps[Overlaps], CmpOp{
+			LeftType:  types.TArray{Typ: t},
+			RightType: types.TArray{Typ: t},
+			fn: func(ctx *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(ArrayOverlaps(ctx, MustBeDArray(left), MustBeDArray(right)))), nil
+			},
+		})
+	}
+}
+
+// ArrayContains returns whether every element of b is equal to some element of
+// a. As in Postgres, NULL elements are not equal to anything, so an array
+// containing a NULL is not contained in any array.
+func ArrayContains(ctx *EvalContext, a, b *DArray) bool {
+	for _, needle := range b.Array {
+		if needle == DNull || !arrayHasElement(ctx, a, needle) {
+			return false
+		}
+	}
+	return true
+}
+
+// ArrayOverlaps returns whether a and b have an element in common. NULL
+// elements are not equal to anything.
+func ArrayOverlaps(ctx *EvalContext, a, b *DArray) bool {
+	for _, needle := range b.Array {
+		if needle != DNull && arrayHasElement(ctx, a, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func arrayHasElement(ctx *EvalContext, a *DArray, needle Datum) bool {
+	for _, elem := range a.Array {
+		if elem != DNull && elem.Compare(ctx, needle) == 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func init() {
@@ -1693,6 +1765,8 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeEqFn(types.TimeTZ, types.TimeTZ),
 		makeEqFn(types.Timestamp, types.Timestamp),
 		makeEqFn(types.TimestampTZ, types.TimestampTZ),
+		makeEqFn(types.TSQuery, types.TSQuery),
+		makeEqFn(types.TSVector, types.TSVector),
 		makeEqFn(types.UUID, types.UUID),
 
 		// Mixed-type comparisons.
@@ -1837,6 +1911,8 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeIsFn(types.TimeTZ, types.TimeTZ),
 		makeIsFn(types.Timestamp, types.Timestamp),
 		makeIsFn(types.TimestampTZ, types.TimestampTZ),
+		makeIsFn(types.TSQuery, types.TSQuery),
+		makeIsFn(types.TSVector, types.TSVector),
 		makeIsFn(types.UUID, types.UUID),
 
 		// Mixed-type comparisons.
@@ -1886,6 +1962,8 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeEvalTupleIn(types.TimeTZ),
 		makeEvalTupleIn(types.Timestamp),
 		makeEvalTupleIn(types.TimestampTZ),
+		makeEvalTupleIn(types.TSQuery),
+		makeEvalTupleIn(types.TSVector),
 		makeEvalTupleIn(types.UUID),
 	},
 
@@ -2026,6 +2104,82 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			},
 		},
 	},
+
+	Overlaps: {
+		CmpOp{
+			LeftType:  types.INet,
+			RightType: types.INet,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				ipAddr := MustBeDIPAddr(left).IPAddr
+				other := MustBeDIPAddr(right).IPAddr
+				return MakeDBool(DBool(ipAddr.ContainsOrContainedBy(&other))), nil
+			},
+		},
+	},
+
+	TrigramSimilar: {
+		CmpOp{
+			LeftType:  types.String,
+			RightType: types.String,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return trigramSimilar(left, right), nil
+			},
+		},
+	},
+
+	TSMatches: {
+		CmpOp{
+			LeftType:  types.TSVector,
+			RightType: types.TSQuery,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return tsMatches(MustBeDTSVector(left).Vector, MustBeDTSQuery(right).Query), nil
+			},
+		},
+		CmpOp{
+			LeftType:  types.TSQuery,
+			RightType: types.TSVector,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return tsMatches(MustBeDTSVector(right).Vector, MustBeDTSQuery(left).Query), nil
+			},
+		},
+		// A string operand is a document, which is converted to a vector using
+		// the default configuration. A string query is converted like
+		// plainto_tsquery does.
+		CmpOp{
+			LeftType:  types.String,
+			RightType: types.TSQuery,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				v := defaultTSConfig.ToVector(string(MustBeDString(left)))
+				return tsMatches(v, MustBeDTSQuery(right).Query), nil
+			},
+		},
+		CmpOp{
+			LeftType:  types.String,
+			RightType: types.String,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				v := defaultTSConfig.ToVector(string(MustBeDString(left)))
+				q := defaultTSConfig.PlainToQuery(string(MustBeDString(right)))
+				return tsMatches(v, q), nil
+			},
+		},
+	},
+}
+
+// trigramSimilar implements the % operator on strings: whether the trigram
+// similarity of the strings reaches the similarity threshold.
+func trigramSimilar(left, right Datum) Datum {
+	sim := trigram.Similarity(string(MustBeDString(left)), string(MustBeDString(right)))
+	return MakeDBool(DBool(sim >= trigram.DefaultSimilarityThreshold))
+}
+
+// defaultTSConfig is the text search configuration that is used when none
+// is specified.
+var defaultTSConfig, _ = tsearch.GetConfig(tsearch.DefaultConfigName)
+
+// tsMatches implements the @@ operator: whether the text search vector
+// matches the query.
+func tsMatches(v tsearch.Vector, q tsearch.Query) Datum {
+	return MakeDBool(DBool(q.Matches(v)))
 }
 
 // This map contains the inverses for operators in the CmpOps map that have
@@ -2384,6 +2538,29 @@ type SequenceOperators interface {
 	SetSequenceValue(ctx context.Context, seqName *TableName, newVal int64, isCalled bool) error
 }
 
+// NotificationSender is used by the pg_notify() builtin to send
+// notifications from EvalContext.
+type NotificationSender interface {
+	// SendNotification queues a notification on the given channel, which is
+	// delivered to the listening sessions once the current transaction
+	// commits.
+	SendNotification(channel, payload string) error
+}
+
+// PlanBaselineManager is used by the crdb_internal.create_plan_baseline()
+// and crdb_internal.drop_plan_baseline() builtins to manage the plan
+// baselines consulted by the optimizer.
+type PlanBaselineManager interface {
+	// CreatePlanBaseline records the join hints of the given statement as the
+	// plan baseline for all statements with the same fingerprint in the
+	// current database, and returns that fingerprint.
+	CreatePlanBaseline(ctx context.Context, sql string) (string, error)
+
+	// DropPlanBaseline removes the plan baseline for the given fingerprint in
+	// the current database. It returns false if there was none.
+	DropPlanBaseline(ctx context.Context, fingerprint string) (bool, error)
+}
+
 // CtxProvider is anything that can return a Context.
 //
 // TODO(andrei): I think this whole CtxProvider business might not be needed any
@@ -2500,6 +2677,10 @@ type EvalContext struct {
 
 	Sequence SequenceOperators
 
+	Notifier NotificationSender
+
+	PlanBaselines PlanBaselineManager
+
 	// Ths transaction in which the statement is executing.
 	Txn *client.Txn
 
@@ -3091,6 +3272,10 @@ func PerformCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 			s = t.name
 		case *DJSON:
 			s = t.JSON.String()
+		case *DTSVector:
+			s = t.Vector.String()
+		case *DTSQuery:
+			s = t.Query.String()
 		}
 		switch c := t.(type) {
 		case *coltypes.TString:
@@ -3269,6 +3454,20 @@ func PerformCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 		case *DJSON:
 			return v, nil
 		}
+	case *coltypes.TTSVector:
+		switch v := d.(type) {
+		case *DString:
+			return ParseDTSVector(string(*v))
+		case *DTSVector:
+			return v, nil
+		}
+	case *coltypes.TTSQuery:
+		switch v := d.(type) {
+		case *DString:
+			return ParseDTSQuery(string(*v))
+		case *DTSQuery:
+			return v, nil
+		}
 	case *coltypes.TArray:
 		switch v := d.(type) {
 		case *DString:
@@ -3913,6 +4112,16 @@ func (t *DJSON) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DTSVector) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DTSQuery) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t dNull) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
@@ -727,6 +727,33 @@ func TestEval(t *testing.T) {
 		{`ARRAY[1,2,3] IS DISTINCT FROM NULL`, `true`},
 		{`ARRAY[1,2,3] IS NOT DISTINCT FROM NULL`, `false`},
 		{`NULL::INT[] IS DISTINCT FROM NULL::INT[]`, `false`},
+		// Array containment and overlap.
+		{`ARRAY[1,2,3] @> ARRAY[3,1]`, `true`},
+		{`ARRAY[1,2,3] @> ARRAY[1,4]`, `false`},
+		{`ARRAY[1,2,3] @> ARRAY[]`, `true`},
+		{`ARRAY[1,2,NULL] @> ARRAY[NULL::INT]`, `false`},
+		{`ARRAY['a','b'] <@ ARRAY['b','a','c']`, `true`},
+		{`ARRAY['a','d'] <@ ARRAY['b','a','c']`, `false`},
+		{`ARRAY[1,2,3] && ARRAY[4,3]`, `true`},
+		{`ARRAY[1,2,3] && ARRAY[4,5]`, `false`},
+		{`ARRAY[1,NULL] && ARRAY[NULL::INT]`, `false`},
+		{`ARRAY[1,2,3] && NULL`, `NULL`},
+		// Trigram similarity.
+		{`'word' % 'two words'`, `true`},
+		{`'word' % 'two dogs'`, `false`},
+		{`'Cat' % 'cat'`, `true`},
+		{`'cat' % NULL`, `NULL`},
+		{`7 % 3`, `1`},
+		// Text search.
+		{`'a fat cat'::TSVECTOR @@ 'cat & !dog'::TSQUERY`, `true`},
+		{`'cat:* & fat'::TSQUERY @@ 'a fat cats'::TSVECTOR`, `true`},
+		{`'fat:1A cat:2'::TSVECTOR @@ 'cat:A'::TSQUERY`, `false`},
+		{`'The Fat Rats' @@ 'rat'::TSQUERY`, `true`},
+		{`'The Fat Rats' @@ 'the rats'`, `true`},
+		{`'a fat cat'::TSVECTOR @@ NULL`, `NULL`},
+		{`'fat:2 cat:1 fat:1'::TSVECTOR`, `e'\'cat\':1 \'fat\':1,2'`},
+		{`'fat & (rat|cat)'::TSQUERY`, `e'\'fat\' & ( \'rat\' | \'cat\' )'`},
+		{`'b a'::TSVECTOR = 'a b'::TSVECTOR`, `true`},
 		// IS expressions.
 		{`0 IS NULL`, `false`},
 		{`0 IS NOT NULL`, `true`},
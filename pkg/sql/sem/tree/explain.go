@@ -54,10 +54,12 @@ func (node *Explain) Format(ctx *FmtCtx) {
 				optsBuffer.WriteString(upperCaseOpt)
 			}
 		}
-		// Write the options.
-		ctx.WriteByte('(')
-		ctx.Write(optsBuffer.Bytes())
-		ctx.WriteString(") ")
+		// Write the options, if there are any besides ANALYZE.
+		if optsBuffer.Len() > 0 {
+			ctx.WriteByte('(')
+			ctx.Write(optsBuffer.Bytes())
+			ctx.WriteString(") ")
+		}
 	}
 	ctx.FormatNode(node.Statement)
 }
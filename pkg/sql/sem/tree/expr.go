@@ -311,6 +311,9 @@ const (
 	IsNotDistinctFrom
 	Contains
 	ContainedBy
+	Overlaps
+	TrigramSimilar
+	TSMatches
 	JSONExists
 	JSONSomeExists
 	JSONAllExists
@@ -358,6 +361,9 @@ var comparisonOpName = [...]string{
 	IsNotDistinctFrom: "IS NOT DISTINCT FROM",
 	Contains:          "@>",
 	ContainedBy:       "<@",
+	Overlaps:          "&&",
+	TrigramSimilar:    "%",
+	TSMatches:         "@@",
 	JSONExists:        "?",
 	JSONSomeExists:    "?|",
 	JSONAllExists:     "?&",
@@ -1411,7 +1417,8 @@ var (
 		types.Timestamp, types.TimestampTZ, types.Date, types.Interval}
 	stringCastTypes = []types.T{types.Unknown, types.Bool, types.Int, types.Float, types.Decimal, types.String, types.FamCollatedString,
 		types.FamArray, types.FamTuple,
-		types.Bytes, types.Timestamp, types.TimestampTZ, types.Interval, types.UUID, types.Date, types.Time, types.TimeTZ, types.Oid, types.INet, types.JSON}
+		types.Bytes, types.Timestamp, types.TimestampTZ, types.Interval, types.UUID, types.Date, types.Time, types.TimeTZ, types.Oid, types.INet, types.JSON,
+		types.TSVector, types.TSQuery}
 	bytesCastTypes     = []types.T{types.Unknown, types.String, types.FamCollatedString, types.Bytes, types.UUID}
 	dateCastTypes      = []types.T{types.Unknown, types.String, types.FamCollatedString, types.Date, types.Timestamp, types.TimestampTZ, types.Int}
 	timeCastTypes      = []types.T{types.Unknown, types.String, types.FamCollatedString, types.Time, types.TimeTZ, types.Timestamp, types.TimestampTZ, types.Interval}
@@ -1423,6 +1430,8 @@ var (
 	inetCastTypes      = []types.T{types.Unknown, types.String, types.FamCollatedString, types.INet}
 	arrayCastTypes     = []types.T{types.Unknown, types.String}
 	jsonCastTypes      = []types.T{types.Unknown, types.String, types.JSON}
+	tsvectorCastTypes  = []types.T{types.Unknown, types.String, types.TSVector}
+	tsqueryCastTypes   = []types.T{types.Unknown, types.String, types.TSQuery}
 )
 
 // validCastTypes returns a set of types that can be cast into the provided type.
@@ -1452,6 +1461,10 @@ func validCastTypes(t types.T) []types.T {
 		return intervalCastTypes
 	case types.JSON:
 		return jsonCastTypes
+	case types.TSVector:
+		return tsvectorCastTypes
+	case types.TSQuery:
+		return tsqueryCastTypes
 	case types.UUID:
 		return uuidCastTypes
 	case types.INet:
@@ -1624,6 +1637,8 @@ func (node *DFloat) String() string           { return AsString(node) }
 func (node *DInt) String() string             { return AsString(node) }
 func (node *DInterval) String() string        { return AsString(node) }
 func (node *DJSON) String() string            { return AsString(node) }
+func (node *DTSVector) String() string        { return AsString(node) }
+func (node *DTSQuery) String() string         { return AsString(node) }
 func (node *DUuid) String() string            { return AsString(node) }
 func (node *DIPAddr) String() string          { return AsString(node) }
 func (node *DString) String() string          { return AsString(node) }
@@ -1653,6 +1668,7 @@ func (node *RangeCond) String() string        { return AsString(node) }
 func (node *StrVal) String() string           { return AsString(node) }
 func (node *Subquery) String() string         { return AsString(node) }
 func (node *Tuple) String() string            { return AsString(node) }
+func (node *GroupingSets) String() string     { return AsString(node) }
 func (node *TupleStar) String() string        { return AsString(node) }
 func (node *AnnotateTypeExpr) String() string { return AsString(node) }
 func (node *UnaryExpr) String() string        { return AsString(node) }
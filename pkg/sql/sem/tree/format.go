@@ -102,6 +102,11 @@ const (
 	// FmtParsableNumerics produces decimal and float representations that are
 	// always parsable, even if they require a string representation like -Inf.
 	FmtParsableNumerics
+
+	// FmtHideHints instructs the pretty-printer to omit join hints, so that
+	// statements that only differ in their hints are formatted identically.
+	// Inner joins are always printed as JOIN rather than INNER JOIN.
+	FmtHideHints
 )
 
 // Composite/derived flag definitions follow.
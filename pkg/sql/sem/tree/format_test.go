@@ -77,6 +77,14 @@ func TestFormatStatement(t *testing.T) {
 		{`SELECT 1+COALESCE(NULL, 'a', x)-ARRAY[3.14]`, tree.FmtHideConstants,
 			`SELECT (_ + COALESCE(_, _, x)) - ARRAY[_]`},
 
+		{`SELECT * FROM a INNER HASH JOIN b ON a.x = b.x`, tree.FmtHideHints,
+			`SELECT * FROM a JOIN b ON a.x = b.x`},
+		{`SELECT * FROM a LEFT LOOKUP JOIN b USING (x) WHERE y = 1`,
+			tree.FmtHideConstants | tree.FmtHideHints,
+			`SELECT * FROM a LEFT JOIN b USING (x) WHERE y = _`},
+		{`SELECT * FROM a INNER JOIN b ON true`, tree.FmtHideHints,
+			`SELECT * FROM a JOIN b ON true`},
+
 		// This here checks encodeSQLString on non-tree.DString strings also
 		// calls encodeSQLString with the right formatter.
 		// See TestFormatExprs below for the test on DStrings.
@@ -15,7 +15,7 @@
 package tree
 
 // FunctionDefinition implements a reference to the (possibly several)
-// overloads for a built-in function.
+// overloads for a built-in or user-defined function.
 type FunctionDefinition struct {
 	// Name is the short name of the function.
 	Name string
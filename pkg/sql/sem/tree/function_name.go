@@ -47,6 +47,18 @@ func (fn *ResolvableFunctionReference) String() string { return AsString(fn) }
 // resolves it as necessary.
 func (fn *ResolvableFunctionReference) Resolve(
 	searchPath sessiondata.SearchPath,
+) (*FunctionDefinition, error) {
+	return fn.ResolveWithFunctions(searchPath, nil /* functions */)
+}
+
+// ResolveWithFunctions is like Resolve, but also consults the given
+// FunctionResolver, if any, for names that do not designate a builtin.
+//
+// Unlike builtins, user-defined functions are not memoized in the
+// reference: they can be replaced or dropped, so their definition is
+// looked up again every time the reference is resolved.
+func (fn *ResolvableFunctionReference) ResolveWithFunctions(
+	searchPath sessiondata.SearchPath, functions FunctionResolver,
 ) (*FunctionDefinition, error) {
 	switch t := fn.FunctionReference.(type) {
 	case *FunctionDefinition:
@@ -54,7 +66,18 @@ func (fn *ResolvableFunctionReference) Resolve(
 	case *UnresolvedName:
 		fd, err := t.ResolveFunction(searchPath)
 		if err != nil {
-			return nil, err
+			if pgErr, ok := pgerror.GetPGCause(err); functions == nil || !ok ||
+				pgErr.Code != pgerror.CodeUndefinedFunctionError {
+				return nil, err
+			}
+			udf, udfErr := functions.ResolveFunction(t)
+			if udfErr != nil {
+				return nil, udfErr
+			}
+			if udf == nil {
+				return nil, err
+			}
+			return udf, nil
 		}
 		fn.FunctionReference = fd
 		return fd, nil
@@ -66,6 +89,13 @@ func (fn *ResolvableFunctionReference) Resolve(
 	}
 }
 
+// FunctionResolver resolves the names of user-defined functions.
+type FunctionResolver interface {
+	// ResolveFunction returns the definition of the user-defined function
+	// designated by the given name, or nil if there is no such function.
+	ResolveFunction(name *UnresolvedName) (*FunctionDefinition, error)
+}
+
 // WrapFunction creates a new ResolvableFunctionReference
 // holding a pre-resolved function. Helper for grammar rules.
 func WrapFunction(n string) ResolvableFunctionReference {
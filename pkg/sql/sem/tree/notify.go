@@ -0,0 +1,66 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "github.com/cockroachdb/cockroach/pkg/sql/lex"
+
+// Listen represents a LISTEN statement.
+type Listen struct {
+	Channel Name
+}
+
+var _ Statement = &Listen{}
+
+// Format implements the NodeFormatter interface.
+func (node *Listen) Format(ctx *FmtCtx) {
+	ctx.WriteString("LISTEN ")
+	ctx.FormatNode(&node.Channel)
+}
+
+// Unlisten represents an UNLISTEN statement.
+type Unlisten struct {
+	// Channel is empty for UNLISTEN *.
+	Channel Name
+}
+
+var _ Statement = &Unlisten{}
+
+// Format implements the NodeFormatter interface.
+func (node *Unlisten) Format(ctx *FmtCtx) {
+	ctx.WriteString("UNLISTEN ")
+	if node.Channel == "" {
+		ctx.WriteByte('*')
+		return
+	}
+	ctx.FormatNode(&node.Channel)
+}
+
+// Notify represents a NOTIFY statement.
+type Notify struct {
+	Channel Name
+	Payload string
+}
+
+var _ Statement = &Notify{}
+
+// Format implements the NodeFormatter interface.
+func (node *Notify) Format(ctx *FmtCtx) {
+	ctx.WriteString("NOTIFY ")
+	ctx.FormatNode(&node.Channel)
+	if node.Payload != "" {
+		ctx.WriteString(", ")
+		lex.EncodeSQLStringWithFlags(ctx.Buffer, node.Payload, ctx.flags.EncodeFlags())
+	}
+}
@@ -51,6 +51,12 @@ type Overload struct {
 	WindowFunc    func([]types.T, *EvalContext) WindowFunc
 	Fn            func(*EvalContext, Datums) (Datum, error)
 	Generator     GeneratorFactory
+
+	// InlineBody, if set, is the body of an immutable user-defined SQL
+	// function that consists of a single scalar expression. It refers to the
+	// arguments through the placeholders $1 to $n, and is used by the
+	// optimizer to inline calls to the function.
+	InlineBody Expr
 }
 
 // params implements the overloadImpl interface.
@@ -91,6 +91,10 @@ func parseStringAs(t types.T, s string, ctx locationContext) (Datum, error) {
 		return ParseDInterval(s)
 	case types.JSON:
 		return ParseDJSON(s)
+	case types.TSQuery:
+		return ParseDTSQuery(s)
+	case types.TSVector:
+		return ParseDTSVector(s)
 	case types.String:
 		return NewDString(s), nil
 	case types.Time:
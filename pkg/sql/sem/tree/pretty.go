@@ -608,6 +608,9 @@ func (node *AliasedTableExpr) doc(p *PrettyCfg) pretty.Doc {
 			),
 		)
 	}
+	if node.Sample != nil {
+		d = p.nestUnder(d, p.Doc(node.Sample))
+	}
 	return d
 }
 
@@ -747,14 +750,14 @@ func (node *JoinTableExpr) doc(p *PrettyCfg) pretty.Doc {
 		// Natural joins have a different syntax: "<a> NATURAL <join_type> <b>"
 		d = append(d,
 			p.nestUnder(
-				pretty.ConcatSpace(p.Doc(node.Cond), pretty.Text(node.Join)),
+				pretty.ConcatSpace(p.Doc(node.Cond), pretty.Text(node.joinKeywords(true /* withHint */))),
 				p.Doc(node.Right)),
 		)
 	} else {
 		// General syntax: "<a> <join_type> <b> <condition>"
 		operand := []pretty.Doc{
 			p.nestUnder(
-				pretty.Text(node.Join),
+				pretty.Text(node.joinKeywords(true /* withHint */)),
 				p.Doc(node.Right)),
 		}
 		if node.Cond != nil {
@@ -1017,6 +1020,9 @@ func (node *UpdateExpr) doc(p *PrettyCfg) pretty.Doc {
 
 func (node *CreateTable) doc(p *PrettyCfg) pretty.Doc {
 	title := "CREATE TABLE "
+	if node.Temporary {
+		title = "CREATE TEMPORARY TABLE "
+	}
 	if node.IfNotExists {
 		title += "IF NOT EXISTS "
 	}
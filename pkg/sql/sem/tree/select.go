@@ -26,6 +26,9 @@ package tree
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 )
 
 // SelectStatement represents any SELECT statement.
@@ -333,6 +336,7 @@ type AliasedTableExpr struct {
 	IndexFlags *IndexFlags
 	Ordinality bool
 	As         AliasClause
+	Sample     *TableSample
 }
 
 // Format implements the NodeFormatter interface.
@@ -348,6 +352,66 @@ func (node *AliasedTableExpr) Format(ctx *FmtCtx) {
 		ctx.WriteString(" AS ")
 		ctx.FormatNode(&node.As)
 	}
+	if node.Sample != nil {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(node.Sample)
+	}
+}
+
+// TableSampleMethod is the sampling method of a TABLESAMPLE clause.
+type TableSampleMethod int
+
+// TableSampleMethod values.
+const (
+	// TableSampleBernoulli selects each row independently with the given
+	// probability.
+	TableSampleBernoulli TableSampleMethod = iota
+	// TableSampleSystem selects whole blocks of rows (ranges) with the given
+	// probability.
+	TableSampleSystem
+)
+
+var tableSampleMethodName = [...]string{
+	TableSampleBernoulli: "BERNOULLI",
+	TableSampleSystem:    "SYSTEM",
+}
+
+func (m TableSampleMethod) String() string {
+	return tableSampleMethodName[m]
+}
+
+// TableSampleMethodFromString returns the TableSampleMethod with the given
+// (case-insensitive) name.
+func TableSampleMethodFromString(name string) (TableSampleMethod, bool) {
+	for m, n := range tableSampleMethodName {
+		if strings.EqualFold(name, n) {
+			return TableSampleMethod(m), true
+		}
+	}
+	return 0, false
+}
+
+// TableSample represents a
+// "TABLESAMPLE <method> (<percent>) [REPEATABLE (<seed>)]" clause.
+type TableSample struct {
+	Method  TableSampleMethod
+	Percent Expr
+	// Seed is nil if REPEATABLE was not specified.
+	Seed Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TableSample) Format(ctx *FmtCtx) {
+	ctx.WriteString("TABLESAMPLE ")
+	ctx.WriteString(node.Method.String())
+	ctx.WriteString(" (")
+	ctx.FormatNode(node.Percent)
+	ctx.WriteByte(')')
+	if node.Seed != nil {
+		ctx.WriteString(" REPEATABLE (")
+		ctx.FormatNode(node.Seed)
+		ctx.WriteByte(')')
+	}
 }
 
 // ParenTableExpr represents a parenthesized TableExpr.
@@ -373,6 +437,7 @@ func StripTableParens(expr TableExpr) TableExpr {
 // JoinTableExpr represents a TableExpr that's a JOIN operation.
 type JoinTableExpr struct {
 	Join  string
+	Hint  string
 	Left  TableExpr
 	Right TableExpr
 	Cond  JoinCond
@@ -388,6 +453,36 @@ const (
 	AstInnerJoin = "INNER JOIN"
 )
 
+// JoinTableExpr.Hint
+const (
+	AstHash   = "HASH"
+	AstMerge  = "MERGE"
+	AstLookup = "LOOKUP"
+)
+
+// joinKeywords returns the keywords for the join type, including the join
+// hint if there is one and withHint is set.
+func (node *JoinTableExpr) joinKeywords(withHint bool) string {
+	if !withHint {
+		// A hint requires an explicit join type, so INNER JOIN is printed as
+		// JOIN for a hinted statement to be formatted like the same statement
+		// without hints.
+		if node.Join == AstInnerJoin {
+			return AstJoin
+		}
+		return node.Join
+	}
+	if node.Hint == "" {
+		return node.Join
+	}
+	// A hint requires an explicit join type: "<join_type> <hint> JOIN".
+	joinType := strings.TrimSuffix(node.Join, " JOIN")
+	if node.Join == AstJoin {
+		joinType = "INNER"
+	}
+	return joinType + " " + node.Hint + " JOIN"
+}
+
 // Format implements the NodeFormatter interface.
 func (node *JoinTableExpr) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.Left)
@@ -396,12 +491,12 @@ func (node *JoinTableExpr) Format(ctx *FmtCtx) {
 		// Natural joins have a different syntax: "<a> NATURAL <join_type> <b>"
 		ctx.FormatNode(node.Cond)
 		ctx.WriteByte(' ')
-		ctx.WriteString(node.Join)
+		ctx.WriteString(node.joinKeywords(!ctx.flags.HasFlags(FmtHideHints)))
 		ctx.WriteByte(' ')
 		ctx.FormatNode(node.Right)
 	} else {
 		// General syntax: "<a> <join_type> <b> <condition>"
-		ctx.WriteString(node.Join)
+		ctx.WriteString(node.joinKeywords(!ctx.flags.HasFlags(FmtHideHints)))
 		ctx.WriteByte(' ')
 		ctx.FormatNode(node.Right)
 		if node.Cond != nil {
@@ -493,6 +588,156 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetsType is the kind of a GroupingSets item.
+type GroupingSetsType int8
+
+// GroupingSetsType values.
+const (
+	// GroupingSetsList is GROUPING SETS (...).
+	GroupingSetsList GroupingSetsType = iota
+	// Rollup is ROLLUP (...).
+	Rollup
+	// Cube is CUBE (...).
+	Cube
+)
+
+// GroupingSets represents a GROUPING SETS, ROLLUP or CUBE item of a GROUP BY
+// clause. The elements of a ROLLUP or CUBE item are expressions, or
+// parenthesized lists of expressions that are grouped by together. The
+// elements of a GROUPING SETS item can also be GroupingSets items, and an
+// empty Tuple denotes the empty grouping set.
+type GroupingSets struct {
+	Type  GroupingSetsType
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSets) Format(ctx *FmtCtx) {
+	switch node.Type {
+	case GroupingSetsList:
+		ctx.WriteString("GROUPING SETS (")
+	case Rollup:
+		ctx.WriteString("ROLLUP (")
+	case Cube:
+		ctx.WriteString("CUBE (")
+	}
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
+// MaxGroupingSets is the maximum number of grouping sets of a GROUP BY
+// clause.
+const MaxGroupingSets = 4096
+
+// maxCubeElements is the maximum number of elements of a CUBE item, which
+// expands to 2^n grouping sets.
+const maxCubeElements = 12
+
+// HasGroupingSets returns whether the GROUP BY clause uses GROUPING SETS,
+// ROLLUP, CUBE or the empty grouping set.
+func (node GroupBy) HasGroupingSets() bool {
+	for _, e := range node {
+		switch t := StripParens(e).(type) {
+		case *GroupingSets:
+			return true
+		case *Tuple:
+			if len(t.Exprs) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ExpandGroupingSets returns the grouping sets of a GROUP BY clause. The
+// grouping sets of the items of the clause are combined by taking their
+// cartesian product; for example GROUP BY a, ROLLUP (b, c) expands to the
+// grouping sets (a, b, c), (a, b) and (a). Parenthesized lists of
+// expressions are unpacked.
+func (node GroupBy) ExpandGroupingSets() ([]Exprs, error) {
+	sets := []Exprs{nil}
+	for _, e := range node {
+		itemSets, err := expandGroupingItem(e)
+		if err != nil {
+			return nil, err
+		}
+		if len(sets)*len(itemSets) > MaxGroupingSets {
+			return nil, errTooManyGroupingSets
+		}
+		product := make([]Exprs, 0, len(sets)*len(itemSets))
+		for _, s := range sets {
+			for _, is := range itemSets {
+				set := make(Exprs, 0, len(s)+len(is))
+				set = append(append(set, s...), is...)
+				product = append(product, set)
+			}
+		}
+		sets = product
+	}
+	return sets, nil
+}
+
+var errTooManyGroupingSets = pgerror.NewErrorf(pgerror.CodeStatementTooComplexError,
+	"too many grouping sets present (maximum %d)", MaxGroupingSets)
+
+// expandGroupingItem returns the grouping sets of one item of a GROUP BY
+// clause.
+func expandGroupingItem(e Expr) ([]Exprs, error) {
+	t, ok := StripParens(e).(*GroupingSets)
+	if !ok {
+		return []Exprs{unpackGroupingElement(e)}, nil
+	}
+	var sets []Exprs
+	switch t.Type {
+	case GroupingSetsList:
+		for _, item := range t.Exprs {
+			itemSets, err := expandGroupingItem(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(sets)+len(itemSets) > MaxGroupingSets {
+				return nil, errTooManyGroupingSets
+			}
+			sets = append(sets, itemSets...)
+		}
+	case Rollup:
+		// ROLLUP (a, b) is (a, b), (a), ().
+		for i := len(t.Exprs); i >= 0; i-- {
+			var set Exprs
+			for _, elem := range t.Exprs[:i] {
+				set = append(set, unpackGroupingElement(elem)...)
+			}
+			sets = append(sets, set)
+		}
+	case Cube:
+		// CUBE (a, b) is (a, b), (a), (b), ().
+		n := len(t.Exprs)
+		if n > maxCubeElements {
+			return nil, pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+				"CUBE is limited to %d elements", maxCubeElements)
+		}
+		for mask := 1<<uint(n) - 1; mask >= 0; mask-- {
+			var set Exprs
+			for i, elem := range t.Exprs {
+				if mask&(1<<uint(n-1-i)) != 0 {
+					set = append(set, unpackGroupingElement(elem)...)
+				}
+			}
+			sets = append(sets, set)
+		}
+	}
+	return sets, nil
+}
+
+// unpackGroupingElement returns the expressions of a parenthesized list of
+// expressions, or the given expression otherwise.
+func unpackGroupingElement(e Expr) Exprs {
+	if t, ok := StripParens(e).(*Tuple); ok && len(t.Labels) == 0 {
+		return t.Exprs
+	}
+	return Exprs{e}
+}
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
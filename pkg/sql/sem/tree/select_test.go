@@ -0,0 +1,66 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestExpandGroupingSets(t *testing.T) {
+	testCases := []struct {
+		groupBy  string
+		expected string
+		err      string
+	}{
+		{`a, b`, `(a, b)`, ``},
+		{`()`, `()`, ``},
+		{`ROLLUP (a, b)`, `(a, b), (a), ()`, ``},
+		{`ROLLUP ((a, b), c)`, `(a, b, c), (a, b), ()`, ``},
+		{`CUBE (a, b)`, `(a, b), (a), (b), ()`, ``},
+		{`GROUPING SETS (a, (b, c), ())`, `(a), (b, c), ()`, ``},
+		{`GROUPING SETS (a, ROLLUP (b))`, `(a), (b), ()`, ``},
+		{`a, ROLLUP (b), CUBE (c)`, `(a, b, c), (a, b), (a, c), (a)`, ``},
+		{`CUBE (a, b, c, d, e, f, g, h, i, j, k, l, m)`, ``, `CUBE is limited to 12 elements`},
+		{`CUBE (a, b, c, d, e, f, g, h, i, j, k, l), ROLLUP (a, b)`, ``, `too many grouping sets`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.groupBy, func(t *testing.T) {
+			stmt, err := parser.ParseOne("SELECT 1 FROM t GROUP BY " + tc.groupBy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			groupBy := stmt.(*tree.Select).Select.(*tree.SelectClause).GroupBy
+			sets, err := groupBy.ExpandGroupingSets()
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			strs := make([]string, len(sets))
+			for i, set := range sets {
+				strs[i] = "(" + set.String() + ")"
+			}
+			if actual := strings.Join(strs, ", "); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -78,6 +78,12 @@ func SampleDatum(t types.T) Datum {
 	case types.JSON:
 		j, _ := ParseDJSON(`{"a": "b"}`)
 		return j
+	case types.TSVector:
+		v, _ := ParseDTSVector(`fat:2 rat:3A`)
+		return v
+	case types.TSQuery:
+		q, _ := ParseDTSQuery(`fat & rat:*`)
+		return q
 	case types.Oid:
 		return NewDOid(DInt(1009))
 	default:
@@ -177,19 +177,18 @@ func (node *RollbackTransaction) Format(ctx *FmtCtx) {
 	ctx.WriteString("ROLLBACK TRANSACTION")
 }
 
-// RestartSavepointName is the only savepoint name that we accept, modulo
-// capitalization.
+// RestartSavepointName is the name of the savepoint used for client-side
+// transaction retries, modulo capitalization. Savepoints with this name don't
+// mark a point in the transaction that can be rolled back to; rolling back to
+// them restarts the transaction instead.
 const RestartSavepointName string = "COCKROACH_RESTART"
 
-// ValidateRestartCheckpoint checks that a checkpoint name is our magic restart
-// value.
+// IsRestartSavepointName returns true if a savepoint name refers to our magic
+// restart savepoint.
 // We accept everything with the desired prefix because at least the C++ libpqxx
 // appends sequence numbers to the savepoint name specified by the user.
-func ValidateRestartCheckpoint(savepoint string) error {
-	if !strings.HasPrefix(strings.ToUpper(savepoint), RestartSavepointName) {
-		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError, "SAVEPOINT not supported except for %s", RestartSavepointName)
-	}
-	return nil
+func IsRestartSavepointName(savepoint string) bool {
+	return strings.HasPrefix(strings.ToUpper(savepoint), RestartSavepointName)
 }
 
 // Savepoint represents a SAVEPOINT <name> statement.
@@ -47,6 +47,10 @@ type SemaContext struct {
 	// already.
 	SearchPath sessiondata.SearchPath
 
+	// FunctionResolver, if set, is used to resolve the names of
+	// user-defined functions that do not designate a builtin.
+	FunctionResolver FunctionResolver
+
 	// privileged, if true, enables "unsafe" builtins, e.g. those
 	// from the crdb_internal namespace. Must be set only for
 	// the root user.
@@ -328,6 +332,11 @@ func (expr *BinaryExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr,
 	}
 
 	binOp := fns[0].(BinOp)
+	if expr.Operator == Mod && binOp.ReturnType == types.Bool {
+		// The % operator on strings is a comparison: the trigram similarity
+		// test. Turn it into one so that it can constrain trigram indexes.
+		return NewTypedComparisonExpr(TrigramSimilar, leftTyped, rightTyped), nil
+	}
 	expr.Left, expr.Right = leftTyped, rightTyped
 	expr.fn = binOp
 	expr.typ = binOp.returnType()(typedSubExprs)
@@ -724,13 +733,20 @@ func (sc *SemaContext) checkFunctionUsage(expr *FuncExpr, def *FunctionDefinitio
 	return nil
 }
 
+// ResolveFunction resolves the given function reference using the search
+// path and the function resolver of the SemaContext, if any.
+func (sc *SemaContext) ResolveFunction(
+	fn *ResolvableFunctionReference,
+) (*FunctionDefinition, error) {
+	if sc == nil {
+		return fn.Resolve(sessiondata.SearchPath{})
+	}
+	return fn.ResolveWithFunctions(sc.SearchPath, sc.FunctionResolver)
+}
+
 // TypeCheck implements the Expr interface.
 func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, error) {
-	var searchPath sessiondata.SearchPath
-	if ctx != nil {
-		searchPath = ctx.SearchPath
-	}
-	def, err := expr.Func.Resolve(searchPath)
+	def, err := ctx.ResolveFunction(&expr.Func)
 	if err != nil {
 		return nil, err
 	}
@@ -794,6 +810,10 @@ func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, e
 		// function or of a builtin aggregate function.
 		switch def.Class {
 		case AggregateClass:
+			if overloadImpl.WindowFunc == nil {
+				return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+					"OVER specified, but %s() cannot be used as a window function", &expr.Func)
+			}
 		case WindowClass:
 		default:
 			return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
@@ -1121,6 +1141,14 @@ func (expr DefaultVal) TypeCheck(_ *SemaContext, desired types.T) (TypedExpr, er
 	return nil, errInvalidDefaultUsage
 }
 
+var errInvalidGroupingSetsUsage = pgerror.NewError(pgerror.CodeSyntaxError,
+	"GROUPING SETS, ROLLUP and CUBE can only appear in GROUP BY")
+
+// TypeCheck implements the Expr interface.
+func (expr *GroupingSets) TypeCheck(_ *SemaContext, desired types.T) (TypedExpr, error) {
+	return nil, errInvalidGroupingSetsUsage
+}
+
 // TypeCheck implements the Expr interface.
 func (expr MinVal) TypeCheck(_ *SemaContext, desired types.T) (TypedExpr, error) {
 	return nil, errInvalidMinUsage
@@ -1333,6 +1361,14 @@ func (d *DInterval) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { re
 // identity function for Datum.
 func (d *DJSON) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTSVector) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
+
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTSQuery) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DTuple) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
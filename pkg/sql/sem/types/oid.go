@@ -109,6 +109,8 @@ var OidToType = map[oid.Oid]T{
 	oid.T__timestamp:   TArray{Timestamp},
 	oid.T_timestamptz:  TimestampTZ,
 	oid.T__timestamptz: TArray{TimestampTZ},
+	oid.T_tsquery:      TSQuery,
+	oid.T_tsvector:     TSVector,
 	oid.T_uuid:         UUID,
 	oid.T__uuid:        TArray{UUID},
 	oid.T_inet:         INet,
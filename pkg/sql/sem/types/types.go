@@ -79,6 +79,10 @@ var (
 	UUID T = tUUID{}
 	// INet is the type of a DIPAddr. Can be compared with ==.
 	INet T = tINet{}
+	// TSVector is the type of a DTSVector. Can be compared with ==.
+	TSVector T = tTSVector{}
+	// TSQuery is the type of a DTSQuery. Can be compared with ==.
+	TSQuery T = tTSQuery{}
 	// AnyArray is the type of a DArray with a wildcard parameterized type.
 	// Can be compared with ==.
 	AnyArray T = TArray{Any}
@@ -102,6 +106,8 @@ var (
 		UUID,
 		INet,
 		JSON,
+		TSVector,
+		TSQuery,
 		Oid,
 	}
 
@@ -317,6 +323,30 @@ func (tINet) Oid() oid.Oid             { return oid.T_inet }
 func (tINet) SQLName() string          { return "inet" }
 func (tINet) IsAmbiguous() bool        { return false }
 
+type tTSVector struct{}
+
+func (tTSVector) String() string { return "tsvector" }
+func (tTSVector) Equivalent(other T) bool {
+	return UnwrapType(other) == TSVector || other == Any
+}
+
+func (tTSVector) FamilyEqual(other T) bool { return UnwrapType(other) == TSVector }
+func (tTSVector) Oid() oid.Oid             { return oid.T_tsvector }
+func (tTSVector) SQLName() string          { return "tsvector" }
+func (tTSVector) IsAmbiguous() bool        { return false }
+
+type tTSQuery struct{}
+
+func (tTSQuery) String() string { return "tsquery" }
+func (tTSQuery) Equivalent(other T) bool {
+	return UnwrapType(other) == TSQuery || other == Any
+}
+
+func (tTSQuery) FamilyEqual(other T) bool { return UnwrapType(other) == TSQuery }
+func (tTSQuery) Oid() oid.Oid             { return oid.T_tsquery }
+func (tTSQuery) SQLName() string          { return "tsquery" }
+func (tTSQuery) IsAmbiguous() bool        { return false }
+
 // TTuple is the type of a DTuple.
 type TTuple struct {
 	Types  []T
@@ -502,7 +532,7 @@ func IsStringType(t T) bool {
 // can be used in TArray.
 func IsValidArrayElementType(t T) bool {
 	switch t {
-	case JSON:
+	case JSON, TSVector, TSQuery:
 		return false
 	default:
 		return true
@@ -28,28 +28,62 @@ const DefaultDatabaseName = "defaultdb"
 // PgCatalogName is the name of the pg_catalog system schema.
 const PgCatalogName = "pg_catalog"
 
+// PgTempSchemaName is the alias for the current session's temporary schema.
+const PgTempSchemaName = "pg_temp"
+
 // SearchPath represents a list of namespaces to search builtins in.
 // The names must be normalized (as per Name.Normalize) already.
 type SearchPath struct {
 	paths             []string
 	containsPgCatalog bool
+	containsPgTemp    bool
+	// tempSchemaName is the name of the session's temporary schema, if
+	// any. It is searched before all other schemas unless pg_temp appears
+	// explicitly in the path.
+	tempSchemaName string
 }
 
 // MakeSearchPath returns a new SearchPath struct.
 func MakeSearchPath(paths []string) SearchPath {
 	containsPgCatalog := false
+	containsPgTemp := false
 	for _, e := range paths {
-		if e == PgCatalogName {
+		switch e {
+		case PgCatalogName:
 			containsPgCatalog = true
-			break
+		case PgTempSchemaName:
+			containsPgTemp = true
 		}
 	}
 	return SearchPath{
 		paths:             paths,
 		containsPgCatalog: containsPgCatalog,
+		containsPgTemp:    containsPgTemp,
 	}
 }
 
+// WithTemporarySchemaName returns a copy of the search path that also
+// searches the given temporary schema.
+func (s SearchPath) WithTemporarySchemaName(tempSchemaName string) SearchPath {
+	s.tempSchemaName = tempSchemaName
+	return s
+}
+
+// GetTemporarySchemaName returns the name of the temporary schema searched
+// by this search path, or the empty string if there is none.
+func (s SearchPath) GetTemporarySchemaName() string {
+	return s.tempSchemaName
+}
+
+// maybeResolveTemporarySchema replaces the pg_temp alias with the name of
+// the temporary schema, if known.
+func (s SearchPath) maybeResolveTemporarySchema(scName string) string {
+	if scName == PgTempSchemaName && s.tempSchemaName != "" {
+		return s.tempSchemaName
+	}
+	return scName
+}
+
 // Iter returns an iterator through the search path. We must include the
 // implicit pg_catalog at the beginning of the search path, unless it has been
 // explicitly set later by the user.
@@ -58,31 +92,38 @@ func MakeSearchPath(paths []string) SearchPath {
 // searched in the specified order. If pg_catalog is not in the path then it
 // will be searched before searching any of the path items."
 // - https://www.postgresql.org/docs/9.1/static/runtime-config-client.html
+//
+// Likewise, the session's temporary schema, if any, is searched first
+// unless pg_temp is mentioned explicitly in the path.
 func (s SearchPath) Iter() func() (next string, ok bool) {
-	i := -1
-	if s.containsPgCatalog {
-		i = 0
-	}
+	implicitPgTemp := s.tempSchemaName != "" && !s.containsPgTemp
+	implicitPgCatalog := !s.containsPgCatalog
+	i := 0
 	return func() (next string, ok bool) {
-		if i == -1 {
-			i++
+		if implicitPgTemp {
+			implicitPgTemp = false
+			return s.tempSchemaName, true
+		}
+		if implicitPgCatalog {
+			implicitPgCatalog = false
 			return PgCatalogName, true
 		}
 		if i < len(s.paths) {
 			i++
-			return s.paths[i-1], true
+			return s.maybeResolveTemporarySchema(s.paths[i-1]), true
 		}
 		return "", false
 	}
 }
 
-// IterWithoutImplicitPGCatalog is the same as Iter, but does not include the implicit pg_catalog.
+// IterWithoutImplicitPGCatalog is the same as Iter, but does not include the
+// implicit pg_catalog nor the implicit temporary schema.
 func (s SearchPath) IterWithoutImplicitPGCatalog() func() (next string, ok bool) {
 	i := 0
 	return func() (next string, ok bool) {
 		if i < len(s.paths) {
 			i++
-			return s.paths[i-1], true
+			return s.maybeResolveTemporarySchema(s.paths[i-1]), true
 		}
 		return "", false
 	}
@@ -58,3 +58,44 @@ func TestImpliedSearchPath(t *testing.T) {
 		})
 	}
 }
+
+func TestImpliedSearchPathWithTemporarySchema(t *testing.T) {
+	const tempSchema = "pg_temp_1_2"
+	testCases := []struct {
+		explicitSearchPath                         []string
+		expectedSearchPath                         []string
+		expectedSearchPathWithoutImplicitPgCatalog []string
+	}{
+		{[]string{}, []string{tempSchema, `pg_catalog`}, []string{}},
+		{[]string{`foobar`}, []string{tempSchema, `pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`foobar`, `pg_catalog`}, []string{tempSchema, `foobar`, `pg_catalog`}, []string{`foobar`, `pg_catalog`}},
+		{[]string{`foobar`, `pg_temp`}, []string{`pg_catalog`, `foobar`, tempSchema}, []string{`foobar`, tempSchema}},
+		{[]string{`pg_catalog`, `pg_temp`, `foobar`}, []string{`pg_catalog`, tempSchema, `foobar`}, []string{`pg_catalog`, tempSchema, `foobar`}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.explicitSearchPath, ","), func(t *testing.T) {
+			searchPath := MakeSearchPath(tc.explicitSearchPath).WithTemporarySchemaName(tempSchema)
+			actualSearchPath := make([]string, 0)
+			iter := searchPath.Iter()
+			for p, ok := iter(); ok; p, ok = iter() {
+				actualSearchPath = append(actualSearchPath, p)
+			}
+			if !reflect.DeepEqual(tc.expectedSearchPath, actualSearchPath) {
+				t.Errorf(`Expected search path to be %#v, but was %#v.`, tc.expectedSearchPath, actualSearchPath)
+			}
+		})
+
+		t.Run(strings.Join(tc.explicitSearchPath, ",")+"/no-pg-catalog", func(t *testing.T) {
+			searchPath := MakeSearchPath(tc.explicitSearchPath).WithTemporarySchemaName(tempSchema)
+			actualSearchPath := make([]string, 0)
+			iter := searchPath.IterWithoutImplicitPGCatalog()
+			for p, ok := iter(); ok; p, ok = iter() {
+				actualSearchPath = append(actualSearchPath, p)
+			}
+			if !reflect.DeepEqual(tc.expectedSearchPathWithoutImplicitPgCatalog, actualSearchPath) {
+				t.Errorf(`Expected search path to be %#v, but was %#v.`, tc.expectedSearchPathWithoutImplicitPgCatalog, actualSearchPath)
+			}
+		})
+	}
+}
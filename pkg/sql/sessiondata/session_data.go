@@ -49,6 +49,10 @@ type SessionData struct {
 	// OptimizerMode indicates whether to use the experimental optimizer for
 	// query planning.
 	OptimizerMode OptimizerMode
+	// ReorderJoinsLimit indicates the number of joins at or below which the
+	// optimizer should try to find a better join order. If set to 0, the joins
+	// are planned in the order in which they appear in the query.
+	ReorderJoinsLimit int
 	// SearchPath is a list of databases that will be searched for a table name
 	// before the database. Currently, this is used only for SELECTs.
 	// Names in the search path must have been normalized already.
@@ -19,6 +19,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/pkg/errors"
@@ -45,10 +46,18 @@ func (p *planner) showCreateView(
 	ctx context.Context, tn *tree.Name, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	f := tree.NewFmtCtxWithBuf(tree.FmtSimple)
-	f.WriteString("CREATE VIEW ")
+	f.WriteString("CREATE ")
+	if desc.IsMaterializedView {
+		f.WriteString("MATERIALIZED ")
+	}
+	f.WriteString("VIEW ")
 	f.FormatNode(tn)
 	f.WriteString(" (")
 	for i := range desc.Columns {
+		if desc.Columns[i].Hidden {
+			// Skip the hidden primary key of a materialized view.
+			continue
+		}
 		if i > 0 {
 			f.WriteString(", ")
 		}
@@ -120,6 +129,39 @@ func (p *planner) showCreateSequence(
 	return f.CloseAndGetString(), nil
 }
 
+// showCreateFunction returns a valid SQL representation of the
+// CREATE FUNCTION statement used to create the given function.
+func (p *planner) showCreateFunction(
+	ctx context.Context, tn *tree.Name, desc *sqlbase.TableDescriptor,
+) (string, error) {
+	f := tree.NewFmtCtxWithBuf(tree.FmtSimple)
+	f.WriteString("CREATE FUNCTION ")
+	f.FormatNode(tn)
+	f.WriteByte('(')
+	opts := desc.FunctionOpts
+	for i := range opts.Arguments {
+		arg := &opts.Arguments[i]
+		if i > 0 {
+			f.WriteString(", ")
+		}
+		if arg.Name != "" {
+			f.FormatNameP(&arg.Name)
+			f.WriteByte(' ')
+		}
+		f.WriteString(arg.Type.SQLString())
+	}
+	f.WriteString(") RETURNS ")
+	f.WriteString(opts.ReturnType.SQLString())
+	f.WriteString(" LANGUAGE sql ")
+	f.WriteString(opts.Volatility.String())
+	if opts.Strict {
+		f.WriteString(" STRICT")
+	}
+	f.WriteString(" AS ")
+	lex.EncodeSQLString(f.Buffer, opts.Body)
+	return f.CloseAndGetString(), nil
+}
+
 // showCreateTable returns a valid SQL representation of the CREATE
 // TABLE statement used to create the given table.
 //
@@ -139,7 +181,11 @@ func (p *planner) showCreateTable(
 	a := &sqlbase.DatumAlloc{}
 
 	f := tree.NewFmtCtxWithBuf(tree.FmtSimple)
-	f.WriteString("CREATE TABLE ")
+	f.WriteString("CREATE ")
+	if desc.IsTemporary() {
+		f.WriteString("TEMPORARY ")
+	}
+	f.WriteString("TABLE ")
 	f.FormatNode(tn)
 	f.WriteString(" (")
 	primaryKeyIsOnVisibleColumn := false
@@ -186,6 +232,10 @@ func (p *planner) showCreateTable(
 			); err != nil {
 				return "", err
 			}
+			if idx.IsPartial() {
+				f.WriteString(" WHERE ")
+				f.WriteString(idx.Predicate)
+			}
 		}
 	}
 
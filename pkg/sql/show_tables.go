@@ -40,6 +40,13 @@ func (p *planner) ShowTables(ctx context.Context, n *tree.ShowTables) (planNode,
 		return nil, sqlbase.NewInvalidWildcardError(tree.ErrString(&n.TableNamePrefix))
 	}
 
+	scName := n.Schema()
+	if p.Tables().temporarySchemas.isOwnSchema(scName) {
+		// information_schema lists temporary tables under the actual name of
+		// the session's temporary schema.
+		scName = p.Tables().temporarySchemas.name
+	}
+
 	const getTablesQuery = `
   SELECT table_name
     FROM %[1]s.information_schema.tables
@@ -47,6 +54,6 @@ func (p *planner) ShowTables(ctx context.Context, n *tree.ShowTables) (planNode,
 ORDER BY table_schema, table_name`
 
 	return p.delegateQuery(ctx, "SHOW TABLES",
-		fmt.Sprintf(getTablesQuery, &n.CatalogName, lex.EscapeSQLString(n.Schema())),
+		fmt.Sprintf(getTablesQuery, &n.CatalogName, lex.EscapeSQLString(scName)),
 		func(_ context.Context) error { return nil }, nil)
 }
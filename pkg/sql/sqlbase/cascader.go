@@ -364,6 +364,7 @@ func (c *cascader) addRowDeleter(table *TableDescriptor) (RowDeleter, RowFetcher
 		c.tablesByID,
 		nil, /* requestedCol */
 		CheckFKs,
+		c.evalCtx,
 		c.alloc,
 	)
 	if err != nil {
@@ -423,6 +424,7 @@ func (c *cascader) addRowUpdater(table *TableDescriptor) (RowUpdater, RowFetcher
 		table.Columns,
 		nil, /* requestedCol */
 		RowUpdaterDefault,
+		c.evalCtx,
 		c.alloc,
 	)
 	if err != nil {
@@ -791,7 +793,7 @@ func (c *cascader) updateRows(
 								if err != nil {
 									return nil, nil, nil, 0, err
 								}
-								if !column.Nullable {
+								if !column.Nullable || referencingTable.ValidatingNotNull(column.ID) {
 									database, err := GetDatabaseDescFromID(ctx, c.txn, referencingTable.ParentID)
 									if err != nil {
 										return nil, nil, nil, 0, err
@@ -0,0 +1,197 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+)
+
+// IsPartial returns true if the index only contains entries for the rows that
+// satisfy its predicate.
+func (desc *IndexDescriptor) IsPartial() bool {
+	return desc.Predicate != ""
+}
+
+// allColumnsForPredicates returns the public columns of the table followed by
+// the columns in mutations. Predicates are resolved against this list so that
+// they can still be evaluated while a column they reference is being dropped
+// alongside the index.
+func (desc *TableDescriptor) allColumnsForPredicates() []ColumnDescriptor {
+	if len(desc.Mutations) == 0 {
+		return desc.Columns
+	}
+	cols := make([]ColumnDescriptor, 0, len(desc.Columns)+len(desc.Mutations))
+	cols = append(cols, desc.Columns...)
+	for _, m := range desc.Mutations {
+		if col := m.GetColumn(); col != nil {
+			cols = append(cols, *col)
+		}
+	}
+	return cols
+}
+
+// PartialIndexPredicateColumnIDs returns the IDs of the columns referenced by
+// the predicate of the given index, which must be a partial index of this
+// table.
+func (desc *TableDescriptor) PartialIndexPredicateColumnIDs(
+	index *IndexDescriptor,
+) ([]ColumnID, error) {
+	expr, err := parser.ParseExpr(index.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	cols := desc.allColumnsForPredicates()
+	var colIDs []ColumnID
+	_, err = tree.SimpleVisit(expr, func(expr tree.Expr) (err error, recurse bool, newExpr tree.Expr) {
+		vBase, ok := expr.(tree.VarName)
+		if !ok {
+			return nil, true, expr
+		}
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return err, false, nil
+		}
+		c, ok := v.(*tree.ColumnItem)
+		if !ok {
+			return nil, true, expr
+		}
+		for i := range cols {
+			if cols[i].Name == string(c.ColumnName) {
+				colIDs = append(colIDs, cols[i].ID)
+				return nil, false, expr
+			}
+		}
+		return pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+			"column %q does not exist", c.ColumnName), false, nil
+	})
+	return colIDs, err
+}
+
+// MakePartialIndexPredicate parses, resolves and type checks the predicate of
+// the given partial index. The indexed vars of the returned expression refer to
+// the columns of the table followed by the columns in mutations and can be
+// evaluated with a RowIndexedVarContainer over those columns.
+func MakePartialIndexPredicate(
+	tableDesc *TableDescriptor, index *IndexDescriptor, evalCtx *tree.EvalContext,
+) (tree.TypedExpr, error) {
+	expr, err := parser.ParseExpr(index.Predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := tableDesc.allColumnsForPredicates()
+	iv := &descContainer{cols}
+	ivarHelper := tree.MakeIndexedVarHelper(iv, len(cols))
+	sourceInfo := NewSourceInfoForSingleTable(AnonymousTable, ResultColumnsFromColDescs(cols))
+
+	semaCtx := tree.MakeSemaContext(false)
+	semaCtx.IVarContainer = iv
+
+	searchPath := sessiondata.MakeSearchPath(nil)
+	if evalCtx != nil && evalCtx.SessionData != nil {
+		searchPath = evalCtx.SessionData.SearchPath
+	}
+	expr, _, _, err = ResolveNames(expr, MakeMultiSourceInfo(sourceInfo), ivarHelper, searchPath)
+	if err != nil {
+		return nil, err
+	}
+	return tree.TypeCheckAndRequire(expr, &semaCtx, types.Bool, "index predicate")
+}
+
+// PartialIndexPredicates evaluates the predicates of the partial indexes among
+// a list of indexes, to determine which of those indexes a row belongs to.
+type PartialIndexPredicates struct {
+	evalCtx *tree.EvalContext
+	// preds parallels the list of indexes the PartialIndexPredicates was
+	// created for; it is nil for the indexes that are not partial.
+	preds []tree.TypedExpr
+	ivars RowIndexedVarContainer
+}
+
+// MakePartialIndexPredicates prepares the predicates of the partial indexes in
+// the given list of indexes of tableDesc for evaluation.
+func MakePartialIndexPredicates(
+	tableDesc *TableDescriptor, indexes []IndexDescriptor, evalCtx *tree.EvalContext,
+) (PartialIndexPredicates, error) {
+	var p PartialIndexPredicates
+	for i := range indexes {
+		if !indexes[i].IsPartial() {
+			continue
+		}
+		if evalCtx == nil {
+			return PartialIndexPredicates{}, pgerror.NewErrorf(pgerror.CodeInternalError,
+				"programming error: no evaluation context to write to partial index %q", indexes[i].Name)
+		}
+		if p.preds == nil {
+			p.preds = make([]tree.TypedExpr, len(indexes))
+			p.evalCtx = evalCtx
+			p.ivars.Cols = tableDesc.allColumnsForPredicates()
+		}
+		var err error
+		if p.preds[i], err = MakePartialIndexPredicate(tableDesc, &indexes[i], evalCtx); err != nil {
+			return PartialIndexPredicates{}, err
+		}
+	}
+	return p, nil
+}
+
+// Empty returns true if none of the indexes is partial.
+func (p *PartialIndexPredicates) Empty() bool {
+	return p.preds == nil
+}
+
+// Matches returns true if the row with the given values satisfies the
+// predicate of the i-th index. It always returns true for indexes that are not
+// partial. colMap maps ColumnIDs to indices in values; columns missing from
+// colMap are considered NULL.
+func (p *PartialIndexPredicates) Matches(
+	i int, colMap map[ColumnID]int, values tree.Datums,
+) (bool, error) {
+	if p.preds == nil || p.preds[i] == nil {
+		return true, nil
+	}
+	p.ivars.CurSourceRow = values
+	p.ivars.Mapping = colMap
+	p.evalCtx.PushIVarContainer(&p.ivars)
+	d, err := p.preds[i].Eval(p.evalCtx)
+	p.evalCtx.PopIVarContainer()
+	if err != nil {
+		return false, err
+	}
+	return d != tree.DNull && bool(tree.MustBeDBool(d)), nil
+}
+
+// FilterIndexEntries clears the key of every entry in secondaryIndexEntries
+// that belongs to a partial index whose predicate the row does not satisfy.
+// secondaryIndexEntries must have been produced by EncodeSecondaryIndexes for
+// the same list of indexes. Entries with a nil key must not be written.
+func (p *PartialIndexPredicates) FilterIndexEntries(
+	colMap map[ColumnID]int, values tree.Datums, secondaryIndexEntries []IndexEntry,
+) error {
+	for i := range p.preds {
+		ok, err := p.Matches(i, colMap, values)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			secondaryIndexEntries[i].Key = nil
+		}
+	}
+	return nil
+}
@@ -113,6 +113,25 @@ var ExplainDistSQLColumns = ResultColumns{
 	{Name: "json", Typ: types.String, Hidden: true},
 }
 
+// ExplainAnalyzeColumns are the result columns of an
+// EXPLAIN ANALYZE [(PLAN)] statement.
+var ExplainAnalyzeColumns = ResultColumns{
+	// Tree shows the node type with the tree structure.
+	{Name: "tree", Typ: types.String},
+	// EstimatedRows is the row count estimated by the optimizer.
+	{Name: "estimated_rows", Typ: types.Int},
+	// EstimatedCost is the cost estimated by the optimizer.
+	{Name: "estimated_cost", Typ: types.Float},
+	// ActualRows is the number of rows produced during execution.
+	{Name: "actual_rows", Typ: types.Int},
+	// Time is the longest time spent by a processor of the node.
+	{Name: "time", Typ: types.Interval},
+	// MaxMemory is the memory used by the processors of the node.
+	{Name: "max_memory", Typ: types.String},
+	// MaxDisk is the disk used by the processors of the node when spilling.
+	{Name: "max_disk", Typ: types.String},
+}
+
 // ExplainOptColumns are the result columns of an
 // EXPLAIN (OPT) statement.
 var ExplainOptColumns = ResultColumns{
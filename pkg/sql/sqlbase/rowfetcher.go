@@ -1298,8 +1298,12 @@ func (rf *RowFetcher) Key() roachpb.Key {
 }
 
 // GetRangeInfo returns information about the ranges where the rows came from.
-// The RangeInfo's are deduped and not ordered.
+// The RangeInfo's are deduped and not ordered. Returns nil if no scan was
+// started.
 func (rf *RowFetcher) GetRangeInfo() []roachpb.RangeInfo {
+	if rf.kvFetcher == nil {
+		return nil
+	}
 	return rf.kvFetcher.getRangesInfo()
 }
 
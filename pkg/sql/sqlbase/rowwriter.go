@@ -46,6 +46,9 @@ type rowHelper struct {
 	// Secondary indexes.
 	Indexes      []IndexDescriptor
 	indexEntries []IndexEntry
+	// Predicates of the partial indexes in Indexes, used to leave out the
+	// entries of rows that do not belong in those indexes.
+	partialIndexes PartialIndexPredicates
 
 	// Computed during initialization for pretty-printing.
 	primIndexValDirs []encoding.Direction
@@ -57,9 +60,16 @@ type rowHelper struct {
 	sortedColumnFamilies  map[FamilyID][]ColumnID
 }
 
-func newRowHelper(desc *TableDescriptor, indexes []IndexDescriptor) rowHelper {
+func newRowHelper(
+	desc *TableDescriptor, indexes []IndexDescriptor, evalCtx *tree.EvalContext,
+) (rowHelper, error) {
 	rh := rowHelper{TableDesc: desc, Indexes: indexes}
 
+	var err error
+	if rh.partialIndexes, err = MakePartialIndexPredicates(desc, indexes, evalCtx); err != nil {
+		return rowHelper{}, err
+	}
+
 	// Pre-compute the encoding directions of the index key values for
 	// pretty-printing in traces.
 	rh.primIndexValDirs = IndexKeyValDirs(&rh.TableDesc.PrimaryIndex)
@@ -69,7 +79,7 @@ func newRowHelper(desc *TableDescriptor, indexes []IndexDescriptor) rowHelper {
 		rh.secIndexValDirs[i] = IndexKeyValDirs(&index)
 	}
 
-	return rh
+	return rh, nil
 }
 
 // encodeIndexes encodes the primary and secondary index keys. The
@@ -96,7 +106,8 @@ func (rh *rowHelper) encodeIndexes(
 
 // encodeSecondaryIndexes encodes the secondary index keys. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
-// encodeSecondaryIndexes.
+// encodeSecondaryIndexes. The entries of partial indexes that the row does not
+// belong to have a nil key.
 func (rh *rowHelper) encodeSecondaryIndexes(
 	colIDtoRowIndex map[ColumnID]int, values []tree.Datum,
 ) (secondaryIndexEntries []IndexEntry, err error) {
@@ -108,6 +119,9 @@ func (rh *rowHelper) encodeSecondaryIndexes(
 	if err != nil {
 		return nil, err
 	}
+	if err := rh.partialIndexes.FilterIndexEntries(colIDtoRowIndex, values, rh.indexEntries); err != nil {
+		return nil, err
+	}
 	return rh.indexEntries, nil
 }
 
@@ -170,13 +184,16 @@ type RowInserter struct {
 
 // MakeRowInserter creates a RowInserter for the given table.
 //
-// insertCols must contain every column in the primary key.
+// insertCols must contain every column in the primary key. evalCtx is used to
+// evaluate the predicates of partial indexes and may only be nil if the table
+// has none.
 func MakeRowInserter(
 	txn *client.Txn,
 	tableDesc *TableDescriptor,
 	fkTables TableLookupsByID,
 	insertCols []ColumnDescriptor,
 	checkFKs checkFKConstraints,
+	evalCtx *tree.EvalContext,
 	alloc *DatumAlloc,
 ) (RowInserter, error) {
 	indexes := tableDesc.Indexes
@@ -190,8 +207,12 @@ func MakeRowInserter(
 		}
 	}
 
+	helper, err := newRowHelper(tableDesc, indexes, evalCtx)
+	if err != nil {
+		return RowInserter{}, err
+	}
 	ri := RowInserter{
-		Helper:                newRowHelper(tableDesc, indexes),
+		Helper:                helper,
 		InsertCols:            insertCols,
 		InsertColIDtoRowIndex: ColIDtoRowIndexFromCols(insertCols),
 		marshaled:             make([]roachpb.Value, len(insertCols)),
@@ -204,7 +225,6 @@ func MakeRowInserter(
 	}
 
 	if checkFKs == CheckFKs {
-		var err error
 		if ri.Fks, err = makeFKInsertHelper(txn, *tableDesc, fkTables,
 			ri.InsertColIDtoRowIndex, alloc); err != nil {
 			return ri, err
@@ -318,6 +338,10 @@ func (ri *RowInserter) InsertRow(
 	putFn = insertInvertedPutFn
 	for i := range secondaryIndexEntries {
 		e := &secondaryIndexEntries[i]
+		if e.Key == nil {
+			// The row does not belong in this partial index.
+			continue
+		}
 		putFn(ctx, b, &e.Key, &e.Value, traceKV)
 	}
 
@@ -528,7 +552,7 @@ func MakeRowUpdater(
 	alloc *DatumAlloc,
 ) (RowUpdater, error) {
 	rowUpdater, err := makeRowUpdaterWithoutCascader(
-		txn, tableDesc, fkTables, updateCols, requestedCols, updateType, alloc,
+		txn, tableDesc, fkTables, updateCols, requestedCols, updateType, evalCtx, alloc,
 	)
 	if err != nil {
 		return RowUpdater{}, err
@@ -551,6 +575,7 @@ func makeRowUpdaterWithoutCascader(
 	updateCols []ColumnDescriptor,
 	requestedCols []ColumnDescriptor,
 	updateType rowUpdaterType,
+	evalCtx *tree.EvalContext,
 	alloc *DatumAlloc,
 ) (RowUpdater, error) {
 	updateColIDtoRowIndex := ColIDtoRowIndexFromCols(updateCols)
@@ -578,12 +603,30 @@ func makeRowUpdaterWithoutCascader(
 		if primaryKeyColChange {
 			return true
 		}
-		return index.RunOverAllColumns(func(id ColumnID) error {
+		if index.RunOverAllColumns(func(id ColumnID) error {
 			if _, ok := updateColIDtoRowIndex[id]; ok {
 				return returnTruePseudoError
 			}
 			return nil
-		}) != nil
+		}) != nil {
+			return true
+		}
+		// A partial index also needs updating when the row may move in or out
+		// of it. The predicate was validated when the index was created, so an
+		// error here means it references columns we cannot see; be
+		// conservative.
+		if index.IsPartial() {
+			colIDs, err := tableDesc.PartialIndexPredicateColumnIDs(&index)
+			if err != nil {
+				return true
+			}
+			for _, id := range colIDs {
+				if _, ok := updateColIDtoRowIndex[id]; ok {
+					return true
+				}
+			}
+		}
+		return false
 	}
 
 	indexes := make([]IndexDescriptor, 0, len(tableDesc.Indexes)+len(tableDesc.Mutations))
@@ -622,12 +665,19 @@ func makeRowUpdaterWithoutCascader(
 
 	var deleteOnlyHelper *rowHelper
 	if len(deleteOnlyIndexes) > 0 {
-		rh := newRowHelper(tableDesc, deleteOnlyIndexes)
+		rh, err := newRowHelper(tableDesc, deleteOnlyIndexes, evalCtx)
+		if err != nil {
+			return RowUpdater{}, err
+		}
 		deleteOnlyHelper = &rh
 	}
 
+	helper, err := newRowHelper(tableDesc, indexes, evalCtx)
+	if err != nil {
+		return RowUpdater{}, err
+	}
 	ru := RowUpdater{
-		Helper:                newRowHelper(tableDesc, indexes),
+		Helper:                helper,
 		DeleteHelper:          deleteOnlyHelper,
 		UpdateCols:            updateCols,
 		updateColIDtoRowIndex: updateColIDtoRowIndex,
@@ -640,16 +690,15 @@ func makeRowUpdaterWithoutCascader(
 		// These fields are only used when the primary key is changing.
 		// When changing the primary key, we delete the old values and reinsert
 		// them, so request them all.
-		var err error
 		if ru.rd, err = makeRowDeleterWithoutCascader(
-			txn, tableDesc, fkTables, tableCols, SkipFKs, alloc,
+			txn, tableDesc, fkTables, tableCols, SkipFKs, evalCtx, alloc,
 		); err != nil {
 			return RowUpdater{}, err
 		}
 		ru.FetchCols = ru.rd.FetchCols
 		ru.FetchColIDtoRowIndex = ColIDtoRowIndexFromCols(ru.FetchCols)
 		if ru.ri, err = MakeRowInserter(txn, tableDesc, fkTables,
-			tableCols, SkipFKs, alloc); err != nil {
+			tableCols, SkipFKs, evalCtx, alloc); err != nil {
 			return RowUpdater{}, err
 		}
 	} else {
@@ -698,9 +747,14 @@ func makeRowUpdaterWithoutCascader(
 				return RowUpdater{}, err
 			}
 		}
+		if err := addPartialIndexPredicateCols(tableDesc, indexes, maybeAddCol); err != nil {
+			return RowUpdater{}, err
+		}
+		if err := addPartialIndexPredicateCols(tableDesc, deleteOnlyIndexes, maybeAddCol); err != nil {
+			return RowUpdater{}, err
+		}
 	}
 
-	var err error
 	if ru.Fks, err = makeFKUpdateHelper(txn, *tableDesc, fkTables,
 		ru.FetchColIDtoRowIndex, alloc); err != nil {
 		return RowUpdater{}, err
@@ -863,10 +917,18 @@ func (ru *RowUpdater) UpdateRow(
 		var expValue interface{}
 		if !bytes.Equal(newSecondaryIndexEntry.Key, oldSecondaryIndexEntry.Key) {
 			ru.Fks.addCheckForIndex(ru.Helper.Indexes[i].ID, ru.Helper.Indexes[i].Type)
-			if traceKV {
-				log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+			// A nil key means the old or new row is not in this partial index.
+			if oldSecondaryIndexEntry.Key != nil {
+				if traceKV {
+					log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+				}
+				batch.Del(oldSecondaryIndexEntry.Key)
+			}
+			if newSecondaryIndexEntry.Key == nil {
+				continue
 			}
-			batch.Del(oldSecondaryIndexEntry.Key)
+		} else if newSecondaryIndexEntry.Key == nil {
+			continue
 		} else if !newSecondaryIndexEntry.Value.EqualData(oldSecondaryIndexEntry.Value) {
 			expValue = &oldSecondaryIndexEntry.Value
 		} else {
@@ -883,6 +945,9 @@ func (ru *RowUpdater) UpdateRow(
 	// indexed will be handled separately.
 	if ru.DeleteHelper != nil {
 		for _, deletedSecondaryIndexEntry := range deleteOldSecondaryIndexEntries {
+			if deletedSecondaryIndexEntry.Key == nil {
+				continue
+			}
 			if traceKV {
 				log.VEventf(ctx, 2, "Del %s", deletedSecondaryIndexEntry.Key)
 			}
@@ -892,6 +957,9 @@ func (ru *RowUpdater) UpdateRow(
 
 	// We're removing all of the inverted index entries from the row being updated.
 	for i := len(ru.Helper.Indexes); i < len(oldSecondaryIndexEntries); i++ {
+		if oldSecondaryIndexEntries[i].Key == nil {
+			continue
+		}
 		if traceKV {
 			log.VEventf(ctx, 2, "Del %s", oldSecondaryIndexEntries[i].Key)
 		}
@@ -901,6 +969,9 @@ func (ru *RowUpdater) UpdateRow(
 	putFn := insertInvertedPutFn
 	// We're adding all of the inverted index entries from the row being updated.
 	for i := len(ru.Helper.Indexes); i < len(newSecondaryIndexEntries); i++ {
+		if newSecondaryIndexEntries[i].Key == nil {
+			continue
+		}
 		putFn(ctx, b, &newSecondaryIndexEntries[i].Key, &newSecondaryIndexEntries[i].Value, traceKV)
 	}
 
@@ -973,7 +1044,7 @@ func MakeRowDeleter(
 	alloc *DatumAlloc,
 ) (RowDeleter, error) {
 	rowDeleter, err := makeRowDeleterWithoutCascader(
-		txn, tableDesc, fkTables, requestedCols, checkFKs, alloc,
+		txn, tableDesc, fkTables, requestedCols, checkFKs, evalCtx, alloc,
 	)
 	if err != nil {
 		return RowDeleter{}, err
@@ -996,6 +1067,7 @@ func makeRowDeleterWithoutCascader(
 	fkTables TableLookupsByID,
 	requestedCols []ColumnDescriptor,
 	checkFKs checkFKConstraints,
+	evalCtx *tree.EvalContext,
 	alloc *DatumAlloc,
 ) (RowDeleter, error) {
 	indexes := tableDesc.Indexes
@@ -1037,14 +1109,22 @@ func makeRowDeleterWithoutCascader(
 			}
 		}
 	}
+	// The predicate columns are needed so that we don't delete the entry of
+	// another row from a unique partial index that this row is not in.
+	if err := addPartialIndexPredicateCols(tableDesc, indexes, maybeAddCol); err != nil {
+		return RowDeleter{}, err
+	}
 
+	helper, err := newRowHelper(tableDesc, indexes, evalCtx)
+	if err != nil {
+		return RowDeleter{}, err
+	}
 	rd := RowDeleter{
-		Helper:               newRowHelper(tableDesc, indexes),
+		Helper:               helper,
 		FetchCols:            fetchCols,
 		FetchColIDtoRowIndex: fetchColIDtoRowIndex,
 	}
 	if checkFKs == CheckFKs {
-		var err error
 		if rd.Fks, err = makeFKDeleteHelper(txn, *tableDesc, fkTables,
 			fetchColIDtoRowIndex, alloc); err != nil {
 			return RowDeleter{}, err
@@ -1072,6 +1152,9 @@ func (rd *RowDeleter) DeleteRow(
 
 	// Delete the row from any secondary indices.
 	for i, secondaryIndexEntry := range secondaryIndexEntries {
+		if secondaryIndexEntry.Key == nil {
+			continue
+		}
 		if traceKV {
 			log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(rd.Helper.secIndexValDirs[i], secondaryIndexEntry.Key))
 		}
@@ -1143,6 +1226,28 @@ func (rd *RowDeleter) DeleteIndexRow(
 	return nil
 }
 
+// addPartialIndexPredicateCols calls addCol for every column referenced by the
+// predicate of a partial index in indexes.
+func addPartialIndexPredicateCols(
+	tableDesc *TableDescriptor, indexes []IndexDescriptor, addCol func(ColumnID) error,
+) error {
+	for i := range indexes {
+		if !indexes[i].IsPartial() {
+			continue
+		}
+		colIDs, err := tableDesc.PartialIndexPredicateColumnIDs(&indexes[i])
+		if err != nil {
+			return err
+		}
+		for _, colID := range colIDs {
+			if err := addCol(colID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // ColIDtoRowIndexFromCols groups a slice of ColumnDescriptors by their ID
 // field, returning a map from ID to ColumnDescriptor. It assumes there are no
 // duplicate descriptors in the input.
@@ -34,6 +34,7 @@ type NameResolutionVisitor struct {
 	sources    MultiSourceInfo
 	iVarHelper tree.IndexedVarHelper
 	searchPath sessiondata.SearchPath
+	functions  tree.FunctionResolver
 	resolver   ColumnResolver
 
 	// foundDependentVars is set to true during the analysis if an
@@ -142,7 +143,7 @@ func (v *NameResolutionVisitor) VisitPre(expr tree.Expr) (recurse bool, newNode
 		return true, ivar
 
 	case *tree.FuncExpr:
-		fd, err := t.Func.Resolve(v.searchPath)
+		fd, err := t.Func.ResolveWithFunctions(v.searchPath, v.functions)
 		if err != nil {
 			v.err = err
 			return false, expr
@@ -220,23 +221,26 @@ func ResolveNames(
 	searchPath sessiondata.SearchPath,
 ) (tree.Expr, bool, bool, error) {
 	var v NameResolutionVisitor
-	return ResolveNamesUsingVisitor(&v, expr, sources, ivarHelper, searchPath)
+	return ResolveNamesUsingVisitor(&v, expr, sources, ivarHelper, searchPath, nil /* functions */)
 }
 
 // ResolveNamesUsingVisitor resolves the names in the given expression. It
 // returns the resolved expression, whether it found dependent vars, and
-// whether it found stars.
+// whether it found stars. User-defined functions are resolved using
+// functions, if non-nil.
 func ResolveNamesUsingVisitor(
 	v *NameResolutionVisitor,
 	expr tree.Expr,
 	sources MultiSourceInfo,
 	ivarHelper tree.IndexedVarHelper,
 	searchPath sessiondata.SearchPath,
+	functions tree.FunctionResolver,
 ) (tree.Expr, bool, bool, error) {
 	*v = NameResolutionVisitor{
 		sources:    sources,
 		iVarHelper: ivarHelper,
 		searchPath: searchPath,
+		functions:  functions,
 		resolver: ColumnResolver{
 			Sources: sources,
 		},
@@ -226,12 +226,24 @@ func (desc *IndexDescriptor) allocateName(tableDesc *TableDescriptor) {
 	desc.Name = name
 }
 
-// FillColumns sets the column names and directions in desc.
+// TrigramOpClass is the name of the operator class of the columns of trigram
+// indexes.
+const TrigramOpClass = "gin_trgm_ops"
+
+// FillColumns sets the column names, directions and operator classes in desc.
 func (desc *IndexDescriptor) FillColumns(elems tree.IndexElemList) error {
 	desc.ColumnNames = make([]string, 0, len(elems))
 	desc.ColumnDirections = make([]IndexDescriptor_Direction, 0, len(elems))
 	for _, c := range elems {
 		desc.ColumnNames = append(desc.ColumnNames, string(c.Column))
+		switch c.OpClass {
+		case "":
+		case TrigramOpClass:
+			desc.Trigram = true
+		default:
+			return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"operator class %q does not exist", c.OpClass)
+		}
 		switch c.Direction {
 		case tree.Ascending, tree.DefaultDirection:
 			desc.ColumnDirections = append(desc.ColumnDirections, IndexDescriptor_ASC)
@@ -296,6 +308,9 @@ func (desc *IndexDescriptor) ColNamesFormat(ctx *tree.FmtCtxWithBuf) {
 			ctx.WriteString(", ")
 		}
 		ctx.FormatNameP(&desc.ColumnNames[i])
+		if desc.Trigram {
+			ctx.WriteString(" " + TrigramOpClass)
+		}
 		if desc.Type != IndexDescriptor_INVERTED {
 			ctx.WriteByte(' ')
 			ctx.WriteString(desc.ColumnDirections[i].String())
@@ -368,7 +383,7 @@ func (desc *TableDescriptor) IsEmpty() bool {
 // IsTable returns true if the TableDescriptor actually describes a
 // Table resource, as opposed to a different resource (like a View).
 func (desc *TableDescriptor) IsTable() bool {
-	return !desc.IsView() && !desc.IsSequence()
+	return !desc.IsView() && !desc.IsSequence() && !desc.IsFunction()
 }
 
 // IsView returns true if the TableDescriptor actually describes a
@@ -383,6 +398,28 @@ func (desc *TableDescriptor) IsSequence() bool {
 	return desc.SequenceOpts != nil
 }
 
+// IsFunction returns true if the TableDescriptor actually describes a
+// user-defined Function rather than a Table.
+func (desc *TableDescriptor) IsFunction() bool {
+	return desc.FunctionOpts != nil
+}
+
+// IsTemporary returns true if the TableDescriptor describes a temporary
+// table, owned by a single session.
+func (desc *TableDescriptor) IsTemporary() bool {
+	return desc.TemporarySchema != nil
+}
+
+// NamespaceParentID returns the ID under which the name of the table is
+// registered in system.namespace. This is the ID of the parent database,
+// except for temporary tables whose names live in their temporary schema.
+func (desc *TableDescriptor) NamespaceParentID() ID {
+	if desc.TemporarySchema != nil {
+		return desc.TemporarySchema.ID
+	}
+	return desc.ParentID
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -395,9 +432,10 @@ func (desc *TableDescriptor) IsVirtualTable() bool {
 // different resource like a view or a virtual table. Physical tables have
 // primary keys, column families, and indexes (unlike virtual tables).
 // Sequences count as physical tables because their values are stored in
-// the KV layer.
+// the KV layer, and so do materialized views because their contents are.
 func (desc *TableDescriptor) IsPhysicalTable() bool {
-	return desc.IsSequence() || (desc.IsTable() && !desc.IsVirtualTable())
+	return desc.IsSequence() || desc.IsMaterializedView ||
+		(desc.IsTable() && !desc.IsVirtualTable())
 }
 
 // KeysPerRow returns the maximum number of keys used to encode a row for the
@@ -655,7 +693,11 @@ func DatumTypeHasCompositeKeyEncoding(typ types.T) bool {
 // MustBeValueEncoded returns true if columns of the given kind can only be value
 // encoded.
 func MustBeValueEncoded(semanticType ColumnType_SemanticType) bool {
-	return semanticType == ColumnType_ARRAY || semanticType == ColumnType_JSON || semanticType == ColumnType_TUPLE
+	switch semanticType {
+	case ColumnType_ARRAY, ColumnType_JSON, ColumnType_TUPLE, ColumnType_TSVECTOR, ColumnType_TSQUERY:
+		return true
+	}
+	return false
 }
 
 // HasOldStoredColumns returns whether the index has stored columns in the old
@@ -700,6 +742,11 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 			isCompositeColumn[col.ID] = struct{}{}
 		}
 	}
+	for _, m := range desc.Mutations {
+		if col := m.GetColumn(); col != nil && HasCompositeKeyEncoding(col.Type.SemanticType) {
+			isCompositeColumn[col.ID] = struct{}{}
+		}
+	}
 
 	// Populate IDs.
 	for _, index := range indexes {
@@ -1024,6 +1071,28 @@ func (desc *TableDescriptor) validateCrossReferences(ctx context.Context, txn *c
 	return nil
 }
 
+// validateFunction validates the parts of a function descriptor that are
+// not shared with tables: functions have neither columns nor indexes.
+func (desc *TableDescriptor) validateFunction() error {
+	if desc.ParentID == 0 {
+		return fmt.Errorf("invalid parent ID %d", desc.ParentID)
+	}
+	if desc.FunctionOpts.Body == "" {
+		return fmt.Errorf("function %q has no body", desc.Name)
+	}
+	argNames := make(map[string]struct{}, len(desc.FunctionOpts.Arguments))
+	for _, arg := range desc.FunctionOpts.Arguments {
+		if arg.Name == "" {
+			continue
+		}
+		if _, ok := argNames[arg.Name]; ok {
+			return fmt.Errorf("duplicate argument name: %q", arg.Name)
+		}
+		argNames[arg.Name] = struct{}{}
+	}
+	return desc.Privileges.Validate(desc.GetID())
+}
+
 // ValidateTable validates that the table descriptor is well formed. Checks
 // include validating the table, column and index names, verifying that column
 // names and index names are unique and verifying that column IDs and index IDs
@@ -1053,6 +1122,14 @@ func (desc *TableDescriptor) ValidateTable(st *cluster.Settings) error {
 		return nil
 	}
 
+	if desc.IsFunction() {
+		return desc.validateFunction()
+	}
+
+	if desc.IsMaterializedView && !desc.IsView() {
+		return fmt.Errorf("materialized view %q has no view query", desc.Name)
+	}
+
 	// ParentID is the ID of the database holding this table.
 	// It is often < ID, except when a table gets moved across databases.
 	if desc.ParentID == 0 {
@@ -1142,6 +1219,23 @@ func (desc *TableDescriptor) ValidateTable(st *cluster.Settings) error {
 				idx := desc.Index
 				return errors.Errorf("mutation in state %s, direction %s, index %s, id %v", m.State, m.Direction, idx.Name, idx.ID)
 			}
+		case *DescriptorMutation_MaterializedViewRefresh:
+			if unSetEnums {
+				return errors.Errorf("mutation in state %s, direction %s, materialized view refresh", m.State, m.Direction)
+			}
+		case *DescriptorMutation_ColumnTypeSwap:
+			if unSetEnums {
+				return errors.Errorf("mutation in state %s, direction %s, column type swap", m.State, m.Direction)
+			}
+			if swap := desc.ColumnTypeSwap; len(swap.OldIndexIDs) != len(swap.NewIndexIDs) {
+				return errors.Errorf("column type swap has %d old indexes but %d new indexes",
+					len(swap.OldIndexIDs), len(swap.NewIndexIDs))
+			}
+		case *DescriptorMutation_NotNullConstraint:
+			if unSetEnums {
+				return errors.Errorf("mutation in state %s, direction %s, NOT NULL constraint on column id %v",
+					m.State, m.Direction, desc.NotNullConstraint.ColumnID)
+			}
 		default:
 			return errors.Errorf("mutation in state %s, direction %s, and no column/index descriptor", m.State, m.Direction)
 		}
@@ -1523,7 +1617,8 @@ func upperBoundColumnValueEncodedSize(col ColumnDescriptor) (int, bool) {
 		typ = encoding.Float
 	case ColumnType_INTERVAL:
 		typ = encoding.Duration
-	case ColumnType_STRING, ColumnType_BYTES, ColumnType_COLLATEDSTRING, ColumnType_NAME, ColumnType_UUID, ColumnType_INET:
+	case ColumnType_STRING, ColumnType_BYTES, ColumnType_COLLATEDSTRING, ColumnType_NAME, ColumnType_UUID, ColumnType_INET,
+		ColumnType_TSVECTOR, ColumnType_TSQUERY:
 		// STRINGs are counted as runes, so this isn't totally correct, but this
 		// seems better than always assuming the maximum rune width.
 		typ, size = encoding.Bytes, int(col.Type.Width)
@@ -1577,11 +1672,24 @@ func columnTypeIsIndexable(t ColumnType) bool {
 }
 
 // columnTypeIsInvertedIndexable returns whether the type t is valid to be indexed
-// using an inverted index.
-func columnTypeIsInvertedIndexable(t ColumnType) bool {
-	return t.SemanticType == ColumnType_JSON
+// using an inverted index. Trigram indexes only accept strings.
+func columnTypeIsInvertedIndexable(t ColumnType, trigram bool) bool {
+	if trigram {
+		return t.SemanticType == ColumnType_STRING
+	}
+	switch t.SemanticType {
+	case ColumnType_JSON, ColumnType_TSVECTOR:
+		return true
+	case ColumnType_ARRAY:
+		// The elements of the array are key encoded.
+		return t.ArrayContents != nil && !MustBeValueEncoded(*t.ArrayContents)
+	}
+	return false
 }
 
+var errTrigramForwardIndex = pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+	"operator class %q requires an inverted index", TrigramOpClass)
+
 func notIndexableError(cols []ColumnDescriptor, inverted bool) error {
 	if len(cols) == 0 {
 		return nil
@@ -1630,7 +1738,9 @@ func checkColumnsValidForIndex(tableDesc *TableDescriptor, indexColNames []strin
 	return nil
 }
 
-func checkColumnsValidForInvertedIndex(tableDesc *TableDescriptor, indexColNames []string) error {
+func checkColumnsValidForInvertedIndex(
+	tableDesc *TableDescriptor, indexColNames []string, trigram bool,
+) error {
 	if len((indexColNames)) > 1 {
 		return errors.New("indexing more than one column with an inverted index is not supported")
 	}
@@ -1638,7 +1748,12 @@ func checkColumnsValidForInvertedIndex(tableDesc *TableDescriptor, indexColNames
 	for _, indexCol := range indexColNames {
 		for _, col := range tableDesc.allNonDropColumns() {
 			if col.Name == indexCol {
-				if !columnTypeIsInvertedIndexable(col.Type) {
+				if !columnTypeIsInvertedIndexable(col.Type, trigram) {
+					if trigram {
+						return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+							"operator class %q does not accept column %s of type %s",
+							TrigramOpClass, col.Name, col.Type.SemanticType)
+					}
 					invalidColumns = append(invalidColumns, col)
 				}
 			}
@@ -1666,6 +1781,9 @@ func (desc *TableDescriptor) AddIndex(idx IndexDescriptor, primary bool) error {
 		if err := checkColumnsValidForIndex(desc, idx.ColumnNames); err != nil {
 			return err
 		}
+		if idx.Trigram {
+			return errTrigramForwardIndex
+		}
 
 		if primary {
 			// PrimaryIndex is unset.
@@ -1683,7 +1801,7 @@ func (desc *TableDescriptor) AddIndex(idx IndexDescriptor, primary bool) error {
 		}
 
 	} else {
-		if err := checkColumnsValidForInvertedIndex(desc, idx.ColumnNames); err != nil {
+		if err := checkColumnsValidForInvertedIndex(desc, idx.ColumnNames, idx.Trigram); err != nil {
 			return err
 		}
 		desc.Indexes = append(desc.Indexes, idx)
@@ -2012,6 +2130,36 @@ func (desc *TableDescriptor) MakeMutationComplete(m DescriptorMutation) {
 			if err := desc.AddIndex(*t.Index, false); err != nil {
 				panic(err)
 			}
+
+		case *DescriptorMutation_MaterializedViewRefresh:
+			// Swap in the primary index containing the recomputed contents of
+			// the view. References to the old primary index are redirected to
+			// the new one, and the old primary index is dropped by a new
+			// mutation.
+			old := desc.PrimaryIndex
+			desc.PrimaryIndex = t.MaterializedViewRefresh.NewPrimaryIndex
+			for i := range desc.DependedOnBy {
+				if desc.DependedOnBy[i].IndexID == old.ID {
+					desc.DependedOnBy[i].IndexID = desc.PrimaryIndex.ID
+				}
+			}
+			desc.addMutation(DescriptorMutation{
+				Descriptor_: &DescriptorMutation_Index{Index: &old},
+				Direction:   DescriptorMutation_DROP,
+			})
+			desc.NextMutationID++
+
+		case *DescriptorMutation_ColumnTypeSwap:
+			desc.swapColumnType(t.ColumnTypeSwap)
+
+		case *DescriptorMutation_NotNullConstraint:
+			// The existing rows have been validated.
+			for i := range desc.Columns {
+				if desc.Columns[i].ID == t.NotNullConstraint.ColumnID {
+					desc.Columns[i].Nullable = false
+					break
+				}
+			}
 		}
 
 	case DescriptorMutation_DROP:
@@ -2042,8 +2190,11 @@ func (desc *TableDescriptor) AddIndexMutation(
 		if err := checkColumnsValidForIndex(desc, idx.ColumnNames); err != nil {
 			return err
 		}
+		if idx.Trigram {
+			return errTrigramForwardIndex
+		}
 	case IndexDescriptor_INVERTED:
-		if err := checkColumnsValidForInvertedIndex(desc, idx.ColumnNames); err != nil {
+		if err := checkColumnsValidForInvertedIndex(desc, idx.ColumnNames, idx.Trigram); err != nil {
 			return err
 		}
 	}
@@ -2053,6 +2204,138 @@ func (desc *TableDescriptor) AddIndexMutation(
 	return nil
 }
 
+// AddMaterializedViewRefreshMutation adds a mutation to desc.Mutations that
+// recomputes the contents of a materialized view into a new primary index,
+// running the view's query with the privileges of the given user.
+func (desc *TableDescriptor) AddMaterializedViewRefreshMutation(user string) {
+	idx := desc.PrimaryIndex
+	idx.ID = desc.NextIndexID
+	desc.NextIndexID++
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_MaterializedViewRefresh{
+			MaterializedViewRefresh: &MaterializedViewRefresh{NewPrimaryIndex: idx, User: user},
+		},
+		Direction: DescriptorMutation_ADD,
+	}
+	desc.addMutation(m)
+}
+
+// AddColumnTypeSwapMutation adds a mutation to desc.Mutations that puts the
+// column newColID in place of the column oldColID, and each index in
+// newIndexIDs in place of the corresponding index in oldIndexIDs. The new
+// column and indexes must be added by mutations preceding this one.
+func (desc *TableDescriptor) AddColumnTypeSwapMutation(
+	oldColID, newColID ColumnID, oldIndexIDs, newIndexIDs []IndexID,
+) {
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_ColumnTypeSwap{
+			ColumnTypeSwap: &ColumnTypeSwap{
+				OldColumnID: oldColID,
+				NewColumnID: newColID,
+				OldIndexIDs: oldIndexIDs,
+				NewIndexIDs: newIndexIDs,
+			},
+		},
+		Direction: DescriptorMutation_ADD,
+	}
+	desc.addMutation(m)
+}
+
+// AddNotNullMutation adds a mutation to desc.Mutations that makes the column
+// with the given ID non-nullable once its existing values have been validated.
+func (desc *TableDescriptor) AddNotNullMutation(id ColumnID) {
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_NotNullConstraint{
+			NotNullConstraint: &NotNullConstraint{ColumnID: id},
+		},
+		Direction: DescriptorMutation_ADD,
+	}
+	desc.addMutation(m)
+}
+
+// ValidatingNotNull returns true if a NOT NULL constraint is being added to
+// the column with the given ID. Writes must not put NULLs into such a column
+// even though it is still marked as nullable.
+func (desc *TableDescriptor) ValidatingNotNull(id ColumnID) bool {
+	for _, m := range desc.Mutations {
+		if c := m.GetNotNullConstraint(); c != nil && c.ColumnID == id &&
+			m.Direction == DescriptorMutation_ADD {
+			return true
+		}
+	}
+	return false
+}
+
+// swapColumnType completes a ColumnTypeSwap mutation. The new column and
+// indexes take over the names and positions of the old ones, which are
+// queued up to be dropped under a new mutation ID.
+func (desc *TableDescriptor) swapColumnType(swap *ColumnTypeSwap) {
+	oldColIdx, newColIdx := -1, -1
+	for i := range desc.Columns {
+		switch desc.Columns[i].ID {
+		case swap.OldColumnID:
+			oldColIdx = i
+		case swap.NewColumnID:
+			newColIdx = i
+		}
+	}
+	if oldColIdx == -1 || newColIdx == -1 {
+		panic(errors.Errorf("column type swap of columns %d and %d: column not found",
+			swap.OldColumnID, swap.NewColumnID))
+	}
+	oldCol, newCol := desc.Columns[oldColIdx], desc.Columns[newColIdx]
+	name, shadowName := oldCol.Name, newCol.Name
+	desc.RenameColumnDescriptor(oldCol, shadowName)
+	oldCol.Name = shadowName
+	newCol.Name = name
+	newCol.Hidden = oldCol.Hidden
+	newCol.DefaultExpr = oldCol.DefaultExpr
+	newCol.ComputeExpr = nil
+	desc.RenameColumnDescriptor(newCol, name)
+	desc.Columns[oldColIdx] = newCol
+	desc.Columns = append(desc.Columns[:newColIdx], desc.Columns[newColIdx+1:]...)
+
+	renameStored := func(idx *IndexDescriptor, from, to string) {
+		for i := range idx.StoreColumnNames {
+			if idx.StoreColumnNames[i] == from {
+				idx.StoreColumnNames[i] = to
+			}
+		}
+	}
+	var oldIndexes []IndexDescriptor
+	for i, oldID := range swap.OldIndexIDs {
+		oldIdx, newIdx := -1, -1
+		for j := range desc.Indexes {
+			switch desc.Indexes[j].ID {
+			case oldID:
+				oldIdx = j
+			case swap.NewIndexIDs[i]:
+				newIdx = j
+			}
+		}
+		if oldIdx == -1 || newIdx == -1 {
+			panic(errors.Errorf("column type swap of indexes %d and %d: index not found",
+				oldID, swap.NewIndexIDs[i]))
+		}
+		old, idx := desc.Indexes[oldIdx], desc.Indexes[newIdx]
+		old.Name, idx.Name = idx.Name, old.Name
+		renameStored(&old, name, shadowName)
+		renameStored(&idx, shadowName, name)
+		oldIndexes = append(oldIndexes, old)
+		desc.Indexes[oldIdx] = idx
+		desc.Indexes = append(desc.Indexes[:newIdx], desc.Indexes[newIdx+1:]...)
+	}
+
+	for i := range oldIndexes {
+		desc.addMutation(DescriptorMutation{
+			Descriptor_: &DescriptorMutation_Index{Index: &oldIndexes[i]},
+			Direction:   DescriptorMutation_DROP,
+		})
+	}
+	desc.AddColumnMutation(oldCol, DescriptorMutation_DROP)
+	desc.NextMutationID++
+}
+
 func (desc *TableDescriptor) addMutation(m DescriptorMutation) {
 	switch m.Direction {
 	case DescriptorMutation_ADD:
@@ -2302,6 +2585,10 @@ func DatumTypeToColumnSemanticType(ptyp types.T) (ColumnType_SemanticType, error
 		return ColumnType_OIDVECTOR, nil
 	case types.JSON:
 		return ColumnType_JSON, nil
+	case types.TSVector:
+		return ColumnType_TSVECTOR, nil
+	case types.TSQuery:
+		return ColumnType_TSQUERY, nil
 	default:
 		if ptyp.FamilyEqual(types.FamCollatedString) {
 			return ColumnType_COLLATEDSTRING, nil
@@ -2388,6 +2675,10 @@ func columnSemanticTypeToDatumType(c *ColumnType, k ColumnType_SemanticType) typ
 		return types.INet
 	case ColumnType_JSON:
 		return types.JSON
+	case ColumnType_TSVECTOR:
+		return types.TSVector
+	case ColumnType_TSQUERY:
+		return types.TSQuery
 	case ColumnType_TUPLE:
 		return types.FamTuple
 	case ColumnType_COLLATEDSTRING:
@@ -2556,7 +2847,7 @@ func (desc TableDescriptor) GetDescMetadataKey() roachpb.Key {
 
 // GetNameMetadataKey returns the namespace key for the table.
 func (desc TableDescriptor) GetNameMetadataKey() roachpb.Key {
-	return MakeNameMetadataKey(desc.ParentID, desc.Name)
+	return MakeNameMetadataKey(desc.NamespaceParentID(), desc.Name)
 }
 
 // SQLString returns the SQL statement describing the column.
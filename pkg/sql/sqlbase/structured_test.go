@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
@@ -1302,6 +1303,293 @@ func TestUnvalidateConstraints(t *testing.T) {
 	}
 }
 
+func TestColumnTypeSwap(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := TableDescriptor{
+		ParentID: keys.MinUserDescID,
+		ID:       keys.MinUserDescID + 1,
+		Name:     "foo",
+		Columns: []ColumnDescriptor{
+			{Name: "a", Type: ColumnType{SemanticType: ColumnType_INT}},
+			{Name: "b", Type: ColumnType{SemanticType: ColumnType_INT}, Nullable: true},
+			{Name: "c", Type: ColumnType{SemanticType: ColumnType_INT}, Nullable: true},
+		},
+		PrimaryIndex: makeIndexDescriptor("primary", []string{"a"}),
+		Indexes: []IndexDescriptor{
+			makeIndexDescriptor("foo_b_idx", []string{"b"}),
+			makeIndexDescriptor("foo_c_idx", []string{"c"}),
+		},
+		Privileges:    NewDefaultPrivilegeDescriptor(),
+		FormatVersion: FamilyFormatVersion,
+	}
+	desc.Indexes[1].StoreColumnNames = []string{"b"}
+	if err := desc.AllocateIDs(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Change the type of b to STRING, as ALTER COLUMN TYPE does.
+	expr := "b::STRING"
+	newCol := ColumnDescriptor{
+		Name:        "b_new",
+		ID:          desc.NextColumnID,
+		Type:        ColumnType{SemanticType: ColumnType_STRING},
+		Nullable:    true,
+		Hidden:      true,
+		ComputeExpr: &expr,
+	}
+	desc.NextColumnID++
+	desc.AddColumnMutation(newCol, DescriptorMutation_ADD)
+	var oldIndexIDs, newIndexIDs []IndexID
+	for _, idx := range desc.Indexes {
+		newIdx := idx
+		newIdx.ID = desc.NextIndexID
+		desc.NextIndexID++
+		newIdx.Name = idx.Name + "_new"
+		newIdx.ColumnNames = []string{idx.ColumnNames[0]}
+		newIdx.ColumnIDs = []ColumnID{idx.ColumnIDs[0]}
+		newIdx.StoreColumnNames = nil
+		newIdx.StoreColumnIDs = nil
+		newIdx.ExtraColumnIDs = nil
+		if idx.ColumnNames[0] == "b" {
+			newIdx.ColumnNames = []string{"b_new"}
+			newIdx.ColumnIDs = []ColumnID{newCol.ID}
+		} else {
+			newIdx.StoreColumnNames = []string{"b_new"}
+		}
+		if err := desc.AddIndexMutation(newIdx, DescriptorMutation_ADD); err != nil {
+			t.Fatal(err)
+		}
+		oldIndexIDs = append(oldIndexIDs, idx.ID)
+		newIndexIDs = append(newIndexIDs, newIdx.ID)
+	}
+	desc.AddColumnTypeSwapMutation(2, newCol.ID, oldIndexIDs, newIndexIDs)
+	if err := desc.AllocateIDs(); err != nil {
+		t.Fatal(err)
+	}
+	mutationID, err := desc.FinalizeMutation()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Complete the mutations, as the schema changer does.
+	mutations := desc.Mutations
+	for _, m := range mutations {
+		if m.MutationID != mutationID {
+			t.Fatalf("unexpected mutation %+v", m)
+		}
+		desc.MakeMutationComplete(m)
+	}
+	desc.Mutations = desc.Mutations[len(mutations):]
+	if err := desc.ValidateTable(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(desc.Columns) != 3 {
+		t.Fatalf("expected 3 columns, found %+v", desc.Columns)
+	}
+	b := desc.Columns[1]
+	if b.Name != "b" || b.ID != newCol.ID || b.Type.SemanticType != ColumnType_STRING ||
+		b.Hidden || b.IsComputed() {
+		t.Fatalf("unexpected column after swap: %+v", b)
+	}
+	if len(desc.Indexes) != 2 {
+		t.Fatalf("expected 2 indexes, found %+v", desc.Indexes)
+	}
+	for i, idx := range desc.Indexes {
+		if idx.ID != newIndexIDs[i] || !idx.ContainsColumnID(newCol.ID) {
+			t.Fatalf("unexpected index after swap: %+v", idx)
+		}
+	}
+	if idx := desc.Indexes[0]; idx.Name != "foo_b_idx" || !reflect.DeepEqual(idx.ColumnNames, []string{"b"}) {
+		t.Fatalf("unexpected index after swap: %+v", idx)
+	}
+	if idx := desc.Indexes[1]; idx.Name != "foo_c_idx" || !reflect.DeepEqual(idx.StoreColumnNames, []string{"b"}) {
+		t.Fatalf("unexpected index after swap: %+v", idx)
+	}
+
+	// The old column and indexes are dropped by a follow-up mutation.
+	if desc.NextMutationID != mutationID+2 {
+		t.Fatalf("expected next mutation ID %d, found %d", mutationID+2, desc.NextMutationID)
+	}
+	if len(desc.Mutations) != 3 {
+		t.Fatalf("expected 3 mutations, found %+v", desc.Mutations)
+	}
+	for _, m := range desc.Mutations {
+		if m.MutationID != mutationID+1 || m.Direction != DescriptorMutation_DROP {
+			t.Fatalf("unexpected mutation %+v", m)
+		}
+	}
+	if col := desc.Mutations[2].GetColumn(); col == nil || col.ID != 2 || col.Name != "b_new" {
+		t.Fatalf("unexpected mutation %+v", desc.Mutations[2])
+	}
+	for i, m := range desc.Mutations[:2] {
+		if idx := m.GetIndex(); idx == nil || idx.ID != oldIndexIDs[i] || !idx.ContainsColumnID(2) {
+			t.Fatalf("unexpected mutation %+v", m)
+		}
+	}
+}
+
+func TestMaterializedViewRefreshMutation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := TableDescriptor{
+		ParentID: keys.MinUserDescID,
+		ID:       keys.MinUserDescID + 1,
+		Name:     "v",
+		Columns: []ColumnDescriptor{
+			{Name: "rowid", Type: ColumnType{SemanticType: ColumnType_INT}, Hidden: true},
+			{Name: "a", Type: ColumnType{SemanticType: ColumnType_INT}, Nullable: true},
+		},
+		PrimaryIndex:       makeIndexDescriptor("primary", []string{"rowid"}),
+		ViewQuery:          "SELECT a FROM t",
+		IsMaterializedView: true,
+		Privileges:         NewDefaultPrivilegeDescriptor(),
+		FormatVersion:      FamilyFormatVersion,
+	}
+	if err := desc.AllocateIDs(); err != nil {
+		t.Fatal(err)
+	}
+	oldPrimary := desc.PrimaryIndex
+
+	desc.AddMaterializedViewRefreshMutation("testuser")
+	mutationID, err := desc.FinalizeMutation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPrimaryID := desc.Mutations[0].GetMaterializedViewRefresh().NewPrimaryIndex.ID
+
+	// Complete the mutation, as the schema changer does.
+	desc.MakeMutationComplete(desc.Mutations[0])
+	desc.Mutations = desc.Mutations[1:]
+	if err := desc.ValidateTable(nil); err != nil {
+		t.Fatal(err)
+	}
+	if desc.PrimaryIndex.ID != newPrimaryID {
+		t.Fatalf("expected primary index %d, found %+v", newPrimaryID, desc.PrimaryIndex)
+	}
+
+	// The old primary index is dropped by a follow-up mutation.
+	if desc.NextMutationID != mutationID+2 {
+		t.Fatalf("expected next mutation ID %d, found %d", mutationID+2, desc.NextMutationID)
+	}
+	if len(desc.Mutations) != 1 {
+		t.Fatalf("expected 1 mutation, found %+v", desc.Mutations)
+	}
+	m := desc.Mutations[0]
+	if idx := m.GetIndex(); idx == nil || idx.ID != oldPrimary.ID ||
+		m.MutationID != mutationID+1 || m.Direction != DescriptorMutation_DROP {
+		t.Fatalf("unexpected mutation %+v", m)
+	}
+}
+
+func TestNotNullMutation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := TableDescriptor{
+		ParentID: keys.MinUserDescID,
+		ID:       keys.MinUserDescID + 1,
+		Name:     "foo",
+		Columns: []ColumnDescriptor{
+			{Name: "a", Type: ColumnType{SemanticType: ColumnType_INT}},
+			{Name: "b", Type: ColumnType{SemanticType: ColumnType_INT}, Nullable: true},
+		},
+		PrimaryIndex:  makeIndexDescriptor("primary", []string{"a"}),
+		Privileges:    NewDefaultPrivilegeDescriptor(),
+		FormatVersion: FamilyFormatVersion,
+	}
+	if err := desc.AllocateIDs(); err != nil {
+		t.Fatal(err)
+	}
+
+	desc.AddNotNullMutation(2)
+	if _, err := desc.FinalizeMutation(); err != nil {
+		t.Fatal(err)
+	}
+	if err := desc.ValidateTable(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !desc.ValidatingNotNull(2) || desc.ValidatingNotNull(1) {
+		t.Fatalf("unexpected pending NOT NULL constraints: %+v", desc.Mutations)
+	}
+	// The column stays nullable until the mutation completes.
+	if !desc.Columns[1].Nullable {
+		t.Fatalf("expected column b to be nullable")
+	}
+
+	// A rolled back constraint leaves the column nullable.
+	rolledBack := desc
+	rolledBack.Columns = append([]ColumnDescriptor(nil), desc.Columns...)
+	m := desc.Mutations[0]
+	m.Direction = DescriptorMutation_DROP
+	rolledBack.Mutations = []DescriptorMutation{m}
+	if rolledBack.ValidatingNotNull(2) {
+		t.Fatalf("expected no pending NOT NULL constraint: %+v", rolledBack.Mutations)
+	}
+	rolledBack.MakeMutationComplete(m)
+	if !rolledBack.Columns[1].Nullable {
+		t.Fatalf("expected column b to be nullable")
+	}
+
+	desc.MakeMutationComplete(desc.Mutations[0])
+	desc.Mutations = nil
+	if desc.Columns[1].Nullable {
+		t.Fatalf("expected column b to be non-nullable")
+	}
+	if desc.ValidatingNotNull(2) {
+		t.Fatalf("expected no pending NOT NULL constraint")
+	}
+}
+
+func TestTrigramIndex(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := TableDescriptor{
+		ParentID: keys.MinUserDescID,
+		ID:       keys.MinUserDescID + 1,
+		Name:     "foo",
+		Columns: []ColumnDescriptor{
+			{Name: "a", Type: ColumnType{SemanticType: ColumnType_INT}},
+			{Name: "b", Type: ColumnType{SemanticType: ColumnType_STRING}},
+		},
+		PrimaryIndex:  makeIndexDescriptor("primary", []string{"a"}),
+		Privileges:    NewDefaultPrivilegeDescriptor(),
+		FormatVersion: FamilyFormatVersion,
+	}
+
+	testCases := []struct {
+		column   string
+		opClass  string
+		idxType  IndexDescriptor_Type
+		expected string
+	}{
+		{"b", TrigramOpClass, IndexDescriptor_INVERTED, ""},
+		{"b", "", IndexDescriptor_INVERTED, "column b is of type STRING and thus is not indexable with an inverted index"},
+		{"a", TrigramOpClass, IndexDescriptor_INVERTED, `operator class "gin_trgm_ops" does not accept column a of type INT`},
+		{"b", TrigramOpClass, IndexDescriptor_FORWARD, `operator class "gin_trgm_ops" requires an inverted index`},
+		{"b", "foo_ops", IndexDescriptor_INVERTED, `operator class "foo_ops" does not exist`},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %s %s", tc.column, tc.opClass, tc.idxType), func(t *testing.T) {
+			idx := IndexDescriptor{Name: "idx", Type: tc.idxType}
+			err := idx.FillColumns(tree.IndexElemList{
+				{Column: tree.Name(tc.column), OpClass: tree.Name(tc.opClass)},
+			})
+			if err == nil {
+				err = desc.AddIndexMutation(idx, DescriptorMutation_ADD)
+			}
+			if !testutils.IsError(err, tc.expected) {
+				t.Fatalf("expected error %q, got %v", tc.expected, err)
+			}
+			if err == nil {
+				if s := idx.SQLString(&AnonymousTable); s != `INVERTED INDEX idx (b gin_trgm_ops)` {
+					t.Fatalf("unexpected SQL string: %s", s)
+				}
+			}
+		})
+	}
+}
+
 func TestKeysPerRow(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
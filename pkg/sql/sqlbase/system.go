@@ -184,6 +184,19 @@ CREATE TABLE system.role_members (
   INDEX ("role"),
   INDEX ("member")
 );`
+
+	// plan_baselines stores the plan baselines created with
+	// crdb_internal.create_plan_baseline(), keyed by the current database and
+	// the fingerprint of the statements they apply to.
+	PlanBaselinesTableSchema = `
+CREATE TABLE system.plan_baselines (
+  database    STRING NOT NULL,
+  fingerprint STRING NOT NULL,
+  statement   STRING NOT NULL,
+  "joinHints" INT[] NOT NULL,
+  PRIMARY KEY (database, fingerprint),
+  FAMILY (database, fingerprint, statement, "joinHints")
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -223,6 +236,7 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.TableStatisticsTableID: privilege.ReadWriteData,
 	keys.LocationsTableID:       privilege.ReadWriteData,
 	keys.RoleMembersTableID:     privilege.ReadWriteData,
+	keys.PlanBaselinesTableID:   privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -792,6 +806,42 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// PlanBaselinesTable is the descriptor for the plan_baselines table.
+	PlanBaselinesTable = TableDescriptor{
+		Name:     "plan_baselines",
+		ID:       keys.PlanBaselinesTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "database", ID: 1, Type: colTypeString},
+			{Name: "fingerprint", ID: 2, Type: colTypeString},
+			{Name: "statement", ID: 3, Type: colTypeString},
+			{Name: "joinHints", ID: 4, Type: colTypeIntArray},
+		},
+		NextColumnID: 5,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_database_fingerprint_statement_joinHints",
+				ID:          0,
+				ColumnNames: []string{"database", "fingerprint", "statement", "joinHints"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"database", "fingerprint"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.PlanBaselinesTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
@@ -15,6 +15,7 @@
 package sqlbase
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sort"
@@ -36,6 +37,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/cockroach/pkg/util/trigram"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -140,6 +143,7 @@ func PopulateTypeAttrs(base ColumnType, typ coltypes.T) (ColumnType, error) {
 	case *coltypes.TUUID:
 	case *coltypes.TIPAddr:
 	case *coltypes.TJSON:
+	case *coltypes.TTSVector, *coltypes.TTSQuery:
 	case *coltypes.TString:
 		base.Width = int32(t.N)
 	case *coltypes.TName:
@@ -722,6 +726,10 @@ func EncodeTableValue(
 			return nil, err
 		}
 		return encoding.EncodeJSONValue(appendTo, uint32(colID), encoded), nil
+	case *tree.DTSVector:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(t.Vector.String())), nil
+	case *tree.DTSQuery:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(t.Query.String())), nil
 	case *tree.DArray:
 		a, err := encodeArray(t, scratch)
 		if err != nil {
@@ -1369,7 +1377,7 @@ func DecodeTableKey(
 			rkey, r, err = encoding.DecodeUnsafeStringDescending(key, nil)
 		}
 		return a.NewDName(tree.DString(r)), rkey, err
-	case types.JSON:
+	case types.JSON, types.TSVector:
 		return tree.DNull, []byte{}, nil
 	case types.Bytes:
 		var r []byte
@@ -1686,6 +1694,26 @@ func decodeUntaggedDatum(a *DatumAlloc, t types.T, buf []byte) (tree.Datum, []by
 			return nil, b, err
 		}
 		return a.NewDJSON(tree.DJSON{JSON: j}), b, nil
+	case types.TSVector:
+		b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		v, err := tsearch.ParseVector(string(data))
+		if err != nil {
+			return nil, b, err
+		}
+		return &tree.DTSVector{Vector: v}, b, nil
+	case types.TSQuery:
+		b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		q, err := tsearch.ParseQuery(string(data))
+		if err != nil {
+			return nil, b, err
+		}
+		return &tree.DTSQuery{Query: q}, b, nil
 	case types.Oid:
 		b, data, err := encoding.DecodeUntaggedIntValue(buf)
 		return a.NewDOid(tree.MakeDOid(tree.DInt(data))), b, err
@@ -1762,7 +1790,9 @@ func EncodeInvertedIndexKeys(
 
 // EncodeInvertedIndexTableKeys encodes the paths in a JSON `val` and concatenates it with `inKey`and returns
 // a list of buffers per path. The encoded values is guaranteed to be lexicographically sortable, but not
-// guaranteed to be round-trippable during decoding.
+// guaranteed to be round-trippable during decoding. An array `val` gets one buffer per distinct non-NULL
+// element; an array without such elements gets none. A string `val`, which only trigram indexes accept,
+// gets one buffer per distinct trigram, and a TSVECTOR `val` gets one buffer per lexeme.
 func EncodeInvertedIndexTableKeys(val tree.Datum, inKey []byte) (key [][]byte, err error) {
 	if val == tree.DNull {
 		return [][]byte{encoding.EncodeNullAscending(inKey)}, nil
@@ -1770,8 +1800,75 @@ func EncodeInvertedIndexTableKeys(val tree.Datum, inKey []byte) (key [][]byte, e
 	switch t := tree.UnwrapDatum(nil, val).(type) {
 	case *tree.DJSON:
 		return json.EncodeInvertedIndexKeys(inKey, (t.JSON))
+	case *tree.DArray:
+		return encodeArrayInvertedIndexTableKeys(t, inKey)
+	case *tree.DString:
+		return encodeTrigramInvertedIndexTableKeys(string(*t), inKey)
+	case *tree.DTSVector:
+		return encodeTSVectorInvertedIndexTableKeys(t.Vector, inKey)
+	}
+	return nil, pgerror.NewError(pgerror.CodeInternalError,
+		"trying to apply inverted index to a type other than JSON, ARRAY, STRING or TSVECTOR")
+}
+
+// encodeTSVectorInvertedIndexTableKeys returns the keys of the inverted index
+// entries for a text search vector: inKey followed by the key encoding of the
+// word of a lexeme, for each lexeme of val. The positions and weights are not
+// indexed.
+func encodeTSVectorInvertedIndexTableKeys(
+	val tsearch.Vector, inKey []byte,
+) (key [][]byte, err error) {
+	outKeys := make([][]byte, len(val))
+	for i, l := range val {
+		outKey := make([]byte, len(inKey), len(inKey)+len(l.Word)+2)
+		copy(outKey, inKey)
+		outKeys[i] = encoding.EncodeStringAscending(outKey, l.Word)
+	}
+	return outKeys, nil
+}
+
+// encodeTrigramInvertedIndexTableKeys returns the keys of the trigram index
+// entries for a string: inKey followed by the key encoding of a trigram, for
+// each distinct trigram of val.
+func encodeTrigramInvertedIndexTableKeys(val string, inKey []byte) (key [][]byte, err error) {
+	trigrams := trigram.MakeTrigrams(val)
+	outKeys := make([][]byte, len(trigrams))
+	for i, t := range trigrams {
+		outKey := make([]byte, len(inKey), len(inKey)+len(t)+2)
+		copy(outKey, inKey)
+		outKeys[i] = encoding.EncodeStringAscending(outKey, t)
+	}
+	return outKeys, nil
+}
+
+// encodeArrayInvertedIndexTableKeys returns the keys of the inverted index
+// entries for an array: inKey followed by the key encoding of an element, for
+// each distinct non-NULL element of val. NULL elements are left out because
+// they never satisfy the containment and overlap operators.
+func encodeArrayInvertedIndexTableKeys(val *tree.DArray, inKey []byte) (key [][]byte, err error) {
+	outKeys := make([][]byte, 0, len(val.Array))
+	for _, d := range val.Array {
+		if d == tree.DNull {
+			continue
+		}
+		outKey := make([]byte, len(inKey), len(inKey)+8)
+		copy(outKey, inKey)
+		outKey, err = EncodeTableKey(outKey, d, encoding.Ascending)
+		if err != nil {
+			return nil, err
+		}
+		outKeys = append(outKeys, outKey)
+	}
+	// Keep a single key per distinct element.
+	sort.Slice(outKeys, func(i, j int) bool { return bytes.Compare(outKeys[i], outKeys[j]) < 0 })
+	n := 0
+	for i := range outKeys {
+		if n == 0 || !bytes.Equal(outKeys[n-1], outKeys[i]) {
+			outKeys[n] = outKeys[i]
+			n++
+		}
 	}
-	return nil, pgerror.NewError(pgerror.CodeInternalError, "trying to apply inverted index to non JSON type")
+	return outKeys[:n], nil
 }
 
 // EncodeSecondaryIndex encodes key/values for a secondary index. colMap maps
@@ -1889,6 +1986,13 @@ func EncodeSecondaryIndexes(
 		if err != nil {
 			return secondaryIndexEntries, err
 		}
+		if len(entries) == 0 {
+			// An inverted index has no entries for a row whose indexed value
+			// has no elements, like an empty array. A nil key means that there
+			// is nothing to write.
+			secondaryIndexEntries[i] = IndexEntry{}
+			continue
+		}
 		secondaryIndexEntries[i] = entries[0]
 
 		// This is specifically for inverted indexes which can have more than one entry
@@ -2033,6 +2137,16 @@ func MarshalColumnValue(col ColumnDescriptor, val tree.Datum) (roachpb.Value, er
 			r.SetBytes(data)
 			return r, nil
 		}
+	case ColumnType_TSVECTOR:
+		if v, ok := val.(*tree.DTSVector); ok {
+			r.SetBytes([]byte(v.Vector.String()))
+			return r, nil
+		}
+	case ColumnType_TSQUERY:
+		if v, ok := val.(*tree.DTSQuery); ok {
+			r.SetBytes([]byte(v.Query.String()))
+			return r, nil
+		}
 	case ColumnType_ARRAY:
 		if v, ok := val.(*tree.DArray); ok {
 			if err := checkElementType(v.ParamTyp, col.Type); err != nil {
@@ -2322,6 +2436,26 @@ func UnmarshalColumnValue(a *DatumAlloc, typ ColumnType, value roachpb.Value) (t
 			return nil, err
 		}
 		return a.NewDIPAddr(tree.DIPAddr{IPAddr: ipAddr}), nil
+	case ColumnType_TSVECTOR:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		vec, err := tsearch.ParseVector(string(v))
+		if err != nil {
+			return nil, err
+		}
+		return &tree.DTSVector{Vector: vec}, nil
+	case ColumnType_TSQUERY:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		q, err := tsearch.ParseQuery(string(v))
+		if err != nil {
+			return nil, err
+		}
+		return &tree.DTSQuery{Query: q}, nil
 	case ColumnType_NAME:
 		v, err := value.GetBytes()
 		if err != nil {
@@ -376,6 +376,89 @@ func TestArrayEncoding(t *testing.T) {
 	}
 }
 
+func TestEncodeArrayInvertedIndexKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	prefix := []byte{0x88}
+	elemKey := func(d tree.Datum) []byte {
+		key, err := EncodeTableKey(append([]byte(nil), prefix...), d, encoding.Ascending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	tests := []struct {
+		name     string
+		val      tree.Datum
+		expected [][]byte
+	}{
+		{"null", tree.DNull, [][]byte{encoding.EncodeNullAscending(append([]byte(nil), prefix...))}},
+		{"empty", &tree.DArray{ParamTyp: types.Int, Array: tree.Datums{}}, [][]byte{}},
+		{
+			"null elements",
+			&tree.DArray{ParamTyp: types.Int, Array: tree.Datums{tree.DNull}, HasNulls: true},
+			[][]byte{},
+		},
+		{
+			"duplicates",
+			&tree.DArray{
+				ParamTyp: types.Int,
+				Array:    tree.Datums{tree.NewDInt(3), tree.NewDInt(1), tree.DNull, tree.NewDInt(3)},
+				HasNulls: true,
+			},
+			[][]byte{elemKey(tree.NewDInt(1)), elemKey(tree.NewDInt(3))},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := EncodeInvertedIndexTableKeys(test.val, prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(keys, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, keys)
+			}
+		})
+	}
+}
+
+func TestEncodeTrigramInvertedIndexKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	prefix := []byte{0x88}
+	var expected [][]byte
+	for _, s := range []string{"  c", " ca", "at ", "cat"} {
+		expected = append(expected, encoding.EncodeStringAscending(append([]byte(nil), prefix...), s))
+	}
+	keys, err := EncodeInvertedIndexTableKeys(tree.NewDString("Cat cat"), prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+}
+
+func TestEncodeTSVectorInvertedIndexKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	prefix := []byte{0x88}
+	var expected [][]byte
+	for _, s := range []string{"cat", "fat", "rat"} {
+		expected = append(expected, encoding.EncodeStringAscending(append([]byte(nil), prefix...), s))
+	}
+	v, err := tree.ParseDTSVector("fat:1 cat:2 rat:3 fat:4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := EncodeInvertedIndexTableKeys(v, prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+}
+
 func BenchmarkArrayEncoding(b *testing.B) {
 	ary := tree.DArray{ParamTyp: types.Int, Array: tree.Datums{}}
 	for i := 0; i < 10000; i++ {
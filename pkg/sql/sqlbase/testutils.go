@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 	"unicode"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -114,6 +116,26 @@ func RandDatum(rng *rand.Rand, typ ColumnType, nullOk bool) tree.Datum {
 			return nil
 		}
 		return &tree.DJSON{JSON: j}
+	case ColumnType_TSVECTOR:
+		var lexemes []string
+		for i, n := 0, rng.Intn(5); i < n; i++ {
+			lexemes = append(lexemes, fmt.Sprintf("%s:%d", randLexeme(rng), 1+rng.Intn(100)))
+		}
+		v, err := tsearch.ParseVector(strings.Join(lexemes, " "))
+		if err != nil {
+			panic(err)
+		}
+		return &tree.DTSVector{Vector: v}
+	case ColumnType_TSQUERY:
+		var lexemes []string
+		for i, n := 0, rng.Intn(5); i < n; i++ {
+			lexemes = append(lexemes, randLexeme(rng))
+		}
+		q, err := tsearch.ParseQuery(strings.Join(lexemes, " | "))
+		if err != nil {
+			panic(err)
+		}
+		return &tree.DTSQuery{Query: q}
 	case ColumnType_TUPLE:
 		tuple := tree.DTuple{D: make(tree.Datums, len(typ.TupleContents))}
 		for i, internalType := range typ.TupleContents {
@@ -175,6 +197,16 @@ func RandDatum(rng *rand.Rand, typ ColumnType, nullOk bool) tree.Datum {
 	}
 }
 
+// randLexeme returns a random word of lowercase ASCII letters, for text
+// search vectors and queries.
+func randLexeme(rng *rand.Rand) string {
+	p := make([]byte, 1+rng.Intn(8))
+	for i := range p {
+		p[i] = byte('a' + rng.Intn(26))
+	}
+	return string(p)
+}
+
 var (
 	columnSemanticTypes []ColumnType_SemanticType
 	collationLocales    = [...]string{"da", "de", "en"}
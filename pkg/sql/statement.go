@@ -25,6 +25,10 @@ type Statement struct {
 	ExpectedTypes sqlbase.ResultColumns
 	AnonymizedStr string
 	queryID       ClusterWideID
+
+	// Prepared is non-nil if the statement is being executed from a prepared
+	// statement. The optimizer uses it to cache its plan across executions.
+	Prepared *PreparedStatement
 }
 
 func (s Statement) String() string {
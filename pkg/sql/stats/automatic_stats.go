@@ -0,0 +1,430 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// AutomaticStatisticsClusterMode controls the cluster setting for enabling
+// automatic table statistics collection.
+var AutomaticStatisticsClusterMode = settings.RegisterBoolSetting(
+	"sql.stats.automatic_collection.enabled",
+	"automatic statistics collection mode",
+	true,
+)
+
+// AutomaticStatisticsFractionStaleRows controls the cluster setting for
+// the target fraction of rows in a table that should be stale before
+// statistics on that table are refreshed.
+var AutomaticStatisticsFractionStaleRows = settings.RegisterNonNegativeFloatSetting(
+	"sql.stats.automatic_collection.fraction_stale_rows",
+	"target fraction of stale rows per table that will trigger a statistics refresh",
+	0.2,
+)
+
+// AutomaticStatisticsMinStaleRows controls the cluster setting for the target
+// number of rows that should be updated before a table is refreshed, in
+// addition to the fraction AutomaticStatisticsFractionStaleRows.
+var AutomaticStatisticsMinStaleRows = settings.RegisterNonNegativeIntSetting(
+	"sql.stats.automatic_collection.min_stale_rows",
+	"target minimum number of stale rows per table that will trigger a statistics refresh",
+	500,
+)
+
+// AutomaticStatisticsMaxFractionIdle controls the cluster setting for the
+// fraction of time that the sampler processors of an automatic statistics
+// job spend idle, in order to limit the impact on foreground traffic.
+var AutomaticStatisticsMaxFractionIdle = settings.RegisterValidatedFloatSetting(
+	"sql.stats.automatic_collection.max_fraction_idle",
+	"maximum fraction of time that automatic statistics sampler processors are idle",
+	0.9,
+	func(val float64) error {
+		if val < 0 || val >= 1 {
+			return errors.Errorf("sql.stats.automatic_collection.max_fraction_idle must be >= 0 and < 1 but found: %v", val)
+		}
+		return nil
+	},
+)
+
+// AutoStatsName is the name to use for statistics created automatically.
+const AutoStatsName = "__auto__"
+
+// DefaultRefreshInterval is the frequency at which the Refresher checks
+// whether the accumulated mutation counts warrant a statistics refresh.
+const DefaultRefreshInterval = time.Minute
+
+// maxAutoStatsColumns is the maximum number of columns of a table for which
+// statistics are collected automatically.
+const maxAutoStatsColumns = 100
+
+// maxAutoStatsMultiColumnStats is the maximum number of multi-column
+// statistics (on index prefixes) collected automatically for a table.
+const maxAutoStatsMultiColumnStats = 20
+
+// refreshChanBufferLen is the length of the buffered channel used by the
+// Refresher to receive mutation notifications. If the buffer is full,
+// notifications are dropped.
+const refreshChanBufferLen = 256
+
+// Refresher is responsible for automatically refreshing the table statistics
+// that are used by the cost-based optimizer. The statistics of a table become
+// stale as its data changes, so the Refresher tracks the number of rows
+// inserted, updated or deleted in each table (as reported by NotifyMutation)
+// and starts a statistics job once the number of stale rows exceeds the
+// fraction sql.stats.automatic_collection.fraction_stale_rows of the row count
+// of the most recent statistics on the table, plus
+// sql.stats.automatic_collection.min_stale_rows.
+//
+// Each node runs its own Refresher, which only sees the mutations performed
+// by that node. Statistics jobs are run one at a time, in a separate task, so
+// that the Refresher keeps accumulating mutation counts while a job runs.
+type Refresher struct {
+	st          *cluster.Settings
+	db          *client.DB
+	cache       *TableStatisticsCache
+	jobRegistry *jobs.Registry
+
+	// mutations is the buffered channel used to pass messages containing
+	// metadata about SQL mutations to the background Refresher thread.
+	mutations chan mutation
+
+	// mutationCounts contains aggregated mutation counts for each table that
+	// have yet to trigger a statistics refresh. It is only accessed by the
+	// background Refresher thread.
+	mutationCounts map[sqlbase.ID]int64
+}
+
+// refreshResult is the outcome of an attempt to refresh the statistics of a
+// table, passed from the refresh task to the background Refresher thread.
+type refreshResult struct {
+	tableID sqlbase.ID
+	// rowsAffected is the mutation count of the table when the refresh was
+	// attempted.
+	rowsAffected int64
+	refreshed    bool
+	err          error
+}
+
+// errTableDropped is returned by maybeRefreshStats if the table is being
+// dropped. The mutation counts of such tables are discarded.
+var errTableDropped = errors.New("table is being dropped")
+
+// mutation contains metadata about a SQL mutation and is the message passed to
+// the background refresher thread to (possibly) trigger a statistics refresh.
+type mutation struct {
+	tableID      sqlbase.ID
+	rowsAffected int64
+}
+
+// MakeRefresher creates a new Refresher.
+func MakeRefresher(
+	st *cluster.Settings, db *client.DB, cache *TableStatisticsCache, jobRegistry *jobs.Registry,
+) *Refresher {
+	return &Refresher{
+		st:             st,
+		db:             db,
+		cache:          cache,
+		jobRegistry:    jobRegistry,
+		mutations:      make(chan mutation, refreshChanBufferLen),
+		mutationCounts: make(map[sqlbase.ID]int64),
+	}
+}
+
+// Start starts the background Refresher thread, which accumulates the
+// mutation counts and, every refreshInterval, refreshes the statistics of the
+// tables that have accumulated enough stale rows.
+func (r *Refresher) Start(
+	ctx context.Context, stopper *stop.Stopper, refreshInterval time.Duration,
+) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		timer.Reset(refreshInterval)
+		// results is non-nil while a refresh task is running. The task closes
+		// it when it is done.
+		var results chan refreshResult
+		for {
+			select {
+			case <-timer.C:
+				timer.Read = true
+				timer.Reset(refreshInterval)
+				if results != nil || len(r.mutationCounts) == 0 {
+					// The previous refresh task is still running.
+					continue
+				}
+				results = r.startRefresh(ctx, stopper)
+
+			case res, ok := <-results:
+				if !ok {
+					results = nil
+					continue
+				}
+				r.applyRefreshResult(ctx, res)
+
+			case mut := <-r.mutations:
+				r.mutationCounts[mut.tableID] += mut.rowsAffected
+
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// startRefresh starts a task that refreshes, one at a time, the statistics of
+// the tables that have accumulated enough stale rows. The outcome of each
+// attempt is sent on the returned channel, which is closed when the task is
+// done. It returns nil if the task could not be started.
+func (r *Refresher) startRefresh(ctx context.Context, stopper *stop.Stopper) chan refreshResult {
+	mutationCounts := make(map[sqlbase.ID]int64, len(r.mutationCounts))
+	for tableID, rowsAffected := range r.mutationCounts {
+		mutationCounts[tableID] = rowsAffected
+	}
+	// The channel can hold all the results, so the task never blocks on it.
+	results := make(chan refreshResult, len(mutationCounts))
+	if err := stopper.RunAsyncTask(ctx, "stats.Refresher: refresh", func(ctx context.Context) {
+		defer close(results)
+		for tableID, rowsAffected := range mutationCounts {
+			// Check the cluster setting before each refresh in case it was
+			// disabled recently.
+			if !AutomaticStatisticsClusterMode.Get(&r.st.SV) {
+				return
+			}
+			refreshed, err := r.maybeRefreshStats(ctx, stopper, tableID, rowsAffected)
+			results <- refreshResult{
+				tableID:      tableID,
+				rowsAffected: rowsAffected,
+				refreshed:    refreshed,
+				err:          err,
+			}
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			default:
+			}
+		}
+	}); err != nil {
+		return nil
+	}
+	return results
+}
+
+// applyRefreshResult updates the mutation counts after an attempt to refresh
+// the statistics of a table. If the statistics were refreshed, the mutations
+// counted before the refresh started no longer count as stale rows; mutations
+// that were counted since are kept. After an error, all the counts are kept so
+// that the refresh is retried, unless the table is being dropped.
+func (r *Refresher) applyRefreshResult(ctx context.Context, res refreshResult) {
+	switch {
+	case res.err == errTableDropped:
+		delete(r.mutationCounts, res.tableID)
+
+	case res.err != nil:
+		log.Warningf(ctx, "failed to refresh statistics for table %d: %v", res.tableID, res.err)
+
+	case res.refreshed:
+		if r.mutationCounts[res.tableID] -= res.rowsAffected; r.mutationCounts[res.tableID] <= 0 {
+			delete(r.mutationCounts, res.tableID)
+		}
+	}
+}
+
+// NotifyMutation is called by SQL mutation operations to signal to the
+// Refresher that a table has been mutated. It never blocks: if the Refresher
+// is falling behind, the notification is dropped.
+func (r *Refresher) NotifyMutation(tableID sqlbase.ID, rowsAffected int64) {
+	if r == nil || rowsAffected == 0 {
+		return
+	}
+	if !AutomaticStatisticsClusterMode.Get(&r.st.SV) {
+		return
+	}
+	if sqlbase.IsReservedID(tableID) || tableID == keys.VirtualDescriptorID {
+		// Don't collect statistics on system or virtual tables.
+		return
+	}
+	select {
+	case r.mutations <- mutation{tableID: tableID, rowsAffected: rowsAffected}:
+	default:
+	}
+}
+
+// staleRowsThreshold returns the number of rows that must be modified in a
+// table whose most recent statistics have the given row count before the
+// statistics are refreshed.
+func staleRowsThreshold(sv *settings.Values, rowCount uint64) float64 {
+	return float64(rowCount)*AutomaticStatisticsFractionStaleRows.Get(sv) +
+		float64(AutomaticStatisticsMinStaleRows.Get(sv))
+}
+
+// maybeRefreshStats starts a statistics job for the given table if
+// rowsAffected exceeds the stale rows threshold, and waits for it to finish.
+// It returns true if the statistics were refreshed.
+func (r *Refresher) maybeRefreshStats(
+	ctx context.Context, stopper *stop.Stopper, tableID sqlbase.ID, rowsAffected int64,
+) (bool, error) {
+	tableStats, err := r.cache.GetTableStats(ctx, tableID)
+	if err != nil {
+		return false, err
+	}
+	var rowCount uint64
+	if len(tableStats) > 0 {
+		// The statistics are ordered by their creation time (most recent first).
+		rowCount = tableStats[0].RowCount
+	}
+	if float64(rowsAffected) < staleRowsThreshold(&r.st.SV, rowCount) {
+		return false, nil
+	}
+
+	var desc *sqlbase.TableDescriptor
+	if err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		desc, err = sqlbase.GetTableDescFromID(ctx, txn, tableID)
+		return err
+	}); err != nil {
+		return false, err
+	}
+	if desc.Dropped() {
+		return false, errTableDropped
+	}
+
+	columnLists := autoStatsColumnLists(desc)
+	if len(columnLists) == 0 {
+		return false, nil
+	}
+	record := jobs.Record{
+		Description:   fmt.Sprintf("automatic statistics collection on table %s", desc.Name),
+		Username:      security.NodeUser,
+		DescriptorIDs: sqlbase.IDs{tableID},
+		Details: jobspb.CreateStatsDetails{
+			Name:            AutoStatsName,
+			TableID:         tableID,
+			ColumnLists:     columnLists,
+			MaxFractionIdle: AutomaticStatisticsMaxFractionIdle.Get(&r.st.SV),
+		},
+		Progress: jobspb.CreateStatsProgress{},
+	}
+	_, errCh, err := r.jobRegistry.StartJob(ctx, nil /* resultsCh */, record)
+	if err != nil {
+		return false, err
+	}
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	case <-stopper.ShouldQuiesce():
+		return false, nil
+	}
+}
+
+// autoStatsColumnLists returns the columns on which statistics are collected
+// automatically: one single-column statistic for each column of the table
+// whose values can be key-encoded, starting with the columns of the primary
+// index and the secondary indexes, up to maxAutoStatsColumns. In addition, a
+// multi-column statistic is collected for each prefix of two or more columns
+// of an index, up to maxAutoStatsMultiColumnStats. These let the optimizer
+// estimate the selectivity of predicates on correlated columns, such as
+// (country, city).
+func autoStatsColumnLists(desc *sqlbase.TableDescriptor) []jobspb.CreateStatsDetails_ColList {
+	var columnLists []jobspb.CreateStatsDetails_ColList
+	keyEncodable := func(id sqlbase.ColumnID) bool {
+		col, err := desc.FindActiveColumnByID(id)
+		return err == nil && !sqlbase.MustBeValueEncoded(col.Type.SemanticType)
+	}
+
+	numSingle := 0
+	seen := make(map[sqlbase.ColumnID]struct{})
+	add := func(id sqlbase.ColumnID) {
+		if _, ok := seen[id]; ok || numSingle >= maxAutoStatsColumns {
+			return
+		}
+		seen[id] = struct{}{}
+		if !keyEncodable(id) {
+			return
+		}
+		columnLists = append(columnLists, jobspb.CreateStatsDetails_ColList{
+			IDs: []sqlbase.ColumnID{id},
+		})
+		numSingle++
+	}
+	for _, id := range desc.PrimaryIndex.ColumnIDs {
+		add(id)
+	}
+	for i := range desc.Indexes {
+		for _, id := range desc.Indexes[i].ColumnIDs {
+			add(id)
+		}
+	}
+	for i := range desc.Columns {
+		add(desc.Columns[i].ID)
+	}
+
+	numMulti := 0
+	var seenSets []util.FastIntSet
+	addPrefixes := func(idx *sqlbase.IndexDescriptor) {
+		var set util.FastIntSet
+		for i, id := range idx.ColumnIDs {
+			if numMulti >= maxAutoStatsMultiColumnStats || !keyEncodable(id) {
+				return
+			}
+			set.Add(int(id))
+			if i == 0 || (idx.Unique && i == len(idx.ColumnIDs)-1) {
+				// The distinct count of all the columns of a unique index is the
+				// row count, so there is no need to collect it.
+				continue
+			}
+			dup := false
+			for j := range seenSets {
+				if seenSets[j].Equals(set) {
+					dup = true
+					break
+				}
+			}
+			if dup {
+				continue
+			}
+			seenSets = append(seenSets, set.Copy())
+			columnLists = append(columnLists, jobspb.CreateStatsDetails_ColList{
+				IDs: append([]sqlbase.ColumnID(nil), idx.ColumnIDs[:i+1]...),
+			})
+			numMulti++
+		}
+	}
+	addPrefixes(&desc.PrimaryIndex)
+	for i := range desc.Indexes {
+		addPrefixes(&desc.Indexes[i])
+	}
+	return columnLists
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestStaleRowsThreshold(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	testData := []struct {
+		fraction  float64
+		minStale  int64
+		rowCount  uint64
+		threshold float64
+	}{
+		{fraction: 0.2, minStale: 500, rowCount: 0, threshold: 500},
+		{fraction: 0.2, minStale: 500, rowCount: 10000, threshold: 2500},
+		{fraction: 0, minStale: 10, rowCount: 10000, threshold: 10},
+		{fraction: 1, minStale: 0, rowCount: 10000, threshold: 10000},
+	}
+	for _, tc := range testData {
+		AutomaticStatisticsFractionStaleRows.Override(&st.SV, tc.fraction)
+		AutomaticStatisticsMinStaleRows.Override(&st.SV, tc.minStale)
+		if res := staleRowsThreshold(&st.SV, tc.rowCount); res != tc.threshold {
+			t.Errorf("fraction=%g minStale=%d rowCount=%d: expected threshold %g, got %g",
+				tc.fraction, tc.minStale, tc.rowCount, tc.threshold, res)
+		}
+	}
+}
+
+func TestNotifyMutation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	r := MakeRefresher(st, nil /* db */, nil /* cache */, nil /* jobRegistry */)
+
+	r.NotifyMutation(sqlbase.ID(100), 10)
+	// Mutations of system and virtual tables are ignored.
+	r.NotifyMutation(keys.JobsTableID, 10)
+	r.NotifyMutation(keys.VirtualDescriptorID, 10)
+	// Mutations are ignored when automatic statistics are disabled.
+	AutomaticStatisticsClusterMode.Override(&st.SV, false)
+	r.NotifyMutation(sqlbase.ID(101), 10)
+
+	if len(r.mutations) != 1 {
+		t.Fatalf("expected 1 mutation, got %d", len(r.mutations))
+	}
+	if m := <-r.mutations; m.tableID != 100 || m.rowsAffected != 10 {
+		t.Fatalf("unexpected mutation %+v", m)
+	}
+
+	// Notifications never block, even if the buffer is full.
+	AutomaticStatisticsClusterMode.Override(&st.SV, true)
+	for i := 0; i < refreshChanBufferLen+10; i++ {
+		r.NotifyMutation(sqlbase.ID(100), 1)
+	}
+	if len(r.mutations) != refreshChanBufferLen {
+		t.Fatalf("expected %d mutations, got %d", refreshChanBufferLen, len(r.mutations))
+	}
+
+	// A nil Refresher ignores all notifications.
+	var nilRefresher *Refresher
+	nilRefresher.NotifyMutation(sqlbase.ID(100), 10)
+}
+
+func TestApplyRefreshResult(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	r := MakeRefresher(st, nil /* db */, nil /* cache */, nil /* jobRegistry */)
+	ctx := context.Background()
+
+	r.mutationCounts[100] = 15
+	r.mutationCounts[101] = 10
+	r.mutationCounts[102] = 10
+	r.mutationCounts[103] = 10
+
+	// Mutations counted while the refresh was running are kept.
+	r.applyRefreshResult(ctx, refreshResult{tableID: 100, rowsAffected: 10, refreshed: true})
+	r.applyRefreshResult(ctx, refreshResult{tableID: 101, rowsAffected: 10, refreshed: true})
+	// Counts are kept after a transient error, so the refresh is retried.
+	r.applyRefreshResult(ctx, refreshResult{tableID: 102, rowsAffected: 10, err: errors.New("boom")})
+	// Counts of dropped tables are discarded.
+	r.applyRefreshResult(ctx, refreshResult{tableID: 103, rowsAffected: 10, err: errTableDropped})
+
+	expected := map[sqlbase.ID]int64{100: 5, 102: 10}
+	if !reflect.DeepEqual(r.mutationCounts, expected) {
+		t.Fatalf("expected mutation counts %v, got %v", expected, r.mutationCounts)
+	}
+}
+
+func TestAutoStatsColumnLists(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	intType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+	desc := sqlbase.TableDescriptor{
+		ID:   100,
+		Name: "t",
+		Columns: []sqlbase.ColumnDescriptor{
+			{Name: "a", ID: 1, Type: intType},
+			{Name: "b", ID: 2, Type: intType},
+			{Name: "j", ID: 3, Type: sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_JSON}},
+			{Name: "c", ID: 4, Type: intType},
+		},
+		PrimaryIndex: sqlbase.IndexDescriptor{
+			Name: "primary", ID: 1, ColumnIDs: []sqlbase.ColumnID{2},
+		},
+		Indexes: []sqlbase.IndexDescriptor{
+			{Name: "c_a_idx", ID: 2, ColumnIDs: []sqlbase.ColumnID{4, 1}},
+			{Name: "a_b_key", ID: 3, Unique: true, ColumnIDs: []sqlbase.ColumnID{1, 2}},
+			{Name: "c_a_b_idx", ID: 4, ColumnIDs: []sqlbase.ColumnID{4, 1, 2}},
+		},
+	}
+
+	// Index columns come first, and columns that cannot be key-encoded are
+	// skipped. They are followed by the index prefixes of two or more columns,
+	// except for duplicates and the full column set of unique indexes.
+	var res [][]sqlbase.ColumnID
+	for _, cols := range autoStatsColumnLists(&desc) {
+		res = append(res, cols.IDs)
+	}
+	expected := [][]sqlbase.ColumnID{{2}, {4}, {1}, {4, 1}, {4, 1, 2}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("expected columns %v, got %v", expected, res)
+	}
+}
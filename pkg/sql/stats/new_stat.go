@@ -26,8 +26,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
+// keepCount is the number of statistics on a given set of columns that are
+// kept in system.table_statistics (including the most recent one). Older
+// statistics are deleted by InsertNewStat.
+const keepCount = 4
+
 // InsertNewStat inserts a new statistic in the system table and updates the
-// gossip key to notify the stat caches.
+// gossip key to notify the stat caches. Older statistics on the same columns
+// beyond the most recent keepCount are deleted.
 func InsertNewStat(
 	ctx context.Context,
 	g *gossip.Gossip,
@@ -81,7 +87,24 @@ func InsertNewStat(
 		return err
 	}
 
-	// TODO(radu): we need to clear out old stats that are superseded.
+	// Delete old statistics on the same columns that have been superseded.
+	if _, err := executor.Exec(
+		ctx, "delete-statistics", txn,
+		`DELETE FROM system.table_statistics
+				WHERE "tableID" = $1
+				AND "columnIDs" = $2
+				AND "statisticID" NOT IN (
+					SELECT "statisticID" FROM system.table_statistics
+					WHERE "tableID" = $1 AND "columnIDs" = $2
+					ORDER BY "createdAt" DESC
+					LIMIT $3
+				)`,
+		tableID,
+		columnIDsVal,
+		keepCount,
+	); err != nil {
+		return err
+	}
 
 	// TODO(radu): perhaps use a TTL here to avoid having a key per table floating
 	// around forever (we would need the stat cache to evict old entries
@@ -93,7 +93,7 @@ func GetKeysForTableDescriptor(
 	tableDesc *sqlbase.TableDescriptor,
 ) (zoneKey roachpb.Key, nameKey roachpb.Key, descKey roachpb.Key) {
 	zoneKey = config.MakeZoneKey(uint32(tableDesc.ID))
-	nameKey = sqlbase.MakeNameMetadataKey(tableDesc.ParentID, tableDesc.GetName())
+	nameKey = sqlbase.MakeNameMetadataKey(tableDesc.NamespaceParentID(), tableDesc.GetName())
 	descKey = sqlbase.MakeDescMetadataKey(tableDesc.ID)
 	return
 }
@@ -177,6 +177,10 @@ type TableCollection struct {
 	// return different values, such as when the txn timestamp changes or when
 	// new descriptors are written in the txn.
 	allDescriptors []sqlbase.DescriptorProto
+
+	// temporarySchemas tracks the temporary schemas of the session owning
+	// this collection. Unlike the other fields, it outlives transactions.
+	temporarySchemas temporarySchemaState
 }
 
 type dbCacheSubscriber interface {
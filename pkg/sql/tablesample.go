@@ -0,0 +1,204 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// tableSample is the analyzed form of a TABLESAMPLE clause, attached to the
+// scanNode it applies to.
+//
+// BERNOULLI sampling is performed by the scan itself (or by the TableReaders
+// planned for it), which keeps each row with the sampling probability.
+// SYSTEM sampling is performed on the spans of the scan: they are broken up at
+// range boundaries and each range is kept with the sampling probability, so
+// that the ranges that are not selected are not read at all.
+type tableSample struct {
+	method tree.TableSampleMethod
+
+	// The expressions are evaluated when the scan is executed (like LIMIT), so
+	// that they can contain placeholders. seedExpr is nil if REPEATABLE was
+	// not specified, in which case a random seed is used.
+	percentExpr tree.TypedExpr
+	seedExpr    tree.TypedExpr
+}
+
+// analyzeTableSample type checks the expressions of a TABLESAMPLE clause.
+func (p *planner) analyzeTableSample(
+	ctx context.Context, ts *tree.TableSample,
+) (*tableSample, error) {
+	// We need to save and restore the previous value of the field in
+	// semaCtx in case we are recursively called within a subquery
+	// context.
+	scalarProps := &p.semaCtx.Properties
+	defer scalarProps.Restore(*scalarProps)
+	scalarProps.Require("TABLESAMPLE", tree.RejectSpecial)
+
+	res := &tableSample{method: ts.Method}
+	var err error
+	res.percentExpr, err = p.analyzeExpr(
+		ctx, ts.Percent, nil, tree.IndexedVarHelper{}, types.Float, true, "TABLESAMPLE",
+	)
+	if err != nil {
+		return nil, err
+	}
+	if ts.Seed != nil {
+		res.seedExpr, err = p.analyzeExpr(
+			ctx, ts.Seed, nil, tree.IndexedVarHelper{}, types.Float, true, "REPEATABLE",
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// eval returns the sampling probability (between 0 and 1) and the seed to use
+// for the sample.
+func (s *tableSample) eval(evalCtx *tree.EvalContext) (fraction float64, seed int64, _ error) {
+	d, err := s.percentExpr.Eval(evalCtx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if d == tree.DNull {
+		return 0, 0, pgerror.NewError(pgerror.CodeInvalidTablesampleArgumentError,
+			"TABLESAMPLE parameter cannot be null")
+	}
+	percent := float64(*d.(*tree.DFloat))
+	if !(percent >= 0 && percent <= 100) {
+		return 0, 0, pgerror.NewError(pgerror.CodeInvalidTablesampleArgumentError,
+			"sample percentage must be between 0 and 100")
+	}
+
+	if s.seedExpr == nil {
+		return percent / 100, rand.Int63(), nil
+	}
+	d, err = s.seedExpr.Eval(evalCtx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if d == tree.DNull {
+		return 0, 0, pgerror.NewError(pgerror.CodeInvalidTablesampleRepeatError,
+			"TABLESAMPLE REPEATABLE parameter cannot be null")
+	}
+	// Like in Postgres, the seed can be any floating point value.
+	return percent / 100, int64(math.Float64bits(float64(*d.(*tree.DFloat)))), nil
+}
+
+// sampleSpans implements TABLESAMPLE SYSTEM: it breaks up the given spans at
+// range boundaries and keeps each piece with the given probability. The choice
+// only depends on the seed and on the start key of the range, so the same seed
+// selects the same ranges as long as the range boundaries don't change.
+func sampleSpans(
+	ctx context.Context,
+	it distsqlplan.SpanResolverIterator,
+	spans roachpb.Spans,
+	fraction float64,
+	seed int64,
+) (roachpb.Spans, error) {
+	var res roachpb.Spans
+	for _, span := range spans {
+		var rspan roachpb.RSpan
+		var err error
+		if rspan.Key, err = keys.Addr(span.Key); err != nil {
+			return nil, err
+		}
+		if rspan.EndKey, err = keys.Addr(span.EndKey); err != nil {
+			return nil, err
+		}
+
+		// lastKey maintains the EndKey of the last piece of `span`.
+		lastKey := rspan.Key
+		for it.Seek(ctx, span, kv.Ascending); ; it.Next(ctx) {
+			if !it.Valid() {
+				return nil, it.Error()
+			}
+			desc := it.Desc()
+
+			// Limit the end key to the end of the span we are sampling.
+			endKey := desc.EndKey
+			if rspan.EndKey.Less(endKey) {
+				endKey = rspan.EndKey
+			}
+
+			if sampleRange(desc.StartKey, fraction, seed) {
+				res = append(res, roachpb.Span{
+					Key:    lastKey.AsRawKey(),
+					EndKey: endKey.AsRawKey(),
+				})
+			}
+
+			if !endKey.Less(rspan.EndKey) {
+				// Done.
+				break
+			}
+			lastKey = endKey
+		}
+	}
+	return res, nil
+}
+
+// sampleRange returns whether the range starting at the given key is part of
+// a TABLESAMPLE SYSTEM sample.
+func sampleRange(startKey roachpb.RKey, fraction float64, seed int64) bool {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(seed))
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write(startKey)
+	// The high bits of FNV are not well mixed for short keys that only differ
+	// in their last bytes (which is typical for range boundaries); apply the
+	// SplitMix64 finalizer before using them.
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x ^= x >> 31
+	// Use the top 53 bits to get a uniform value in [0, 1).
+	return float64(x>>11)/(1<<53) < fraction
+}
+
+// sampledSpans returns the spans that the scanNode reads: these are the spans
+// of the node, unless it uses TABLESAMPLE SYSTEM. If the scan uses TABLESAMPLE
+// BERNOULLI, the row sampling to perform is also returned.
+func (n *scanNode) sampledSpans(
+	ctx context.Context, evalCtx *tree.EvalContext, it distsqlplan.SpanResolverIterator,
+) (roachpb.Spans, *distsqlrun.BernoulliSampleSpec, error) {
+	if n.sample == nil {
+		return n.spans, nil, nil
+	}
+	fraction, seed, err := n.sample.eval(evalCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n.sample.method == tree.TableSampleBernoulli {
+		return n.spans, &distsqlrun.BernoulliSampleSpec{Fraction: fraction, Seed: seed}, nil
+	}
+	spans, err := sampleSpans(ctx, it, n.spans, fraction, seed)
+	return spans, nil, err
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// fixedRangesIterator is a distsqlplan.SpanResolverIterator over a fixed set of
+// ranges, given by their split keys.
+type fixedRangesIterator struct {
+	splits []roachpb.RKey
+	idx    int
+	span   roachpb.RSpan
+}
+
+func (it *fixedRangesIterator) Seek(ctx context.Context, span roachpb.Span, _ kv.ScanDirection) {
+	it.span = roachpb.RSpan{Key: roachpb.RKey(span.Key), EndKey: roachpb.RKey(span.EndKey)}
+	for it.idx = 0; it.idx < len(it.splits) && !it.span.Key.Less(it.splits[it.idx]); it.idx++ {
+	}
+}
+
+func (it *fixedRangesIterator) NeedAnother() bool {
+	return it.idx < len(it.splits) && it.splits[it.idx].Less(it.span.EndKey)
+}
+
+func (it *fixedRangesIterator) Next(ctx context.Context) { it.idx++ }
+func (it *fixedRangesIterator) Valid() bool              { return true }
+func (it *fixedRangesIterator) Error() error             { return nil }
+
+func (it *fixedRangesIterator) Desc() roachpb.RangeDescriptor {
+	desc := roachpb.RangeDescriptor{StartKey: roachpb.RKeyMin, EndKey: roachpb.RKeyMax}
+	if it.idx > 0 {
+		desc.StartKey = it.splits[it.idx-1]
+	}
+	if it.idx < len(it.splits) {
+		desc.EndKey = it.splits[it.idx]
+	}
+	return desc
+}
+
+func (it *fixedRangesIterator) ReplicaInfo(ctx context.Context) (kv.ReplicaInfo, error) {
+	return kv.ReplicaInfo{}, nil
+}
+
+func TestSampleSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	it := &fixedRangesIterator{}
+	for _, k := range []string{"b", "d", "f", "h", "j", "l", "n", "p", "r", "t"} {
+		it.splits = append(it.splits, roachpb.RKey(k))
+	}
+	spans := roachpb.Spans{
+		{Key: roachpb.Key("a"), EndKey: roachpb.Key("c")},
+		{Key: roachpb.Key("e"), EndKey: roachpb.Key("z")},
+	}
+
+	// With a probability of 1, the spans are broken up at range boundaries.
+	all, err := sampleSpans(ctx, it, spans, 1, 0 /* seed */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := roachpb.Spans{
+		{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")},
+		{Key: roachpb.Key("b"), EndKey: roachpb.Key("c")},
+		{Key: roachpb.Key("e"), EndKey: roachpb.Key("f")},
+		{Key: roachpb.Key("f"), EndKey: roachpb.Key("h")},
+		{Key: roachpb.Key("h"), EndKey: roachpb.Key("j")},
+		{Key: roachpb.Key("j"), EndKey: roachpb.Key("l")},
+		{Key: roachpb.Key("l"), EndKey: roachpb.Key("n")},
+		{Key: roachpb.Key("n"), EndKey: roachpb.Key("p")},
+		{Key: roachpb.Key("p"), EndKey: roachpb.Key("r")},
+		{Key: roachpb.Key("r"), EndKey: roachpb.Key("t")},
+		{Key: roachpb.Key("t"), EndKey: roachpb.Key("z")},
+	}
+	if !reflect.DeepEqual(all, expected) {
+		t.Fatalf("expected %s, got %s", expected, all)
+	}
+
+	none, err := sampleSpans(ctx, it, spans, 0, 0 /* seed */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(none) != 0 {
+		t.Fatalf("expected no spans, got %s", none)
+	}
+
+	// The sample only depends on the seed.
+	partial := false
+	for seed := int64(0); seed < 10; seed++ {
+		first, err := sampleSpans(ctx, it, spans, 0.5, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := sampleSpans(ctx, it, spans, 0.5, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Fatalf("seed %d: different samples %s and %s", seed, first, second)
+		}
+		// Every piece is one of the range-aligned pieces.
+		for _, s := range first {
+			found := false
+			for _, e := range expected {
+				found = found || s.EqualValue(e)
+			}
+			if !found {
+				t.Fatalf("seed %d: unexpected span %s", seed, s)
+			}
+		}
+		partial = partial || (len(first) > 0 && len(first) < len(expected))
+	}
+	if !partial {
+		t.Fatal("expected some samples to contain only part of the ranges")
+	}
+}
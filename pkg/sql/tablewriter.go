@@ -111,6 +111,10 @@ type extendedTableWriter interface {
 	// batch size because the actual KV batch will be constructed only
 	// during the call to atBatchEnd().
 	curBatchSize() int
+
+	// rowsWritten returns the number of rows written by the tableWriter in
+	// batches that have been flushed or finalized.
+	rowsWritten() int64
 }
 
 var _ extendedTableWriter = (*tableUpdater)(nil)
@@ -126,6 +130,10 @@ type tableWriterBase struct {
 	b *client.Batch
 	// batchSize is the current batch size (when known).
 	batchSize int
+	// numRowsWritten is the number of rows written in batches that have been
+	// flushed or finalized. It is reported to the automatic statistics
+	// refresher.
+	numRowsWritten int64
 }
 
 func (tb *tableWriterBase) init(txn *client.Txn) {
@@ -142,6 +150,7 @@ func (tb *tableWriterBase) flushAndStartNewBatch(
 		return sqlbase.ConvertBatchError(ctx, tableDesc, tb.b)
 	}
 	tb.b = tb.txn.NewBatch()
+	tb.numRowsWritten += int64(tb.batchSize)
 	tb.batchSize = 0
 	return nil
 }
@@ -149,6 +158,9 @@ func (tb *tableWriterBase) flushAndStartNewBatch(
 // curBatchSize shares the common curBatchSize() code between extendedTableWriters().
 func (tb *tableWriterBase) curBatchSize() int { return tb.batchSize }
 
+// rowsWritten shares the common rowsWritten() code between extendedTableWriters.
+func (tb *tableWriterBase) rowsWritten() int64 { return tb.numRowsWritten }
+
 // finalize shares the common finalize code between extendedTableWriters.
 func (tb *tableWriterBase) finalize(
 	ctx context.Context, autoCommit autoCommitOpt, tableDesc *sqlbase.TableDescriptor,
@@ -165,6 +177,7 @@ func (tb *tableWriterBase) finalize(
 	if err != nil {
 		return sqlbase.ConvertBatchError(ctx, tableDesc, tb.b)
 	}
+	tb.numRowsWritten += int64(tb.batchSize)
 	return nil
 }
 
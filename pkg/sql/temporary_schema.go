@@ -0,0 +1,443 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
+	"github.com/pkg/errors"
+)
+
+// Temporary tables live in a per-session schema named after the session,
+// pg_temp_<hi>_<lo>, which can also be referred to as pg_temp by the
+// session that owns it. The schema is not visible to other sessions.
+//
+// There are no schema descriptors: a session allocates a descriptor ID for
+// its temporary schema in a database the first time it creates a temporary
+// table there, and registers the names of its temporary tables in
+// system.namespace under that ID instead of the ID of the database. The
+// table descriptors record the schema ID and the ID of the owning session,
+// which is all that is needed to find the tables again.
+//
+// Temporary tables are dropped when their session ends. Tables left behind
+// by sessions that did not end cleanly, e.g. because their node crashed,
+// are dropped by the TemporaryObjectCleaner.
+
+const temporarySchemaPrefix = "pg_temp_"
+
+// temporarySchemaName returns the name of the temporary schema of the
+// session with the given ID.
+func temporarySchemaName(sessionID ClusterWideID) string {
+	return fmt.Sprintf("%s%d_%d", temporarySchemaPrefix, sessionID.Hi, sessionID.Lo)
+}
+
+// isTemporarySchemaName returns true if the given name refers to a
+// temporary schema, of the current session or any other.
+func isTemporarySchemaName(scName string) bool {
+	return scName == sessiondata.PgTempSchemaName || strings.HasPrefix(scName, temporarySchemaPrefix)
+}
+
+// temporarySchemaState tracks the temporary schemas of a session.
+type temporarySchemaState struct {
+	// sessionID is the ID of the session owning the schemas. It is unset
+	// for internal planners, which cannot create temporary tables.
+	sessionID ClusterWideID
+	// name is the name of the session's temporary schemas.
+	name string
+	// ids maps the ID of a database to the ID of the session's temporary
+	// schema in that database.
+	ids map[sqlbase.ID]sqlbase.ID
+}
+
+func (s *temporarySchemaState) init(sessionID ClusterWideID) {
+	s.sessionID = sessionID
+	s.name = temporarySchemaName(sessionID)
+}
+
+// isOwnSchema returns true if the given schema name designates the temporary
+// schema of the session.
+func (s *temporarySchemaState) isOwnSchema(scName string) bool {
+	return s.name != "" && (scName == s.name || scName == sessiondata.PgTempSchemaName)
+}
+
+// lookup returns the ID of the given temporary schema in the given database,
+// if the schema belongs to the session and has been allocated already.
+func (s *temporarySchemaState) lookup(dbID sqlbase.ID, scName string) (sqlbase.ID, bool) {
+	if len(s.ids) == 0 || !s.isOwnSchema(scName) {
+		return 0, false
+	}
+	id, ok := s.ids[dbID]
+	return id, ok
+}
+
+// owns returns true if the given temporary table belongs to the session.
+func (s *temporarySchemaState) owns(desc *sqlbase.TableDescriptor) bool {
+	return s.name != "" &&
+		desc.TemporarySchema.SessionIDHi == s.sessionID.Hi &&
+		desc.TemporarySchema.SessionIDLo == s.sessionID.Lo
+}
+
+// tableIDs returns the IDs of all the tables in the session's temporary
+// schemas.
+func (s *temporarySchemaState) tableIDs(
+	ctx context.Context, txn *client.Txn,
+) ([]sqlbase.ID, error) {
+	var ids []sqlbase.ID
+	for _, schemaID := range s.ids {
+		prefix := sqlbase.MakeNameMetadataKey(schemaID, "")
+		kvs, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range kvs {
+			ids = append(ids, sqlbase.ID(kv.ValueInt()))
+		}
+	}
+	return ids, nil
+}
+
+// getTemporaryObjectNames implements GetObjectNames for temporary schemas.
+func (a *CachedPhysicalAccessor) getTemporaryObjectNames(
+	dbDesc *DatabaseDescriptor, scName string, flags DatabaseListFlags,
+) (TableNames, error) {
+	if !a.tc.temporarySchemas.isOwnSchema(scName) {
+		if flags.required {
+			tn := tree.MakeTableNameWithSchema(tree.Name(dbDesc.Name), tree.Name(scName), "")
+			return nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(&tn.TableNamePrefix))
+		}
+		return nil, nil
+	}
+	schemaID, ok := a.tc.temporarySchemas.lookup(dbDesc.ID, scName)
+	if !ok {
+		// The schema has not been allocated yet: it is empty.
+		return nil, nil
+	}
+	return getObjectNamesInNamespace(dbDesc, schemaID, a.tc.temporarySchemas.name, flags)
+}
+
+// getTemporaryObjectDesc implements GetObjectDesc for temporary schemas.
+// Temporary tables are always read from KV in the current transaction.
+func (a *CachedPhysicalAccessor) getTemporaryObjectDesc(
+	name *ObjectName, flags ObjectLookupFlags,
+) (*ObjectDescriptor, *DatabaseDescriptor, error) {
+	var desc *ObjectDescriptor
+	var dbDesc *DatabaseDescriptor
+	if len(a.tc.temporarySchemas.ids) > 0 {
+		var err error
+		dbDesc, err = a.GetDatabaseDesc(name.Catalog(), flags.CommonLookupFlags)
+		if dbDesc == nil || err != nil {
+			return nil, dbDesc, err
+		}
+		if schemaID, ok := a.tc.temporarySchemas.lookup(dbDesc.ID, name.Schema()); ok {
+			desc, err = getObjectDescInNamespace(flags, tableKey{parentID: schemaID, name: name.Table()})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if desc == nil && flags.required {
+		return nil, nil, sqlbase.NewUndefinedRelationError(name)
+	}
+	return desc, dbDesc, nil
+}
+
+// resolveTemporaryTableTarget is the counterpart of ResolveTargetObject for
+// the creation of temporary tables. It resolves the database the table is
+// created in and qualifies the name with the session's temporary schema.
+func (p *planner) resolveTemporaryTableTarget(
+	ctx context.Context, tn *ObjectName,
+) (*DatabaseDescriptor, error) {
+	s := &p.Tables().temporarySchemas
+	if tn.ExplicitSchema {
+		if !isTemporarySchemaName(tn.Schema()) {
+			return nil, pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"cannot create temporary relation in non-temporary schema")
+		}
+		if !s.isOwnSchema(tn.Schema()) {
+			return nil, pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"cannot create relations in temporary schemas of other sessions")
+		}
+	}
+	// The database is resolved as if the table were created in its public
+	// schema.
+	target := *tn
+	target.SchemaName = tree.PublicSchemaName
+	target.ExplicitSchema = true
+	dbDesc, err := ResolveTargetObject(ctx, p, &target)
+	if err != nil {
+		return nil, err
+	}
+	tn.CatalogName = target.CatalogName
+	tn.SchemaName = tree.Name(s.name)
+	return dbDesc, nil
+}
+
+// getOrCreateTemporarySchema returns the temporary schema of the session in
+// the given database, allocating it if this is the first temporary table the
+// session creates there.
+func (p *planner) getOrCreateTemporarySchema(
+	ctx context.Context, dbID sqlbase.ID,
+) (*sqlbase.TemporarySchema, error) {
+	s := &p.Tables().temporarySchemas
+	if s.name == "" {
+		return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"temporary tables can only be created by client sessions")
+	}
+	schemaID, ok := s.ids[dbID]
+	if !ok {
+		var err error
+		schemaID, err = GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+		if err != nil {
+			return nil, err
+		}
+		if s.ids == nil {
+			s.ids = make(map[sqlbase.ID]sqlbase.ID)
+		}
+		s.ids[dbID] = schemaID
+	}
+	return &sqlbase.TemporarySchema{
+		ID:          schemaID,
+		SessionIDHi: s.sessionID.Hi,
+		SessionIDLo: s.sessionID.Lo,
+	}, nil
+}
+
+// checkTemporaryReferences verifies that the foreign keys of a table only
+// reference tables with the same persistence: the temporary tables of a
+// session disappear with it, and cannot be referenced by permanent tables.
+func checkTemporaryReferences(
+	desc *sqlbase.TableDescriptor, referenced map[sqlbase.ID]*sqlbase.TableDescriptor,
+) error {
+	for _, other := range referenced {
+		if other.IsTemporary() == desc.IsTemporary() {
+			continue
+		}
+		if desc.IsTemporary() {
+			return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"constraints on temporary tables may reference only temporary tables")
+		}
+		return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+			"constraints on permanent tables may reference only permanent tables")
+	}
+	return nil
+}
+
+// dropTemporaryTables drops the temporary tables with the given IDs. Tables
+// that are already being dropped are skipped.
+func (p *planner) dropTemporaryTables(ctx context.Context, ids []sqlbase.ID) error {
+	params := runParams{ctx: ctx, extendedEvalCtx: &p.extendedEvalCtx, p: p}
+	for _, id := range ids {
+		// Dropping a table can modify the tables referencing it, so the
+		// descriptors are read one at a time.
+		desc, err := sqlbase.GetTableDescFromID(ctx, p.txn, id)
+		if err != nil {
+			return err
+		}
+		if !desc.IsTemporary() {
+			return pgerror.NewErrorf(pgerror.CodeInternalError,
+				"programming error: table %q is not temporary", desc.Name)
+		}
+		if desc.Dropped() {
+			continue
+		}
+		if _, err := p.dropTableImpl(params, desc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropSessionTemporaryTables drops the tables in the session's temporary
+// schemas. It is used by DISCARD TEMP.
+func (p *planner) dropSessionTemporaryTables(ctx context.Context) error {
+	ids, err := p.Tables().temporarySchemas.tableIDs(ctx, p.txn)
+	if err != nil {
+		return err
+	}
+	return p.dropTemporaryTables(ctx, ids)
+}
+
+// dropTemporaryTablesInNewTxn drops the given temporary tables outside of any
+// session. The tables are garbage collected asynchronously by the schema
+// change manager.
+func dropTemporaryTablesInNewTxn(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	opName string,
+	getIDs func(*client.Txn) ([]sqlbase.ID, error),
+) error {
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		ids, err := getIDs(txn)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		p, cleanup := newInternalPlanner(opName, txn, security.RootUser, &MemoryMetrics{}, execCfg)
+		defer cleanup()
+		p.extendedEvalCtx.SchemaChangers = &schemaChangerCollection{}
+		return p.dropTemporaryTables(ctx, ids)
+	})
+}
+
+// cleanupSessionTemporarySchemas drops the tables in the temporary schemas of
+// a session that is ending.
+func cleanupSessionTemporarySchemas(
+	ctx context.Context, execCfg *ExecutorConfig, s *temporarySchemaState,
+) error {
+	if len(s.ids) == 0 {
+		return nil
+	}
+	return dropTemporaryTablesInNewTxn(ctx, execCfg, "drop-session-temp-tables",
+		func(txn *client.Txn) ([]sqlbase.ID, error) {
+			return s.tableIDs(ctx, txn)
+		})
+}
+
+var temporaryObjectCleanupInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.temp_object_cleaner.cleanup_interval",
+	"how often to drop the temporary tables of sessions that did not end cleanly",
+	30*time.Minute,
+)
+
+// TemporaryObjectCleaner periodically drops the temporary tables of sessions
+// that are no longer running, for instance because the node they were
+// connected to crashed before the tables could be dropped.
+type TemporaryObjectCleaner struct {
+	ambientCtx log.AmbientContext
+	execCfg    *ExecutorConfig
+	// liveness is used to recognize the nodes that are gone for good, whose
+	// sessions cannot be listed anymore. It may be nil in tests, in which
+	// case the tables of unreachable nodes are never dropped.
+	liveness *storage.NodeLiveness
+}
+
+// NewTemporaryObjectCleaner returns a new TemporaryObjectCleaner.
+func NewTemporaryObjectCleaner(
+	ambientCtx log.AmbientContext, execCfg *ExecutorConfig, liveness *storage.NodeLiveness,
+) *TemporaryObjectCleaner {
+	return &TemporaryObjectCleaner{ambientCtx: ambientCtx, execCfg: execCfg, liveness: liveness}
+}
+
+// Start starts a goroutine that runs the cleaner every
+// sql.temp_object_cleaner.cleanup_interval.
+func (c *TemporaryObjectCleaner) Start(stopper *stop.Stopper) {
+	stopper.RunWorker(c.ambientCtx.AnnotateCtx(context.Background()), func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(temporaryObjectCleanupInterval.Get(&c.execCfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+				if err := c.cleanup(ctx); err != nil {
+					log.Warningf(ctx, "error while dropping orphaned temporary tables: %s", err)
+				}
+			}
+		}
+	})
+}
+
+// cleanup drops the temporary tables whose session is not running anymore.
+func (c *TemporaryObjectCleaner) cleanup(ctx context.Context) error {
+	var orphaned []*sqlbase.TableDescriptor
+	if err := c.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		orphaned = orphaned[:0]
+		descs, err := GetAllDescriptors(ctx, txn)
+		if err != nil {
+			return err
+		}
+		for _, desc := range descs {
+			if table, ok := desc.(*sqlbase.TableDescriptor); ok && table.IsTemporary() && !table.Dropped() {
+				orphaned = append(orphaned, table)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	// The sessions are listed after the tables, so that the session of any
+	// table found above is listed if it is still running.
+	resp, err := c.execCfg.StatusServer.ListSessions(ctx,
+		&serverpb.ListSessionsRequest{Username: security.RootUser})
+	if err != nil {
+		return err
+	}
+	running := make(map[ClusterWideID]struct{}, len(resp.Sessions))
+	for _, session := range resp.Sessions {
+		running[BytesToClusterWideID(session.ID)] = struct{}{}
+	}
+	// The sessions of nodes that could not be reached are unknown; their
+	// tables are left alone until the node is back, unless node liveness
+	// says the node is dead or decommissioned. A node that restarts gets new
+	// session IDs, so the sessions of a dead node cannot be running.
+	var statuses map[roachpb.NodeID]storage.NodeLivenessStatus
+	if c.liveness != nil {
+		statuses = c.liveness.GetLivenessStatusMap()
+	}
+	unreachable := make(map[roachpb.NodeID]struct{}, len(resp.Errors))
+	for _, e := range resp.Errors {
+		if e.NodeID == 0 {
+			return errors.Errorf("could not list sessions: %s", e.Message)
+		}
+		switch statuses[e.NodeID] {
+		case storage.NodeLivenessStatus_DEAD, storage.NodeLivenessStatus_DECOMMISSIONED:
+		default:
+			unreachable[e.NodeID] = struct{}{}
+		}
+	}
+
+	var ids []sqlbase.ID
+	for _, table := range orphaned {
+		sessionID := ClusterWideID{uint128.FromInts(
+			table.TemporarySchema.SessionIDHi, table.TemporarySchema.SessionIDLo)}
+		if _, ok := running[sessionID]; ok {
+			continue
+		}
+		if _, ok := unreachable[roachpb.NodeID(sessionID.GetNodeID())]; ok {
+			continue
+		}
+		ids = append(ids, table.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	log.Infof(ctx, "dropping %d orphaned temporary table(s)", len(ids))
+	return dropTemporaryTablesInNewTxn(ctx, c.execCfg, "drop-orphaned-temp-tables",
+		func(*client.Txn) ([]sqlbase.ID, error) {
+			return ids, nil
+		})
+}
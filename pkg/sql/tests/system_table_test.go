@@ -120,6 +120,7 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.TableStatisticsTableID, sqlbase.TableStatisticsTableSchema, sqlbase.TableStatisticsTable},
 		{keys.LocationsTableID, sqlbase.LocationsTableSchema, sqlbase.LocationsTable},
 		{keys.RoleMembersTableID, sqlbase.RoleMembersTableSchema, sqlbase.RoleMembersTable},
+		{keys.PlanBaselinesTableID, sqlbase.PlanBaselinesTableSchema, sqlbase.PlanBaselinesTable},
 	} {
 		// Always create tables with "admin" privileges included, or CreateTestTableDescriptor fails.
 		privs := sqlbase.NewCustomSuperuserPrivilegeDescriptor(sqlbase.SystemAllowedPrivileges[test.id])
@@ -108,7 +108,7 @@ func (v *srfExtractionVisitor) VisitPost(expr tree.Expr) tree.Expr {
 }
 
 func (v *srfExtractionVisitor) lookupSRF(t *tree.FuncExpr) (*tree.FunctionDefinition, error) {
-	fd, err := t.Func.Resolve(v.searchPath)
+	fd, err := t.Func.ResolveWithFunctions(v.searchPath, v.p)
 	if err != nil {
 		return nil, err
 	}
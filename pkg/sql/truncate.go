@@ -239,7 +239,7 @@ func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool
 		}
 	}
 	newTableDesc.Mutations = nil
-	tKey := tableKey{parentID: newTableDesc.ParentID, name: newTableDesc.Name}
+	tKey := tableKey{parentID: newTableDesc.NamespaceParentID(), name: newTableDesc.Name}
 	key := tKey.Key()
 	if err := p.createDescriptorWithID(ctx, key, newID, &newTableDesc); err != nil {
 		return err
@@ -360,7 +360,11 @@ func reassignReferencedTables(
 // can even eliminate the need to use a transaction for each chunk at a later
 // stage if it proves inefficient).
 func truncateTableInChunks(
-	ctx context.Context, tableDesc *sqlbase.TableDescriptor, db *client.DB, traceKV bool,
+	ctx context.Context,
+	tableDesc *sqlbase.TableDescriptor,
+	db *client.DB,
+	evalCtx *tree.EvalContext,
+	traceKV bool,
 ) error {
 	const chunkSize = TableTruncateChunkSize
 	var resume roachpb.Span
@@ -372,13 +376,13 @@ func truncateTableInChunks(
 		}
 		if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 			rd, err := sqlbase.MakeRowDeleter(
-				txn, tableDesc, nil, nil, sqlbase.SkipFKs, nil /* *tree.EvalContext */, alloc,
+				txn, tableDesc, nil, nil, sqlbase.SkipFKs, evalCtx, alloc,
 			)
 			if err != nil {
 				return err
 			}
 			td := tableDeleter{rd: rd, alloc: alloc}
-			if err := td.init(txn, nil /* *tree.EvalContext */); err != nil {
+			if err := td.init(txn, evalCtx); err != nil {
 				return err
 			}
 			resume, err = td.deleteAllRows(ctx, resumeAt, chunkSize, noAutoCommit, traceKV)
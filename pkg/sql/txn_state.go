@@ -96,6 +96,16 @@ type txnState struct {
 	// The schema change closures to run when this txn is done.
 	schemaChangers schemaChangerCollection
 
+	// savepoints are the savepoints active in the current txn, other than the
+	// cockroach_restart one.
+	savepoints savepointStack
+
+	// retainedAbortErr is set while in the Aborted state if the SQL txn was
+	// aborted while savepoints were active. In that case the KV txn is not rolled
+	// back, so that a ROLLBACK TO SAVEPOINT can resume it. It is rolled back by
+	// cleanupRetainedTxn() once the SQL txn finishes instead.
+	retainedAbortErr error
+
 	// adv is overwritten after every transition. It represents instructions for
 	// for moving the cursor over the stream of input statements to the next
 	// statement to be executed.
@@ -215,6 +225,8 @@ func (ts *txnState) resetForNewSQLTxn(
 
 	// Discard the old schemaChangers, if any.
 	ts.schemaChangers = schemaChangerCollection{}
+	ts.savepoints = nil
+	ts.retainedAbortErr = nil
 }
 
 // finishSQLTxn finalizes a transaction's results and closes the root span for
@@ -251,6 +263,17 @@ func (ts *txnState) finishSQLTxn() {
 	ts.recordingThreshold = 0
 }
 
+// cleanupRetainedTxn rolls back a KV txn that was kept open in the Aborted state
+// because savepoints were active when the SQL txn was aborted. It's a no-op if
+// there's no such txn.
+func (ts *txnState) cleanupRetainedTxn() {
+	if ts.retainedAbortErr == nil {
+		return
+	}
+	ts.mu.txn.CleanupOnError(ts.Ctx, ts.retainedAbortErr)
+	ts.retainedAbortErr = nil
+}
+
 // finishExternalTxn is a stripped-down version of finishSQLTxn used by
 // connExecutors that run within a higher-level transaction (through the
 // InternalExecutor). These guys don't want to mess with the transaction per-se,
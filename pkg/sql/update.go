@@ -535,6 +535,10 @@ func (u *updateNode) BatchedNext(params runParams) (bool, error) {
 		if _, err := u.run.tu.finalize(params.ctx, u.run.autoCommit, u.run.traceKV); err != nil {
 			return false, err
 		}
+		// Possibly initiate a refresh of the table statistics.
+		params.extendedEvalCtx.ExecCfg.StatsRefresher.NotifyMutation(
+			u.run.tu.tableDesc().ID, u.run.tu.rowsWritten(),
+		)
 		// Remember we're done for the next call to BatchedNext().
 		u.run.done = true
 	}
@@ -628,8 +632,8 @@ func (u *updateNode) processSourceRow(params runParams, sourceVals tree.Datums)
 	for i, val := range u.run.updateValues {
 		col := &u.run.tu.ru.UpdateCols[i]
 		if val == tree.DNull {
-			// Verify no NULL makes it to a nullable column.
-			if !col.Nullable {
+			// Verify no NULL makes it to a non-nullable column.
+			if !col.Nullable || u.run.tu.tableDesc().ValidatingNotNull(col.ID) {
 				return sqlbase.NewNonNullViolationError(col.Name)
 			}
 		} else {
@@ -48,7 +48,7 @@ var _ autoCommitNode = &upsertNode{}
 
 func (p *planner) newUpsertNode(
 	ctx context.Context,
-	n *tree.Insert,
+	onConflict *tree.OnConflict,
 	desc *sqlbase.TableDescriptor,
 	ri sqlbase.RowInserter,
 	tn, alias *tree.TableName,
@@ -64,7 +64,7 @@ func (p *planner) newUpsertNode(
 	// Extract the index that will detect upsert conflicts
 	// (conflictIndex) and the assignment expressions to use when
 	// conflicts are detected (updateExprs).
-	updateExprs, conflictIndex, err := upsertExprsAndIndex(desc, *n.OnConflict, ri.InsertCols)
+	updateExprs, conflictIndex, err := upsertExprsAndIndex(desc, *onConflict, ri.InsertCols)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +95,7 @@ func (p *planner) newUpsertNode(
 		}
 	}()
 
-	if n.OnConflict.DoNothing {
+	if onConflict.DoNothing {
 		if conflictIndex == nil {
 			un.run.tw = &strictTableUpserter{
 				tableUpserterBase: tableUpserterBase{
@@ -155,7 +155,7 @@ func (p *planner) newUpsertNode(
 			updateExprs,
 			computeExprs,
 			conflictIndex,
-			n.OnConflict.Where,
+			onConflict.Where,
 		)
 		if err != nil {
 			return nil, err
@@ -168,7 +168,7 @@ func (p *planner) newUpsertNode(
 		// there are lots of edge cases (that caused real correctness bugs #13437
 		// #13962). As a result, we've decided to remove this until after 1.0 and
 		// re-enable it then. See #14482.
-		enableFastPath := n.OnConflict.IsUpsertAlias() &&
+		enableFastPath := onConflict.IsUpsertAlias() &&
 			// Tables with secondary indexes are not eligible for fast path (it
 			// would be easy to add the new secondary index entry but we can't clean
 			// up the old one without the previous values).
@@ -334,6 +334,10 @@ func (n *upsertNode) BatchedNext(params runParams) (bool, error) {
 		if _, err := n.run.tw.finalize(params.ctx, n.run.autoCommit, n.run.traceKV); err != nil {
 			return false, err
 		}
+		// Possibly initiate a refresh of the table statistics.
+		params.extendedEvalCtx.ExecCfg.StatsRefresher.NotifyMutation(
+			n.run.tw.tableDesc().ID, n.run.tw.rowsWritten(),
+		)
 		// Remember we're done for the next call to BatchedNext().
 		n.run.done = true
 	}
@@ -762,7 +766,9 @@ func upsertExprsAndIndex(
 	// General case: INSERT with an ON CONFLICT clause.
 
 	indexMatch := func(index sqlbase.IndexDescriptor) bool {
-		if !index.Unique {
+		// A partial unique index only guarantees uniqueness among the rows
+		// satisfying its predicate, so it can't serve as an arbiter.
+		if !index.Unique || index.IsPartial() {
 			return false
 		}
 		if len(index.ColumnNames) != len(onConflict.Columns) {
@@ -23,6 +23,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -374,6 +375,32 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// CockroachDB extension.
+	`reorder_joins_limit`: {
+		Set: func(
+			_ context.Context, m *sessionDataMutator,
+			evalCtx *extendedEvalContext, values []tree.TypedExpr,
+		) error {
+			s, err := getIntVal(&evalCtx.EvalContext, `reorder_joins_limit`, values)
+			if err != nil {
+				return err
+			}
+			if s < 0 {
+				return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+					"cannot set reorder_joins_limit to a negative value: %d", s)
+			}
+			m.SetReorderJoinsLimit(int(s))
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return strconv.Itoa(evalCtx.SessionData.ReorderJoinsLimit)
+		},
+		Reset: func(m *sessionDataMutator) error {
+			m.SetReorderJoinsLimit(xform.DefaultReorderJoinsLimit)
+			return nil
+		},
+	},
+
 	// CockroachDB extension (inspired by MySQL).
 	// See https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html#sysvar_sql_safe_updates
 	`sql_safe_updates`: {
@@ -145,9 +145,18 @@ func (v *planVisitor) visit(plan planNode) {
 			if n.hardLimit > 0 && isFilterTrue(n.filter) {
 				v.observer.attr(name, "limit", fmt.Sprintf("%d", n.hardLimit))
 			}
+			if n.sample != nil {
+				v.observer.attr(name, "sample", n.sample.method.String())
+			}
 		}
 		if v.observer.expr != nil {
 			v.expr(name, "filter", -1, n.filter)
+			if n.sample != nil {
+				v.expr(name, "sample percent", -1, n.sample.percentExpr)
+				if n.sample.seedExpr != nil {
+					v.expr(name, "sample seed", -1, n.sample.seedExpr)
+				}
+			}
 		}
 
 	case *filterNode:
@@ -178,6 +187,20 @@ func (v *planVisitor) visit(plan planNode) {
 		v.visit(n.input)
 		v.visit(n.table)
 
+	case *zigzagJoinNode:
+		if v.observer.attr != nil {
+			v.observer.attr(name, "type", joinTypeStr(sqlbase.InnerJoin))
+			for i, side := range []string{"left", "right"} {
+				v.observer.attr(name, side+" fixed values", tree.AsString(&n.sides[i].fixedVals))
+			}
+		}
+		if v.observer.expr != nil && n.onCond != nil && n.onCond != tree.DBoolTrue {
+			v.expr(name, "pred", -1, n.onCond)
+		}
+		for i := range n.sides {
+			v.visit(n.sides[i].scan)
+		}
+
 	case *joinNode:
 		if v.observer.attr != nil {
 			jType := joinTypeStr(n.joinType)
@@ -285,6 +308,12 @@ func (v *planVisitor) visit(plan planNode) {
 					buf.WriteString(inputCols[groupingCol].Name)
 				} else {
 					fmt.Fprintf(&buf, "%s(", agg.funcName)
+					for j, idx := range agg.groupingArgs {
+						if j > 0 {
+							buf.WriteString(", ")
+						}
+						buf.WriteString(inputCols[idx].Name)
+					}
 					if agg.argRenderIdx != noRenderIdx {
 						if agg.isDistinct() {
 							buf.WriteString("DISTINCT ")
@@ -320,6 +349,25 @@ func (v *planVisitor) visit(plan planNode) {
 					v.observer.attr(name, "group by", buf.String())
 				}
 			}
+			if n.groupingSets != nil {
+				var buf bytes.Buffer
+				for i, set := range n.groupingSets {
+					if i > 0 {
+						buf.WriteString(", ")
+					}
+					buf.WriteByte('(')
+					first := true
+					set.ForEach(func(idx int) {
+						if !first {
+							buf.WriteByte(',')
+						}
+						first = false
+						fmt.Fprintf(&buf, "@%d", idx+1)
+					})
+					buf.WriteByte(')')
+				}
+				v.observer.attr(name, "grouping sets", buf.String())
+			}
 			if n.isScalar {
 				v.observer.attr(name, "scalar", "")
 			}
@@ -472,6 +520,9 @@ func (v *planVisitor) visit(plan planNode) {
 	case *explainDistSQLNode:
 		v.visit(n.plan)
 
+	case *explainAnalyzeNode:
+		v.visit(n.plan)
+
 	case *ordinalityNode:
 		v.visit(n.source)
 
@@ -574,65 +625,74 @@ func joinTypeStr(t sqlbase.JoinType) string {
 // strings are constant and not precomputed so that the type names can
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
-	reflect.TypeOf(&alterIndexNode{}):           "alter index",
-	reflect.TypeOf(&alterSequenceNode{}):        "alter sequence",
-	reflect.TypeOf(&alterTableNode{}):           "alter table",
-	reflect.TypeOf(&alterUserSetPasswordNode{}): "alter user",
-	reflect.TypeOf(&cancelQueriesNode{}):        "cancel queries",
-	reflect.TypeOf(&cancelSessionsNode{}):       "cancel sessions",
-	reflect.TypeOf(&controlJobsNode{}):          "control jobs",
-	reflect.TypeOf(&createDatabaseNode{}):       "create database",
-	reflect.TypeOf(&createIndexNode{}):          "create index",
-	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
-	reflect.TypeOf(&createStatsNode{}):          "create statistics",
-	reflect.TypeOf(&createTableNode{}):          "create table",
-	reflect.TypeOf(&CreateUserNode{}):           "create user/role",
-	reflect.TypeOf(&createViewNode{}):           "create view",
-	reflect.TypeOf(&delayedNode{}):              "virtual table",
-	reflect.TypeOf(&deleteNode{}):               "delete",
-	reflect.TypeOf(&distinctNode{}):             "distinct",
-	reflect.TypeOf(&dropDatabaseNode{}):         "drop database",
-	reflect.TypeOf(&dropIndexNode{}):            "drop index",
-	reflect.TypeOf(&dropSequenceNode{}):         "drop sequence",
-	reflect.TypeOf(&dropTableNode{}):            "drop table",
-	reflect.TypeOf(&DropUserNode{}):             "drop user/role",
-	reflect.TypeOf(&dropViewNode{}):             "drop view",
-	reflect.TypeOf(&explainDistSQLNode{}):       "explain distsql",
-	reflect.TypeOf(&explainPlanNode{}):          "explain plan",
-	reflect.TypeOf(&filterNode{}):               "filter",
-	reflect.TypeOf(&groupNode{}):                "group",
-	reflect.TypeOf(&hookFnNode{}):               "plugin",
-	reflect.TypeOf(&indexJoinNode{}):            "index-join",
-	reflect.TypeOf(&insertNode{}):               "insert",
-	reflect.TypeOf(&joinNode{}):                 "join",
-	reflect.TypeOf(&limitNode{}):                "limit",
-	reflect.TypeOf(&lookupJoinNode{}):           "lookup-join",
-	reflect.TypeOf(&ordinalityNode{}):           "ordinality",
-	reflect.TypeOf(&projectSetNode{}):           "project set",
-	reflect.TypeOf(&relocateNode{}):             "relocate",
-	reflect.TypeOf(&renderNode{}):               "render",
-	reflect.TypeOf(&rowCountNode{}):             "count",
-	reflect.TypeOf(&scanNode{}):                 "scan",
-	reflect.TypeOf(&scatterNode{}):              "scatter",
-	reflect.TypeOf(&scrubNode{}):                "scrub",
-	reflect.TypeOf(&sequenceSelectNode{}):       "sequence select",
-	reflect.TypeOf(&serializeNode{}):            "run",
-	reflect.TypeOf(&setClusterSettingNode{}):    "set cluster setting",
-	reflect.TypeOf(&setVarNode{}):               "set",
-	reflect.TypeOf(&setZoneConfigNode{}):        "configure zone",
-	reflect.TypeOf(&showFingerprintsNode{}):     "showFingerprints",
-	reflect.TypeOf(&showRangesNode{}):           "showRanges",
-	reflect.TypeOf(&showTraceNode{}):            "show trace for",
-	reflect.TypeOf(&showTraceReplicaNode{}):     "replica trace",
-	reflect.TypeOf(&showZoneConfigNode{}):       "show zone configuration",
-	reflect.TypeOf(&sortNode{}):                 "sort",
-	reflect.TypeOf(&splitNode{}):                "split",
-	reflect.TypeOf(&spoolNode{}):                "spool",
-	reflect.TypeOf(&unaryNode{}):                "emptyrow",
-	reflect.TypeOf(&unionNode{}):                "union",
-	reflect.TypeOf(&updateNode{}):               "update",
-	reflect.TypeOf(&upsertNode{}):               "upsert",
-	reflect.TypeOf(&valuesNode{}):               "values",
-	reflect.TypeOf(&windowNode{}):               "window",
-	reflect.TypeOf(&zeroNode{}):                 "norows",
+	reflect.TypeOf(&alterIndexNode{}):              "alter index",
+	reflect.TypeOf(&alterSequenceNode{}):           "alter sequence",
+	reflect.TypeOf(&alterTableNode{}):              "alter table",
+	reflect.TypeOf(&alterUserSetPasswordNode{}):    "alter user",
+	reflect.TypeOf(&cancelQueriesNode{}):           "cancel queries",
+	reflect.TypeOf(&cancelSessionsNode{}):          "cancel sessions",
+	reflect.TypeOf(&closeCursorNode{}):             "close cursor",
+	reflect.TypeOf(&controlJobsNode{}):             "control jobs",
+	reflect.TypeOf(&createDatabaseNode{}):          "create database",
+	reflect.TypeOf(&createFunctionNode{}):          "create function",
+	reflect.TypeOf(&createIndexNode{}):             "create index",
+	reflect.TypeOf(&createSequenceNode{}):          "create sequence",
+	reflect.TypeOf(&createStatsNode{}):             "create statistics",
+	reflect.TypeOf(&createTableNode{}):             "create table",
+	reflect.TypeOf(&CreateUserNode{}):              "create user/role",
+	reflect.TypeOf(&createViewNode{}):              "create view",
+	reflect.TypeOf(&delayedNode{}):                 "virtual table",
+	reflect.TypeOf(&deleteNode{}):                  "delete",
+	reflect.TypeOf(&distinctNode{}):                "distinct",
+	reflect.TypeOf(&dropDatabaseNode{}):            "drop database",
+	reflect.TypeOf(&dropFunctionNode{}):            "drop function",
+	reflect.TypeOf(&dropIndexNode{}):               "drop index",
+	reflect.TypeOf(&dropSequenceNode{}):            "drop sequence",
+	reflect.TypeOf(&dropTableNode{}):               "drop table",
+	reflect.TypeOf(&DropUserNode{}):                "drop user/role",
+	reflect.TypeOf(&dropViewNode{}):                "drop view",
+	reflect.TypeOf(&explainAnalyzeNode{}):          "explain analyze",
+	reflect.TypeOf(&explainDistSQLNode{}):          "explain distsql",
+	reflect.TypeOf(&explainPlanNode{}):             "explain plan",
+	reflect.TypeOf(&fetchNode{}):                   "fetch",
+	reflect.TypeOf(&filterNode{}):                  "filter",
+	reflect.TypeOf(&groupNode{}):                   "group",
+	reflect.TypeOf(&hookFnNode{}):                  "plugin",
+	reflect.TypeOf(&indexJoinNode{}):               "index-join",
+	reflect.TypeOf(&insertNode{}):                  "insert",
+	reflect.TypeOf(&joinNode{}):                    "join",
+	reflect.TypeOf(&limitNode{}):                   "limit",
+	reflect.TypeOf(&listenNode{}):                  "listen",
+	reflect.TypeOf(&lookupJoinNode{}):              "lookup-join",
+	reflect.TypeOf(&notifyNode{}):                  "notify",
+	reflect.TypeOf(&ordinalityNode{}):              "ordinality",
+	reflect.TypeOf(&projectSetNode{}):              "project set",
+	reflect.TypeOf(&refreshMaterializedViewNode{}): "refresh materialized view",
+	reflect.TypeOf(&relocateNode{}):                "relocate",
+	reflect.TypeOf(&renderNode{}):                  "render",
+	reflect.TypeOf(&rowCountNode{}):                "count",
+	reflect.TypeOf(&scanNode{}):                    "scan",
+	reflect.TypeOf(&scatterNode{}):                 "scatter",
+	reflect.TypeOf(&scrubNode{}):                   "scrub",
+	reflect.TypeOf(&sequenceSelectNode{}):          "sequence select",
+	reflect.TypeOf(&serializeNode{}):               "run",
+	reflect.TypeOf(&setClusterSettingNode{}):       "set cluster setting",
+	reflect.TypeOf(&setVarNode{}):                  "set",
+	reflect.TypeOf(&setZoneConfigNode{}):           "configure zone",
+	reflect.TypeOf(&showFingerprintsNode{}):        "showFingerprints",
+	reflect.TypeOf(&showRangesNode{}):              "showRanges",
+	reflect.TypeOf(&showTraceNode{}):               "show trace for",
+	reflect.TypeOf(&showTraceReplicaNode{}):        "replica trace",
+	reflect.TypeOf(&showZoneConfigNode{}):          "show zone configuration",
+	reflect.TypeOf(&sortNode{}):                    "sort",
+	reflect.TypeOf(&splitNode{}):                   "split",
+	reflect.TypeOf(&spoolNode{}):                   "spool",
+	reflect.TypeOf(&unaryNode{}):                   "emptyrow",
+	reflect.TypeOf(&unionNode{}):                   "union",
+	reflect.TypeOf(&updateNode{}):                  "update",
+	reflect.TypeOf(&upsertNode{}):                  "upsert",
+	reflect.TypeOf(&valuesNode{}):                  "values",
+	reflect.TypeOf(&windowNode{}):                  "window",
+	reflect.TypeOf(&zeroNode{}):                    "norows",
+	reflect.TypeOf(&zigzagJoinNode{}):              "zigzag-join",
 }
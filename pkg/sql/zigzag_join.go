@@ -0,0 +1,121 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// zigzagJoinNode represents a zigzag join between two indexes of the same
+// table. Each index has a prefix of its columns fixed to constant values, and
+// the indexes are joined on the columns that follow the prefix.
+type zigzagJoinNode struct {
+	// sides contains information about each index involved in the join.
+	sides []zigzagJoinSide
+
+	// columns are the produced columns, namely the columns of the scanNodes of
+	// all the sides, in order.
+	columns sqlbase.ResultColumns
+
+	// onCond is any ON condition to be used in conjunction with the implicit
+	// equality condition on the equality columns.
+	onCond tree.TypedExpr
+
+	run zigzagJoinRun
+}
+
+// zigzagJoinSide contains information about one index involved in a zigzag
+// join.
+type zigzagJoinSide struct {
+	// scan is configured with the table, the index and the columns produced by
+	// this side.
+	scan *scanNode
+
+	// eqCols identifies the columns of the scan which are used for the join.
+	// All the sides have the same number of equality columns.
+	eqCols []int
+
+	// fixedVals are the values of the prefix of the index columns.
+	fixedVals tree.Datums
+}
+
+// zigzagJoinRun is the state for the local execution path for zigzag join.
+//
+// We have no local execution path; like lookup joins, we fall back on doing
+// full scans of both indexes and using the joinNode to do the join. The fixed
+// values are checked by the ON condition.
+//
+// This path is temporary and only exists to avoid failures (especially in logic
+// tests) when DistSQL is not being used.
+type zigzagJoinRun struct {
+	n *joinNode
+}
+
+// startExec is part of the execStartable interface.
+func (zj *zigzagJoinNode) startExec(params runParams) error {
+	// Make sure the scan nodes have a span (full scan). Note that startExec
+	// will be called on the scan nodes.
+	srcs := make([]planDataSource, len(zj.sides))
+	for i, side := range zj.sides {
+		var err error
+		side.scan.spans, err = spansFromConstraint(side.scan.desc, side.scan.index, nil /* constraint */)
+		if err != nil {
+			return err
+		}
+		srcs[i] = planDataSource{
+			info: &sqlbase.DataSourceInfo{SourceColumns: planColumns(side.scan)},
+			plan: side.scan,
+		}
+	}
+
+	left, right := &zj.sides[0], &zj.sides[1]
+	pred, _, err := params.p.makeJoinPredicate(
+		context.TODO(), srcs[0].info, srcs[1].info, sqlbase.InnerJoin, nil, /* cond */
+	)
+	if err != nil {
+		return err
+	}
+
+	// Program the equalities implied by the equality columns.
+	for i := range left.eqCols {
+		pred.addEquality(srcs[0].info, left.eqCols[i], srcs[1].info, right.eqCols[i])
+	}
+
+	onAndExprs := splitAndExpr(params.EvalContext(), zj.onCond, nil /* exprs */)
+	for _, e := range onAndExprs {
+		if e != tree.DBoolTrue && !pred.tryAddEqualityFilter(e, srcs[0].info, srcs[1].info) {
+			pred.onCond = mergeConj(pred.onCond, e)
+		}
+	}
+	zj.run.n = params.p.makeJoinNode(srcs[0], srcs[1], pred)
+	return zj.run.n.startExec(params)
+}
+
+func (zj *zigzagJoinNode) Next(params runParams) (bool, error) {
+	return zj.run.n.Next(params)
+}
+
+func (zj *zigzagJoinNode) Values() tree.Datums {
+	return zj.run.n.Values()
+}
+
+func (zj *zigzagJoinNode) Close(ctx context.Context) {
+	if zj.run.n != nil {
+		zj.run.n.Close(ctx)
+	}
+}
@@ -190,6 +190,13 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "add progress to system.jobs",
 		workFn: addJobsProgress,
 	},
+	{
+		// Introduced in v2.1.
+		// TODO(optimizer): bake into v2.2.
+		name:             "create system.plan_baselines table",
+		workFn:           createPlanBaselinesTable,
+		newDescriptorIDs: staticIDs(keys.PlanBaselinesTableID),
+	},
 }
 
 func staticIDs(ids ...sqlbase.ID) func(ctx context.Context, db db) ([]sqlbase.ID, error) {
@@ -503,6 +510,10 @@ func createSystemTable(ctx context.Context, r runner, desc sqlbase.TableDescript
 	return err
 }
 
+func createPlanBaselinesTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.PlanBaselinesTable)
+}
+
 var reportingOptOut = envutil.EnvOrDefaultBool("COCKROACH_SKIP_ENABLING_DIAGNOSTIC_REPORTING", false)
 
 func runStmtAsRootWithRetry(
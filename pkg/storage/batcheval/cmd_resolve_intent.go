@@ -63,14 +63,15 @@ func ResolveIntent(
 	h := cArgs.Header
 	ms := cArgs.Stats
 
-	if h.Txn != nil {
+	if h.Txn != nil && !isOwnIntentRevert(h.Txn, args.IntentTxn, args.Status, args.IgnoredSeqNums) {
 		return result.Result{}, ErrTransactionUnsupported
 	}
 
 	intent := roachpb.Intent{
-		Span:   args.Span(),
-		Txn:    args.IntentTxn,
-		Status: args.Status,
+		Span:           args.Span(),
+		Txn:            args.IntentTxn,
+		Status:         args.Status,
+		IgnoredSeqNums: args.IgnoredSeqNums,
 	}
 	if err := engine.MVCCResolveWriteIntent(ctx, batch, ms, intent); err != nil {
 		return result.Result{}, err
@@ -43,14 +43,15 @@ func ResolveIntentRange(
 	h := cArgs.Header
 	ms := cArgs.Stats
 
-	if h.Txn != nil {
+	if h.Txn != nil && !isOwnIntentRevert(h.Txn, args.IntentTxn, args.Status, args.IgnoredSeqNums) {
 		return result.Result{}, ErrTransactionUnsupported
 	}
 
 	intent := roachpb.Intent{
-		Span:   args.Span(),
-		Txn:    args.IntentTxn,
-		Status: args.Status,
+		Span:           args.Span(),
+		Txn:            args.IntentTxn,
+		Status:         args.Status,
+		IgnoredSeqNums: args.IgnoredSeqNums,
 	}
 
 	// Use a time-bounded iterator as an optimization if indicated.
@@ -213,3 +213,43 @@ func TestDeclareKeysResolveIntent(t *testing.T) {
 		}
 	})
 }
+
+func TestIsOwnIntentRevert(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	txn := roachpb.MakeTransaction("test", roachpb.Key("a"), 0, enginepb.SERIALIZABLE, hlc.Timestamp{WallTime: 1}, 0)
+	txn.Epoch = 2
+	txn.Sequence = 10
+	txn.AddIgnoredSeqNumRange(enginepb.IgnoredSeqNumRange{Start: 3, End: 5})
+	txn.AddIgnoredSeqNumRange(enginepb.IgnoredSeqNumRange{Start: 7, End: 9})
+
+	otherTxn := roachpb.MakeTransaction("other", roachpb.Key("a"), 0, enginepb.SERIALIZABLE, hlc.Timestamp{WallTime: 1}, 0)
+	prevEpoch := txn.TxnMeta
+	prevEpoch.Epoch = 1
+
+	tests := []struct {
+		name      string
+		intentTxn enginepb.TxnMeta
+		status    roachpb.TransactionStatus
+		ignored   []enginepb.IgnoredSeqNumRange
+		exp       bool
+	}{
+		{"revert", txn.TxnMeta, roachpb.PENDING, txn.IgnoredSeqNums, true},
+		{"partial revert", txn.TxnMeta, roachpb.PENDING, txn.IgnoredSeqNums[1:], true},
+		{"other txn", otherTxn.TxnMeta, roachpb.PENDING, txn.IgnoredSeqNums, false},
+		{"previous epoch", prevEpoch, roachpb.PENDING, txn.IgnoredSeqNums, false},
+		{"commit", txn.TxnMeta, roachpb.COMMITTED, txn.IgnoredSeqNums, false},
+		{"no ignored seqs", txn.TxnMeta, roachpb.PENDING, nil, false},
+		{"not rolled back", txn.TxnMeta, roachpb.PENDING,
+			[]enginepb.IgnoredSeqNumRange{{Start: 5, End: 7}}, false},
+		{"not yet written", txn.TxnMeta, roachpb.PENDING,
+			[]enginepb.IgnoredSeqNumRange{{Start: 7, End: 10}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := isOwnIntentRevert(&txn, test.intentTxn, test.status, test.ignored); res != test.exp {
+				t.Errorf("expected %t, got %t", test.exp, res)
+			}
+		})
+	}
+}
@@ -40,6 +40,40 @@ func VerifyTransaction(h roachpb.Header, args roachpb.Request) error {
 	return nil
 }
 
+// isOwnIntentRevert returns true if a transaction is resolving its own
+// intents of the current epoch while still pending, in order to revert writes
+// that it rolled back to a savepoint. This is the only intent resolution that
+// may be evaluated in the context of a transaction. The sequence numbers to
+// revert must all be among those the transaction has rolled back, and must
+// precede the request itself.
+func isOwnIntentRevert(
+	txn *roachpb.Transaction,
+	intentTxn enginepb.TxnMeta,
+	status roachpb.TransactionStatus,
+	ignored []enginepb.IgnoredSeqNumRange,
+) bool {
+	if txn.ID != intentTxn.ID || txn.Epoch != intentTxn.Epoch ||
+		status != roachpb.PENDING || len(ignored) == 0 {
+		return false
+	}
+	for _, r := range ignored {
+		if r.Start > r.End || r.End >= txn.Sequence {
+			return false
+		}
+		contained := false
+		for _, txnRange := range txn.IgnoredSeqNums {
+			if r.Start >= txnRange.Start && r.End <= txnRange.End {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
+
 // WriteAbortSpanOnResolve returns true if the abort span must be written when
 // the transaction with the given status is resolved.
 func WriteAbortSpanOnResolve(status roachpb.TransactionStatus) bool {
@@ -129,3 +129,14 @@ func (ms *MVCCStats) Subtract(oms MVCCStats) {
 func (meta MVCCMetadata) IsInline() bool {
 	return meta.RawBytes != nil
 }
+
+// TxnSeqIsIgnored returns true iff the sequence number is contained in one of
+// the given ignored ranges.
+func TxnSeqIsIgnored(seq int32, ignored []IgnoredSeqNumRange) bool {
+	for _, r := range ignored {
+		if seq >= r.Start && seq <= r.End {
+			return true
+		}
+	}
+	return false
+}
@@ -1143,6 +1143,19 @@ func maybeGetValue(
 	return valueFn(exVal)
 }
 
+// mvccGetIntentValue returns a copy of the provisional value that the intent
+// on the given key wrote at the given timestamp.
+func mvccGetIntentValue(iter Iterator, key roachpb.Key, timestamp hlc.Timestamp) ([]byte, error) {
+	versionKey := MVCCKey{Key: key, Timestamp: timestamp}
+	iter.Seek(versionKey)
+	if ok, err := iter.Valid(); err != nil {
+		return nil, err
+	} else if !ok || !iter.UnsafeKey().Equal(versionKey) {
+		return nil, errors.Errorf("intent value missing for %s", versionKey)
+	}
+	return append([]byte(nil), iter.UnsafeValue()...), nil
+}
+
 // mvccPutInternal adds a new timestamped value to the specified key.
 // If value is nil, creates a deletion tombstone value. valueFn is
 // an optional alternative to supplying value directly. It is passed
@@ -1217,6 +1230,7 @@ func mvccPutInternal(
 	var meta *enginepb.MVCCMetadata
 	var maybeTooOldErr error
 	var prevValSize int64
+	var intentHistory []enginepb.SequencedIntent
 	if ok {
 		// There is existing metadata for this key; ensure our write is permitted.
 		meta = &buf.meta
@@ -1246,6 +1260,31 @@ func mvccPutInternal(
 				ctx, iter, metaKey, value, ok, timestamp, txn, buf, valueFn); err != nil {
 				return err
 			}
+			// Remember the value we're replacing in the intent history so
+			// that it can be restored if the transaction rolls back to a
+			// savepoint taken before this write. The history does not carry
+			// over into a new epoch.
+			//
+			// Every overwrite appends the full previous value, and the whole
+			// history is rewritten with the metadata on each write and read
+			// back on each access to the key. A transaction overwriting the
+			// same key n times thus keeps n values in the intent's metadata,
+			// and writes O(n^2) bytes in total, until the intent is resolved.
+			// The history isn't capped, as dropping entries would make the
+			// corresponding savepoints impossible to roll back to; it is
+			// accounted in the metadata's size in the MVCC stats.
+			if txn.Epoch == meta.Txn.Epoch {
+				prevIntentValue, err := mvccGetIntentValue(iter, key, metaTimestamp)
+				if err != nil {
+					return err
+				}
+				intentHistory = make([]enginepb.SequencedIntent, len(meta.IntentHistory), len(meta.IntentHistory)+1)
+				copy(intentHistory, meta.IntentHistory)
+				intentHistory = append(intentHistory, enginepb.SequencedIntent{
+					Sequence: meta.Txn.Sequence,
+					Value:    prevIntentValue,
+				})
+			}
 			// We are replacing our own write intent. If we are writing at
 			// the same timestamp (see comments in else block) we can
 			// overwrite the existing intent; otherwise we must manually
@@ -1329,8 +1368,9 @@ func mvccPutInternal(
 			txnMeta = &txn.TxnMeta
 		}
 		buf.newMeta = enginepb.MVCCMetadata{
-			Txn:       txnMeta,
-			Timestamp: hlc.LegacyTimestamp(timestamp),
+			Txn:           txnMeta,
+			Timestamp:     hlc.LegacyTimestamp(timestamp),
+			IntentHistory: intentHistory,
 		}
 	}
 	newMeta := &buf.newMeta
@@ -2247,7 +2287,23 @@ func mvccResolveWriteIntent(
 	// restart in EndTransaction, so the replay won't resolve intents.
 	epochsMatch := meta.Txn.Epoch == intent.Txn.Epoch
 	timestampsValid := !intent.Txn.Timestamp.Less(hlc.Timestamp(meta.Timestamp))
-	commit := intent.Status == roachpb.COMMITTED && epochsMatch && timestampsValid
+
+	// If the latest write to the intent was rolled back to a savepoint, revert
+	// the intent to the most recent value in its history that wasn't. When
+	// every write in the history was rolled back, the intent is removed just as
+	// if the transaction had aborted.
+	var rolledBack, removeIntent bool
+	if epochsMatch && intent.Status != roachpb.ABORTED &&
+		enginepb.TxnSeqIsIgnored(meta.Txn.Sequence, intent.IgnoredSeqNums) {
+		removeIntent, origMetaKeySize, origMetaValSize, err = mvccRollbackIgnoredWrites(
+			engine, ms, intent, metaKey, origMetaKeySize, origMetaValSize, buf)
+		if err != nil {
+			return false, err
+		}
+		rolledBack = !removeIntent
+	}
+
+	commit := !removeIntent && intent.Status == roachpb.COMMITTED && epochsMatch && timestampsValid
 
 	// Note the small difference to commit epoch handling here: We allow
 	// a push from a previous epoch to move a newer intent. That's not
@@ -2274,7 +2330,7 @@ func mvccResolveWriteIntent(
 	// used for resolving), but that costs latency.
 	// TODO(tschottdorf): various epoch-related scenarios here deserve more
 	// testing.
-	pushed := intent.Status == roachpb.PENDING &&
+	pushed := !removeIntent && intent.Status == roachpb.PENDING &&
 		hlc.Timestamp(meta.Timestamp).Less(intent.Txn.Timestamp) &&
 		meta.Txn.Epoch >= intent.Txn.Epoch
 
@@ -2366,8 +2422,8 @@ func mvccResolveWriteIntent(
 	// This method shouldn't be called in this instance, but there's
 	// nothing to do if meta's epoch is greater than or equal txn's
 	// epoch and the state is still PENDING.
-	if intent.Status == roachpb.PENDING && meta.Txn.Epoch >= intent.Txn.Epoch {
-		return false, nil
+	if !removeIntent && intent.Status == roachpb.PENDING && meta.Txn.Epoch >= intent.Txn.Epoch {
+		return rolledBack, nil
 	}
 
 	// Otherwise, we're deleting the intent. We must find the next
@@ -2437,6 +2493,65 @@ func mvccResolveWriteIntent(
 	return true, nil
 }
 
+// mvccRollbackIgnoredWrites reverts an intent whose latest write has a
+// sequence number that the transaction rolled back to a savepoint. The intent
+// is rewritten with the newest value in its history that was not rolled back,
+// and buf.meta is updated to describe the rewritten intent. If no such value
+// exists, nothing is written and removeIntent is returned as true; the caller
+// is then expected to remove the intent. The returned sizes are those of the
+// intent's metadata after the rollback.
+func mvccRollbackIgnoredWrites(
+	engine Writer,
+	ms *enginepb.MVCCStats,
+	intent roachpb.Intent,
+	metaKey MVCCKey,
+	origMetaKeySize, origMetaValSize int64,
+	buf *putBuffer,
+) (removeIntent bool, metaKeySize, metaValSize int64, err error) {
+	meta := &buf.meta
+	i := len(meta.IntentHistory) - 1
+	for ; i >= 0; i-- {
+		if !enginepb.TxnSeqIsIgnored(meta.IntentHistory[i].Sequence, intent.IgnoredSeqNums) {
+			break
+		}
+	}
+	if i < 0 {
+		return true, origMetaKeySize, origMetaValSize, nil
+	}
+	restored := meta.IntentHistory[i]
+
+	txnMeta := *meta.Txn
+	txnMeta.Sequence = restored.Sequence
+	buf.newMeta = *meta
+	buf.newMeta.Txn = &txnMeta
+	buf.newMeta.IntentHistory = meta.IntentHistory[:i]
+	buf.newMeta.ValBytes = int64(len(restored.Value))
+	buf.newMeta.Deleted = len(restored.Value) == 0
+
+	metaKeySize, metaValSize, err = buf.putMeta(engine, metaKey, &buf.newMeta)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	versionKey := MVCCKey{Key: intent.Key, Timestamp: hlc.Timestamp(meta.Timestamp)}
+	if err := engine.Put(versionKey, restored.Value); err != nil {
+		return false, 0, 0, err
+	}
+	if ms != nil {
+		ms.Add(updateStatsOnPut(intent.Key, 0 /* prevValSize */, origMetaKeySize, origMetaValSize,
+			metaKeySize, metaValSize, meta, &buf.newMeta))
+	}
+	engine.LogLogicalOp(MVCCUpdateIntentOpType, MVCCLogicalOpDetails{
+		Txn: txnMeta,
+		Key: intent.Key,
+		Value: roachpb.Value{
+			Timestamp: hlc.Timestamp(meta.Timestamp),
+		},
+	})
+
+	buf.meta = buf.newMeta
+	return false, metaKeySize, metaValSize, nil
+}
+
 // IterAndBuf used to pass iterators and buffers between MVCC* calls, allowing
 // reuse without the callers needing to know the particulars.
 type IterAndBuf struct {
@@ -3116,6 +3116,73 @@ func TestMVCCResolveWithDiffEpochs(t *testing.T) {
 	}
 }
 
+// TestMVCCResolveWithIgnoredSeqNums verifies that resolving an intent with
+// some of the writer's sequence numbers ignored reverts the intent to the
+// newest value written at a sequence number that is not ignored, or removes
+// it if there is no such value.
+func TestMVCCResolveWithIgnoredSeqNums(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	txn := txn1.Clone()
+	for _, w := range []struct {
+		seq   int32
+		key   roachpb.Key
+		value roachpb.Value
+	}{
+		{seq: 1, key: testKey1, value: value1},
+		{seq: 2, key: testKey1, value: value2},
+		{seq: 3, key: testKey1, value: value3},
+		{seq: 3, key: testKey2, value: value4},
+	} {
+		txn.Sequence = w.seq
+		if err := MVCCPut(ctx, engine, nil, w.key, txn.Timestamp, w.value, &txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Roll back the writes at sequence numbers 2 and 3, leaving the txn
+	// pending.
+	txn.AddIgnoredSeqNumRange(enginepb.IgnoredSeqNumRange{Start: 2, End: 3})
+	if _, _, err := MVCCResolveWriteIntentRange(ctx, engine, nil, roachpb.Intent{
+		Span:           roachpb.Span{Key: testKey1, EndKey: testKey2.Next()},
+		Txn:            txn.TxnMeta,
+		Status:         roachpb.PENDING,
+		IgnoredSeqNums: txn.IgnoredSeqNums,
+	}, math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+
+	value, _, err := MVCCGet(ctx, engine, testKey1, txn.Timestamp, true, &txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value == nil || !bytes.Equal(value1.RawBytes, value.RawBytes) {
+		t.Fatalf("expected value %s, got %v", value1.RawBytes, value)
+	}
+	if value, _, err := MVCCGet(ctx, engine, testKey2, txn.Timestamp, true, &txn); value != nil || err != nil {
+		t.Fatalf("expected value nil, err nil; got %+v, %v", value, err)
+	}
+
+	// Committing the txn commits the value that survived.
+	txn.Status = roachpb.COMMITTED
+	if _, _, err := MVCCResolveWriteIntentRange(
+		ctx, engine, nil, roachpb.AsIntents([]roachpb.Span{{Key: testKey1, EndKey: testKey2.Next()}}, &txn)[0],
+		math.MaxInt64,
+	); err != nil {
+		t.Fatal(err)
+	}
+	value, _, err = MVCCGet(ctx, engine, testKey1, txn.Timestamp, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value == nil || !bytes.Equal(value1.RawBytes, value.RawBytes) {
+		t.Fatalf("expected value %s, got %v", value1.RawBytes, value)
+	}
+}
+
 func TestMVCCResolveWithUpdatedTimestamp(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	engine := createTestEngine()
@@ -4887,6 +4887,81 @@ func TestEndTransactionResolveOnlyLocalIntents(t *testing.T) {
 	}
 }
 
+// TestEndTransactionResolveIgnoredSeqNums verifies that committing a
+// transaction rolls back the writes at ignored sequence numbers, both for the
+// intents resolved along with the EndTransaction and for external intents.
+func TestEndTransactionResolveIgnoredSeqNums(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	key := roachpb.Key("a")
+	splitKey := roachpb.RKey(key).Next()
+	newRepl := splitTestRange(tc.store, splitKey, splitKey, t)
+
+	txn := newTransaction("test", key, 1, enginepb.SERIALIZABLE, tc.Clock())
+	h := roachpb.Header{Txn: txn}
+	pArgs := putArgs(key, []byte("value"))
+	assignSeqNumsForReqs(txn, &pArgs)
+	if _, pErr := maybeWrapWithBeginTransaction(context.Background(), tc.Sender(), h, &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	for _, val := range []string{"value", "ignored"} {
+		var ba roachpb.BatchRequest
+		ba.Header = h
+		ba.RangeID = newRepl.RangeID
+		if err := ba.SetActiveTimestamp(newRepl.store.Clock().Now); err != nil {
+			t.Fatal(err)
+		}
+		pArgs := putArgs(splitKey.AsRawKey(), []byte(val))
+		ba.Add(&pArgs)
+		assignSeqNumsForReqs(txn, &pArgs)
+		if _, pErr := newRepl.Send(context.Background(), ba); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+	pArgs = putArgs(key, []byte("ignored"))
+	assignSeqNumsForReqs(txn, &pArgs)
+	if _, pErr := tc.SendWrappedWith(h, &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Ignore the second write to each key, then commit.
+	txn.AddIgnoredSeqNumRange(enginepb.IgnoredSeqNumRange{Start: 3, End: txn.Sequence})
+	args, h := endTxnArgs(txn, true /* commit */)
+	args.IntentSpans = []roachpb.Span{{Key: key}, {Key: splitKey.AsRawKey()}}
+	assignSeqNumsForReqs(txn, &args)
+	if _, pErr := tc.SendWrappedWith(h, &args); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	for _, k := range []roachpb.Key{key, splitKey.AsRawKey()} {
+		testutils.SucceedsSoon(t, func() error {
+			var gr roachpb.GetResponse
+			if _, err := batcheval.Get(
+				context.Background(), tc.engine, batcheval.CommandArgs{
+					Header: roachpb.Header{Timestamp: tc.Clock().Now()},
+					Args:   &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: k}},
+				},
+				&gr,
+			); err != nil {
+				return err
+			}
+			if gr.Value == nil {
+				return errors.Errorf("%s: expected a value", k)
+			}
+			if b, err := gr.Value.GetBytes(); err != nil {
+				t.Fatal(err)
+			} else if string(b) != "value" {
+				t.Fatalf("%s: expected %q, got %q", k, "value", b)
+			}
+			return nil
+		})
+	}
+}
+
 // TestEndTransactionDirectGC verifies that after successfully resolving the
 // external intents of a transaction after EndTransaction, the transaction and
 // AbortSpan records are purged on both the local range and non-local range.
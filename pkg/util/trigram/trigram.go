@@ -0,0 +1,212 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package trigram computes the trigrams of strings and the similarity
+// measures derived from them, following the semantics of the PostgreSQL
+// pg_trgm extension.
+//
+// A string is lowercased and split into words, which are the maximal runs of
+// letters and digits. Every word is padded with two spaces at the front and
+// one space at the end, and the trigrams of the string are the distinct
+// three-character substrings of the padded words. For example, the trigrams
+// of "Cat" are "  c", " ca", "cat" and "at ".
+package trigram
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultSimilarityThreshold is the similarity at or above which the %
+// operator considers two strings to be similar.
+const DefaultSimilarityThreshold = 0.3
+
+// isWordChar returns whether r is part of a word.
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// appendWordTrigrams appends the trigrams of word, padded as requested, to
+// out in order of appearance.
+func appendWordTrigrams(out []string, word []rune, padLeft, padRight bool) []string {
+	padded := make([]rune, 0, len(word)+3)
+	if padLeft {
+		padded = append(padded, ' ', ' ')
+	}
+	padded = append(padded, word...)
+	if padRight {
+		padded = append(padded, ' ')
+	}
+	for i := 0; i+3 <= len(padded); i++ {
+		out = append(out, string(padded[i:i+3]))
+	}
+	return out
+}
+
+// orderedTrigrams returns the trigrams of the padded words of s, in order of
+// appearance and with repetitions.
+func orderedTrigrams(s string) []string {
+	var out []string
+	var word []rune
+	for _, r := range strings.ToLower(s) {
+		if isWordChar(r) {
+			word = append(word, r)
+			continue
+		}
+		if len(word) > 0 {
+			out = appendWordTrigrams(out, word, true /* padLeft */, true /* padRight */)
+			word = word[:0]
+		}
+	}
+	if len(word) > 0 {
+		out = appendWordTrigrams(out, word, true /* padLeft */, true /* padRight */)
+	}
+	return out
+}
+
+// sortedDistinct sorts trigrams and removes duplicates in place.
+func sortedDistinct(trigrams []string) []string {
+	sort.Strings(trigrams)
+	n := 0
+	for i := range trigrams {
+		if n == 0 || trigrams[n-1] != trigrams[i] {
+			trigrams[n] = trigrams[i]
+			n++
+		}
+	}
+	return trigrams[:n]
+}
+
+// MakeTrigrams returns the sorted, distinct trigrams of s.
+func MakeTrigrams(s string) []string {
+	return sortedDistinct(orderedTrigrams(s))
+}
+
+// countShared returns the number of elements that the sorted, distinct
+// slices a and b have in common.
+func countShared(a, b []string) int {
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			shared++
+			i++
+			j++
+		}
+	}
+	return shared
+}
+
+// Similarity returns the number of trigrams that a and b share divided by
+// the number of distinct trigrams in both of them; 1 means that the strings
+// have the same trigrams and 0 that they have none in common.
+func Similarity(a, b string) float64 {
+	ta, tb := MakeTrigrams(a), MakeTrigrams(b)
+	shared := countShared(ta, tb)
+	union := len(ta) + len(tb) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// WordSimilarity returns the greatest similarity between the trigrams of a
+// and the trigrams of any contiguous extent of the ordered trigrams of b. It
+// measures how well a matches a part of b, for instance a word in a longer
+// text.
+func WordSimilarity(a, b string) float64 {
+	ta := MakeTrigrams(a)
+	if len(ta) == 0 {
+		return 0
+	}
+	inA := make(map[string]struct{}, len(ta))
+	for _, t := range ta {
+		inA[t] = struct{}{}
+	}
+	tb := orderedTrigrams(b)
+	best := 0.0
+	for i := range tb {
+		// Extents that start with a trigram not in a are never better than the
+		// extent that starts at the next trigram.
+		if _, ok := inA[tb[i]]; !ok {
+			continue
+		}
+		seen := make(map[string]struct{})
+		shared := 0
+		for j := i; j < len(tb); j++ {
+			if _, ok := seen[tb[j]]; ok {
+				continue
+			}
+			seen[tb[j]] = struct{}{}
+			if _, ok := inA[tb[j]]; ok {
+				shared++
+			}
+			if sim := float64(shared) / float64(len(ta)+len(seen)-shared); sim > best {
+				best = sim
+			}
+		}
+	}
+	return best
+}
+
+// LikeTrigrams returns the sorted, distinct trigrams that the trigrams of
+// every string that matches the LIKE pattern must contain. The result does
+// not depend on case, so it applies to ILIKE as well. The pattern uses % and
+// _ as wildcards and backslash as the escape character.
+//
+// A word of the pattern is padded on a side only when the pattern shows that
+// the word ends there in the matching strings: it is next to a character
+// that is not part of a word, or at an end of the pattern that has no
+// wildcard.
+func LikeTrigrams(pattern string) []string {
+	var out []string
+	var word []rune
+	// padLeft is whether the word being accumulated started right after a
+	// non-word character or at the start of the pattern.
+	padLeft := true
+	flush := func(padRight bool) {
+		if len(word) > 0 {
+			out = appendWordTrigrams(out, word, padLeft, padRight)
+			word = word[:0]
+		}
+	}
+	escaped := false
+	for _, r := range strings.ToLower(pattern) {
+		if !escaped {
+			switch r {
+			case '\\':
+				escaped = true
+				continue
+			case '%', '_':
+				flush(false /* padRight */)
+				padLeft = false
+				continue
+			}
+		}
+		escaped = false
+		if isWordChar(r) {
+			word = append(word, r)
+			continue
+		}
+		flush(true /* padRight */)
+		padLeft = true
+	}
+	flush(true /* padRight */)
+	return sortedDistinct(out)
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package trigram
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMakeTrigrams(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected []string
+	}{
+		{``, nil},
+		{`!?`, nil},
+		{`a`, []string{`  a`, ` a `}},
+		{`Cat`, []string{`  c`, ` ca`, `at `, `cat`}},
+		{`cat, CAT`, []string{`  c`, ` ca`, `at `, `cat`}},
+		{`ab-cd`, []string{`  a`, `  c`, ` ab`, ` cd`, `ab `, `cd `}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			if res := MakeTrigrams(tc.s); !reflect.DeepEqual(res, tc.expected) {
+				t.Fatalf("expected %q, got %q", tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b               string
+		similarity         float64
+		wordSimilarity     float64
+		reverseWordSimilar float64
+	}{
+		{`word`, `two words`, 4.0 / 11, 0.8, 0.4},
+		{`cat`, `cat`, 1, 1, 1},
+		{`cat`, `dog`, 0, 0, 0},
+		{``, ``, 0, 0, 0},
+		{`cat`, ``, 0, 0, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s/%s", tc.a, tc.b), func(t *testing.T) {
+			check := func(name string, res, expected float64) {
+				if math.Abs(res-expected) > 1e-9 {
+					t.Errorf("%s: expected %f, got %f", name, expected, res)
+				}
+			}
+			check("similarity", Similarity(tc.a, tc.b), tc.similarity)
+			check("reverse similarity", Similarity(tc.b, tc.a), tc.similarity)
+			check("word similarity", WordSimilarity(tc.a, tc.b), tc.wordSimilarity)
+			check("reverse word similarity", WordSimilarity(tc.b, tc.a), tc.reverseWordSimilar)
+		})
+	}
+}
+
+func TestLikeTrigrams(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		expected []string
+	}{
+		{`%`, nil},
+		{`%ab%`, nil},
+		{`%abc%`, []string{`abc`}},
+		{`abc%`, []string{`  a`, ` ab`, `abc`}},
+		{`%abc`, []string{`abc`, `bc `}},
+		{`abc`, []string{`  a`, ` ab`, `abc`, `bc `}},
+		{`%Foo Bar%`, []string{`  b`, ` ba`, `bar`, `foo`, `oo `}},
+		{`%ab_cd%`, nil},
+		{`%ab\_cd%`, []string{`  c`, ` cd`, `ab `}},
+		{`%ab\%cd%`, []string{`  c`, ` cd`, `ab `}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			if res := LikeTrigrams(tc.pattern); !reflect.DeepEqual(res, tc.expected) {
+				t.Fatalf("expected %q, got %q", tc.expected, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tsearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Config is a text search configuration, which splits a document into words
+// and normalizes the words into lexemes.
+type Config struct {
+	name string
+	// normalize returns the lexeme for a lowercase word, or false if the word
+	// is a stop word that is left out of vectors and queries.
+	normalize func(word string) (string, bool)
+}
+
+// Name returns the name of the configuration.
+func (c *Config) Name() string { return c.name }
+
+// DefaultConfigName is the name of the configuration that is used when none
+// is specified.
+const DefaultConfigName = "english"
+
+var configs = map[string]*Config{
+	"simple":  {name: "simple", normalize: normalizeSimple},
+	"english": {name: "english", normalize: normalizeEnglish},
+}
+
+// GetConfig returns the configuration with the given name, which may be
+// qualified with pg_catalog.
+func GetConfig(name string) (*Config, bool) {
+	c, ok := configs[strings.TrimPrefix(strings.ToLower(name), "pg_catalog.")]
+	return c, ok
+}
+
+// normalizeSimple is the normalization of the simple configuration, which
+// uses the lowercase words as lexemes.
+func normalizeSimple(word string) (string, bool) {
+	return word, true
+}
+
+// normalizeEnglish is the normalization of the english configuration, which
+// leaves out English stop words and stems the other words made of ASCII
+// letters.
+func normalizeEnglish(word string) (string, bool) {
+	if _, ok := englishStopWords[word]; ok {
+		return "", false
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word, true
+		}
+	}
+	return stemEnglish(word), true
+}
+
+// splitWords returns the lowercase words of text, which are the maximal runs
+// of letters and digits.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// lexemes returns the lexemes of the words of text, leaving out stop words.
+func (c *Config) lexemes(text string) []string {
+	var out []string
+	for _, w := range splitWords(text) {
+		if l, ok := c.normalize(w); ok {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// ToVector returns the vector of a document, the PostgreSQL to_tsvector. The
+// position of a lexeme is the position of its word in the document,
+// counting stop words.
+func (c *Config) ToVector(document string) Vector {
+	var v Vector
+	for i, w := range splitWords(document) {
+		l, ok := c.normalize(w)
+		if !ok {
+			continue
+		}
+		pos := i + 1
+		if pos > MaxPosition {
+			pos = MaxPosition
+		}
+		v = append(v, Lexeme{Word: l, Positions: []Position{{Pos: uint16(pos)}}})
+	}
+	return normalizeVector(v)
+}
+
+// ToQuery parses the text representation of a query and normalizes its
+// lexemes, the PostgreSQL to_tsquery. A lexeme that normalizes to several
+// lexemes is replaced by their conjunction, and one that is a stop word is
+// removed from the query.
+func (c *Config) ToQuery(text string) (Query, error) {
+	return parseQuery(text, c.lexemes)
+}
+
+// PlainToQuery returns the query that matches the normalized lexemes of all
+// of the words of text, the PostgreSQL plainto_tsquery.
+func (c *Config) PlainToQuery(text string) Query {
+	return makeAndQuery(c.lexemes(text))
+}
+
+// englishStopWords are the stop words of the english configuration.
+var englishStopWords = func() map[string]struct{} {
+	words := strings.Fields(`
+		i me my myself we our ours ourselves you your yours yourself yourselves
+		he him his himself she her hers herself it its itself they them their
+		theirs themselves what which who whom this that these those am is are
+		was were be been being have has had having do does did doing a an the
+		and but if or because as until while of at by for with about against
+		between into through during before after above below to from up down
+		in out on off over under again further then once here there when where
+		why how all any both each few more most other some such no nor not only
+		own same so than too very s t can will just don should now`)
+	m := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		m[w] = struct{}{}
+	}
+	return m
+}()
@@ -0,0 +1,356 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tsearch
+
+import (
+	"bytes"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// Operator is the operator of a query node.
+type Operator uint8
+
+// The query operators. The binary operators are listed in increasing order
+// of precedence.
+const (
+	// OpLexeme is the operator of a leaf node, which matches a lexeme.
+	OpLexeme Operator = iota
+	OpOr
+	OpAnd
+	OpNot
+)
+
+// Node is a node of a query.
+type Node struct {
+	Op Operator
+	// Word, Prefix and Weights are only set for OpLexeme. Prefix means that
+	// Word matches every lexeme that starts with it. Weights, if not zero, is
+	// the set of weights, as bits 1<<Weight, of the positions of a lexeme
+	// that can match.
+	Word    string
+	Prefix  bool
+	Weights uint8
+	// Left is the operand of OpNot; Left and Right are the operands of OpAnd
+	// and OpOr.
+	Left, Right *Node
+}
+
+// Query is a text search query, the PostgreSQL tsquery. The empty query has
+// a nil root and matches nothing.
+type Query struct {
+	Root *Node
+}
+
+// Lexemes returns the lexeme nodes of q in order of appearance.
+func (q Query) Lexemes() []*Node {
+	var out []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n == nil {
+			return
+		}
+		if n.Op == OpLexeme {
+			out = append(out, n)
+			return
+		}
+		walk(n.Left)
+		walk(n.Right)
+	}
+	walk(q.Root)
+	return out
+}
+
+// String returns the canonical text representation of q, for instance
+// 'fat' & ( 'rat' | 'cat':* ).
+func (q Query) String() string {
+	var buf bytes.Buffer
+	if q.Root != nil {
+		q.Root.format(&buf, OpLexeme)
+	}
+	return buf.String()
+}
+
+// Size returns the approximate size of q in bytes.
+func (q Query) Size() uintptr {
+	var size uintptr
+	for _, n := range q.Lexemes() {
+		size += uintptr(len(n.Word)) + 64
+	}
+	return size
+}
+
+// format writes n; parent is the operator of the node that contains it,
+// which decides whether n needs parentheses.
+func (n *Node) format(buf *bytes.Buffer, parent Operator) {
+	switch n.Op {
+	case OpLexeme:
+		writeQuotedWord(buf, n.Word)
+		if n.Prefix || n.Weights != 0 {
+			buf.WriteByte(':')
+			if n.Prefix {
+				buf.WriteByte('*')
+			}
+			for w := WeightA; ; w-- {
+				if n.Weights&(1<<w) != 0 {
+					buf.WriteString(w.String())
+				}
+				if w == WeightD {
+					break
+				}
+			}
+		}
+	case OpNot:
+		buf.WriteByte('!')
+		n.Left.format(buf, OpNot)
+	default:
+		paren := n.Op < parent
+		if paren {
+			buf.WriteString("( ")
+		}
+		n.Left.format(buf, n.Op)
+		if n.Op == OpAnd {
+			buf.WriteString(" & ")
+		} else {
+			buf.WriteString(" | ")
+		}
+		n.Right.format(buf, n.Op)
+		if paren {
+			buf.WriteString(" )")
+		}
+	}
+}
+
+// Matches returns whether v matches q.
+func (q Query) Matches(v Vector) bool {
+	if q.Root == nil {
+		return false
+	}
+	return q.Root.matches(v)
+}
+
+func (n *Node) matches(v Vector) bool {
+	switch n.Op {
+	case OpLexeme:
+		if !n.Prefix {
+			i, ok := v.find(n.Word)
+			return ok && n.matchesWeight(v[i])
+		}
+		start, end := v.findPrefix(n.Word)
+		for i := start; i < end; i++ {
+			if n.matchesWeight(v[i]) {
+				return true
+			}
+		}
+		return false
+	case OpNot:
+		return !n.Left.matches(v)
+	case OpAnd:
+		return n.Left.matches(v) && n.Right.matches(v)
+	default:
+		return n.Left.matches(v) || n.Right.matches(v)
+	}
+}
+
+// matchesWeight returns whether l has a position of a weight that n allows.
+// A lexeme without positions matches any weight.
+func (n *Node) matchesWeight(l Lexeme) bool {
+	if n.Weights == 0 || len(l.Positions) == 0 {
+		return true
+	}
+	for _, p := range l.Positions {
+		if n.Weights&(1<<p.Weight) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	errQuerySyntax = pgerror.NewError(pgerror.CodeSyntaxError, "syntax error in tsquery")
+	errQueryPhrase = pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+		"phrase search operators are not supported")
+)
+
+// queryParser parses the text representation of a query. The operators are,
+// in decreasing order of precedence, ! (not), & (and) and | (or), and
+// parentheses group. A lexeme may be followed by a colon and then * for a
+// prefix match and the letters of the weights it is restricted to.
+type queryParser struct {
+	scanner
+	// normalize, if set, turns a lexeme of the query text into the lexemes
+	// that the query matches. It may return no lexemes, in which case the
+	// lexeme is removed from the query.
+	normalize func(word string) []string
+}
+
+const queryDelimiters = "&|!():<"
+
+// ParseQuery parses the text representation of a query.
+func ParseQuery(s string) (Query, error) {
+	return parseQuery(s, nil /* normalize */)
+}
+
+func parseQuery(s string, normalize func(string) []string) (Query, error) {
+	p := queryParser{scanner: scanner{input: []rune(s)}, normalize: normalize}
+	p.skipSpace()
+	if p.eof() {
+		return Query{}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return Query{}, p.syntaxError()
+	}
+	return Query{Root: root}, nil
+}
+
+func (p *queryParser) syntaxError() error {
+	p.skipSpace()
+	if !p.eof() && p.peek() == '<' {
+		return errQueryPhrase
+	}
+	return errQuerySyntax
+}
+
+// The parse functions return a nil node, without an error, for an operand
+// that normalization removed entirely.
+
+func (p *queryParser) parseOr() (*Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() != '|' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = combine(OpOr, left, right)
+	}
+}
+
+func (p *queryParser) parseAnd() (*Node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() != '&' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = combine(OpAnd, left, right)
+	}
+}
+
+func (p *queryParser) parseNot() (*Node, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.syntaxError()
+	}
+	switch p.peek() {
+	case '!':
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil || operand == nil {
+			return nil, err
+		}
+		return &Node{Op: OpNot, Left: operand}, nil
+	case '(':
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.eof() || p.peek() != ')' {
+			return nil, p.syntaxError()
+		}
+		p.pos++
+		return n, nil
+	}
+	return p.parseLexeme()
+}
+
+func (p *queryParser) parseLexeme() (*Node, error) {
+	word, err := p.scanWord(queryDelimiters)
+	if err != nil {
+		return nil, err
+	}
+	if word == "" {
+		return nil, p.syntaxError()
+	}
+	var prefix bool
+	var weights uint8
+	if !p.eof() && p.peek() == ':' {
+		p.pos++
+	flags:
+		for !p.eof() {
+			r := p.peek()
+			if r == '*' {
+				prefix = true
+			} else if w, ok := parseWeight(r); ok {
+				weights |= 1 << w
+			} else {
+				break flags
+			}
+			p.pos++
+		}
+	}
+	words := []string{word}
+	if p.normalize != nil {
+		words = p.normalize(word)
+	}
+	var n *Node
+	for _, w := range words {
+		n = combine(OpAnd, n, &Node{Op: OpLexeme, Word: w, Prefix: prefix, Weights: weights})
+	}
+	return n, nil
+}
+
+// combine returns the node for the binary operation op, or the other operand
+// if one of them is nil.
+func combine(op Operator, left, right *Node) *Node {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	}
+	return &Node{Op: op, Left: left, Right: right}
+}
+
+// makeAndQuery returns the query that matches all of words.
+func makeAndQuery(words []string) Query {
+	var root *Node
+	for _, w := range words {
+		root = combine(OpAnd, root, &Node{Op: OpLexeme, Word: w})
+	}
+	return Query{Root: root}
+}
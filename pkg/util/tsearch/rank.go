@@ -0,0 +1,207 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tsearch
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultRankWeights are the factors by which Rank multiplies the positions
+// of weights D, C, B and A.
+var DefaultRankWeights = [4]float64{0.1, 0.2, 0.4, 1.0}
+
+// The bits of the normalization argument of Rank.
+const (
+	// RankNormLogLength divides the rank by 1 + the logarithm of the length
+	// of the document, which is its number of positions.
+	RankNormLogLength = 1 << iota
+	// RankNormLength divides the rank by the length of the document.
+	RankNormLength
+	// rankNormExtDist only applies to cover density ranking, which is not
+	// implemented.
+	rankNormExtDist
+	// RankNormUniq divides the rank by the number of distinct lexemes of the
+	// document.
+	RankNormUniq
+	// RankNormLogUniq divides the rank by 1 + the logarithm of the number of
+	// distinct lexemes of the document.
+	RankNormLogUniq
+	// RankNormRankPlusOne divides the rank by itself plus one.
+	RankNormRankPlusOne
+)
+
+// nullPositions and andNullPositions stand in for the positions of a lexeme
+// that has none when ranking disjunctions and conjunctions respectively.
+var (
+	nullPositions    = []Position{{}}
+	andNullPositions = []Position{{Pos: MaxPosition}}
+)
+
+// Rank returns how well v matches q based on the frequency of the matching
+// lexemes, the PostgreSQL ts_rank. The normalization is a combination of the
+// RankNorm bits.
+func Rank(weights [4]float64, v Vector, q Query, normalization int) float64 {
+	words := q.rankWords()
+	var res float64
+	if q.Root != nil && q.Root.Op == OpAnd && len(words) > 1 {
+		res = rankAnd(weights, v, words)
+	} else {
+		res = rankOr(weights, v, words)
+	}
+	if res < 0 {
+		res = 1e-20
+	}
+
+	if normalization&RankNormLogLength != 0 && len(v) > 0 {
+		res /= math.Log(float64(v.length())+1) / math.Log(2)
+	}
+	if normalization&RankNormLength != 0 && len(v) > 0 {
+		if l := v.length(); l > 0 {
+			res /= float64(l)
+		}
+	}
+	if normalization&RankNormUniq != 0 && len(v) > 0 {
+		res /= float64(len(v))
+	}
+	if normalization&RankNormLogUniq != 0 && len(v) > 0 {
+		res /= math.Log(float64(len(v))+1) / math.Log(2)
+	}
+	if normalization&RankNormRankPlusOne != 0 {
+		res /= res + 1
+	}
+	return res
+}
+
+// length returns the number of positions of v, counting a lexeme without
+// positions once.
+func (v Vector) length() int {
+	l := 0
+	for i := range v {
+		if n := len(v[i].Positions); n > 0 {
+			l += n
+		} else {
+			l++
+		}
+	}
+	return l
+}
+
+// rankWords returns the distinct lexeme nodes of q, sorted by word.
+func (q Query) rankWords() []*Node {
+	nodes := q.Lexemes()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Word != nodes[j].Word {
+			return nodes[i].Word < nodes[j].Word
+		}
+		return !nodes[i].Prefix && nodes[j].Prefix
+	})
+	n := 0
+	for i := range nodes {
+		if n > 0 && nodes[n-1].Word == nodes[i].Word && nodes[n-1].Prefix == nodes[i].Prefix {
+			continue
+		}
+		nodes[n] = nodes[i]
+		n++
+	}
+	return nodes[:n]
+}
+
+// matchingLexemes returns the lexemes of v that the lexeme node n matches,
+// disregarding weights.
+func (n *Node) matchingLexemes(v Vector) []Lexeme {
+	if n.Prefix {
+		start, end := v.findPrefix(n.Word)
+		return v[start:end]
+	}
+	if i, ok := v.find(n.Word); ok {
+		return v[i : i+1]
+	}
+	return nil
+}
+
+func positionsOf(l Lexeme, null []Position) []Position {
+	if len(l.Positions) == 0 {
+		return null
+	}
+	return l.Positions
+}
+
+func rankOr(weights [4]float64, v Vector, words []*Node) float64 {
+	if len(words) == 0 {
+		return 0
+	}
+	var res float64
+	for _, n := range words {
+		for _, l := range n.matchingLexemes(v) {
+			var resj float64
+			wjm, jm := -1.0, 0
+			for j, p := range positionsOf(l, nullPositions) {
+				w := weights[p.Weight]
+				resj += w / float64((j+1)*(j+1))
+				if w > wjm {
+					wjm, jm = w, j
+				}
+			}
+			// The limit of sum(1/i^2) is pi^2/6.
+			res += (wjm + resj - wjm/float64((jm+1)*(jm+1))) / 1.64493406685
+		}
+	}
+	return res / float64(len(words))
+}
+
+// wordDistance returns the factor for two positions at a distance of dist.
+func wordDistance(dist int) float64 {
+	if dist > 100 {
+		return 1e-30
+	}
+	return 1.0 / (1.005 + 0.05*math.Exp(float64(dist)/1.5-2))
+}
+
+func rankAnd(weights [4]float64, v Vector, words []*Node) float64 {
+	res := -1.0
+	positions := make([][]Position, len(words))
+	for i, n := range words {
+		for _, l := range n.matchingLexemes(v) {
+			positions[i] = positionsOf(l, andNullPositions)
+			for k := 0; k < i; k++ {
+				if positions[k] == nil {
+					continue
+				}
+				for _, pi := range positions[i] {
+					for _, pk := range positions[k] {
+						dist := int(pi.Pos) - int(pk.Pos)
+						if dist < 0 {
+							dist = -dist
+						}
+						if dist == 0 {
+							if len(l.Positions) > 0 && &positions[k][0] != &andNullPositions[0] {
+								continue
+							}
+							dist = MaxPosition + 1
+						}
+						curw := math.Sqrt(weights[pi.Weight] * weights[pk.Weight] * wordDistance(dist))
+						if res < 0 {
+							res = curw
+						} else {
+							res = 1 - (1-res)*(1-curw)
+						}
+					}
+				}
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,333 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tsearch
+
+import "strings"
+
+// This file implements the Snowball English (Porter2) stemmer, which is the
+// stemmer of the PostgreSQL english configuration. The steps and their names
+// follow the published description of the algorithm.
+
+// stemExceptions are the words whose stems the algorithm does not derive.
+var stemExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// step1aExceptions are the words that are left alone after step 1a.
+var step1aExceptions = map[string]struct{}{
+	"inning": {}, "outing": {}, "canning": {}, "herring": {}, "earring": {},
+	"proceed": {}, "exceed": {}, "succeed": {},
+}
+
+// stemmer holds a word being stemmed, as a byte slice of lowercase ASCII
+// letters in which the consonant y is written Y, and its regions.
+type stemmer struct {
+	w      []byte
+	r1, r2 int
+}
+
+func isStemVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+// stemEnglish returns the stem of word, which must consist of lowercase
+// ASCII letters.
+func stemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	if stem, ok := stemExceptions[word]; ok {
+		return stem
+	}
+	s := stemmer{w: []byte(word)}
+	for i := range s.w {
+		if s.w[i] == 'y' && (i == 0 || isStemVowel(s.w[i-1])) {
+			s.w[i] = 'Y'
+		}
+	}
+	s.computeRegions()
+	s.step1a()
+	if _, ok := step1aExceptions[string(s.w)]; ok {
+		return string(s.w)
+	}
+	s.step1b()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5()
+	return strings.Replace(string(s.w), "Y", "y", -1)
+}
+
+// computeRegions sets R1, the region after the first non-vowel that follows
+// a vowel, and R2, the same region computed within R1.
+func (s *stemmer) computeRegions() {
+	s.r1 = -1
+	for _, p := range []string{"gener", "commun", "arsen"} {
+		if s.hasPrefix(p) {
+			s.r1 = len(p)
+			break
+		}
+	}
+	if s.r1 < 0 {
+		s.r1 = s.regionAfter(0)
+	}
+	s.r2 = s.regionAfter(s.r1)
+}
+
+func (s *stemmer) regionAfter(start int) int {
+	for i := start + 1; i < len(s.w); i++ {
+		if !isStemVowel(s.w[i]) && isStemVowel(s.w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(s.w)
+}
+
+func (s *stemmer) hasPrefix(p string) bool {
+	return strings.HasPrefix(string(s.w), p)
+}
+
+func (s *stemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.w), suffix)
+}
+
+// longestSuffix returns the longest of the suffixes that the word ends with.
+func (s *stemmer) longestSuffix(suffixes ...string) string {
+	longest := ""
+	for _, suffix := range suffixes {
+		if len(suffix) > len(longest) && s.hasSuffix(suffix) {
+			longest = suffix
+		}
+	}
+	return longest
+}
+
+// inR1 and inR2 return whether the suffix lies within the region.
+func (s *stemmer) inR1(suffix string) bool { return len(s.w)-len(suffix) >= s.r1 }
+func (s *stemmer) inR2(suffix string) bool { return len(s.w)-len(suffix) >= s.r2 }
+
+// replace replaces the suffix with repl.
+func (s *stemmer) replace(suffix, repl string) {
+	s.w = append(s.w[:len(s.w)-len(suffix)], repl...)
+}
+
+// containsVowel returns whether w[:end] contains a vowel.
+func (s *stemmer) containsVowel(end int) bool {
+	for i := 0; i < end; i++ {
+		if isStemVowel(s.w[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsInShortSyllable returns whether the word ends in a vowel followed by a
+// non-vowel other than w, x or Y and preceded by a non-vowel, or is a vowel
+// followed by a non-vowel.
+func (s *stemmer) endsInShortSyllable() bool {
+	n := len(s.w)
+	if n == 2 {
+		return isStemVowel(s.w[0]) && !isStemVowel(s.w[1])
+	}
+	if n < 3 {
+		return false
+	}
+	c := s.w[n-1]
+	return !isStemVowel(s.w[n-3]) && isStemVowel(s.w[n-2]) && !isStemVowel(c) &&
+		c != 'w' && c != 'x' && c != 'Y'
+}
+
+// isShort returns whether the word ends in a short syllable and R1 is empty.
+func (s *stemmer) isShort() bool {
+	return s.r1 >= len(s.w) && s.endsInShortSyllable()
+}
+
+func (s *stemmer) step1a() {
+	switch suffix := s.longestSuffix("sses", "ied", "ies", "s", "us", "ss"); suffix {
+	case "sses":
+		s.replace(suffix, "ss")
+	case "ied", "ies":
+		if len(s.w) > 4 {
+			s.replace(suffix, "i")
+		} else {
+			s.replace(suffix, "ie")
+		}
+	case "s":
+		// Delete the s if the preceding part contains a vowel that is not
+		// immediately before it.
+		if s.containsVowel(len(s.w) - 2) {
+			s.replace(suffix, "")
+		}
+	}
+}
+
+func (s *stemmer) step1b() {
+	suffix := s.longestSuffix("eed", "eedly", "ed", "edly", "ing", "ingly")
+	switch suffix {
+	case "":
+		return
+	case "eed", "eedly":
+		if s.inR1(suffix) {
+			s.replace(suffix, "ee")
+		}
+		return
+	}
+	if !s.containsVowel(len(s.w) - len(suffix)) {
+		return
+	}
+	s.replace(suffix, "")
+	switch {
+	case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+		s.w = append(s.w, 'e')
+	case s.endsInDouble():
+		s.w = s.w[:len(s.w)-1]
+	case s.isShort():
+		s.w = append(s.w, 'e')
+	}
+}
+
+// endsInDouble returns whether the word ends in one of the doubles bb, dd,
+// ff, gg, mm, nn, pp, rr and tt.
+func (s *stemmer) endsInDouble() bool {
+	n := len(s.w)
+	if n < 2 || s.w[n-1] != s.w[n-2] {
+		return false
+	}
+	return strings.IndexByte("bdfgmnprt", s.w[n-1]) >= 0
+}
+
+func (s *stemmer) step1c() {
+	n := len(s.w)
+	if n > 2 && (s.w[n-1] == 'y' || s.w[n-1] == 'Y') && !isStemVowel(s.w[n-2]) {
+		s.w[n-1] = 'i'
+	}
+}
+
+var step2Suffixes = map[string]string{
+	"tional": "tion", "enci": "ence", "anci": "ance", "abli": "able",
+	"entli": "ent", "izer": "ize", "ization": "ize", "ational": "ate",
+	"ation": "ate", "ator": "ate", "alism": "al", "aliti": "al", "alli": "al",
+	"fulness": "ful", "ousli": "ous", "ousness": "ous", "iveness": "ive",
+	"iviti": "ive", "biliti": "ble", "bli": "ble", "ogi": "og", "fulli": "ful",
+	"lessli": "less", "li": "",
+}
+
+func (s *stemmer) step2() {
+	suffix := s.longestSuffixIn(step2Suffixes)
+	if suffix == "" || !s.inR1(suffix) {
+		return
+	}
+	before := len(s.w) - len(suffix)
+	switch suffix {
+	case "ogi":
+		if before == 0 || s.w[before-1] != 'l' {
+			return
+		}
+	case "li":
+		if before == 0 || strings.IndexByte("cdeghkmnrt", s.w[before-1]) < 0 {
+			return
+		}
+	}
+	s.replace(suffix, step2Suffixes[suffix])
+}
+
+var step3Suffixes = map[string]string{
+	"tional": "tion", "ational": "ate", "alize": "al", "icate": "ic",
+	"iciti": "ic", "ical": "ic", "ful": "", "ness": "", "ative": "",
+}
+
+func (s *stemmer) step3() {
+	suffix := s.longestSuffixIn(step3Suffixes)
+	if suffix == "" || !s.inR1(suffix) {
+		return
+	}
+	if suffix == "ative" && !s.inR2(suffix) {
+		return
+	}
+	s.replace(suffix, step3Suffixes[suffix])
+}
+
+var step4Suffixes = map[string]string{
+	"al": "", "ance": "", "ence": "", "er": "", "ic": "", "able": "",
+	"ible": "", "ant": "", "ement": "", "ment": "", "ent": "", "ism": "",
+	"ate": "", "iti": "", "ous": "", "ive": "", "ize": "", "ion": "",
+}
+
+func (s *stemmer) step4() {
+	suffix := s.longestSuffixIn(step4Suffixes)
+	if suffix == "" || !s.inR2(suffix) {
+		return
+	}
+	if suffix == "ion" {
+		before := len(s.w) - len(suffix)
+		if before == 0 || (s.w[before-1] != 's' && s.w[before-1] != 't') {
+			return
+		}
+	}
+	s.replace(suffix, "")
+}
+
+func (s *stemmer) step5() {
+	n := len(s.w)
+	switch s.w[n-1] {
+	case 'e':
+		if s.inR2("e") {
+			s.w = s.w[:n-1]
+			return
+		}
+		if s.inR1("e") {
+			s.w = s.w[:n-1]
+			if s.endsInShortSyllable() {
+				s.w = append(s.w, 'e')
+			}
+		}
+	case 'l':
+		if s.inR2("l") && n > 1 && s.w[n-2] == 'l' {
+			s.w = s.w[:n-1]
+		}
+	}
+}
+
+func (s *stemmer) longestSuffixIn(suffixes map[string]string) string {
+	longest := ""
+	for suffix := range suffixes {
+		if len(suffix) > len(longest) && s.hasSuffix(suffix) {
+			longest = suffix
+		}
+	}
+	return longest
+}
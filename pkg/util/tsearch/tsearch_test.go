@@ -0,0 +1,297 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tsearch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestParseVector(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected string
+	}{
+		{``, ``},
+		{`a fat cat sat on a mat and ate a fat rat`,
+			`'a' 'and' 'ate' 'cat' 'fat' 'mat' 'on' 'rat' 'sat'`},
+		{`fat:2,4 cat:3 rat:5A`, `'cat':3 'fat':2,4 'rat':5A`},
+		{`a:1 a:3,1b`, `'a':1B,3`},
+		{`x:20000`, `'x':16383`},
+		{`'the lexeme ''  '' contains spaces'`, `'the lexeme ''  '' contains spaces'`},
+		{`'    ' contains`, `'    ' 'contains'`},
+		{`a\:b c\\d`, `'a:b' 'c\\d'`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			v, err := ParseVector(tc.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := v.String(); s != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, s)
+			}
+			// The canonical representation parses to the same vector.
+			v2, err := ParseVector(v.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v2.String() != v.String() {
+				t.Fatalf("expected %s after round trip, got %s", v, v2)
+			}
+		})
+	}
+
+	errCases := []struct {
+		s   string
+		err string
+	}{
+		{`'abc`, `unterminated quoted string`},
+		{`''`, `syntax error in tsvector`},
+		{`a:0`, `wrong position info in tsvector`},
+		{`a:`, `wrong position info in tsvector`},
+		{`a:1x`, `syntax error in tsvector`},
+	}
+	for _, tc := range errCases {
+		t.Run(tc.s, func(t *testing.T) {
+			if _, err := ParseVector(tc.s); !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected string
+	}{
+		{``, ``},
+		{`fat & rat`, `'fat' & 'rat'`},
+		{`fat & (rat | cat)`, `'fat' & ( 'rat' | 'cat' )`},
+		{`fat | rat & cat`, `'fat' | 'rat' & 'cat'`},
+		{`fat & rat & ! cat`, `'fat' & 'rat' & !'cat'`},
+		{`!(a & b)`, `!( 'a' & 'b' )`},
+		{`!!a`, `!!'a'`},
+		{`fat:ab & cat`, `'fat':AB & 'cat'`},
+		{`super:*`, `'super':*`},
+		{`super:*d`, `'super':*D`},
+		{`'it''s'`, `'it''s'`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			q, err := ParseQuery(tc.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := q.String(); s != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, s)
+			}
+			q2, err := ParseQuery(q.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q2.String() != q.String() {
+				t.Fatalf("expected %s after round trip, got %s", q, q2)
+			}
+		})
+	}
+
+	errCases := []struct {
+		s   string
+		err string
+	}{
+		{`a &`, `syntax error in tsquery`},
+		{`a b`, `syntax error in tsquery`},
+		{`(a | b`, `syntax error in tsquery`},
+		{`a & & b`, `syntax error in tsquery`},
+		{`a <-> b`, `phrase search operators are not supported`},
+	}
+	for _, tc := range errCases {
+		t.Run(tc.s, func(t *testing.T) {
+			if _, err := ParseQuery(tc.s); !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	testCases := []struct {
+		vector, query string
+		expected      bool
+	}{
+		{`a fat cat`, `cat`, true},
+		{`a fat cat`, `dog`, false},
+		{`a fat cat`, `fat & cat`, true},
+		{`a fat cat`, `fat & dog`, false},
+		{`a fat cat`, `dog | cat`, true},
+		{`a fat cat`, `!dog`, true},
+		{`a fat cat`, `!cat`, false},
+		{`a fat cat`, `fat & !(dog | rat)`, true},
+		{`a fat cat`, `ca:*`, true},
+		{`a fat cat`, `cb:*`, false},
+		{`fat:1A cat:2`, `fat:A`, true},
+		{`fat:1A cat:2`, `fat:B`, false},
+		{`fat:1A cat:2`, `cat:A`, false},
+		{`fat:1A cat:2`, `cat:D`, true},
+		{`fat cat`, `cat:A`, true},
+		{`fat cat`, ``, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.vector+"@@"+tc.query, func(t *testing.T) {
+			v, err := ParseVector(tc.vector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q, err := ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res := q.Matches(v); res != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	simple, _ := GetConfig("simple")
+	english, _ := GetConfig("pg_catalog.english")
+	if _, ok := GetConfig("klingon"); ok {
+		t.Fatal("expected no klingon configuration")
+	}
+
+	vectorCases := []struct {
+		config   *Config
+		document string
+		expected string
+	}{
+		{simple, `The Fat Rats`, `'fat':2 'rats':3 'the':1`},
+		{english, `The Fat Rats`, `'fat':2 'rat':3`},
+		{english, `a fat  cat sat on a mat - it ate a fat rats`,
+			`'ate':9 'cat':3 'fat':2,11 'mat':7 'rat':12 'sat':4`},
+		{english, `Supernovae stars are the brightest phenomena in galaxies`,
+			`'brightest':5 'galaxi':8 'phenomena':6 'star':2 'supernova':1`},
+		{english, `the 3 R2D2s`, `'3':2 'r2d2s':3`},
+	}
+	for _, tc := range vectorCases {
+		t.Run(tc.config.Name()+"/"+tc.document, func(t *testing.T) {
+			if s := tc.config.ToVector(tc.document).String(); s != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, s)
+			}
+		})
+	}
+
+	queryCases := []struct {
+		config   *Config
+		query    string
+		expected string
+	}{
+		{simple, `The & Fat & Rats`, `'the' & 'fat' & 'rats'`},
+		{english, `The & Fat & Rats`, `'fat' & 'rat'`},
+		{english, `the | (an & !a)`, ``},
+		{english, `Supernovae:*`, `'supernova':*`},
+		{english, `'fat rats':A | cat`, `'fat':A & 'rat':A | 'cat'`},
+	}
+	for _, tc := range queryCases {
+		t.Run(tc.config.Name()+"/"+tc.query, func(t *testing.T) {
+			q, err := tc.config.ToQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := q.String(); s != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, s)
+			}
+		})
+	}
+
+	if s := english.PlainToQuery(`The Fat & Rats`).String(); s != `'fat' & 'rat'` {
+		t.Fatalf("expected 'fat' & 'rat', got %s", s)
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	testCases := map[string]string{
+		"consign":      "consign",
+		"consigned":    "consign",
+		"consignment":  "consign",
+		"consistency":  "consist",
+		"consistently": "consist",
+		"consolation":  "consol",
+		"consolatory":  "consolatori",
+		"consolidate":  "consolid",
+		"consolingly":  "consol",
+		"conspiracy":   "conspiraci",
+		"conspirators": "conspir",
+		"constable":    "constabl",
+		"constancy":    "constanc",
+		"knackeries":   "knackeri",
+		"knavish":      "knavish",
+		"kneeling":     "kneel",
+		"knightly":     "knight",
+		"knitting":     "knit",
+		"knives":       "knive",
+		"caresses":     "caress",
+		"ponies":       "poni",
+		"ties":         "tie",
+		"gas":          "gas",
+		"gaps":         "gap",
+		"hopping":      "hop",
+		"filing":       "file",
+		"happy":        "happi",
+		"relational":   "relat",
+		"generously":   "generous",
+		"skies":        "sky",
+		"succeed":      "succeed",
+		"by":           "by",
+	}
+	for word, expected := range testCases {
+		if stem := stemEnglish(word); stem != expected {
+			t.Errorf("%s: expected %s, got %s", word, expected, stem)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	english, _ := GetConfig("english")
+	testCases := []struct {
+		document, query string
+		normalization   int
+		expected        float64
+	}{
+		{`a fat cat`, `cat`, 0, 0.1 / 1.64493406685},
+		{`a fat cat`, `dog`, 0, 0},
+		{`a fat cat`, `cat`, RankNormRankPlusOne, 0.0573084},
+		{`a fat cat`, `fat & cat`, 0, 0.0991032},
+		{`a fat cat`, `fat & dog`, 0, 1e-20},
+		{`cat cat cat`, `cat`, 0, 0.0827456},
+		{`cat cat cat`, `cat`, RankNormLength, 0.0275819},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.document+"/"+tc.query, func(t *testing.T) {
+			q, err := english.ToQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := english.ToVector(tc.document)
+			if res := Rank(DefaultRankWeights, v, q, tc.normalization); math.Abs(res-tc.expected) > 1e-6 {
+				t.Fatalf("expected %g, got %g", tc.expected, res)
+			}
+		})
+	}
+}
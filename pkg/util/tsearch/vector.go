@@ -0,0 +1,340 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tsearch implements the text search vectors and queries of
+// PostgreSQL full-text search, along with the text search configurations
+// that turn documents into vectors and queries.
+//
+// A vector is a sorted set of distinct lexemes, each of which may carry the
+// positions at which it appears in the document and a weight for each
+// position. A query is a boolean combination of lexemes, which may be
+// restricted to a prefix match or to positions of certain weights.
+package tsearch
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// Weight is the weight of a lexeme position. Weights are used to mark
+// lexemes from different parts of a document, such as the title and the
+// body, and to rank them differently.
+type Weight uint8
+
+// The weights, in increasing order of importance. D is the default weight.
+const (
+	WeightD Weight = iota
+	WeightC
+	WeightB
+	WeightA
+)
+
+// String returns the letter of the weight.
+func (w Weight) String() string {
+	return string(rune('D' - w))
+}
+
+// parseWeight returns the weight for the given letter, if it is one.
+func parseWeight(r rune) (Weight, bool) {
+	switch unicode.ToUpper(r) {
+	case 'A':
+		return WeightA, true
+	case 'B':
+		return WeightB, true
+	case 'C':
+		return WeightC, true
+	case 'D':
+		return WeightD, true
+	}
+	return 0, false
+}
+
+// MaxPosition is the largest position that a vector records; larger
+// positions are recorded as MaxPosition.
+const MaxPosition = 1<<14 - 1
+
+// maxPositionsPerLexeme is the maximum number of positions that a vector
+// records for a lexeme; the positions past it are dropped.
+const maxPositionsPerLexeme = 256
+
+// Position is a position of a lexeme in a document, starting at 1.
+type Position struct {
+	Pos    uint16
+	Weight Weight
+}
+
+// Lexeme is a normalized word of a vector.
+type Lexeme struct {
+	Word string
+	// Positions are sorted and distinct. A lexeme has no positions when its
+	// vector was written without them.
+	Positions []Position
+}
+
+// Vector is a text search vector, the PostgreSQL tsvector. Its lexemes are
+// sorted by word and distinct.
+type Vector []Lexeme
+
+// Words returns the words of the lexemes of v, in order.
+func (v Vector) Words() []string {
+	if len(v) == 0 {
+		return nil
+	}
+	words := make([]string, len(v))
+	for i := range v {
+		words[i] = v[i].Word
+	}
+	return words
+}
+
+// find returns the index of the lexeme with the given word, if there is one.
+func (v Vector) find(word string) (int, bool) {
+	i := sort.Search(len(v), func(i int) bool { return v[i].Word >= word })
+	return i, i < len(v) && v[i].Word == word
+}
+
+// findPrefix returns the range of indexes of the lexemes whose words start
+// with prefix.
+func (v Vector) findPrefix(prefix string) (start, end int) {
+	start = sort.Search(len(v), func(i int) bool { return v[i].Word >= prefix })
+	end = start
+	for end < len(v) && strings.HasPrefix(v[end].Word, prefix) {
+		end++
+	}
+	return start, end
+}
+
+// normalizeVector sorts the lexemes of v and merges the ones with the same
+// word.
+func normalizeVector(v Vector) Vector {
+	sort.SliceStable(v, func(i, j int) bool { return v[i].Word < v[j].Word })
+	n := 0
+	for i := range v {
+		if n > 0 && v[n-1].Word == v[i].Word {
+			v[n-1].Positions = append(v[n-1].Positions, v[i].Positions...)
+			continue
+		}
+		v[n] = v[i]
+		n++
+	}
+	v = v[:n]
+	for i := range v {
+		v[i].Positions = normalizePositions(v[i].Positions)
+	}
+	return v
+}
+
+// normalizePositions sorts positions and removes duplicates, keeping the
+// greatest weight of a duplicated position.
+func normalizePositions(positions []Position) []Position {
+	if len(positions) == 0 {
+		return nil
+	}
+	sort.SliceStable(positions, func(i, j int) bool { return positions[i].Pos < positions[j].Pos })
+	n := 0
+	for i := range positions {
+		if n > 0 && positions[n-1].Pos == positions[i].Pos {
+			if positions[i].Weight > positions[n-1].Weight {
+				positions[n-1].Weight = positions[i].Weight
+			}
+			continue
+		}
+		positions[n] = positions[i]
+		n++
+	}
+	if n > maxPositionsPerLexeme {
+		n = maxPositionsPerLexeme
+	}
+	return positions[:n]
+}
+
+// writeQuotedWord writes word enclosed in single quotes, doubling the quotes
+// and backslashes that it contains.
+func writeQuotedWord(buf *bytes.Buffer, word string) {
+	buf.WriteByte('\'')
+	for _, r := range word {
+		if r == '\'' || r == '\\' {
+			buf.WriteRune(r)
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte('\'')
+}
+
+// String returns the canonical text representation of v, for instance
+// 'cat':3 'fat':2,11A.
+func (v Vector) String() string {
+	var buf bytes.Buffer
+	for i, l := range v {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		writeQuotedWord(&buf, l.Word)
+		for j, p := range l.Positions {
+			if j == 0 {
+				buf.WriteByte(':')
+			} else {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.Itoa(int(p.Pos)))
+			if p.Weight != WeightD {
+				buf.WriteString(p.Weight.String())
+			}
+		}
+	}
+	return buf.String()
+}
+
+// Size returns the approximate size of v in bytes.
+func (v Vector) Size() uintptr {
+	var size uintptr
+	for _, l := range v {
+		size += uintptr(len(l.Word)) + uintptr(len(l.Positions))*4 + 40
+	}
+	return size
+}
+
+// scanner reads the words of the text representations of vectors and
+// queries.
+type scanner struct {
+	input []rune
+	pos   int
+}
+
+func (s *scanner) eof() bool { return s.pos >= len(s.input) }
+
+func (s *scanner) peek() rune { return s.input[s.pos] }
+
+func (s *scanner) skipSpace() {
+	for !s.eof() && unicode.IsSpace(s.peek()) {
+		s.pos++
+	}
+}
+
+// scanWord reads a word, either enclosed in single quotes or ending before
+// whitespace or one of the delimiters. Backslash escapes the next character
+// in both forms, and a doubled quote stands for a quote in a quoted word.
+func (s *scanner) scanWord(delimiters string) (string, error) {
+	var buf bytes.Buffer
+	if !s.eof() && s.peek() == '\'' {
+		s.pos++
+		for {
+			if s.eof() {
+				return "", errUnterminatedQuote
+			}
+			r := s.peek()
+			s.pos++
+			switch r {
+			case '\\':
+				if s.eof() {
+					return "", errUnterminatedQuote
+				}
+				r = s.peek()
+				s.pos++
+			case '\'':
+				if s.eof() || s.peek() != '\'' {
+					return buf.String(), nil
+				}
+				s.pos++
+			}
+			buf.WriteRune(r)
+		}
+	}
+	for !s.eof() {
+		r := s.peek()
+		if unicode.IsSpace(r) || strings.ContainsRune(delimiters, r) {
+			break
+		}
+		s.pos++
+		if r == '\\' {
+			if s.eof() {
+				return "", errTrailingEscape
+			}
+			r = s.peek()
+			s.pos++
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String(), nil
+}
+
+var (
+	errUnterminatedQuote = pgerror.NewError(pgerror.CodeSyntaxError, "unterminated quoted string")
+	errTrailingEscape    = pgerror.NewError(pgerror.CodeSyntaxError, "unexpected end of input after backslash")
+)
+
+var (
+	errVectorSyntax   = pgerror.NewError(pgerror.CodeSyntaxError, "syntax error in tsvector")
+	errVectorPosition = pgerror.NewError(pgerror.CodeSyntaxError, "wrong position info in tsvector")
+)
+
+// ParseVector parses the text representation of a vector. The lexemes are
+// separated by whitespace, may be quoted, and may be followed by a colon and
+// a comma-separated list of positions, each with an optional weight.
+func ParseVector(s string) (Vector, error) {
+	sc := scanner{input: []rune(s)}
+	var v Vector
+	for {
+		sc.skipSpace()
+		if sc.eof() {
+			break
+		}
+		word, err := sc.scanWord(":")
+		if err != nil {
+			return nil, err
+		}
+		if word == "" {
+			return nil, errVectorSyntax
+		}
+		l := Lexeme{Word: word}
+		if !sc.eof() && sc.peek() == ':' {
+			sc.pos++
+			for {
+				start := sc.pos
+				for !sc.eof() && sc.peek() >= '0' && sc.peek() <= '9' {
+					sc.pos++
+				}
+				pos, err := strconv.Atoi(string(sc.input[start:sc.pos]))
+				if err != nil || pos == 0 {
+					return nil, errVectorPosition
+				}
+				if pos > MaxPosition {
+					pos = MaxPosition
+				}
+				p := Position{Pos: uint16(pos)}
+				if !sc.eof() {
+					if w, ok := parseWeight(sc.peek()); ok {
+						p.Weight = w
+						sc.pos++
+					}
+				}
+				l.Positions = append(l.Positions, p)
+				if sc.eof() || sc.peek() != ',' {
+					break
+				}
+				sc.pos++
+			}
+			if !sc.eof() && !unicode.IsSpace(sc.peek()) {
+				return nil, errVectorSyntax
+			}
+		}
+		v = append(v, l)
+	}
+	return normalizeVector(v), nil
+}